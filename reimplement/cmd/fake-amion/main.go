@@ -0,0 +1,64 @@
+// Command fake-amion serves a fake Amion site on a local port, so the scraper
+// can be run end-to-end on a laptop without network access.
+//
+// Usage:
+//
+//	fake-amion -addr :8089 -seed 42 -username scheduler -password secret \
+//		-session-requests 5 -rate-limit 3 -error-rate 0.1 -latency 200ms -markup links
+//
+// Month pages are served at /schedule/YYYY-MM and range pages at
+// /cgi-bin/ocs?Syr=YYYY&Mo=M&Dy=D&Days=N.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/schedcu/reimplement/pkg/amion/amiontest"
+)
+
+func main() {
+	var cfg amiontest.Config
+	addr := flag.String("addr", "localhost:8089", "Address to listen on")
+	markup := flag.String("markup", string(amiontest.MarkupTable), "Page markup variant (table, links, grid)")
+	flag.Int64Var(&cfg.Seed, "seed", 1, "Seed for the generated schedule")
+	flag.StringVar(&cfg.Username, "username", "", "Require a login with this username")
+	flag.StringVar(&cfg.Password, "password", "", "Password for -username")
+	flag.IntVar(&cfg.SessionRequests, "session-requests", 0, "Pages a session may view before it expires (0 = no limit)")
+	flag.DurationVar(&cfg.SessionTTL, "session-ttl", 0, "Session lifetime (0 = no limit)")
+	flag.IntVar(&cfg.RateLimit, "rate-limit", 0, "Schedule requests per -rate-window before answering 429 (0 = unlimited)")
+	flag.DurationVar(&cfg.RateWindow, "rate-window", 0, "Rate limit window (default 1s)")
+	flag.DurationVar(&cfg.RetryAfter, "retry-after", 0, "Retry-After sent with 429 responses (default 1s)")
+	flag.Float64Var(&cfg.ErrorRate, "error-rate", 0, "Chance (0-1) that a schedule request fails with a 5xx")
+	flag.IntVar(&cfg.MaxErrorsPerPage, "max-errors-per-page", 0, "Stop failing a page after this many errors (0 = no limit)")
+	flag.DurationVar(&cfg.Latency, "latency", 0, "Delay added to every response")
+	flag.DurationVar(&cfg.LatencyJitter, "latency-jitter", 0, "Random extra delay up to this much")
+	flag.Parse()
+
+	cfg.Markup = amiontest.Markup(*markup)
+	known := false
+	var names []string
+	for _, m := range amiontest.Markups {
+		known = known || m == cfg.Markup
+		names = append(names, string(m))
+	}
+	if !known {
+		fmt.Fprintf(os.Stderr, "Unknown -markup %q (want one of %s)\n", *markup, strings.Join(names, ", "))
+		os.Exit(2)
+	}
+	if (cfg.Username == "") != (cfg.Password == "") {
+		log.Fatal("-username and -password must be given together")
+	}
+
+	log.Printf("Fake Amion listening on http://%s (seed %d, markup %s)", *addr, cfg.Seed, cfg.Markup)
+	if cfg.Markup == amiontest.MarkupGrid {
+		log.Printf("Grid pages need the selector profiles:\n%s", amiontest.GridProfileJSON)
+	}
+	if err := http.ListenAndServe(*addr, amiontest.NewSite(cfg)); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
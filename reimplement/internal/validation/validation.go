@@ -50,6 +50,7 @@ const (
 	DATABASE_ERROR            MessageCode = "DATABASE_ERROR"
 	EXTERNAL_SERVICE_ERROR    MessageCode = "EXTERNAL_SERVICE_ERROR"
 	UNKNOWN_ERROR             MessageCode = "UNKNOWN_ERROR"
+	SELECTOR_DRIFT            MessageCode = "SELECTOR_DRIFT"
 )
 
 // String returns the string representation of the message code.
@@ -78,6 +79,8 @@ func MessageCodeFromString(s string) (MessageCode, error) {
 		return EXTERNAL_SERVICE_ERROR, nil
 	case "UNKNOWN_ERROR":
 		return UNKNOWN_ERROR, nil
+	case "SELECTOR_DRIFT":
+		return SELECTOR_DRIFT, nil
 	default:
 		return "", fmt.Errorf("invalid message code: %q", s)
 	}
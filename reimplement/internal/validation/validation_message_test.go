@@ -439,6 +439,7 @@ func TestAllMessageCodeValues(t *testing.T) {
 		DATABASE_ERROR,
 		EXTERNAL_SERVICE_ERROR,
 		UNKNOWN_ERROR,
+		SELECTOR_DRIFT,
 	}
 	seen := make(map[MessageCode]bool)
 
@@ -449,8 +450,8 @@ func TestAllMessageCodeValues(t *testing.T) {
 		seen[c] = true
 	}
 
-	if len(seen) != 7 {
-		t.Errorf("Expected 7 unique message codes, got %d", len(seen))
+	if len(seen) != 8 {
+		t.Errorf("Expected 8 unique message codes, got %d", len(seen))
 	}
 }
 
@@ -0,0 +1,116 @@
+package amiontest
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Markup is a variant of Amion's schedule page HTML
+type Markup string
+
+const (
+	// MarkupTable is the standard seven-column table, staff separated by <br>.
+	// It matches the scraper's default selectors.
+	MarkupTable Markup = "table"
+
+	// MarkupLinks is the standard table with each staff name linked to their
+	// page and open slots in <span class="staff">. It also matches the default
+	// selectors.
+	MarkupLinks Markup = "links"
+
+	// MarkupGrid is a reordered class-based layout (shift first, no staffing
+	// column, staff in a list) that needs GridProfileJSON to scrape
+	MarkupGrid Markup = "grid"
+)
+
+// Markups lists every variant
+var Markups = []Markup{MarkupTable, MarkupLinks, MarkupGrid}
+
+// GridProfileJSON is a selector profile config (see amion.LoadSelectorProfiles)
+// that reads MarkupGrid pages alongside the default profile
+const GridProfileJSON = `{"profiles": [
+  {"name": "default", "version": 1},
+  {"name": "grid", "version": 1, "probe": "table.grid",
+   "selectors": {
+     "shift_table": "table.grid", "shift_row": "table.grid tbody tr",
+     "shift_type_cell": "td:nth-child(1)", "date_cell": "td:nth-child(2)",
+     "start_time_cell": "td:nth-child(3)", "end_time_cell": "td:nth-child(4)",
+     "location_cell": "td:nth-child(5)", "required_staffing_cell": "",
+     "staff_cell": "td:nth-child(6)", "staff_name": "li"}}
+]}`
+
+// renderSchedule writes a schedule page holding shifts in the given markup
+func renderSchedule(w io.Writer, markup Markup, title string, shifts []Shift) {
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head><title>%s</title></head><body>\n", html.EscapeString(title))
+	fmt.Fprintf(w, "<h1>%s</h1>\n", html.EscapeString(title))
+
+	switch markup {
+	case MarkupGrid:
+		fmt.Fprint(w, `<div class="amion-grid"><table class="grid">`+"\n")
+		fmt.Fprint(w, "<thead><tr><th>Assignment</th><th>Date</th><th>From</th><th>To</th><th>Where</th><th>Who</th></tr></thead>\n<tbody>\n")
+		for _, s := range shifts {
+			fmt.Fprintf(w, `<tr class="row"><td class="assignment">%s</td><td class="date">%s</td><td>%s</td><td>%s</td><td>%s</td><td><ul>`,
+				html.EscapeString(s.Name), s.Date, s.Start, s.End, html.EscapeString(s.Location))
+			for _, name := range s.Staff {
+				fmt.Fprintf(w, "<li>%s</li>", html.EscapeString(name))
+			}
+			for i := 0; i < s.OpenSlots; i++ {
+				fmt.Fprint(w, "<li>Open</li>")
+			}
+			fmt.Fprint(w, "</ul></td></tr>\n")
+		}
+		fmt.Fprint(w, "</tbody></table></div>\n")
+
+	default:
+		fmt.Fprint(w, "<table>\n<thead><tr><th>Date</th><th>Shift</th><th>Start</th><th>End</th><th>Location</th><th>Staffing</th><th>Staff</th></tr></thead>\n<tbody>\n")
+		for _, s := range shifts {
+			fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td></tr>\n",
+				s.Date, html.EscapeString(s.Name), s.Start, s.End, html.EscapeString(s.Location), s.Staffing, staffCell(markup, s))
+		}
+		fmt.Fprint(w, "</tbody></table>\n")
+	}
+
+	fmt.Fprint(w, "</body></html>\n")
+}
+
+// staffCell renders the staff column of the table variants
+func staffCell(markup Markup, s Shift) string {
+	var entries []string
+	for _, name := range s.Staff {
+		if markup == MarkupLinks {
+			entries = append(entries, fmt.Sprintf(`<a href="/staff?name=%s">%s</a>`, url.QueryEscape(name), html.EscapeString(name)))
+		} else {
+			entries = append(entries, html.EscapeString(name))
+		}
+	}
+	for i := 0; i < s.OpenSlots; i++ {
+		if markup == MarkupLinks {
+			entries = append(entries, `<span class="staff">Open</span>`)
+		} else {
+			entries = append(entries, "Open")
+		}
+	}
+	if markup == MarkupLinks {
+		return strings.Join(entries, " ")
+	}
+	return strings.Join(entries, "<br>")
+}
+
+// loginPage is the login form, also served when a session has expired
+func loginPage(w io.Writer, csrf, message string) {
+	fmt.Fprint(w, "<!DOCTYPE html>\n<html><head><title>Amion Login</title></head><body>\n")
+	if message != "" {
+		fmt.Fprintf(w, `<p class="error">%s</p>`+"\n", html.EscapeString(message))
+	}
+	fmt.Fprintf(w, `<form method="post" action="%s">
+<input type="hidden" name="csrf" value="%s">
+<input type="text" name="username">
+<input type="password" name="password">
+<input type="submit" value="Log in">
+</form>
+</body></html>
+`, LoginPath, csrf)
+}
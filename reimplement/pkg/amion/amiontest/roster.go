@@ -0,0 +1,116 @@
+package amiontest
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ShiftSpec is one shift the fake department staffs
+type ShiftSpec struct {
+	Name     string         // Shift label as Amion shows it, e.g. "Body Late"
+	Start    string         // HH:MM
+	End      string         // HH:MM; earlier than Start for overnight shifts
+	Location string         // e.g. "Main Reading Room"
+	Staffing int            // People required
+	Days     []time.Weekday // Days the shift runs; nil means every day
+}
+
+// runsOn reports whether the shift is scheduled on the weekday
+func (s ShiftSpec) runsOn(day time.Weekday) bool {
+	if s.Days == nil {
+		return true
+	}
+	for _, d := range s.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Roster is the seed data a schedule is generated from
+type Roster struct {
+	Staff    []string // Names as Amion shows them, "Last, First"
+	Shifts   []ShiftSpec
+	OpenRate float64 // Chance (0-1) that a slot is left "Open"
+}
+
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// DefaultRoster is a small radiology department: day, late and overnight
+// body coverage, weekday neuro and IR, and weekend call
+func DefaultRoster() Roster {
+	return Roster{
+		Staff: []string{
+			"Smith, Jane", "Lee, Ann", "Doe, John", "Patel, Priya", "Garcia, Luis",
+			"Nguyen, Thao", "Okafor, Chidi", "Kowalski, Eva", "Brown, Sam", "Haddad, Omar",
+			"Schmidt, Lena", "Tanaka, Ken", "Murphy, Ciara", "Rossi, Marco",
+		},
+		Shifts: []ShiftSpec{
+			{Name: "Body Day", Start: "07:00", End: "15:00", Location: "Main Reading Room", Staffing: 2},
+			{Name: "Body Late", Start: "15:00", End: "23:00", Location: "Main Reading Room", Staffing: 1},
+			{Name: "Overnight", Start: "23:00", End: "07:00", Location: "ER", Staffing: 1},
+			{Name: "Neuro Day", Start: "08:00", End: "17:00", Location: "Neuro Suite", Staffing: 1, Days: weekdays},
+			{Name: "IR", Start: "07:30", End: "16:30", Location: "IR Suite", Staffing: 1, Days: weekdays},
+			{Name: "Weekend Call", Start: "08:00", End: "20:00", Location: "Main Reading Room", Staffing: 1,
+				Days: []time.Weekday{time.Saturday, time.Sunday}},
+		},
+		OpenRate: 0.05,
+	}
+}
+
+// Shift is one generated schedule row
+type Shift struct {
+	Date      string // YYYY-MM-DD
+	Name      string
+	Start     string
+	End       string
+	Location  string
+	Staffing  int
+	Staff     []string // Assigned staff, in page order
+	OpenSlots int      // Slots shown as "Open"
+}
+
+// Day generates the shifts for one date. The result depends only on the seed,
+// the roster and the date, so every page covering the date shows the same
+// shifts and a test can compute what a scrape should return. Nobody works two
+// shifts on the same day; if the roster runs out, the slot is left open.
+func (r Roster) Day(seed int64, date time.Time) []Shift {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(seed*1_000_003 + date.Unix()/86400))
+	available := rng.Perm(len(r.Staff))
+
+	var shifts []Shift
+	for _, spec := range r.Shifts {
+		if !spec.runsOn(date.Weekday()) {
+			continue
+		}
+		shift := Shift{
+			Date:     date.Format("2006-01-02"),
+			Name:     spec.Name,
+			Start:    spec.Start,
+			End:      spec.End,
+			Location: spec.Location,
+			Staffing: spec.Staffing,
+		}
+		for slot := 0; slot < spec.Staffing; slot++ {
+			if len(available) == 0 || rng.Float64() < r.OpenRate {
+				shift.OpenSlots++
+				continue
+			}
+			shift.Staff = append(shift.Staff, r.Staff[available[0]])
+			available = available[1:]
+		}
+		shifts = append(shifts, shift)
+	}
+	return shifts
+}
+
+// Range generates the shifts for every date in [from, to], inclusive
+func (r Roster) Range(seed int64, from, to time.Time) []Shift {
+	var shifts []Shift
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		shifts = append(shifts, r.Day(seed, d)...)
+	}
+	return shifts
+}
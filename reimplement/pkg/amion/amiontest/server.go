@@ -0,0 +1,339 @@
+// Package amiontest provides a fake Amion site for end-to-end tests of the
+// scraper, rate limiter and importer without network access. Schedules are
+// generated from a seeded roster, so the same seed always serves the same
+// pages and tests can compute the expected shifts with Roster.Range.
+//
+// The site can require a login with expiring sessions, throttle with 429,
+// fail with random 5xx responses, respond slowly and serve several markup
+// variants:
+//
+//	srv := amiontest.NewServer(amiontest.Config{
+//		Seed:     42,
+//		Username: "scheduler", Password: "secret",
+//		SessionRequests: 3, RateLimit: 5, ErrorRate: 0.2, MaxErrorsPerPage: 1,
+//	})
+//	defer srv.Close()
+//	client, _ := amion.NewAmionHTTPClient(srv.URL)
+//
+// cmd/fake-amion serves the same site on a local port.
+package amiontest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LoginPath is where the fake site serves its login form
+const LoginPath = "/login"
+
+// SessionCookie names the session cookie set on login
+const SessionCookie = "amion_session"
+
+// MaxPageDays bounds the days one /cgi-bin/ocs page may cover
+const MaxPageDays = 42
+
+var monthPathPattern = regexp.MustCompile(`^/schedule/(\d{4})-(\d{2})$`)
+
+// Config configures the fake site. The zero value serves DefaultRoster pages
+// in MarkupTable without login, throttling, errors or delay.
+type Config struct {
+	Seed   int64
+	Roster *Roster // nil means DefaultRoster()
+	Markup Markup  // Empty means MarkupTable
+
+	// Username and Password enable the login; without them pages are public
+	Username        string
+	Password        string
+	SessionRequests int           // Pages a session may view before it expires; 0 = no limit
+	SessionTTL      time.Duration // Session lifetime; 0 = no limit
+
+	// RateLimit is how many schedule requests are served per RateWindow
+	// (default one second) before answering 429; 0 = unlimited
+	RateLimit  int
+	RateWindow time.Duration
+	RetryAfter time.Duration // Sent with 429s, rounded up to whole seconds; default 1s
+
+	ErrorRate        float64 // Chance (0-1) that a schedule request fails with 500, 502 or 503
+	MaxErrorsPerPage int     // A page stops failing after this many errors; 0 = no limit
+
+	Latency       time.Duration // Added to every response
+	LatencyJitter time.Duration // Random extra latency up to this much
+}
+
+// Stats counts what the site has served
+type Stats struct {
+	Requests        int // Every request, logins included
+	Pages           int // Schedule pages served successfully
+	Logins          int // Successful logins
+	FailedLogins    int
+	ExpiredSessions int // Requests redirected to the login because the session expired
+	Throttled       int // 429 responses
+	ServerErrors    int // Injected 5xx responses
+}
+
+type session struct {
+	created time.Time
+	views   int
+}
+
+// Site is the fake Amion site as an http.Handler
+type Site struct {
+	cfg    Config
+	roster Roster
+
+	mu         sync.Mutex
+	rng        *rand.Rand
+	sessions   map[string]*session
+	csrf       map[string]bool
+	recent     []time.Time    // Schedule requests inside the rate window
+	pageErrors map[string]int // Injected errors per page
+	stats      Stats
+}
+
+// NewSite creates the fake site
+func NewSite(cfg Config) *Site {
+	if cfg.Markup == "" {
+		cfg.Markup = MarkupTable
+	}
+	if cfg.RateWindow <= 0 {
+		cfg.RateWindow = time.Second
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = time.Second
+	}
+	roster := DefaultRoster()
+	if cfg.Roster != nil {
+		roster = *cfg.Roster
+	}
+
+	return &Site{
+		cfg:        cfg,
+		roster:     roster,
+		rng:        rand.New(rand.NewSource(cfg.Seed)),
+		sessions:   make(map[string]*session),
+		csrf:       make(map[string]bool),
+		pageErrors: make(map[string]int),
+	}
+}
+
+// Server is a fake Amion site listening on a local httptest server
+type Server struct {
+	*httptest.Server
+	Site *Site
+}
+
+// NewServer starts a fake Amion site. Close it when done.
+func NewServer(cfg Config) *Server {
+	site := NewSite(cfg)
+	return &Server{Server: httptest.NewServer(site), Site: site}
+}
+
+// Shifts returns the shifts the site shows for [from, to], inclusive
+func (s *Site) Shifts(from, to time.Time) []Shift {
+	return s.roster.Range(s.cfg.Seed, from, to)
+}
+
+// Stats returns the counters so far
+func (s *Site) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// ServeHTTP implements http.Handler
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.stats.Requests++
+	delay := s.cfg.Latency
+	if s.cfg.LatencyJitter > 0 {
+		delay += time.Duration(s.rng.Int63n(int64(s.cfg.LatencyJitter)))
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case r.URL.Path == LoginPath:
+		s.serveLogin(w, r)
+	case r.URL.Path == "/":
+		fmt.Fprint(w, "<!DOCTYPE html>\n<html><head><title>Amion</title></head><body><p>Welcome to Amion</p></body></html>\n")
+	case r.URL.Path == "/cgi-bin/ocs" || monthPathPattern.MatchString(r.URL.Path):
+		s.serveSchedule(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveLogin shows the login form (GET) or checks the submitted credentials (POST)
+func (s *Site) serveLogin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method != http.MethodPost {
+		loginPage(w, s.newCSRFLocked(), "")
+		return
+	}
+
+	if !s.csrf[r.FormValue("csrf")] {
+		s.stats.FailedLogins++
+		loginPage(w, s.newCSRFLocked(), "Your login form expired, please try again")
+		return
+	}
+	delete(s.csrf, r.FormValue("csrf"))
+
+	if s.cfg.Username == "" || r.FormValue("username") != s.cfg.Username || r.FormValue("password") != s.cfg.Password {
+		s.stats.FailedLogins++
+		loginPage(w, s.newCSRFLocked(), "Invalid username or password")
+		return
+	}
+
+	token := s.tokenLocked()
+	s.sessions[token] = &session{created: time.Now()}
+	s.stats.Logins++
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Value: token, Path: "/", HttpOnly: true})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// serveSchedule serves a month page (/schedule/YYYY-MM) or a range page
+// (/cgi-bin/ocs?Syr=YYYY&Mo=M&Dy=D&Days=N), after the throttling, session and
+// error checks
+func (s *Site) serveSchedule(w http.ResponseWriter, r *http.Request) {
+	from, to, title, err := pageRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	if retryAfter, throttled := s.throttleLocked(); throttled {
+		s.stats.Throttled++
+		s.mu.Unlock()
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	if s.cfg.Username != "" {
+		if expired := s.checkSessionLocked(r); expired != "" {
+			if expired == "expired" {
+				s.stats.ExpiredSessions++
+			}
+			s.mu.Unlock()
+			http.Redirect(w, r, LoginPath, http.StatusFound)
+			return
+		}
+	}
+
+	page := r.URL.RequestURI()
+	if s.cfg.ErrorRate > 0 && s.rng.Float64() < s.cfg.ErrorRate &&
+		(s.cfg.MaxErrorsPerPage == 0 || s.pageErrors[page] < s.cfg.MaxErrorsPerPage) {
+		s.pageErrors[page]++
+		s.stats.ServerErrors++
+		status := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}[s.rng.Intn(3)]
+		s.mu.Unlock()
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	s.stats.Pages++
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	renderSchedule(w, s.cfg.Markup, title, s.Shifts(from, to))
+}
+
+// throttleLocked records a schedule request and reports whether it is over
+// the rate limit, with the Retry-After in seconds
+func (s *Site) throttleLocked() (int, bool) {
+	if s.cfg.RateLimit <= 0 {
+		return 0, false
+	}
+
+	now := time.Now()
+	kept := s.recent[:0]
+	for _, t := range s.recent {
+		if now.Sub(t) < s.cfg.RateWindow {
+			kept = append(kept, t)
+		}
+	}
+	s.recent = kept
+
+	if len(s.recent) >= s.cfg.RateLimit {
+		return int((s.cfg.RetryAfter + time.Second - 1) / time.Second), true
+	}
+	s.recent = append(s.recent, now)
+	return 0, false
+}
+
+// checkSessionLocked counts a page view against the request's session. It
+// returns "" if the session is valid, "missing" without one, and "expired"
+// once it has run out of views or time.
+func (s *Site) checkSessionLocked(r *http.Request) string {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return "missing"
+	}
+	sess, ok := s.sessions[cookie.Value]
+	if !ok {
+		return "missing"
+	}
+
+	if (s.cfg.SessionRequests > 0 && sess.views >= s.cfg.SessionRequests) ||
+		(s.cfg.SessionTTL > 0 && time.Since(sess.created) > s.cfg.SessionTTL) {
+		delete(s.sessions, cookie.Value)
+		return "expired"
+	}
+	sess.views++
+	return ""
+}
+
+func (s *Site) newCSRFLocked() string {
+	token := s.tokenLocked()
+	s.csrf[token] = true
+	return token
+}
+
+func (s *Site) tokenLocked() string {
+	return fmt.Sprintf("%016x%016x", s.rng.Uint64(), s.rng.Uint64())
+}
+
+// pageRange parses the dates a schedule request covers
+func pageRange(r *http.Request) (from, to time.Time, title string, err error) {
+	if m := monthPathPattern.FindStringSubmatch(r.URL.Path); m != nil {
+		from, err = time.Parse("2006-01", m[1]+"-"+m[2])
+		if err != nil {
+			return from, to, "", fmt.Errorf("invalid month %s-%s", m[1], m[2])
+		}
+		return from, from.AddDate(0, 1, -1), "Schedule for " + from.Format("January 2006"), nil
+	}
+
+	q := r.URL.Query()
+	year, yerr := strconv.Atoi(q.Get("Syr"))
+	month, merr := strconv.Atoi(q.Get("Mo"))
+	day, derr := strconv.Atoi(q.Get("Dy"))
+	if yerr != nil || merr != nil || derr != nil || month < 1 || month > 12 || day < 1 || day > 31 {
+		return from, to, "", fmt.Errorf("Syr, Mo and Dy must give a valid date")
+	}
+	days := 1
+	if d := q.Get("Days"); d != "" {
+		if days, err = strconv.Atoi(d); err != nil || days < 1 || days > MaxPageDays {
+			return from, to, "", fmt.Errorf("Days must be between 1 and %d", MaxPageDays)
+		}
+	}
+
+	from = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	to = from.AddDate(0, 0, days-1)
+	return from, to, fmt.Sprintf("Schedule for %s to %s", from.Format("Jan 2, 2006"), to.Format("Jan 2, 2006")), nil
+}
@@ -0,0 +1,184 @@
+package amiontest
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var nov2025 = time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+func TestRoster_Day(t *testing.T) {
+	roster := DefaultRoster()
+
+	monday := roster.Day(7, time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, monday, roster.Day(7, time.Date(2025, 11, 3, 15, 30, 0, 0, time.UTC)), "time of day is ignored")
+	assert.NotEqual(t, monday, roster.Day(8, time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)), "the seed changes the schedule")
+
+	names := make(map[string]bool)
+	for _, s := range monday {
+		assert.Equal(t, s.Staffing, len(s.Staff)+s.OpenSlots, s.Name)
+		for _, name := range s.Staff {
+			assert.False(t, names[name], "%s works two shifts on one day", name)
+			names[name] = true
+		}
+		assert.NotEqual(t, "Weekend Call", s.Name)
+	}
+
+	saturday := roster.Day(7, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC))
+	var shiftNames []string
+	for _, s := range saturday {
+		shiftNames = append(shiftNames, s.Name)
+	}
+	assert.Equal(t, []string{"Body Day", "Body Late", "Overnight", "Weekend Call"}, shiftNames)
+
+	assert.Len(t, roster.Range(7, nov2025, nov2025.AddDate(0, 0, 6)), 5*5+2*4)
+}
+
+func get(t *testing.T, client *http.Client, rawURL string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(rawURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+var csrfPattern = regexp.MustCompile(`name="csrf" value="([0-9a-f]+)"`)
+
+func login(t *testing.T, client *http.Client, baseURL, username, password string) (*http.Response, string) {
+	t.Helper()
+	_, form := get(t, client, baseURL+LoginPath)
+	m := csrfPattern.FindStringSubmatch(form)
+	require.NotNil(t, m, "login form has a CSRF token")
+
+	resp, err := client.PostForm(baseURL+LoginPath, url.Values{"csrf": {m[1]}, "username": {username}, "password": {password}})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestServer_Pages(t *testing.T) {
+	srv := NewServer(Config{Seed: 3})
+	defer srv.Close()
+
+	resp, body := get(t, srv.Client(), srv.URL+"/schedule/2025-11")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	november := srv.Site.Shifts(nov2025, nov2025.AddDate(0, 1, -1))
+	assert.Equal(t, len(november), strings.Count(body, "<tr><td>"), "one row per shift")
+	assert.Contains(t, body, "<td>2025-11-30</td>")
+	assert.NotContains(t, body, "2025-12-01")
+
+	_, week := get(t, srv.Client(), srv.URL+"/cgi-bin/ocs?Syr=2025&Mo=11&Dy=2&Days=7")
+	assert.Contains(t, week, "<td>2025-11-02</td>")
+	assert.Contains(t, week, "<td>2025-11-08</td>")
+	assert.NotContains(t, week, "2025-11-09")
+
+	resp, _ = get(t, srv.Client(), srv.URL+"/cgi-bin/ocs?Syr=2025&Mo=13&Dy=1")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = get(t, srv.Client(), srv.URL+"/nope")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_Markups(t *testing.T) {
+	for _, markup := range Markups {
+		t.Run(string(markup), func(t *testing.T) {
+			srv := NewServer(Config{Seed: 3, Markup: markup})
+			defer srv.Close()
+
+			_, body := get(t, srv.Client(), srv.URL+"/cgi-bin/ocs?Syr=2025&Mo=11&Dy=3")
+			var first Shift
+			for _, s := range srv.Site.Shifts(nov2025.AddDate(0, 0, 2), nov2025.AddDate(0, 0, 2)) {
+				if len(s.Staff) > 0 {
+					first = s
+					break
+				}
+			}
+			assert.Contains(t, body, first.Name)
+			switch markup {
+			case MarkupGrid:
+				assert.Contains(t, body, `<table class="grid">`)
+				assert.Contains(t, body, "<li>"+first.Staff[0]+"</li>")
+			case MarkupLinks:
+				assert.Contains(t, body, ">"+first.Staff[0]+"</a>")
+			default:
+				assert.Contains(t, body, "<td>"+first.Staff[0])
+			}
+		})
+	}
+}
+
+func TestServer_LoginAndSessionExpiry(t *testing.T) {
+	srv := NewServer(Config{Username: "scheduler", Password: "s3cret", SessionRequests: 2})
+	defer srv.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+
+	resp, _ := get(t, client, srv.URL+"/schedule/2025-11")
+	assert.Equal(t, LoginPath, resp.Request.URL.Path, "pages need a session")
+
+	_, body := login(t, client, srv.URL, "scheduler", "wrong")
+	assert.Contains(t, body, "Invalid username or password")
+
+	resp, body = login(t, client, srv.URL, "scheduler", "s3cret")
+	assert.Equal(t, "/", resp.Request.URL.Path)
+	assert.Contains(t, body, "Welcome")
+
+	for i := 0; i < 2; i++ {
+		resp, _ = get(t, client, srv.URL+"/schedule/2025-11")
+		assert.Equal(t, "/schedule/2025-11", resp.Request.URL.Path)
+	}
+	resp, _ = get(t, client, srv.URL+"/schedule/2025-11")
+	assert.Equal(t, LoginPath, resp.Request.URL.Path, "the session expires after two pages")
+
+	stats := srv.Site.Stats()
+	assert.Equal(t, 1, stats.Logins)
+	assert.Equal(t, 1, stats.FailedLogins)
+	assert.Equal(t, 1, stats.ExpiredSessions)
+	assert.Equal(t, 2, stats.Pages)
+}
+
+func TestServer_ThrottlingAndErrors(t *testing.T) {
+	srv := NewServer(Config{RateLimit: 2, RateWindow: time.Hour, RetryAfter: 1500 * time.Millisecond})
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, _ := get(t, srv.Client(), srv.URL+"/schedule/2025-11")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	resp, _ := get(t, srv.Client(), srv.URL+"/schedule/2025-11")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"), "rounded up to whole seconds")
+
+	failing := NewServer(Config{ErrorRate: 1, MaxErrorsPerPage: 2})
+	defer failing.Close()
+	for i := 0; i < 2; i++ {
+		resp, _ := get(t, failing.Client(), failing.URL+"/schedule/2025-11")
+		assert.GreaterOrEqual(t, resp.StatusCode, 500)
+	}
+	resp, _ = get(t, failing.Client(), failing.URL+"/schedule/2025-11")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "a page stops failing after MaxErrorsPerPage")
+	assert.Equal(t, 2, failing.Site.Stats().ServerErrors)
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv := NewServer(Config{Latency: 50 * time.Millisecond})
+	defer srv.Close()
+
+	start := time.Now()
+	get(t, srv.Client(), srv.URL+"/schedule/2025-11")
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
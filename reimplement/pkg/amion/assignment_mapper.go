@@ -2,7 +2,9 @@ package amion
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -145,3 +147,118 @@ func (am *AssignmentMapper) parseDate(dateStr string) (time.Time, error) {
 
 	return parsedDate, nil
 }
+
+// ErrPersonNotFound is returned by a PersonResolver when no person matches a staff name.
+var ErrPersonNotFound = errors.New("person not found")
+
+// PersonResolver resolves a staff name as shown on an Amion page to a person ID.
+// Implementations should return an error wrapping ErrPersonNotFound for unknown
+// names; any other error aborts the mapping.
+type PersonResolver interface {
+	ResolvePerson(ctx context.Context, name string) (uuid.UUID, error)
+}
+
+// StaticPersonResolver resolves names from a fixed map. Lookups ignore case and
+// extra whitespace.
+type StaticPersonResolver struct {
+	people map[string]uuid.UUID
+}
+
+// NewStaticPersonResolver creates a resolver from a name -> person ID map.
+func NewStaticPersonResolver(people map[string]uuid.UUID) *StaticPersonResolver {
+	normalized := make(map[string]uuid.UUID, len(people))
+	for name, id := range people {
+		normalized[normalizeStaffName(name)] = id
+	}
+	return &StaticPersonResolver{people: normalized}
+}
+
+// ResolvePerson implements PersonResolver.
+func (r *StaticPersonResolver) ResolvePerson(ctx context.Context, name string) (uuid.UUID, error) {
+	id, ok := r.people[normalizeStaffName(name)]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("%w: %q", ErrPersonNotFound, name)
+	}
+	return id, nil
+}
+
+// normalizeStaffName lower-cases a name and collapses its whitespace
+func normalizeStaffName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// StaffMappingResult is the outcome of mapping every staff name on one shift.
+type StaffMappingResult struct {
+	// Assignments has one assignment per distinct resolved person
+	Assignments []*entity.Assignment
+
+	// UnresolvedNames lists staff names the resolver did not recognise
+	UnresolvedNames []string
+
+	// OpenSlots is carried over from the raw shift (unfilled "open"/"TBD" slots)
+	OpenSlots int
+}
+
+// MapStaffToAssignments converts a RawAmionShift into one Assignment per
+// person named in its staff cell.
+//
+// Parameters:
+// - ctx: Context for the operation
+// - raw: The raw shift data from Amion scraper, including StaffNames
+// - resolver: Resolves each staff name to a person ID
+// - shiftInstance: The shift instance entity to assign people to
+// - scheduleVersionID: The UUID of the parent schedule version
+// - userID: The UUID of the user creating the assignments
+// - shiftRepo: The shift instance repository (passed through to MapToAssignment)
+//
+// Returns:
+// - *StaffMappingResult: Assignments for resolved people, plus unresolved names
+// - error: A validation error from MapToAssignment or a resolver failure
+//
+// Names the resolver does not know (ErrPersonNotFound) are reported in
+// UnresolvedNames rather than failing the shift, so one unknown locum does not
+// drop everyone else's assignment. Two names resolving to the same person
+// produce a single assignment. A shift without staff names maps to no
+// assignments.
+func (am *AssignmentMapper) MapStaffToAssignments(
+	ctx context.Context,
+	raw RawAmionShift,
+	resolver PersonResolver,
+	shiftInstance *entity.ShiftInstance,
+	scheduleVersionID uuid.UUID,
+	userID uuid.UUID,
+	shiftRepo repository.ShiftInstanceRepository,
+) (*StaffMappingResult, error) {
+	if resolver == nil {
+		return nil, fmt.Errorf("person resolver cannot be nil")
+	}
+
+	result := &StaffMappingResult{
+		Assignments: make([]*entity.Assignment, 0, len(raw.StaffNames)),
+		OpenSlots:   raw.OpenSlots,
+	}
+	seen := make(map[uuid.UUID]bool)
+
+	for _, name := range raw.StaffNames {
+		personID, err := resolver.ResolvePerson(ctx, name)
+		if errors.Is(err, ErrPersonNotFound) {
+			result.UnresolvedNames = append(result.UnresolvedNames, name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve staff name %q: %w", name, err)
+		}
+		if seen[personID] {
+			continue
+		}
+		seen[personID] = true
+
+		assignment, err := am.MapToAssignment(ctx, raw, personID, shiftInstance, scheduleVersionID, userID, shiftRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map assignment for %q: %w", name, err)
+		}
+		result.Assignments = append(result.Assignments, assignment)
+	}
+
+	return result, nil
+}
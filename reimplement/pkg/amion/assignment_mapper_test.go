@@ -866,3 +866,103 @@ func (m *MockShiftInstanceRepository) DeleteByScheduleVersion(ctx context.Contex
 	}
 	return count, nil
 }
+
+// TestAssignmentMapperMapStaffToAssignments tests one assignment per resolved staff name.
+func TestAssignmentMapperMapStaffToAssignments(t *testing.T) {
+	mapper := NewAssignmentMapper()
+
+	ann := uuid.New()
+	joon := uuid.New()
+	scheduleVersionID := uuid.New()
+	userID := uuid.New()
+
+	shiftInstance := &entity.ShiftInstance{
+		ID:                uuid.New(),
+		ScheduleVersionID: scheduleVersionID,
+		ShiftType:         "Radiologist",
+		CreatedAt:         time.Now(),
+		CreatedBy:         userID,
+	}
+	mockRepo := &MockShiftInstanceRepository{
+		shifts: map[uuid.UUID]*entity.ShiftInstance{shiftInstance.ID: shiftInstance},
+	}
+
+	resolver := NewStaticPersonResolver(map[string]uuid.UUID{
+		"Lee, Ann":   ann,
+		"Park, Joon": joon,
+		"Ann Lee":    ann, // Alias resolving to the same person
+	})
+
+	raw := RawAmionShift{
+		Date:       "2025-11-15",
+		ShiftType:  "Radiologist",
+		StaffNames: []string{"Lee, Ann", "PARK,  JOON", "Ann Lee", "Unknown, Locum"},
+		OpenSlots:  1,
+		RowIndex:   2,
+	}
+
+	result, err := mapper.MapStaffToAssignments(context.Background(), raw, resolver, shiftInstance, scheduleVersionID, userID, mockRepo)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(result.Assignments) != 2 {
+		t.Fatalf("Expected 2 assignments, got %d", len(result.Assignments))
+	}
+	if result.Assignments[0].PersonID != ann || result.Assignments[1].PersonID != joon {
+		t.Errorf("Expected assignments for Ann then Joon, got %s, %s", result.Assignments[0].PersonID, result.Assignments[1].PersonID)
+	}
+	for _, assignment := range result.Assignments {
+		if assignment.ShiftInstanceID != shiftInstance.ID {
+			t.Errorf("Expected ShiftInstanceID %s, got %s", shiftInstance.ID, assignment.ShiftInstanceID)
+		}
+		if assignment.OriginalShiftType != "Radiologist" {
+			t.Errorf("Expected OriginalShiftType 'Radiologist', got %s", assignment.OriginalShiftType)
+		}
+	}
+
+	if len(result.UnresolvedNames) != 1 || result.UnresolvedNames[0] != "Unknown, Locum" {
+		t.Errorf("Expected unresolved [Unknown, Locum], got %q", result.UnresolvedNames)
+	}
+	if result.OpenSlots != 1 {
+		t.Errorf("Expected 1 open slot, got %d", result.OpenSlots)
+	}
+}
+
+// failingResolver returns an error that is not ErrPersonNotFound.
+type failingResolver struct{}
+
+func (failingResolver) ResolvePerson(ctx context.Context, name string) (uuid.UUID, error) {
+	return uuid.Nil, fmt.Errorf("directory unavailable")
+}
+
+// TestAssignmentMapperMapStaffToAssignmentsErrors tests resolver and validation failures.
+func TestAssignmentMapperMapStaffToAssignmentsErrors(t *testing.T) {
+	mapper := NewAssignmentMapper()
+	scheduleVersionID := uuid.New()
+	userID := uuid.New()
+	shiftInstance := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: scheduleVersionID}
+	raw := RawAmionShift{Date: "2025-11-15", ShiftType: "Technologist", StaffNames: []string{"Smith, Jane"}}
+	resolver := NewStaticPersonResolver(map[string]uuid.UUID{"Smith, Jane": uuid.New()})
+
+	if _, err := mapper.MapStaffToAssignments(context.Background(), raw, nil, shiftInstance, scheduleVersionID, userID, nil); err == nil {
+		t.Error("Expected error for nil resolver")
+	}
+
+	if _, err := mapper.MapStaffToAssignments(context.Background(), raw, failingResolver{}, shiftInstance, scheduleVersionID, userID, nil); err == nil {
+		t.Error("Expected resolver failure to be returned")
+	}
+
+	if _, err := mapper.MapStaffToAssignments(context.Background(), raw, resolver, nil, scheduleVersionID, userID, nil); err == nil {
+		t.Error("Expected error for nil shift instance")
+	}
+
+	unstaffed := RawAmionShift{Date: "2025-11-15", ShiftType: "Technologist", OpenSlots: 2}
+	result, err := mapper.MapStaffToAssignments(context.Background(), unstaffed, resolver, shiftInstance, scheduleVersionID, userID, nil)
+	if err != nil {
+		t.Fatalf("Expected no error for unstaffed shift, got: %v", err)
+	}
+	if len(result.Assignments) != 0 || result.OpenSlots != 2 {
+		t.Errorf("Expected no assignments and 2 open slots, got %d and %d", len(result.Assignments), result.OpenSlots)
+	}
+}
@@ -0,0 +1,299 @@
+package amion
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// AuthErrorKind classifies an authentication failure
+type AuthErrorKind string
+
+const (
+	// AuthMissingCredentials means login is configured without a username or password
+	AuthMissingCredentials AuthErrorKind = "MISSING_CREDENTIALS"
+	// AuthInvalidCredentials means Amion rejected the username or password
+	AuthInvalidCredentials AuthErrorKind = "INVALID_CREDENTIALS"
+	// AuthLoginUnavailable means the login request itself failed (network, 5xx, ...)
+	AuthLoginUnavailable AuthErrorKind = "LOGIN_UNAVAILABLE"
+	// AuthSessionExpired means the session expired and logging in again did not restore it
+	AuthSessionExpired AuthErrorKind = "SESSION_EXPIRED"
+)
+
+const (
+	// DefaultLoginPath is the path of the Amion login form
+	DefaultLoginPath = "/login"
+
+	// DefaultUsernameField is the form field holding the username
+	DefaultUsernameField = "username"
+
+	// DefaultPasswordField is the form field holding the password
+	DefaultPasswordField = "password"
+)
+
+// AuthError represents a failure to authenticate with Amion.
+type AuthError struct {
+	Kind       AuthErrorKind
+	URL        string
+	Message    string
+	Underlying error
+}
+
+// Error implements the error interface for AuthError.
+func (e *AuthError) Error() string {
+	msg := fmt.Sprintf("amion auth %s: %s (URL: %s)", e.Kind, e.Message, e.URL)
+	if e.Underlying != nil {
+		msg += fmt.Sprintf(": %v", e.Underlying)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *AuthError) Unwrap() error {
+	return e.Underlying
+}
+
+// LoginConfig configures the Amion login form.
+type LoginConfig struct {
+	Username string
+	Password string
+
+	// LoginPath is where the login form lives; being redirected here means the
+	// session has expired. Default DefaultLoginPath.
+	LoginPath string
+
+	// UsernameField and PasswordField are the form field names.
+	// Default DefaultUsernameField and DefaultPasswordField.
+	UsernameField string
+	PasswordField string
+
+	// ExtraFields are posted along with the credentials (e.g. a login mode)
+	ExtraFields map[string]string
+}
+
+// withDefaults fills in the default form settings
+func (lc LoginConfig) withDefaults() LoginConfig {
+	if lc.LoginPath == "" {
+		lc.LoginPath = DefaultLoginPath
+	}
+	if lc.UsernameField == "" {
+		lc.UsernameField = DefaultUsernameField
+	}
+	if lc.PasswordField == "" {
+		lc.PasswordField = DefaultPasswordField
+	}
+	return lc
+}
+
+// SetLogin enables authenticated sessions. The client logs in before its first
+// request, and when a request lands on the login page (the session expired) it
+// logs in again and repeats the request. Setting the login clears any earlier
+// session and any remembered credential failure.
+//
+// Example:
+//
+//	client.SetLogin(LoginConfig{Username: "scheduler", Password: password})
+func (c *AmionHTTPClient) SetLogin(config LoginConfig) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	config = config.withDefaults()
+	c.login = &config
+	c.loggedIn = false
+	c.authErr = nil
+	c.sessionGen++
+}
+
+// Login logs in to Amion now. It is called automatically before the first
+// request, so calling it is only needed to verify the credentials up front.
+//
+// Returns:
+//   - error: *AuthError if the login fails, nil if no login is configured
+func (c *AmionHTTPClient) Login(ctx context.Context) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	return c.loginLocked(ctx)
+}
+
+// ensureSession logs in if needed and returns the session generation, which
+// identifies the session a request was made with.
+func (c *AmionHTTPClient) ensureSession(ctx context.Context) (int, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.login == nil || c.loggedIn {
+		return c.sessionGen, nil
+	}
+	if c.authErr != nil {
+		// Don't retry rejected credentials: repeated failures can lock the account
+		return c.sessionGen, c.authErr
+	}
+	err := c.loginLocked(ctx)
+	return c.sessionGen, err
+}
+
+// relogin logs in again after a request made with session generation gen found
+// its session expired. If another request has already logged in again since,
+// its session is reused.
+func (c *AmionHTTPClient) relogin(ctx context.Context, gen int) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.sessionGen != gen && c.loggedIn {
+		return nil
+	}
+	if c.authErr != nil {
+		return c.authErr
+	}
+
+	if c.logger != nil {
+		c.logger.Infow("amion session expired, logging in again")
+	}
+	c.loggedIn = false
+	return c.loginLocked(ctx)
+}
+
+// loginLocked performs the login. Must be called with authMu held.
+func (c *AmionHTTPClient) loginLocked(ctx context.Context) error {
+	if c.login == nil {
+		return nil
+	}
+
+	loginURL := c.baseURL + c.login.LoginPath
+	if c.login.Username == "" || c.login.Password == "" {
+		c.authErr = &AuthError{Kind: AuthMissingCredentials, URL: loginURL, Message: "username and password are required"}
+		return c.authErr
+	}
+
+	// Load the form first: it sets any pre-login cookie and may carry hidden
+	// fields such as a CSRF token
+	action, fields, err := c.loadLoginForm(ctx, loginURL)
+	if err != nil {
+		return &AuthError{Kind: AuthLoginUnavailable, URL: loginURL, Message: "failed to load login form", Underlying: err}
+	}
+	for name, value := range c.login.ExtraFields {
+		fields.Set(name, value)
+	}
+	fields.Set(c.login.UsernameField, c.login.Username)
+	fields.Set(c.login.PasswordField, c.login.Password)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, action, strings.NewReader(fields.Encode()))
+	if err != nil {
+		return &AuthError{Kind: AuthLoginUnavailable, URL: action, Message: "failed to build login request", Underlying: err}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &AuthError{Kind: AuthLoginUnavailable, URL: action, Message: "login request failed", Underlying: err}
+	}
+	body, err := readBody(resp)
+	resp.Body.Close()
+	if err != nil {
+		return &AuthError{Kind: AuthLoginUnavailable, URL: action, Message: "failed to read login response", Underlying: err}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		c.authErr = &AuthError{Kind: AuthInvalidCredentials, URL: action, Message: fmt.Sprintf("login rejected with HTTP %d", resp.StatusCode)}
+		return c.authErr
+	case resp.StatusCode >= 400:
+		return &AuthError{Kind: AuthLoginUnavailable, URL: action, Message: fmt.Sprintf("login returned HTTP %d", resp.StatusCode)}
+	case c.hasLoginForm(body):
+		// Still looking at the form: the credentials were not accepted
+		c.authErr = &AuthError{Kind: AuthInvalidCredentials, URL: action, Message: "login form was shown again after submitting credentials"}
+		return c.authErr
+	}
+
+	c.loggedIn = true
+	c.sessionGen++
+	if c.logger != nil {
+		c.logger.Infow("logged in to amion", "username", c.login.Username)
+	}
+	return nil
+}
+
+// loadLoginForm fetches the login page and returns where to post the form and
+// its hidden fields. If the page has no recognisable form, the credentials are
+// posted to the login URL itself.
+func (c *AmionHTTPClient) loadLoginForm(ctx context.Context, loginURL string) (string, url.Values, error) {
+	fields := url.Values{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loginURL, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	body, err := readBody(resp)
+	resp.Body.Close()
+	if err != nil {
+		return "", nil, err
+	}
+	if resp.StatusCode >= 500 {
+		return "", nil, fmt.Errorf("login page returned HTTP %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return loginURL, fields, nil
+	}
+	form := doc.Find("form").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.Find("input[type=password]").Length() > 0
+	}).First()
+	if form.Length() == 0 {
+		return loginURL, fields, nil
+	}
+
+	form.Find("input[type=hidden]").Each(func(_ int, s *goquery.Selection) {
+		if name, ok := s.Attr("name"); ok && name != "" {
+			fields.Set(name, s.AttrOr("value", ""))
+		}
+	})
+
+	action := loginURL
+	if attr := strings.TrimSpace(form.AttrOr("action", "")); attr != "" {
+		if ref, err := url.Parse(attr); err == nil {
+			action = resp.Request.URL.ResolveReference(ref).String()
+		}
+	}
+	return action, fields, nil
+}
+
+// isLoginPage reports whether a response is the login page, which is how Amion
+// answers a request whose session has expired
+func (c *AmionHTTPClient) isLoginPage(finalURL *url.URL, body []byte) bool {
+	if c.login == nil {
+		return false
+	}
+	if finalURL != nil && strings.TrimSuffix(finalURL.Path, "/") == strings.TrimSuffix(c.login.LoginPath, "/") {
+		return true
+	}
+	return c.hasLoginForm(body)
+}
+
+// hasLoginForm reports whether a page contains the login form
+func (c *AmionHTTPClient) hasLoginForm(body []byte) bool {
+	if len(body) == 0 {
+		return false
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	selector := fmt.Sprintf("form input[type=password][name=%q]", c.login.PasswordField)
+	return doc.Find(selector).Length() > 0
+}
@@ -0,0 +1,210 @@
+package amion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/schedcu/reimplement/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAmionAuth is a minimal Amion login: a form with a CSRF token, a session
+// cookie, and a redirect to the login page when the session is missing or expired.
+type fakeAmionAuth struct {
+	mu       sync.Mutex
+	sessions map[string]bool
+	logins   int32
+	next     int
+}
+
+func newFakeAmionAuth() *fakeAmionAuth {
+	return &fakeAmionAuth{sessions: make(map[string]bool)}
+}
+
+// expireAll invalidates every session, as Amion does after a timeout
+func (f *fakeAmionAuth) expireAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions = make(map[string]bool)
+}
+
+func (f *fakeAmionAuth) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "token-1", Path: "/login"})
+			fmt.Fprint(w, `<html><body><form method="post" action="/login/submit">
+				<input type="hidden" name="csrf" value="token-1">
+				<input type="text" name="username"><input type="password" name="password">
+			</form></body></html>`)
+			return
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/login/submit", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&f.logins, 1)
+		csrf, err := r.Cookie("csrf")
+		if err != nil || csrf.Value != r.FormValue("csrf") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.FormValue("username") != "scheduler" || r.FormValue("password") != "secret" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+
+		f.mu.Lock()
+		f.next++
+		id := fmt.Sprintf("session-%d", f.next)
+		f.sessions[id] = true
+		f.mu.Unlock()
+
+		http.SetCookie(w, &http.Cookie{Name: "AMION_SESSION", Value: id, Path: "/", Expires: time.Now().Add(time.Hour)})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("AMION_SESSION")
+		f.mu.Lock()
+		valid := err == nil && f.sessions[cookie.Value]
+		f.mu.Unlock()
+		if !valid {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		fmt.Fprintf(w, `<html><body><p>%s</p></body></html>`, r.URL.Path)
+	})
+	return mux
+}
+
+func newAuthClient(t *testing.T, server *httptest.Server, password string) *AmionHTTPClient {
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	client.SetLogin(LoginConfig{Username: "scheduler", Password: password})
+	return client
+}
+
+// TestLoginCapturesSession verifies that the client logs in before its first
+// request and reuses the session cookie afterwards.
+func TestLoginCapturesSession(t *testing.T) {
+	fake := newFakeAmionAuth()
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newAuthClient(t, server, "secret")
+
+	for _, path := range []string{"/schedule/2025-11", "/schedule/2025-12"} {
+		doc, err := client.FetchAndParseHTML(path)
+		require.NoError(t, err)
+		assert.Equal(t, path, doc.Find("p").Text())
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fake.logins))
+}
+
+// TestSessionExpiryTriggersRelogin verifies transparent re-authentication when
+// a request is redirected to the login page mid-scrape.
+func TestSessionExpiryTriggersRelogin(t *testing.T) {
+	fake := newFakeAmionAuth()
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newAuthClient(t, server, "secret")
+
+	_, err := client.FetchAndParseHTML("/schedule/2025-11")
+	require.NoError(t, err)
+
+	fake.expireAll()
+
+	// Concurrent requests share a single re-login
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(month int) {
+			defer wg.Done()
+			doc, err := client.FetchAndParseHTML(fmt.Sprintf("/schedule/2025-%02d", month))
+			if err == nil && doc.Find("p").Length() == 0 {
+				err = fmt.Errorf("month %d: got the login page", month)
+			}
+			errs <- err
+		}(i + 1)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fake.logins))
+}
+
+// TestInvalidCredentialsAreNotRetried verifies the typed auth failure and that
+// rejected credentials are not posted again (which could lock the account).
+func TestInvalidCredentialsAreNotRetried(t *testing.T) {
+	fake := newFakeAmionAuth()
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newAuthClient(t, server, "wrong")
+
+	for i := 0; i < 3; i++ {
+		_, err := client.FetchAndParseHTML("/schedule/2025-11")
+		var authErr *AuthError
+		require.ErrorAs(t, err, &authErr)
+		assert.Equal(t, AuthInvalidCredentials, authErr.Kind)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fake.logins))
+
+	// New credentials clear the remembered failure
+	client.SetLogin(LoginConfig{Username: "scheduler", Password: "secret"})
+	require.NoError(t, client.Login(context.Background()))
+}
+
+// TestMissingCredentials verifies the failure when login is configured without a password.
+func TestMissingCredentials(t *testing.T) {
+	fake := newFakeAmionAuth()
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newAuthClient(t, server, "")
+
+	err := client.Login(context.Background())
+	var authErr *AuthError
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, AuthMissingCredentials, authErr.Kind)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&fake.logins))
+}
+
+// TestScrapeAuthFailureIsExternalServiceError verifies that an auth failure
+// during a scrape reaches the validation result as EXTERNAL_SERVICE_ERROR.
+func TestScrapeAuthFailureIsExternalServiceError(t *testing.T) {
+	fake := newFakeAmionAuth()
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	client := newAuthClient(t, server, "wrong")
+	pool := NewGoroutinePool(3)
+	defer pool.Close()
+	scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+
+	results, err := scraper.ScrapeSchedule(time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC), 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, results.MonthsFailed)
+	for _, scrapingErr := range results.Errors {
+		assert.Equal(t, "auth", scrapingErr.ErrorType)
+	}
+
+	result := results.ToValidationResult()
+	require.Len(t, result.Errors, 1, "an auth failure is reported once, not per month")
+	assert.Equal(t, validation.EXTERNAL_SERVICE_ERROR.String(), result.Errors[0].Field)
+	assert.Contains(t, result.Errors[0].Message, string(AuthInvalidCredentials))
+	kinds, ok := result.GetContext("auth_error_kinds")
+	require.True(t, ok)
+	assert.Equal(t, []string{string(AuthInvalidCredentials)}, kinds)
+}
@@ -0,0 +1,159 @@
+package amion
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failed requests that opens the circuit
+	DefaultFailureThreshold = 5
+
+	// DefaultOpenTimeout is how long an open circuit rejects requests before probing again
+	DefaultOpenTimeout = 30 * time.Second
+)
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects every request until the open timeout has passed
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe request through to test the server
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitOpenError is returned instead of making a request while the circuit is open.
+type CircuitOpenError struct {
+	URL     string
+	RetryAt time.Time // When the circuit will let a probe through
+}
+
+// Error implements the error interface for CircuitOpenError.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open, not requesting until %s (URL: %s)", e.RetryAt.Format(time.RFC3339), e.URL)
+}
+
+// CircuitBreaker stops requests to a server that keeps failing, so that when
+// Amion is down every month job fails fast instead of burning its retries.
+// It is thread-safe and meant to be shared by all requests to the same server.
+//
+// The circuit opens after failureThreshold consecutive failures. Once
+// openTimeout has passed it goes half-open and lets one probe request through:
+// a successful probe closes the circuit, a failed one opens it again.
+//
+// Example usage:
+//
+//	breaker := NewCircuitBreaker(5, 30*time.Second)
+//	client.SetCircuitBreaker(breaker)
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+	state            CircuitState
+	failures         int
+	openedAt         time.Time
+	probeInFlight    bool
+	now              func() time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker. A threshold below 1 is treated as 1.
+//
+// Parameters:
+//   - failureThreshold: Consecutive failures that open the circuit
+//   - openTimeout: How long the circuit stays open before a probe is allowed
+//
+// Returns:
+//   - *CircuitBreaker: A new circuit breaker
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		state:            CircuitClosed,
+		now:              time.Now,
+	}
+}
+
+// Allow reports whether a request may be made now. It returns false while the
+// circuit is open, and while half-open if the probe is already in flight.
+// Every allowed request must be followed by RecordSuccess, RecordFailure or Abandon.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.openTimeout {
+			return false
+		}
+		cb.state = CircuitHalfOpen
+		cb.probeInFlight = true
+		return true
+	case CircuitHalfOpen:
+		if cb.probeInFlight {
+			return false
+		}
+		cb.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess records a request the server handled, closing the circuit.
+func (cb *CircuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = CircuitClosed
+	cb.failures = 0
+	cb.probeInFlight = false
+}
+
+// RecordFailure records a failed request. It opens the circuit when the
+// threshold is reached, or immediately if the failed request was the half-open probe.
+func (cb *CircuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.state = CircuitOpen
+		cb.openedAt = cb.now()
+	}
+	cb.probeInFlight = false
+}
+
+// Abandon releases an allowed request that ended without telling us anything
+// about the server, e.g. because its context was cancelled.
+func (cb *CircuitBreaker) Abandon() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probeInFlight = false
+}
+
+// State returns the current state, moving an expired open circuit to half-open.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.openTimeout {
+		cb.state = CircuitHalfOpen
+	}
+	return cb.state
+}
+
+// RetryAt returns when an open circuit will let a probe through.
+func (cb *CircuitBreaker) RetryAt() time.Time {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.openedAt.Add(cb.openTimeout)
+}
@@ -0,0 +1,113 @@
+package amion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCircuitBreakerOpensAfterConsecutiveFailures verifies the failure threshold
+// and that a success in between resets the count.
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	cb := NewCircuitBreaker(3, time.Minute)
+
+	cb.RecordFailure()
+	cb.RecordFailure()
+	cb.RecordSuccess()
+	cb.RecordFailure()
+	cb.RecordFailure()
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.True(t, cb.Allow())
+
+	cb.RecordFailure()
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.False(t, cb.Allow())
+}
+
+// TestCircuitBreakerHalfOpenProbe verifies that after the open timeout a single
+// probe goes through, and its outcome closes or re-opens the circuit.
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Date(2025, time.November, 1, 12, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker(1, 30*time.Second)
+	cb.now = func() time.Time { return now }
+
+	cb.RecordFailure()
+	require.False(t, cb.Allow())
+	assert.Equal(t, now.Add(30*time.Second), cb.RetryAt())
+
+	now = now.Add(31 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	require.True(t, cb.Allow(), "first probe should be allowed")
+	assert.False(t, cb.Allow(), "only one probe at a time")
+
+	// A failed probe opens the circuit again
+	cb.RecordFailure()
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.False(t, cb.Allow())
+
+	// An abandoned probe frees the slot
+	now = now.Add(31 * time.Second)
+	require.True(t, cb.Allow())
+	cb.Abandon()
+	require.True(t, cb.Allow())
+
+	// A successful probe closes it
+	cb.RecordSuccess()
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.True(t, cb.Allow())
+	assert.True(t, cb.Allow())
+}
+
+// TestClientFailsFastWhenCircuitOpen verifies that once the server has failed
+// enough times, the client stops making requests instead of retrying.
+func TestClientFailsFastWhenCircuitOpen(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+	client.SetCircuitBreaker(NewCircuitBreaker(2, time.Minute))
+
+	// Two failed attempts open the circuit and the third retry is never sent
+	_, err = client.FetchAndParseHTML("/schedule/2025-11")
+	var openErr *CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// Every later request fails immediately
+	start := time.Now()
+	_, err = client.FetchAndParseHTML("/schedule/2025-12")
+	require.ErrorAs(t, err, &openErr)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+// TestClientNotFoundDoesNotOpenCircuit verifies that client errors show the
+// server is up and don't count as failures.
+func TestClientNotFoundDoesNotOpenCircuit(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+	breaker := NewCircuitBreaker(1, time.Minute)
+	client.SetCircuitBreaker(breaker)
+
+	for i := 0; i < 3; i++ {
+		_, err = client.FetchAndParseHTML("/missing")
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+	}
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
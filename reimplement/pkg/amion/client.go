@@ -4,14 +4,17 @@
 package amion
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -19,12 +22,20 @@ import (
 )
 
 // AmionHTTPClient handles HTTP communication with the Amion service.
-// It manages session cookies, retries, and timeout handling.
+// It manages session cookies, login, retries, and timeout handling.
 type AmionHTTPClient struct {
 	httpClient *http.Client
 	baseURL    string
 	userAgent  string
 	logger     *zap.SugaredLogger
+	breaker    *CircuitBreaker // Optional: fails fast while Amion is down
+
+	// Session state, see SetLogin
+	authMu     sync.Mutex
+	login      *LoginConfig
+	loggedIn   bool
+	sessionGen int
+	authErr    error // Remembered credential failure, not retried
 }
 
 // HTTPError represents an HTTP error response from the server.
@@ -32,6 +43,7 @@ type HTTPError struct {
 	StatusCode int
 	URL        string
 	Message    string
+	RetryAfter time.Duration // From the Retry-After header, zero if absent
 }
 
 // Error implements the error interface for HTTPError.
@@ -63,10 +75,11 @@ func (e *ParseError) Error() string {
 
 // RetryError represents an error after all retries are exhausted.
 type RetryError struct {
-	URL           string
-	Attempts      int
-	LastError     error
+	URL            string
+	Attempts       int
+	LastError      error
 	LastStatusCode int
+	RetryAfter     time.Duration // Retry-After of the last response, zero if absent
 }
 
 // Error implements the error interface for RetryError.
@@ -125,8 +138,11 @@ func NewAmionHTTPClient(baseURL string) (*AmionHTTPClient, error) {
 	}
 
 	// Create cookie jar for session persistence
-	// Using a simple in-memory jar for cookie management
-	cookieJar := NewSimpleCookieJar()
+	// The standard library jar honours domain, path and expiry (RFC 6265)
+	cookieJar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
 
 	// Configure transport with connection pooling and timeouts
 	transport := &http.Transport{
@@ -170,6 +186,17 @@ func (c *AmionHTTPClient) SetLogger(logger *zap.SugaredLogger) {
 	c.logger = logger
 }
 
+// SetCircuitBreaker makes every request go through the given circuit breaker.
+// Share one breaker between all requests to the same Amion server so that
+// concurrent month jobs stop together when it goes down.
+//
+// Example:
+//
+//	client.SetCircuitBreaker(NewCircuitBreaker(DefaultFailureThreshold, DefaultOpenTimeout))
+func (c *AmionHTTPClient) SetCircuitBreaker(breaker *CircuitBreaker) {
+	c.breaker = breaker
+}
+
 // FetchAndParseHTML fetches a URL and parses the response as HTML.
 // It handles gzip compression, character encoding detection, and implements
 // exponential backoff retry logic for transient failures.
@@ -209,7 +236,7 @@ func (c *AmionHTTPClient) FetchAndParseHTML(urlStr string) (*goquery.Document, e
 //
 // Returns:
 //   - *goquery.Document: Parsed HTML document
-//   - error: HTTPError, NetworkError, ParseError, or RetryError on failure
+//   - error: HTTPError, NetworkError, ParseError, RetryError, or CircuitOpenError on failure
 //
 // Example:
 //
@@ -217,6 +244,117 @@ func (c *AmionHTTPClient) FetchAndParseHTML(urlStr string) (*goquery.Document, e
 //	defer cancel()
 //	doc, err := client.FetchAndParseHTMLWithContext(ctx, url)
 func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlStr string) (*goquery.Document, error) {
+	page, err := c.fetchPage(ctx, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.parsePage(page)
+}
+
+// FetchConditionalWithContext fetches a URL unless it is unchanged since the
+// cached entry was stored. It sends If-None-Match / If-Modified-Since from the
+// entry and treats 304 Not Modified, or a body with the same content hash, as
+// unchanged; in that case the page is not parsed.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout
+//   - url: The URL to fetch (can be absolute or relative to base URL)
+//   - cached: The entry from the last processed fetch, or nil to always fetch
+//
+// Returns:
+//   - *ConditionalResult: The parsed document or Unchanged, plus the entry to cache
+//   - error: Same errors as FetchAndParseHTMLWithContext
+//
+// Example:
+//
+//	cached, _ := cache.Get(url)
+//	result, err := client.FetchConditionalWithContext(ctx, url, &cached)
+//	if err == nil && !result.Unchanged {
+//	    process(result.Document)
+//	    cache.Put(url, result.Entry)
+//	}
+func (c *AmionHTTPClient) FetchConditionalWithContext(ctx context.Context, urlStr string, cached *CacheEntry) (*ConditionalResult, error) {
+	page, err := c.fetchPage(ctx, urlStr, cached)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.notModified {
+		entry := *cached
+		entry.FetchedAt = time.Now()
+		return &ConditionalResult{Unchanged: true, Entry: entry}, nil
+	}
+
+	entry := CacheEntry{
+		ETag:         page.header.Get("ETag"),
+		LastModified: page.header.Get("Last-Modified"),
+		ContentHash:  contentHash(page.body),
+		FetchedAt:    time.Now(),
+	}
+	if cached != nil && cached.ContentHash != "" && cached.ContentHash == entry.ContentHash {
+		return &ConditionalResult{Unchanged: true, Entry: entry}, nil
+	}
+
+	doc, err := c.parsePage(page)
+	if err != nil {
+		return nil, err
+	}
+	return &ConditionalResult{Document: doc, Entry: entry, Body: page.body}, nil
+}
+
+// fetchedPage is a successful response with its body read and decompressed
+type fetchedPage struct {
+	url         string
+	finalURL    *url.URL // After redirects
+	header      http.Header
+	body        []byte
+	notModified bool
+}
+
+// parsePage parses a fetched body with goquery
+func (c *AmionHTTPClient) parsePage(page *fetchedPage) (*goquery.Document, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page.body))
+	if err != nil {
+		// goquery is lenient and handles malformed HTML gracefully
+		// If it still fails, we return the parse error
+		return nil, &ParseError{URL: page.url, Underlying: err}
+	}
+
+	if c.logger != nil {
+		c.logger.Debugw("successfully parsed HTML", "url", page.url)
+	}
+
+	return doc, nil
+}
+
+// fetchPage fetches a page within the login session, if one is configured.
+// Landing on the login page means the session expired mid-scrape: the client
+// logs in again and repeats the request once.
+func (c *AmionHTTPClient) fetchPage(ctx context.Context, urlStr string, cached *CacheEntry) (*fetchedPage, error) {
+	gen, err := c.ensureSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := c.fetchWithRetries(ctx, urlStr, cached)
+	if err != nil || !c.isLoginPage(page.finalURL, page.body) {
+		return page, err
+	}
+
+	if err := c.relogin(ctx, gen); err != nil {
+		return nil, err
+	}
+	page, err = c.fetchWithRetries(ctx, urlStr, cached)
+	if err == nil && c.isLoginPage(page.finalURL, page.body) {
+		return nil, &AuthError{Kind: AuthSessionExpired, URL: page.url, Message: "still on the login page after logging in again"}
+	}
+	return page, err
+}
+
+// fetchWithRetries performs a GET with exponential backoff retries, consulting the
+// circuit breaker before every attempt. When cached is non-nil the request is
+// conditional and a 304 response is returned with notModified set.
+func (c *AmionHTTPClient) fetchWithRetries(ctx context.Context, urlStr string, cached *CacheEntry) (*fetchedPage, error) {
 	// Validate URL
 	if urlStr == "" {
 		return nil, fmt.Errorf("URL cannot be empty")
@@ -230,7 +368,7 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 	// Convert relative URLs to absolute
 	if !parsedURL.IsAbs() {
 		absoluteURL := fmt.Sprintf("%s%s", c.baseURL, urlStr)
-		parsedURL, err = url.Parse(absoluteURL)
+		_, err = url.Parse(absoluteURL)
 		if err != nil {
 			return nil, &ParseError{URL: urlStr, Underlying: err}
 		}
@@ -239,12 +377,17 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 
 	var lastErr error
 	var lastStatusCode int
+	var lastRetryAfter time.Duration
 
 	// Implement exponential backoff retry logic
 	for attempt := 0; attempt <= MaxRetries; attempt++ {
 		// Calculate backoff delay for retries (exponential: 1s, 2s, 4s)
 		if attempt > 0 {
 			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			// Never retry sooner than the server asked us to, within reason
+			if lastRetryAfter > backoff {
+				backoff = min(lastRetryAfter, DefaultMaxRetryAfter)
+			}
 
 			if c.logger != nil {
 				c.logger.Debugw("retrying request", "url", urlStr, "attempt", attempt, "backoff", backoff)
@@ -258,9 +401,18 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 			}
 		}
 
+		// Fail fast while the server is known to be down
+		if c.breaker != nil && !c.breaker.Allow() {
+			if c.logger != nil {
+				c.logger.Warnw("circuit open, skipping request", "url", urlStr)
+			}
+			return nil, &CircuitOpenError{URL: urlStr, RetryAt: c.breaker.RetryAt()}
+		}
+
 		// Create request with context
 		req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 		if err != nil {
+			c.abandonAttempt()
 			lastErr = err
 			continue
 		}
@@ -270,6 +422,14 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 		req.Header.Set("Accept-Encoding", "gzip, deflate")
 		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+		if cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
 
 		if c.logger != nil {
 			c.logger.Debugw("fetching URL", "url", urlStr, "method", "GET")
@@ -282,6 +442,14 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 		if err != nil {
 			lastErr = err
 			lastStatusCode = 0
+			lastRetryAfter = 0
+
+			if ctx.Err() != nil {
+				// Cancelled by us - says nothing about the server
+				c.abandonAttempt()
+				return nil, &NetworkError{URL: urlStr, Underlying: err}
+			}
+			c.recordAttempt(false)
 
 			// Check if this is a temporary error (retryable)
 			if isTemporaryError(err) && attempt < MaxRetries {
@@ -295,14 +463,24 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 			return nil, &NetworkError{URL: urlStr, Underlying: err}
 		}
 
-		// Always read and close the body
-		defer resp.Body.Close()
-
 		lastStatusCode = resp.StatusCode
+		lastRetryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+		// Server errors and throttling count against the circuit, anything else shows it is up
+		c.recordAttempt(resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests)
+
+		if cached != nil && resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			if c.logger != nil {
+				c.logger.Debugw("not modified", "url", urlStr)
+			}
+			return &fetchedPage{url: urlStr, finalURL: resp.Request.URL, header: resp.Header, notModified: true}, nil
+		}
 
 		// Check HTTP status code
 		if resp.StatusCode >= 400 {
 			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
 			lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
 
 			if c.logger != nil {
@@ -322,37 +500,13 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 				StatusCode: resp.StatusCode,
 				URL:        urlStr,
 				Message:    strings.TrimSpace(string(body)),
+				RetryAfter: lastRetryAfter,
 			}
 		}
 
-		// Handle gzip compression
-		var body io.ReadCloser
-		if resp.Header.Get("Content-Encoding") == "gzip" {
-			gzipReader, err := gzip.NewReader(resp.Body)
-			if err != nil {
-				return nil, &ParseError{URL: urlStr, Underlying: fmt.Errorf("failed to create gzip reader: %w", err)}
-			}
-			defer gzipReader.Close()
-			body = gzipReader
-		} else {
-			body = resp.Body
-		}
-
-		// Read the entire response body
-		htmlBytes, err := io.ReadAll(body)
-		if err != nil {
-			lastErr = err
-			if attempt < MaxRetries {
-				continue
-			}
-			return nil, &ParseError{URL: urlStr, Underlying: err}
-		}
-
-		// Parse HTML with goquery
-		doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(htmlBytes)))
+		htmlBytes, err := readBody(resp)
+		resp.Body.Close()
 		if err != nil {
-			// goquery is lenient and handles malformed HTML gracefully
-			// If it still fails, we return the parse error
 			lastErr = err
 			if attempt < MaxRetries {
 				continue
@@ -360,11 +514,7 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 			return nil, &ParseError{URL: urlStr, Underlying: err}
 		}
 
-		if c.logger != nil {
-			c.logger.Debugw("successfully parsed HTML", "url", urlStr, "attempt", attempt+1)
-		}
-
-		return doc, nil
+		return &fetchedPage{url: urlStr, finalURL: resp.Request.URL, header: resp.Header, body: htmlBytes}, nil
 	}
 
 	// All retries exhausted
@@ -373,6 +523,40 @@ func (c *AmionHTTPClient) FetchAndParseHTMLWithContext(ctx context.Context, urlS
 		Attempts:       MaxRetries + 1,
 		LastError:      lastErr,
 		LastStatusCode: lastStatusCode,
+		RetryAfter:     lastRetryAfter,
+	}
+}
+
+// readBody reads the entire response body, handling gzip compression
+func readBody(resp *http.Response) ([]byte, error) {
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		return io.ReadAll(resp.Body)
+	}
+
+	gzipReader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzipReader.Close()
+	return io.ReadAll(gzipReader)
+}
+
+// recordAttempt reports an attempt's outcome to the circuit breaker, if any
+func (c *AmionHTTPClient) recordAttempt(ok bool) {
+	if c.breaker == nil {
+		return
+	}
+	if ok {
+		c.breaker.RecordSuccess()
+	} else {
+		c.breaker.RecordFailure()
+	}
+}
+
+// abandonAttempt releases an attempt that never reached the server
+func (c *AmionHTTPClient) abandonAttempt() {
+	if c.breaker != nil {
+		c.breaker.Abandon()
 	}
 }
 
@@ -419,26 +603,3 @@ func (c *AmionHTTPClient) Close() error {
 	}
 	return nil
 }
-
-// SimpleCookieJar is a simple in-memory cookie jar implementation.
-// It stores cookies by domain.
-type SimpleCookieJar struct {
-	cookies map[string][]*http.Cookie
-}
-
-// NewSimpleCookieJar creates a new SimpleCookieJar.
-func NewSimpleCookieJar() *SimpleCookieJar {
-	return &SimpleCookieJar{
-		cookies: make(map[string][]*http.Cookie),
-	}
-}
-
-// SetCookies stores cookies for a given URL.
-func (j *SimpleCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
-	j.cookies[u.Host] = cookies
-}
-
-// Cookies returns cookies for a given URL.
-func (j *SimpleCookieJar) Cookies(u *url.URL) []*http.Cookie {
-	return j.cookies[u.Host]
-}
@@ -257,7 +257,7 @@ func TestGoroutinePoolIntegrationWithRateLimiter(t *testing.T) {
 	var mu sync.Mutex
 
 	job := func(ctx context.Context) error {
-		limiter.Wait()
+		limiter.Wait(ctx)
 
 		mu.Lock()
 		requestTimes = append(requestTimes, time.Now())
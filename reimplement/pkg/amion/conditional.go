@@ -0,0 +1,89 @@
+package amion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CacheEntry holds what we know about the last version of a page we processed:
+// the validators the server sent and a hash of the body.
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	ContentHash  string // Hex SHA-256 of the (decompressed) body
+	FetchedAt    time.Time
+}
+
+// ConditionalResult is the result of a conditional fetch.
+type ConditionalResult struct {
+	// Document is the parsed page, nil when Unchanged
+	Document *goquery.Document
+
+	// Unchanged is true when the server answered 304 Not Modified or the body
+	// hashes the same as the cached entry
+	Unchanged bool
+
+	// Entry describes the page as fetched, to be stored once it has been processed
+	Entry CacheEntry
+
+	// Body is the raw (decompressed) page, nil when Unchanged
+	Body []byte
+}
+
+// ResponseCache remembers pages that have been scraped so unchanged ones can be
+// skipped. It is thread-safe and in-memory; entries live as long as the cache.
+//
+// Only store an entry after the page has been fully processed, otherwise a page
+// whose processing failed would be reported as unchanged next time.
+type ResponseCache struct {
+	mu      sync.RWMutex
+	entries map[string]CacheEntry
+}
+
+// NewResponseCache creates an empty ResponseCache.
+func NewResponseCache() *ResponseCache {
+	return &ResponseCache{entries: make(map[string]CacheEntry)}
+}
+
+// Get returns the entry for a URL, if any.
+func (c *ResponseCache) Get(url string) (CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[url]
+	return entry, ok
+}
+
+// Put stores the entry for a URL.
+func (c *ResponseCache) Put(url string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[url] = entry
+}
+
+// Delete forgets a URL so it is fetched and processed again.
+func (c *ResponseCache) Delete(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, url)
+}
+
+// Clear forgets every URL.
+func (c *ResponseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]CacheEntry)
+}
+
+// contentHash returns the hex SHA-256 of a page body.
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
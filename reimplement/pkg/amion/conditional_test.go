@@ -0,0 +1,140 @@
+package amion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFetchConditionalETag verifies the If-None-Match round trip and 304 handling.
+func TestFetchConditionalETag(t *testing.T) {
+	var notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Sat, 01 Nov 2025 12:00:00 GMT")
+		fmt.Fprint(w, `<html><body><p>Schedule</p></body></html>`)
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	first, err := client.FetchConditionalWithContext(context.Background(), "/schedule/2025-11", nil)
+	require.NoError(t, err)
+	assert.False(t, first.Unchanged)
+	require.NotNil(t, first.Document)
+	assert.Equal(t, "Schedule", first.Document.Find("p").Text())
+	assert.Equal(t, `"v1"`, first.Entry.ETag)
+	assert.Equal(t, "Sat, 01 Nov 2025 12:00:00 GMT", first.Entry.LastModified)
+	assert.NotEmpty(t, first.Entry.ContentHash)
+
+	second, err := client.FetchConditionalWithContext(context.Background(), "/schedule/2025-11", &first.Entry)
+	require.NoError(t, err)
+	assert.True(t, second.Unchanged)
+	assert.Nil(t, second.Document)
+	assert.Equal(t, first.Entry.ContentHash, second.Entry.ContentHash, "a 304 keeps the cached entry")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+}
+
+// TestFetchConditionalContentHash verifies that without validators an identical
+// body is still detected as unchanged, and a different one is not.
+func TestFetchConditionalContentHash(t *testing.T) {
+	body := `<html><body><p>v1</p></body></html>`
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	first, err := client.FetchConditionalWithContext(context.Background(), "/schedule/2025-11", nil)
+	require.NoError(t, err)
+	require.False(t, first.Unchanged)
+
+	same, err := client.FetchConditionalWithContext(context.Background(), "/schedule/2025-11", &first.Entry)
+	require.NoError(t, err)
+	assert.True(t, same.Unchanged)
+
+	mu.Lock()
+	body = `<html><body><p>v2</p></body></html>`
+	mu.Unlock()
+
+	changed, err := client.FetchConditionalWithContext(context.Background(), "/schedule/2025-11", &first.Entry)
+	require.NoError(t, err)
+	assert.False(t, changed.Unchanged)
+	assert.Equal(t, "v2", changed.Document.Find("p").Text())
+	assert.NotEqual(t, first.Entry.ContentHash, changed.Entry.ContentHash)
+}
+
+// TestScrapeSchedule_UnchangedMonths verifies that a second scrape with the same
+// cache skips unchanged months and reports them.
+func TestScrapeSchedule_UnchangedMonths(t *testing.T) {
+	pages := map[string]string{
+		"/schedule/2025-11": `<html><body><table><tbody>
+			<tr><td>2025-11-15</td><td>Technologist</td><td>07:00</td><td>15:00</td><td>Main Lab</td></tr>
+		</tbody></table></body></html>`,
+		"/schedule/2025-12": `<html><body><table><tbody>
+			<tr><td>2025-12-01</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Read Room A</td></tr>
+		</tbody></table></body></html>`,
+	}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, pages[r.URL.Path])
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// A pool serves a single scrape, so each scrape gets its own scraper sharing the cache
+	cache := NewResponseCache()
+	newScraper := func() *AmionScraper {
+		pool := NewGoroutinePool(2)
+		t.Cleanup(func() { pool.Close() })
+		scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+		scraper.SetResponseCache(cache)
+		return scraper
+	}
+	startDate := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	results, err := newScraper().ScrapeSchedule(startDate, 2)
+	require.NoError(t, err)
+	assert.Len(t, results.Shifts, 2)
+	assert.Empty(t, results.UnchangedMonths)
+	assert.Equal(t, 2, results.MonthsProcessed)
+
+	mu.Lock()
+	pages["/schedule/2025-12"] = `<html><body><table><tbody>
+		<tr><td>2025-12-01</td><td>Radiologist</td><td>08:00</td><td>20:00</td><td>Read Room A</td></tr>
+	</tbody></table></body></html>`
+	mu.Unlock()
+
+	results, err = newScraper().ScrapeSchedule(startDate, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-11"}, results.UnchangedMonths)
+	assert.Equal(t, 1, results.MonthsProcessed)
+	require.Len(t, results.Shifts, 1)
+	assert.Equal(t, "08:00", results.Shifts[0].StartTime)
+}
@@ -0,0 +1,104 @@
+package amion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DedupeField is a shift attribute that can be part of the de-duplication key
+type DedupeField string
+
+const (
+	DedupeDate      DedupeField = "date"
+	DedupeShiftType DedupeField = "shift_type"
+	DedupeStartTime DedupeField = "start_time"
+	DedupeEndTime   DedupeField = "end_time"
+	DedupeLocation  DedupeField = "location"
+	DedupeStaff     DedupeField = "staff"
+)
+
+// DedupeKey lists the fields two shifts must share to count as duplicates.
+// Comparison ignores case and surrounding whitespace, and staff order.
+type DedupeKey []DedupeField
+
+// DefaultDedupeKey treats shifts as duplicates only when every field matches,
+// so same-day shifts of one type at different times, locations or with
+// different staff are all kept.
+var DefaultDedupeKey = DedupeKey{DedupeDate, DedupeShiftType, DedupeStartTime, DedupeEndTime, DedupeLocation, DedupeStaff}
+
+// ParseDedupeKey parses a comma-separated field list such as
+// "date,shift_type,location".
+func ParseDedupeKey(s string) (DedupeKey, error) {
+	var key DedupeKey
+	for _, part := range strings.Split(s, ",") {
+		key = append(key, DedupeField(strings.TrimSpace(part)))
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Validate checks the key is non-empty, includes the date (shifts on
+// different days are never duplicates) and has only known fields.
+func (k DedupeKey) Validate() error {
+	if len(k) == 0 {
+		return fmt.Errorf("dedupe key must have at least one field")
+	}
+	hasDate := false
+	for _, field := range k {
+		switch field {
+		case DedupeDate:
+			hasDate = true
+		case DedupeShiftType, DedupeStartTime, DedupeEndTime, DedupeLocation, DedupeStaff:
+		default:
+			return fmt.Errorf("unknown dedupe field %q", field)
+		}
+	}
+	if !hasDate {
+		return fmt.Errorf("dedupe key must include %q", DedupeDate)
+	}
+	return nil
+}
+
+// Of returns the shift's key value
+func (k DedupeKey) Of(shift *RawAmionShift) string {
+	parts := make([]string, len(k))
+	for i, field := range k {
+		switch field {
+		case DedupeDate:
+			parts[i] = shift.Date
+		case DedupeShiftType:
+			parts[i] = shift.ShiftType
+		case DedupeStartTime:
+			parts[i] = shift.StartTime
+		case DedupeEndTime:
+			parts[i] = shift.EndTime
+		case DedupeLocation:
+			parts[i] = shift.Location
+		case DedupeStaff:
+			names := make([]string, len(shift.StaffNames))
+			for j, name := range shift.StaffNames {
+				names[j] = strings.ToLower(strings.TrimSpace(name))
+			}
+			sort.Strings(names)
+			parts[i] = strings.Join(names, ";")
+			continue
+		}
+		parts[i] = strings.ToLower(strings.TrimSpace(parts[i]))
+	}
+	return strings.Join(parts, "|")
+}
+
+// ShiftRowRef locates the table row a shift was extracted from
+type ShiftRowRef struct {
+	Month    string // YYYY-MM format
+	URL      string
+	RowIndex int
+}
+
+// String returns e.g. "/schedule/2025-11 row 3"
+func (r ShiftRowRef) String() string {
+	return fmt.Sprintf("%s row %d", r.URL, r.RowIndex)
+}
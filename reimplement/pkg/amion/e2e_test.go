@@ -0,0 +1,155 @@
+package amion
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/reimplement/internal/entity"
+	"github.com/schedcu/reimplement/pkg/amion/amiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// End-to-end scrapes against the fake Amion site in amiontest
+
+// e2eShift is the part of a scraped shift the fake site controls
+type e2eShift struct {
+	Date, ShiftType, Start, End, Location string
+	Required                              int
+	Staff                                 string
+	OpenSlots                             int
+}
+
+func expectedShifts(shifts []amiontest.Shift) []e2eShift {
+	out := make([]e2eShift, 0, len(shifts))
+	for _, s := range shifts {
+		out = append(out, e2eShift{s.Date, s.Name, s.Start, s.End, s.Location, s.Staffing, strings.Join(s.Staff, ";"), s.OpenSlots})
+	}
+	return sortE2E(out)
+}
+
+func scrapedShifts(shifts []RawAmionShift, withRequired bool) []e2eShift {
+	out := make([]e2eShift, 0, len(shifts))
+	for _, s := range shifts {
+		required := s.RequiredStaffing
+		if !withRequired {
+			// Layouts without a staffing column: the slots shown are the requirement
+			required = len(s.StaffNames) + s.OpenSlots
+		}
+		out = append(out, e2eShift{s.Date, s.ShiftType, s.StartTime, s.EndTime, s.Location, required, strings.Join(s.StaffNames, ";"), s.OpenSlots})
+	}
+	return sortE2E(out)
+}
+
+func sortE2E(shifts []e2eShift) []e2eShift {
+	sort.Slice(shifts, func(i, j int) bool {
+		if shifts[i].Date != shifts[j].Date {
+			return shifts[i].Date < shifts[j].Date
+		}
+		return shifts[i].ShiftType < shifts[j].ShiftType
+	})
+	return shifts
+}
+
+func newE2EScraper(t *testing.T, baseURL string, workers int) (*AmionScraper, *AmionHTTPClient) {
+	t.Helper()
+	client, err := NewAmionHTTPClient(baseURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	pool := NewGoroutinePool(workers)
+	t.Cleanup(func() { pool.Close() })
+
+	return NewAmionScraper(client, pool, NewRateLimiter(10*time.Millisecond), DefaultSelectors()), client
+}
+
+var e2eStart = time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+func TestE2E_LoginExpiringSessionsAndServerErrors(t *testing.T) {
+	srv := amiontest.NewServer(amiontest.Config{
+		Seed:     11,
+		Markup:   amiontest.MarkupLinks,
+		Username: "scheduler", Password: "s3cret",
+		SessionRequests: 2,
+		ErrorRate:       1, MaxErrorsPerPage: 1, // every page fails once, then succeeds
+	})
+	defer srv.Close()
+
+	// One worker: concurrent workers would share each two-page session
+	scraper, client := newE2EScraper(t, srv.URL, 1)
+	client.SetLogin(LoginConfig{Username: "scheduler", Password: "s3cret"})
+
+	results, err := scraper.ScrapeSchedule(e2eStart, 3)
+	require.NoError(t, err)
+	require.Empty(t, results.Errors, results.FormattedErrors())
+	assert.Equal(t, 3, results.MonthsProcessed)
+
+	want := expectedShifts(srv.Site.Shifts(e2eStart, e2eStart.AddDate(0, 3, -1)))
+	assert.Equal(t, want, scrapedShifts(results.Shifts, true))
+
+	stats := srv.Site.Stats()
+	assert.Equal(t, 3, stats.ServerErrors)
+	assert.Equal(t, 2, stats.ExpiredSessions, "each session covers one page and its retry")
+	assert.Equal(t, 3, stats.Logins, "one re-login per expired session")
+
+	// Map the staff onto assignments the way the import does
+	people := make(map[string]uuid.UUID)
+	for _, name := range amiontest.DefaultRoster().Staff {
+		people[name] = uuid.New()
+	}
+	resolver := NewStaticPersonResolver(people)
+	mapper := NewAssignmentMapper()
+	versionID, userID := uuid.New(), uuid.New()
+
+	assignments, named := 0, 0
+	for _, raw := range results.Shifts {
+		shift := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: versionID, ShiftType: raw.ShiftType}
+		mapped, err := mapper.MapStaffToAssignments(context.Background(), raw, resolver, shift, versionID, userID, nil)
+		require.NoError(t, err)
+		assert.Empty(t, mapped.UnresolvedNames)
+		assignments += len(mapped.Assignments)
+		named += len(raw.StaffNames)
+	}
+	assert.Equal(t, named, assignments)
+	assert.Positive(t, assignments)
+}
+
+func TestE2E_Throttling(t *testing.T) {
+	srv := amiontest.NewServer(amiontest.Config{Seed: 12, RateLimit: 2, RateWindow: 500 * time.Millisecond})
+	defer srv.Close()
+
+	scraper, _ := newE2EScraper(t, srv.URL, 3)
+	results, err := scraper.ScrapeSchedule(e2eStart, 3)
+	require.NoError(t, err)
+	require.Empty(t, results.Errors, results.FormattedErrors())
+
+	assert.Equal(t, expectedShifts(srv.Site.Shifts(e2eStart, e2eStart.AddDate(0, 3, -1))), scrapedShifts(results.Shifts, true))
+	assert.Positive(t, srv.Site.Stats().Throttled, "the limiter was pushed back and recovered")
+}
+
+func TestE2E_GridMarkupWeekPages(t *testing.T) {
+	srv := amiontest.NewServer(amiontest.Config{Seed: 13, Markup: amiontest.MarkupGrid, Latency: 20 * time.Millisecond})
+	defer srv.Close()
+
+	profiles, err := LoadSelectorProfiles(strings.NewReader(amiontest.GridProfileJSON))
+	require.NoError(t, err)
+
+	scraper, _ := newE2EScraper(t, srv.URL, 3)
+	scraper.SetSelectorProfiles(profiles)
+	require.NoError(t, scraper.SetURLTemplate(URLTemplate{
+		Pattern: "/cgi-bin/ocs?Syr={yyyy}&Mo={m}&Dy={d}&Days=7",
+		View:    ViewWeek,
+	}))
+
+	from, to := time.Date(2025, 11, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 25, 0, 0, 0, 0, time.UTC)
+	results, err := scraper.ScrapeWindow(from, to)
+	require.NoError(t, err)
+	require.Empty(t, results.Errors, results.FormattedErrors())
+
+	assert.Equal(t, expectedShifts(srv.Site.Shifts(from, to)), scrapedShifts(results.Shifts, false),
+		"week pages are trimmed to the window")
+}
@@ -0,0 +1,229 @@
+package amion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// PageFingerprint summarises the structure of a schedule page, ignoring its
+// data: where the shift table sits in the document, its header labels, and
+// how many cells its data rows usually have. Amion markup changes show up here even
+// when the selectors still match something.
+type PageFingerprint struct {
+	TablePath string   `json:"table_path"` // e.g. "html>body>div.schedule>table.grid"
+	Headers   []string `json:"headers"`    // Lower-cased header cell text
+	Columns   int      `json:"columns"`    // Most common data-row cell count, 0 without data rows
+	Hash      string   `json:"hash"`       // SHA-256 of the fields above
+}
+
+// SelectorDriftError is returned when a page no longer looks like the markup
+// its selectors were written for. Its data can't be trusted, so the scrape is
+// halted rather than imported.
+type SelectorDriftError struct {
+	URL      string
+	Profile  string           // Profile ID, empty if no profile matched
+	Expected *PageFingerprint // Last known-good fingerprint, nil if none
+	Actual   PageFingerprint
+	Reason   string
+}
+
+// Error implements error
+func (e *SelectorDriftError) Error() string {
+	if e.Profile == "" {
+		return fmt.Sprintf("SELECTOR_DRIFT: %s: %s", e.URL, e.Reason)
+	}
+	return fmt.Sprintf("SELECTOR_DRIFT: %s (profile %s): %s", e.URL, e.Profile, e.Reason)
+}
+
+// ComputeFingerprint fingerprints the page structure around the shift table
+// the selectors point at.
+func ComputeFingerprint(doc *goquery.Document, sel *AmionSelectors) PageFingerprint {
+	var fp PageFingerprint
+
+	rows := doc.Find(sel.ShiftRowSelector)
+	table := rows.First().Closest("table")
+	if table.Length() == 0 && sel.ShiftTableSelector != "" {
+		table = doc.Find(sel.ShiftTableSelector).First()
+	}
+
+	if table.Length() > 0 {
+		fp.TablePath = nodePath(table)
+		table.Find("th").Each(func(i int, th *goquery.Selection) {
+			fp.Headers = append(fp.Headers, strings.ToLower(strings.Join(strings.Fields(th.Text()), " ")))
+		})
+	}
+
+	// The most common cell count, so the odd colspan note row doesn't count
+	counts := make(map[int]int)
+	rows.Each(func(i int, row *goquery.Selection) {
+		if row.Find("th").Length() > 0 {
+			return
+		}
+		if cells := row.Find("td").Length(); cells > 0 {
+			counts[cells]++
+			if counts[cells] > counts[fp.Columns] || (counts[cells] == counts[fp.Columns] && cells > fp.Columns) {
+				fp.Columns = cells
+			}
+		}
+	})
+
+	fp.Hash = fp.computeHash()
+	return fp
+}
+
+// Matches reports whether the page has the same structure as a known-good
+// fingerprint. Columns are only compared when both pages had data rows, so a
+// month without shifts isn't mistaken for drift.
+func (f PageFingerprint) Matches(known PageFingerprint) bool {
+	if f.TablePath != known.TablePath || strings.Join(f.Headers, "|") != strings.Join(known.Headers, "|") {
+		return false
+	}
+	if f.Columns == 0 || known.Columns == 0 {
+		return true
+	}
+	return f.Columns == known.Columns
+}
+
+// HasRows reports whether the page had any data rows
+func (f PageFingerprint) HasRows() bool {
+	return f.Columns > 0
+}
+
+// String returns a compact description for logs and error messages
+func (f PageFingerprint) String() string {
+	return fmt.Sprintf("table=%s headers=[%s] columns=%d", f.TablePath, strings.Join(f.Headers, ","), f.Columns)
+}
+
+func (f PageFingerprint) computeHash() string {
+	sum := sha256.Sum256([]byte(f.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// nodePath returns the element's ancestry as "tag.class1.class2>..." from the
+// root down. IDs are left out because some pages generate them.
+func nodePath(s *goquery.Selection) string {
+	var parts []string
+	for n := s.First(); n.Length() > 0; n = n.Parent() {
+		part := goquery.NodeName(n)
+		if strings.HasPrefix(part, "#") { // Reached the document node
+			break
+		}
+		if class, ok := n.Attr("class"); ok {
+			classes := strings.Fields(class)
+			sort.Strings(classes)
+			for _, c := range classes {
+				part += "." + c
+			}
+		}
+		parts = append([]string{part}, parts...)
+	}
+	return strings.Join(parts, ">")
+}
+
+// FingerprintStore remembers the last known-good fingerprint per selector
+// profile ID.
+type FingerprintStore interface {
+	KnownGood(profileID string) (PageFingerprint, bool)
+	RecordKnownGood(profileID string, fp PageFingerprint) error
+}
+
+// MemoryFingerprintStore is an in-memory FingerprintStore, safe for concurrent use.
+type MemoryFingerprintStore struct {
+	mu           sync.RWMutex
+	fingerprints map[string]PageFingerprint
+}
+
+// NewMemoryFingerprintStore creates an empty store.
+func NewMemoryFingerprintStore() *MemoryFingerprintStore {
+	return &MemoryFingerprintStore{fingerprints: make(map[string]PageFingerprint)}
+}
+
+// KnownGood implements FingerprintStore.
+func (s *MemoryFingerprintStore) KnownGood(profileID string) (PageFingerprint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fp, ok := s.fingerprints[profileID]
+	return fp, ok
+}
+
+// RecordKnownGood implements FingerprintStore.
+func (s *MemoryFingerprintStore) RecordKnownGood(profileID string, fp PageFingerprint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fingerprints[profileID] = fp
+	return nil
+}
+
+// Forget drops a profile's known-good fingerprint, accepting whatever markup
+// is seen next. Use after verifying a markup change by hand.
+func (s *MemoryFingerprintStore) Forget(profileID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.fingerprints, profileID)
+}
+
+// FileFingerprintStore is a FingerprintStore persisted as a JSON file, so
+// known-good fingerprints survive between scrape runs.
+type FileFingerprintStore struct {
+	MemoryFingerprintStore
+	path string
+}
+
+// NewFileFingerprintStore loads the store at path. A missing file starts empty.
+func NewFileFingerprintStore(path string) (*FileFingerprintStore, error) {
+	store := &FileFingerprintStore{
+		MemoryFingerprintStore: MemoryFingerprintStore{fingerprints: make(map[string]PageFingerprint)},
+		path:                   path,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint store: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.fingerprints); err != nil {
+		return nil, fmt.Errorf("failed to parse fingerprint store: %w", err)
+	}
+	return store, nil
+}
+
+// RecordKnownGood implements FingerprintStore, writing the file through.
+func (s *FileFingerprintStore) RecordKnownGood(profileID string, fp PageFingerprint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fingerprints[profileID] = fp
+	return s.saveLocked()
+}
+
+// Forget drops a profile's known-good fingerprint and saves the file.
+func (s *FileFingerprintStore) Forget(profileID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.fingerprints, profileID)
+	return s.saveLocked()
+}
+
+func (s *FileFingerprintStore) saveLocked() error {
+	data, err := json.MarshalIndent(s.fingerprints, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fingerprint store: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write fingerprint store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write fingerprint store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package amion
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxSlowdown caps how far the limiter stretches its interval after
+	// throttling responses, relative to the base interval
+	DefaultMaxSlowdown = 16
+
+	// DefaultRecoveryRate is the fraction of the extra delay removed after each
+	// successful request, so the limiter speeds up gradually after a slow-down
+	DefaultRecoveryRate = 0.25
+
+	// DefaultMaxRetryAfter caps how long a server's Retry-After may pause the
+	// limiter, so a bogus or hostile value cannot stall a scrape for hours
+	DefaultMaxRetryAfter = 5 * time.Minute
+)
+
+// RateLimiterConfig configures a token bucket RateLimiter.
+type RateLimiterConfig struct {
+	// Interval is the time it takes to refill one token (the steady-state
+	// minimum interval between requests)
+	Interval time.Duration
+
+	// Burst is the maximum number of tokens held, i.e. how many requests may
+	// go out back to back after an idle period. Values below 1 are treated as 1.
+	Burst int
+
+	// MaxInterval caps the interval after repeated throttling.
+	// Zero means Interval * DefaultMaxSlowdown.
+	MaxInterval time.Duration
+
+	// RecoveryRate is the fraction of the extra delay removed after each success.
+	// Zero means DefaultRecoveryRate.
+	RecoveryRate float64
+
+	// MaxRetryAfter caps the pause Backoff takes from a Retry-After value.
+	// Zero means DefaultMaxRetryAfter.
+	MaxRetryAfter time.Duration
+}
+
+// RateLimiter implements a token bucket that adapts to server pushback.
+// It is thread-safe and can be used concurrently by multiple goroutines.
+//
+// Tokens refill at one per interval up to the burst size, and Wait() takes one
+// token, blocking until it is available or the context is done. When the server
+// throttles (429, 503, or a Retry-After header), Backoff() doubles the interval
+// and optionally pauses all requests; Success() then shrinks the interval back
+// toward its base a little at a time.
+//
+// Example usage:
+//
+//	limiter := NewRateLimiter(1 * time.Second)
+//	limiter.Wait(ctx) // Returns immediately on first call
+//	limiter.Wait(ctx) // Waits ~1 second
+//	limiter.Wait(ctx) // Waits ~1 second
+type RateLimiter struct {
+	mu           sync.Mutex
+	baseInterval time.Duration
+	interval     time.Duration
+	maxInterval  time.Duration
+	maxPause     time.Duration
+	recoveryRate float64
+	burst        float64
+	tokens       float64
+	lastRefill   time.Time
+	pausedUntil  time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter with the specified minimum
+// interval between requests and no burst.
+//
+// Parameters:
+//   - minInterval: The minimum time to wait between requests
+//
+// Returns:
+//   - *RateLimiter: A new rate limiter instance
+//
+// Example:
+//
+//	limiter := NewRateLimiter(1 * time.Second)
+func NewRateLimiter(minInterval time.Duration) *RateLimiter {
+	return NewRateLimiterWithConfig(RateLimiterConfig{Interval: minInterval, Burst: 1})
+}
+
+// NewRateLimiterWithConfig creates a new RateLimiter from a full configuration.
+//
+// Example:
+//
+//	limiter := NewRateLimiterWithConfig(RateLimiterConfig{
+//	    Interval: 1 * time.Second,
+//	    Burst:    3,
+//	})
+func NewRateLimiterWithConfig(config RateLimiterConfig) *RateLimiter {
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	maxInterval := config.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = config.Interval * DefaultMaxSlowdown
+	}
+	if maxInterval < config.Interval {
+		maxInterval = config.Interval
+	}
+	maxPause := config.MaxRetryAfter
+	if maxPause <= 0 {
+		maxPause = DefaultMaxRetryAfter
+	}
+	recoveryRate := config.RecoveryRate
+	if recoveryRate <= 0 || recoveryRate > 1 {
+		recoveryRate = DefaultRecoveryRate
+	}
+
+	return &RateLimiter{
+		baseInterval: config.Interval,
+		interval:     config.Interval,
+		maxInterval:  maxInterval,
+		maxPause:     maxPause,
+		recoveryRate: recoveryRate,
+		burst:        float64(burst),
+		tokens:       float64(burst), // Allow the first burst immediately
+		lastRefill:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available, then takes it.
+// It is safe to call from multiple goroutines concurrently.
+//
+// It returns ctx.Err() without taking a token if the context is cancelled
+// while waiting.
+//
+// Example:
+//
+//	limiter := NewRateLimiter(1 * time.Second)
+//	limiter.Wait(ctx) // Returns immediately
+//	limiter.Wait(ctx) // Blocks for ~1 second
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rl.mu.Lock()
+		now := time.Now()
+		rl.refill(now)
+
+		var delay time.Duration
+		if now.Before(rl.pausedUntil) {
+			delay = rl.pausedUntil.Sub(now)
+		} else if rl.tokens >= 1 {
+			rl.tokens--
+			rl.mu.Unlock()
+			return nil
+		} else {
+			delay = time.Duration((1 - rl.tokens) * float64(rl.interval))
+		}
+		rl.mu.Unlock()
+
+		// Sleep without holding the lock, then compete for the token again
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// refill adds the tokens earned since the last refill. Must be called with mu held.
+func (rl *RateLimiter) refill(now time.Time) {
+	if rl.interval <= 0 {
+		rl.tokens = rl.burst
+		rl.lastRefill = now
+		return
+	}
+
+	elapsed := now.Sub(rl.lastRefill)
+	if elapsed <= 0 {
+		return
+	}
+	rl.tokens += float64(elapsed) / float64(rl.interval)
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+	rl.lastRefill = now
+}
+
+// Backoff slows the limiter down after the server pushed back: the interval
+// doubles (up to the maximum), the saved-up burst is dropped, and if retryAfter
+// is positive no request is let through until it (capped at MaxRetryAfter)
+// has passed.
+func (rl *RateLimiter) Backoff(retryAfter time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.refill(now)
+
+	rl.interval *= 2
+	if rl.interval > rl.maxInterval {
+		rl.interval = rl.maxInterval
+	}
+	if rl.tokens > 0 {
+		rl.tokens = 0
+	}
+
+	if retryAfter > rl.maxPause {
+		retryAfter = rl.maxPause
+	}
+	if retryAfter > 0 {
+		if until := now.Add(retryAfter); until.After(rl.pausedUntil) {
+			rl.pausedUntil = until
+		}
+	}
+}
+
+// Success records a request the server accepted, removing part of any extra
+// delay added by Backoff so the limiter recovers gradually.
+func (rl *RateLimiter) Success() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.interval <= rl.baseInterval {
+		return
+	}
+
+	rl.refill(time.Now())
+	extra := rl.interval - rl.baseInterval
+	reduced := time.Duration(float64(extra) * (1 - rl.recoveryRate))
+	if reduced < time.Millisecond {
+		reduced = 0
+	}
+	rl.interval = rl.baseInterval + reduced
+}
+
+// ObserveResponse adapts the limiter to a server response: 429, 503, or any
+// Retry-After header triggers Backoff, other non-error responses count as a
+// Success.
+func (rl *RateLimiter) ObserveResponse(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	retryAfter := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if IsThrottleStatus(resp.StatusCode) || retryAfter > 0 {
+		rl.Backoff(retryAfter)
+		return
+	}
+	if resp.StatusCode < 400 {
+		rl.Success()
+	}
+}
+
+// Interval returns the current interval between tokens, including any slow-down.
+func (rl *RateLimiter) Interval() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return rl.interval
+}
+
+// Reset resets the rate limiter so that the next Wait() call will return
+// immediately. Any slow-down and pause from Backoff is cleared as well.
+//
+// Example:
+//
+//	limiter := NewRateLimiter(1 * time.Second)
+//	limiter.Wait(ctx)
+//	limiter.Reset()
+//	limiter.Wait(ctx) // Returns immediately due to reset
+func (rl *RateLimiter) Reset() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.interval = rl.baseInterval
+	rl.tokens = rl.burst
+	rl.lastRefill = time.Now()
+	rl.pausedUntil = time.Time{}
+}
+
+// IsThrottleStatus reports whether an HTTP status means the server wants us to slow down.
+func IsThrottleStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// ParseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP date. It returns 0 if the value is empty, invalid, or
+// already in the past.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,228 @@
+package amion
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRateLimiterWaitEnforcesMinimumDelay tests that Wait() blocks until
+// at least 1 second has elapsed since the last request.
+func TestRateLimiterWaitEnforcesMinimumDelay(t *testing.T) {
+	limiter := NewRateLimiter(1 * time.Second)
+
+	// First request should complete immediately
+	start := time.Now()
+	limiter.Wait(context.Background())
+	duration := time.Since(start)
+	assert.Less(t, duration, 100*time.Millisecond, "first Wait() should be immediate")
+
+	// Second request should wait ~1 second
+	start = time.Now()
+	limiter.Wait(context.Background())
+	duration = time.Since(start)
+	assert.GreaterOrEqual(t, duration, 900*time.Millisecond, "second Wait() should wait ~1 second")
+	assert.Less(t, duration, 1200*time.Millisecond, "second Wait() should not wait much longer than 1 second")
+}
+
+// TestRateLimiterMultipleRequests verifies consistent 1-second delays between requests.
+func TestRateLimiterMultipleRequests(t *testing.T) {
+	limiter := NewRateLimiter(1 * time.Second)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		limiter.Wait(context.Background())
+	}
+	totalDuration := time.Since(start)
+
+	// Should have waited approximately 4 seconds for 5 requests (first is immediate)
+	expectedMin := 3800 * time.Millisecond
+	expectedMax := 5500 * time.Millisecond
+	assert.GreaterOrEqual(t, totalDuration, expectedMin, "5 requests should take at least ~4 seconds")
+	assert.Less(t, totalDuration, expectedMax, "5 requests should not take much longer than ~4 seconds")
+}
+
+// TestRateLimiterThreadSafety verifies the rate limiter works correctly with concurrent access.
+func TestRateLimiterThreadSafety(t *testing.T) {
+	limiter := NewRateLimiter(100 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	var requestCount int32
+	var lastRequestTime int64
+
+	// Launch 10 goroutines, each making 3 requests
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 3; j++ {
+				limiter.Wait(context.Background())
+				atomic.AddInt32(&requestCount, 1)
+				now := time.Now().UnixNano()
+				atomic.StoreInt64(&lastRequestTime, now)
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, int32(30), requestCount, "should have completed all 30 requests")
+}
+
+// TestRateLimiterResetAfterWait verifies that the rate limiter can be reset.
+func TestRateLimiterResetAfterWait(t *testing.T) {
+	limiter := NewRateLimiter(100 * time.Millisecond)
+
+	limiter.Wait(context.Background())
+	limiter.Reset()
+
+	// After reset, next Wait() should be immediate
+	start := time.Now()
+	limiter.Wait(context.Background())
+	duration := time.Since(start)
+	assert.Less(t, duration, 50*time.Millisecond, "after reset, Wait() should be immediate")
+}
+
+// TestRateLimiterDifferentIntervals tests the rate limiter with different intervals.
+func TestRateLimiterDifferentIntervals(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"100ms", 100 * time.Millisecond},
+		{"500ms", 500 * time.Millisecond},
+		{"1s", 1 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.interval)
+
+			// First wait is immediate
+			start := time.Now()
+			limiter.Wait(context.Background())
+			duration := time.Since(start)
+			assert.Less(t, duration, 50*time.Millisecond)
+
+			// Second wait should respect the interval
+			start = time.Now()
+			limiter.Wait(context.Background())
+			duration = time.Since(start)
+			expectedMin := tt.interval - 50*time.Millisecond
+			assert.GreaterOrEqual(t, duration, expectedMin)
+		})
+	}
+}
+
+// TestRateLimiterWaitReturnsOnCancellation verifies that a cancelled context
+// interrupts the wait instead of sleeping out the interval.
+func TestRateLimiterWaitReturnsOnCancellation(t *testing.T) {
+	limiter := NewRateLimiter(10 * time.Second)
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "Wait() should return soon after cancellation")
+
+	// An already cancelled context never takes a token
+	limiter.Reset()
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	assert.ErrorIs(t, limiter.Wait(cancelled), context.Canceled)
+	assert.NoError(t, limiter.Wait(context.Background()), "token should still be available")
+}
+
+// TestRateLimiterBurst verifies that up to Burst requests go out back to back.
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiterWithConfig(RateLimiterConfig{Interval: 200 * time.Millisecond, Burst: 3})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(context.Background()))
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "burst should not wait")
+
+	start = time.Now()
+	require.NoError(t, limiter.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond, "request after the burst should wait for a token")
+}
+
+// TestRateLimiterBackoffAndRecovery verifies the slow-down after throttling
+// and the gradual return to the base interval.
+func TestRateLimiterBackoffAndRecovery(t *testing.T) {
+	limiter := NewRateLimiterWithConfig(RateLimiterConfig{
+		Interval:     100 * time.Millisecond,
+		MaxInterval:  300 * time.Millisecond,
+		RecoveryRate: 0.5,
+	})
+
+	limiter.Backoff(0)
+	assert.Equal(t, 200*time.Millisecond, limiter.Interval())
+	limiter.Backoff(0)
+	assert.Equal(t, 300*time.Millisecond, limiter.Interval(), "interval should be capped")
+
+	limiter.Success()
+	assert.Equal(t, 200*time.Millisecond, limiter.Interval())
+	limiter.Success()
+	assert.Equal(t, 150*time.Millisecond, limiter.Interval())
+	for i := 0; i < 20; i++ {
+		limiter.Success()
+	}
+	assert.Equal(t, 100*time.Millisecond, limiter.Interval(), "should recover to the base interval")
+
+	limiter.Backoff(0)
+	limiter.Reset()
+	assert.Equal(t, 100*time.Millisecond, limiter.Interval(), "Reset should clear the slow-down")
+}
+
+// TestRateLimiterRetryAfterPausesRequests verifies that Retry-After holds back
+// every request until it has passed.
+func TestRateLimiterRetryAfterPausesRequests(t *testing.T) {
+	limiter := NewRateLimiterWithConfig(RateLimiterConfig{Interval: 10 * time.Millisecond, Burst: 5})
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "1")
+	limiter.ObserveResponse(resp)
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond, "Wait() should honour Retry-After")
+	assert.Equal(t, 20*time.Millisecond, limiter.Interval())
+
+	limiter.ObserveResponse(&http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	assert.Less(t, limiter.Interval(), 20*time.Millisecond, "a success should start the recovery")
+}
+
+// TestRateLimiterCapsRetryAfter verifies that a huge Retry-After only pauses
+// the limiter for MaxRetryAfter.
+func TestRateLimiterCapsRetryAfter(t *testing.T) {
+	limiter := NewRateLimiterWithConfig(RateLimiterConfig{Interval: time.Millisecond, MaxRetryAfter: 50 * time.Millisecond})
+	limiter.Backoff(24 * time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx), "Wait() should not honour more than MaxRetryAfter")
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+// TestParseRetryAfter tests both Retry-After formats.
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.November, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 120*time.Second, ParseRetryAfter("120", now))
+	assert.Equal(t, 30*time.Second, ParseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("soon", now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("-5", now))
+}
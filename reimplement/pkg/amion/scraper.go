@@ -0,0 +1,800 @@
+package amion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/schedcu/reimplement/internal/validation"
+)
+
+// MaxThrottleRetries is how many times a month is requested again after a 429
+const MaxThrottleRetries = 2
+
+// ScrapedShifts represents the result of scraping multiple months of data
+type ScrapedShifts struct {
+	// Shifts contains all successfully extracted shifts
+	Shifts []RawAmionShift
+
+	// Errors contains any errors that occurred during scraping
+	Errors []ScrapingError
+
+	// Warnings contains non-fatal issues like duplicates
+	Warnings []ScrapingWarning
+
+	// DuplicateCount tracks the number of duplicate shifts detected
+	DuplicateCount int
+
+	// MonthsProcessed tracks how many months were successfully scraped
+	MonthsProcessed int
+
+	// MonthsFailed tracks how many months failed to scrape
+	MonthsFailed int
+
+	// UnchangedMonths lists pages (by label, see ScrapingError.Month) skipped
+	// because they haven't changed since the last scrape with the same
+	// response cache. Their shifts are not in Shifts; keep the previously
+	// imported ones.
+	UnchangedMonths []string
+
+	// Pages are the pages fetched and extracted, in date order. Failed and
+	// unchanged pages are left out.
+	Pages []ScrapedPage
+}
+
+// ScrapedPage is one fetched page, kept so callers can archive the raw
+// markup and report the rows that could not be extracted
+type ScrapedPage struct {
+	Page
+	Body      []byte
+	FetchedAt time.Time
+
+	// RowErrors are the page's rows that were dropped: missing required
+	// cells, or a date that is not YYYY-MM-DD
+	RowErrors []ExtractionError
+}
+
+// ScrapingError represents an error that occurred while scraping a specific URL
+type ScrapingError struct {
+	Month     string // Page label: YYYY-MM for month views, the page's first date (YYYY-MM-DD) for week and day views
+	URL       string
+	Error     error
+	ErrorType string // "network", "parse", "http", "retry", "circuit_open", "auth", "cancelled", "selector_drift"
+}
+
+// ScrapingWarning represents a non-fatal warning (e.g., duplicate shifts)
+type ScrapingWarning struct {
+	Month   string // Page label, as in ScrapingError
+	Message string
+
+	// Rows are the source rows involved. For a duplicate, the first is the
+	// row that was kept and the second the row that was dropped.
+	Rows []ShiftRowRef
+}
+
+// AmionScraper coordinates scraping of Amion schedule data.
+// It uses a goroutine pool for concurrency and rate limiting to avoid overwhelming the server.
+type AmionScraper struct {
+	client       *AmionHTTPClient
+	pool         *GoroutinePool
+	limiter      *RateLimiter
+	selectors    *AmionSelectors
+	cache        *ResponseCache      // Optional: skips months whose page hasn't changed
+	profiles     *SelectorProfileSet // Optional: picks selectors per page by probing
+	fingerprints FingerprintStore    // Optional: halts the scrape when page markup drifts
+	dedupeKey    DedupeKey           // Fields identifying duplicate shifts; DefaultDedupeKey if nil
+	urlTemplate  *URLTemplate        // Page URLs to fetch; DefaultURLTemplate if nil
+	logger       interface{}         // For compatibility - zap.SugaredLogger optional
+}
+
+// NewAmionScraper creates a new AmionScraper with the specified configuration.
+//
+// Parameters:
+//   - client: The HTTP client to use for fetching
+//   - pool: The goroutine pool for concurrent fetching (typically 5 workers)
+//   - limiter: The rate limiter (typically 1 second between requests)
+//   - selectors: CSS selectors for HTML parsing
+//
+// Returns:
+//   - *AmionScraper: A new scraper instance
+//
+// Example:
+//
+//	client, _ := NewAmionHTTPClient("https://amion.example.com")
+//	pool := NewGoroutinePool(5)
+//	limiter := NewRateLimiter(1 * time.Second)
+//	scraper := NewAmionScraper(client, pool, limiter, DefaultSelectors())
+func NewAmionScraper(client *AmionHTTPClient, pool *GoroutinePool, limiter *RateLimiter, selectors *AmionSelectors) *AmionScraper {
+	return &AmionScraper{
+		client:    client,
+		pool:      pool,
+		limiter:   limiter,
+		selectors: selectors,
+	}
+}
+
+// SetResponseCache enables conditional requests: months whose page is unchanged
+// since it was last scraped with this cache are not re-parsed and are reported
+// in ScrapedShifts.UnchangedMonths instead. Use the same cache across scrapes.
+//
+// Example:
+//
+//	scraper.SetResponseCache(NewResponseCache())
+func (s *AmionScraper) SetResponseCache(cache *ResponseCache) {
+	s.cache = cache
+}
+
+// SetSelectorProfiles makes the scraper probe each page and extract it with the
+// best matching profile instead of the fixed selectors. A page no profile
+// matches is reported as selector drift.
+//
+// Example:
+//
+//	profiles, err := LoadSelectorProfilesFile("amion_selectors.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	scraper.SetSelectorProfiles(profiles)
+func (s *AmionScraper) SetSelectorProfiles(profiles *SelectorProfileSet) {
+	s.profiles = profiles
+}
+
+// SetFingerprintStore enables drift detection: each page's structure is
+// compared with the last known-good fingerprint for its selector profile, and
+// a mismatch halts the scrape with a *SelectorDriftError instead of returning
+// shifts extracted from unfamiliar markup. The first page with data rows seen
+// for a profile becomes its known-good fingerprint.
+//
+// Example:
+//
+//	store, err := NewFileFingerprintStore("/var/lib/schedcu/amion_fingerprints.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	scraper.SetFingerprintStore(store)
+func (s *AmionScraper) SetFingerprintStore(store FingerprintStore) {
+	s.fingerprints = store
+}
+
+// SetDedupeKey changes which fields identify duplicate shifts. The default,
+// DefaultDedupeKey, compares every field; a narrower key such as
+// {DedupeDate, DedupeShiftType, DedupeLocation} treats rows differing only in
+// the remaining fields as the same shift.
+//
+// Returns an error if the key is invalid (see DedupeKey.Validate).
+//
+// Example:
+//
+//	key, err := ParseDedupeKey("date,shift_type,start_time,location")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := scraper.SetDedupeKey(key); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *AmionScraper) SetDedupeKey(key DedupeKey) error {
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	s.dedupeKey = key
+	return nil
+}
+
+// SetURLTemplate changes which pages are fetched, for hospitals or Amion
+// divisions whose schedule lives at a different URL or is published as week
+// or day views.
+//
+// Returns an error if the template is invalid (see URLTemplate.Validate).
+//
+// Example:
+//
+//	err := scraper.SetURLTemplate(URLTemplate{
+//	    Pattern: "/cgi-bin/ocs?Lo=mercy-neuro&Syr={yyyy}&Mo={m}&Dy={d}&Days=7",
+//	    View:    ViewWeek,
+//	})
+func (s *AmionScraper) SetURLTemplate(t URLTemplate) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	s.urlTemplate = &t
+	return nil
+}
+
+// ScrapeSchedule scrapes the schedule data for multiple months.
+// It returns all successfully extracted shifts plus any errors/warnings encountered.
+// Does not fail on partial errors - returns what succeeded.
+//
+// Parameters:
+//   - startDate: The starting month (year-month as time.Time)
+//   - monthCount: Number of months to scrape (e.g., 6 for 6 months)
+//
+// Returns:
+//   - *ScrapedShifts: Results including shifts, errors, and warnings
+//   - error: Only returned if the entire operation failed (e.g., context cancelled)
+//
+// Example:
+//
+//	startDate := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+//	results, err := scraper.ScrapeSchedule(startDate, 6)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Scraped %d shifts, %d errors, %d warnings\n",
+//	    len(results.Shifts), len(results.Errors), len(results.Warnings))
+func (s *AmionScraper) ScrapeSchedule(startDate time.Time, monthCount int) (*ScrapedShifts, error) {
+	ctx := context.Background()
+	return s.ScrapeScheduleWithContext(ctx, startDate, monthCount)
+}
+
+// ScrapeScheduleWithContext scrapes the schedule data with context support.
+// Allows cancellation and timeout control.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout
+//   - startDate: The starting month
+//   - monthCount: Number of months to scrape
+//
+// Returns:
+//   - *ScrapedShifts: Results including shifts, errors, and warnings
+//   - error: Context errors or if the entire operation failed. On selector
+//     drift, a *SelectorDriftError is returned and Shifts is empty.
+func (s *AmionScraper) ScrapeScheduleWithContext(ctx context.Context, startDate time.Time, monthCount int) (*ScrapedShifts, error) {
+	if monthCount < 1 {
+		return nil, fmt.Errorf("monthCount must be at least 1, got %d", monthCount)
+	}
+
+	from, to := monthWindow(startDate, monthCount)
+	return s.ScrapeWindowWithContext(ctx, from, to)
+}
+
+// ScrapeWindow scrapes the shifts dated from through to (inclusive; times of
+// day are ignored). Only the pages covering the window are fetched and shifts
+// outside it are dropped, so refreshing the next few days is cheap, especially
+// with a week- or day-view URL template.
+//
+// Example:
+//
+//	today := time.Now()
+//	results, err := scraper.ScrapeWindow(today, today.AddDate(0, 0, 9))
+func (s *AmionScraper) ScrapeWindow(from, to time.Time) (*ScrapedShifts, error) {
+	return s.ScrapeWindowWithContext(context.Background(), from, to)
+}
+
+// ScrapeWindowWithContext is ScrapeWindow with cancellation and timeout
+// control. Errors are as for ScrapeScheduleWithContext; an invalid window
+// (to before from, or more than MaxWindowPages pages) is also an error.
+func (s *AmionScraper) ScrapeWindowWithContext(ctx context.Context, from, to time.Time) (*ScrapedShifts, error) {
+	monthURLs, err := s.template().Pages(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &ScrapedShifts{
+		Shifts:          make([]RawAmionShift, 0),
+		Errors:          make([]ScrapingError, 0),
+		Warnings:        make([]ScrapingWarning, 0),
+		UnchangedMonths: make([]string, 0),
+		Pages:           make([]ScrapedPage, 0),
+	}
+
+	// Use a channel to collect results from workers
+	shiftsChan := make(chan []RawAmionShift, len(monthURLs))
+	errorsChan := make(chan ScrapingError, len(monthURLs))
+	dupCountChan := make(chan int, len(monthURLs))
+	unchangedChan := make(chan string, len(monthURLs))
+	warningsChan := make(chan []ScrapingWarning, len(monthURLs))
+	cacheChan := make(chan cachedPage, len(monthURLs))
+	pagesChan := make(chan ScrapedPage, len(monthURLs))
+	baselineChan := make(chan baseline, len(monthURLs))
+
+	// Prepare deduplication tracking: key -> first row seen with it
+	seenShifts := make(map[string]ShiftRowRef)
+	seenMutex := &sync.Mutex{}
+
+	// Submit jobs to the pool
+	for _, monthURL := range monthURLs {
+		job := s.createScrapingJob(monthURL, shiftsChan, errorsChan, dupCountChan, unchangedChan, warningsChan, cacheChan, pagesChan, baselineChan, seenShifts, seenMutex)
+		err := s.pool.Submit(job)
+		if err == ErrQueueFull {
+			// Queue is full - wait a bit and retry
+			time.Sleep(100 * time.Millisecond)
+			err = s.pool.Submit(job)
+			if err != nil {
+				results.Errors = append(results.Errors, ScrapingError{
+					Month:     monthURL.Label,
+					URL:       monthURL.URL,
+					Error:     fmt.Errorf("failed to submit job to pool: %w", err),
+					ErrorType: "queue",
+				})
+			}
+		}
+	}
+
+	// Wait for all jobs to complete
+	err = s.pool.Wait(ctx)
+	if err != nil {
+		return results, err
+	}
+
+	// Collect all results from channels
+	close(shiftsChan)
+	close(errorsChan)
+	close(dupCountChan)
+	close(unchangedChan)
+	close(warningsChan)
+	close(cacheChan)
+	close(pagesChan)
+	close(baselineChan)
+
+	for shifts := range shiftsChan {
+		results.Shifts = append(results.Shifts, shifts...)
+	}
+
+	for scrapingErr := range errorsChan {
+		results.Errors = append(results.Errors, scrapingErr)
+		results.MonthsFailed++
+	}
+
+	for dupCount := range dupCountChan {
+		results.DuplicateCount += dupCount
+	}
+
+	for month := range unchangedChan {
+		results.UnchangedMonths = append(results.UnchangedMonths, month)
+	}
+	sort.Strings(results.UnchangedMonths)
+
+	for warnings := range warningsChan {
+		results.Warnings = append(results.Warnings, warnings...)
+	}
+	sort.SliceStable(results.Warnings, func(i, j int) bool {
+		return results.Warnings[i].Month < results.Warnings[j].Month
+	})
+
+	for page := range pagesChan {
+		results.Pages = append(results.Pages, page)
+	}
+	sort.Slice(results.Pages, func(i, j int) bool {
+		return results.Pages[i].From < results.Pages[j].From
+	})
+
+	// Calculate successful months (unchanged months were not processed)
+	results.MonthsProcessed = len(monthURLs) - results.MonthsFailed - len(results.UnchangedMonths)
+
+	// Drifted markup on any page means the rest can't be trusted either:
+	// halt instead of handing back shifts to import
+	for _, scrapingErr := range results.Errors {
+		var driftErr *SelectorDriftError
+		if errors.As(scrapingErr.Error, &driftErr) {
+			results.Shifts = make([]RawAmionShift, 0)
+			results.Pages = make([]ScrapedPage, 0)
+			return results, driftErr
+		}
+	}
+
+	// Only now are the returned shifts final: caching pages earlier would
+	// let a halted scrape mark them unchanged for the next one
+	if s.cache != nil {
+		for page := range cacheChan {
+			s.cache.Put(page.url, page.entry)
+		}
+	}
+	s.recordBaselines(baselineChan)
+
+	return results, nil
+}
+
+// cachedPage is a response cache entry held back until the scrape succeeds
+type cachedPage struct {
+	url   string
+	entry CacheEntry
+}
+
+// baseline is a page fingerprint that becomes its profile's known-good
+// fingerprint, held back until the scrape succeeds
+type baseline struct {
+	page        Page
+	profileID   string
+	fingerprint PageFingerprint
+}
+
+// recordBaselines stores the earliest page's fingerprint for each profile
+// that has no known-good fingerprint with rows yet
+func (s *AmionScraper) recordBaselines(baselines <-chan baseline) {
+	earliest := make(map[string]baseline)
+	for b := range baselines {
+		if first, ok := earliest[b.profileID]; !ok || b.page.From < first.page.From {
+			earliest[b.profileID] = b
+		}
+	}
+	for profileID, b := range earliest {
+		// A failed write only delays the baseline to a later scrape
+		_ = s.fingerprints.RecordKnownGood(profileID, b.fingerprint)
+	}
+}
+
+// template returns the scraper's URL template
+func (s *AmionScraper) template() URLTemplate {
+	if s.urlTemplate != nil {
+		return *s.urlTemplate
+	}
+	return DefaultURLTemplate
+}
+
+// monthWindow returns the first and last day of monthCount calendar months
+// starting with startDate's month. Handles year boundaries (Dec -> Jan).
+func monthWindow(startDate time.Time, monthCount int) (from, to time.Time) {
+	from = time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to = from.AddDate(0, monthCount, -1)
+	return from, to
+}
+
+// generateMonthURLs generates the page URLs covering monthCount months from
+// startDate's month, using the scraper's URL template.
+//
+// Parameters:
+//   - startDate: Starting month (uses year and month from this date)
+//   - monthCount: Number of months to cover
+//
+// Returns:
+//   - []Page: Pages in date order (one per month for month views)
+func (s *AmionScraper) generateMonthURLs(startDate time.Time, monthCount int) []Page {
+	from, to := monthWindow(startDate, monthCount)
+	pages, err := s.template().Pages(from, to)
+	if err != nil {
+		return nil
+	}
+	return pages
+}
+
+// createScrapingJob creates a job function for scraping a single page.
+// The job respects rate limiting (adapting to throttling), fetches the URL, extracts shifts, and handles errors.
+func (s *AmionScraper) createScrapingJob(
+	monthURL Page,
+	shiftsChan chan<- []RawAmionShift,
+	errorsChan chan<- ScrapingError,
+	dupCountChan chan<- int,
+	unchangedChan chan<- string,
+	warningsChan chan<- []ScrapingWarning,
+	cacheChan chan<- cachedPage,
+	pagesChan chan<- ScrapedPage,
+	baselineChan chan<- baseline,
+	seenShifts map[string]ShiftRowRef,
+	seenMutex *sync.Mutex,
+) Job {
+	return func(ctx context.Context) error {
+		// Fetch and parse the HTML through the rate limiter
+		fetched, err := s.fetchMonth(ctx, monthURL.URL)
+		if err != nil {
+			errorType := "unknown"
+			switch err.(type) {
+			case *HTTPError:
+				errorType = "http"
+			case *NetworkError:
+				errorType = "network"
+			case *ParseError:
+				errorType = "parse"
+			case *RetryError:
+				errorType = "retry"
+			case *CircuitOpenError:
+				errorType = "circuit_open"
+			case *AuthError:
+				errorType = "auth"
+			}
+			if ctx.Err() != nil {
+				errorType = "cancelled"
+			}
+
+			errorsChan <- ScrapingError{
+				Month:     monthURL.Label,
+				URL:       monthURL.URL,
+				Error:     err,
+				ErrorType: errorType,
+			}
+			return nil // Don't fail the job, just record the error
+		}
+
+		if fetched.Unchanged {
+			cacheChan <- cachedPage{url: monthURL.URL, entry: fetched.Entry}
+			unchangedChan <- monthURL.Label
+			return nil
+		}
+
+		// Pick the selectors for this page and make sure its markup hasn't drifted
+		selectors, pageBaseline, err := s.checkPage(fetched.Document, monthURL)
+		if err != nil {
+			errorsChan <- ScrapingError{
+				Month:     monthURL.Label,
+				URL:       monthURL.URL,
+				Error:     err,
+				ErrorType: "selector_drift",
+			}
+			return nil
+		}
+
+		// Extract shifts from the document
+		extractionResult := ExtractShiftsWithSelectors(fetched.Document, selectors)
+		page := ScrapedPage{
+			Page:      monthURL,
+			Body:      fetched.Body,
+			FetchedAt: fetched.Entry.FetchedAt,
+			RowErrors: extractionResult.Errors,
+		}
+
+		// Filter for this month's shifts and check for duplicates
+		monthShifts := make([]RawAmionShift, 0)
+		var warnings []ScrapingWarning
+		dupCount := 0
+
+		dedupeKey := s.dedupeKey
+		if dedupeKey == nil {
+			dedupeKey = DefaultDedupeKey
+		}
+
+		for _, shift := range extractionResult.Shifts {
+			// Keep only the part of the page inside the scrape window
+			if !monthURL.Contains(shift.Date) {
+				if _, err := time.Parse(dateLayout, shift.Date); err != nil {
+					page.RowErrors = append(page.RowErrors, ExtractionError{
+						RowIndex: shift.RowIndex,
+						Field:    "date",
+						Value:    shift.Date,
+						Reason:   "not a YYYY-MM-DD date",
+					})
+				}
+				continue
+			}
+			shift.URL = monthURL.URL
+
+			shiftKey := dedupeKey.Of(&shift)
+			ref := ShiftRowRef{Month: monthURL.Label, URL: monthURL.URL, RowIndex: shift.RowIndex}
+
+			seenMutex.Lock()
+			first, duplicate := seenShifts[shiftKey]
+			if !duplicate {
+				seenShifts[shiftKey] = ref
+			}
+			seenMutex.Unlock()
+
+			if duplicate {
+				dupCount++
+				warnings = append(warnings, ScrapingWarning{
+					Month:   monthURL.Label,
+					Message: fmt.Sprintf("duplicate %s shift on %s: %s repeats %s", shift.ShiftType, shift.Date, ref, first),
+					Rows:    []ShiftRowRef{first, ref},
+				})
+				continue
+			}
+			monthShifts = append(monthShifts, shift)
+		}
+
+		// Send results to channels
+		if len(monthShifts) > 0 || len(extractionResult.Shifts) == 0 {
+			shiftsChan <- monthShifts
+		} else {
+			// All shifts were duplicates or outside the window
+			shiftsChan <- make([]RawAmionShift, 0)
+		}
+
+		dupCountChan <- dupCount
+		warningsChan <- warnings
+		pagesChan <- page
+
+		// Remember the page once it has been processed; the scrape stores
+		// it after every page has been checked for drift
+		if s.cache != nil {
+			cacheChan <- cachedPage{url: monthURL.URL, entry: fetched.Entry}
+		}
+		if pageBaseline != nil {
+			baselineChan <- *pageBaseline
+		}
+
+		return nil
+	}
+}
+
+// checkPage returns the selectors to extract a page with: the best matching
+// profile when profiles are configured, otherwise the scraper's selectors.
+// With a fingerprint store, the page structure must match the profile's
+// known-good fingerprint. When there is none yet, a page with data rows is
+// returned as the baseline to record once the whole scrape has passed.
+func (s *AmionScraper) checkPage(doc *goquery.Document, page Page) (*AmionSelectors, *baseline, error) {
+	url := page.URL
+	selectors := s.selectors
+	profileID := DefaultProfileName + "@v1" // Fixed selectors use the built-in profile's slot
+	if s.profiles != nil {
+		profile, err := s.profiles.Select(doc)
+		if err != nil {
+			fallback := selectors
+			if fallback == nil {
+				fallback = DefaultSelectors()
+			}
+			return nil, nil, &SelectorDriftError{URL: url, Actual: ComputeFingerprint(doc, fallback), Reason: err.Error()}
+		}
+		selectors, profileID = &profile.Selectors, profile.ID()
+	}
+
+	if s.fingerprints == nil {
+		return selectors, nil, nil
+	}
+
+	fp := ComputeFingerprint(doc, selectors)
+	known, ok := s.fingerprints.KnownGood(profileID)
+	if ok && !fp.Matches(known) {
+		return nil, nil, &SelectorDriftError{
+			URL:      url,
+			Profile:  profileID,
+			Expected: &known,
+			Actual:   fp,
+			Reason:   fmt.Sprintf("page structure changed from %s to %s", known, fp),
+		}
+	}
+	if fp.HasRows() && (!ok || !known.HasRows()) {
+		return selectors, &baseline{page: page, profileID: profileID, fingerprint: fp}, nil
+	}
+	return selectors, nil, nil
+}
+
+// fetchMonth fetches one month's page, waiting on the rate limiter first.
+// Throttling responses (429/503/Retry-After) slow the limiter down for every
+// job; a 429, which the client does not retry itself, is tried again up to
+// MaxThrottleRetries times once the limiter lets it through. With a response
+// cache the request is conditional and may come back Unchanged.
+func (s *AmionScraper) fetchMonth(ctx context.Context, url string) (*ConditionalResult, error) {
+	for attempt := 0; ; attempt++ {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		result, err := s.fetchOnce(ctx, url)
+		if err == nil {
+			s.limiter.Success()
+			return result, nil
+		}
+
+		throttled, retryAfter, retryable := throttleInfo(err)
+		if !throttled {
+			return nil, err
+		}
+		s.limiter.Backoff(retryAfter)
+		if !retryable || attempt >= MaxThrottleRetries {
+			return nil, err
+		}
+	}
+}
+
+// fetchOnce makes one (possibly conditional) fetch of a month's page
+func (s *AmionScraper) fetchOnce(ctx context.Context, url string) (*ConditionalResult, error) {
+	// Without a cache the request is unconditional but still returns the body
+	var cached *CacheEntry
+	if s.cache != nil {
+		if entry, ok := s.cache.Get(url); ok {
+			cached = &entry
+		}
+	}
+	return s.client.FetchConditionalWithContext(ctx, url, cached)
+}
+
+// throttleInfo reports whether a fetch error means the server is throttling us,
+// the Retry-After it asked for, and whether the page is worth requesting again.
+func throttleInfo(err error) (throttled bool, retryAfter time.Duration, retryable bool) {
+	switch e := err.(type) {
+	case *HTTPError:
+		return IsThrottleStatus(e.StatusCode) || e.RetryAfter > 0, e.RetryAfter, e.StatusCode == http.StatusTooManyRequests
+	case *RetryError:
+		// The client already retried this page, so only slow down
+		return IsThrottleStatus(e.LastStatusCode) || e.RetryAfter > 0, e.RetryAfter, false
+	}
+	return false, 0, false
+}
+
+// AddWarning adds a warning to the results (for duplicate detection, etc).
+// This is exposed as a utility for external callers.
+func (sr *ScrapedShifts) AddWarning(month, message string) {
+	sr.Warnings = append(sr.Warnings, ScrapingWarning{
+		Month:   month,
+		Message: message,
+	})
+}
+
+// HasErrors returns true if any errors occurred during scraping.
+func (sr *ScrapedShifts) HasErrors() bool {
+	return len(sr.Errors) > 0
+}
+
+// ErrorCount returns the number of errors that occurred.
+func (sr *ScrapedShifts) ErrorCount() int {
+	return len(sr.Errors)
+}
+
+// WarningCount returns the number of warnings that occurred.
+func (sr *ScrapedShifts) WarningCount() int {
+	return len(sr.Warnings)
+}
+
+// TotalShifts returns the total number of shifts scraped (including duplicates detected).
+func (sr *ScrapedShifts) TotalShifts() int {
+	return len(sr.Shifts) + sr.DuplicateCount
+}
+
+// FormattedErrors returns a formatted string of all errors for logging.
+func (sr *ScrapedShifts) FormattedErrors() string {
+	if !sr.HasErrors() {
+		return ""
+	}
+
+	result := fmt.Sprintf("Scraping errors (%d):\n", len(sr.Errors))
+	for _, err := range sr.Errors {
+		result += fmt.Sprintf("  [%s] %s: %v\n", err.Month, err.ErrorType, err.Error)
+	}
+	return result
+}
+
+// ToValidationResult converts the scrape outcome into a ValidationResult.
+// Failures to reach or authenticate with Amion are reported under the
+// EXTERNAL_SERVICE_ERROR field (an auth failure once, not once per month),
+// parse failures under PARSE_ERROR, anything else under UNKNOWN_ERROR, and
+// warnings under their month.
+func (sr *ScrapedShifts) ToValidationResult() *validation.ValidationResult {
+	result := validation.NewValidationResult()
+
+	authReported := make(map[AuthErrorKind]bool)
+	for _, scrapingErr := range sr.Errors {
+		var authErr *AuthError
+		if errors.As(scrapingErr.Error, &authErr) {
+			if !authReported[authErr.Kind] {
+				authReported[authErr.Kind] = true
+				result.AddError(validation.EXTERNAL_SERVICE_ERROR.String(), fmt.Sprintf("Amion authentication failed (%s): %s", authErr.Kind, authErr.Message))
+			}
+			continue
+		}
+
+		field := validation.EXTERNAL_SERVICE_ERROR.String()
+		switch scrapingErr.ErrorType {
+		case "selector_drift":
+			field = validation.SELECTOR_DRIFT.String()
+		case "parse":
+			field = validation.PARSE_ERROR.String()
+		case "cancelled", "queue", "unknown":
+			field = validation.UNKNOWN_ERROR.String()
+		}
+		result.AddError(field, fmt.Sprintf("[%s] %s: %v", scrapingErr.Month, scrapingErr.ErrorType, scrapingErr.Error))
+	}
+
+	for _, warn := range sr.Warnings {
+		result.AddWarning(warn.Month, warn.Message)
+	}
+
+	if len(authReported) > 0 {
+		kinds := make([]string, 0, len(authReported))
+		for kind := range authReported {
+			kinds = append(kinds, string(kind))
+		}
+		sort.Strings(kinds)
+		result.SetContext("auth_error_kinds", kinds)
+	}
+	result.SetContext("months_processed", sr.MonthsProcessed)
+	result.SetContext("months_failed", sr.MonthsFailed)
+	result.SetContext("unchanged_months", sr.UnchangedMonths)
+	result.SetContext("duplicate_count", sr.DuplicateCount)
+
+	return result
+}
+
+// FormattedWarnings returns a formatted string of all warnings for logging.
+func (sr *ScrapedShifts) FormattedWarnings() string {
+	if len(sr.Warnings) == 0 {
+		return ""
+	}
+
+	result := fmt.Sprintf("Scraping warnings (%d):\n", len(sr.Warnings))
+	for _, warn := range sr.Warnings {
+		result += fmt.Sprintf("  [%s] %s\n", warn.Month, warn.Message)
+	}
+	return result
+}
@@ -97,9 +97,16 @@ func ExampleAmionScraper_WithErrorHandling() {
 //	    fmt.Printf("Duplicates detected: %d\n", results.DuplicateCount)
 //	    fmt.Printf("Total before dedup: %d\n", results.TotalShifts())
 //
-//	    // Duplicates are based on: date + shift_type
-//	    // So a shift on 2025-11-15 with type "Technologist" that appears
-//	    // in multiple months would only be returned once
+//	    // By default a duplicate matches on every field (date, shift type,
+//	    // start/end time, location and staff), so two "Radiologist" shifts on
+//	    // 2025-11-15 in different read rooms are both kept. Each duplicate is
+//	    // reported with the row it repeats:
+//	    for _, w := range results.Warnings {
+//	        fmt.Printf("%s (rows %v)\n", w.Message, w.Rows)
+//	    }
+//
+//	    // A narrower key can be set before scraping:
+//	    //   scraper.SetDedupeKey(DedupeKey{DedupeDate, DedupeShiftType, DedupeLocation})
 //	}
 func ExampleAmionScraper_DuplicateHandling() {
 	// This is an example function - it's not executed but demonstrates usage
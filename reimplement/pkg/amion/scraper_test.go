@@ -458,8 +458,8 @@ func TestGenerateMonthURLs(t *testing.T) {
 					t.Errorf("Missing URL for month %s", expectedMonth)
 					continue
 				}
-				if urls[i].Month != expectedMonth {
-					t.Errorf("Expected month %s, got %s", expectedMonth, urls[i].Month)
+				if urls[i].Label != expectedMonth {
+					t.Errorf("Expected month %s, got %s", expectedMonth, urls[i].Label)
 				}
 				if !strings.Contains(urls[i].URL, expectedMonth) {
 					t.Errorf("URL should contain month %s, got %s", expectedMonth, urls[i].URL)
@@ -564,6 +564,63 @@ func TestScrapeSchedule_ContextCancellation(t *testing.T) {
 	}
 }
 
+// TestScrapeSchedule_ThrottledMonthIsRetried tests that a 429 slows the
+// limiter down and the month is fetched again instead of being lost
+func TestScrapeSchedule_ThrottledMonthIsRetried(t *testing.T) {
+	var mu sync.Mutex
+	throttled := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		first := !throttled
+		throttled = true
+		mu.Unlock()
+
+		if first {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><body><table><tbody>
+			<tr><td>2025-11-15</td><td>Technologist</td><td>07:00</td><td>15:00</td><td>Main Lab</td></tr>
+		</tbody></table></body></html>`)
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewAmionHTTPClient failed: %v", err)
+	}
+	defer client.Close()
+
+	pool := NewGoroutinePool(1)
+	defer pool.Close()
+
+	limiter := NewRateLimiter(10 * time.Millisecond)
+	scraper := NewAmionScraper(client, pool, limiter, DefaultSelectors())
+
+	start := time.Now()
+	results, err := scraper.ScrapeSchedule(time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC), 1)
+	if err != nil {
+		t.Fatalf("ScrapeSchedule failed: %v", err)
+	}
+
+	if results.HasErrors() {
+		t.Errorf("expected the throttled month to succeed on retry, got:\n%s", results.FormattedErrors())
+	}
+	if len(results.Shifts) != 1 {
+		t.Errorf("expected 1 shift, got %d", len(results.Shifts))
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("expected the retry to wait for Retry-After, took %v", elapsed)
+	}
+	if limiter.Interval() <= 10*time.Millisecond || limiter.Interval() >= 20*time.Millisecond {
+		t.Errorf("expected the limiter to be slowed down and partly recovered, interval %v", limiter.Interval())
+	}
+}
+
 // TestScrapedShifts_Helpers tests helper methods on ScrapedShifts
 func TestScrapedShifts_Helpers(t *testing.T) {
 	results := &ScrapedShifts{
@@ -652,3 +709,95 @@ func BenchmarkScrapeSchedule_6Months(b *testing.B) {
 		scraper.ScrapeSchedule(startDate, 6)
 	}
 }
+
+// TestScrapeSchedule_LocationAwareDeduplication tests that same-day shifts of one
+// type at different locations or times are kept, and true duplicates are reported
+// with both source rows
+func TestScrapeSchedule_LocationAwareDeduplication(t *testing.T) {
+	page := `
+		<html><body><table><tbody>
+			<tr><td>2025-11-15</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Read Room A</td></tr>
+			<tr><td>2025-11-15</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Read Room B</td></tr>
+			<tr><td>2025-11-15</td><td>Radiologist</td><td>19:00</td><td>07:00</td><td>Read Room A</td></tr>
+			<tr><td>2025-11-15</td><td>radiologist</td><td>07:00</td><td>19:00</td><td>Read Room A </td></tr>
+		</tbody></table></body></html>
+	`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewAmionHTTPClient failed: %v", err)
+	}
+	defer client.Close()
+
+	startDate := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+	scrape := func(key DedupeKey) *ScrapedShifts {
+		pool := NewGoroutinePool(2)
+		defer pool.Close()
+		scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+		if key != nil {
+			if err := scraper.SetDedupeKey(key); err != nil {
+				t.Fatalf("SetDedupeKey failed: %v", err)
+			}
+		}
+		results, err := scraper.ScrapeSchedule(startDate, 1)
+		if err != nil {
+			t.Fatalf("ScrapeSchedule failed: %v", err)
+		}
+		return results
+	}
+
+	results := scrape(nil)
+	if len(results.Shifts) != 3 {
+		t.Errorf("Expected 3 distinct shifts, got %d", len(results.Shifts))
+	}
+	if results.DuplicateCount != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", results.DuplicateCount)
+	}
+	if len(results.Warnings) != 1 {
+		t.Fatalf("Expected 1 duplicate warning, got %d", len(results.Warnings))
+	}
+	warning := results.Warnings[0]
+	want := []ShiftRowRef{
+		{Month: "2025-11", URL: "/schedule/2025-11", RowIndex: 1},
+		{Month: "2025-11", URL: "/schedule/2025-11", RowIndex: 4},
+	}
+	if len(warning.Rows) != 2 || warning.Rows[0] != want[0] || warning.Rows[1] != want[1] {
+		t.Errorf("Expected duplicate rows %v, got %v", want, warning.Rows)
+	}
+	if !strings.Contains(warning.Message, "/schedule/2025-11 row 4 repeats /schedule/2025-11 row 1") {
+		t.Errorf("Warning should name both rows, got %q", warning.Message)
+	}
+
+	// Ignoring location and times collapses all four rows into one
+	results = scrape(DedupeKey{DedupeDate, DedupeShiftType})
+	if len(results.Shifts) != 1 || results.DuplicateCount != 3 {
+		t.Errorf("Expected 1 shift and 3 duplicates, got %d and %d", len(results.Shifts), results.DuplicateCount)
+	}
+}
+
+// TestDedupeKey tests key parsing, validation and normalisation
+func TestDedupeKey(t *testing.T) {
+	key, err := ParseDedupeKey("date, shift_type,staff")
+	if err != nil {
+		t.Fatalf("ParseDedupeKey failed: %v", err)
+	}
+
+	a := RawAmionShift{Date: "2025-11-15", ShiftType: "ON1", StaffNames: []string{"Smith, Jane", "Lee, Ann"}, Location: "A"}
+	b := RawAmionShift{Date: "2025-11-15", ShiftType: "on1 ", StaffNames: []string{"lee, ann", "Smith, Jane"}, Location: "B"}
+	if key.Of(&a) != key.Of(&b) {
+		t.Errorf("Expected equal keys ignoring case, staff order and location: %q vs %q", key.Of(&a), key.Of(&b))
+	}
+	if DefaultDedupeKey.Of(&a) == DefaultDedupeKey.Of(&b) {
+		t.Error("Default key should distinguish locations")
+	}
+
+	for _, bad := range []string{"", "shift_type,location", "date,room"} {
+		if _, err := ParseDedupeKey(bad); err == nil {
+			t.Errorf("ParseDedupeKey(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package amion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultProfileName is the name of the built-in profile wrapping DefaultSelectors
+const DefaultProfileName = "default"
+
+// MinProfileMatchRatio is the fraction of a page's data rows a profile must
+// extract cleanly (valid date and times) to be chosen for that page.
+const MinProfileMatchRatio = 0.8
+
+var (
+	profileDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	profileTimePattern = regexp.MustCompile(`^\d{1,2}:\d{2}$`)
+)
+
+// SelectorProfile is a named, versioned set of selectors for one Amion markup
+// variant. When Amion changes its markup, add a new profile (or bump the
+// version) rather than editing the one that matches older pages.
+type SelectorProfile struct {
+	// Name identifies the markup variant (e.g. "default", "grid-2026")
+	Name string `json:"name"`
+
+	// Version is bumped whenever the selectors change
+	Version int `json:"version"`
+
+	// Probe is an optional selector that must match for the profile to be
+	// considered (e.g. "table.amion-grid"). Without it, every page is tried.
+	Probe string `json:"probe,omitempty"`
+
+	// Selectors are the extraction selectors. Fields missing from a config
+	// file default to DefaultSelectors().
+	Selectors AmionSelectors `json:"selectors"`
+}
+
+// ID returns "name@vN", which keys the profile's known-good fingerprint
+func (p *SelectorProfile) ID() string {
+	return fmt.Sprintf("%s@v%d", p.Name, p.Version)
+}
+
+// SelectorProfileSet is an ordered list of selector profiles. Earlier profiles
+// win ties when probing a page.
+type SelectorProfileSet struct {
+	profiles []*SelectorProfile
+}
+
+// selectorProfileConfig is the JSON layout read by LoadSelectorProfiles
+type selectorProfileConfig struct {
+	Profiles []json.RawMessage `json:"profiles"`
+}
+
+// DefaultSelectorProfiles returns a set holding only the built-in profile.
+func DefaultSelectorProfiles() *SelectorProfileSet {
+	return &SelectorProfileSet{profiles: []*SelectorProfile{{
+		Name:      DefaultProfileName,
+		Version:   1,
+		Selectors: *DefaultSelectors(),
+	}}}
+}
+
+// NewSelectorProfileSet creates a profile set, validating each profile.
+//
+// Returns an error if a profile has no name, lacks a required selector, or
+// shares its name and version with another profile.
+func NewSelectorProfileSet(profiles ...*SelectorProfile) (*SelectorProfileSet, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("at least one selector profile is required")
+	}
+
+	seen := make(map[string]bool, len(profiles))
+	for i, p := range profiles {
+		if p.Name == "" {
+			return nil, fmt.Errorf("selector profile %d has no name", i)
+		}
+		if p.Version < 1 {
+			return nil, fmt.Errorf("selector profile %q: version must be at least 1", p.Name)
+		}
+		if seen[p.ID()] {
+			return nil, fmt.Errorf("duplicate selector profile %s", p.ID())
+		}
+		seen[p.ID()] = true
+
+		sel := p.Selectors
+		required := []struct{ field, value string }{
+			{"shift_row", sel.ShiftRowSelector},
+			{"date_cell", sel.DateCellSelector},
+			{"shift_type_cell", sel.ShiftTypeCellSelector},
+			{"start_time_cell", sel.StartTimeCellSelector},
+			{"end_time_cell", sel.EndTimeCellSelector},
+		}
+		for _, r := range required {
+			if r.value == "" {
+				return nil, fmt.Errorf("selector profile %s: %s selector is required", p.ID(), r.field)
+			}
+		}
+	}
+
+	return &SelectorProfileSet{profiles: profiles}, nil
+}
+
+// LoadSelectorProfiles reads profiles from JSON of the form
+//
+//	{"profiles": [
+//	  {"name": "default", "version": 1},
+//	  {"name": "grid-2026", "version": 1, "probe": "table.grid",
+//	   "selectors": {"shift_row": "table.grid tbody tr", "staff_cell": "td.staff"}}
+//	]}
+//
+// Selectors missing from a profile default to DefaultSelectors().
+func LoadSelectorProfiles(r io.Reader) (*SelectorProfileSet, error) {
+	var config selectorProfileConfig
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse selector profiles: %w", err)
+	}
+
+	profiles := make([]*SelectorProfile, 0, len(config.Profiles))
+	for i, raw := range config.Profiles {
+		profile := &SelectorProfile{Version: 1, Selectors: *DefaultSelectors()}
+		if err := json.Unmarshal(raw, profile); err != nil {
+			return nil, fmt.Errorf("failed to parse selector profile %d: %w", i, err)
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return NewSelectorProfileSet(profiles...)
+}
+
+// LoadSelectorProfilesFile reads profiles from a JSON file (see LoadSelectorProfiles).
+func LoadSelectorProfilesFile(path string) (*SelectorProfileSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open selector profiles: %w", err)
+	}
+	defer f.Close()
+	return LoadSelectorProfiles(f)
+}
+
+// Profiles returns the profiles in priority order.
+func (ps *SelectorProfileSet) Profiles() []*SelectorProfile {
+	return append([]*SelectorProfile(nil), ps.profiles...)
+}
+
+// Select probes the page and returns the profile that extracts the most rows
+// cleanly. A page with no data rows for any profile (e.g. an empty month) gets
+// the first profile whose probe matches.
+//
+// Returns an error if no profile's probe matches, or if every profile extracts
+// fewer than MinProfileMatchRatio of the rows it finds cleanly.
+func (ps *SelectorProfileSet) Select(doc *goquery.Document) (*SelectorProfile, error) {
+	var best, firstProbed *SelectorProfile
+	bestValid := 0
+
+	for _, p := range ps.profiles {
+		if p.Probe != "" && doc.Find(p.Probe).Length() == 0 {
+			continue
+		}
+		if firstProbed == nil {
+			firstProbed = p
+		}
+
+		valid, rows := probeProfile(doc, &p.Selectors)
+		if rows == 0 || float64(valid) < MinProfileMatchRatio*float64(rows) {
+			continue
+		}
+		if valid > bestValid {
+			best, bestValid = p, valid
+		}
+	}
+
+	if best != nil {
+		return best, nil
+	}
+	if firstProbed == nil {
+		return nil, fmt.Errorf("no selector profile probe matches the page")
+	}
+	if _, rows := probeProfile(doc, &firstProbed.Selectors); rows == 0 {
+		return firstProbed, nil
+	}
+	return nil, fmt.Errorf("no selector profile extracts at least %.0f%% of the page's rows", MinProfileMatchRatio*100)
+}
+
+// probeProfile counts the data rows a selector set finds and how many of them
+// have a well-formed date, start time and end time.
+func probeProfile(doc *goquery.Document, sel *AmionSelectors) (valid, rows int) {
+	result := ExtractShiftsWithSelectors(doc, sel)
+	for _, shift := range result.Shifts {
+		if profileDatePattern.MatchString(shift.Date) &&
+			profileTimePattern.MatchString(shift.StartTime) &&
+			profileTimePattern.MatchString(shift.EndTime) {
+			valid++
+		}
+	}
+
+	// Rejected rows are still rows the profile thinks are shifts
+	rejected := make(map[int]bool)
+	for _, e := range result.Errors {
+		rejected[e.RowIndex] = true
+	}
+	for _, shift := range result.Shifts {
+		delete(rejected, shift.RowIndex)
+	}
+	return valid, len(result.Shifts) + len(rejected)
+}
@@ -0,0 +1,349 @@
+package amion
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/schedcu/reimplement/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const legacyPage = `<html><body><table><tbody>
+	<tr><td>2025-11-15</td><td>Technologist</td><td>07:00</td><td>15:00</td><td>Main Lab</td></tr>
+	<tr><td>2025-11-16</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Read Room A</td></tr>
+</tbody></table></body></html>`
+
+// gridPage is a redesigned layout: a header row and the staff column first
+const gridPage = `<html><body><div class="schedule"><table class="grid">
+	<thead><tr><th>Staff</th><th>Date</th><th>Shift</th><th>Start</th><th>End</th></tr></thead>
+	<tbody>
+	<tr><td>Smith, Jane</td><td>2025-11-15</td><td>Technologist</td><td>07:00</td><td>15:00</td></tr>
+	<tr><td>Lee, Ann</td><td>2025-11-16</td><td>Radiologist</td><td>07:00</td><td>19:00</td></tr>
+	</tbody></table></div></body></html>`
+
+const profilesJSON = `{"profiles": [
+	{"name": "default", "version": 1},
+	{"name": "grid", "version": 2, "probe": "table.grid", "selectors": {
+		"shift_row": "table.grid tbody tr",
+		"date_cell": "td:nth-child(2)",
+		"shift_type_cell": "td:nth-child(3)",
+		"start_time_cell": "td:nth-child(4)",
+		"end_time_cell": "td:nth-child(5)",
+		"location_cell": "",
+		"required_staffing_cell": "",
+		"staff_cell": "td:nth-child(1)"
+	}}
+]}`
+
+// TestLoadSelectorProfiles verifies config parsing, defaults and validation.
+func TestLoadSelectorProfiles(t *testing.T) {
+	profiles, err := LoadSelectorProfiles(strings.NewReader(profilesJSON))
+	require.NoError(t, err)
+
+	list := profiles.Profiles()
+	require.Len(t, list, 2)
+	assert.Equal(t, "default@v1", list[0].ID())
+	assert.Equal(t, *DefaultSelectors(), list[0].Selectors)
+	assert.Equal(t, "grid@v2", list[1].ID())
+	assert.Equal(t, "td:nth-child(1)", list[1].Selectors.StaffCellSelector)
+	assert.Equal(t, DefaultSelectors().HeaderRowSelector, list[1].Selectors.HeaderRowSelector, "unset fields default")
+
+	_, err = LoadSelectorProfiles(strings.NewReader(`{"profiles": [{"name": "a"}, {"name": "a"}]}`))
+	assert.ErrorContains(t, err, "duplicate selector profile a@v1")
+
+	_, err = LoadSelectorProfiles(strings.NewReader(`{"profiles": [{"name": "a", "selectors": {"date_cell": ""}}]}`))
+	assert.ErrorContains(t, err, "date_cell selector is required")
+
+	_, err = LoadSelectorProfiles(strings.NewReader(`{"profiles": []}`))
+	assert.Error(t, err)
+}
+
+// TestSelectorProfileSet_Select verifies probing picks the profile matching the markup.
+func TestSelectorProfileSet_Select(t *testing.T) {
+	profiles, err := LoadSelectorProfiles(strings.NewReader(profilesJSON))
+	require.NoError(t, err)
+
+	doc, err := docFromHTML(legacyPage)
+	require.NoError(t, err)
+	profile, err := profiles.Select(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "default@v1", profile.ID())
+
+	// The default profile finds the grid rows too, but extracts garbage from them
+	doc, err = docFromHTML(gridPage)
+	require.NoError(t, err)
+	profile, err = profiles.Select(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "grid@v2", profile.ID())
+
+	shifts := ExtractShiftsWithSelectors(doc, &profile.Selectors).Shifts
+	require.Len(t, shifts, 2)
+	assert.Equal(t, "2025-11-15", shifts[0].Date)
+	assert.Equal(t, []string{"Smith, Jane"}, shifts[0].StaffNames)
+
+	// An empty month still gets a profile
+	doc, err = docFromHTML(`<html><body><table><tbody></tbody></table></body></html>`)
+	require.NoError(t, err)
+	profile, err = profiles.Select(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "default@v1", profile.ID())
+
+	// Nothing extracts cleanly from unknown markup
+	doc, err = docFromHTML(`<html><body><table><tbody>
+		<tr><td>Mon 15</td><td>Tech</td><td>7a</td><td>3p</td></tr>
+	</tbody></table></body></html>`)
+	require.NoError(t, err)
+	_, err = profiles.Select(doc)
+	assert.Error(t, err)
+}
+
+// TestComputeFingerprint verifies the fingerprint tracks structure, not data.
+func TestComputeFingerprint(t *testing.T) {
+	docA, _ := docFromHTML(legacyPage)
+	docB, _ := docFromHTML(strings.Replace(legacyPage, "Main Lab", "Annex", 1))
+	docEmpty, _ := docFromHTML(`<html><body><table><tbody></tbody></table></body></html>`)
+	docGrid, _ := docFromHTML(gridPage)
+
+	fpA := ComputeFingerprint(docA, DefaultSelectors())
+	assert.Equal(t, "html>body>table", fpA.TablePath)
+	assert.Equal(t, 5, fpA.Columns)
+	assert.NotEmpty(t, fpA.Hash)
+
+	assert.True(t, ComputeFingerprint(docB, DefaultSelectors()).Matches(fpA), "data changes are not drift")
+	assert.True(t, ComputeFingerprint(docEmpty, DefaultSelectors()).Matches(fpA), "an empty month is not drift")
+
+	fpGrid := ComputeFingerprint(docGrid, DefaultSelectors())
+	assert.Equal(t, "html>body>div.schedule>table.grid", fpGrid.TablePath)
+	assert.Equal(t, []string{"staff", "date", "shift", "start", "end"}, fpGrid.Headers)
+	assert.False(t, fpGrid.Matches(fpA))
+}
+
+// TestFileFingerprintStore verifies known-good fingerprints persist.
+func TestFileFingerprintStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.json")
+	doc, _ := docFromHTML(legacyPage)
+	fp := ComputeFingerprint(doc, DefaultSelectors())
+
+	store, err := NewFileFingerprintStore(path)
+	require.NoError(t, err)
+	_, ok := store.KnownGood("default@v1")
+	assert.False(t, ok)
+	require.NoError(t, store.RecordKnownGood("default@v1", fp))
+
+	reopened, err := NewFileFingerprintStore(path)
+	require.NoError(t, err)
+	known, ok := reopened.KnownGood("default@v1")
+	require.True(t, ok)
+	assert.Equal(t, fp, known)
+
+	require.NoError(t, reopened.Forget("default@v1"))
+	reopened, err = NewFileFingerprintStore(path)
+	require.NoError(t, err)
+	_, ok = reopened.KnownGood("default@v1")
+	assert.False(t, ok)
+}
+
+// TestScrapeSchedule_SelectorDriftHaltsImport verifies a markup change halts the
+// scrape with SELECTOR_DRIFT instead of returning shifts.
+func TestScrapeSchedule_SelectorDriftHaltsImport(t *testing.T) {
+	pages := map[string]string{
+		"/schedule/2025-11": legacyPage,
+		"/schedule/2025-12": strings.ReplaceAll(legacyPage, "2025-11", "2025-12"),
+	}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, pages[r.URL.Path])
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	store := NewMemoryFingerprintStore()
+	newScraper := func() *AmionScraper {
+		pool := NewGoroutinePool(2)
+		t.Cleanup(func() { pool.Close() })
+		scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+		scraper.SetFingerprintStore(store)
+		return scraper
+	}
+	startDate := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	results, err := newScraper().ScrapeSchedule(startDate, 2)
+	require.NoError(t, err)
+	assert.Len(t, results.Shifts, 4)
+	_, ok := store.KnownGood("default@v1")
+	assert.True(t, ok, "first page becomes the known-good fingerprint")
+
+	// Amion adds a column: the default selectors still "work" but now read the wrong cells
+	mu.Lock()
+	pages["/schedule/2025-12"] = strings.NewReplacer(
+		"<tr><td>2025-12", "<tr><td>Smith</td><td>2025-12",
+	).Replace(pages["/schedule/2025-12"])
+	mu.Unlock()
+
+	results, err = newScraper().ScrapeSchedule(startDate, 2)
+	var driftErr *SelectorDriftError
+	require.ErrorAs(t, err, &driftErr)
+	assert.Equal(t, "/schedule/2025-12", driftErr.URL)
+	assert.Equal(t, "default@v1", driftErr.Profile)
+	assert.Equal(t, 6, driftErr.Actual.Columns)
+	assert.Empty(t, results.Shifts, "no shifts are returned once drift is detected")
+	require.Len(t, results.Errors, 1)
+	assert.Equal(t, "selector_drift", results.Errors[0].ErrorType)
+
+	vr := results.ToValidationResult()
+	require.NotEmpty(t, vr.Errors)
+	assert.Equal(t, validation.SELECTOR_DRIFT.String(), vr.Errors[0].Field)
+}
+
+// TestScrapeSchedule_DriftDoesNotCachePages verifies pages fetched by a scrape
+// halted for drift are not cached, so the rescrape after the fix imports every
+// month instead of reporting the pages as unchanged.
+func TestScrapeSchedule_DriftDoesNotCachePages(t *testing.T) {
+	drifted := strings.NewReplacer("<tr><td>2025-12", "<tr><td>Smith</td><td>2025-12").
+		Replace(strings.ReplaceAll(legacyPage, "2025-11", "2025-12"))
+	pages := map[string]string{
+		"/schedule/2025-11": legacyPage,
+		"/schedule/2025-12": drifted,
+	}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, pages[r.URL.Path])
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// November's markup is the known-good structure
+	store := NewMemoryFingerprintStore()
+	doc, err := docFromHTML(legacyPage)
+	require.NoError(t, err)
+	require.NoError(t, store.RecordKnownGood("default@v1", ComputeFingerprint(doc, DefaultSelectors())))
+
+	cache := NewResponseCache()
+	newScraper := func() *AmionScraper {
+		pool := NewGoroutinePool(2)
+		t.Cleanup(func() { pool.Close() })
+		scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+		scraper.SetFingerprintStore(store)
+		scraper.SetResponseCache(cache)
+		return scraper
+	}
+	startDate := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err = newScraper().ScrapeSchedule(startDate, 2)
+	var driftErr *SelectorDriftError
+	require.ErrorAs(t, err, &driftErr)
+	_, cached := cache.Get("/schedule/2025-11")
+	assert.False(t, cached, "pages of a halted scrape are not cached")
+
+	// Amion reverts the markup change
+	mu.Lock()
+	pages["/schedule/2025-12"] = strings.ReplaceAll(legacyPage, "2025-11", "2025-12")
+	mu.Unlock()
+
+	results, err := newScraper().ScrapeSchedule(startDate, 2)
+	require.NoError(t, err)
+	assert.Empty(t, results.UnchangedMonths)
+	assert.Equal(t, 2, results.MonthsProcessed)
+	assert.Len(t, results.Shifts, 4, "both months are imported")
+	_, cached = cache.Get("/schedule/2025-11")
+	assert.True(t, cached)
+}
+
+// TestScrapeSchedule_SelectorProfiles verifies each month uses the profile its markup matches.
+func TestScrapeSchedule_SelectorProfiles(t *testing.T) {
+	pages := map[string]string{
+		"/schedule/2025-11": legacyPage,
+		"/schedule/2025-12": strings.ReplaceAll(gridPage, "2025-11", "2025-12"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, pages[r.URL.Path])
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	profiles, err := LoadSelectorProfiles(strings.NewReader(profilesJSON))
+	require.NoError(t, err)
+
+	pool := NewGoroutinePool(2)
+	defer pool.Close()
+	scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+	scraper.SetSelectorProfiles(profiles)
+
+	results, err := scraper.ScrapeSchedule(time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC), 2)
+	require.NoError(t, err)
+	require.Len(t, results.Shifts, 4)
+	for _, shift := range results.Shifts {
+		assert.Regexp(t, `^\d{4}-\d{2}-\d{2}$`, shift.Date)
+	}
+}
+
+// TestScrapeSchedule_DriftRecordsNoBaseline verifies a page's fingerprint only
+// becomes the known-good baseline once the whole scrape has passed.
+func TestScrapeSchedule_DriftRecordsNoBaseline(t *testing.T) {
+	pages := map[string]string{
+		"/schedule/2025-11": legacyPage,
+		"/schedule/2025-12": `<html><body><table><tbody>
+			<tr><td>Mon 15</td><td>Tech</td><td>7a</td><td>3p</td></tr>
+		</tbody></table></body></html>`,
+	}
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, pages[r.URL.Path])
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	profiles, err := LoadSelectorProfiles(strings.NewReader(profilesJSON))
+	require.NoError(t, err)
+	store := NewMemoryFingerprintStore()
+	newScraper := func() *AmionScraper {
+		pool := NewGoroutinePool(2)
+		t.Cleanup(func() { pool.Close() })
+		scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+		scraper.SetSelectorProfiles(profiles)
+		scraper.SetFingerprintStore(store)
+		return scraper
+	}
+	startDate := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	// December matches no profile, so November's page must not become the baseline
+	_, err = newScraper().ScrapeSchedule(startDate, 2)
+	var driftErr *SelectorDriftError
+	require.ErrorAs(t, err, &driftErr)
+	_, ok := store.KnownGood("default@v1")
+	assert.False(t, ok, "a halted scrape records no known-good fingerprint")
+
+	mu.Lock()
+	pages["/schedule/2025-12"] = strings.ReplaceAll(legacyPage, "2025-11", "2025-12")
+	mu.Unlock()
+
+	_, err = newScraper().ScrapeSchedule(startDate, 2)
+	require.NoError(t, err)
+	_, ok = store.KnownGood("default@v1")
+	assert.True(t, ok, "the passing scrape records it")
+}
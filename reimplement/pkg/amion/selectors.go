@@ -9,36 +9,58 @@ import (
 
 // AmionSelectors contains all CSS selectors for extracting data from Amion HTML.
 // These selectors are based on Spike 1 testing and are validated to work
-// with the current Amion HTML structure.
+// with the current Amion HTML structure. The JSON form is used by selector
+// profile configs (see LoadSelectorProfiles).
 type AmionSelectors struct {
 	// ShiftTableSelector selects the main table containing all shifts
-	ShiftTableSelector string
+	ShiftTableSelector string `json:"shift_table,omitempty"`
 
 	// ShiftRowSelector selects individual shift rows within the table body
-	ShiftRowSelector string
+	ShiftRowSelector string `json:"shift_row,omitempty"`
 
 	// DateCellSelector selects the date column (column 1)
-	DateCellSelector string
+	DateCellSelector string `json:"date_cell,omitempty"`
 
 	// ShiftTypeCellSelector selects the shift type/position column (column 2)
-	ShiftTypeCellSelector string
+	ShiftTypeCellSelector string `json:"shift_type_cell,omitempty"`
 
 	// StartTimeCellSelector selects the start time column (column 3)
-	StartTimeCellSelector string
+	StartTimeCellSelector string `json:"start_time_cell,omitempty"`
 
 	// EndTimeCellSelector selects the end time column (column 4)
-	EndTimeCellSelector string
+	EndTimeCellSelector string `json:"end_time_cell,omitempty"`
 
 	// LocationCellSelector selects the location column (column 5)
-	LocationCellSelector string
+	LocationCellSelector string `json:"location_cell,omitempty"`
 
 	// RequiredStaffingCellSelector selects the required staffing column (column 6, optional)
-	RequiredStaffingCellSelector string
+	RequiredStaffingCellSelector string `json:"required_staffing_cell,omitempty"`
+
+	// StaffCellSelector selects the assigned staff column (column 7, optional).
+	// Leave empty if the page has no staff column.
+	StaffCellSelector string `json:"staff_cell,omitempty"`
+
+	// StaffNameSelector selects individual names inside the staff cell when each
+	// name is its own element (e.g. links to staff pages). If nothing matches,
+	// the cell text is split on line breaks and StaffNameSeparators instead.
+	StaffNameSelector string `json:"staff_name,omitempty"`
+
+	// StaffNameSeparators are the characters that separate names in a
+	// multi-name staff cell. Commas are deliberately not included because
+	// Amion shows names as "Last, First".
+	StaffNameSeparators string `json:"staff_name_separators,omitempty"`
+
+	// OpenStaffMarkers are staff cell entries (case-insensitive) that mean the
+	// slot is unfilled rather than naming a person. Nil means DefaultOpenStaffMarkers.
+	OpenStaffMarkers []string `json:"open_staff_markers,omitempty"`
 
 	// HeaderRowSelector identifies header rows to skip
-	HeaderRowSelector string
+	HeaderRowSelector string `json:"header_row,omitempty"`
 }
 
+// DefaultOpenStaffMarkers are the placeholders Amion uses for unfilled slots
+var DefaultOpenStaffMarkers = []string{"open", "tbd", "tba", "unassigned", "vacant", "none", "-", "--", "?"}
+
 // DefaultSelectors returns the default Amion HTML selectors based on Spike 1 results.
 // These selectors are CSS nth-child based and work reliably with standard Amion HTML.
 func DefaultSelectors() *AmionSelectors {
@@ -51,6 +73,9 @@ func DefaultSelectors() *AmionSelectors {
 		EndTimeCellSelector:         "td:nth-child(4)",
 		LocationCellSelector:        "td:nth-child(5)",
 		RequiredStaffingCellSelector: "td:nth-child(6)",
+		StaffCellSelector:           "td:nth-child(7)",
+		StaffNameSelector:           "a, li, div, p, span.staff",
+		StaffNameSeparators:         ";/&|",
 		HeaderRowSelector:           "thead tr, tr:has(th)",
 	}
 }
@@ -212,6 +237,12 @@ func extractShiftFromRow(row *goquery.Selection, rowIndex int, sel *AmionSelecto
 	}
 	shift.RequiredStaffCell = fmt.Sprintf("row %d, column 6", rowIndex)
 
+	// Extract assigned staff (optional field - an empty cell is an unstaffed shift)
+	if sel.StaffCellSelector != "" {
+		shift.StaffNames, shift.OpenSlots = extractStaffNames(row.Find(sel.StaffCellSelector).First(), sel)
+		shift.StaffCell = fmt.Sprintf("row %d, column 7", rowIndex)
+	}
+
 	// Return nil if any critical field is missing
 	if hasCriticalErrors {
 		return nil
@@ -220,6 +251,69 @@ func extractShiftFromRow(row *goquery.Selection, rowIndex int, sel *AmionSelecto
 	return shift
 }
 
+// extractStaffNames returns the staff names in a staff cell and the number of
+// open-slot markers. Names come from StaffNameSelector elements if the cell
+// has any, otherwise from the cell text split on <br> and StaffNameSeparators.
+// Whitespace is collapsed and repeated names are kept once.
+func extractStaffNames(cell *goquery.Selection, sel *AmionSelectors) ([]string, int) {
+	if cell.Length() == 0 {
+		return nil, 0
+	}
+
+	var entries []string
+	if sel.StaffNameSelector != "" {
+		cell.Find(sel.StaffNameSelector).Each(func(i int, el *goquery.Selection) {
+			// Skip containers whose names are already picked up from their children
+			if el.Find(sel.StaffNameSelector).Length() > 0 {
+				return
+			}
+			entries = append(entries, el.Text())
+		})
+	}
+	if len(entries) == 0 {
+		// goquery's Text drops <br>, so turn line breaks into newlines first
+		clone := cell.Clone()
+		clone.Find("br").ReplaceWithHtml("\n")
+		entries = strings.FieldsFunc(clone.Text(), func(r rune) bool {
+			return r == '\n' || strings.ContainsRune(sel.StaffNameSeparators, r)
+		})
+	}
+
+	markers := sel.OpenStaffMarkers
+	if markers == nil {
+		markers = DefaultOpenStaffMarkers
+	}
+
+	var names []string
+	openSlots := 0
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		name := strings.Join(strings.Fields(entry), " ")
+		if name == "" {
+			continue
+		}
+		if isOpenStaffMarker(name, markers) {
+			openSlots++
+			continue
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+	return names, openSlots
+}
+
+// isOpenStaffMarker reports whether a staff entry is an unfilled-slot placeholder
+func isOpenStaffMarker(entry string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.EqualFold(entry, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 // parseInteger safely parses an integer from a string.
 func parseInteger(s string) (int, error) {
 	// Handle empty string
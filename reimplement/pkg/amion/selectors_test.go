@@ -978,3 +978,63 @@ func TestExtractionResult_HelperMethods(t *testing.T) {
 		t.Errorf("Expected CriticalErrorCount to return 1, got %d", result.CriticalErrorCount())
 	}
 }
+
+// Test 24: Staff names from single-name, multi-name and open cells
+func TestExtractShifts_StaffNames(t *testing.T) {
+	html := `
+	<table>
+		<tbody>
+			<tr><td>2025-11-15</td><td>Technologist</td><td>07:00</td><td>15:00</td><td>Main Lab</td><td>1</td><td> Smith,  Jane </td></tr>
+			<tr><td>2025-11-15</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Read Room A</td><td>2</td><td><a href="/staff/1">Lee, Ann</a><a href="/staff/2">Park, Joon</a></td></tr>
+			<tr><td>2025-11-16</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Read Room A</td><td>3</td><td>Lee, Ann<br>TBD<br/>Open</td></tr>
+			<tr><td>2025-11-16</td><td>Technologist</td><td>07:00</td><td>15:00</td><td>Main Lab</td><td>2</td><td>Smith, Jane / Doe, John; smith, jane</td></tr>
+			<tr><td>2025-11-17</td><td>Technologist</td><td>07:00</td><td>15:00</td><td>Main Lab</td><td>1</td><td>  </td></tr>
+		</tbody>
+	</table>
+	`
+
+	doc, _ := docFromHTML(html)
+	result := ExtractShifts(doc)
+
+	if result.ShiftCount() != 5 {
+		t.Fatalf("Expected 5 shifts, got %d (errors: %s)", result.ShiftCount(), result.FormattedErrors())
+	}
+
+	tests := []struct {
+		names     []string
+		openSlots int
+	}{
+		{[]string{"Smith, Jane"}, 0},
+		{[]string{"Lee, Ann", "Park, Joon"}, 0},
+		{[]string{"Lee, Ann"}, 2},
+		{[]string{"Smith, Jane", "Doe, John"}, 0},
+		{nil, 0},
+	}
+	for i, tt := range tests {
+		shift := result.Shifts[i]
+		if strings.Join(shift.StaffNames, "|") != strings.Join(tt.names, "|") {
+			t.Errorf("Row %d: expected staff %q, got %q", i+1, tt.names, shift.StaffNames)
+		}
+		if shift.OpenSlots != tt.openSlots {
+			t.Errorf("Row %d: expected %d open slots, got %d", i+1, tt.openSlots, shift.OpenSlots)
+		}
+		if shift.StaffCell != fmt.Sprintf("row %d, column 7", i+1) {
+			t.Errorf("Row %d: unexpected staff cell reference %q", i+1, shift.StaffCell)
+		}
+	}
+}
+
+// Test 25: Pages without a staff column still extract, with no staff
+func TestExtractShifts_NoStaffColumn(t *testing.T) {
+	html := `<table><tbody><tr><td>2025-11-15</td><td>Technologist</td><td>07:00</td><td>15:00</td></tr></tbody></table>`
+
+	doc, _ := docFromHTML(html)
+	result := ExtractShifts(doc)
+
+	if result.ShiftCount() != 1 {
+		t.Fatalf("Expected 1 shift, got %d", result.ShiftCount())
+	}
+	if len(result.Shifts[0].StaffNames) != 0 || result.Shifts[0].OpenSlots != 0 {
+		t.Errorf("Expected no staff, got %q (open %d)", result.Shifts[0].StaffNames, result.Shifts[0].OpenSlots)
+	}
+}
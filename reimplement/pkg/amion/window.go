@@ -0,0 +1,162 @@
+package amion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxWindowPages bounds how many pages one scrape may request, so a day-view
+// template over a long window fails fast instead of hammering Amion.
+const MaxWindowPages = 400
+
+const dateLayout = "2006-01-02"
+
+// PageView is the period one Amion schedule page covers
+type PageView string
+
+const (
+	ViewMonth PageView = "month"
+	ViewWeek  PageView = "week"
+	ViewDay   PageView = "day"
+)
+
+// URLTemplate builds the schedule page URLs for one hospital or Amion
+// division. Pattern placeholders are filled from each page's start date:
+//
+//	{yyyy} 2025   {yy} 25   {mm} 01   {m} 1   {dd} 05   {d} 5   {date} 2025-01-05
+//
+// For example "/schedule/{yyyy}-{mm}" (month view) or
+// "/cgi-bin/ocs?Lo=mercy&Syr={yyyy}&Mo={m}&Dy={d}&Rsel=-1&Days=7" (week view).
+type URLTemplate struct {
+	Pattern   string       `json:"pattern"`
+	View      PageView     `json:"view"`
+	WeekStart time.Weekday `json:"week_start,omitempty"` // First day of a week-view page; Sunday by default
+}
+
+// DefaultURLTemplate is the monthly "/schedule/YYYY-MM" page layout
+var DefaultURLTemplate = URLTemplate{Pattern: "/schedule/{yyyy}-{mm}", View: ViewMonth}
+
+var urlPlaceholderPattern = regexp.MustCompile(`\{[a-z]+\}`)
+
+// Validate checks the view is known, every placeholder is known, and the
+// pattern identifies the page: month views need the year and month, week and
+// day views the full date.
+func (t URLTemplate) Validate() error {
+	if t.Pattern == "" {
+		return fmt.Errorf("URL template pattern is required")
+	}
+
+	used := make(map[string]bool)
+	for _, p := range urlPlaceholderPattern.FindAllString(t.Pattern, -1) {
+		switch p {
+		case "{yyyy}", "{yy}", "{mm}", "{m}", "{dd}", "{d}", "{date}":
+			used[p] = true
+		default:
+			return fmt.Errorf("URL template %q: unknown placeholder %s", t.Pattern, p)
+		}
+	}
+
+	hasYear := used["{yyyy}"] || used["{yy}"] || used["{date}"]
+	hasMonth := used["{mm}"] || used["{m}"] || used["{date}"]
+	hasDay := used["{dd}"] || used["{d}"] || used["{date}"]
+
+	switch t.View {
+	case ViewMonth:
+		if !hasYear || !hasMonth {
+			return fmt.Errorf("URL template %q: a month view needs year and month placeholders", t.Pattern)
+		}
+	case ViewWeek, ViewDay:
+		if !hasYear || !hasMonth || !hasDay {
+			return fmt.Errorf("URL template %q: a %s view needs year, month and day placeholders", t.Pattern, t.View)
+		}
+	default:
+		return fmt.Errorf("URL template %q: unknown view %q", t.Pattern, t.View)
+	}
+	return nil
+}
+
+// Expand fills the pattern's placeholders from date
+func (t URLTemplate) Expand(date time.Time) string {
+	return strings.NewReplacer(
+		"{yyyy}", fmt.Sprintf("%04d", date.Year()),
+		"{yy}", fmt.Sprintf("%02d", date.Year()%100),
+		"{mm}", fmt.Sprintf("%02d", int(date.Month())),
+		"{m}", fmt.Sprintf("%d", int(date.Month())),
+		"{dd}", fmt.Sprintf("%02d", date.Day()),
+		"{d}", fmt.Sprintf("%d", date.Day()),
+		"{date}", date.Format(dateLayout),
+	).Replace(t.Pattern)
+}
+
+// Page is one page to fetch and the part of the scrape window it covers
+type Page struct {
+	Label string // YYYY-MM for month views, the page's first date (YYYY-MM-DD) otherwise
+	URL   string // Path to fetch
+	From  string // First date (YYYY-MM-DD) whose shifts are kept from this page
+	To    string // Last date kept, inclusive
+}
+
+// Pages lists the pages covering [from, to] (inclusive dates), each trimmed
+// to the part of the window it covers
+func (t URLTemplate) Pages(from, to time.Time) ([]Page, error) {
+	from, to = civilDate(from), civilDate(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("window end %s is before start %s", to.Format(dateLayout), from.Format(dateLayout))
+	}
+
+	var start time.Time
+	var next func(time.Time) time.Time
+	switch t.View {
+	case ViewMonth:
+		start = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = func(d time.Time) time.Time { return d.AddDate(0, 1, 0) }
+	case ViewWeek:
+		start = from.AddDate(0, 0, -((int(from.Weekday()) - int(t.WeekStart) + 7) % 7))
+		next = func(d time.Time) time.Time { return d.AddDate(0, 0, 7) }
+	case ViewDay:
+		start = from
+		next = func(d time.Time) time.Time { return d.AddDate(0, 0, 1) }
+	default:
+		return nil, fmt.Errorf("unknown view %q", t.View)
+	}
+
+	var pages []Page
+	for pageStart := start; !pageStart.After(to); pageStart = next(pageStart) {
+		if len(pages) == MaxWindowPages {
+			return nil, fmt.Errorf("window %s to %s needs more than %d %s pages",
+				from.Format(dateLayout), to.Format(dateLayout), MaxWindowPages, t.View)
+		}
+
+		pageFrom, pageTo := pageStart, next(pageStart).AddDate(0, 0, -1)
+		if pageFrom.Before(from) {
+			pageFrom = from
+		}
+		if pageTo.After(to) {
+			pageTo = to
+		}
+
+		label := pageStart.Format(dateLayout)
+		if t.View == ViewMonth {
+			label = pageStart.Format("2006-01")
+		}
+		pages = append(pages, Page{
+			Label: label,
+			URL:   t.Expand(pageStart),
+			From:  pageFrom.Format(dateLayout),
+			To:    pageTo.Format(dateLayout),
+		})
+	}
+	return pages, nil
+}
+
+// Contains reports whether a YYYY-MM-DD date falls in the page's trimmed range
+func (p Page) Contains(date string) bool {
+	return len(date) == len(dateLayout) && date >= p.From && date <= p.To
+}
+
+// civilDate drops the time of day, keeping the calendar date in t's location
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
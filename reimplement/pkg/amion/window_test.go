@@ -0,0 +1,173 @@
+package amion
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// TestURLTemplate_Validate verifies placeholders are checked against the view.
+func TestURLTemplate_Validate(t *testing.T) {
+	assert.NoError(t, DefaultURLTemplate.Validate())
+	assert.NoError(t, URLTemplate{Pattern: "/ocs?Syr={yyyy}&Mo={m}&Dy={d}", View: ViewWeek}.Validate())
+	assert.NoError(t, URLTemplate{Pattern: "/day/{date}", View: ViewDay}.Validate())
+
+	assert.ErrorContains(t, URLTemplate{Pattern: "/schedule/{yyyy}-{mm}", View: ViewDay}.Validate(), "day placeholders")
+	assert.ErrorContains(t, URLTemplate{Pattern: "/schedule/{year}", View: ViewMonth}.Validate(), "unknown placeholder {year}")
+	assert.ErrorContains(t, URLTemplate{Pattern: "/schedule/{yyyy}-{mm}", View: "fortnight"}.Validate(), "unknown view")
+	assert.Error(t, URLTemplate{View: ViewMonth}.Validate())
+}
+
+// TestURLTemplate_Pages verifies the pages covering a window and their trimming.
+func TestURLTemplate_Pages(t *testing.T) {
+	pages, err := DefaultURLTemplate.Pages(day(2025, time.November, 25), day(2025, time.December, 4))
+	require.NoError(t, err)
+	assert.Equal(t, []Page{
+		{Label: "2025-11", URL: "/schedule/2025-11", From: "2025-11-25", To: "2025-11-30"},
+		{Label: "2025-12", URL: "/schedule/2025-12", From: "2025-12-01", To: "2025-12-04"},
+	}, pages)
+
+	// 2025-11-25 is a Tuesday: the first Sunday-start week page begins on the 23rd
+	week := URLTemplate{Pattern: "/week/{date}", View: ViewWeek}
+	pages, err = week.Pages(day(2025, time.November, 25), day(2025, time.December, 4))
+	require.NoError(t, err)
+	assert.Equal(t, []Page{
+		{Label: "2025-11-23", URL: "/week/2025-11-23", From: "2025-11-25", To: "2025-11-29"},
+		{Label: "2025-11-30", URL: "/week/2025-11-30", From: "2025-11-30", To: "2025-12-04"},
+	}, pages)
+
+	week.WeekStart = time.Monday
+	pages, err = week.Pages(day(2025, time.November, 25), day(2025, time.November, 25))
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, "/week/2025-11-24", pages[0].URL)
+
+	daily := URLTemplate{Pattern: "/d?y={yy}&m={m}&d={d}", View: ViewDay}
+	pages, err = daily.Pages(day(2025, time.December, 31), day(2026, time.January, 1).Add(15*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, "/d?y=25&m=12&d=31", pages[0].URL)
+	assert.Equal(t, "/d?y=26&m=1&d=1", pages[1].URL)
+
+	_, err = daily.Pages(day(2025, time.December, 31), day(2025, time.December, 1))
+	assert.ErrorContains(t, err, "before start")
+	_, err = daily.Pages(day(2025, time.January, 1), day(2026, time.December, 31))
+	assert.ErrorContains(t, err, "more than")
+}
+
+// TestScrapeWindow_TrimsToWindow verifies a short window fetches only the
+// pages it needs and keeps only the shifts inside it.
+func TestScrapeWindow_TrimsToWindow(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.RequestURI())
+		mu.Unlock()
+
+		// Every week page lists its own seven days
+		start, err := time.Parse(dateLayout, r.URL.Query().Get("start"))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var rows strings.Builder
+		for i := 0; i < 7; i++ {
+			fmt.Fprintf(&rows, "<tr><td>%s</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Main</td></tr>",
+				start.AddDate(0, 0, i).Format(dateLayout))
+		}
+		fmt.Fprintf(w, "<html><body><table><tbody>%s</tbody></table></body></html>", rows.String())
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	pool := NewGoroutinePool(2)
+	defer pool.Close()
+	scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+	require.NoError(t, scraper.SetURLTemplate(URLTemplate{Pattern: "/week?start={date}", View: ViewWeek}))
+
+	// A mid-month "next 10 days" refresh
+	results, err := scraper.ScrapeWindow(day(2025, time.November, 12), day(2025, time.November, 21))
+	require.NoError(t, err)
+
+	sort.Strings(requested)
+	assert.Equal(t, []string{"/week?start=2025-11-09", "/week?start=2025-11-16"}, requested)
+
+	require.Len(t, results.Shifts, 10)
+	dates := make([]string, 0, len(results.Shifts))
+	for _, shift := range results.Shifts {
+		dates = append(dates, shift.Date)
+	}
+	sort.Strings(dates)
+	assert.Equal(t, "2025-11-12", dates[0])
+	assert.Equal(t, "2025-11-21", dates[9])
+	assert.Equal(t, 2, results.MonthsProcessed)
+	assert.Zero(t, results.DuplicateCount)
+
+	assert.Error(t, scraper.SetURLTemplate(URLTemplate{Pattern: "/week", View: ViewWeek}))
+}
+
+// TestScrapeWindow_ReturnsPages verifies the scrape hands back each page's
+// markup and the rows it dropped, and tags shifts with their page.
+func TestScrapeWindow_ReturnsPages(t *testing.T) {
+	pages := map[string]string{
+		"/schedule/2025-11": `<html><body><table><tbody>
+			<tr><td>2025-11-30</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Main</td></tr>
+			<tr><td>Sun 30</td><td>Radiologist</td><td>19:00</td><td>07:00</td><td>Main</td></tr>
+		</tbody></table></body></html>`,
+		"/schedule/2025-12": `<html><body><table><tbody>
+			<tr><td>2025-12-01</td><td>Radiologist</td><td>07:00</td><td>19:00</td><td>Main</td></tr>
+			<tr><td>2025-12-02</td><td></td><td>07:00</td><td>19:00</td><td>Main</td></tr>
+		</tbody></table></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, html)
+	}))
+	defer server.Close()
+
+	client, err := NewAmionHTTPClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	pool := NewGoroutinePool(2)
+	defer pool.Close()
+	scraper := NewAmionScraper(client, pool, NewRateLimiter(time.Millisecond), DefaultSelectors())
+
+	results, err := scraper.ScrapeWindow(day(2025, time.November, 30), day(2025, time.December, 2))
+	require.NoError(t, err)
+
+	require.Len(t, results.Pages, 2)
+	assert.Equal(t, Page{Label: "2025-11", URL: "/schedule/2025-11", From: "2025-11-30", To: "2025-11-30"}, results.Pages[0].Page)
+	assert.Equal(t, pages["/schedule/2025-11"], string(results.Pages[0].Body))
+	assert.False(t, results.Pages[0].FetchedAt.IsZero())
+
+	require.Len(t, results.Pages[0].RowErrors, 1, "a malformed date is reported, not silently dropped")
+	assert.Equal(t, "date", results.Pages[0].RowErrors[0].Field)
+	require.Len(t, results.Pages[1].RowErrors, 1)
+	assert.Equal(t, "shift_type", results.Pages[1].RowErrors[0].Field)
+
+	require.Len(t, results.Shifts, 2)
+	for _, shift := range results.Shifts {
+		assert.Equal(t, "/schedule/"+shift.Date[:7], shift.URL)
+	}
+}
@@ -0,0 +1,113 @@
+// Command amion-reimport inspects the Amion pages archived for a scrape batch
+// and re-runs extraction over them into a new STAGING schedule version.
+//
+// Usage:
+//
+//	amion-reimport -batch <id> list
+//	amion-reimport -batch <id> dump <snapshot-id> > page.html
+//	amion-reimport -batch <id> -user <id> reimport
+//
+// The database is read from DATABASE_URL.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/repository/postgres"
+	"github.com/schedcu/v2/internal/service/amion"
+)
+
+func main() {
+	batchFlag := flag.String("batch", "", "Scrape batch ID (required)")
+	userFlag := flag.String("user", "", "User ID recorded as creator of the new version (reimport)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -batch <id> [-user <id>] list | dump <snapshot-id> | reimport\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *batchFlag == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	batchID, err := uuid.Parse(*batchFlag)
+	if err != nil {
+		log.Fatalf("Invalid -batch: %v", err)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+	db, err := postgres.New(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	archive := amion.NewArchive(postgres.NewAmionSnapshotRepository(db.DB))
+
+	switch cmd := flag.Arg(0); cmd {
+	case "list":
+		pages, err := archive.Pages(ctx, batchID)
+		if err != nil {
+			log.Fatalf("Failed to list archived pages: %v", err)
+		}
+		for _, p := range pages {
+			fmt.Printf("%s\t%s\t%d bytes\t%s\t%s\n", p.ID, p.Month, p.Size, p.ContentHash[:12], p.URL)
+		}
+
+	case "dump":
+		if flag.NArg() < 2 {
+			log.Fatal("dump requires a snapshot ID")
+		}
+		snapshotID, err := uuid.Parse(flag.Arg(1))
+		if err != nil {
+			log.Fatalf("Invalid snapshot ID: %v", err)
+		}
+		snapshot, html, err := archive.Load(ctx, snapshotID)
+		if err != nil {
+			log.Fatalf("Failed to load snapshot: %v", err)
+		}
+		if snapshot.ScrapeBatchID != batchID {
+			log.Fatalf("Snapshot %s belongs to batch %s, not %s", snapshotID, snapshot.ScrapeBatchID, batchID)
+		}
+		os.Stdout.Write(html)
+
+	case "reimport":
+		if *userFlag == "" {
+			log.Fatal("reimport requires -user")
+		}
+		userID, err := uuid.Parse(*userFlag)
+		if err != nil {
+			log.Fatalf("Invalid -user: %v", err)
+		}
+
+		reimporter := amion.NewReimporter(
+			archive,
+			postgres.NewScrapeBatchRepository(db.DB),
+			postgres.NewScheduleVersionRepository(db.DB),
+			postgres.NewAssignmentRepository(db.DB),
+			postgres.NewPersonRepository(db.DB),
+		)
+		reimporter.SetDivisionRepository(postgres.NewAmionDivisionRepository(db.DB))
+		reimporter.SetShiftMappingRepository(postgres.NewAmionShiftMappingRepository(db.DB))
+		result, err := reimporter.Reimport(ctx, batchID, userID)
+		if err != nil {
+			log.Fatalf("Re-import failed: %v", err)
+		}
+
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+
+	default:
+		log.Fatalf("Unknown command %q", cmd)
+	}
+}
@@ -2,15 +2,30 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	amionweb "github.com/schedcu/reimplement/pkg/amion"
 	"github.com/schedcu/v2/internal/api"
+	"github.com/schedcu/v2/internal/event"
 	"github.com/schedcu/v2/internal/job"
+	"github.com/schedcu/v2/internal/repository"
 	"github.com/schedcu/v2/internal/repository/memory"
+	"github.com/schedcu/v2/internal/repository/postgres"
+	"github.com/schedcu/v2/internal/secrets"
 	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/service/amion"
+	"github.com/schedcu/v2/internal/service/clone"
+	"github.com/schedcu/v2/internal/service/editing"
+	"github.com/schedcu/v2/internal/service/freshness"
+	"github.com/schedcu/v2/internal/service/merge"
+	"github.com/schedcu/v2/internal/service/rollback"
+	"github.com/schedcu/v2/internal/service/templates"
 )
 
 func main() {
@@ -45,6 +60,242 @@ func main() {
 		log.Printf("Warning: Failed to initialize job scheduler: %v (jobs will not be queued)", err)
 	}
 
+	// Secrets (Amion logins, database credentials) come from the provider
+	// selected by SECRETS_PROVIDER; see secrets.NewProviderFromEnv
+	secretProvider, err := secrets.NewProviderFromEnv()
+	if err != nil {
+		log.Printf("Warning: Failed to initialize secret provider: %v", err)
+	}
+
+	// DATABASE_SECRET names a secret with the full connection string ("url") or
+	// the username/password to add to DATABASE_URL
+	dbURL := os.Getenv("DATABASE_URL")
+	if name := os.Getenv("DATABASE_SECRET"); name != "" && secretProvider != nil {
+		resolved, serr := secrets.DatabaseURL(context.Background(), secretProvider, name, dbURL)
+		if serr != nil {
+			log.Printf("Warning: Failed to resolve database secret %q: %v", name, serr)
+		} else {
+			dbURL = resolved
+		}
+	}
+
+	// Event bus for the SSE stream. With a database configured, events go through
+	// Postgres LISTEN/NOTIFY so all API replicas see them; otherwise in-memory.
+	var events event.Bus = event.NewMemoryBus(event.DefaultRetention)
+	var db *sql.DB
+	if dbURL != "" {
+		db, err = sql.Open("postgres", dbURL)
+		if err == nil {
+			var pgBus *event.PostgresBus
+			if pgBus, err = event.NewPostgresBus(db, dbURL); err == nil {
+				events = pgBus
+			}
+		}
+		if err != nil {
+			log.Printf("Warning: Failed to initialize Postgres event bus: %v (using in-memory bus)", err)
+		}
+	}
+	defer events.Close()
+
+	// Outgoing webhooks need the database for subscriptions/delivery logs and
+	// the job queue for retries
+	var webhookService service.WebhookService
+	if db != nil && scheduler != nil {
+		webhookService = service.NewWebhookService(
+			postgres.NewWebhookSubscriptionRepository(db),
+			postgres.NewWebhookDeliveryRepository(db),
+			scheduler,
+		)
+
+		// Only one process should turn events into deliveries
+		if os.Getenv("RUN_WEBHOOK_DISPATCHER") == "true" {
+			dispatchCtx, stopDispatcher := context.WithCancel(context.Background())
+			defer stopDispatcher()
+			go func() {
+				if err := job.RunWebhookDispatcher(dispatchCtx, events, webhookService, postgres.NewEventCursorRepository(db)); err != nil {
+					log.Printf("Webhook dispatcher stopped: %v", err)
+				}
+			}()
+		}
+	}
+
+	var notificationPreferences repository.NotificationPreferenceRepository
+	var coverageAlerts repository.CoverageAlertRepository
+	var scrapeFreshness *freshness.Checker
+	var amionArchive *amion.Archive
+	var amionReimporter *amion.Reimporter
+	var amionDivisions repository.AmionDivisionRepository
+	var amionShiftMappings repository.AmionShiftMappingRepository
+	var amionScraper *amion.Scraper
+	var scrapeBatches repository.ScrapeBatchRepository
+	var batchRollback *rollback.Service
+	var assignments repository.AssignmentRepository
+	var shiftInstances repository.ShiftInstanceRepository
+	var users repository.UserRepository
+	var scheduleEditor *editing.Editor
+	var scheduleMerges *merge.Service
+	var scheduleCloner *clone.Cloner
+	var shiftTemplates repository.ShiftTemplateRepository
+	var shiftGenerator *templates.Generator
+	if db != nil {
+		notificationPreferences = postgres.NewNotificationPreferenceRepository(db)
+		coverageAlerts = postgres.NewCoverageAlertRepository(db)
+
+		// Amion scrape freshness: /api/health/scrapes and Prometheus gauges on /metrics
+		scrapeFreshness = freshness.NewChecker(
+			postgres.NewHospitalRepository(db),
+			postgres.NewScrapeBatchRepository(db),
+			freshness.DefaultConfig,
+		)
+		scrapeFreshness.SetMetrics(freshness.NewMetrics(prometheus.DefaultRegisterer))
+
+		freshnessCtx, stopFreshness := context.WithCancel(context.Background())
+		defer stopFreshness()
+		go scrapeFreshness.Run(freshnessCtx, time.Minute)
+
+		// Raw Amion pages per scrape batch, re-importable into STAGING
+		amionArchive = amion.NewArchive(postgres.NewAmionSnapshotRepository(db))
+		amionReimporter = amion.NewReimporter(
+			amionArchive,
+			postgres.NewScrapeBatchRepository(db),
+			postgres.NewScheduleVersionRepository(db),
+			postgres.NewAssignmentRepository(db),
+			postgres.NewPersonRepository(db),
+		)
+		amionDivisions = postgres.NewAmionDivisionRepository(db)
+		amionReimporter.SetDivisionRepository(amionDivisions)
+		amionShiftMappings = postgres.NewAmionShiftMappingRepository(db)
+		amionReimporter.SetShiftMappingRepository(amionShiftMappings)
+		scrapeBatches = postgres.NewScrapeBatchRepository(db)
+		batchRollback = rollback.NewService(scrapeBatches, postgres.NewAuditLogRepository(db))
+
+		// Hospitals with Amion divisions are scraped through the hardened Amion client
+		amionImporter := amion.NewImporter(
+			postgres.NewAssignmentRepository(db),
+			postgres.NewPersonRepository(db),
+		)
+		amionImporter.SetShiftMappingRepository(amionShiftMappings)
+		amionScraper = amion.NewScraper(amionDivisions, scrapeBatches, amionImporter)
+		amionScraper.SetSecretProvider(secretProvider)
+		amionScraper.SetArchive(amionArchive)
+		amionScraper.SetChangeStager(amion.NewChangeStager(
+			scrapeBatches,
+			postgres.NewScrapedAssignmentRepository(db),
+			postgres.NewScheduleVersionRepository(db),
+			postgres.NewShiftInstanceRepository(db),
+			amionImporter,
+		))
+
+		// AMION_SELECTOR_PROFILES names a JSON file of markup variants (see
+		// amionweb.LoadSelectorProfiles); AMION_FINGERPRINTS keeps each
+		// division's known-good page structure across restarts
+		if path := os.Getenv("AMION_SELECTOR_PROFILES"); path != "" {
+			if profiles, perr := amionweb.LoadSelectorProfilesFile(path); perr != nil {
+				log.Printf("Warning: Failed to load Amion selector profiles: %v (using the default profile)", perr)
+			} else {
+				amionScraper.SetSelectorProfiles(profiles)
+				amionReimporter.SetSelectorProfiles(profiles)
+			}
+		}
+		if path := os.Getenv("AMION_FINGERPRINTS"); path != "" {
+			if store, ferr := amionweb.NewFileFingerprintStore(path); ferr != nil {
+				log.Printf("Warning: Failed to open Amion fingerprint store: %v (fingerprints are kept in memory)", ferr)
+			} else {
+				amionScraper.SetFingerprintStore(store)
+			}
+		}
+		assignments = postgres.NewAssignmentRepository(db)
+		shiftInstances = postgres.NewShiftInstanceRepository(db)
+		users = postgres.NewUserRepository(db)
+
+		// Hand edits to STAGING (and, when confirmed, PRODUCTION) versions
+		scheduleEditor = editing.NewEditor(
+			postgres.NewScheduleVersionRepository(db),
+			shiftInstances,
+			assignments,
+			postgres.NewPersonRepository(db),
+		)
+		if scheduler != nil {
+			scheduleEditor.SetCoverageQueue(scheduler)
+		}
+
+		// Three-way merges of fresh imports into edited STAGING versions
+		scheduleMerges = merge.NewService(
+			postgres.NewScheduleVersionRepository(db),
+			shiftInstances,
+			assignments,
+			postgres.NewScheduleMergeRepository(db),
+		)
+		if scheduler != nil {
+			scheduleMerges.SetCoverageQueue(scheduler)
+		}
+
+		// Next period's draft copied from an existing version
+		scheduleCloner = clone.NewCloner(
+			postgres.NewScheduleVersionRepository(db),
+			shiftInstances,
+			assignments,
+			postgres.NewPersonRepository(db),
+		)
+		if scheduler != nil {
+			scheduleCloner.SetCoverageQueue(scheduler)
+		}
+
+		// Recurring shift templates and the shifts they generate
+		shiftTemplates = postgres.NewShiftTemplateRepository(db)
+		shiftGenerator = templates.NewGenerator(
+			shiftTemplates,
+			postgres.NewScheduleVersionRepository(db),
+			shiftInstances,
+		)
+		if scheduler != nil {
+			shiftGenerator.SetCoverageQueue(scheduler)
+		}
+	}
+
+	// Only processes started with RUN_JOB_WORKER run queued jobs
+	if os.Getenv("RUN_JOB_WORKER") == "true" && db != nil {
+		handlers := job.NewJobHandlers(nil, nil, coverageCalc,
+			service.NewScheduleVersionService(postgres.NewScheduleVersionRepository(db)))
+		handlers.SetEventPublisher(events)
+		handlers.SetSecretProvider(secretProvider)
+		handlers.SetAmionScraper(amionScraper)
+		if webhookService != nil {
+			handlers.SetWebhookService(webhookService)
+		}
+		if pgBus, ok := events.(*event.PostgresBus); ok {
+			handlers.SetEventPruner(pgBus, postgres.NewEventCursorRepository(db))
+		}
+
+		mux := asynq.NewServeMux()
+		handlers.RegisterHandlers(mux)
+		worker := asynq.NewServer(asynq.RedisClientOpt{Addr: redisAddr}, asynq.Config{RetryDelayFunc: job.RetryDelay})
+		if err := worker.Start(mux); err != nil {
+			log.Printf("Warning: Failed to start job worker: %v", err)
+		} else {
+			defer worker.Shutdown()
+		}
+
+		// Recurring jobs are enqueued by a single process per deployment
+		if os.Getenv("RUN_PERIODIC_SCHEDULER") == "true" {
+			periodic := asynq.NewScheduler(asynq.RedisClientOpt{Addr: redisAddr}, nil)
+			if err := job.RegisterPeriodicTasks(periodic); err != nil {
+				log.Printf("Warning: Failed to register periodic jobs: %v", err)
+			} else if err := periodic.Start(); err != nil {
+				log.Printf("Warning: Failed to start periodic job scheduler: %v", err)
+			} else {
+				defer periodic.Shutdown()
+			}
+		}
+	}
+
+	// Hospital data is refused without a user directory unless explicitly
+	// opened up for local development
+	allowUnauthenticated := os.Getenv("DEV_ALLOW_UNAUTHENTICATED") == "true"
+	if users == nil && allowUnauthenticated {
+		log.Println("Warning: DEV_ALLOW_UNAUTHENTICATED is set; hospital data is served without authorization")
+	}
+
 	// Create API router with all services
 	serviceDeps := &api.ServiceDeps{
 		OdsImporter:    nil, // TODO: Initialize in Phase 3
@@ -52,6 +303,29 @@ func main() {
 		Orchestrator:   nil, // TODO: Initialize in Phase 3
 		CoverageCalc:   coverageCalc,
 		VersionService: versionService,
+		Webhooks:       webhookService,
+		Events:         events,
+
+		NotificationPreferences: notificationPreferences,
+		CoverageAlerts:          coverageAlerts,
+		ScrapeFreshness:         scrapeFreshness,
+		AmionArchive:            amionArchive,
+		AmionReimporter:         amionReimporter,
+		AmionDivisions:          amionDivisions,
+		AmionShiftMappings:      amionShiftMappings,
+		AmionScraper:            amionScraper,
+		ScrapeBatches:           scrapeBatches,
+		BatchRollback:           batchRollback,
+		Assignments:             assignments,
+		ShiftInstances:          shiftInstances,
+		ScheduleEditor:          scheduleEditor,
+		ScheduleMerges:          scheduleMerges,
+		ScheduleCloner:          scheduleCloner,
+		ShiftTemplates:          shiftTemplates,
+		ShiftGenerator:          shiftGenerator,
+
+		Users:                users,
+		AllowUnauthenticated: allowUnauthenticated,
 	}
 
 	router := api.NewRouter(scheduler, serviceDeps)
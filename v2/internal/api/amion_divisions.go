@@ -0,0 +1,255 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/secrets"
+	"github.com/schedcu/v2/internal/service/amion"
+)
+
+// AmionDivisionRequest creates or replaces an Amion division. CredentialsRef
+// names a secret; the login itself is never sent to or returned by the API.
+type AmionDivisionRequest struct {
+	Name             string            `json:"name"`
+	BaseURL          string            `json:"base_url"`
+	URLTemplate      string            `json:"url_template"`
+	PageView         string            `json:"page_view"`
+	CredentialsRef   string            `json:"credentials_ref"`
+	SelectorProfile  string            `json:"selector_profile"`
+	ShiftNameMapping map[string]string `json:"shift_name_mapping"`
+	Active           *bool             `json:"active"` // Defaults to true
+}
+
+// AmionDivisionResponse is the API representation of an Amion division
+type AmionDivisionResponse struct {
+	ID               string            `json:"id"`
+	HospitalID       string            `json:"hospital_id"`
+	Name             string            `json:"name"`
+	BaseURL          string            `json:"base_url"`
+	URLTemplate      string            `json:"url_template"`
+	PageView         string            `json:"page_view"`
+	CredentialsRef   string            `json:"credentials_ref"`
+	SelectorProfile  string            `json:"selector_profile"`
+	ShiftNameMapping map[string]string `json:"shift_name_mapping"`
+	Active           bool              `json:"active"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+func toAmionDivisionResponse(d *entity.AmionDivision) AmionDivisionResponse {
+	mapping := d.ShiftNameMapping
+	if mapping == nil {
+		mapping = map[string]string{}
+	}
+	return AmionDivisionResponse{
+		ID:               d.ID.String(),
+		HospitalID:       d.HospitalID.String(),
+		Name:             d.Name,
+		BaseURL:          d.BaseURL,
+		URLTemplate:      d.URLTemplate,
+		PageView:         d.PageView,
+		CredentialsRef:   d.CredentialsRef,
+		SelectorProfile:  d.SelectorProfile,
+		ShiftNameMapping: mapping,
+		Active:           d.Active,
+		CreatedAt:        d.CreatedAt,
+		UpdatedAt:        d.UpdatedAt,
+	}
+}
+
+// validate checks the request describes a scrapeable division using one of
+// the known selector profiles
+func (req *AmionDivisionRequest) validate(profiles []string) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	u, err := url.Parse(req.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base_url must be an absolute http(s) URL")
+	}
+	if err := amion.ValidateURLTemplate(req.URLTemplate, req.PageView); err != nil {
+		return err
+	}
+	if req.SelectorProfile != "" && !slices.Contains(profiles, req.SelectorProfile) {
+		return fmt.Errorf("selector_profile %q is not one of the known profiles: %s", req.SelectorProfile, strings.Join(profiles, ", "))
+	}
+	if req.CredentialsRef != "" {
+		if err := secrets.ValidateName(req.CredentialsRef); err != nil {
+			return fmt.Errorf("credentials_ref: %w", err)
+		}
+	}
+	for label, shiftType := range req.ShiftNameMapping {
+		if strings.TrimSpace(label) == "" || strings.TrimSpace(shiftType) == "" {
+			return fmt.Errorf("shift_name_mapping entries need a label and a shift type")
+		}
+	}
+	return nil
+}
+
+// apply copies the request onto a division
+func (req *AmionDivisionRequest) apply(d *entity.AmionDivision) {
+	d.Name = req.Name
+	d.BaseURL = strings.TrimRight(req.BaseURL, "/")
+	d.URLTemplate = req.URLTemplate
+	d.PageView = req.PageView
+	d.CredentialsRef = req.CredentialsRef
+	d.SelectorProfile = req.SelectorProfile
+	d.ShiftNameMapping = req.ShiftNameMapping
+	d.Active = req.Active == nil || *req.Active
+}
+
+// selectorProfiles lists the selector profile names divisions may use
+func (h *Handlers) selectorProfiles() []string {
+	if h.services.AmionScraper != nil {
+		return h.services.AmionScraper.SelectorProfileNames()
+	}
+	return []string{amion.DefaultSelectorProfile}
+}
+
+// amionDivisionsUnavailable responds when no division repository is configured
+func amionDivisionsUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("DIVISIONS_UNAVAILABLE", "Amion divisions are not configured"))
+}
+
+// ListAmionDivisions lists a hospital's Amion divisions
+func (h *Handlers) ListAmionDivisions(c echo.Context) error {
+	if h.services.AmionDivisions == nil {
+		return amionDivisionsUnavailable(c)
+	}
+
+	hospitalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital id must be a UUID"))
+	}
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	divisions, err := h.services.AmionDivisions.GetByHospital(c.Request().Context(), hospitalID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list Amion divisions: %v", err)))
+	}
+
+	resp := make([]AmionDivisionResponse, 0, len(divisions))
+	for _, d := range divisions {
+		resp = append(resp, toAmionDivisionResponse(d))
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// CreateAmionDivision adds an Amion division to a hospital
+func (h *Handlers) CreateAmionDivision(c echo.Context) error {
+	if h.services.AmionDivisions == nil {
+		return amionDivisionsUnavailable(c)
+	}
+
+	hospitalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital id must be a UUID"))
+	}
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	var req AmionDivisionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	if err := req.validate(h.selectorProfiles()); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_DIVISION", err.Error()))
+	}
+
+	// TODO: Get creator ID from authenticated user
+	creatorID := entity.UserID(uuid.New())
+
+	now := entity.Now()
+	division := &entity.AmionDivision{
+		ID:         uuid.New(),
+		HospitalID: hospitalID,
+		CreatedAt:  now,
+		CreatedBy:  creatorID,
+		UpdatedAt:  now,
+	}
+	req.apply(division)
+
+	if err := h.services.AmionDivisions.Create(c.Request().Context(), division); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("DIVISION_CREATE_FAILED", fmt.Sprintf("Failed to create Amion division: %v", err)))
+	}
+
+	return c.JSON(http.StatusCreated, SuccessResponse(toAmionDivisionResponse(division)))
+}
+
+// UpdateAmionDivision replaces an Amion division's configuration
+func (h *Handlers) UpdateAmionDivision(c echo.Context) error {
+	division, ok, err := h.loadAmionDivision(c)
+	if !ok {
+		return err
+	}
+
+	var req AmionDivisionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	if err := req.validate(h.selectorProfiles()); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_DIVISION", err.Error()))
+	}
+
+	req.apply(division)
+	division.UpdatedAt = entity.Now()
+
+	if err := h.services.AmionDivisions.Update(c.Request().Context(), division); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("DIVISION_UPDATE_FAILED", fmt.Sprintf("Failed to update Amion division: %v", err)))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(toAmionDivisionResponse(division)))
+}
+
+// DeleteAmionDivision removes an Amion division; its archived pages are kept
+func (h *Handlers) DeleteAmionDivision(c echo.Context) error {
+	division, ok, err := h.loadAmionDivision(c)
+	if !ok {
+		return err
+	}
+
+	// TODO: Get deleter ID from authenticated user
+	deleterID := entity.UserID(uuid.New())
+
+	if err := h.services.AmionDivisions.Delete(c.Request().Context(), division.ID, deleterID); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("DIVISION_DELETE_FAILED", fmt.Sprintf("Failed to delete Amion division: %v", err)))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// loadAmionDivision loads the division named by the :id path parameter and
+// checks the caller may manage its hospital
+func (h *Handlers) loadAmionDivision(c echo.Context) (*entity.AmionDivision, bool, error) {
+	if h.services.AmionDivisions == nil {
+		return nil, false, amionDivisionsUnavailable(c)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "division id must be a UUID"))
+	}
+
+	division, err := h.services.AmionDivisions.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Amion division not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, division.HospitalID); !ok {
+		return nil, false, err
+	}
+
+	return division, true, nil
+}
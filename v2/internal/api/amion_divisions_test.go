@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/schedcu/v2/internal/service/amion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmionDivisionRequest_ValidatesSelectorProfile(t *testing.T) {
+	h := &Handlers{services: &ServiceDeps{}}
+	request := func(profile string) *AmionDivisionRequest {
+		return &AmionDivisionRequest{Name: "Body", BaseURL: "https://www.amion.com", SelectorProfile: profile}
+	}
+
+	assert.NoError(t, request("").validate(h.selectorProfiles()), "empty is the default profile")
+	assert.NoError(t, request(amion.DefaultSelectorProfile).validate(h.selectorProfiles()))
+
+	err := request("amion-2019").validate(h.selectorProfiles())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"amion-2019"`)
+	assert.Contains(t, err.Error(), amion.DefaultSelectorProfile, "the known profiles are listed")
+}
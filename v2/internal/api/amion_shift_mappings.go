@@ -0,0 +1,265 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// AmionShiftMappingRequest creates or replaces an Amion shift mapping.
+// Location, start_time and end_time are optional conditions narrowing which
+// Amion rows the mapping applies to.
+type AmionShiftMappingRequest struct {
+	AmionLabel          string `json:"amion_label"`
+	Location            string `json:"location"`
+	StartTime           string `json:"start_time"` // HH:MM
+	EndTime             string `json:"end_time"`   // HH:MM
+	ShiftType           string `json:"shift_type"`
+	StudyType           string `json:"study_type"`
+	SpecialtyConstraint string `json:"specialty_constraint"`
+}
+
+// AmionShiftMappingResponse is the API representation of an Amion shift mapping
+type AmionShiftMappingResponse struct {
+	ID                  string    `json:"id"`
+	HospitalID          string    `json:"hospital_id"`
+	AmionLabel          string    `json:"amion_label"`
+	Location            string    `json:"location"`
+	StartTime           string    `json:"start_time"`
+	EndTime             string    `json:"end_time"`
+	ShiftType           string    `json:"shift_type"`
+	StudyType           string    `json:"study_type"`
+	SpecialtyConstraint string    `json:"specialty_constraint"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+func toAmionShiftMappingResponse(m *entity.AmionShiftMapping) AmionShiftMappingResponse {
+	return AmionShiftMappingResponse{
+		ID:                  m.ID.String(),
+		HospitalID:          m.HospitalID.String(),
+		AmionLabel:          m.AmionLabel,
+		Location:            m.Location,
+		StartTime:           m.StartTime,
+		EndTime:             m.EndTime,
+		ShiftType:           string(m.ShiftType),
+		StudyType:           string(m.StudyType),
+		SpecialtyConstraint: string(m.SpecialtyConstraint),
+		CreatedAt:           m.CreatedAt,
+		UpdatedAt:           m.UpdatedAt,
+	}
+}
+
+// validate checks the request and normalizes its label, location and times
+func (req *AmionShiftMappingRequest) validate() error {
+	req.AmionLabel = strings.TrimSpace(req.AmionLabel)
+	req.Location = strings.TrimSpace(req.Location)
+	if req.AmionLabel == "" {
+		return fmt.Errorf("amion_label is required")
+	}
+	for _, t := range []*string{&req.StartTime, &req.EndTime} {
+		if strings.TrimSpace(*t) == "" {
+			*t = ""
+			continue
+		}
+		normalized := entity.NormalizeClock(*t)
+		if normalized == "" {
+			return fmt.Errorf("start_time and end_time must be HH:MM")
+		}
+		*t = normalized
+	}
+	if !entity.ValidateShiftType(req.ShiftType) {
+		return fmt.Errorf("%w: %q", entity.ErrUnknownShiftType, req.ShiftType)
+	}
+	if !entity.ValidateStudyType(req.StudyType) {
+		return fmt.Errorf("unknown study type: %q", req.StudyType)
+	}
+	if !entity.ValidateSpecialty(req.SpecialtyConstraint) {
+		return fmt.Errorf("%w: %q", entity.ErrUnknownSpecialty, req.SpecialtyConstraint)
+	}
+	return nil
+}
+
+// apply copies the request onto a mapping
+func (req *AmionShiftMappingRequest) apply(m *entity.AmionShiftMapping) {
+	m.AmionLabel = req.AmionLabel
+	m.Location = req.Location
+	m.StartTime = req.StartTime
+	m.EndTime = req.EndTime
+	m.ShiftType = entity.ShiftType(req.ShiftType)
+	m.StudyType = entity.StudyType(req.StudyType)
+	m.SpecialtyConstraint = entity.SpecialtyType(req.SpecialtyConstraint)
+}
+
+// sameConditions reports whether two mappings would match exactly the same rows
+func sameConditions(a, b *entity.AmionShiftMapping) bool {
+	return strings.EqualFold(a.AmionLabel, b.AmionLabel) && strings.EqualFold(a.Location, b.Location) &&
+		a.StartTime == b.StartTime && a.EndTime == b.EndTime
+}
+
+// amionShiftMappingsUnavailable responds when no shift mapping repository is configured
+func amionShiftMappingsUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("SHIFT_MAPPINGS_UNAVAILABLE", "Amion shift mappings are not configured"))
+}
+
+// checkDuplicateMapping responds with 409 if another live mapping of the
+// hospital has the same label and conditions
+func (h *Handlers) checkDuplicateMapping(c echo.Context, mapping *entity.AmionShiftMapping) (bool, error) {
+	existing, err := h.services.AmionShiftMappings.GetByHospital(c.Request().Context(), mapping.HospitalID)
+	if err != nil {
+		return false, c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list Amion shift mappings: %v", err)))
+	}
+	for _, other := range existing {
+		if other.ID != mapping.ID && sameConditions(other, mapping) {
+			return false, c.JSON(http.StatusConflict, ErrorResponseWithCode("DUPLICATE_MAPPING",
+				fmt.Sprintf("Amion label %q already has a mapping with the same location and times (%s)", mapping.AmionLabel, other.ID)))
+		}
+	}
+	return true, nil
+}
+
+// ListAmionShiftMappings lists a hospital's Amion shift mappings
+func (h *Handlers) ListAmionShiftMappings(c echo.Context) error {
+	if h.services.AmionShiftMappings == nil {
+		return amionShiftMappingsUnavailable(c)
+	}
+
+	hospitalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital id must be a UUID"))
+	}
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	mappings, err := h.services.AmionShiftMappings.GetByHospital(c.Request().Context(), hospitalID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list Amion shift mappings: %v", err)))
+	}
+
+	resp := make([]AmionShiftMappingResponse, 0, len(mappings))
+	for _, m := range mappings {
+		resp = append(resp, toAmionShiftMappingResponse(m))
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// CreateAmionShiftMapping adds an Amion shift mapping to a hospital
+func (h *Handlers) CreateAmionShiftMapping(c echo.Context) error {
+	if h.services.AmionShiftMappings == nil {
+		return amionShiftMappingsUnavailable(c)
+	}
+
+	hospitalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital id must be a UUID"))
+	}
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	var req AmionShiftMappingRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	if err := req.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_MAPPING", err.Error()))
+	}
+
+	// TODO: Get creator ID from authenticated user
+	creatorID := entity.UserID(uuid.New())
+
+	now := entity.Now()
+	mapping := &entity.AmionShiftMapping{
+		ID:         uuid.New(),
+		HospitalID: hospitalID,
+		CreatedAt:  now,
+		CreatedBy:  creatorID,
+		UpdatedAt:  now,
+	}
+	req.apply(mapping)
+
+	if ok, err := h.checkDuplicateMapping(c, mapping); !ok {
+		return err
+	}
+	if err := h.services.AmionShiftMappings.Create(c.Request().Context(), mapping); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("MAPPING_CREATE_FAILED", fmt.Sprintf("Failed to create Amion shift mapping: %v", err)))
+	}
+
+	return c.JSON(http.StatusCreated, SuccessResponse(toAmionShiftMappingResponse(mapping)))
+}
+
+// UpdateAmionShiftMapping replaces an Amion shift mapping
+func (h *Handlers) UpdateAmionShiftMapping(c echo.Context) error {
+	mapping, ok, err := h.loadAmionShiftMapping(c)
+	if !ok {
+		return err
+	}
+
+	var req AmionShiftMappingRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	if err := req.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_MAPPING", err.Error()))
+	}
+
+	req.apply(mapping)
+	mapping.UpdatedAt = entity.Now()
+
+	if ok, err := h.checkDuplicateMapping(c, mapping); !ok {
+		return err
+	}
+	if err := h.services.AmionShiftMappings.Update(c.Request().Context(), mapping); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("MAPPING_UPDATE_FAILED", fmt.Sprintf("Failed to update Amion shift mapping: %v", err)))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(toAmionShiftMappingResponse(mapping)))
+}
+
+// DeleteAmionShiftMapping removes an Amion shift mapping; shifts already
+// imported through it are kept
+func (h *Handlers) DeleteAmionShiftMapping(c echo.Context) error {
+	mapping, ok, err := h.loadAmionShiftMapping(c)
+	if !ok {
+		return err
+	}
+
+	// TODO: Get deleter ID from authenticated user
+	deleterID := entity.UserID(uuid.New())
+
+	if err := h.services.AmionShiftMappings.Delete(c.Request().Context(), mapping.ID, deleterID); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("MAPPING_DELETE_FAILED", fmt.Sprintf("Failed to delete Amion shift mapping: %v", err)))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// loadAmionShiftMapping loads the mapping named by the :id path parameter and
+// checks the caller may manage its hospital
+func (h *Handlers) loadAmionShiftMapping(c echo.Context) (*entity.AmionShiftMapping, bool, error) {
+	if h.services.AmionShiftMappings == nil {
+		return nil, false, amionShiftMappingsUnavailable(c)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "mapping id must be a UUID"))
+	}
+
+	mapping, err := h.services.AmionShiftMappings.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Amion shift mapping not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, mapping.HospitalID); !ok {
+		return nil, false, err
+	}
+
+	return mapping, true, nil
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// AssignmentResponse is the API representation of an assignment
+type AssignmentResponse struct {
+	ID                string     `json:"id"`
+	PersonID          string     `json:"person_id"`
+	ShiftInstanceID   string     `json:"shift_instance_id"`
+	ScheduleDate      string     `json:"schedule_date"`
+	OriginalShiftType string     `json:"original_shift_type,omitempty"`
+	Source            string     `json:"source"`
+	Pinned            bool       `json:"pinned"`
+	ScrapeBatchID     *uuid.UUID `json:"scrape_batch_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UnpinnedAt        *time.Time `json:"unpinned_at,omitempty"`
+}
+
+func toAssignmentResponse(a *entity.Assignment) AssignmentResponse {
+	return AssignmentResponse{
+		ID:                a.ID.String(),
+		PersonID:          a.PersonID.String(),
+		ShiftInstanceID:   a.ShiftInstanceID.String(),
+		ScheduleDate:      a.ScheduleDate.Format("2006-01-02"),
+		OriginalShiftType: a.OriginalShiftType,
+		Source:            string(a.Source),
+		Pinned:            a.IsPinned(),
+		ScrapeBatchID:     a.ScrapeBatchID,
+		CreatedAt:         a.CreatedAt,
+		UnpinnedAt:        a.UnpinnedAt,
+	}
+}
+
+// assignmentsUnavailable responds when no assignment or shift repository is configured
+func assignmentsUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("ASSIGNMENTS_UNAVAILABLE", "Assignment editing is not configured"))
+}
+
+// UnpinAssignment lets the next import replace a MANUAL or OVERRIDE
+// assignment instead of skipping its shift/date
+func (h *Handlers) UnpinAssignment(c echo.Context) error {
+	assignment, ok, err := h.loadAssignment(c)
+	if !ok {
+		return err
+	}
+
+	// TODO: Get unpinner ID from authenticated user
+	unpinnerID := entity.UserID(uuid.New())
+
+	if err := assignment.Unpin(unpinnerID); err != nil {
+		if errors.Is(err, entity.ErrAssignmentNotPinned) {
+			return c.JSON(http.StatusConflict, ErrorResponseWithCode("NOT_PINNED", "Only pinned MANUAL or OVERRIDE assignments can be unpinned"))
+		}
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("UNPIN_FAILED", err.Error()))
+	}
+	if err := h.services.Assignments.Update(c.Request().Context(), assignment); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("UPDATE_FAILED", fmt.Sprintf("Failed to unpin assignment: %v", err)))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(toAssignmentResponse(assignment)))
+}
+
+// loadAssignment loads the assignment named by the :id path parameter and
+// checks the caller may see its shift's hospital
+func (h *Handlers) loadAssignment(c echo.Context) (*entity.Assignment, bool, error) {
+	if h.services.Assignments == nil || h.services.ShiftInstances == nil {
+		return nil, false, assignmentsUnavailable(c)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "id must be a UUID"))
+	}
+
+	ctx := c.Request().Context()
+	assignment, err := h.services.Assignments.GetByID(ctx, id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Assignment not found"))
+	}
+	shift, err := h.services.ShiftInstances.GetByID(ctx, assignment.ShiftInstanceID)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Assignment's shift not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, shift.HospitalID); !ok {
+		return nil, false, err
+	}
+
+	return assignment, true, nil
+}
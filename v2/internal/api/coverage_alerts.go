@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// CoverageAlertResponse is the API representation of a coverage gap alert
+type CoverageAlertResponse struct {
+	ID                string     `json:"id"`
+	HospitalID        string     `json:"hospital_id"`
+	ScheduleVersionID string     `json:"schedule_version_id"`
+	ShiftInstanceID   string     `json:"shift_instance_id"`
+	ShiftType         string     `json:"shift_type"`
+	StudyType         string     `json:"study_type,omitempty"`
+	ShiftStart        time.Time  `json:"shift_start"`
+	CoverageStatus    string     `json:"coverage_status"`
+	Required          int        `json:"required"`
+	Assigned          int        `json:"assigned"`
+	Tier              string     `json:"tier"`
+	State             string     `json:"state"`
+	ResolutionReason  string     `json:"resolution_reason,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	EscalatedAt       *time.Time `json:"escalated_at,omitempty"`
+	ResolvedAt        *time.Time `json:"resolved_at,omitempty"`
+}
+
+func toCoverageAlertResponse(a *entity.CoverageAlert) CoverageAlertResponse {
+	return CoverageAlertResponse{
+		ID:                a.ID.String(),
+		HospitalID:        a.HospitalID.String(),
+		ScheduleVersionID: a.ScheduleVersionID.String(),
+		ShiftInstanceID:   a.ShiftInstanceID.String(),
+		ShiftType:         string(a.ShiftType),
+		StudyType:         string(a.StudyType),
+		ShiftStart:        a.ShiftStart,
+		CoverageStatus:    a.CoverageStatus,
+		Required:          a.Required,
+		Assigned:          a.Assigned,
+		Tier:              string(a.Tier),
+		State:             string(a.State),
+		ResolutionReason:  a.ResolutionReason,
+		CreatedAt:         a.CreatedAt,
+		EscalatedAt:       a.EscalatedAt,
+		ResolvedAt:        a.ResolvedAt,
+	}
+}
+
+// ListCoverageAlerts lists a hospital's coverage gap alerts (open ones by default)
+func (h *Handlers) ListCoverageAlerts(c echo.Context) error {
+	if h.services.CoverageAlerts == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("ALERTS_UNAVAILABLE", "Coverage alerts are not configured"))
+	}
+
+	hospitalParam := c.QueryParam("hospital_id")
+	if hospitalParam == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("MISSING_PARAM", "hospital_id query parameter required"))
+	}
+	hospitalID, err := uuid.Parse(hospitalParam)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital_id must be a UUID"))
+	}
+
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	state := entity.AlertStateOpen
+	switch c.QueryParam("state") {
+	case "", string(entity.AlertStateOpen):
+	case string(entity.AlertStateResolved):
+		state = entity.AlertStateResolved
+	case "ALL":
+		state = ""
+	default:
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "state must be OPEN, RESOLVED or ALL"))
+	}
+
+	alerts, err := h.services.CoverageAlerts.GetByHospital(c.Request().Context(), hospitalID, state)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list alerts: %v", err)))
+	}
+
+	resp := make([]CoverageAlertResponse, 0, len(alerts))
+	for _, a := range alerts {
+		resp = append(resp, toCoverageAlertResponse(a))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
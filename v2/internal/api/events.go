@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/event"
+)
+
+// sseHeartbeatInterval keeps idle connections open through proxies and load balancers
+var sseHeartbeatInterval = 15 * time.Second
+
+// StreamEvents streams job, version and coverage events for a hospital as Server-Sent Events.
+// Clients resume after a disconnect by sending the Last-Event-ID header (browsers do
+// this automatically) or the last_event_id query parameter.
+func (h *Handlers) StreamEvents(c echo.Context) error {
+	if h.services.Events == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("EVENTS_UNAVAILABLE", "Event stream is not configured"))
+	}
+
+	hospitalParam := c.QueryParam("hospital_id")
+	if hospitalParam == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("MISSING_PARAM", "hospital_id query parameter required"))
+	}
+	hospitalID, err := uuid.Parse(hospitalParam)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital_id must be a UUID"))
+	}
+
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+	var afterID int64
+	if lastEventID != "" {
+		afterID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || afterID < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "Last-Event-ID must be a non-negative integer"))
+		}
+	}
+
+	ctx := c.Request().Context()
+	events, err := h.services.Events.Subscribe(ctx, hospitalID, afterID)
+	if err != nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("EVENTS_UNAVAILABLE", fmt.Sprintf("Failed to subscribe: %v", err)))
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case evt, ok := <-events:
+			if !ok {
+				// Bus closed or client too slow; the client reconnects with Last-Event-ID
+				return nil
+			}
+			if err := writeSSEEvent(res, evt); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeSSEEvent writes a single event in text/event-stream framing
+func writeSSEEvent(w *echo.Response, evt *event.Event) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, payload)
+	return err
+}
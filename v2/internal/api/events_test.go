@@ -0,0 +1,254 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUserRepository is a mock user repository for testing
+type MockUserRepository struct {
+	users map[uuid.UUID]*entity.User
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	if u, ok := m.users[id]; ok {
+		return u, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return nil, errors.New("not found")
+}
+
+func (m *MockUserRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserRepository) GetByRole(ctx context.Context, role entity.UserRole) ([]*entity.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *entity.User) error {
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	delete(m.users, id)
+	return nil
+}
+
+func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
+	return int64(len(m.users)), nil
+}
+
+// newEventsServer starts a test server exposing only the SSE endpoint
+func newEventsServer(t *testing.T, services *ServiceDeps) *httptest.Server {
+	t.Helper()
+	handlers := &Handlers{services: services}
+
+	e := echo.New()
+	e.GET("/api/events", handlers.StreamEvents)
+
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// readSSEEvent reads lines until a complete event frame has been received
+func readSSEEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	t.Helper()
+	frame := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+
+		if line == "" {
+			if len(frame) > 0 {
+				return frame
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			frame["comment"] = strings.TrimSpace(line[1:])
+			return frame
+		}
+		parts := strings.SplitN(line, ": ", 2)
+		require.Len(t, parts, 2)
+		frame[parts[0]] = parts[1]
+	}
+}
+
+func TestStreamEvents_DeliversAndResumes(t *testing.T) {
+	bus := event.NewMemoryBus(10)
+	defer bus.Close()
+
+	hospitalID := uuid.New()
+	srv := newEventsServer(t, &ServiceDeps{Events: bus, AllowUnauthenticated: true})
+
+	// Published before connecting; only replayed when resuming
+	require.NoError(t, bus.Publish(context.Background(), event.New(event.TypeJobStateChanged, hospitalID, map[string]interface{}{"state": "RUNNING"})))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/events?hospital_id="+hospitalID.String(), nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "0")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	require.NoError(t, bus.Publish(context.Background(), event.New(event.TypeVersionPromoted, uuid.New(), nil)))
+	require.NoError(t, bus.Publish(context.Background(), event.New(event.TypeVersionPromoted, hospitalID, nil)))
+
+	frame := readSSEEvent(t, reader)
+	assert.Equal(t, "3", frame["id"])
+	assert.Equal(t, string(event.TypeVersionPromoted), frame["event"])
+	assert.Contains(t, frame["data"], hospitalID.String())
+
+	// Reconnect after the first event and receive only what was missed
+	resp.Body.Close()
+
+	resp2, err := http.Get(srv.URL + "/api/events?hospital_id=" + hospitalID.String() + "&last_event_id=1")
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+
+	frame = readSSEEvent(t, bufio.NewReader(resp2.Body))
+	assert.Equal(t, "3", frame["id"])
+}
+
+func TestStreamEvents_Heartbeat(t *testing.T) {
+	original := sseHeartbeatInterval
+	sseHeartbeatInterval = 20 * time.Millisecond
+	defer func() { sseHeartbeatInterval = original }()
+
+	bus := event.NewMemoryBus(10)
+	defer bus.Close()
+
+	srv := newEventsServer(t, &ServiceDeps{Events: bus, AllowUnauthenticated: true})
+
+	resp, err := http.Get(srv.URL + "/api/events?hospital_id=" + uuid.New().String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	frame := readSSEEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "heartbeat", frame["comment"])
+}
+
+func TestStreamEvents_Authorization(t *testing.T) {
+	bus := event.NewMemoryBus(10)
+	defer bus.Close()
+
+	hospitalID := uuid.New()
+	otherHospital := uuid.New()
+
+	scheduler := &entity.User{ID: uuid.New(), Role: entity.UserRoleScheduler, HospitalID: &hospitalID, Active: true}
+	admin := &entity.User{ID: uuid.New(), Role: entity.UserRoleAdmin, Active: true}
+	inactive := &entity.User{ID: uuid.New(), Role: entity.UserRoleViewer, HospitalID: &hospitalID}
+
+	users := &MockUserRepository{users: map[uuid.UUID]*entity.User{
+		scheduler.ID: scheduler,
+		admin.ID:     admin,
+		inactive.ID:  inactive,
+	}}
+	srv := newEventsServer(t, &ServiceDeps{Events: bus, Users: users})
+
+	tests := []struct {
+		name           string
+		userID         string
+		hospitalID     uuid.UUID
+		expectedStatus int
+	}{
+		{"missing user header", "", hospitalID, http.StatusUnauthorized},
+		{"unknown user", uuid.New().String(), hospitalID, http.StatusUnauthorized},
+		{"inactive user", inactive.ID.String(), hospitalID, http.StatusUnauthorized},
+		{"other hospital", scheduler.ID.String(), otherHospital, http.StatusForbidden},
+		{"own hospital", scheduler.ID.String(), hospitalID, http.StatusOK},
+		{"system admin", admin.ID.String(), otherHospital, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/events?hospital_id="+tt.hospitalID.String(), nil)
+			require.NoError(t, err)
+			if tt.userID != "" {
+				req.Header.Set(HeaderUserID, tt.userID)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestStreamEvents_RefusedWithoutUserDirectory(t *testing.T) {
+	bus := event.NewMemoryBus(10)
+	defer bus.Close()
+	srv := newEventsServer(t, &ServiceDeps{Events: bus})
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/events?hospital_id="+uuid.New().String(), nil)
+	require.NoError(t, err)
+	req.Header.Set(HeaderUserID, uuid.New().String())
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "only the explicit dev flag skips authorization")
+}
+
+func TestStreamEvents_InvalidParams(t *testing.T) {
+	bus := event.NewMemoryBus(10)
+	defer bus.Close()
+
+	handlers := &Handlers{services: &ServiceDeps{Events: bus, AllowUnauthenticated: true}}
+
+	tests := []struct {
+		name         string
+		url          string
+		expectedCode string
+	}{
+		{"missing hospital", "/api/events", "MISSING_PARAM"},
+		{"invalid hospital", "/api/events?hospital_id=abc", "INVALID_PARAM"},
+		{"invalid last event id", "/api/events?hospital_id=" + uuid.New().String() + "&last_event_id=x", "INVALID_PARAM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := handlers.StreamEvents(c)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedCode)
+		})
+	}
+}
@@ -12,8 +12,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
 	"github.com/schedcu/v2/internal/job"
+	"github.com/schedcu/v2/internal/secrets"
 	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/service/freshness"
 	"github.com/schedcu/v2/internal/validation"
 )
 
@@ -119,6 +122,9 @@ func (h *Handlers) PromoteScheduleVersion(c echo.Context) error {
 	// TODO: Get promoter ID from authenticated user
 	promoterID := entity.UserID(uuid.New())
 
+	// Remember the outgoing PRODUCTION version so people can be told what changed
+	outgoingID := h.currentProductionVersion(c, versionID)
+
 	// Promote to production (and archive others)
 	if err := h.services.VersionService.PromoteAndArchiveOthers(c.Request().Context(), versionID, promoterID); err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("PROMOTE_FAILED", fmt.Sprintf("Failed to promote version: %v", err)))
@@ -126,9 +132,55 @@ func (h *Handlers) PromoteScheduleVersion(c echo.Context) error {
 
 	version, _ := h.services.VersionService.GetVersion(c.Request().Context(), versionID)
 
+	if h.scheduler != nil && version != nil {
+		if _, err := h.scheduler.EnqueueVersionNotifications(c.Request().Context(), version.HospitalID, outgoingID, versionID); err != nil {
+			c.Logger().Warnf("Failed to enqueue schedule change notifications: %v", err)
+		}
+	}
+
+	if h.services.Events != nil && version != nil {
+		evt := event.New(event.TypeVersionPromoted, version.HospitalID, map[string]interface{}{
+			"version_id":          version.ID.String(),
+			"previous_version_id": outgoingID.String(),
+			"promoted_by":         promoterID.String(),
+		})
+		if err := h.services.Events.Publish(c.Request().Context(), evt); err != nil {
+			c.Logger().Warnf("Failed to publish version promotion event: %v", err)
+		}
+	}
+
 	return c.JSON(http.StatusOK, SuccessResponse(version))
 }
 
+// currentProductionVersion returns the hospital's PRODUCTION version that promoting
+// versionID will replace: the one sharing the most dates with it, or uuid.Nil if
+// no PRODUCTION version overlaps
+func (h *Handlers) currentProductionVersion(c echo.Context, versionID entity.ScheduleVersionID) entity.ScheduleVersionID {
+	ctx := c.Request().Context()
+
+	version, err := h.services.VersionService.GetVersion(ctx, versionID)
+	if err != nil || version == nil {
+		return entity.ScheduleVersionID(uuid.Nil)
+	}
+
+	production, err := h.services.VersionService.ListVersionsByStatus(ctx, version.HospitalID, entity.VersionStatusProduction)
+	if err != nil {
+		return entity.ScheduleVersionID(uuid.Nil)
+	}
+
+	replaced, mostDays := entity.ScheduleVersionID(uuid.Nil), 0
+	for _, v := range production {
+		if v.ID == versionID {
+			continue
+		}
+		start, end, ok := entity.Overlap(v.EffectiveStartDate, v.EffectiveEndDate, version.EffectiveStartDate, version.EffectiveEndDate)
+		if days := int(end.Sub(start).Hours()/24) + 1; ok && days > mostDays {
+			replaced, mostDays = v.ID, days
+		}
+	}
+	return replaced
+}
+
 // ArchiveScheduleVersion archives a schedule version
 func (h *Handlers) ArchiveScheduleVersion(c echo.Context) error {
 	id := c.Param("id")
@@ -291,11 +343,13 @@ func getTotalAssignments(odsData *service.ODSData) int {
 	return total
 }
 
-// StartAmionImportRequest represents a request to start Amion import
+// StartAmionImportRequest represents a request to start Amion import.
+// CredentialsRef names the secret holding the Amion login; it defaults to the
+// hospital's conventional secret (amion/<hospital_id>).
 type StartAmionImportRequest struct {
 	ScheduleVersionID string `json:"schedule_version_id" validate:"required"`
 	MonthsBack        int    `json:"months_back" validate:"required,min=1,max=24"`
-	Username          string `json:"username" validate:"required"`
+	CredentialsRef    string `json:"credentials_ref,omitempty"`
 }
 
 // StartAmionImport enqueues an Amion scraping job
@@ -314,11 +368,19 @@ func (h *Handlers) StartAmionImport(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("ERROR", "Schedule version not found"))
 	}
 
+	credentialsRef := req.CredentialsRef
+	if credentialsRef == "" {
+		credentialsRef = secrets.AmionCredentialsName(version.HospitalID)
+	}
+	if err := secrets.ValidateName(credentialsRef); err != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_CREDENTIALS_REF", err.Error()))
+	}
+
 	// TODO: Get creator ID from authenticated user
 	creatorID := entity.UserID(uuid.New())
 
 	// Enqueue scrape job
-	info, err := h.scheduler.EnqueueAmionScrape(c.Request().Context(), version.HospitalID, versionID, req.MonthsBack, req.Username, creatorID)
+	info, err := h.scheduler.EnqueueAmionScrape(c.Request().Context(), version.HospitalID, versionID, req.MonthsBack, credentialsRef, creatorID)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("ERROR", fmt.Sprintf("Failed to enqueue job: %v", err)))
 	}
@@ -335,8 +397,8 @@ type StartFullWorkflowRequest struct {
 	StartDate  string `json:"start_date" validate:"required"`
 	EndDate    string `json:"end_date" validate:"required"`
 	Filename   string `json:"filename" validate:"required"`
-	MonthsBack int    `json:"months_back" validate:"min=1,max=24"`
-	Username   string `json:"username"`
+	MonthsBack     int    `json:"months_back" validate:"min=1,max=24"`
+	CredentialsRef string `json:"credentials_ref,omitempty"` // Secret name of the Amion login
 }
 
 // StartFullWorkflow starts the full 3-phase workflow
@@ -440,3 +502,45 @@ func (h *Handlers) HealthRedis(c echo.Context) error {
 		"redis": "UP",
 	}))
 }
+
+// HealthScrapes returns Amion scrape freshness per hospital (or for ?hospital_id=).
+// Responds 503 when any hospital has a stale, failed or anomalous scrape.
+func (h *Handlers) HealthScrapes(c echo.Context) error {
+	if h.services.ScrapeFreshness == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("FRESHNESS_UNAVAILABLE", "Scrape freshness monitoring is not configured"))
+	}
+
+	var statuses []*freshness.Status
+	if hospitalParam := c.QueryParam("hospital_id"); hospitalParam != "" {
+		hospitalID, err := uuid.Parse(hospitalParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital_id must be a UUID"))
+		}
+		status, err := h.services.ScrapeFreshness.CheckHospital(c.Request().Context(), hospitalID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("FRESHNESS_CHECK_FAILED", err.Error()))
+		}
+		statuses = append(statuses, status)
+	} else {
+		var err error
+		statuses, err = h.services.ScrapeFreshness.Check(c.Request().Context())
+		if err != nil && len(statuses) == 0 {
+			return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("FRESHNESS_CHECK_FAILED", err.Error()))
+		}
+	}
+
+	overall := "UP"
+	code := http.StatusOK
+	for _, status := range statuses {
+		if !status.Healthy() {
+			overall = "DEGRADED"
+			code = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	return c.JSON(code, SuccessResponse(map[string]interface{}{
+		"scrapes":   overall,
+		"hospitals": statuses,
+	}))
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// NotificationPreferenceRequest updates a person's schedule-change notification settings
+type NotificationPreferenceRequest struct {
+	Enabled     bool     `json:"enabled"`
+	Channels    []string `json:"channels"`
+	PhoneNumber string   `json:"phone_number"`
+	DigestMode  string   `json:"digest_mode"`
+	DigestHour  int      `json:"digest_hour"`
+}
+
+// NotificationPreferenceResponse is the API representation of notification settings
+type NotificationPreferenceResponse struct {
+	PersonID    string     `json:"person_id"`
+	Enabled     bool       `json:"enabled"`
+	Channels    []string   `json:"channels"`
+	PhoneNumber string     `json:"phone_number,omitempty"`
+	DigestMode  string     `json:"digest_mode"`
+	DigestHour  int        `json:"digest_hour"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+func toNotificationPreferenceResponse(pref *entity.NotificationPreference) NotificationPreferenceResponse {
+	channels := make([]string, len(pref.Channels))
+	for i, ch := range pref.Channels {
+		channels[i] = string(ch)
+	}
+	resp := NotificationPreferenceResponse{
+		PersonID:    pref.PersonID.String(),
+		Enabled:     pref.Enabled,
+		Channels:    channels,
+		PhoneNumber: pref.PhoneNumber,
+		DigestMode:  string(pref.DigestMode),
+		DigestHour:  pref.DigestHour,
+	}
+	if !pref.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &pref.UpdatedAt
+	}
+	return resp
+}
+
+// GetNotificationPreferences returns a person's notification settings (defaults if never set)
+func (h *Handlers) GetNotificationPreferences(c echo.Context) error {
+	if h.services.NotificationPreferences == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("NOTIFICATIONS_UNAVAILABLE", "Notifications are not configured"))
+	}
+
+	personID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_ID", "Invalid person ID"))
+	}
+
+	pref, err := h.services.NotificationPreferences.GetByPerson(c.Request().Context(), personID)
+	if repository.IsNotFound(err) {
+		pref = entity.DefaultNotificationPreference(personID)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LOOKUP_FAILED", fmt.Sprintf("Failed to load preferences: %v", err)))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(toNotificationPreferenceResponse(pref)))
+}
+
+// UpdateNotificationPreferences replaces a person's notification settings
+func (h *Handlers) UpdateNotificationPreferences(c echo.Context) error {
+	if h.services.NotificationPreferences == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("NOTIFICATIONS_UNAVAILABLE", "Notifications are not configured"))
+	}
+
+	personID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_ID", "Invalid person ID"))
+	}
+
+	var req NotificationPreferenceRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+
+	pref := &entity.NotificationPreference{
+		PersonID:    personID,
+		Enabled:     req.Enabled,
+		PhoneNumber: req.PhoneNumber,
+		DigestMode:  entity.DigestMode(req.DigestMode),
+		DigestHour:  req.DigestHour,
+	}
+	if pref.DigestMode == "" {
+		pref.DigestMode = entity.DigestModeImmediate
+	}
+	if pref.DigestMode != entity.DigestModeImmediate && pref.DigestMode != entity.DigestModeDaily {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DIGEST_MODE", "digest_mode must be IMMEDIATE or DAILY"))
+	}
+	if pref.DigestHour < 0 || pref.DigestHour > 23 {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DIGEST_HOUR", "digest_hour must be between 0 and 23"))
+	}
+	for _, ch := range req.Channels {
+		channel := entity.NotificationChannel(ch)
+		if channel != entity.NotificationChannelEmail && channel != entity.NotificationChannelSMS {
+			return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_CHANNEL", fmt.Sprintf("Unknown channel %q", ch)))
+		}
+		if channel == entity.NotificationChannelSMS && req.PhoneNumber == "" {
+			return c.JSON(http.StatusBadRequest, ValidationErrorResponse("MISSING_PHONE_NUMBER", "phone_number is required for SMS"))
+		}
+		pref.Channels = append(pref.Channels, channel)
+	}
+
+	if err := h.services.NotificationPreferences.Upsert(c.Request().Context(), pref); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("UPDATE_FAILED", fmt.Sprintf("Failed to save preferences: %v", err)))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(toNotificationPreferenceResponse(pref)))
+}
@@ -3,8 +3,18 @@ package api
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/schedcu/v2/internal/event"
 	"github.com/schedcu/v2/internal/job"
+	"github.com/schedcu/v2/internal/repository"
 	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/service/amion"
+	"github.com/schedcu/v2/internal/service/clone"
+	"github.com/schedcu/v2/internal/service/editing"
+	"github.com/schedcu/v2/internal/service/freshness"
+	"github.com/schedcu/v2/internal/service/merge"
+	"github.com/schedcu/v2/internal/service/rollback"
+	"github.com/schedcu/v2/internal/service/templates"
 )
 
 // Router creates and configures the Echo router
@@ -17,11 +27,32 @@ type Router struct {
 
 // ServiceDeps holds all business logic services
 type ServiceDeps struct {
-	OdsImporter    service.ODSImportService
-	AmionImporter  service.AmionImportService
-	Orchestrator   service.ScheduleOrchestrator
-	CoverageCalc   service.CoverageCalculator
-	VersionService service.ScheduleVersionService
+	OdsImporter             service.ODSImportService
+	AmionImporter           service.AmionImportService
+	Orchestrator            service.ScheduleOrchestrator
+	CoverageCalc            service.CoverageCalculator
+	VersionService          service.ScheduleVersionService
+	Webhooks                service.WebhookService
+	NotificationPreferences repository.NotificationPreferenceRepository // Optional: enables notification settings endpoints
+	CoverageAlerts          repository.CoverageAlertRepository          // Optional: enables GET /api/coverage/alerts
+	ScrapeFreshness         *freshness.Checker                          // Optional: enables GET /api/health/scrapes
+	Events                  event.Bus                                   // Optional: enables GET /api/events
+	Users                   repository.UserRepository                   // Enables per-hospital authorization; without it hospital data is refused
+	AllowUnauthenticated    bool                                        // Development only: allows every request when Users is nil
+	AmionArchive            *amion.Archive                              // Optional: enables GET /api/scrape-batches/:id/snapshots
+	AmionReimporter         *amion.Reimporter                           // Optional: enables POST /api/scrape-batches/:id/reimport
+	AmionDivisions          repository.AmionDivisionRepository          // Optional: enables Amion division configuration endpoints
+	AmionShiftMappings      repository.AmionShiftMappingRepository      // Optional: enables Amion shift mapping endpoints
+	AmionScraper            *amion.Scraper                              // Optional: its selector profiles are the ones divisions may name
+	ScrapeBatches           repository.ScrapeBatchRepository            // Optional: enables GET /api/scrape-batches/:id/delta
+	BatchRollback           *rollback.Service                           // Optional: enables POST /api/batches/:id/rollback
+	Assignments             repository.AssignmentRepository             // Optional, with ShiftInstances: enables assignment endpoints
+	ShiftInstances          repository.ShiftInstanceRepository          // Optional, with Assignments: enables assignment endpoints
+	ScheduleEditor          *editing.Editor                             // Optional: enables hand edits to shifts and assignments
+	ScheduleMerges          *merge.Service                              // Optional: enables three-way merges of imports into edited STAGING versions
+	ScheduleCloner          *clone.Cloner                               // Optional: enables POST /api/schedules/:id/clone
+	ShiftTemplates          repository.ShiftTemplateRepository          // Optional: enables shift template endpoints
+	ShiftGenerator          *templates.Generator                        // Optional: enables template previews and POST /api/schedules/:id/shifts/generate
 }
 
 // NewRouter creates a new Echo router with all routes
@@ -38,7 +69,7 @@ func NewRouter(
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.PATCH},
-		AllowHeaders: []string{echo.HeaderContentType, echo.HeaderAuthorization},
+		AllowHeaders: []string{echo.HeaderContentType, echo.HeaderAuthorization, HeaderUserID, "Last-Event-ID"},
 	}))
 
 	r := &Router{
@@ -68,6 +99,12 @@ func (r *Router) registerRoutes() {
 	scheduleGroup.GET("", r.handlers.ListScheduleVersions)
 	scheduleGroup.POST("/:id/promote", r.handlers.PromoteScheduleVersion)
 	scheduleGroup.POST("/:id/archive", r.handlers.ArchiveScheduleVersion)
+	scheduleGroup.POST("/:id/shifts", r.handlers.AddShift)
+	scheduleGroup.POST("/:id/assignments/bulk", r.handlers.BulkEditAssignments)
+	scheduleGroup.POST("/:id/merge", r.handlers.MergeImport)
+	scheduleGroup.GET("/:id/merges", r.handlers.ListScheduleMerges)
+	scheduleGroup.POST("/:id/clone", r.handlers.CloneScheduleVersion)
+	scheduleGroup.POST("/:id/shifts/generate", r.handlers.GenerateShifts)
 
 	// Import operations
 	importGroup := r.echo.Group("/api/imports")
@@ -81,10 +118,70 @@ func (r *Router) registerRoutes() {
 	coverageGroup := r.echo.Group("/api/coverage")
 	coverageGroup.GET("/schedule/:scheduleID", r.handlers.GetScheduleCoverage)
 	coverageGroup.POST("/calculate", r.handlers.CalculateCoverage)
+	coverageGroup.GET("/alerts", r.handlers.ListCoverageAlerts)
+
+	// Archived Amion pages and offline re-import
+	scrapeBatchGroup := r.echo.Group("/api/scrape-batches")
+	scrapeBatchGroup.GET("/:id/snapshots", r.handlers.ListScrapeSnapshots)
+	scrapeBatchGroup.GET("/:id/snapshots/:snapshotID/html", r.handlers.GetScrapeSnapshotHTML)
+	scrapeBatchGroup.POST("/:id/reimport", r.handlers.ReimportScrapeBatch)
+	scrapeBatchGroup.GET("/:id/delta", r.handlers.GetScrapeBatchDelta)
+
+	// Undo everything a bad import batch created
+	r.echo.POST("/api/batches/:id/rollback", r.handlers.RollbackScrapeBatch)
+
+	// Hand edits to shifts and assignments
+	r.echo.PATCH("/api/shifts/:id", r.handlers.UpdateShift)
+	r.echo.POST("/api/shifts/:id/assignments", r.handlers.AddAssignment)
+	r.echo.POST("/api/assignments/swap", r.handlers.SwapAssignments)
+	r.echo.DELETE("/api/assignments/:id", r.handlers.DeleteAssignment)
+	r.echo.POST("/api/assignments/:id/unpin", r.handlers.UnpinAssignment)
+
+	// Merges of fresh imports into edited STAGING versions
+	r.echo.GET("/api/merges/:id", r.handlers.GetScheduleMerge)
+	r.echo.POST("/api/merges/:id/conflicts/:conflictId/resolve", r.handlers.ResolveMergeConflict)
+
+	// Per-hospital Amion divisions (separately published schedules)
+	r.echo.GET("/api/hospitals/:id/amion-divisions", r.handlers.ListAmionDivisions)
+	r.echo.POST("/api/hospitals/:id/amion-divisions", r.handlers.CreateAmionDivision)
+	r.echo.PUT("/api/amion-divisions/:id", r.handlers.UpdateAmionDivision)
+	r.echo.DELETE("/api/amion-divisions/:id", r.handlers.DeleteAmionDivision)
+
+	// Per-hospital Amion shift label -> shift slot mappings
+	r.echo.GET("/api/hospitals/:id/amion-shift-mappings", r.handlers.ListAmionShiftMappings)
+	r.echo.POST("/api/hospitals/:id/amion-shift-mappings", r.handlers.CreateAmionShiftMapping)
+	r.echo.PUT("/api/amion-shift-mappings/:id", r.handlers.UpdateAmionShiftMapping)
+	r.echo.DELETE("/api/amion-shift-mappings/:id", r.handlers.DeleteAmionShiftMapping)
+
+	// Per-hospital recurring shift templates
+	r.echo.GET("/api/hospitals/:id/shift-templates", r.handlers.ListShiftTemplates)
+	r.echo.POST("/api/hospitals/:id/shift-templates", r.handlers.CreateShiftTemplate)
+	r.echo.GET("/api/hospitals/:id/shift-templates/preview", r.handlers.PreviewShiftTemplates)
+	r.echo.PUT("/api/shift-templates/:id", r.handlers.UpdateShiftTemplate)
+	r.echo.DELETE("/api/shift-templates/:id", r.handlers.DeleteShiftTemplate)
+
+	// Webhooks
+	webhookGroup := r.echo.Group("/api/webhooks")
+	webhookGroup.POST("", r.handlers.CreateWebhook)
+	webhookGroup.GET("", r.handlers.ListWebhooks)
+	webhookGroup.DELETE("/:id", r.handlers.DeleteWebhook)
+	webhookGroup.GET("/:id/deliveries", r.handlers.ListWebhookDeliveries)
+	webhookGroup.POST("/:id/deliveries/:deliveryID/redeliver", r.handlers.RedeliverWebhook)
+
+	// Personal schedule-change notifications
+	r.echo.GET("/api/people/:id/notification-preferences", r.handlers.GetNotificationPreferences)
+	r.echo.PUT("/api/people/:id/notification-preferences", r.handlers.UpdateNotificationPreferences)
+
+	// Live updates (Server-Sent Events)
+	r.echo.GET("/api/events", r.handlers.StreamEvents)
 
 	// Health checks
 	r.echo.GET("/api/health/db", r.handlers.HealthDB)
 	r.echo.GET("/api/health/redis", r.handlers.HealthRedis)
+	r.echo.GET("/api/health/scrapes", r.handlers.HealthScrapes)
+
+	// Prometheus metrics
+	r.echo.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 }
 
 // Start starts the HTTP server
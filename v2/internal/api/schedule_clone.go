@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service/clone"
+)
+
+// CloneScheduleRequest copies a schedule version into a new period
+type CloneScheduleRequest struct {
+	StartDate          string   `json:"start_date"` // YYYY-MM-DD
+	EndDate            string   `json:"end_date"`   // YYYY-MM-DD
+	IncludeAssignments bool     `json:"include_assignments"`
+	RotationOffset     int      `json:"rotation_offset"` // Places each person moves along their specialty's rotation
+	Holidays           []string `json:"holidays"`        // Extra YYYY-MM-DD dates to staff as holidays
+}
+
+// ClonedDayResponse says which source date a new date was copied from
+type ClonedDayResponse struct {
+	Date       string `json:"date"`
+	DayType    string `json:"day_type"`
+	Holiday    string `json:"holiday,omitempty"`
+	SourceDate string `json:"source_date,omitempty"`
+}
+
+// CloneScheduleResponse is the new STAGING version and how it was built
+type CloneScheduleResponse struct {
+	Version            CreateScheduleVersionResponse `json:"version"`
+	SourceVersionID    string                        `json:"source_version_id"`
+	ShiftsCreated      int                           `json:"shifts_created"`
+	AssignmentsCreated int                           `json:"assignments_created"`
+	Days               []ClonedDayResponse           `json:"days"`
+}
+
+// CloneScheduleVersion creates a STAGING version for a new period from an
+// existing version's shift pattern and, optionally, its assignments
+func (h *Handlers) CloneScheduleVersion(c echo.Context) error {
+	if h.services.ScheduleCloner == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("CLONE_UNAVAILABLE", "Schedule cloning is not configured"))
+	}
+	source, ok, err := h.loadVersion(c)
+	if !ok {
+		return err
+	}
+
+	var req CloneScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	startDate, startErr := time.Parse("2006-01-02", req.StartDate)
+	endDate, endErr := time.Parse("2006-01-02", req.EndDate)
+	if startErr != nil || endErr != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", "start_date and end_date must be YYYY-MM-DD"))
+	}
+	holidays, err := parseHolidays(req.Holidays)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", err.Error()))
+	}
+
+	creatorID := requestUserID(c)
+
+	result, err := h.services.ScheduleCloner.Clone(c.Request().Context(), source.ID, clone.Options{
+		StartDate:          startDate,
+		EndDate:            endDate,
+		Holidays:           holidays,
+		IncludeAssignments: req.IncludeAssignments,
+		RotationOffset:     req.RotationOffset,
+		CreatorID:          creatorID,
+	})
+	switch {
+	case errors.Is(err, entity.ErrInvalidPeriod):
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_RANGE", err.Error()))
+	case errors.Is(err, clone.ErrSourceDeleted):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("VERSION_DELETED", err.Error()))
+	case repository.IsNotFound(err):
+		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Schedule version not found"))
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("CLONE_FAILED", fmt.Sprintf("Failed to clone schedule version: %v", err)))
+	}
+
+	resp := CloneScheduleResponse{
+		Version: CreateScheduleVersionResponse{
+			ID:        result.Version.ID.String(),
+			Status:    string(result.Version.Status),
+			StartDate: result.Version.EffectiveStartDate.Format("2006-01-02"),
+			EndDate:   result.Version.EffectiveEndDate.Format("2006-01-02"),
+			CreatedAt: result.Version.CreatedAt.Format(time.RFC3339),
+		},
+		SourceVersionID:    source.ID.String(),
+		ShiftsCreated:      result.Shifts,
+		AssignmentsCreated: result.Assignments,
+		Days:               make([]ClonedDayResponse, 0, len(result.Days)),
+	}
+	for _, d := range result.Days {
+		day := ClonedDayResponse{Date: d.Date.Format("2006-01-02"), DayType: string(d.DayType), Holiday: d.Holiday}
+		if d.SourceDate != nil {
+			day.SourceDate = d.SourceDate.Format("2006-01-02")
+		}
+		resp.Days = append(resp.Days, day)
+	}
+	return c.JSON(http.StatusCreated, ResponseWithValidation(resp, result.Validation))
+}
@@ -0,0 +1,425 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service/editing"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// AddAssignmentRequest puts a person on a shift
+type AddAssignmentRequest struct {
+	PersonID string `json:"person_id"`
+}
+
+// SwapAssignmentsRequest exchanges the people on two assignments
+type SwapAssignmentsRequest struct {
+	FirstAssignmentID  string `json:"first_assignment_id"`
+	SecondAssignmentID string `json:"second_assignment_id"`
+}
+
+// AddShiftRequest adds an ad-hoc shift to a schedule version
+type AddShiftRequest struct {
+	ShiftType           string `json:"shift_type"`
+	Date                string `json:"date"`       // YYYY-MM-DD
+	StartTime           string `json:"start_time"` // HH:MM
+	EndTime             string `json:"end_time"`   // HH:MM
+	StudyType           string `json:"study_type"`
+	SpecialtyConstraint string `json:"specialty_constraint"`
+	DesiredCoverage     int    `json:"desired_coverage"`
+	IsMandatory         bool   `json:"is_mandatory"`
+}
+
+// BulkAssignmentOperation is one add, remove or move in a bulk edit
+type BulkAssignmentOperation struct {
+	Type         string `json:"type"`          // add, remove or move
+	AssignmentID string `json:"assignment_id"` // remove, move
+	ShiftID      string `json:"shift_id"`      // add; move to another shift
+	PersonID     string `json:"person_id"`     // add; move to another person
+}
+
+// BulkAssignmentsRequest applies many assignment changes at once. With
+// Partial, operations that fail validation are skipped instead of rejecting
+// the whole set.
+type BulkAssignmentsRequest struct {
+	Operations []BulkAssignmentOperation `json:"operations"`
+	Partial    bool                      `json:"partial"`
+}
+
+// BulkOperationResponse reports what happened to one operation
+type BulkOperationResponse struct {
+	Index      int                  `json:"index"`
+	Type       string               `json:"type"`
+	Status     string               `json:"status"` // applied, rejected or not_applied
+	Assignment *AssignmentResponse  `json:"assignment,omitempty"`
+	Messages   []validation.Message `json:"messages,omitempty"`
+}
+
+// CoverageDeltaResponse is how one shift's staffing changed
+type CoverageDeltaResponse struct {
+	ShiftID         string `json:"shift_id"`
+	ShiftType       string `json:"shift_type"`
+	Date            string `json:"date"`
+	DesiredCoverage int    `json:"desired_coverage"`
+	Before          int    `json:"before"`
+	After           int    `json:"after"`
+}
+
+// BulkAssignmentsResponse reports a bulk edit
+type BulkAssignmentsResponse struct {
+	Applied       bool                    `json:"applied"`
+	Results       []BulkOperationResponse `json:"results"`
+	CoverageDelta []CoverageDeltaResponse `json:"coverage_delta"`
+}
+
+func toBulkAssignmentsResponse(result *editing.BulkResult) BulkAssignmentsResponse {
+	resp := BulkAssignmentsResponse{
+		Applied:       result.Applied,
+		Results:       make([]BulkOperationResponse, 0, len(result.Results)),
+		CoverageDelta: make([]CoverageDeltaResponse, 0, len(result.CoverageDelta)),
+	}
+	for _, r := range result.Results {
+		op := BulkOperationResponse{Index: r.Index, Type: string(r.Type), Status: string(r.Status), Messages: r.Messages}
+		if r.Assignment != nil {
+			a := toAssignmentResponse(r.Assignment)
+			op.Assignment = &a
+		}
+		resp.Results = append(resp.Results, op)
+	}
+	for _, d := range result.CoverageDelta {
+		resp.CoverageDelta = append(resp.CoverageDelta, CoverageDeltaResponse{
+			ShiftID:         d.ShiftID.String(),
+			ShiftType:       string(d.ShiftType),
+			Date:            d.ScheduleDate.Format("2006-01-02"),
+			DesiredCoverage: d.DesiredCoverage,
+			Before:          d.Before,
+			After:           d.After,
+		})
+	}
+	return resp
+}
+
+// UpdateShiftRequest changes how many people a shift needs
+type UpdateShiftRequest struct {
+	DesiredCoverage *int `json:"desired_coverage"`
+}
+
+// ShiftInstanceResponse is the API representation of a shift instance
+type ShiftInstanceResponse struct {
+	ID                  string     `json:"id"`
+	ScheduleVersionID   string     `json:"schedule_version_id"`
+	ShiftType           string     `json:"shift_type"`
+	Date                string     `json:"date"`
+	StartTime           string     `json:"start_time"`
+	EndTime             string     `json:"end_time"`
+	StudyType           string     `json:"study_type,omitempty"`
+	SpecialtyConstraint string     `json:"specialty_constraint,omitempty"`
+	DesiredCoverage     int        `json:"desired_coverage"`
+	IsMandatory         bool       `json:"is_mandatory"`
+	ScrapeBatchID       *uuid.UUID `json:"scrape_batch_id,omitempty"`
+}
+
+func toShiftInstanceResponse(s *entity.ShiftInstance) ShiftInstanceResponse {
+	return ShiftInstanceResponse{
+		ID:                  s.ID.String(),
+		ScheduleVersionID:   s.ScheduleVersionID.String(),
+		ShiftType:           string(s.ShiftType),
+		Date:                s.ScheduleDate.Format("2006-01-02"),
+		StartTime:           s.StartTime,
+		EndTime:             s.EndTime,
+		StudyType:           string(s.StudyType),
+		SpecialtyConstraint: string(s.SpecialtyConstraint),
+		DesiredCoverage:     s.DesiredCoverage,
+		IsMandatory:         s.IsMandatory,
+		ScrapeBatchID:       s.ScrapeBatchID,
+	}
+}
+
+// editOptions reads who is editing and whether a PRODUCTION version may be
+// changed (?confirm_production=true)
+func editOptions(c echo.Context) editing.Options {
+	confirmed, _ := strconv.ParseBool(c.QueryParam("confirm_production"))
+	return editing.Options{EditorID: requestUserID(c), AllowProduction: confirmed}
+}
+
+// editFailed responds to an editing error
+func editFailed(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, editing.ErrProductionLocked):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("PRODUCTION_LOCKED", err.Error()+": pass confirm_production=true"))
+	case errors.Is(err, editing.ErrVersionArchived):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("VERSION_ARCHIVED", err.Error()))
+	case repository.IsNotFound(err):
+		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", err.Error()))
+	}
+	return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("EDIT_FAILED", fmt.Sprintf("Failed to edit schedule: %v", err)))
+}
+
+// editRejected responds to an edit that broke a scheduling rule
+func editRejected(c echo.Context, vr *validation.Result) error {
+	return c.JSON(http.StatusUnprocessableEntity, ResponseWithValidation(nil, vr))
+}
+
+// scheduleEditingAvailable responds with 503 and returns false when editing is not configured
+func (h *Handlers) scheduleEditingAvailable(c echo.Context) (bool, error) {
+	if h.services.ScheduleEditor == nil || h.services.ShiftInstances == nil || h.services.Assignments == nil {
+		return false, assignmentsUnavailable(c)
+	}
+	return true, nil
+}
+
+// loadShift loads the shift named by the :id path parameter and checks the
+// caller may see its hospital
+func (h *Handlers) loadShift(c echo.Context) (*entity.ShiftInstance, bool, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "id must be a UUID"))
+	}
+
+	shift, err := h.services.ShiftInstances.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Shift not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, shift.HospitalID); !ok {
+		return nil, false, err
+	}
+	return shift, true, nil
+}
+
+// loadVersion loads the schedule version named by the :id path parameter and
+// checks the caller may see its hospital
+func (h *Handlers) loadVersion(c echo.Context) (*entity.ScheduleVersion, bool, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "id must be a UUID"))
+	}
+
+	version, err := h.services.VersionService.GetVersion(c.Request().Context(), id)
+	if err != nil || version == nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Schedule version not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, version.HospitalID); !ok {
+		return nil, false, err
+	}
+	return version, true, nil
+}
+
+// AddAssignment puts a person on a shift by hand
+func (h *Handlers) AddAssignment(c echo.Context) error {
+	if ok, err := h.scheduleEditingAvailable(c); !ok {
+		return err
+	}
+	shift, ok, err := h.loadShift(c)
+	if !ok {
+		return err
+	}
+
+	var req AddAssignmentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	personID, err := uuid.Parse(req.PersonID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_PERSON_ID", "person_id must be a UUID"))
+	}
+
+	assignment, vr, err := h.services.ScheduleEditor.AddAssignment(c.Request().Context(), shift.ID, personID, editOptions(c))
+	if err != nil {
+		return editFailed(c, err)
+	}
+	if vr.HasErrors() {
+		return editRejected(c, vr)
+	}
+	return c.JSON(http.StatusCreated, ResponseWithValidation(toAssignmentResponse(assignment), vr))
+}
+
+// DeleteAssignment takes a person off a shift by hand
+func (h *Handlers) DeleteAssignment(c echo.Context) error {
+	if ok, err := h.scheduleEditingAvailable(c); !ok {
+		return err
+	}
+	assignment, ok, err := h.loadAssignment(c)
+	if !ok {
+		return err
+	}
+
+	vr, err := h.services.ScheduleEditor.RemoveAssignment(c.Request().Context(), assignment.ID, editOptions(c))
+	if err != nil {
+		return editFailed(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseWithValidation(map[string]string{"id": assignment.ID.String()}, vr))
+}
+
+// SwapAssignments exchanges the people on two assignments
+func (h *Handlers) SwapAssignments(c echo.Context) error {
+	if ok, err := h.scheduleEditingAvailable(c); !ok {
+		return err
+	}
+
+	var req SwapAssignmentsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	firstID, firstErr := uuid.Parse(req.FirstAssignmentID)
+	secondID, secondErr := uuid.Parse(req.SecondAssignmentID)
+	if firstErr != nil || secondErr != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_ASSIGNMENT_ID", "first_assignment_id and second_assignment_id must be UUIDs"))
+	}
+
+	ctx := c.Request().Context()
+	for _, id := range []uuid.UUID{firstID, secondID} {
+		assignment, err := h.services.Assignments.GetByID(ctx, id)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Assignment not found"))
+		}
+		shift, err := h.services.ShiftInstances.GetByID(ctx, assignment.ShiftInstanceID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Assignment's shift not found"))
+		}
+		if ok, err := h.authorizeHospital(c, shift.HospitalID); !ok {
+			return err
+		}
+	}
+
+	swapped, vr, err := h.services.ScheduleEditor.SwapAssignments(ctx, firstID, secondID, editOptions(c))
+	if err != nil {
+		return editFailed(c, err)
+	}
+	if vr.HasErrors() {
+		return editRejected(c, vr)
+	}
+	resp := make([]AssignmentResponse, 0, len(swapped))
+	for _, a := range swapped {
+		resp = append(resp, toAssignmentResponse(a))
+	}
+	return c.JSON(http.StatusOK, ResponseWithValidation(resp, vr))
+}
+
+// AddShift adds an ad-hoc shift instance to a schedule version
+func (h *Handlers) AddShift(c echo.Context) error {
+	if ok, err := h.scheduleEditingAvailable(c); !ok {
+		return err
+	}
+
+	version, ok, err := h.loadVersion(c)
+	if !ok {
+		return err
+	}
+
+	var req AddShiftRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", "date must be YYYY-MM-DD"))
+	}
+
+	spec := &entity.ShiftInstance{
+		ShiftType:           entity.ShiftType(req.ShiftType),
+		ScheduleDate:        date,
+		StartTime:           req.StartTime,
+		EndTime:             req.EndTime,
+		StudyType:           entity.StudyType(req.StudyType),
+		SpecialtyConstraint: entity.SpecialtyType(req.SpecialtyConstraint),
+		DesiredCoverage:     req.DesiredCoverage,
+		IsMandatory:         req.IsMandatory,
+	}
+	shift, vr, err := h.services.ScheduleEditor.AddShift(c.Request().Context(), version.ID, spec, editOptions(c))
+	if err != nil {
+		return editFailed(c, err)
+	}
+	if vr.HasErrors() {
+		return editRejected(c, vr)
+	}
+	return c.JSON(http.StatusCreated, ResponseWithValidation(toShiftInstanceResponse(shift), vr))
+}
+
+// UpdateShift changes a shift's desired coverage
+func (h *Handlers) UpdateShift(c echo.Context) error {
+	if ok, err := h.scheduleEditingAvailable(c); !ok {
+		return err
+	}
+	shift, ok, err := h.loadShift(c)
+	if !ok {
+		return err
+	}
+
+	var req UpdateShiftRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	if req.DesiredCoverage == nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("MISSING_FIELD", "desired_coverage is required"))
+	}
+
+	updated, vr, err := h.services.ScheduleEditor.SetDesiredCoverage(c.Request().Context(), shift.ID, *req.DesiredCoverage, editOptions(c))
+	if err != nil {
+		return editFailed(c, err)
+	}
+	if vr.HasErrors() {
+		return editRejected(c, vr)
+	}
+	return c.JSON(http.StatusOK, ResponseWithValidation(toShiftInstanceResponse(updated), vr))
+}
+
+// BulkEditAssignments applies a list of assignment adds, removes and moves to
+// a schedule version in one transaction
+func (h *Handlers) BulkEditAssignments(c echo.Context) error {
+	if ok, err := h.scheduleEditingAvailable(c); !ok {
+		return err
+	}
+	version, ok, err := h.loadVersion(c)
+	if !ok {
+		return err
+	}
+
+	var req BulkAssignmentsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+
+	ops := make([]editing.BulkOperation, 0, len(req.Operations))
+	for i, op := range req.Operations {
+		parsed := editing.BulkOperation{Type: editing.BulkOperationType(op.Type)}
+		for _, field := range []struct {
+			name  string
+			value string
+			id    *uuid.UUID
+		}{
+			{"assignment_id", op.AssignmentID, &parsed.AssignmentID},
+			{"shift_id", op.ShiftID, &parsed.ShiftID},
+			{"person_id", op.PersonID, &parsed.PersonID},
+		} {
+			if field.value == "" {
+				continue
+			}
+			id, err := uuid.Parse(field.value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_OPERATION",
+					fmt.Sprintf("operations[%d].%s must be a UUID", i, field.name)))
+			}
+			*field.id = id
+		}
+		ops = append(ops, parsed)
+	}
+
+	result, err := h.services.ScheduleEditor.ApplyBulk(c.Request().Context(), version.ID, ops, req.Partial, editOptions(c))
+	if err != nil {
+		return editFailed(c, err)
+	}
+	if !result.Applied {
+		return c.JSON(http.StatusUnprocessableEntity, ResponseWithValidation(toBulkAssignmentsResponse(result), result.Validation))
+	}
+	return c.JSON(http.StatusOK, ResponseWithValidation(toBulkAssignmentsResponse(result), result.Validation))
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/service/editing"
+	"github.com/schedcu/v2/tests/helpers"
+	"github.com/schedcu/v2/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddShift_RecordsTheCallingUser(t *testing.T) {
+	schedule := mocks.NewMockSchedule()
+	hospitalID := uuid.New()
+	version := helpers.NewScheduleVersionBuilder().WithHospitalID(hospitalID).WithStatus(entity.VersionStatusStaging).
+		WithEffectiveStartDate(helpers.Date("2025-11-01")).WithEffectiveEndDate(helpers.Date("2025-11-30")).Build()
+	require.NoError(t, schedule.Versions.Create(context.Background(), version))
+	scheduler := &entity.User{ID: uuid.New(), Role: entity.UserRoleScheduler, HospitalID: &hospitalID, Active: true}
+
+	h := &Handlers{services: &ServiceDeps{
+		VersionService: service.NewScheduleVersionService(schedule.Versions),
+		ScheduleEditor: editing.NewEditor(schedule.Versions, schedule.Shifts, schedule.Assignments, schedule.People),
+		ShiftInstances: schedule.Shifts,
+		Assignments:    schedule.Assignments,
+		Users:          &MockUserRepository{users: map[uuid.UUID]*entity.User{scheduler.ID: scheduler}},
+	}}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+		`{"shift_type": "ON1", "date": "2025-11-03", "study_type": "GENERAL", "specialty_constraint": "BOTH", "desired_coverage": 1}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(HeaderUserID, scheduler.ID.String())
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(version.ID.String())
+
+	require.NoError(t, h.AddShift(c))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	shifts, err := schedule.Shifts.GetByScheduleVersion(context.Background(), version.ID)
+	require.NoError(t, err)
+	require.Len(t, shifts, 1)
+	assert.Equal(t, scheduler.ID, shifts[0].CreatedBy)
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service/merge"
+)
+
+// MergeImportRequest merges an imported version into the STAGING version in
+// the path, using the version both started from as the base
+type MergeImportRequest struct {
+	BaseVersionID     string `json:"base_version_id"`
+	ImportedVersionID string `json:"imported_version_id"`
+}
+
+// ResolveConflictRequest picks which side of a merge conflict to keep
+type ResolveConflictRequest struct {
+	Resolution string `json:"resolution"` // STAGING or IMPORTED
+}
+
+// ShiftChangeResponse is one shift a merge took from the import
+type ShiftChangeResponse struct {
+	Date                string      `json:"date"`
+	ShiftType           string      `json:"shift_type"`
+	StudyType           string      `json:"study_type"`
+	SpecialtyConstraint string      `json:"specialty_constraint"`
+	ShiftAdded          bool        `json:"shift_added,omitempty"`
+	ShiftRemoved        bool        `json:"shift_removed,omitempty"`
+	Added               []uuid.UUID `json:"added"`
+	Removed             []uuid.UUID `json:"removed"`
+}
+
+func toShiftChangeResponse(c merge.ShiftChange) ShiftChangeResponse {
+	return ShiftChangeResponse{
+		Date:                c.ScheduleDate.Format("2006-01-02"),
+		ShiftType:           string(c.ShiftType),
+		StudyType:           string(c.StudyType),
+		SpecialtyConstraint: string(c.SpecialtyConstraint),
+		ShiftAdded:          c.ShiftAdded,
+		ShiftRemoved:        c.ShiftRemoved,
+		Added:               c.Added,
+		Removed:             c.Removed,
+	}
+}
+
+// MergeResponse is a merge, how many of its conflicts are open and, right
+// after merging, the shifts it took from the import
+type MergeResponse struct {
+	*entity.ScheduleMerge
+	OpenConflicts int                   `json:"open_conflicts"`
+	Applied       []ShiftChangeResponse `json:"applied,omitempty"`
+}
+
+// ResolveConflictResponse is a resolved conflict and what resolving it changed
+type ResolveConflictResponse struct {
+	Conflict *entity.MergeConflict `json:"conflict"`
+	Applied  *ShiftChangeResponse  `json:"applied,omitempty"`
+}
+
+// scheduleMergesUnavailable responds when merging is not configured
+func scheduleMergesUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("MERGES_UNAVAILABLE", "Schedule merging is not configured"))
+}
+
+// mergeFailed responds to a merge error
+func mergeFailed(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, merge.ErrNotStaging):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("NOT_STAGING", err.Error()))
+	case errors.Is(err, merge.ErrConflictResolved):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("ALREADY_RESOLVED", err.Error()))
+	case errors.Is(err, merge.ErrSameVersion), errors.Is(err, merge.ErrHospitalMismatch):
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_VERSIONS", err.Error()))
+	case errors.Is(err, merge.ErrInvalidResolution):
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_RESOLUTION", err.Error()))
+	case repository.IsNotFound(err):
+		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", err.Error()))
+	}
+	return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("MERGE_FAILED", fmt.Sprintf("Failed to merge schedules: %v", err)))
+}
+
+// MergeImport merges a fresh import into an edited STAGING version. Shifts
+// only the import changed are applied; shifts both sides changed are
+// returned as conflicts.
+func (h *Handlers) MergeImport(c echo.Context) error {
+	if h.services.ScheduleMerges == nil {
+		return scheduleMergesUnavailable(c)
+	}
+	staging, ok, err := h.loadVersion(c)
+	if !ok {
+		return err
+	}
+
+	var req MergeImportRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	baseID, baseErr := uuid.Parse(req.BaseVersionID)
+	importedID, importedErr := uuid.Parse(req.ImportedVersionID)
+	if baseErr != nil || importedErr != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_VERSION_ID", "base_version_id and imported_version_id must be UUIDs"))
+	}
+
+	creatorID := requestUserID(c)
+
+	result, err := h.services.ScheduleMerges.Merge(c.Request().Context(), staging.ID, baseID, importedID, creatorID)
+	if err != nil {
+		return mergeFailed(c, err)
+	}
+
+	resp := MergeResponse{
+		ScheduleMerge: result.Merge,
+		OpenConflicts: result.Merge.OpenConflicts(),
+		Applied:       make([]ShiftChangeResponse, 0, len(result.Applied)),
+	}
+	for _, change := range result.Applied {
+		resp.Applied = append(resp.Applied, toShiftChangeResponse(change))
+	}
+	return c.JSON(http.StatusCreated, SuccessResponse(resp))
+}
+
+// ListScheduleMerges lists the merges into a STAGING version, newest first
+func (h *Handlers) ListScheduleMerges(c echo.Context) error {
+	if h.services.ScheduleMerges == nil {
+		return scheduleMergesUnavailable(c)
+	}
+	staging, ok, err := h.loadVersion(c)
+	if !ok {
+		return err
+	}
+
+	merges, err := h.services.ScheduleMerges.ListMerges(c.Request().Context(), staging.ID)
+	if err != nil {
+		return mergeFailed(c, err)
+	}
+	resp := make([]MergeResponse, 0, len(merges))
+	for _, m := range merges {
+		resp = append(resp, MergeResponse{ScheduleMerge: m, OpenConflicts: m.OpenConflicts()})
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// GetScheduleMerge returns a merge and its conflicts
+func (h *Handlers) GetScheduleMerge(c echo.Context) error {
+	m, ok, err := h.loadMerge(c)
+	if !ok {
+		return err
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(MergeResponse{ScheduleMerge: m, OpenConflicts: m.OpenConflicts()}))
+}
+
+// ResolveMergeConflict keeps one side of a merge conflict
+func (h *Handlers) ResolveMergeConflict(c echo.Context) error {
+	m, ok, err := h.loadMerge(c)
+	if !ok {
+		return err
+	}
+	conflictID, err := uuid.Parse(c.Param("conflictId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "conflictId must be a UUID"))
+	}
+
+	var req ResolveConflictRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+
+	resolverID := requestUserID(c)
+
+	conflict, change, err := h.services.ScheduleMerges.ResolveConflict(c.Request().Context(), m.ID, conflictID,
+		entity.MergeResolution(req.Resolution), resolverID)
+	if err != nil {
+		return mergeFailed(c, err)
+	}
+
+	resp := ResolveConflictResponse{Conflict: conflict}
+	if change != nil {
+		applied := toShiftChangeResponse(*change)
+		resp.Applied = &applied
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// loadMerge loads the merge named by the :id path parameter and checks the
+// caller may see its hospital
+func (h *Handlers) loadMerge(c echo.Context) (*entity.ScheduleMerge, bool, error) {
+	if h.services.ScheduleMerges == nil {
+		return nil, false, scheduleMergesUnavailable(c)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "id must be a UUID"))
+	}
+
+	m, err := h.services.ScheduleMerges.GetMerge(c.Request().Context(), id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Schedule merge not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, m.HospitalID); !ok {
+		return nil, false, err
+	}
+	return m, true, nil
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentProductionVersion_PicksOverlappingVersion(t *testing.T) {
+	ctx := context.Background()
+	versions := mocks.NewMockScheduleVersionRepository()
+	hospitalID := uuid.New()
+	month := func(m time.Month) (time.Time, time.Time) {
+		start := time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+	create := func(status entity.VersionStatus, from, to time.Time) *entity.ScheduleVersion {
+		v := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: hospitalID, Status: status,
+			EffectiveStartDate: from, EffectiveEndDate: to}
+		require.NoError(t, versions.Create(ctx, v))
+		return v
+	}
+
+	octStart, octEnd := month(time.October)
+	novStart, novEnd := month(time.November)
+	create(entity.VersionStatusProduction, octStart, octEnd)
+	november := create(entity.VersionStatusProduction, novStart, novEnd)
+	staged := create(entity.VersionStatusStaging, novStart.AddDate(0, 0, 14), novEnd.AddDate(0, 0, 14))
+
+	h := &Handlers{services: &ServiceDeps{VersionService: service.NewScheduleVersionService(versions)}}
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+
+	assert.Equal(t, november.ID, h.currentProductionVersion(c, staged.ID))
+
+	decStart, decEnd := month(time.December)
+	staged.EffectiveStartDate, staged.EffectiveEndDate = decStart.AddDate(0, 0, 1), decEnd
+	assert.Equal(t, uuid.Nil, h.currentProductionVersion(c, staged.ID), "no PRODUCTION version covers December")
+}
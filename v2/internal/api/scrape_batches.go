@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service/rollback"
+)
+
+// scrapeBatchesUnavailable responds when no scrape batch repository is configured
+func scrapeBatchesUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("BATCHES_UNAVAILABLE", "Scrape batches are not configured"))
+}
+
+// GetScrapeBatchDelta returns how Amion's schedule changed between a batch
+// and the previous scrape, and any STAGING versions created for the changes
+func (h *Handlers) GetScrapeBatchDelta(c echo.Context) error {
+	batch, ok, err := h.loadScrapeBatch(c)
+	if !ok {
+		return err
+	}
+
+	if batch.Delta == nil {
+		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NO_DELTA", "Scrape batch was not compared with an earlier scrape"))
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(batch.Delta))
+}
+
+// RollbackScrapeBatch soft-deletes the shifts and assignments a batch
+// imported, keeping MANUAL and OVERRIDE assignments, and records who did it
+// in the audit log
+func (h *Handlers) RollbackScrapeBatch(c echo.Context) error {
+	if h.services.BatchRollback == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("ROLLBACK_UNAVAILABLE", "Batch rollback is not configured"))
+	}
+
+	batch, ok, err := h.loadScrapeBatch(c)
+	if !ok {
+		return err
+	}
+
+	// The audit log needs to know who rolled the batch back
+	userID, err := uuid.Parse(c.Request().Header.Get(HeaderUserID))
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, ErrorResponseWithCode("UNAUTHORIZED", "Missing or invalid "+HeaderUserID+" header"))
+	}
+
+	result, err := h.services.BatchRollback.Rollback(c.Request().Context(), batch.ID, userID, c.RealIP())
+	switch {
+	case errors.Is(err, rollback.ErrAlreadyRolledBack):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("ALREADY_ROLLED_BACK", err.Error()))
+	case errors.Is(err, rollback.ErrBatchPending):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("BATCH_PENDING", err.Error()))
+	case repository.IsNotFound(err):
+		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Scrape batch not found"))
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("ROLLBACK_FAILED", fmt.Sprintf("Failed to roll back scrape batch: %v", err)))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// loadScrapeBatch loads the batch named by the :id path parameter and checks
+// the caller may see its hospital
+func (h *Handlers) loadScrapeBatch(c echo.Context) (*entity.ScrapeBatch, bool, error) {
+	if h.services.ScrapeBatches == nil {
+		return nil, false, scrapeBatchesUnavailable(c)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "id must be a UUID"))
+	}
+
+	batch, err := h.services.ScrapeBatches.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Scrape batch not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, batch.HospitalID); !ok {
+		return nil, false, err
+	}
+
+	return batch, true, nil
+}
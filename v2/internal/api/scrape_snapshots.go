@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// PageSnapshotResponse is the API representation of an archived Amion page
+type PageSnapshotResponse struct {
+	ID            string    `json:"id"`
+	ScrapeBatchID string    `json:"scrape_batch_id"`
+	HospitalID    string    `json:"hospital_id"`
+	URL           string    `json:"url"`
+	Month         string    `json:"month"`
+	WindowFrom    string    `json:"window_from,omitempty"`
+	WindowTo      string    `json:"window_to,omitempty"`
+	ContentHash   string    `json:"content_hash"`
+	Size          int       `json:"size"`
+	FetchedAt     time.Time `json:"fetched_at"`
+}
+
+func toPageSnapshotResponse(s *entity.AmionPageSnapshot) PageSnapshotResponse {
+	return PageSnapshotResponse{
+		ID:            s.ID.String(),
+		ScrapeBatchID: s.ScrapeBatchID.String(),
+		HospitalID:    s.HospitalID.String(),
+		URL:           s.URL,
+		Month:         s.Month,
+		WindowFrom:    s.WindowFrom,
+		WindowTo:      s.WindowTo,
+		ContentHash:   s.ContentHash,
+		Size:          s.Size,
+		FetchedAt:     s.FetchedAt,
+	}
+}
+
+// archivedBatchPages loads a batch's archived pages and checks the caller may
+// see the batch's hospital. On failure the error response has already been
+// written and ok is false.
+func (h *Handlers) archivedBatchPages(c echo.Context) (batchID uuid.UUID, pages []*entity.AmionPageSnapshot, ok bool, err error) {
+	if h.services.AmionArchive == nil {
+		return uuid.Nil, nil, false, c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("ARCHIVE_UNAVAILABLE", "Amion page archive is not configured"))
+	}
+
+	batchID, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "id must be a UUID"))
+	}
+
+	pages, err = h.services.AmionArchive.Pages(c.Request().Context(), batchID)
+	if err != nil {
+		return uuid.Nil, nil, false, c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list archived pages: %v", err)))
+	}
+	if len(pages) == 0 {
+		return uuid.Nil, nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "No archived pages for this scrape batch"))
+	}
+
+	if ok, err := h.authorizeHospital(c, pages[0].HospitalID); !ok {
+		return uuid.Nil, nil, false, err
+	}
+	return batchID, pages, true, nil
+}
+
+// ListScrapeSnapshots lists the Amion pages archived for a scrape batch
+func (h *Handlers) ListScrapeSnapshots(c echo.Context) error {
+	_, pages, ok, err := h.archivedBatchPages(c)
+	if !ok {
+		return err
+	}
+
+	resp := make([]PageSnapshotResponse, 0, len(pages))
+	for _, p := range pages {
+		resp = append(resp, toPageSnapshotResponse(p))
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// GetScrapeSnapshotHTML returns an archived page's raw HTML, for debugging
+// parser problems against exactly what Amion served
+func (h *Handlers) GetScrapeSnapshotHTML(c echo.Context) error {
+	_, pages, ok, err := h.archivedBatchPages(c)
+	if !ok {
+		return err
+	}
+
+	snapshotID, err := uuid.Parse(c.Param("snapshotID"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "snapshotID must be a UUID"))
+	}
+	found := false
+	for _, p := range pages {
+		found = found || p.ID == snapshotID
+	}
+	if !found {
+		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Snapshot not found in this scrape batch"))
+	}
+
+	_, html, err := h.services.AmionArchive.Load(c.Request().Context(), snapshotID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LOAD_FAILED", fmt.Sprintf("Failed to load snapshot: %v", err)))
+	}
+	return c.HTMLBlob(http.StatusOK, html)
+}
+
+// ReimportScrapeBatch re-runs extraction over a batch's archived pages into a
+// new STAGING schedule version, without contacting Amion
+func (h *Handlers) ReimportScrapeBatch(c echo.Context) error {
+	if h.services.AmionReimporter == nil {
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("REIMPORT_UNAVAILABLE", "Amion re-import is not configured"))
+	}
+
+	batchID, _, ok, err := h.archivedBatchPages(c)
+	if !ok {
+		return err
+	}
+
+	// TODO: Get creator ID from authenticated user
+	creatorID := entity.UserID(uuid.New())
+
+	result, err := h.services.AmionReimporter.Reimport(c.Request().Context(), batchID, creatorID)
+	if err != nil {
+		var notFound *repository.NotFoundError
+		if errors.As(err, &notFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("REIMPORT_FAILED", fmt.Sprintf("Failed to re-import scrape batch: %v", err)))
+	}
+
+	return c.JSON(http.StatusCreated, SuccessResponse(result))
+}
@@ -0,0 +1,486 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service/templates"
+)
+
+// ShiftTemplateRequest creates or replaces a recurring shift template
+type ShiftTemplateRequest struct {
+	Name           string                        `json:"name"`
+	ShiftType      string                        `json:"shift_type"`
+	Weekdays       []int                         `json:"weekdays"` // 0 = Sunday .. 6 = Saturday
+	Variants       []entity.ShiftTemplateVariant `json:"variants"` // At most one per day_type: WEEKDAY, WEEKEND, HOLIDAY
+	SkipHolidays   bool                          `json:"skip_holidays"`
+	EffectiveFrom  string                        `json:"effective_from"`  // YYYY-MM-DD
+	EffectiveUntil string                        `json:"effective_until"` // YYYY-MM-DD; empty runs indefinitely
+
+	effectiveFrom  time.Time
+	effectiveUntil *time.Time
+}
+
+// ShiftTemplateResponse is the API representation of a shift template
+type ShiftTemplateResponse struct {
+	ID             string                        `json:"id"`
+	HospitalID     string                        `json:"hospital_id"`
+	Name           string                        `json:"name"`
+	ShiftType      string                        `json:"shift_type"`
+	Weekdays       []int                         `json:"weekdays"`
+	Variants       []entity.ShiftTemplateVariant `json:"variants"`
+	SkipHolidays   bool                          `json:"skip_holidays"`
+	EffectiveFrom  string                        `json:"effective_from"`
+	EffectiveUntil string                        `json:"effective_until,omitempty"`
+	CreatedAt      time.Time                     `json:"created_at"`
+	UpdatedAt      time.Time                     `json:"updated_at"`
+}
+
+func toShiftTemplateResponse(t *entity.ShiftTemplate) ShiftTemplateResponse {
+	resp := ShiftTemplateResponse{
+		ID:            t.ID.String(),
+		HospitalID:    t.HospitalID.String(),
+		Name:          t.Name,
+		ShiftType:     string(t.ShiftType),
+		Weekdays:      make([]int, 0, len(t.Weekdays)),
+		Variants:      t.Variants,
+		SkipHolidays:  t.SkipHolidays,
+		EffectiveFrom: t.EffectiveFrom.Format("2006-01-02"),
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	}
+	for _, wd := range t.Weekdays {
+		resp.Weekdays = append(resp.Weekdays, int(wd))
+	}
+	if t.EffectiveUntil != nil {
+		resp.EffectiveUntil = t.EffectiveUntil.Format("2006-01-02")
+	}
+	return resp
+}
+
+// GenerateShiftsRequest generates a STAGING version's shifts from its
+// hospital's templates. Dates default to the version's effective dates.
+type GenerateShiftsRequest struct {
+	StartDate string   `json:"start_date"` // YYYY-MM-DD
+	EndDate   string   `json:"end_date"`   // YYYY-MM-DD
+	Holidays  []string `json:"holidays"`   // Extra YYYY-MM-DD dates to staff as holidays
+}
+
+// GeneratedShiftResponse is a shift generated from a template. ID is empty in previews.
+type GeneratedShiftResponse struct {
+	ID                  string `json:"id,omitempty"`
+	TemplateID          string `json:"template_id"`
+	Date                string `json:"date"`
+	DayType             string `json:"day_type"`
+	Holiday             string `json:"holiday,omitempty"`
+	ShiftType           string `json:"shift_type"`
+	StartTime           string `json:"start_time"`
+	EndTime             string `json:"end_time"`
+	StudyType           string `json:"study_type"`
+	SpecialtyConstraint string `json:"specialty_constraint"`
+	DesiredCoverage     int    `json:"desired_coverage"`
+	IsMandatory         bool   `json:"is_mandatory"`
+}
+
+// GenerateShiftsResponse lists the shifts generated and how many slots were
+// skipped because they already had a shift
+type GenerateShiftsResponse struct {
+	Shifts  []GeneratedShiftResponse `json:"shifts"`
+	Skipped int                      `json:"skipped"`
+}
+
+func toGenerateShiftsResponse(result *templates.Result, saved bool) GenerateShiftsResponse {
+	resp := GenerateShiftsResponse{
+		Shifts:  make([]GeneratedShiftResponse, 0, len(result.Shifts)),
+		Skipped: result.Skipped,
+	}
+	for _, g := range result.Shifts {
+		shift := GeneratedShiftResponse{
+			TemplateID:          g.TemplateID.String(),
+			Date:                g.Shift.ScheduleDate.Format("2006-01-02"),
+			DayType:             string(g.DayType),
+			Holiday:             g.Holiday,
+			ShiftType:           string(g.Shift.ShiftType),
+			StartTime:           g.Shift.StartTime,
+			EndTime:             g.Shift.EndTime,
+			StudyType:           string(g.Shift.StudyType),
+			SpecialtyConstraint: string(g.Shift.SpecialtyConstraint),
+			DesiredCoverage:     g.Shift.DesiredCoverage,
+			IsMandatory:         g.Shift.IsMandatory,
+		}
+		if saved {
+			shift.ID = g.Shift.ID.String()
+		}
+		resp.Shifts = append(resp.Shifts, shift)
+	}
+	return resp
+}
+
+// validate checks the request, normalizes its name, weekdays and times, and
+// parses its effective dates
+func (req *ShiftTemplateRequest) validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !entity.ValidateShiftType(req.ShiftType) {
+		return fmt.Errorf("%w: %q", entity.ErrUnknownShiftType, req.ShiftType)
+	}
+
+	if len(req.Weekdays) == 0 {
+		return fmt.Errorf("weekdays must list at least one day")
+	}
+	seenDays := make(map[int]bool)
+	weekdays := req.Weekdays[:0:0]
+	for _, wd := range req.Weekdays {
+		if wd < 0 || wd > 6 {
+			return fmt.Errorf("weekdays must be 0 (Sunday) to 6 (Saturday), got %d", wd)
+		}
+		if !seenDays[wd] {
+			seenDays[wd] = true
+			weekdays = append(weekdays, wd)
+		}
+	}
+	sort.Ints(weekdays)
+	req.Weekdays = weekdays
+
+	if len(req.Variants) == 0 {
+		return fmt.Errorf("at least one variant is required")
+	}
+	seenTypes := make(map[entity.DayType]bool)
+	for i := range req.Variants {
+		v := &req.Variants[i]
+		if !entity.ValidateDayType(string(v.DayType)) {
+			return fmt.Errorf("unknown day type: %q", v.DayType)
+		}
+		if seenTypes[v.DayType] {
+			return fmt.Errorf("only one %s variant is allowed", v.DayType)
+		}
+		seenTypes[v.DayType] = true
+
+		v.StartTime, v.EndTime = entity.NormalizeClock(v.StartTime), entity.NormalizeClock(v.EndTime)
+		if v.StartTime == "" || v.EndTime == "" {
+			return fmt.Errorf("%s variant: start_time and end_time must be HH:MM", v.DayType)
+		}
+		if !entity.ValidateStudyType(string(v.StudyType)) {
+			return fmt.Errorf("%s variant: unknown study type: %q", v.DayType, v.StudyType)
+		}
+		if !entity.ValidateSpecialty(string(v.SpecialtyConstraint)) {
+			return fmt.Errorf("%s variant: %w: %q", v.DayType, entity.ErrUnknownSpecialty, v.SpecialtyConstraint)
+		}
+		if v.DesiredCoverage < 1 {
+			return fmt.Errorf("%s variant: desired_coverage must be at least 1", v.DayType)
+		}
+	}
+
+	from, err := time.Parse("2006-01-02", req.EffectiveFrom)
+	if err != nil {
+		return fmt.Errorf("effective_from must be YYYY-MM-DD")
+	}
+	req.effectiveFrom, req.effectiveUntil = from, nil
+	if strings.TrimSpace(req.EffectiveUntil) != "" {
+		until, err := time.Parse("2006-01-02", req.EffectiveUntil)
+		if err != nil {
+			return fmt.Errorf("effective_until must be YYYY-MM-DD")
+		}
+		if until.Before(from) {
+			return fmt.Errorf("effective_until must not be before effective_from")
+		}
+		req.effectiveUntil = &until
+	}
+	return nil
+}
+
+// apply copies a validated request onto a template
+func (req *ShiftTemplateRequest) apply(t *entity.ShiftTemplate) {
+	t.Name = req.Name
+	t.ShiftType = entity.ShiftType(req.ShiftType)
+	t.Weekdays = make([]time.Weekday, 0, len(req.Weekdays))
+	for _, wd := range req.Weekdays {
+		t.Weekdays = append(t.Weekdays, time.Weekday(wd))
+	}
+	t.Variants = req.Variants
+	t.SkipHolidays = req.SkipHolidays
+	t.EffectiveFrom = req.effectiveFrom
+	t.EffectiveUntil = req.effectiveUntil
+}
+
+// parseHolidays reads extra YYYY-MM-DD holidays into a calendar
+func parseHolidays(dates []string) (entity.HolidayCalendar, error) {
+	holidays := entity.HolidayCalendar{}
+	for _, d := range dates {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(d))
+		if err != nil {
+			return nil, fmt.Errorf("holiday %q must be YYYY-MM-DD", d)
+		}
+		holidays.Add(date, "Hospital holiday")
+	}
+	return holidays, nil
+}
+
+// shiftTemplatesUnavailable responds when no shift template repository is configured
+func shiftTemplatesUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("SHIFT_TEMPLATES_UNAVAILABLE", "Shift templates are not configured"))
+}
+
+// shiftGeneratorUnavailable responds when shift generation is not configured
+func shiftGeneratorUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("SHIFT_GENERATOR_UNAVAILABLE", "Shift generation is not configured"))
+}
+
+// generateFailed responds to a shift generation error
+func generateFailed(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, entity.ErrInvalidPeriod), errors.Is(err, templates.ErrOutsideVersion):
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_RANGE", err.Error()))
+	case errors.Is(err, templates.ErrNotStaging):
+		return c.JSON(http.StatusConflict, ErrorResponseWithCode("NOT_STAGING", err.Error()))
+	case repository.IsNotFound(err):
+		return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Schedule version not found"))
+	}
+	return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("GENERATE_FAILED", fmt.Sprintf("Failed to generate shifts: %v", err)))
+}
+
+// checkDuplicateTemplate responds with 409 if another live template of the
+// hospital has the same name
+func (h *Handlers) checkDuplicateTemplate(c echo.Context, template *entity.ShiftTemplate) (bool, error) {
+	existing, err := h.services.ShiftTemplates.GetByHospital(c.Request().Context(), template.HospitalID)
+	if err != nil {
+		return false, c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list shift templates: %v", err)))
+	}
+	for _, other := range existing {
+		if other.ID != template.ID && strings.EqualFold(other.Name, template.Name) {
+			return false, c.JSON(http.StatusConflict, ErrorResponseWithCode("DUPLICATE_TEMPLATE",
+				fmt.Sprintf("A shift template named %q already exists (%s)", template.Name, other.ID)))
+		}
+	}
+	return true, nil
+}
+
+// ListShiftTemplates lists a hospital's shift templates
+func (h *Handlers) ListShiftTemplates(c echo.Context) error {
+	if h.services.ShiftTemplates == nil {
+		return shiftTemplatesUnavailable(c)
+	}
+
+	hospitalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital id must be a UUID"))
+	}
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	list, err := h.services.ShiftTemplates.GetByHospital(c.Request().Context(), hospitalID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list shift templates: %v", err)))
+	}
+
+	resp := make([]ShiftTemplateResponse, 0, len(list))
+	for _, t := range list {
+		resp = append(resp, toShiftTemplateResponse(t))
+	}
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// CreateShiftTemplate adds a shift template to a hospital
+func (h *Handlers) CreateShiftTemplate(c echo.Context) error {
+	if h.services.ShiftTemplates == nil {
+		return shiftTemplatesUnavailable(c)
+	}
+
+	hospitalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital id must be a UUID"))
+	}
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	var req ShiftTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	if err := req.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_TEMPLATE", err.Error()))
+	}
+
+	creatorID := requestUserID(c)
+
+	now := entity.Now()
+	template := &entity.ShiftTemplate{
+		ID:         uuid.New(),
+		HospitalID: hospitalID,
+		CreatedAt:  now,
+		CreatedBy:  creatorID,
+		UpdatedAt:  now,
+	}
+	req.apply(template)
+
+	if ok, err := h.checkDuplicateTemplate(c, template); !ok {
+		return err
+	}
+	if err := h.services.ShiftTemplates.Create(c.Request().Context(), template); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("TEMPLATE_CREATE_FAILED", fmt.Sprintf("Failed to create shift template: %v", err)))
+	}
+
+	return c.JSON(http.StatusCreated, SuccessResponse(toShiftTemplateResponse(template)))
+}
+
+// UpdateShiftTemplate replaces a shift template; shifts already generated
+// from it are kept
+func (h *Handlers) UpdateShiftTemplate(c echo.Context) error {
+	template, ok, err := h.loadShiftTemplate(c)
+	if !ok {
+		return err
+	}
+
+	var req ShiftTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	if err := req.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_TEMPLATE", err.Error()))
+	}
+
+	req.apply(template)
+	template.UpdatedAt = entity.Now()
+
+	if ok, err := h.checkDuplicateTemplate(c, template); !ok {
+		return err
+	}
+	if err := h.services.ShiftTemplates.Update(c.Request().Context(), template); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("TEMPLATE_UPDATE_FAILED", fmt.Sprintf("Failed to update shift template: %v", err)))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(toShiftTemplateResponse(template)))
+}
+
+// DeleteShiftTemplate removes a shift template; shifts already generated
+// from it are kept
+func (h *Handlers) DeleteShiftTemplate(c echo.Context) error {
+	template, ok, err := h.loadShiftTemplate(c)
+	if !ok {
+		return err
+	}
+
+	deleterID := requestUserID(c)
+
+	if err := h.services.ShiftTemplates.Delete(c.Request().Context(), template.ID, deleterID); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("TEMPLATE_DELETE_FAILED", fmt.Sprintf("Failed to delete shift template: %v", err)))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PreviewShiftTemplates returns the shifts a hospital's templates would
+// generate between ?start_date and ?end_date, without saving them.
+// ?holidays takes extra comma-separated YYYY-MM-DD holidays.
+func (h *Handlers) PreviewShiftTemplates(c echo.Context) error {
+	if h.services.ShiftGenerator == nil {
+		return shiftGeneratorUnavailable(c)
+	}
+
+	hospitalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital id must be a UUID"))
+	}
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	startDate, startErr := time.Parse("2006-01-02", c.QueryParam("start_date"))
+	endDate, endErr := time.Parse("2006-01-02", c.QueryParam("end_date"))
+	if startErr != nil || endErr != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", "start_date and end_date must be YYYY-MM-DD"))
+	}
+	var extra []string
+	if param := c.QueryParam("holidays"); param != "" {
+		extra = strings.Split(param, ",")
+	}
+	holidays, err := parseHolidays(extra)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", err.Error()))
+	}
+
+	result, err := h.services.ShiftGenerator.Preview(c.Request().Context(), hospitalID, templates.Options{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Holidays:  holidays,
+	})
+	if err != nil {
+		return generateFailed(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseWithValidation(toGenerateShiftsResponse(result, false), result.Validation))
+}
+
+// GenerateShifts creates a STAGING version's shifts from its hospital's
+// templates, skipping slots the version already has a shift for
+func (h *Handlers) GenerateShifts(c echo.Context) error {
+	if h.services.ShiftGenerator == nil {
+		return shiftGeneratorUnavailable(c)
+	}
+	version, ok, err := h.loadVersion(c)
+	if !ok {
+		return err
+	}
+
+	var req GenerateShiftsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+	var opts templates.Options
+	if req.StartDate != "" {
+		if opts.StartDate, err = time.Parse("2006-01-02", req.StartDate); err != nil {
+			return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", "start_date must be YYYY-MM-DD"))
+		}
+	}
+	if req.EndDate != "" {
+		if opts.EndDate, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+			return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", "end_date must be YYYY-MM-DD"))
+		}
+	}
+	if opts.Holidays, err = parseHolidays(req.Holidays); err != nil {
+		return c.JSON(http.StatusBadRequest, ValidationErrorResponse("INVALID_DATE", err.Error()))
+	}
+
+	opts.CreatorID = requestUserID(c)
+
+	result, err := h.services.ShiftGenerator.Generate(c.Request().Context(), version.ID, opts)
+	if err != nil {
+		return generateFailed(c, err)
+	}
+	return c.JSON(http.StatusCreated, ResponseWithValidation(toGenerateShiftsResponse(result, true), result.Validation))
+}
+
+// loadShiftTemplate loads the template named by the :id path parameter and
+// checks the caller may manage its hospital
+func (h *Handlers) loadShiftTemplate(c echo.Context) (*entity.ShiftTemplate, bool, error) {
+	if h.services.ShiftTemplates == nil {
+		return nil, false, shiftTemplatesUnavailable(c)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "template id must be a UUID"))
+	}
+
+	template, err := h.services.ShiftTemplates.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Shift template not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, template.HospitalID); !ok {
+		return nil, false, err
+	}
+
+	return template, true, nil
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// CreateWebhookRequest represents a request to register a webhook
+type CreateWebhookRequest struct {
+	HospitalID string   `json:"hospital_id" validate:"required"`
+	URL        string   `json:"url" validate:"required"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+// WebhookResponse is the API representation of a webhook subscription.
+// Secret is only populated in the response to the create request.
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	HospitalID string    `json:"hospital_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryResponse is the API representation of a delivery log entry
+type WebhookDeliveryResponse struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscription_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	ResponseBody   *string    `json:"response_body,omitempty"`
+	ErrorMessage   *string    `json:"error_message,omitempty"`
+	RedeliveryOf   *uuid.UUID `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+func toWebhookResponse(sub *entity.WebhookSubscription) WebhookResponse {
+	eventTypes := sub.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return WebhookResponse{
+		ID:         sub.ID.String(),
+		HospitalID: sub.HospitalID.String(),
+		URL:        sub.URL,
+		EventTypes: eventTypes,
+		Active:     sub.Active,
+		CreatedAt:  sub.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(d *entity.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             d.ID.String(),
+		SubscriptionID: d.SubscriptionID.String(),
+		EventType:      d.EventType,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		ErrorMessage:   d.ErrorMessage,
+		RedeliveryOf:   d.RedeliveryOf,
+		CreatedAt:      d.CreatedAt,
+		LastAttemptAt:  d.LastAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+// webhooksUnavailable responds when no webhook service is configured
+func webhooksUnavailable(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, ErrorResponseWithCode("WEBHOOKS_UNAVAILABLE", "Webhooks are not configured"))
+}
+
+// CreateWebhook registers a webhook subscription for a hospital
+func (h *Handlers) CreateWebhook(c echo.Context) error {
+	if h.services.Webhooks == nil {
+		return webhooksUnavailable(c)
+	}
+
+	var req CreateWebhookRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_REQUEST", fmt.Sprintf("Invalid request: %v", err)))
+	}
+
+	hospitalID, err := uuid.Parse(req.HospitalID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital_id must be a UUID"))
+	}
+
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	// TODO: Get creator ID from authenticated user
+	creatorID := entity.UserID(uuid.New())
+
+	sub, err := h.services.Webhooks.CreateSubscription(c.Request().Context(), hospitalID, req.URL, req.EventTypes, req.Secret, creatorID)
+	if err != nil {
+		if _, ok := err.(*repository.ValidationError); ok {
+			return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_WEBHOOK", err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("WEBHOOK_CREATE_FAILED", fmt.Sprintf("Failed to create webhook: %v", err)))
+	}
+
+	resp := toWebhookResponse(sub)
+	resp.Secret = sub.Secret
+
+	return c.JSON(http.StatusCreated, SuccessResponse(resp))
+}
+
+// ListWebhooks lists a hospital's webhook subscriptions
+func (h *Handlers) ListWebhooks(c echo.Context) error {
+	if h.services.Webhooks == nil {
+		return webhooksUnavailable(c)
+	}
+
+	hospitalParam := c.QueryParam("hospital_id")
+	if hospitalParam == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("MISSING_PARAM", "hospital_id query parameter required"))
+	}
+	hospitalID, err := uuid.Parse(hospitalParam)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "hospital_id must be a UUID"))
+	}
+
+	if ok, err := h.authorizeHospital(c, hospitalID); !ok {
+		return err
+	}
+
+	subs, err := h.services.Webhooks.ListSubscriptions(c.Request().Context(), hospitalID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list webhooks: %v", err)))
+	}
+
+	resp := make([]WebhookResponse, 0, len(subs))
+	for _, sub := range subs {
+		resp = append(resp, toWebhookResponse(sub))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// DeleteWebhook removes a webhook subscription
+func (h *Handlers) DeleteWebhook(c echo.Context) error {
+	sub, ok, err := h.loadWebhook(c)
+	if !ok {
+		return err
+	}
+
+	// TODO: Get deleter ID from authenticated user
+	deleterID := entity.UserID(uuid.New())
+
+	if err := h.services.Webhooks.DeleteSubscription(c.Request().Context(), sub.ID, deleterID); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("WEBHOOK_DELETE_FAILED", fmt.Sprintf("Failed to delete webhook: %v", err)))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries returns the delivery log for a webhook subscription
+func (h *Handlers) ListWebhookDeliveries(c echo.Context) error {
+	sub, ok, err := h.loadWebhook(c)
+	if !ok {
+		return err
+	}
+
+	limit := 100
+	if l := c.QueryParam("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "limit must be an integer"))
+		}
+	}
+
+	deliveries, err := h.services.Webhooks.ListDeliveries(c.Request().Context(), sub.ID, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("LIST_FAILED", fmt.Sprintf("Failed to list deliveries: %v", err)))
+	}
+
+	resp := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, toWebhookDeliveryResponse(d))
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse(resp))
+}
+
+// RedeliverWebhook queues a new attempt of an earlier delivery with the same payload
+func (h *Handlers) RedeliverWebhook(c echo.Context) error {
+	sub, ok, err := h.loadWebhook(c)
+	if !ok {
+		return err
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("deliveryID"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "delivery id must be a UUID"))
+	}
+
+	delivery, err := h.services.Webhooks.Redeliver(c.Request().Context(), sub.ID, deliveryID)
+	if err != nil {
+		if repository.IsNotFound(err) {
+			return c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Webhook delivery not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponseWithCode("REDELIVERY_FAILED", fmt.Sprintf("Failed to redeliver webhook: %v", err)))
+	}
+
+	return c.JSON(http.StatusAccepted, SuccessResponse(toWebhookDeliveryResponse(delivery)))
+}
+
+// loadWebhook resolves the :id path parameter and checks hospital access.
+// On failure the error response has already been written and ok is false.
+func (h *Handlers) loadWebhook(c echo.Context) (*entity.WebhookSubscription, bool, error) {
+	if h.services.Webhooks == nil {
+		return nil, false, webhooksUnavailable(c)
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, ErrorResponseWithCode("INVALID_PARAM", "webhook id must be a UUID"))
+	}
+
+	sub, err := h.services.Webhooks.GetSubscription(c.Request().Context(), id)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusNotFound, ErrorResponseWithCode("NOT_FOUND", "Webhook not found"))
+	}
+
+	if ok, err := h.authorizeHospital(c, sub.HospitalID); !ok {
+		return nil, false, err
+	}
+
+	return sub, true, nil
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AmionDivision is one separately published Amion schedule of a hospital
+// (e.g. Body, Neuro, IR, Nights), each with its own site and login
+type AmionDivision struct {
+	ID               uuid.UUID
+	HospitalID       uuid.UUID
+	Name             string
+	BaseURL          string            // Amion site root for the division
+	URLTemplate      string            // Schedule page path with {yyyy} {yy} {mm} {m} {dd} {d} {date} placeholders; empty = "/schedule/{yyyy}-{mm}"
+	PageView         string            // month | week | day; empty = month
+	CredentialsRef   string            // Secret holding the division's login; empty for a public schedule
+	SelectorProfile  string            // Named selector profile for the division's markup; empty = default
+	ShiftNameMapping map[string]string // Amion shift label -> our shift type, e.g. "Body Late" -> "MidL"
+	Active           bool
+	CreatedAt        time.Time
+	CreatedBy        uuid.UUID
+	UpdatedAt        time.Time
+	DeletedAt        *time.Time
+	DeletedBy        *uuid.UUID
+}
+
+// MapShiftName returns our shift type for an Amion shift label, matching the
+// mapping case-insensitively. Unmapped labels are returned unchanged.
+func (d *AmionDivision) MapShiftName(label string) string {
+	if mapped, ok := d.ShiftNameMapping[label]; ok {
+		return mapped
+	}
+	for from, to := range d.ShiftNameMapping {
+		if strings.EqualFold(strings.TrimSpace(from), strings.TrimSpace(label)) {
+			return to
+		}
+	}
+	return label
+}
+
+// DivisionScrapeStats records how one division fared in a multi-division scrape
+type DivisionScrapeStats struct {
+	DivisionID uuid.UUID `json:"division_id"`
+	Division   string    `json:"division"`
+	Pages      int       `json:"pages"`
+	Rows       int       `json:"rows"`
+	Errors     []string  `json:"errors,omitempty"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AmionShiftMapping maps an Amion shift label onto one of our shift slots.
+// Location, StartTime and EndTime narrow the match when Amion uses one label
+// for different slots, e.g. "Body Late" at two sites.
+type AmionShiftMapping struct {
+	ID                  uuid.UUID
+	HospitalID          uuid.UUID
+	AmionLabel          string // Matched case-insensitively
+	Location            string // Empty matches any location
+	StartTime           string // HH:MM; empty matches any start
+	EndTime             string // HH:MM; empty matches any end
+	ShiftType           ShiftType
+	StudyType           StudyType
+	SpecialtyConstraint SpecialtyType
+	CreatedAt           time.Time
+	CreatedBy           uuid.UUID
+	UpdatedAt           time.Time
+	DeletedAt           *time.Time
+	DeletedBy           *uuid.UUID
+}
+
+// Matches reports whether an Amion shift is covered by the mapping
+func (m *AmionShiftMapping) Matches(label, location, startTime, endTime string) bool {
+	return sameText(m.AmionLabel, label) &&
+		(m.Location == "" || sameText(m.Location, location)) &&
+		(m.StartTime == "" || sameClock(m.StartTime, startTime)) &&
+		(m.EndTime == "" || sameClock(m.EndTime, endTime))
+}
+
+// specificity counts the optional conditions the mapping sets
+func (m *AmionShiftMapping) specificity() int {
+	n := 0
+	for _, condition := range []string{m.Location, m.StartTime, m.EndTime} {
+		if condition != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// MatchAmionShiftMapping returns the most specific mapping covering an Amion
+// shift, or nil if none does. Among equally specific mappings the first wins.
+func MatchAmionShiftMapping(mappings []*AmionShiftMapping, label, location, startTime, endTime string) *AmionShiftMapping {
+	var best *AmionShiftMapping
+	for _, m := range mappings {
+		if m.Matches(label, location, startTime, endTime) && (best == nil || m.specificity() > best.specificity()) {
+			best = m
+		}
+	}
+	return best
+}
+
+func sameText(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// sameClock compares times of day, so "7:00" matches "07:00"
+func sameClock(a, b string) bool {
+	normalized := NormalizeClock(a)
+	return normalized != "" && normalized == NormalizeClock(b)
+}
+
+// NormalizeClock returns an H:MM or HH:MM time as HH:MM, or "" if it is not one
+func NormalizeClock(value string) string {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return ""
+	}
+	return t.Format("15:04")
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchAmionShiftMapping(t *testing.T) {
+	anyTime := &AmionShiftMapping{AmionLabel: "Overnight", ShiftType: ShiftTypeON1}
+	early := &AmionShiftMapping{AmionLabel: "Overnight", StartTime: "19:00", ShiftType: ShiftTypeON2}
+	mappings := []*AmionShiftMapping{anyTime, early}
+
+	assert.Equal(t, early, MatchAmionShiftMapping(mappings, " overnight ", "Main", "19:00", "07:00"), "more specific mapping wins")
+	assert.Equal(t, anyTime, MatchAmionShiftMapping(mappings, "Overnight", "Main", "21:00", "07:00"))
+	assert.Nil(t, MatchAmionShiftMapping(mappings, "Technologist", "Main", "19:00", "07:00"))
+
+	morning := &AmionShiftMapping{AmionLabel: "Day", StartTime: "07:00", Location: "North"}
+	assert.True(t, morning.Matches("Day", "north", "7:00", ""), "times compare as times of day")
+	assert.False(t, morning.Matches("Day", "South", "07:00", ""))
+	assert.False(t, morning.Matches("Day", "North", "", ""), "a missing time does not match a condition")
+}
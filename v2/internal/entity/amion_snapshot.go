@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SnapshotEncodingGzip is the only content encoding used for archived pages
+const SnapshotEncodingGzip = "gzip"
+
+// AmionPageSnapshot is a raw Amion page archived during a scrape, so the batch
+// can be re-parsed later (e.g. with fixed selectors) without hitting Amion
+type AmionPageSnapshot struct {
+	ID            uuid.UUID
+	ScrapeBatchID uuid.UUID
+	HospitalID    uuid.UUID
+	DivisionID    *uuid.UUID // Amion division the page came from, if the hospital has divisions
+	URL           string
+	Month         string // Page label: YYYY-MM, or the first date (YYYY-MM-DD) of a week or day page
+	WindowFrom    string // First date (YYYY-MM-DD) the page is authoritative for; empty for older snapshots
+	WindowTo      string // Last date (YYYY-MM-DD) the page is authoritative for
+	ContentHash   string // SHA-256 (hex) of the uncompressed HTML
+	Encoding      string // Compression of Content, "gzip"
+	Size          int    // Uncompressed size in bytes
+	Content       []byte // Compressed HTML; not loaded when listing a batch's pages
+	FetchedAt     time.Time
+	CreatedAt     time.Time
+}
+
+// Contains reports whether a YYYY-MM-DD date falls inside the page's window.
+// Snapshots archived without a window contain every date.
+func (s *AmionPageSnapshot) Contains(date string) bool {
+	if s.WindowFrom == "" || s.WindowTo == "" {
+		return true
+	}
+	return len(date) == 10 && date >= s.WindowFrom && date <= s.WindowTo
+}
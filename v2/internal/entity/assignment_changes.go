@@ -0,0 +1,30 @@
+package entity
+
+import "github.com/google/uuid"
+
+// AssignmentChanges is a set of assignment writes that must be applied
+// together or not at all
+type AssignmentChanges struct {
+	Create []*Assignment
+	Update []*Assignment
+	Delete []uuid.UUID
+}
+
+// IsEmpty reports whether there is nothing to write
+func (c *AssignmentChanges) IsEmpty() bool {
+	return len(c.Create) == 0 && len(c.Update) == 0 && len(c.Delete) == 0
+}
+
+// ScheduleChanges is a set of shift and assignment writes to one version that
+// must be applied together or not at all. New shifts are written first and
+// dropped shifts last, so assignments can move onto or off them.
+type ScheduleChanges struct {
+	CreateShifts []*ShiftInstance
+	Assignments  AssignmentChanges
+	DeleteShifts []uuid.UUID
+}
+
+// IsEmpty reports whether there is nothing to write
+func (c *ScheduleChanges) IsEmpty() bool {
+	return len(c.CreateShifts) == 0 && c.Assignments.IsEmpty() && len(c.DeleteShifts) == 0
+}
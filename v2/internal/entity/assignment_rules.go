@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// CanCover reports whether the person may work a shift with the given
+// specialty constraint. BODY_ONLY and NEURO_ONLY shifts need a radiologist of
+// that specialty or one who reads both; BOTH and unconstrained shifts take
+// anyone.
+func (p *Person) CanCover(constraint SpecialtyType) bool {
+	switch constraint {
+	case SpecialtyBodyOnly, SpecialtyNeuroOnly:
+		return p.Specialty == constraint || p.Specialty == SpecialtyBoth
+	default:
+		return true
+	}
+}
+
+// Window returns when the shift starts and ends. A shift ending at or before
+// its start time runs overnight; one without times spans its whole date.
+func (s *ShiftInstance) Window() (time.Time, time.Time) {
+	day := time.Date(s.ScheduleDate.Year(), s.ScheduleDate.Month(), s.ScheduleDate.Day(), 0, 0, 0, 0, time.UTC)
+	start, startOK := clockOffset(s.StartTime)
+	end, endOK := clockOffset(s.EndTime)
+	if !startOK || !endOK {
+		return day, day.AddDate(0, 0, 1)
+	}
+	if end <= start {
+		end += 24 * time.Hour
+	}
+	return day.Add(start), day.Add(end)
+}
+
+// Overlaps reports whether two shifts are worked at the same time
+func (s *ShiftInstance) Overlaps(other *ShiftInstance) bool {
+	start, end := s.Window()
+	otherStart, otherEnd := other.Window()
+	return start.Before(otherEnd) && otherStart.Before(end)
+}
+
+// clockOffset parses an HH:MM clock time as an offset from midnight
+func clockOffset(clock string) (time.Duration, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, true
+}
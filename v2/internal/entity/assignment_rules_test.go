@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonCanCover(t *testing.T) {
+	body := &Person{Specialty: SpecialtyBodyOnly}
+	both := &Person{Specialty: SpecialtyBoth}
+
+	assert.True(t, body.CanCover(SpecialtyBodyOnly))
+	assert.False(t, body.CanCover(SpecialtyNeuroOnly))
+	assert.True(t, body.CanCover(SpecialtyBoth), "BOTH shifts take either specialty")
+	assert.True(t, body.CanCover(""))
+	assert.True(t, both.CanCover(SpecialtyNeuroOnly))
+}
+
+func TestShiftInstanceOverlaps(t *testing.T) {
+	nov3 := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	shift := func(date time.Time, start, end string) *ShiftInstance {
+		return &ShiftInstance{ScheduleDate: date, StartTime: start, EndTime: end}
+	}
+	day := shift(nov3, "07:00", "17:00")
+	overnight := shift(nov3, "19:00", "07:00")
+	nextMorning := shift(nov3.AddDate(0, 0, 1), "06:00", "14:00")
+
+	assert.True(t, day.Overlaps(shift(nov3, "16:00", "20:00")))
+	assert.False(t, day.Overlaps(overnight), "back-to-back shifts do not overlap")
+	assert.True(t, overnight.Overlaps(nextMorning), "overnight shifts run into the next date")
+	assert.False(t, day.Overlaps(nextMorning))
+	assert.True(t, shift(nov3, "", "").Overlaps(day), "a shift without times spans its date")
+
+	_, end := overnight.Window()
+	assert.Equal(t, time.Date(2025, 11, 4, 7, 0, 0, 0, time.UTC), end)
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BatchRollback records what rolling back a scrape batch soft-deleted. Only
+// rows the batch imported are removed; MANUAL and OVERRIDE assignments, and
+// the shifts they are on, stay.
+type BatchRollback struct {
+	ScrapeBatchID      uuid.UUID   `json:"scrape_batch_id"`
+	AssignmentsDeleted int         `json:"assignments_deleted"`
+	ShiftsDeleted      int         `json:"shifts_deleted"`
+	AssignmentsKept    int         `json:"assignments_kept"` // MANUAL/OVERRIDE rows left on the batch's shifts
+	ScheduleVersionIDs []uuid.UUID `json:"schedule_version_ids"`
+	RolledBackAt       time.Time   `json:"rolled_back_at"`
+	RolledBackBy       uuid.UUID   `json:"rolled_back_by"`
+}
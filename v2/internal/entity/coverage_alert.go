@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertTier is who a coverage gap alert is escalated to
+type AlertTier string
+
+const (
+	AlertTierScheduler AlertTier = "SCHEDULER"
+	AlertTierChief     AlertTier = "CHIEF"
+	AlertTierAdmin     AlertTier = "ADMIN"
+)
+
+// Rank orders tiers so escalation only moves upward
+func (t AlertTier) Rank() int {
+	switch t {
+	case AlertTierScheduler:
+		return 1
+	case AlertTierChief:
+		return 2
+	case AlertTierAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// AlertState is the lifecycle state of an alert
+type AlertState string
+
+const (
+	AlertStateOpen     AlertState = "OPEN"
+	AlertStateResolved AlertState = "RESOLVED"
+)
+
+// CoverageAlert is raised for a mandatory shift in a PRODUCTION schedule that is
+// UNCOVERED or PARTIAL. GapKey identifies the slot (date, type, study, times)
+// independently of version-specific shift IDs so one gap gets one alert.
+type CoverageAlert struct {
+	ID                uuid.UUID
+	HospitalID        uuid.UUID
+	ScheduleVersionID uuid.UUID
+	ShiftInstanceID   uuid.UUID
+	GapKey            string
+	ShiftType         ShiftType
+	StudyType         StudyType
+	ShiftStart        time.Time
+	CoverageStatus    string // PARTIAL | UNCOVERED
+	Required          int
+	Assigned          int
+	Tier              AlertTier
+	State             AlertState
+	ResolutionReason  string // FILLED | EXPIRED | REMOVED
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	EscalatedAt       *time.Time
+	ResolvedAt        *time.Time
+}
+
+// Alert resolution reasons
+const (
+	AlertResolvedFilled  = "FILLED"  // An assignment now covers the shift
+	AlertResolvedExpired = "EXPIRED" // The shift has started
+	AlertResolvedRemoved = "REMOVED" // The shift is no longer in the PRODUCTION schedule
+)
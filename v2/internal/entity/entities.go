@@ -126,10 +126,32 @@ type ShiftInstance struct {
 	SpecialtyConstraint SpecialtyType // Guides coverage resolution
 	DesiredCoverage     int           // How many people needed
 	IsMandatory         bool
+	ScrapeBatchID       *uuid.UUID // Batch that created the shift; nil when created by hand
 	CreatedAt           time.Time
 	CreatedBy           uuid.UUID
 }
 
+// ShiftSlot identifies the place a shift fills in a version: the same shift
+// type can run on one date for several study types and specialties, e.g. a
+// BODY and a NEURO ON1. Imports, merges and generated shifts keep one shift
+// per slot.
+type ShiftSlot struct {
+	Date      string // YYYY-MM-DD
+	ShiftType ShiftType
+	StudyType StudyType
+	Specialty SpecialtyType
+}
+
+// Slot returns the slot the shift fills
+func (s *ShiftInstance) Slot() ShiftSlot {
+	return ShiftSlot{
+		Date:      s.ScheduleDate.Format("2006-01-02"),
+		ShiftType: s.ShiftType,
+		StudyType: s.StudyType,
+		Specialty: s.SpecialtyConstraint,
+	}
+}
+
 // Assignment maps a person to a shift
 // Source tracking enables audit trail
 type Assignment struct {
@@ -139,10 +161,13 @@ type Assignment struct {
 	ScheduleDate      time.Time
 	OriginalShiftType string // What Amion said
 	Source            AssignmentSource
+	ScrapeBatchID     *uuid.UUID // Batch that created the assignment; nil when created by hand
 	CreatedAt         time.Time
 	CreatedBy         uuid.UUID
 	DeletedAt         *time.Time
 	DeletedBy         *uuid.UUID
+	UnpinnedAt        *time.Time // Set when a MANUAL/OVERRIDE assignment stops blocking imports
+	UnpinnedBy        *uuid.UUID
 }
 
 // AssignmentSource tracks where an assignment came from
@@ -167,12 +192,16 @@ type ScrapeBatch struct {
 	RowCount         int
 	IngestChecksum   string    // Detects corrupted imports
 	ErrorMessage     *string
+	DivisionStats    []DivisionScrapeStats // Per-division results when the hospital has several Amion divisions
+	Delta            *ScrapeDelta          // Changes since the previous scrape; nil without one to compare
 	CreatedAt        time.Time
 	CreatedBy        uuid.UUID
 	DeletedAt        *time.Time // Soft delete
 	DeletedBy        *uuid.UUID
 	ArchivedAt       *time.Time // Archival support
 	ArchivedBy       *uuid.UUID
+	RolledBackAt     *time.Time // Rows the batch created were soft-deleted
+	RolledBackBy     *uuid.UUID
 }
 
 // BatchState represents the lifecycle of a batch operation
@@ -222,11 +251,33 @@ func (a *Assignment) IsDeleted() bool {
 	return a.DeletedAt != nil
 }
 
+// IsPinned reports whether imports must leave the assignment's shift/date
+// alone: MANUAL and OVERRIDE assignments are pinned until unpinned
+func (a *Assignment) IsPinned() bool {
+	return (a.Source == AssignmentSourceManual || a.Source == AssignmentSourceOverride) && a.UnpinnedAt == nil
+}
+
+// Unpin lets imports replace a MANUAL/OVERRIDE assignment
+func (a *Assignment) Unpin(unpinnerID uuid.UUID) error {
+	if !a.IsPinned() {
+		return ErrAssignmentNotPinned
+	}
+	now := time.Now().UTC()
+	a.UnpinnedAt = &now
+	a.UnpinnedBy = &unpinnerID
+	return nil
+}
+
 // IsDeleted checks if a batch is soft-deleted
 func (b *ScrapeBatch) IsDeleted() bool {
 	return b.DeletedAt != nil
 }
 
+// IsRolledBack checks if the rows a batch created were rolled back
+func (b *ScrapeBatch) IsRolledBack() bool {
+	return b.RolledBackAt != nil
+}
+
 // IsDeleted checks if a schedule version is soft-deleted
 func (sv *ScheduleVersion) IsDeleted() bool {
 	return sv.DeletedAt != nil
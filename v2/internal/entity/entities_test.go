@@ -81,6 +81,22 @@ func TestAssignmentSoftDelete(t *testing.T) {
 	assert.Equal(t, deleterID, *assignment.DeletedBy)
 }
 
+// TestAssignmentPinning tests that MANUAL/OVERRIDE assignments are pinned until unpinned
+func TestAssignmentPinning(t *testing.T) {
+	amion := &Assignment{ID: uuid.New(), Source: AssignmentSourceAmion}
+	override := &Assignment{ID: uuid.New(), Source: AssignmentSourceOverride}
+
+	assert.False(t, amion.IsPinned())
+	assert.Equal(t, ErrAssignmentNotPinned, amion.Unpin(uuid.New()))
+	assert.True(t, override.IsPinned())
+
+	unpinnerID := uuid.New()
+	assert.NoError(t, override.Unpin(unpinnerID))
+	assert.False(t, override.IsPinned())
+	assert.Equal(t, unpinnerID, *override.UnpinnedBy)
+	assert.Equal(t, ErrAssignmentNotPinned, override.Unpin(unpinnerID))
+}
+
 // TestScheduleVersionCreation tests schedule version creation
 func TestScheduleVersionCreation(t *testing.T) {
 	hospitalID := uuid.New()
@@ -10,6 +10,7 @@ var (
 	ErrEmptyValidationResult         = errors.New("validation result cannot be empty")
 	ErrUnknownShiftType              = errors.New("unknown shift type")
 	ErrUnknownSpecialty              = errors.New("unknown specialty type")
+	ErrAssignmentNotPinned           = errors.New("assignment is not pinned")
 )
 
 // ValidateVersionStatus validates a version status string
@@ -33,6 +34,13 @@ func ValidateSpecialty(specialty string) bool {
 		specialty == string(SpecialtyBoth)
 }
 
+// ValidateStudyType validates a study type
+func ValidateStudyType(studyType string) bool {
+	return studyType == string(StudyTypeGeneral) ||
+		studyType == string(StudyTypeBodyImaging) ||
+		studyType == string(StudyTypeNeuroImaging)
+}
+
 // ValidateShiftType validates a shift type
 func ValidateShiftType(shiftType string) bool {
 	return shiftType == string(ShiftTypeON1) ||
@@ -41,3 +49,10 @@ func ValidateShiftType(shiftType string) bool {
 		shiftType == string(ShiftTypeMidL) ||
 		shiftType == string(ShiftTypeDay)
 }
+
+// ValidateDayType validates a day type
+func ValidateDayType(dayType string) bool {
+	return dayType == string(DayTypeWeekday) ||
+		dayType == string(DayTypeWeekend) ||
+		dayType == string(DayTypeHoliday)
+}
@@ -0,0 +1,68 @@
+package entity
+
+import "time"
+
+// DayType is how a date is staffed: as a weekday, a weekend day or a holiday
+type DayType string
+
+const (
+	DayTypeWeekday DayType = "WEEKDAY"
+	DayTypeWeekend DayType = "WEEKEND"
+	DayTypeHoliday DayType = "HOLIDAY"
+)
+
+// HolidayCalendar names the dates staffed as holidays, keyed by YYYY-MM-DD
+type HolidayCalendar map[string]string
+
+// NewHolidayCalendar returns the major US holidays hospitals staff as
+// holidays (New Year's Day, Memorial Day, Independence Day, Labor Day,
+// Thanksgiving and Christmas) for every year from..to touches. Holidays are
+// kept on their actual date, not moved to an observed weekday.
+func NewHolidayCalendar(from, to time.Time) HolidayCalendar {
+	c := HolidayCalendar{}
+	for year := from.Year(); year <= to.Year(); year++ {
+		c.Add(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), "New Year's Day")
+		c.Add(lastWeekday(year, time.May, time.Monday), "Memorial Day")
+		c.Add(time.Date(year, time.July, 4, 0, 0, 0, 0, time.UTC), "Independence Day")
+		c.Add(nthWeekday(year, time.September, time.Monday, 1), "Labor Day")
+		c.Add(nthWeekday(year, time.November, time.Thursday, 4), "Thanksgiving")
+		c.Add(time.Date(year, time.December, 25, 0, 0, 0, 0, time.UTC), "Christmas Day")
+	}
+	return c
+}
+
+// Add marks a date as a holiday
+func (c HolidayCalendar) Add(date time.Time, name string) {
+	c[date.Format("2006-01-02")] = name
+}
+
+// Holiday returns the name of the holiday on date, if it is one
+func (c HolidayCalendar) Holiday(date time.Time) (string, bool) {
+	name, ok := c[date.Format("2006-01-02")]
+	return name, ok
+}
+
+// DayType classifies date: holidays first, then Saturdays and Sundays
+func (c HolidayCalendar) DayType(date time.Time) DayType {
+	if _, ok := c.Holiday(date); ok {
+		return DayTypeHoliday
+	}
+	if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return DayTypeWeekend
+	}
+	return DayTypeWeekday
+}
+
+// nthWeekday returns the nth (1-based) given weekday of a month
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+// lastWeekday returns the last given weekday of a month
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestHolidayCalendar(t *testing.T) {
+	c := NewHolidayCalendar(day("2025-11-01"), day("2026-01-31"))
+
+	for date, name := range map[string]string{
+		"2025-11-27": "Thanksgiving",
+		"2025-12-25": "Christmas Day",
+		"2026-01-01": "New Year's Day",
+		"2026-05-25": "Memorial Day",
+		"2026-09-07": "Labor Day",
+	} {
+		got, ok := c.Holiday(day(date))
+		assert.True(t, ok, date)
+		assert.Equal(t, name, got, date)
+	}
+
+	assert.Equal(t, DayTypeHoliday, c.DayType(day("2025-11-27")))
+	assert.Equal(t, DayTypeWeekend, c.DayType(day("2025-11-29")))
+	assert.Equal(t, DayTypeWeekday, c.DayType(day("2025-11-28")))
+
+	c.Add(day("2025-11-28"), "Day after Thanksgiving")
+	assert.Equal(t, DayTypeHoliday, c.DayType(day("2025-11-28")))
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationChannel identifies how a person is contacted
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "EMAIL"
+	NotificationChannelSMS   NotificationChannel = "SMS"
+)
+
+// DigestMode controls whether changes are sent immediately or batched
+type DigestMode string
+
+const (
+	DigestModeImmediate DigestMode = "IMMEDIATE"
+	DigestModeDaily     DigestMode = "DAILY"
+)
+
+// NotificationPreference holds a person's schedule-change notification settings
+type NotificationPreference struct {
+	PersonID    uuid.UUID
+	Enabled     bool
+	Channels    []NotificationChannel
+	PhoneNumber string     // E.164, required for SMS
+	DigestMode  DigestMode // IMMEDIATE | DAILY
+	DigestHour  int        // 0-23 UTC, used with DAILY
+	UpdatedAt   time.Time
+}
+
+// DefaultNotificationPreference is used for people who never set preferences:
+// immediate email
+func DefaultNotificationPreference(personID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{
+		PersonID:   personID,
+		Enabled:    true,
+		Channels:   []NotificationChannel{NotificationChannelEmail},
+		DigestMode: DigestModeImmediate,
+		DigestHour: 7,
+	}
+}
+
+// PendingNotification is a rendered change set waiting for a person's daily digest
+type PendingNotification struct {
+	ID           uuid.UUID
+	PersonID     uuid.UUID
+	HospitalID   uuid.UUID
+	OldVersionID uuid.UUID
+	NewVersionID uuid.UUID
+	Body         string
+	CreatedAt    time.Time
+	SentAt       *time.Time
+}
+
+// NotificationDeliveryStatus records how a person was told about a promotion
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliverySent   NotificationDeliveryStatus = "SENT"
+	NotificationDeliveryQueued NotificationDeliveryStatus = "QUEUED"
+)
+
+// NotificationDelivery marks a person as handled for one promotion, so a
+// retried notification job does not contact them twice
+type NotificationDelivery struct {
+	OldVersionID uuid.UUID
+	NewVersionID uuid.UUID
+	PersonID     uuid.UUID
+	Status       NotificationDeliveryStatus // SENT | QUEUED
+	CreatedAt    time.Time
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxPeriodDays caps how long a generated or copied schedule period may be
+const MaxPeriodDays = 366
+
+// ErrInvalidPeriod is returned for a period that ends before it starts or is
+// longer than MaxPeriodDays
+var ErrInvalidPeriod = fmt.Errorf("period must end on or after its start and span at most %d days", MaxPeriodDays)
+
+// ValidatePeriod checks that start..end is a usable schedule period
+func ValidatePeriod(start, end time.Time) error {
+	start, end = DateOf(start), DateOf(end)
+	if end.Before(start) || end.Sub(start) >= MaxPeriodDays*24*time.Hour {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
+
+// DateOf returns the calendar date of t as midnight UTC
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// EarlierOf returns the earlier of two times
+func EarlierOf(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// LaterOf returns the later of two times
+func LaterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// Overlap returns the dates two periods share. ok is false when they share none.
+func Overlap(aStart, aEnd, bStart, bEnd time.Time) (start, end time.Time, ok bool) {
+	start = LaterOf(DateOf(aStart), DateOf(bStart))
+	end = EarlierOf(DateOf(aEnd), DateOf(bEnd))
+	return start, end, !end.Before(start)
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MergeResolution is which side of a merge conflict was kept
+type MergeResolution string
+
+const (
+	MergeResolutionStaging  MergeResolution = "STAGING"  // Keep the edited staffing
+	MergeResolutionImported MergeResolution = "IMPORTED" // Take the import's staffing
+)
+
+// ScheduleMerge is a three-way merge of a freshly imported version into an
+// edited STAGING version, using the version both started from as the base.
+// Shifts only the import changed were applied to the STAGING version; shifts
+// both sides changed are left as conflicts.
+type ScheduleMerge struct {
+	ID                uuid.UUID       `json:"id"`
+	HospitalID        uuid.UUID       `json:"hospital_id"`
+	BaseVersionID     uuid.UUID       `json:"base_version_id"`
+	StagingVersionID  uuid.UUID       `json:"staging_version_id"`
+	ImportedVersionID uuid.UUID       `json:"imported_version_id"`
+	AutoApplied       int             `json:"auto_applied"` // Shifts taken from the import without conflict
+	Conflicts         []MergeConflict `json:"conflicts"`
+	CreatedAt         time.Time       `json:"created_at"`
+	CreatedBy         uuid.UUID       `json:"created_by"`
+}
+
+// MergeConflict is a shift slot whose staffing was changed differently by the
+// STAGING edits and by the import
+type MergeConflict struct {
+	ID                  uuid.UUID       `json:"id"`
+	MergeID             uuid.UUID       `json:"merge_id"`
+	ScheduleDate        time.Time       `json:"schedule_date"`
+	ShiftType           ShiftType       `json:"shift_type"`
+	StudyType           StudyType       `json:"study_type"`
+	SpecialtyConstraint SpecialtyType   `json:"specialty_constraint"`
+	BasePersonIDs       []uuid.UUID     `json:"base_person_ids"`
+	StagingPersonIDs    []uuid.UUID     `json:"staging_person_ids"`
+	ImportedPersonIDs   []uuid.UUID     `json:"imported_person_ids"`
+	Resolution          MergeResolution `json:"resolution,omitempty"`
+	ResolvedAt          *time.Time      `json:"resolved_at,omitempty"`
+	ResolvedBy          *uuid.UUID      `json:"resolved_by,omitempty"`
+}
+
+// Slot returns the shift slot the conflict is about
+func (c *MergeConflict) Slot() ShiftSlot {
+	return ShiftSlot{
+		Date:      c.ScheduleDate.Format("2006-01-02"),
+		ShiftType: c.ShiftType,
+		StudyType: c.StudyType,
+		Specialty: c.SpecialtyConstraint,
+	}
+}
+
+// IsResolved reports whether a side has been chosen
+func (c *MergeConflict) IsResolved() bool {
+	return c.ResolvedAt != nil
+}
+
+// OpenConflicts counts the conflicts still waiting for a resolution
+func (m *ScheduleMerge) OpenConflicts() int {
+	open := 0
+	for i := range m.Conflicts {
+		if !m.Conflicts[i].IsResolved() {
+			open++
+		}
+	}
+	return open
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "github.com/google/uuid"
+
+// ScrapedAssignment is one staff member on one Amion shift as a scrape saw
+// it. Each batch keeps its own, so the next scrape can be compared against it.
+type ScrapedAssignment struct {
+	ScrapeBatchID uuid.UUID `json:"-"`
+	Date          string    `json:"date"`  // YYYY-MM-DD
+	Shift         string    `json:"shift"` // Amion label
+	StartTime     string    `json:"start_time"`
+	EndTime       string    `json:"end_time"`
+	Location      string    `json:"location,omitempty"`
+	Person        string    `json:"person"` // Amion staff name
+}
+
+// AssignmentMove is a staff member Amion moved to another shift on the same date
+type AssignmentMove struct {
+	Person string            `json:"person"`
+	From   ScrapedAssignment `json:"from"`
+	To     ScrapedAssignment `json:"to"`
+}
+
+// DateDelta lists what changed on one date
+type DateDelta struct {
+	Date    string              `json:"date"`
+	Added   []ScrapedAssignment `json:"added,omitempty"`
+	Removed []ScrapedAssignment `json:"removed,omitempty"`
+	Moved   []AssignmentMove    `json:"moved,omitempty"`
+}
+
+// ScrapeDelta is how Amion's published schedule changed since the hospital's
+// previous scrape, over the dates both scrapes covered
+type ScrapeDelta struct {
+	PreviousBatchID  uuid.UUID   `json:"previous_batch_id"`
+	WindowStart      string      `json:"window_start"`
+	WindowEnd        string      `json:"window_end"`
+	Added            int         `json:"added"`
+	Removed          int         `json:"removed"`
+	Moved            int         `json:"moved"`
+	Dates            []DateDelta `json:"dates,omitempty"` // Only dates with changes
+	StagedVersionIDs []uuid.UUID `json:"staged_version_ids,omitempty"`
+}
+
+// Changed reports whether anything changed
+func (d *ScrapeDelta) Changed() bool {
+	return d.Added+d.Removed+d.Moved > 0
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShiftTemplate is a recurring shift requirement of a hospital, used to
+// generate shift instances for periods no ODS file covers. The template runs
+// on its Weekdays between EffectiveFrom and EffectiveUntil; each day takes
+// its times, study type and coverage from the variant for its day type.
+type ShiftTemplate struct {
+	ID             uuid.UUID
+	HospitalID     uuid.UUID
+	Name           string
+	ShiftType      ShiftType
+	Weekdays       []time.Weekday         // Days of the week the shift runs
+	Variants       []ShiftTemplateVariant // At most one per day type
+	SkipHolidays   bool                   // No shift on holidays without a HOLIDAY variant
+	EffectiveFrom  time.Time
+	EffectiveUntil *time.Time // nil runs indefinitely
+	CreatedAt      time.Time
+	CreatedBy      uuid.UUID
+	UpdatedAt      time.Time
+	DeletedAt      *time.Time
+	DeletedBy      *uuid.UUID
+}
+
+// ShiftTemplateVariant is how a template's shift is staffed on one day type
+type ShiftTemplateVariant struct {
+	DayType             DayType       `json:"day_type"`
+	StartTime           string        `json:"start_time"` // HH:MM
+	EndTime             string        `json:"end_time"`   // HH:MM
+	StudyType           StudyType     `json:"study_type"`
+	SpecialtyConstraint SpecialtyType `json:"specialty_constraint"`
+	DesiredCoverage     int           `json:"desired_coverage"`
+	IsMandatory         bool          `json:"is_mandatory"`
+}
+
+// InEffect reports whether date falls within the template's effective dates
+func (t *ShiftTemplate) InEffect(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if day < t.EffectiveFrom.Format("2006-01-02") {
+		return false
+	}
+	return t.EffectiveUntil == nil || day <= t.EffectiveUntil.Format("2006-01-02")
+}
+
+// RunsOn reports whether the template's weekly pattern includes weekday
+func (t *ShiftTemplate) RunsOn(weekday time.Weekday) bool {
+	for _, wd := range t.Weekdays {
+		if wd == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Variant returns the template's variant for a day type, or nil if it has none
+func (t *ShiftTemplate) Variant(dayType DayType) *ShiftTemplateVariant {
+	for i := range t.Variants {
+		if t.Variants[i].DayType == dayType {
+			return &t.Variants[i]
+		}
+	}
+	return nil
+}
+
+// VariantFor returns the variant staffing date, or nil when the template
+// generates no shift that day. A holiday on one of the template's weekdays
+// uses the HOLIDAY variant; without one it is skipped if SkipHolidays is set
+// and otherwise staffed as the weekday or weekend day it falls on.
+func (t *ShiftTemplate) VariantFor(date time.Time, holidays HolidayCalendar) *ShiftTemplateVariant {
+	if !t.InEffect(date) || !t.RunsOn(date.Weekday()) {
+		return nil
+	}
+	if _, ok := holidays.Holiday(date); ok {
+		if v := t.Variant(DayTypeHoliday); v != nil {
+			return v
+		}
+		if t.SkipHolidays {
+			return nil
+		}
+	}
+	if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return t.Variant(DayTypeWeekend)
+	}
+	return t.Variant(DayTypeWeekday)
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftTemplate_VariantFor(t *testing.T) {
+	until := day("2025-12-31")
+	tmpl := &ShiftTemplate{
+		ShiftType: ShiftTypeDay,
+		Weekdays:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+		Variants: []ShiftTemplateVariant{
+			{DayType: DayTypeWeekday, StartTime: "07:00", EndTime: "15:00", DesiredCoverage: 2},
+			{DayType: DayTypeWeekend, StartTime: "08:00", EndTime: "14:00", DesiredCoverage: 1},
+		},
+		EffectiveFrom:  day("2025-11-01"),
+		EffectiveUntil: &until,
+	}
+	holidays := NewHolidayCalendar(day("2025-11-01"), day("2026-01-31"))
+
+	assert.Equal(t, 2, tmpl.VariantFor(day("2025-11-03"), holidays).DesiredCoverage, "Monday")
+	assert.Equal(t, 1, tmpl.VariantFor(day("2025-11-01"), holidays).DesiredCoverage, "Saturday")
+	assert.Nil(t, tmpl.VariantFor(day("2025-11-02"), holidays), "Sunday is not in the pattern")
+	assert.Nil(t, tmpl.VariantFor(day("2025-10-31"), holidays), "before the template takes effect")
+	assert.Nil(t, tmpl.VariantFor(day("2026-01-02"), holidays), "after the template ends")
+	assert.Equal(t, 2, tmpl.VariantFor(day("2025-11-27"), holidays).DesiredCoverage, "Thanksgiving is staffed as a Thursday")
+
+	tmpl.SkipHolidays = true
+	assert.Nil(t, tmpl.VariantFor(day("2025-11-27"), holidays))
+
+	tmpl.Variants = append(tmpl.Variants, ShiftTemplateVariant{DayType: DayTypeHoliday, DesiredCoverage: 1, IsMandatory: true})
+	assert.True(t, tmpl.VariantFor(day("2025-11-27"), holidays).IsMandatory)
+	holidays.Add(day("2025-11-30"), "Hospital holiday")
+	assert.Nil(t, tmpl.VariantFor(day("2025-11-30"), holidays), "a Sunday holiday stays off the pattern")
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookSubscription registers a downstream system for schedule lifecycle notifications
+type WebhookSubscription struct {
+	ID         uuid.UUID
+	HospitalID uuid.UUID
+	URL        string
+	Secret     string   // HMAC-SHA256 signing key; only returned when the subscription is created
+	EventTypes []string // e.g. version.promoted, coverage.dropped, import.failed; empty = all
+	Active     bool
+	CreatedAt  time.Time
+	CreatedBy  uuid.UUID
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+	DeletedBy  *uuid.UUID
+}
+
+// Accepts reports whether the subscription wants events of the given type
+func (s *WebhookSubscription) Accepts(eventType string) bool {
+	if !s.Active || s.DeletedAt != nil {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SoftDelete marks a webhook subscription as deleted
+func (s *WebhookSubscription) SoftDelete(deleterID uuid.UUID) {
+	now := time.Now().UTC()
+	s.DeletedAt = &now
+	s.DeletedBy = &deleterID
+	s.Active = false
+}
+
+// WebhookDelivery is the log entry for one event sent to one subscription
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	HospitalID     uuid.UUID
+	EventType      string
+	Payload        []byte // Exact JSON body that is signed and sent
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	ResponseStatus *int
+	ResponseBody   *string // Truncated
+	ErrorMessage   *string
+	RedeliveryOf   *uuid.UUID // Set when created through the redelivery endpoint
+	CreatedAt      time.Time
+	LastAttemptAt  *time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
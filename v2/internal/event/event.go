@@ -0,0 +1,99 @@
+// Package event provides the internal event bus used to push job, version and
+// coverage updates to connected clients (Server-Sent Events) and other consumers.
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies the kind of event published on the bus
+type Type string
+
+const (
+	// TypeJobStateChanged is published whenever a background job changes state
+	TypeJobStateChanged Type = "job.state_changed"
+	// TypeVersionPromoted is published when a schedule version becomes PRODUCTION
+	TypeVersionPromoted Type = "version.promoted"
+	// TypeCoverageRecalculated is published after a coverage calculation completes
+	TypeCoverageRecalculated Type = "coverage.recalculated"
+	// TypeCoverageDropped is published when a recalculation finds lower coverage than the previous one
+	TypeCoverageDropped Type = "coverage.dropped"
+	// TypeImportFailed is published when an ODS or Amion import fails after its last retry
+	TypeImportFailed Type = "import.failed"
+	// TypeCoverageGapAlert is published when a coverage gap alert is raised, escalated or resolved
+	TypeCoverageGapAlert Type = "coverage.gap_alert"
+)
+
+// JobState is the state carried by TypeJobStateChanged events
+type JobState string
+
+const (
+	JobStateRunning   JobState = "RUNNING"
+	JobStateCompleted JobState = "COMPLETED"
+	JobStateFailed    JobState = "FAILED"
+)
+
+// ErrBusClosed is returned when publishing to or subscribing on a closed bus
+var ErrBusClosed = errors.New("event bus is closed")
+
+// Event is a single notification delivered to subscribers.
+// IDs are assigned by the bus and increase monotonically, which is what makes
+// Last-Event-ID resume possible.
+type Event struct {
+	ID         int64                  `json:"id"`
+	Type       Type                   `json:"type"`
+	HospitalID uuid.UUID              `json:"hospital_id"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+
+	insertedAt time.Time // Set by PostgresBus when read back from the events table
+}
+
+// New creates an event for a hospital with the current timestamp
+func New(eventType Type, hospitalID uuid.UUID, data map[string]interface{}) *Event {
+	return &Event{
+		Type:       eventType,
+		HospitalID: hospitalID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publisher publishes events to the bus
+type Publisher interface {
+	// Publish assigns the event an ID and delivers it to all matching subscribers
+	Publish(ctx context.Context, evt *Event) error
+}
+
+// Subscriber streams events from the bus
+type Subscriber interface {
+	// Subscribe returns a channel of events for hospitalID (uuid.Nil for all hospitals).
+	// Events with an ID greater than afterID that are still retained are replayed first.
+	// The channel is closed when ctx is cancelled, the bus is closed, or the
+	// subscriber falls too far behind; clients should then resume from the last ID seen.
+	Subscribe(ctx context.Context, hospitalID uuid.UUID, afterID int64) (<-chan *Event, error)
+}
+
+// Pruner deletes stored events nobody can resume from any more
+type Pruner interface {
+	// Prune deletes events inserted before `before` whose ID is at most throughID
+	// (throughID <= 0 means no ID limit), always keeping the latest DefaultRetention
+	// events. It returns the number of events deleted.
+	Prune(ctx context.Context, before time.Time, throughID int64) (int64, error)
+}
+
+// Bus combines publishing and subscribing
+type Bus interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+// Matches reports whether the event belongs to the given hospital filter
+func (e *Event) Matches(hospitalID uuid.UUID) bool {
+	return hospitalID == uuid.Nil || e.HospitalID == hospitalID
+}
@@ -0,0 +1,123 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is how many undelivered events a subscriber may lag behind
+// before it is disconnected and has to resume with Last-Event-ID
+const subscriberBuffer = 64
+
+// hub fans events out to in-process subscribers.
+// Both the in-memory and PostgreSQL buses use it for local delivery.
+type hub struct {
+	mu     sync.Mutex
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+// subscription is a single registered listener on the hub
+type subscription struct {
+	hospitalID uuid.UUID
+	ch         chan *Event
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[*subscription]struct{})}
+}
+
+// add registers a new subscription (caller must hold h.mu)
+func (h *hub) addLocked(hospitalID uuid.UUID) (*subscription, error) {
+	if h.closed {
+		return nil, ErrBusClosed
+	}
+	sub := &subscription{
+		hospitalID: hospitalID,
+		ch:         make(chan *Event, subscriberBuffer),
+	}
+	h.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// remove unregisters a subscription and closes its channel
+func (h *hub) remove(sub *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+func (h *hub) removeLocked(sub *subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// dispatchLocked delivers an event to every matching subscriber (caller must hold h.mu).
+// Slow subscribers are dropped rather than blocking the publisher.
+func (h *hub) dispatchLocked(evt *Event) {
+	for sub := range h.subs {
+		if !evt.Matches(sub.hospitalID) {
+			continue
+		}
+		select {
+		case sub.ch <- evt:
+		default:
+			h.removeLocked(sub)
+		}
+	}
+}
+
+// closeLocked disconnects all subscribers (caller must hold h.mu)
+func (h *hub) closeLocked() {
+	h.closed = true
+	for sub := range h.subs {
+		h.removeLocked(sub)
+	}
+}
+
+// stream forwards replayed events followed by live events to a new channel.
+// Live events already covered by the replay are skipped so clients never see duplicates.
+func (h *hub) stream(ctx context.Context, sub *subscription, replay []*Event) <-chan *Event {
+	out := make(chan *Event)
+
+	go func() {
+		defer close(out)
+		defer h.remove(sub)
+
+		// Live events can commit out of ID order, so only skip ones already replayed
+		replayed := make(map[int64]bool, len(replay))
+		for _, evt := range replay {
+			select {
+			case out <- evt:
+				replayed[evt.ID] = true
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			select {
+			case evt, ok := <-sub.ch:
+				if !ok {
+					return
+				}
+				if replayed[evt.ID] {
+					continue
+				}
+				select {
+				case out <- evt:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
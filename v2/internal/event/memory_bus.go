@@ -0,0 +1,83 @@
+package event
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRetention is the number of recent events kept for Last-Event-ID resume
+const DefaultRetention = 1000
+
+// MemoryBus is an in-process event bus.
+// It is suitable for a single API replica and for tests; multi-replica
+// deployments should use PostgresBus so every replica sees the same events.
+type MemoryBus struct {
+	*hub
+	nextID    int64
+	retention int
+	recent    []*Event // ring of the most recent events, oldest first
+}
+
+// NewMemoryBus creates an in-memory bus that retains the last `retention` events for replay
+func NewMemoryBus(retention int) *MemoryBus {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &MemoryBus{
+		hub:       newHub(),
+		retention: retention,
+		recent:    make([]*Event, 0, retention),
+	}
+}
+
+// Publish assigns the next ID and delivers the event to matching subscribers
+func (b *MemoryBus) Publish(ctx context.Context, evt *Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBusClosed
+	}
+
+	b.nextID++
+	evt.ID = b.nextID
+
+	if len(b.recent) == b.retention {
+		b.recent = b.recent[1:]
+	}
+	b.recent = append(b.recent, evt)
+
+	b.dispatchLocked(evt)
+	return nil
+}
+
+// Subscribe replays retained events after afterID and then streams live events
+func (b *MemoryBus) Subscribe(ctx context.Context, hospitalID uuid.UUID, afterID int64) (<-chan *Event, error) {
+	b.mu.Lock()
+	sub, err := b.addLocked(hospitalID)
+	if err != nil {
+		b.mu.Unlock()
+		return nil, err
+	}
+
+	var replay []*Event
+	if afterID > 0 {
+		for _, evt := range b.recent {
+			if evt.ID > afterID && evt.Matches(hospitalID) {
+				replay = append(replay, evt)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	return b.stream(ctx, sub, replay), nil
+}
+
+// Close disconnects all subscribers and rejects further publishes
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closeLocked()
+	return nil
+}
@@ -0,0 +1,131 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, ch <-chan *Event) *Event {
+	t.Helper()
+	select {
+	case evt, ok := <-ch:
+		require.True(t, ok, "channel closed unexpectedly")
+		return evt
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestMemoryBus_PublishAssignsIncreasingIDs(t *testing.T) {
+	bus := NewMemoryBus(10)
+	defer bus.Close()
+
+	hospitalID := uuid.New()
+	first := New(TypeVersionPromoted, hospitalID, nil)
+	second := New(TypeVersionPromoted, hospitalID, nil)
+
+	require.NoError(t, bus.Publish(context.Background(), first))
+	require.NoError(t, bus.Publish(context.Background(), second))
+
+	assert.Equal(t, int64(1), first.ID)
+	assert.Equal(t, int64(2), second.ID)
+}
+
+func TestMemoryBus_FiltersByHospital(t *testing.T) {
+	bus := NewMemoryBus(10)
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mine := uuid.New()
+	other := uuid.New()
+
+	events, err := bus.Subscribe(ctx, mine, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(ctx, New(TypeJobStateChanged, other, nil)))
+	require.NoError(t, bus.Publish(ctx, New(TypeJobStateChanged, mine, nil)))
+
+	evt := receive(t, events)
+	assert.Equal(t, mine, evt.HospitalID)
+	assert.Equal(t, int64(2), evt.ID)
+}
+
+func TestMemoryBus_ResumeAfterLastEventID(t *testing.T) {
+	bus := NewMemoryBus(10)
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hospitalID := uuid.New()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, bus.Publish(ctx, New(TypeJobStateChanged, hospitalID, nil)))
+	}
+
+	events, err := bus.Subscribe(ctx, hospitalID, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), receive(t, events).ID)
+	assert.Equal(t, int64(3), receive(t, events).ID)
+
+	// Live events continue after the replay without duplicates
+	require.NoError(t, bus.Publish(ctx, New(TypeJobStateChanged, hospitalID, nil)))
+	assert.Equal(t, int64(4), receive(t, events).ID)
+}
+
+func TestMemoryBus_RetentionLimit(t *testing.T) {
+	bus := NewMemoryBus(2)
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hospitalID := uuid.New()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, bus.Publish(ctx, New(TypeJobStateChanged, hospitalID, nil)))
+	}
+
+	events, err := bus.Subscribe(ctx, hospitalID, 1)
+	require.NoError(t, err)
+
+	// Only the two most recent events are still retained
+	assert.Equal(t, int64(4), receive(t, events).ID)
+	assert.Equal(t, int64(5), receive(t, events).ID)
+}
+
+func TestMemoryBus_CancelClosesChannel(t *testing.T) {
+	bus := NewMemoryBus(10)
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := bus.Subscribe(ctx, uuid.Nil, 0)
+	require.NoError(t, err)
+
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestMemoryBus_Closed(t *testing.T) {
+	bus := NewMemoryBus(10)
+	require.NoError(t, bus.Close())
+
+	err := bus.Publish(context.Background(), New(TypeJobStateChanged, uuid.New(), nil))
+	assert.ErrorIs(t, err, ErrBusClosed)
+
+	_, err = bus.Subscribe(context.Background(), uuid.New(), 0)
+	assert.ErrorIs(t, err, ErrBusClosed)
+}
@@ -0,0 +1,311 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// notifyChannel is the LISTEN/NOTIFY channel shared by all API replicas
+const notifyChannel = "schedcu_events"
+
+// commitOverlap is how long after its insert an event is looked for again.
+// BIGSERIAL IDs are handed out at insert, so an event can commit after events
+// with higher IDs have already been read; rows inserted this recently are
+// re-read and the ones already dispatched are skipped.
+const commitOverlap = time.Minute
+
+// eventPageSize is how many stored events one query reads
+const eventPageSize = 1000
+
+// PostgresBus persists events in the events table and uses LISTEN/NOTIFY so
+// that every API replica delivers the same events to its SSE clients.
+// Event IDs come from the table's BIGSERIAL, so Last-Event-ID works across replicas.
+type PostgresBus struct {
+	*hub
+	db       *sql.DB
+	listener *pq.Listener
+	lastID   int64
+	recent   map[int64]time.Time // Events seen within commitOverlap, by insert time
+	newest   time.Time           // Latest insert time seen
+	done     chan struct{}
+}
+
+// NewPostgresBus creates a bus on top of db, listening for notifications on a
+// dedicated connection opened with connStr
+func NewPostgresBus(db *sql.DB, connStr string) (*PostgresBus, error) {
+	listener := pq.NewListener(connStr, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event listener connection problem: %v", err)
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", notifyChannel, err)
+	}
+
+	b := &PostgresBus{
+		hub:      newHub(),
+		db:       db,
+		listener: listener,
+		recent:   make(map[int64]time.Time),
+		done:     make(chan struct{}),
+	}
+
+	// Only events published from now on are pushed live; older ones are replayed on request
+	if err := db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM events`).Scan(&b.lastID); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to read latest event id: %w", err)
+	}
+	late, err := b.loadLate(context.Background())
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	for _, evt := range late {
+		b.seen(evt)
+	}
+
+	go b.run()
+	return b, nil
+}
+
+// Publish stores the event and notifies all replicas.
+// Local subscribers receive it through the same notification path as remote ones.
+func (b *PostgresBus) Publish(ctx context.Context, evt *Event) error {
+	dataJSON, err := json.Marshal(evt.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO events (event_type, hospital_id, occurred_at, data)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	if err := tx.QueryRowContext(ctx, query, string(evt.Type), evt.HospitalID, evt.OccurredAt, dataJSON).Scan(&evt.ID); err != nil {
+		return fmt.Errorf("failed to insert event: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, notifyChannel, fmt.Sprintf("%d", evt.ID)); err != nil {
+		return fmt.Errorf("failed to notify event: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit event: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe replays stored events after afterID and then streams live events
+func (b *PostgresBus) Subscribe(ctx context.Context, hospitalID uuid.UUID, afterID int64) (<-chan *Event, error) {
+	// Register before replaying so nothing published in between is missed;
+	// stream() drops the duplicates
+	b.mu.Lock()
+	sub, err := b.addLocked(hospitalID)
+	b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var replay []*Event
+	if afterID > 0 {
+		replay, err = b.loadAllAfter(ctx, hospitalID, afterID)
+		if err != nil {
+			b.remove(sub)
+			return nil, err
+		}
+	}
+
+	return b.stream(ctx, sub, replay), nil
+}
+
+// Close stops listening and disconnects all subscribers
+func (b *PostgresBus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closeLocked()
+	b.mu.Unlock()
+
+	close(b.done)
+	return b.listener.Close()
+}
+
+// run dispatches new events whenever a notification arrives.
+// Notifications only carry the ID; the rows are read back in order so that a
+// missed notification (e.g. during reconnect) never loses events.
+func (b *PostgresBus) run() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-b.listener.Notify:
+		case <-ticker.C:
+			go b.listener.Ping()
+		}
+
+		late, err := b.loadLate(context.Background())
+		if err != nil {
+			log.Printf("Failed to load events: %v", err)
+			continue
+		}
+		events, err := b.loadAllAfter(context.Background(), uuid.Nil, b.lastID)
+		if err != nil {
+			log.Printf("Failed to load events: %v", err)
+			continue
+		}
+
+		b.mu.Lock()
+		for _, evt := range append(late, events...) {
+			if _, dispatched := b.recent[evt.ID]; dispatched {
+				continue
+			}
+			b.dispatchLocked(evt)
+			b.seen(evt)
+		}
+		b.prune()
+		b.mu.Unlock()
+	}
+}
+
+// Prune deletes old stored events. The latest DefaultRetention events are kept
+// so Last-Event-ID resumes behave like the in-memory bus's ring.
+func (b *PostgresBus) Prune(ctx context.Context, before time.Time, throughID int64) (int64, error) {
+	query := `
+		DELETE FROM events
+		WHERE inserted_at < $1
+		  AND ($2 <= 0 OR id <= $2)
+		  AND id <= (SELECT COALESCE(MAX(id), 0) FROM events) - $3
+	`
+	res, err := b.db.ExecContext(ctx, query, before, throughID, DefaultRetention)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pruned events: %w", err)
+	}
+	return deleted, nil
+}
+
+// seen records a dispatched event so re-reads within commitOverlap skip it
+func (b *PostgresBus) seen(evt *Event) {
+	b.recent[evt.ID] = evt.insertedAt
+	if evt.ID > b.lastID {
+		b.lastID = evt.ID
+	}
+	if evt.insertedAt.After(b.newest) {
+		b.newest = evt.insertedAt
+	}
+}
+
+// prune forgets events too old for loadLate to return again
+func (b *PostgresBus) prune() {
+	cutoff := b.newest.Add(-commitOverlap)
+	for id, insertedAt := range b.recent {
+		if insertedAt.Before(cutoff) {
+			delete(b.recent, id)
+		}
+	}
+}
+
+// loadLate reads events up to lastID inserted within commitOverlap, which
+// includes any that committed after a higher ID had been read
+func (b *PostgresBus) loadLate(ctx context.Context) ([]*Event, error) {
+	query := `
+		SELECT id, event_type, hospital_id, occurred_at, data, inserted_at
+		FROM events
+		WHERE id <= $1 AND inserted_at > NOW() - make_interval(secs => $2)
+		ORDER BY id
+	`
+	rows, err := b.db.QueryContext(ctx, query, b.lastID, commitOverlap.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent events: %w", err)
+	}
+	return scanEvents(rows)
+}
+
+// loadAllAfter reads every stored event with an ID greater than afterID, a
+// page at a time, so a subscriber that was away for a while catches up fully
+// before it switches to live events
+func (b *PostgresBus) loadAllAfter(ctx context.Context, hospitalID uuid.UUID, afterID int64) ([]*Event, error) {
+	var events []*Event
+	for {
+		page, err := b.loadAfter(ctx, hospitalID, afterID)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page...)
+		if len(page) < eventPageSize {
+			return events, nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
+
+// loadAfter reads up to eventPageSize stored events with an ID greater than afterID
+func (b *PostgresBus) loadAfter(ctx context.Context, hospitalID uuid.UUID, afterID int64) ([]*Event, error) {
+	query := `
+		SELECT id, event_type, hospital_id, occurred_at, data, inserted_at
+		FROM events
+		WHERE id > $1 AND ($2 = '00000000-0000-0000-0000-000000000000'::uuid OR hospital_id = $2)
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := b.db.QueryContext(ctx, query, afterID, hospitalID, eventPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	return scanEvents(rows)
+}
+
+// scanEvents reads and closes event rows
+func scanEvents(rows *sql.Rows) ([]*Event, error) {
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		evt := &Event{}
+		var eventType string
+		var dataJSON []byte
+
+		if err := rows.Scan(&evt.ID, &eventType, &evt.HospitalID, &evt.OccurredAt, &dataJSON, &evt.insertedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		evt.Type = Type(eventType)
+
+		if len(dataJSON) > 0 {
+			if err := json.Unmarshal(dataJSON, &evt.Data); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+			}
+		}
+
+		events = append(events, evt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+
+	return events, nil
+}
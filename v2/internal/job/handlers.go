@@ -3,12 +3,21 @@ package job
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/secrets"
 	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/service/alerting"
+	"github.com/schedcu/v2/internal/service/amion"
+	"github.com/schedcu/v2/internal/service/notification"
 )
 
 // JobHandlers manages job execution handlers
@@ -17,6 +26,15 @@ type JobHandlers struct {
 	amionImporter  service.AmionImportService
 	coverageCalc   service.CoverageCalculator
 	versionService service.ScheduleVersionService
+	events         event.Publisher                          // Optional: job state and coverage events
+	webhooks       service.WebhookService                   // Optional: outgoing webhook deliveries
+	notifier       *notification.Notifier                   // Optional: personal schedule change notifications
+	gapMonitor     *alerting.CoverageGapMonitor             // Optional: coverage gap alerts
+	secrets        secrets.Provider                         // Optional: resolves Amion credentials for scrape jobs
+	amionScraper   *amion.Scraper                           // Optional: scrapes hospitals configured with Amion divisions
+	coverageRepo   repository.CoverageCalculationRepository // Optional: keeps calculations so coverage drops can be detected
+	eventPruner    event.Pruner                             // Optional: deletes stored events past their retention
+	eventCursors   repository.EventCursorRepository         // Optional: events the webhook dispatcher has not reached are kept
 }
 
 // NewJobHandlers creates a new job handlers instance
@@ -34,15 +52,65 @@ func NewJobHandlers(
 	}
 }
 
+// SetEventPublisher enables publishing job state transitions and coverage results
+func (h *JobHandlers) SetEventPublisher(events event.Publisher) {
+	h.events = events
+}
+
+// SetWebhookService enables handling of webhook delivery jobs
+func (h *JobHandlers) SetWebhookService(webhooks service.WebhookService) {
+	h.webhooks = webhooks
+}
+
+// SetCoverageCalculationRepository stores every coverage calculation and
+// publishes TypeCoverageDropped when a version's coverage falls below its
+// previous calculation for the same period
+func (h *JobHandlers) SetCoverageCalculationRepository(repo repository.CoverageCalculationRepository) {
+	h.coverageRepo = repo
+}
+
+// SetNotifier enables personal schedule change notifications
+func (h *JobHandlers) SetNotifier(notifier *notification.Notifier) {
+	h.notifier = notifier
+}
+
+// SetCoverageGapMonitor enables the periodic coverage gap scan
+func (h *JobHandlers) SetCoverageGapMonitor(monitor *alerting.CoverageGapMonitor) {
+	h.gapMonitor = monitor
+}
+
+// SetSecretProvider enables resolving the Amion credentials referenced by scrape jobs
+func (h *JobHandlers) SetSecretProvider(provider secrets.Provider) {
+	h.secrets = provider
+}
+
+// SetAmionScraper enables scraping every configured Amion division of a
+// hospital; hospitals without divisions keep using their single login
+func (h *JobHandlers) SetAmionScraper(scraper *amion.Scraper) {
+	h.amionScraper = scraper
+}
+
+// SetEventPruner enables the periodic event prune. Events the webhook
+// dispatcher has not reached yet are kept when cursors is set.
+func (h *JobHandlers) SetEventPruner(pruner event.Pruner, cursors repository.EventCursorRepository) {
+	h.eventPruner = pruner
+	h.eventCursors = cursors
+}
+
 // RegisterHandlers registers all job handlers with the Asynq mux
 func (h *JobHandlers) RegisterHandlers(mux *asynq.ServeMux) {
 	mux.HandleFunc(TypeODSImport, h.HandleODSImport)
 	mux.HandleFunc(TypeAmionScrape, h.HandleAmionScrape)
 	mux.HandleFunc(TypeCoverageCalc, h.HandleCoverageCalculation)
+	mux.HandleFunc(TypeWebhookDeliver, h.HandleWebhookDelivery)
+	mux.HandleFunc(TypeVersionNotify, h.HandleVersionNotifications)
+	mux.HandleFunc(TypeDigestFlush, h.HandleDigestFlush)
+	mux.HandleFunc(TypeCoverageGapScan, h.HandleCoverageGapScan)
+	mux.HandleFunc(TypeEventPrune, h.HandleEventPrune)
 }
 
 // HandleODSImport handles ODS import jobs
-func (h *JobHandlers) HandleODSImport(ctx context.Context, t *asynq.Task) error {
+func (h *JobHandlers) HandleODSImport(ctx context.Context, t *asynq.Task) (err error) {
 	var payload ODSImportPayload
 
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
@@ -51,8 +119,14 @@ func (h *JobHandlers) HandleODSImport(ctx context.Context, t *asynq.Task) error
 
 	log.Printf("Executing ODS import job: hospital=%s, filename=%s", payload.HospitalID, payload.Filename)
 
+	h.publishJobState(ctx, TypeODSImport, payload.HospitalID, nil)
+	defer func() {
+		h.publishJobState(ctx, TypeODSImport, payload.HospitalID, &err)
+		h.publishImportFailure(ctx, TypeODSImport, payload.HospitalID, payload.VersionID, err)
+	}()
+
 	// Get the schedule version
-	_, err := h.versionService.GetVersion(ctx, payload.VersionID)
+	_, err = h.versionService.GetVersion(ctx, payload.VersionID)
 	if err != nil {
 		log.Printf("Failed to get schedule version: %v", err)
 		return fmt.Errorf("schedule version not found: %w", err)
@@ -70,7 +144,7 @@ func (h *JobHandlers) HandleODSImport(ctx context.Context, t *asynq.Task) error
 }
 
 // HandleAmionScrape handles Amion scraping jobs
-func (h *JobHandlers) HandleAmionScrape(ctx context.Context, t *asynq.Task) error {
+func (h *JobHandlers) HandleAmionScrape(ctx context.Context, t *asynq.Task) (err error) {
 	var payload AmionScrapePayload
 
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
@@ -79,6 +153,12 @@ func (h *JobHandlers) HandleAmionScrape(ctx context.Context, t *asynq.Task) erro
 
 	log.Printf("Executing Amion scrape job: hospital=%s, months=%d", payload.HospitalID, payload.MonthsBack)
 
+	h.publishJobState(ctx, TypeAmionScrape, payload.HospitalID, nil)
+	defer func() {
+		h.publishJobState(ctx, TypeAmionScrape, payload.HospitalID, &err)
+		h.publishImportFailure(ctx, TypeAmionScrape, payload.HospitalID, payload.VersionID, err)
+	}()
+
 	// Get the schedule version
 	version, err := h.versionService.GetVersion(ctx, payload.VersionID)
 	if err != nil {
@@ -86,11 +166,45 @@ func (h *JobHandlers) HandleAmionScrape(ctx context.Context, t *asynq.Task) erro
 		return fmt.Errorf("schedule version not found: %w", err)
 	}
 
+	// Hospitals with several Amion divisions are scraped division by division
+	if h.amionScraper != nil {
+		batch, result, serr := h.amionScraper.Scrape(ctx, version, payload.CreatorID)
+		if serr == nil {
+			if batch.State == entity.BatchStateFailed {
+				log.Printf("Amion division scrape produced no valid data: %s", *batch.ErrorMessage)
+				return fmt.Errorf("amion scrape failed: %s", *batch.ErrorMessage)
+			}
+			log.Printf("Amion division scrape completed: hospital=%s, divisions=%d, records=%d, messages=%d",
+				payload.HospitalID, len(batch.DivisionStats), batch.RowCount, len(result.Messages))
+			return nil
+		}
+		if !errors.Is(serr, amion.ErrNoDivisions) {
+			log.Printf("Amion division scrape failed: %v", serr)
+			return fmt.Errorf("amion scrape error: %w", serr)
+		}
+	}
+
+	if h.amionImporter == nil {
+		return fmt.Errorf("hospital %s has no amion divisions and no single-login importer is configured: %w", payload.HospitalID, asynq.SkipRetry)
+	}
+
+	// Resolve the Amion login; only the secret's name is ever logged
+	if h.secrets == nil {
+		return fmt.Errorf("no secret provider configured for amion credentials %q: %w", payload.CredentialsRef, asynq.SkipRetry)
+	}
+	creds, err := secrets.LoadCredentials(ctx, h.secrets, payload.CredentialsRef)
+	if err != nil {
+		log.Printf("Failed to load amion credentials %q: %v", payload.CredentialsRef, err)
+		if errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("amion credentials %q not found: %w", payload.CredentialsRef, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to load amion credentials %q: %w", payload.CredentialsRef, err)
+	}
+
 	// Configure Amion scraper
-	// NOTE: Password would come from Vault in production
 	config := service.AmionScraperConfig{
-		Username:          payload.Username,
-		Password:          "", // TODO: Load from Vault
+		Username:          creds.Username,
+		Password:          creds.Password,
 		MonthsToScrape:    payload.MonthsBack,
 		ConcurrentWorkers: 5, // From Spike 1: optimal concurrent scrapers
 	}
@@ -114,7 +228,7 @@ func (h *JobHandlers) HandleAmionScrape(ctx context.Context, t *asynq.Task) erro
 }
 
 // HandleCoverageCalculation handles coverage calculation jobs
-func (h *JobHandlers) HandleCoverageCalculation(ctx context.Context, t *asynq.Task) error {
+func (h *JobHandlers) HandleCoverageCalculation(ctx context.Context, t *asynq.Task) (err error) {
 	var payload CoverageCalcPayload
 
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
@@ -124,8 +238,17 @@ func (h *JobHandlers) HandleCoverageCalculation(ctx context.Context, t *asynq.Ta
 	log.Printf("Executing coverage calculation job: version=%s, period=%s to %s",
 		payload.ScheduleVersionID, payload.StartDate, payload.EndDate)
 
+	// Coverage payloads don't carry the hospital, so resolve it from the version
+	hospitalID := uuid.Nil
+	if version, verr := h.versionService.GetVersion(ctx, payload.ScheduleVersionID); verr == nil && version != nil {
+		hospitalID = version.HospitalID
+	}
+
+	h.publishJobState(ctx, TypeCoverageCalc, hospitalID, nil)
+	defer func() { h.publishJobState(ctx, TypeCoverageCalc, hospitalID, &err) }()
+
 	// Calculate coverage
-	_, err := h.coverageCalc.CalculateCoverageForSchedule(
+	calc, err := h.coverageCalc.CalculateCoverageForSchedule(
 		ctx,
 		payload.ScheduleVersionID,
 		payload.StartDate,
@@ -140,5 +263,244 @@ func (h *JobHandlers) HandleCoverageCalculation(ctx context.Context, t *asynq.Ta
 	log.Printf("Coverage calculation completed: version=%s",
 		payload.ScheduleVersionID)
 
+	if calc != nil {
+		if calc.HospitalID == uuid.Nil {
+			calc.HospitalID = hospitalID
+		}
+		h.publish(ctx, event.New(event.TypeCoverageRecalculated, calc.HospitalID, map[string]interface{}{
+			"schedule_version_id": payload.ScheduleVersionID.String(),
+			"period_start":        calc.CalculationPeriodStartDate,
+			"period_end":          calc.CalculationPeriodEndDate,
+			"summary":             calc.CoverageSummary,
+		}))
+
+		previous, err := h.recordCoverage(ctx, calc)
+		if err != nil {
+			return err
+		}
+		avg, ok := averageCoverage(calc)
+		if before, hadBefore := averageCoverage(previous); ok && hadBefore && avg < before {
+			h.publish(ctx, event.New(event.TypeCoverageDropped, calc.HospitalID, map[string]interface{}{
+				"schedule_version_id":       payload.ScheduleVersionID.String(),
+				"average_coverage":          avg,
+				"previous_average_coverage": before,
+				"summary":                   calc.CoverageSummary,
+			}))
+		}
+	}
+
 	return nil
 }
+
+// recordCoverage stores calc and returns the version's previous calculation
+// for the same period, or nil if there is none or calculations aren't kept
+func (h *JobHandlers) recordCoverage(ctx context.Context, calc *entity.CoverageCalculation) (*entity.CoverageCalculation, error) {
+	if h.coverageRepo == nil {
+		return nil, nil
+	}
+
+	earlier, err := h.coverageRepo.GetByScheduleVersion(ctx, calc.ScheduleVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previous coverage calculations: %w", err)
+	}
+	var previous *entity.CoverageCalculation
+	for _, c := range earlier {
+		if c.CalculationPeriodStartDate.Equal(calc.CalculationPeriodStartDate) &&
+			c.CalculationPeriodEndDate.Equal(calc.CalculationPeriodEndDate) &&
+			(previous == nil || c.CalculatedAt.After(previous.CalculatedAt)) {
+			previous = c
+		}
+	}
+
+	if err := h.coverageRepo.Create(ctx, calc); err != nil {
+		return nil, fmt.Errorf("failed to store coverage calculation: %w", err)
+	}
+	return previous, nil
+}
+
+// averageCoverage reads the summary's average coverage
+func averageCoverage(calc *entity.CoverageCalculation) (float64, bool) {
+	if calc == nil {
+		return 0, false
+	}
+	avg, ok := calc.CoverageSummary["average_coverage"].(float64)
+	return avg, ok
+}
+
+// publishJobState publishes a job state transition.
+// A nil result pointer means the job has started; otherwise *result decides COMPLETED or FAILED.
+func (h *JobHandlers) publishJobState(ctx context.Context, jobType string, hospitalID uuid.UUID, result *error) {
+	state := event.JobStateRunning
+	data := map[string]interface{}{
+		"job_type": jobType,
+	}
+	if id, ok := asynq.GetTaskID(ctx); ok {
+		data["job_id"] = id
+	}
+	if result != nil {
+		state = event.JobStateCompleted
+		if *result != nil {
+			state = event.JobStateFailed
+			data["error"] = (*result).Error()
+		}
+	}
+	data["state"] = string(state)
+
+	h.publish(ctx, event.New(event.TypeJobStateChanged, hospitalID, data))
+}
+
+// publishImportFailure publishes TypeImportFailed once an import has failed for good
+// (retries exhausted or not retryable), so webhook receivers aren't paged per attempt
+func (h *JobHandlers) publishImportFailure(ctx context.Context, jobType string, hospitalID uuid.UUID, versionID entity.ScheduleVersionID, err error) {
+	if err == nil || !isFinalAttempt(ctx, err) {
+		return
+	}
+
+	data := map[string]interface{}{
+		"job_type":   jobType,
+		"version_id": versionID.String(),
+		"error":      err.Error(),
+	}
+	if id, ok := asynq.GetTaskID(ctx); ok {
+		data["job_id"] = id
+	}
+
+	h.publish(ctx, event.New(event.TypeImportFailed, hospitalID, data))
+}
+
+// isFinalAttempt reports whether asynq will not retry after this error
+func isFinalAttempt(ctx context.Context, err error) bool {
+	if errors.Is(err, asynq.SkipRetry) {
+		return true
+	}
+	retried, ok1 := asynq.GetRetryCount(ctx)
+	maxRetry, ok2 := asynq.GetMaxRetry(ctx)
+	if !ok1 || !ok2 {
+		return true // Not running under asynq (e.g. invoked directly)
+	}
+	return retried >= maxRetry
+}
+
+// HandleWebhookDelivery handles webhook delivery jobs
+func (h *JobHandlers) HandleWebhookDelivery(ctx context.Context, t *asynq.Task) error {
+	var payload WebhookDeliveryPayload
+
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	if h.webhooks == nil {
+		return fmt.Errorf("webhook service not configured: %w", asynq.SkipRetry)
+	}
+
+	if err := h.webhooks.Deliver(ctx, payload.DeliveryID, isFinalAttempt(ctx, nil)); err != nil {
+		log.Printf("Webhook delivery %s failed: %v", payload.DeliveryID, err)
+		return fmt.Errorf("webhook delivery failed: %w", err)
+	}
+
+	return nil
+}
+
+// HandleVersionNotifications handles personal notification jobs after a promotion
+func (h *JobHandlers) HandleVersionNotifications(ctx context.Context, t *asynq.Task) error {
+	var payload VersionNotifyPayload
+
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	if h.notifier == nil {
+		return fmt.Errorf("notifier not configured: %w", asynq.SkipRetry)
+	}
+
+	// People already notified are recorded, so a retry only contacts the ones that failed
+	result, err := h.notifier.NotifyVersionChange(ctx, payload.HospitalID, payload.OutgoingVersionID, payload.IncomingVersionID)
+	if result != nil {
+		log.Printf("Schedule change notifications: hospital=%s, changed=%d, sent=%d, queued=%d, failed=%d, repeated=%d",
+			payload.HospitalID, result.PeopleChanged, result.Sent, result.Queued, result.Failed, result.Repeated)
+	}
+	if err != nil {
+		log.Printf("Schedule change notifications failed: %v", err)
+		return fmt.Errorf("schedule change notifications failed: %w", err)
+	}
+
+	return nil
+}
+
+// HandleDigestFlush sends daily digests that are due
+func (h *JobHandlers) HandleDigestFlush(ctx context.Context, t *asynq.Task) error {
+	if h.notifier == nil {
+		return nil
+	}
+
+	result, err := h.notifier.FlushDigests(ctx)
+	if err != nil {
+		return fmt.Errorf("digest flush failed: %w", err)
+	}
+
+	if result.Sent > 0 || result.Failed > 0 {
+		log.Printf("Digest flush: sent=%d, failed=%d", result.Sent, result.Failed)
+	}
+
+	return nil
+}
+
+// HandleCoverageGapScan raises, escalates and resolves coverage gap alerts
+func (h *JobHandlers) HandleCoverageGapScan(ctx context.Context, t *asynq.Task) error {
+	if h.gapMonitor == nil {
+		return nil
+	}
+
+	result, err := h.gapMonitor.Scan(ctx)
+	if result != nil {
+		log.Printf("Coverage gap scan: hospitals=%d, gaps=%d, raised=%d, escalated=%d, resolved=%d",
+			result.Hospitals, result.Gaps, result.Raised, result.Escalated, result.Resolved)
+	}
+	if err != nil {
+		return fmt.Errorf("coverage gap scan failed: %w", err)
+	}
+
+	return nil
+}
+
+// EventRetention is how long stored events stay available to SSE resumes
+const EventRetention = 7 * 24 * time.Hour
+
+// HandleEventPrune deletes stored events older than EventRetention that the
+// webhook dispatcher has already handled
+func (h *JobHandlers) HandleEventPrune(ctx context.Context, t *asynq.Task) error {
+	if h.eventPruner == nil {
+		return nil
+	}
+
+	// A dispatcher that has never run starts from new events, so it holds nothing back
+	var throughID int64
+	if h.eventCursors != nil {
+		cursor, err := h.eventCursors.Get(ctx, WebhookDispatcherCursor)
+		if err != nil {
+			return fmt.Errorf("failed to load webhook dispatch cursor: %w", err)
+		}
+		throughID = cursor
+	}
+
+	deleted, err := h.eventPruner.Prune(ctx, time.Now().Add(-EventRetention), throughID)
+	if err != nil {
+		return fmt.Errorf("event prune failed: %w", err)
+	}
+
+	if deleted > 0 {
+		log.Printf("Event prune: deleted=%d", deleted)
+	}
+
+	return nil
+}
+
+// publish sends an event if a publisher is configured; failures never fail the job
+func (h *JobHandlers) publish(ctx context.Context, evt *event.Event) {
+	if h.events == nil {
+		return
+	}
+	if err := h.events.Publish(ctx, evt); err != nil {
+		log.Printf("Failed to publish %s event: %v", evt.Type, err)
+	}
+}
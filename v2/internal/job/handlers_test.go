@@ -0,0 +1,136 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCoverageCalculator returns the next average coverage on every calculation
+type stubCoverageCalculator struct {
+	service.CoverageCalculator
+	hospitalID uuid.UUID
+	averages   []float64
+}
+
+func (s *stubCoverageCalculator) CalculateCoverageForSchedule(ctx context.Context, versionID entity.ScheduleVersionID, start, end time.Time) (*entity.CoverageCalculation, error) {
+	avg := s.averages[0]
+	s.averages = s.averages[1:]
+	now := time.Now()
+	return &entity.CoverageCalculation{
+		ID:                         uuid.New(),
+		ScheduleVersionID:          uuid.UUID(versionID),
+		HospitalID:                 s.hospitalID,
+		CalculationPeriodStartDate: start,
+		CalculationPeriodEndDate:   end,
+		CoverageSummary:            map[string]interface{}{"average_coverage": avg},
+		CalculatedAt:               now,
+	}, nil
+}
+
+type memoryCoverageRepo struct {
+	repository.CoverageCalculationRepository
+	calculations []*entity.CoverageCalculation
+}
+
+func (m *memoryCoverageRepo) Create(ctx context.Context, calc *entity.CoverageCalculation) error {
+	m.calculations = append(m.calculations, calc)
+	return nil
+}
+
+func (m *memoryCoverageRepo) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.CoverageCalculation, error) {
+	var result []*entity.CoverageCalculation
+	for _, c := range m.calculations {
+		if c.ScheduleVersionID == versionID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+type recordingPublisher struct {
+	events []*event.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, evt *event.Event) error {
+	p.events = append(p.events, evt)
+	return nil
+}
+
+func (p *recordingPublisher) ofType(t event.Type) []*event.Event {
+	var matching []*event.Event
+	for _, evt := range p.events {
+		if evt.Type == t {
+			matching = append(matching, evt)
+		}
+	}
+	return matching
+}
+
+func TestHandleCoverageCalculation_PublishesDropOnDecrease(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	versions := mocks.NewMockScheduleVersionRepository()
+	version := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: hospitalID, Status: entity.VersionStatusProduction}
+	require.NoError(t, versions.Create(ctx, version))
+
+	calculator := &stubCoverageCalculator{hospitalID: hospitalID, averages: []float64{0.8, 0.9, 0.7}}
+	publisher := &recordingPublisher{}
+	h := NewJobHandlers(nil, nil, calculator, service.NewScheduleVersionService(versions))
+	h.SetEventPublisher(publisher)
+	h.SetCoverageCalculationRepository(&memoryCoverageRepo{})
+
+	payload, err := json.Marshal(CoverageCalcPayload{
+		ScheduleVersionID: version.ID,
+		StartDate:         time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:           time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	run := func() {
+		require.NoError(t, h.HandleCoverageCalculation(ctx, asynq.NewTask(TypeCoverageCalc, payload)))
+	}
+
+	run()
+	assert.Empty(t, publisher.ofType(event.TypeCoverageDropped), "nothing to compare the first calculation with")
+	run()
+	assert.Empty(t, publisher.ofType(event.TypeCoverageDropped), "coverage went up")
+	run()
+	dropped := publisher.ofType(event.TypeCoverageDropped)
+	require.Len(t, dropped, 1)
+	assert.Equal(t, 0.7, dropped[0].Data["average_coverage"])
+	assert.Equal(t, 0.9, dropped[0].Data["previous_average_coverage"])
+	assert.Len(t, publisher.ofType(event.TypeCoverageRecalculated), 3)
+}
+
+type recordingPruner struct {
+	before    time.Time
+	throughID int64
+}
+
+func (p *recordingPruner) Prune(ctx context.Context, before time.Time, throughID int64) (int64, error) {
+	p.before = before
+	p.throughID = throughID
+	return 3, nil
+}
+
+func TestHandleEventPrune_KeepsEventsTheDispatcherHasNotReached(t *testing.T) {
+	pruner := &recordingPruner{}
+	handlers := NewJobHandlers(nil, nil, nil, nil)
+	handlers.SetEventPruner(pruner, &recordingCursors{})
+
+	require.NoError(t, handlers.HandleEventPrune(context.Background(), asynq.NewTask(TypeEventPrune, nil)))
+
+	assert.Equal(t, int64(4), pruner.throughID, "pruning stops at the dispatcher's cursor")
+	assert.WithinDuration(t, time.Now().Add(-EventRetention), pruner.before, time.Minute)
+}
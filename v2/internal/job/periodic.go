@@ -0,0 +1,31 @@
+package job
+
+import (
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+// PeriodicTask is a job the worker runs on a schedule
+type PeriodicTask struct {
+	Cronspec string
+	Type     string
+}
+
+// PeriodicTasks lists the recurring jobs
+var PeriodicTasks = []PeriodicTask{
+	{Cronspec: "0 * * * *", Type: TypeDigestFlush},        // Digest hours are per person, so check hourly
+	{Cronspec: "*/15 * * * *", Type: TypeCoverageGapScan}, // Catch gaps early enough to escalate
+	{Cronspec: "30 3 * * *", Type: TypeEventPrune},        // Outside the busy hours
+}
+
+// RegisterPeriodicTasks registers all recurring jobs with an asynq scheduler.
+// Run a single asynq.Scheduler per deployment so each job is enqueued once.
+func RegisterPeriodicTasks(scheduler *asynq.Scheduler) error {
+	for _, pt := range PeriodicTasks {
+		if _, err := scheduler.Register(pt.Cronspec, asynq.NewTask(pt.Type, nil), asynq.MaxRetry(0)); err != nil {
+			return fmt.Errorf("failed to register periodic task %s: %w", pt.Type, err)
+		}
+	}
+	return nil
+}
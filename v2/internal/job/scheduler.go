@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/schedcu/v2/internal/entity"
 )
@@ -30,11 +31,19 @@ func NewJobScheduler(redisAddr string) (*JobScheduler, error) {
 
 // Job types
 const (
-	TypeODSImport    = "ods:import"
-	TypeAmionScrape  = "amion:scrape"
-	TypeCoverageCalc = "coverage:calculate"
+	TypeODSImport       = "ods:import"
+	TypeAmionScrape     = "amion:scrape"
+	TypeCoverageCalc    = "coverage:calculate"
+	TypeWebhookDeliver  = "webhook:deliver"
+	TypeVersionNotify   = "notification:version_changed"
+	TypeDigestFlush     = "notification:digest"
+	TypeCoverageGapScan = "coverage:gap_scan"
+	TypeEventPrune      = "event:prune"
 )
 
+// webhookMaxRetry gives a receiver roughly a day to recover (see RetryDelay)
+const webhookMaxRetry = 10
+
 // ODSImportPayload represents the payload for ODS import job
 type ODSImportPayload struct {
 	HospitalID entity.HospitalID `json:"hospital_id"`
@@ -74,13 +83,15 @@ func (s *JobScheduler) EnqueueODSImport(
 	return info, nil
 }
 
-// AmionScrapePayload represents the payload for Amion scrape job
+// AmionScrapePayload represents the payload for Amion scrape job.
+// Credentials are referenced by secret name and resolved by the worker, so they
+// are never stored in Redis.
 type AmionScrapePayload struct {
-	HospitalID  entity.HospitalID `json:"hospital_id"`
-	VersionID   entity.ScheduleVersionID `json:"version_id"`
-	MonthsBack  int `json:"months_back"`
-	Username    string `json:"username"`
-	CreatorID   entity.UserID `json:"creator_id"`
+	HospitalID     entity.HospitalID        `json:"hospital_id"`
+	VersionID      entity.ScheduleVersionID `json:"version_id"`
+	MonthsBack     int                      `json:"months_back"`
+	CredentialsRef string                   `json:"credentials_ref"`
+	CreatorID      entity.UserID            `json:"creator_id"`
 }
 
 // EnqueueAmionScrape enqueues an Amion scraping job
@@ -89,16 +100,16 @@ func (s *JobScheduler) EnqueueAmionScrape(
 	hospitalID entity.HospitalID,
 	versionID entity.ScheduleVersionID,
 	monthsBack int,
-	username string,
+	credentialsRef string,
 	creatorID entity.UserID,
 ) (*asynq.TaskInfo, error) {
 
 	payload := AmionScrapePayload{
-		HospitalID: hospitalID,
-		VersionID:  versionID,
-		MonthsBack: monthsBack,
-		Username:   username,
-		CreatorID:  creatorID,
+		HospitalID:     hospitalID,
+		VersionID:      versionID,
+		MonthsBack:     monthsBack,
+		CredentialsRef: credentialsRef,
+		CreatorID:      creatorID,
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -171,6 +182,86 @@ func (s *JobScheduler) EnqueueCoverageCalculation(
 	return info, nil
 }
 
+// WebhookDeliveryPayload represents the payload for a webhook delivery job
+type WebhookDeliveryPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// EnqueueWebhookDelivery enqueues a webhook delivery attempt (satisfies service.WebhookQueue)
+func (s *JobScheduler) EnqueueWebhookDelivery(ctx context.Context, deliveryID uuid.UUID) error {
+	payloadBytes, err := json.Marshal(WebhookDeliveryPayload{DeliveryID: deliveryID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	task := asynq.NewTask(TypeWebhookDeliver, payloadBytes)
+
+	_, err = s.client.EnqueueContext(
+		ctx,
+		task,
+		asynq.MaxRetry(webhookMaxRetry),
+		asynq.Timeout(30*time.Second),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook delivery job: %w", err)
+	}
+
+	return nil
+}
+
+// VersionNotifyPayload represents the payload for a schedule change notification job
+type VersionNotifyPayload struct {
+	HospitalID        entity.HospitalID        `json:"hospital_id"`
+	OutgoingVersionID entity.ScheduleVersionID `json:"outgoing_version_id"` // uuid.Nil on first promotion
+	IncomingVersionID entity.ScheduleVersionID `json:"incoming_version_id"`
+}
+
+// EnqueueVersionNotifications enqueues personal notifications for a promotion
+func (s *JobScheduler) EnqueueVersionNotifications(
+	ctx context.Context,
+	hospitalID entity.HospitalID,
+	outgoingVersionID, incomingVersionID entity.ScheduleVersionID,
+) (*asynq.TaskInfo, error) {
+
+	payload := VersionNotifyPayload{
+		HospitalID:        hospitalID,
+		OutgoingVersionID: outgoingVersionID,
+		IncomingVersionID: incomingVersionID,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	task := asynq.NewTask(TypeVersionNotify, payloadBytes)
+
+	info, err := s.client.EnqueueContext(ctx, task, asynq.MaxRetry(3), asynq.Timeout(5*time.Minute))
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue version notification job: %w", err)
+	}
+
+	return info, nil
+}
+
+// RetryDelay is the asynq.Config.RetryDelayFunc for workers.
+// Webhook deliveries back off exponentially (30s, 1m, 2m, ... capped at 6h);
+// all other jobs use asynq's default.
+func RetryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if task.Type() != TypeWebhookDeliver {
+		return asynq.DefaultRetryDelayFunc(n, err, task)
+	}
+
+	delay := 30 * time.Second
+	for i := 0; i < n && delay < 6*time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}
+
 // Close closes the job scheduler and releases resources
 func (s *JobScheduler) Close() error {
 	return s.client.Close()
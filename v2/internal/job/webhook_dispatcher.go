@@ -0,0 +1,55 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service"
+)
+
+// WebhookDispatcherCursor names the dispatcher's position in event_cursors
+const WebhookDispatcherCursor = "webhook_dispatcher"
+
+// RunWebhookDispatcher turns bus events into queued webhook deliveries until ctx is cancelled.
+// Run it in exactly one process (the worker) so each event is dispatched once.
+// The last dispatched event is saved in cursors, so after a restart the
+// dispatcher replays whatever was published while it was down.
+func RunWebhookDispatcher(ctx context.Context, bus event.Subscriber, webhooks service.WebhookService, cursors repository.EventCursorRepository) error {
+	lastID, err := cursors.Get(ctx, WebhookDispatcherCursor)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook dispatch cursor: %w", err)
+	}
+
+	for {
+		events, err := bus.Subscribe(ctx, uuid.Nil, lastID)
+		if err != nil {
+			return err
+		}
+
+		for evt := range events {
+			if _, err := webhooks.Dispatch(ctx, evt); err != nil {
+				log.Printf("Failed to dispatch webhooks for event %d (%s): %v", evt.ID, evt.Type, err)
+			}
+			// Late commits arrive below the cursor; it only ever moves forward
+			if evt.ID <= lastID {
+				continue
+			}
+			lastID = evt.ID
+			if err := cursors.Save(ctx, WebhookDispatcherCursor, lastID); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to save webhook dispatch cursor at event %d: %v", lastID, err)
+			}
+		}
+
+		// Channel closed: stop on cancellation, otherwise resume after the last event
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+}
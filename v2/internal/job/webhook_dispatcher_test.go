@@ -0,0 +1,85 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedBus replays a fixed list of events once, then blocks until cancelled
+type scriptedBus struct {
+	events []*event.Event
+}
+
+func (b *scriptedBus) Subscribe(ctx context.Context, hospitalID uuid.UUID, afterID int64) (<-chan *event.Event, error) {
+	ch := make(chan *event.Event)
+	events := b.events
+	b.events = nil
+	go func() {
+		defer close(ch)
+		for _, evt := range events {
+			ch <- evt
+		}
+		<-ctx.Done()
+	}()
+	return ch, nil
+}
+
+type recordingWebhooks struct {
+	service.WebhookService
+	mu         sync.Mutex
+	dispatched []int64
+}
+
+func (w *recordingWebhooks) Dispatch(ctx context.Context, evt *event.Event) ([]*entity.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dispatched = append(w.dispatched, evt.ID)
+	return nil, nil
+}
+
+type recordingCursors struct {
+	mu    sync.Mutex
+	saved []int64
+}
+
+func (c *recordingCursors) Get(ctx context.Context, consumer string) (int64, error) {
+	return 4, nil
+}
+
+func (c *recordingCursors) Save(ctx context.Context, consumer string, lastEventID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saved = append(c.saved, lastEventID)
+	return nil
+}
+
+func TestRunWebhookDispatcher_CursorOnlyMovesForward(t *testing.T) {
+	// Event 5 committed late, after 6 had been dispatched
+	bus := &scriptedBus{events: []*event.Event{{ID: 6}, {ID: 5}, {ID: 7}}}
+	webhooks := &recordingWebhooks{}
+	cursors := &recordingCursors{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- RunWebhookDispatcher(ctx, bus, webhooks, cursors) }()
+
+	require.Eventually(t, func() bool {
+		webhooks.mu.Lock()
+		defer webhooks.mu.Unlock()
+		return len(webhooks.dispatched) == 3
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []int64{6, 5, 7}, webhooks.dispatched, "late events are still dispatched")
+	assert.Equal(t, []int64{6, 7}, cursors.saved)
+}
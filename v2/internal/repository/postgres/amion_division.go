@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// AmionDivisionRepository implements repository.AmionDivisionRepository for PostgreSQL
+type AmionDivisionRepository struct {
+	db *sql.DB
+}
+
+// NewAmionDivisionRepository creates a new AmionDivisionRepository
+func NewAmionDivisionRepository(db *sql.DB) *AmionDivisionRepository {
+	return &AmionDivisionRepository{db: db}
+}
+
+// Create creates a new Amion division
+func (r *AmionDivisionRepository) Create(ctx context.Context, division *entity.AmionDivision) error {
+	if division.ID == uuid.Nil {
+		division.ID = uuid.New()
+	}
+
+	mappingJSON, err := json.Marshal(shiftNameMapping(division))
+	if err != nil {
+		return fmt.Errorf("failed to marshal shift name mapping: %w", err)
+	}
+
+	query := `
+		INSERT INTO amion_divisions (
+			id, hospital_id, name, base_url, url_template, page_view, credentials_ref,
+			selector_profile, shift_name_mapping, active, created_at, created_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		division.ID,
+		division.HospitalID,
+		division.Name,
+		division.BaseURL,
+		division.URLTemplate,
+		division.PageView,
+		division.CredentialsRef,
+		division.SelectorProfile,
+		mappingJSON,
+		division.Active,
+		division.CreatedAt,
+		division.CreatedBy,
+		division.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create amion division: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an Amion division by ID
+func (r *AmionDivisionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AmionDivision, error) {
+	query := `
+		SELECT id, hospital_id, name, base_url, url_template, page_view, credentials_ref,
+		       selector_profile, shift_name_mapping, active, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM amion_divisions
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	division, err := scanAmionDivision(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "AmionDivision",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get amion division: %w", err)
+	}
+
+	return division, nil
+}
+
+// GetByHospital retrieves all Amion divisions of a hospital, active or not
+func (r *AmionDivisionRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.AmionDivision, error) {
+	query := `
+		SELECT id, hospital_id, name, base_url, url_template, page_view, credentials_ref,
+		       selector_profile, shift_name_mapping, active, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM amion_divisions
+		WHERE hospital_id = $1 AND deleted_at IS NULL
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, hospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query amion divisions: %w", err)
+	}
+	defer rows.Close()
+
+	var divisions []*entity.AmionDivision
+	for rows.Next() {
+		division, err := scanAmionDivision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan amion division: %w", err)
+		}
+		divisions = append(divisions, division)
+	}
+
+	return divisions, rows.Err()
+}
+
+// Update updates an Amion division
+func (r *AmionDivisionRepository) Update(ctx context.Context, division *entity.AmionDivision) error {
+	mappingJSON, err := json.Marshal(shiftNameMapping(division))
+	if err != nil {
+		return fmt.Errorf("failed to marshal shift name mapping: %w", err)
+	}
+
+	query := `
+		UPDATE amion_divisions
+		SET name = $2, base_url = $3, url_template = $4, page_view = $5, credentials_ref = $6,
+		    selector_profile = $7, shift_name_mapping = $8, active = $9, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		division.ID,
+		division.Name,
+		division.BaseURL,
+		division.URLTemplate,
+		division.PageView,
+		division.CredentialsRef,
+		division.SelectorProfile,
+		mappingJSON,
+		division.Active,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update amion division: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "AmionDivision",
+			ResourceID:   division.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+// Delete soft-deletes an Amion division
+func (r *AmionDivisionRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	query := `
+		UPDATE amion_divisions
+		SET deleted_at = NOW(), deleted_by = $2, active = FALSE
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deleterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete amion division: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "AmionDivision",
+			ResourceID:   id.String(),
+		}
+	}
+
+	return nil
+}
+
+// shiftNameMapping returns the division's mapping, never nil, so the column stays a JSON object
+func shiftNameMapping(division *entity.AmionDivision) map[string]string {
+	if division.ShiftNameMapping == nil {
+		return map[string]string{}
+	}
+	return division.ShiftNameMapping
+}
+
+func scanAmionDivision(row rowScanner) (*entity.AmionDivision, error) {
+	division := &entity.AmionDivision{}
+	var mappingJSON []byte
+	err := row.Scan(
+		&division.ID,
+		&division.HospitalID,
+		&division.Name,
+		&division.BaseURL,
+		&division.URLTemplate,
+		&division.PageView,
+		&division.CredentialsRef,
+		&division.SelectorProfile,
+		&mappingJSON,
+		&division.Active,
+		&division.CreatedAt,
+		&division.CreatedBy,
+		&division.UpdatedAt,
+		&division.DeletedAt,
+		&division.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mappingJSON, &division.ShiftNameMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shift name mapping: %w", err)
+	}
+	return division, nil
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// AmionShiftMappingRepository implements repository.AmionShiftMappingRepository for PostgreSQL
+type AmionShiftMappingRepository struct {
+	db *sql.DB
+}
+
+// NewAmionShiftMappingRepository creates a new AmionShiftMappingRepository
+func NewAmionShiftMappingRepository(db *sql.DB) *AmionShiftMappingRepository {
+	return &AmionShiftMappingRepository{db: db}
+}
+
+// Create creates a new Amion shift mapping
+func (r *AmionShiftMappingRepository) Create(ctx context.Context, mapping *entity.AmionShiftMapping) error {
+	if mapping.ID == uuid.Nil {
+		mapping.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO amion_shift_mappings (
+			id, hospital_id, amion_label, location, start_time, end_time,
+			shift_type, study_type, specialty_constraint, created_at, created_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		mapping.ID,
+		mapping.HospitalID,
+		mapping.AmionLabel,
+		mapping.Location,
+		mapping.StartTime,
+		mapping.EndTime,
+		mapping.ShiftType,
+		mapping.StudyType,
+		mapping.SpecialtyConstraint,
+		mapping.CreatedAt,
+		mapping.CreatedBy,
+		mapping.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create amion shift mapping: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an Amion shift mapping by ID
+func (r *AmionShiftMappingRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AmionShiftMapping, error) {
+	query := `
+		SELECT id, hospital_id, amion_label, location, start_time, end_time, shift_type, study_type,
+		       specialty_constraint, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM amion_shift_mappings
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	mapping, err := scanAmionShiftMapping(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "AmionShiftMapping",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get amion shift mapping: %w", err)
+	}
+
+	return mapping, nil
+}
+
+// GetByHospital retrieves all Amion shift mappings of a hospital
+func (r *AmionShiftMappingRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.AmionShiftMapping, error) {
+	query := `
+		SELECT id, hospital_id, amion_label, location, start_time, end_time, shift_type, study_type,
+		       specialty_constraint, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM amion_shift_mappings
+		WHERE hospital_id = $1 AND deleted_at IS NULL
+		ORDER BY lower(amion_label), location, start_time, end_time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, hospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query amion shift mappings: %w", err)
+	}
+	defer rows.Close()
+
+	var mappings []*entity.AmionShiftMapping
+	for rows.Next() {
+		mapping, err := scanAmionShiftMapping(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan amion shift mapping: %w", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	return mappings, rows.Err()
+}
+
+// Update updates an Amion shift mapping
+func (r *AmionShiftMappingRepository) Update(ctx context.Context, mapping *entity.AmionShiftMapping) error {
+	query := `
+		UPDATE amion_shift_mappings
+		SET amion_label = $2, location = $3, start_time = $4, end_time = $5,
+		    shift_type = $6, study_type = $7, specialty_constraint = $8, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		mapping.ID,
+		mapping.AmionLabel,
+		mapping.Location,
+		mapping.StartTime,
+		mapping.EndTime,
+		mapping.ShiftType,
+		mapping.StudyType,
+		mapping.SpecialtyConstraint,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update amion shift mapping: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "AmionShiftMapping",
+			ResourceID:   mapping.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+// Delete soft-deletes an Amion shift mapping
+func (r *AmionShiftMappingRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	query := `
+		UPDATE amion_shift_mappings
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deleterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete amion shift mapping: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "AmionShiftMapping",
+			ResourceID:   id.String(),
+		}
+	}
+
+	return nil
+}
+
+func scanAmionShiftMapping(row rowScanner) (*entity.AmionShiftMapping, error) {
+	mapping := &entity.AmionShiftMapping{}
+	err := row.Scan(
+		&mapping.ID,
+		&mapping.HospitalID,
+		&mapping.AmionLabel,
+		&mapping.Location,
+		&mapping.StartTime,
+		&mapping.EndTime,
+		&mapping.ShiftType,
+		&mapping.StudyType,
+		&mapping.SpecialtyConstraint,
+		&mapping.CreatedAt,
+		&mapping.CreatedBy,
+		&mapping.UpdatedAt,
+		&mapping.DeletedAt,
+		&mapping.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// AmionSnapshotRepository implements repository.AmionSnapshotRepository for PostgreSQL
+type AmionSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewAmionSnapshotRepository creates a new AmionSnapshotRepository
+func NewAmionSnapshotRepository(db *sql.DB) *AmionSnapshotRepository {
+	return &AmionSnapshotRepository{db: db}
+}
+
+// Save stores a snapshot, replacing any earlier snapshot of the same URL in the batch
+func (r *AmionSnapshotRepository) Save(ctx context.Context, snapshot *entity.AmionPageSnapshot) error {
+	if snapshot.ID == uuid.Nil {
+		snapshot.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO amion_page_snapshots (
+			id, scrape_batch_id, hospital_id, division_id, url, month, window_from, window_to,
+			content_hash, encoding, size, content, fetched_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (scrape_batch_id, url) DO UPDATE
+		SET division_id = EXCLUDED.division_id, month = EXCLUDED.month, window_from = EXCLUDED.window_from,
+			window_to = EXCLUDED.window_to, content_hash = EXCLUDED.content_hash, encoding = EXCLUDED.encoding,
+			size = EXCLUDED.size, content = EXCLUDED.content, fetched_at = EXCLUDED.fetched_at
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		snapshot.ID,
+		snapshot.ScrapeBatchID,
+		snapshot.HospitalID,
+		snapshot.DivisionID,
+		snapshot.URL,
+		snapshot.Month,
+		snapshot.WindowFrom,
+		snapshot.WindowTo,
+		snapshot.ContentHash,
+		snapshot.Encoding,
+		snapshot.Size,
+		snapshot.Content,
+		snapshot.FetchedAt,
+		snapshot.CreatedAt,
+	).Scan(&snapshot.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to save amion page snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a snapshot including its content
+func (r *AmionSnapshotRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AmionPageSnapshot, error) {
+	query := `
+		SELECT id, scrape_batch_id, hospital_id, division_id, url, month, window_from, window_to, content_hash, encoding, size, content, fetched_at, created_at
+		FROM amion_page_snapshots
+		WHERE id = $1
+	`
+
+	snapshot := &entity.AmionPageSnapshot{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&snapshot.ID,
+		&snapshot.ScrapeBatchID,
+		&snapshot.HospitalID,
+		&snapshot.DivisionID,
+		&snapshot.URL,
+		&snapshot.Month,
+		&snapshot.WindowFrom,
+		&snapshot.WindowTo,
+		&snapshot.ContentHash,
+		&snapshot.Encoding,
+		&snapshot.Size,
+		&snapshot.Content,
+		&snapshot.FetchedAt,
+		&snapshot.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "AmionPageSnapshot",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get amion page snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// ListByBatch retrieves a batch's snapshots ordered by month and URL, without content
+func (r *AmionSnapshotRepository) ListByBatch(ctx context.Context, batchID uuid.UUID) ([]*entity.AmionPageSnapshot, error) {
+	query := `
+		SELECT id, scrape_batch_id, hospital_id, division_id, url, month, window_from, window_to, content_hash, encoding, size, fetched_at, created_at
+		FROM amion_page_snapshots
+		WHERE scrape_batch_id = $1
+		ORDER BY month, url
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query amion page snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*entity.AmionPageSnapshot
+	for rows.Next() {
+		snapshot := &entity.AmionPageSnapshot{}
+		err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.ScrapeBatchID,
+			&snapshot.HospitalID,
+			&snapshot.DivisionID,
+			&snapshot.URL,
+			&snapshot.Month,
+			&snapshot.WindowFrom,
+			&snapshot.WindowTo,
+			&snapshot.ContentHash,
+			&snapshot.Encoding,
+			&snapshot.Size,
+			&snapshot.FetchedAt,
+			&snapshot.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan amion page snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate amion page snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -35,10 +36,13 @@ func scanAssignment(scanner interface{ Scan(...interface{}) error }, assignment
 		&assignment.ScheduleDate,
 		&originalShiftType,
 		&source,
+		&assignment.ScrapeBatchID,
 		&assignment.CreatedAt,
 		&assignment.CreatedBy,
 		&assignment.DeletedAt,
 		&assignment.DeletedBy,
+		&assignment.UnpinnedAt,
+		&assignment.UnpinnedBy,
 	)
 
 	if err == nil {
@@ -60,8 +64,8 @@ func (r *AssignmentRepository) Create(ctx context.Context, assignment *entity.As
 	}
 
 	query := `
-		INSERT INTO assignments (id, person_id, shift_instance_id, schedule_date, original_shift_type, source, created_at, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO assignments (id, person_id, shift_instance_id, schedule_date, original_shift_type, source, scrape_batch_id, created_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -71,6 +75,7 @@ func (r *AssignmentRepository) Create(ctx context.Context, assignment *entity.As
 		assignment.ScheduleDate,
 		assignment.OriginalShiftType,
 		string(assignment.Source),
+		assignment.ScrapeBatchID,
 		assignment.CreatedAt,
 		assignment.CreatedBy,
 	)
@@ -87,8 +92,8 @@ func (r *AssignmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*enti
 	assignment := &entity.Assignment{}
 
 	query := `
-		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source,
-		       created_at, created_by, deleted_at, deleted_by
+		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source, scrape_batch_id,
+		       created_at, created_by, deleted_at, deleted_by, unpinned_at, unpinned_by
 		FROM assignments
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -111,8 +116,8 @@ func (r *AssignmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*enti
 // GetByShiftInstance retrieves all assignments for a shift instance
 func (r *AssignmentRepository) GetByShiftInstance(ctx context.Context, shiftInstanceID uuid.UUID) ([]*entity.Assignment, error) {
 	query := `
-		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source,
-		       created_at, created_by, deleted_at, deleted_by
+		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source, scrape_batch_id,
+		       created_at, created_by, deleted_at, deleted_by, unpinned_at, unpinned_by
 		FROM assignments
 		WHERE shift_instance_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at ASC
@@ -143,8 +148,8 @@ func (r *AssignmentRepository) GetByShiftInstance(ctx context.Context, shiftInst
 // GetByPerson retrieves all assignments for a person
 func (r *AssignmentRepository) GetByPerson(ctx context.Context, personID uuid.UUID) ([]*entity.Assignment, error) {
 	query := `
-		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source,
-		       created_at, created_by, deleted_at, deleted_by
+		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source, scrape_batch_id,
+		       created_at, created_by, deleted_at, deleted_by, unpinned_at, unpinned_by
 		FROM assignments
 		WHERE person_id = $1 AND deleted_at IS NULL
 		ORDER BY schedule_date DESC
@@ -175,8 +180,8 @@ func (r *AssignmentRepository) GetByPerson(ctx context.Context, personID uuid.UU
 // GetByPersonAndDateRange retrieves assignments for a person within a date range
 func (r *AssignmentRepository) GetByPersonAndDateRange(ctx context.Context, personID uuid.UUID, startDate, endDate entity.Date) ([]*entity.Assignment, error) {
 	query := `
-		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source,
-		       created_at, created_by, deleted_at, deleted_by
+		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source, scrape_batch_id,
+		       created_at, created_by, deleted_at, deleted_by, unpinned_at, unpinned_by
 		FROM assignments
 		WHERE person_id = $1 AND schedule_date >= $2 AND schedule_date <= $3 AND deleted_at IS NULL
 		ORDER BY schedule_date ASC
@@ -206,16 +211,21 @@ func (r *AssignmentRepository) GetByPersonAndDateRange(ctx context.Context, pers
 
 // GetByScheduleVersion retrieves all assignments for a schedule version
 func (r *AssignmentRepository) GetByScheduleVersion(ctx context.Context, scheduleVersionID uuid.UUID) ([]*entity.Assignment, error) {
+	return assignmentsByVersion(ctx, r.db, scheduleVersionID)
+}
+
+// assignmentsByVersion retrieves all live assignments for a schedule version
+func assignmentsByVersion(ctx context.Context, q querier, scheduleVersionID uuid.UUID) ([]*entity.Assignment, error) {
 	query := `
-		SELECT a.id, a.person_id, a.shift_instance_id, a.schedule_date, a.original_shift_type, a.source,
-		       a.created_at, a.created_by, a.deleted_at, a.deleted_by
+		SELECT a.id, a.person_id, a.shift_instance_id, a.schedule_date, a.original_shift_type, a.source, a.scrape_batch_id,
+		       a.created_at, a.created_by, a.deleted_at, a.deleted_by, a.unpinned_at, a.unpinned_by
 		FROM assignments a
 		INNER JOIN shift_instances si ON a.shift_instance_id = si.id
 		WHERE si.schedule_version_id = $1 AND a.deleted_at IS NULL
 		ORDER BY a.schedule_date ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, scheduleVersionID)
+	rows, err := q.QueryContext(ctx, query, scheduleVersionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query assignments: %w", err)
 	}
@@ -241,7 +251,8 @@ func (r *AssignmentRepository) GetByScheduleVersion(ctx context.Context, schedul
 func (r *AssignmentRepository) Update(ctx context.Context, assignment *entity.Assignment) error {
 	query := `
 		UPDATE assignments
-		SET person_id = $2, shift_instance_id = $3, schedule_date = $4, original_shift_type = $5, source = $6
+		SET person_id = $2, shift_instance_id = $3, schedule_date = $4, original_shift_type = $5, source = $6,
+		    unpinned_at = $7, unpinned_by = $8
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -252,6 +263,8 @@ func (r *AssignmentRepository) Update(ctx context.Context, assignment *entity.As
 		assignment.ScheduleDate,
 		assignment.OriginalShiftType,
 		string(assignment.Source),
+		assignment.UnpinnedAt,
+		assignment.UnpinnedBy,
 	)
 
 	if err != nil {
@@ -301,6 +314,205 @@ func (r *AssignmentRepository) Delete(ctx context.Context, id uuid.UUID, deleter
 	return nil
 }
 
+// assignmentInsertChunk is how many assignments one multi-row INSERT writes
+const assignmentInsertChunk = 500
+
+// ApplyChanges writes a set of assignment changes in one transaction.
+// Deletes and updates of assignments that are no longer live fail the whole
+// set with a NotFoundError.
+func (r *AssignmentRepository) ApplyChanges(ctx context.Context, changes *entity.AssignmentChanges, editorID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := applyAssignmentChanges(ctx, tx, changes, editorID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit assignment changes: %w", err)
+	}
+	return nil
+}
+
+// EditVersion locks the schedule version row, so edits to one version run one
+// at a time, then plans and writes changes against its current assignments
+func (r *AssignmentRepository) EditVersion(ctx context.Context, versionID uuid.UUID, editorID uuid.UUID, plan repository.VersionEditPlan) error {
+	return r.EditSchedule(ctx, versionID, editorID, func(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.ScheduleChanges, error) {
+		changes, err := plan(version, shifts, assignments)
+		if err != nil || changes == nil {
+			return nil, err
+		}
+		return &entity.ScheduleChanges{Assignments: *changes}, nil
+	})
+}
+
+// EditSchedule locks the schedule version row, then plans and writes shift
+// and assignment changes against its current shifts and assignments
+func (r *AssignmentRepository) EditSchedule(ctx context.Context, versionID uuid.UUID, editorID uuid.UUID, plan repository.ScheduleEditPlan) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	version, shifts, assignments, err := lockSchedule(ctx, tx, versionID)
+	if err != nil {
+		return err
+	}
+
+	changes, err := plan(version, shifts, assignments)
+	if err != nil {
+		return err
+	}
+	if changes == nil || changes.IsEmpty() {
+		return nil
+	}
+	if err := applyScheduleChanges(ctx, tx, changes, editorID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schedule changes: %w", err)
+	}
+	return nil
+}
+
+// lockSchedule locks a version and reads its shifts and live assignments
+func lockSchedule(ctx context.Context, tx *sql.Tx, versionID uuid.UUID) (*entity.ScheduleVersion, []*entity.ShiftInstance, []*entity.Assignment, error) {
+	version, err := lockScheduleVersion(ctx, tx, versionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	shifts, err := shiftsByVersion(ctx, tx, versionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	assignments, err := assignmentsByVersion(ctx, tx, versionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return version, shifts, assignments, nil
+}
+
+// applyAssignmentChanges writes deletes, then updates, then creates
+func applyAssignmentChanges(ctx context.Context, tx *sql.Tx, changes *entity.AssignmentChanges, editorID uuid.UUID) error {
+	if len(changes.Delete) > 0 {
+		deleted, err := returnedIDs(tx.QueryContext(ctx, `
+			UPDATE assignments
+			SET deleted_at = NOW(), deleted_by = $2
+			WHERE id = ANY($1) AND deleted_at IS NULL
+			RETURNING id
+		`, pq.Array(changes.Delete), editorID))
+		if err != nil {
+			return fmt.Errorf("failed to delete assignments: %w", err)
+		}
+		for _, id := range changes.Delete {
+			if !deleted[id] {
+				return &repository.NotFoundError{ResourceType: "Assignment", ResourceID: id.String()}
+			}
+		}
+	}
+
+	if len(changes.Update) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `
+			UPDATE assignments
+			SET person_id = $2, shift_instance_id = $3, schedule_date = $4, original_shift_type = $5, source = $6,
+			    unpinned_at = $7, unpinned_by = $8
+			WHERE id = $1 AND deleted_at IS NULL
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare assignment update: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, a := range changes.Update {
+			result, err := stmt.ExecContext(ctx, a.ID, a.PersonID, a.ShiftInstanceID, a.ScheduleDate,
+				a.OriginalShiftType, string(a.Source), a.UnpinnedAt, a.UnpinnedBy)
+			if err != nil {
+				return fmt.Errorf("failed to update assignment: %w", err)
+			}
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rowsAffected == 0 {
+				return &repository.NotFoundError{ResourceType: "Assignment", ResourceID: a.ID.String()}
+			}
+		}
+	}
+
+	for start := 0; start < len(changes.Create); start += assignmentInsertChunk {
+		end := start + assignmentInsertChunk
+		if end > len(changes.Create) {
+			end = len(changes.Create)
+		}
+		if err := insertAssignments(ctx, tx, changes.Create[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyScheduleChanges writes new shifts, then the assignment changes, then
+// the dropped shifts
+func applyScheduleChanges(ctx context.Context, tx *sql.Tx, changes *entity.ScheduleChanges, editorID uuid.UUID) error {
+	if err := insertShiftInstances(ctx, tx, changes.CreateShifts); err != nil {
+		return err
+	}
+	if err := applyAssignmentChanges(ctx, tx, &changes.Assignments, editorID); err != nil {
+		return err
+	}
+	return deleteShiftInstances(ctx, tx, changes.DeleteShifts, editorID)
+}
+
+// returnedIDs collects the IDs an UPDATE ... RETURNING id reported
+func returnedIDs(rows *sql.Rows, err error) (map[uuid.UUID]bool, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// insertAssignments writes assignments with a single multi-row INSERT
+func insertAssignments(ctx context.Context, tx *sql.Tx, assignments []*entity.Assignment) error {
+	const columns = 9
+	values := make([]string, 0, len(assignments))
+	args := make([]interface{}, 0, len(assignments)*columns)
+	for i, a := range assignments {
+		if a.ID == uuid.Nil {
+			a.ID = uuid.New()
+		}
+		placeholders := make([]string, columns)
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", i*columns+j+1)
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, a.ID, a.PersonID, a.ShiftInstanceID, a.ScheduleDate, a.OriginalShiftType,
+			string(a.Source), a.ScrapeBatchID, a.CreatedAt, a.CreatedBy)
+	}
+
+	query := `
+		INSERT INTO assignments (id, person_id, shift_instance_id, schedule_date, original_shift_type, source, scrape_batch_id, created_at, created_by)
+		VALUES ` + strings.Join(values, ", ")
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create assignments: %w", err)
+	}
+	return nil
+}
+
 // Count returns the count of active assignments
 func (r *AssignmentRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
@@ -325,8 +537,8 @@ func (r *AssignmentRepository) GetAllByShiftIDs(ctx context.Context, shiftInstan
 	}
 
 	query := `
-		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source,
-		       created_at, created_by, deleted_at, deleted_by
+		SELECT id, person_id, shift_instance_id, schedule_date, original_shift_type, source, scrape_batch_id,
+		       created_at, created_by, deleted_at, deleted_by, unpinned_at, unpinned_by
 		FROM assignments
 		WHERE shift_instance_id = ANY($1) AND deleted_at IS NULL
 		ORDER BY shift_instance_id, created_at ASC
@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// CoverageAlertRepository implements repository.CoverageAlertRepository for PostgreSQL
+type CoverageAlertRepository struct {
+	db *sql.DB
+}
+
+// NewCoverageAlertRepository creates a new CoverageAlertRepository
+func NewCoverageAlertRepository(db *sql.DB) *CoverageAlertRepository {
+	return &CoverageAlertRepository{db: db}
+}
+
+// Create creates a new coverage alert
+func (r *CoverageAlertRepository) Create(ctx context.Context, alert *entity.CoverageAlert) error {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO coverage_alerts (
+			id, hospital_id, schedule_version_id, shift_instance_id, gap_key, shift_type, study_type,
+			shift_start, coverage_status, required, assigned, tier, state, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		alert.ID,
+		alert.HospitalID,
+		alert.ScheduleVersionID,
+		alert.ShiftInstanceID,
+		alert.GapKey,
+		string(alert.ShiftType),
+		string(alert.StudyType),
+		alert.ShiftStart,
+		alert.CoverageStatus,
+		alert.Required,
+		alert.Assigned,
+		string(alert.Tier),
+		string(alert.State),
+		alert.CreatedAt,
+		alert.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create coverage alert: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a coverage alert
+func (r *CoverageAlertRepository) Update(ctx context.Context, alert *entity.CoverageAlert) error {
+	query := `
+		UPDATE coverage_alerts
+		SET schedule_version_id = $2, shift_instance_id = $3, coverage_status = $4, required = $5,
+			assigned = $6, tier = $7, state = $8, resolution_reason = $9, updated_at = $10,
+			escalated_at = $11, resolved_at = $12
+		WHERE id = $1
+	`
+
+	var reason sql.NullString
+	if alert.ResolutionReason != "" {
+		reason = sql.NullString{String: alert.ResolutionReason, Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
+		alert.ID,
+		alert.ScheduleVersionID,
+		alert.ShiftInstanceID,
+		alert.CoverageStatus,
+		alert.Required,
+		alert.Assigned,
+		string(alert.Tier),
+		string(alert.State),
+		reason,
+		alert.UpdatedAt,
+		alert.EscalatedAt,
+		alert.ResolvedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update coverage alert: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "CoverageAlert",
+			ResourceID:   alert.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+// GetByHospital retrieves a hospital's alerts, newest first, optionally filtered by state
+func (r *CoverageAlertRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID, state entity.AlertState) ([]*entity.CoverageAlert, error) {
+	query := `
+		SELECT id, hospital_id, schedule_version_id, shift_instance_id, gap_key, shift_type, study_type,
+			shift_start, coverage_status, required, assigned, tier, state, resolution_reason,
+			created_at, updated_at, escalated_at, resolved_at
+		FROM coverage_alerts
+		WHERE hospital_id = $1 AND ($2 = '' OR state = $2)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, hospitalID, string(state))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query coverage alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*entity.CoverageAlert
+	for rows.Next() {
+		alert := &entity.CoverageAlert{}
+		var shiftType, tier, alertState string
+		var studyType, reason sql.NullString
+
+		err := rows.Scan(
+			&alert.ID,
+			&alert.HospitalID,
+			&alert.ScheduleVersionID,
+			&alert.ShiftInstanceID,
+			&alert.GapKey,
+			&shiftType,
+			&studyType,
+			&alert.ShiftStart,
+			&alert.CoverageStatus,
+			&alert.Required,
+			&alert.Assigned,
+			&tier,
+			&alertState,
+			&reason,
+			&alert.CreatedAt,
+			&alert.UpdatedAt,
+			&alert.EscalatedAt,
+			&alert.ResolvedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan coverage alert: %w", err)
+		}
+
+		alert.ShiftType = entity.ShiftType(shiftType)
+		alert.StudyType = entity.StudyType(studyType.String)
+		alert.Tier = entity.AlertTier(tier)
+		alert.State = entity.AlertState(alertState)
+		alert.ResolutionReason = reason.String
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
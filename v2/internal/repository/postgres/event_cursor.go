@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// EventCursorRepository implements repository.EventCursorRepository for PostgreSQL
+type EventCursorRepository struct {
+	db *sql.DB
+}
+
+// NewEventCursorRepository creates a new EventCursorRepository
+func NewEventCursorRepository(db *sql.DB) *EventCursorRepository {
+	return &EventCursorRepository{db: db}
+}
+
+// Get returns the last event the consumer handled, or 0 if it has none
+func (r *EventCursorRepository) Get(ctx context.Context, consumer string) (int64, error) {
+	var lastEventID int64
+	err := r.db.QueryRowContext(ctx, `SELECT last_event_id FROM event_cursors WHERE consumer = $1`, consumer).Scan(&lastEventID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get event cursor: %w", err)
+	}
+	return lastEventID, nil
+}
+
+// Save records the last event the consumer handled
+func (r *EventCursorRepository) Save(ctx context.Context, consumer string, lastEventID int64) error {
+	query := `
+		INSERT INTO event_cursors (consumer, last_event_id, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (consumer) DO UPDATE
+		SET last_event_id = EXCLUDED.last_event_id, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := r.db.ExecContext(ctx, query, consumer, lastEventID); err != nil {
+		return fmt.Errorf("failed to save event cursor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// HospitalRepository implements repository.HospitalRepository for PostgreSQL
+type HospitalRepository struct {
+	db *sql.DB
+}
+
+// NewHospitalRepository creates a new HospitalRepository
+func NewHospitalRepository(db *sql.DB) *HospitalRepository {
+	return &HospitalRepository{db: db}
+}
+
+// Create creates a new hospital
+func (r *HospitalRepository) Create(ctx context.Context, hospital *entity.Hospital) error {
+	if hospital.ID == uuid.Nil {
+		hospital.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO hospitals (id, name, code, location, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		hospital.ID,
+		hospital.Name,
+		hospital.Code,
+		hospital.Location,
+		hospital.CreatedAt,
+		hospital.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create hospital: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a hospital by ID
+func (r *HospitalRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Hospital, error) {
+	hospital := &entity.Hospital{}
+	var location sql.NullString
+
+	query := `
+		SELECT id, name, code, location, created_at, updated_at
+		FROM hospitals
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&hospital.ID,
+		&hospital.Name,
+		&hospital.Code,
+		&location,
+		&hospital.CreatedAt,
+		&hospital.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "Hospital",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hospital: %w", err)
+	}
+
+	hospital.Location = location.String
+	return hospital, nil
+}
+
+// GetAll retrieves all hospitals
+func (r *HospitalRepository) GetAll(ctx context.Context) ([]*entity.Hospital, error) {
+	query := `
+		SELECT id, name, code, location, created_at, updated_at
+		FROM hospitals
+		WHERE deleted_at IS NULL
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hospitals: %w", err)
+	}
+	defer rows.Close()
+
+	var hospitals []*entity.Hospital
+	for rows.Next() {
+		hospital := &entity.Hospital{}
+		var location sql.NullString
+		err := rows.Scan(
+			&hospital.ID,
+			&hospital.Name,
+			&hospital.Code,
+			&location,
+			&hospital.CreatedAt,
+			&hospital.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hospital: %w", err)
+		}
+		hospital.Location = location.String
+		hospitals = append(hospitals, hospital)
+	}
+
+	return hospitals, rows.Err()
+}
+
+// Update updates a hospital
+func (r *HospitalRepository) Update(ctx context.Context, hospital *entity.Hospital) error {
+	query := `
+		UPDATE hospitals
+		SET name = $1, code = $2, location = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		hospital.Name,
+		hospital.Code,
+		hospital.Location,
+		hospital.UpdatedAt,
+		hospital.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update hospital: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "Hospital",
+			ResourceID:   hospital.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+// Delete soft-deletes a hospital
+func (r *HospitalRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE hospitals
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete hospital: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "Hospital",
+			ResourceID:   id.String(),
+		}
+	}
+
+	return nil
+}
+
+// Count returns the total count of non-deleted hospitals
+func (r *HospitalRepository) Count(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM hospitals WHERE deleted_at IS NULL`
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count hospitals: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// NotificationPreferenceRepository implements repository.NotificationPreferenceRepository for PostgreSQL
+type NotificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationPreferenceRepository creates a new NotificationPreferenceRepository
+func NewNotificationPreferenceRepository(db *sql.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+// GetByPerson retrieves a person's notification preferences
+func (r *NotificationPreferenceRepository) GetByPerson(ctx context.Context, personID uuid.UUID) (*entity.NotificationPreference, error) {
+	pref := &entity.NotificationPreference{}
+	var channels []string
+	var phone sql.NullString
+	var digestMode string
+
+	query := `
+		SELECT person_id, enabled, channels, phone_number, digest_mode, digest_hour, updated_at
+		FROM notification_preferences
+		WHERE person_id = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, personID).Scan(
+		&pref.PersonID,
+		&pref.Enabled,
+		pq.Array(&channels),
+		&phone,
+		&digestMode,
+		&pref.DigestHour,
+		&pref.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "NotificationPreference",
+			ResourceID:   personID.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+
+	for _, ch := range channels {
+		pref.Channels = append(pref.Channels, entity.NotificationChannel(ch))
+	}
+	pref.PhoneNumber = phone.String
+	pref.DigestMode = entity.DigestMode(digestMode)
+
+	return pref, nil
+}
+
+// Upsert creates or replaces a person's notification preferences
+func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, pref *entity.NotificationPreference) error {
+	channels := make([]string, len(pref.Channels))
+	for i, ch := range pref.Channels {
+		channels[i] = string(ch)
+	}
+
+	query := `
+		INSERT INTO notification_preferences (
+			person_id, enabled, channels, phone_number, digest_mode, digest_hour, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (person_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, channels = EXCLUDED.channels,
+			phone_number = EXCLUDED.phone_number, digest_mode = EXCLUDED.digest_mode,
+			digest_hour = EXCLUDED.digest_hour, updated_at = NOW()
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		pref.PersonID,
+		pref.Enabled,
+		pq.Array(channels),
+		pref.PhoneNumber,
+		string(pref.DigestMode),
+		pref.DigestHour,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert notification preference: %w", err)
+	}
+
+	return nil
+}
+
+// PendingNotificationRepository implements repository.PendingNotificationRepository for PostgreSQL
+type PendingNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewPendingNotificationRepository creates a new PendingNotificationRepository
+func NewPendingNotificationRepository(db *sql.DB) *PendingNotificationRepository {
+	return &PendingNotificationRepository{db: db}
+}
+
+// Create stores a notification for a later digest
+func (r *PendingNotificationRepository) Create(ctx context.Context, n *entity.PendingNotification) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO pending_notifications (
+			id, person_id, hospital_id, old_version_id, new_version_id, body, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		n.ID,
+		n.PersonID,
+		n.HospitalID,
+		n.OldVersionID,
+		n.NewVersionID,
+		n.Body,
+		n.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create pending notification: %w", err)
+	}
+
+	return nil
+}
+
+// GetUnsent retrieves all notifications not yet sent, oldest first
+func (r *PendingNotificationRepository) GetUnsent(ctx context.Context) ([]*entity.PendingNotification, error) {
+	query := `
+		SELECT id, person_id, hospital_id, old_version_id, new_version_id, body, created_at, sent_at
+		FROM pending_notifications
+		WHERE sent_at IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*entity.PendingNotification
+	for rows.Next() {
+		n := &entity.PendingNotification{}
+		err := rows.Scan(
+			&n.ID,
+			&n.PersonID,
+			&n.HospitalID,
+			&n.OldVersionID,
+			&n.NewVersionID,
+			&n.Body,
+			&n.CreatedAt,
+			&n.SentAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pending notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MarkSent marks notifications as delivered in a digest
+func (r *PendingNotificationRepository) MarkSent(ctx context.Context, ids []uuid.UUID, sentAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE pending_notifications
+		SET sent_at = $2
+		WHERE id = ANY($1)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, pq.Array(ids), sentAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark notifications sent: %w", err)
+	}
+
+	return nil
+}
+
+// NotificationDeliveryRepository implements repository.NotificationDeliveryRepository for PostgreSQL
+type NotificationDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationDeliveryRepository creates a new NotificationDeliveryRepository
+func NewNotificationDeliveryRepository(db *sql.DB) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{db: db}
+}
+
+// GetByVersionChange retrieves everyone already notified about a promotion
+func (r *NotificationDeliveryRepository) GetByVersionChange(ctx context.Context, oldVersionID, newVersionID uuid.UUID) ([]*entity.NotificationDelivery, error) {
+	query := `
+		SELECT old_version_id, new_version_id, person_id, status, created_at
+		FROM notification_deliveries
+		WHERE old_version_id = $1 AND new_version_id = $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, oldVersionID, newVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.NotificationDelivery
+	for rows.Next() {
+		d := &entity.NotificationDelivery{}
+		var status string
+		if err := rows.Scan(&d.OldVersionID, &d.NewVersionID, &d.PersonID, &status, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		d.Status = entity.NotificationDeliveryStatus(status)
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Create records that a person was notified about a promotion
+func (r *NotificationDeliveryRepository) Create(ctx context.Context, d *entity.NotificationDelivery) error {
+	query := `
+		INSERT INTO notification_deliveries (old_version_id, new_version_id, person_id, status, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (old_version_id, new_version_id, person_id) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, d.OldVersionID, d.NewVersionID, d.PersonID, string(d.Status), d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification delivery: %w", err)
+	}
+
+	return nil
+}
@@ -14,6 +14,13 @@ type DB struct {
 	*sql.DB
 }
 
+// querier is satisfied by both *sql.DB and *sql.Tx, so reads can run inside
+// a caller's transaction
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // New creates a new PostgreSQL database connection
 func New(connString string) (*DB, error) {
 	sqldb, err := sql.Open("postgres", connString)
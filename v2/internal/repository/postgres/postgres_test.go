@@ -179,6 +179,7 @@ func createTestTables(ctx context.Context, db *sql.DB) error {
 		specialty_constraint VARCHAR(50),
 		desired_coverage INTEGER DEFAULT 0,
 		is_mandatory BOOLEAN DEFAULT false,
+		scrape_batch_id UUID,
 		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
 		created_by UUID,
 		updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
@@ -195,12 +196,15 @@ func createTestTables(ctx context.Context, db *sql.DB) error {
 		schedule_date TIMESTAMP NOT NULL,
 		original_shift_type VARCHAR(50),
 		source VARCHAR(50),
+		scrape_batch_id UUID,
 		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
 		created_by UUID,
 		updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
 		updated_by UUID,
 		deleted_at TIMESTAMP,
-		deleted_by UUID
+		deleted_by UUID,
+		unpinned_at TIMESTAMP,
+		unpinned_by UUID
 	);
 
 	-- Scrape Batches
@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// ScheduleMergeRepository implements repository.ScheduleMergeRepository for PostgreSQL
+type ScheduleMergeRepository struct {
+	db *sql.DB
+}
+
+// NewScheduleMergeRepository creates a new ScheduleMergeRepository
+func NewScheduleMergeRepository(db *sql.DB) *ScheduleMergeRepository {
+	return &ScheduleMergeRepository{db: db}
+}
+
+// Create plans a merge against the locked STAGING version and stores it,
+// its conflicts and the shift and assignment changes it applied in one
+// transaction, so a failed merge leaves nothing behind and edits made while
+// it runs wait for it instead of being planned around
+func (r *ScheduleMergeRepository) Create(ctx context.Context, stagingVersionID uuid.UUID, editorID uuid.UUID, plan repository.MergePlan) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	staging, shifts, assignments, err := lockSchedule(ctx, tx, stagingVersionID)
+	if err != nil {
+		return err
+	}
+	merge, changes, err := plan(staging, shifts, assignments)
+	if err != nil {
+		return err
+	}
+	if merge.ID == uuid.Nil {
+		merge.ID = uuid.New()
+	}
+
+	if err := applyScheduleChanges(ctx, tx, changes, editorID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO schedule_merges (
+			id, hospital_id, base_version_id, staging_version_id, imported_version_id, auto_applied, created_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		merge.ID,
+		merge.HospitalID,
+		merge.BaseVersionID,
+		merge.StagingVersionID,
+		merge.ImportedVersionID,
+		merge.AutoApplied,
+		merge.CreatedAt,
+		merge.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule merge: %w", err)
+	}
+
+	if len(merge.Conflicts) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO merge_conflicts (
+				id, merge_id, schedule_date, shift_type, study_type, specialty_constraint,
+				base_person_ids, staging_person_ids, imported_person_ids
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare merge conflict insert: %w", err)
+		}
+		defer stmt.Close()
+
+		for i := range merge.Conflicts {
+			c := &merge.Conflicts[i]
+			if c.ID == uuid.Nil {
+				c.ID = uuid.New()
+			}
+			c.MergeID = merge.ID
+			_, err := stmt.ExecContext(ctx, c.ID, c.MergeID, c.ScheduleDate, string(c.ShiftType),
+				string(c.StudyType), string(c.SpecialtyConstraint), pq.Array(c.BasePersonIDs), pq.Array(c.StagingPersonIDs), pq.Array(c.ImportedPersonIDs))
+			if err != nil {
+				return fmt.Errorf("failed to create merge conflict: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schedule merge: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a merge with its conflicts
+func (r *ScheduleMergeRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ScheduleMerge, error) {
+	query := `
+		SELECT id, hospital_id, base_version_id, staging_version_id, imported_version_id, auto_applied, created_at, created_by
+		FROM schedule_merges
+		WHERE id = $1
+	`
+
+	merge := &entity.ScheduleMerge{}
+	err := scanScheduleMerge(r.db.QueryRowContext(ctx, query, id), merge)
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "ScheduleMerge",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule merge: %w", err)
+	}
+
+	if merge.Conflicts, err = r.conflicts(ctx, merge.ID); err != nil {
+		return nil, err
+	}
+	return merge, nil
+}
+
+// GetByStagingVersion retrieves the merges into a STAGING version, newest first
+func (r *ScheduleMergeRepository) GetByStagingVersion(ctx context.Context, stagingVersionID uuid.UUID) ([]*entity.ScheduleMerge, error) {
+	query := `
+		SELECT id, hospital_id, base_version_id, staging_version_id, imported_version_id, auto_applied, created_at, created_by
+		FROM schedule_merges
+		WHERE staging_version_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, stagingVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule merges: %w", err)
+	}
+	defer rows.Close()
+
+	var merges []*entity.ScheduleMerge
+	for rows.Next() {
+		merge := &entity.ScheduleMerge{}
+		if err := scanScheduleMerge(rows, merge); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule merge: %w", err)
+		}
+		merges = append(merges, merge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule merges: %w", err)
+	}
+
+	for _, merge := range merges {
+		if merge.Conflicts, err = r.conflicts(ctx, merge.ID); err != nil {
+			return nil, err
+		}
+	}
+	return merges, nil
+}
+
+// ResolveConflict plans a resolution against the locked STAGING version,
+// records it on the open conflict and applies the changes it made in one
+// transaction
+func (r *ScheduleMergeRepository) ResolveConflict(ctx context.Context, stagingVersionID uuid.UUID, editorID uuid.UUID, plan repository.ConflictResolutionPlan) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	staging, shifts, assignments, err := lockSchedule(ctx, tx, stagingVersionID)
+	if err != nil {
+		return err
+	}
+	conflict, changes, err := plan(staging, shifts, assignments)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE merge_conflicts
+		SET resolution = $2, resolved_at = $3, resolved_by = $4
+		WHERE id = $1 AND resolved_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, conflict.ID, string(conflict.Resolution), conflict.ResolvedAt, conflict.ResolvedBy)
+	if err != nil {
+		return fmt.Errorf("failed to resolve merge conflict: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "MergeConflict",
+			ResourceID:   conflict.ID.String(),
+		}
+	}
+
+	if err := applyScheduleChanges(ctx, tx, changes, editorID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merge conflict resolution: %w", err)
+	}
+	return nil
+}
+
+// conflicts retrieves a merge's conflicts by slot
+func (r *ScheduleMergeRepository) conflicts(ctx context.Context, mergeID uuid.UUID) ([]entity.MergeConflict, error) {
+	query := `
+		SELECT id, merge_id, schedule_date, shift_type, study_type, specialty_constraint,
+		       base_person_ids, staging_person_ids, imported_person_ids, resolution, resolved_at, resolved_by
+		FROM merge_conflicts
+		WHERE merge_id = $1
+		ORDER BY schedule_date, shift_type, study_type, specialty_constraint
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mergeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merge conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	conflicts := []entity.MergeConflict{}
+	for rows.Next() {
+		var c entity.MergeConflict
+		var shiftType, studyType, specialty string
+		var resolution sql.NullString
+		if err := rows.Scan(
+			&c.ID,
+			&c.MergeID,
+			&c.ScheduleDate,
+			&shiftType,
+			&studyType,
+			&specialty,
+			pq.Array(&c.BasePersonIDs),
+			pq.Array(&c.StagingPersonIDs),
+			pq.Array(&c.ImportedPersonIDs),
+			&resolution,
+			&c.ResolvedAt,
+			&c.ResolvedBy,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan merge conflict: %w", err)
+		}
+		c.ShiftType = entity.ShiftType(shiftType)
+		c.StudyType = entity.StudyType(studyType)
+		c.SpecialtyConstraint = entity.SpecialtyType(specialty)
+		c.Resolution = entity.MergeResolution(resolution.String)
+		conflicts = append(conflicts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating merge conflicts: %w", err)
+	}
+	return conflicts, nil
+}
+
+// scanScheduleMerge scans a schedule_merges row
+func scanScheduleMerge(scanner interface{ Scan(...interface{}) error }, merge *entity.ScheduleMerge) error {
+	return scanner.Scan(
+		&merge.ID,
+		&merge.HospitalID,
+		&merge.BaseVersionID,
+		&merge.StagingVersionID,
+		&merge.ImportedVersionID,
+		&merge.AutoApplied,
+		&merge.CreatedAt,
+		&merge.CreatedBy,
+	)
+}
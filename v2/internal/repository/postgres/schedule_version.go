@@ -60,20 +60,32 @@ func (r *ScheduleVersionRepository) Create(ctx context.Context, version *entity.
 	return nil
 }
 
-// GetByID retrieves a schedule version by ID
-func (r *ScheduleVersionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ScheduleVersion, error) {
-	version := &entity.ScheduleVersion{}
-
-	query := `
+// scheduleVersionByID is the query GetByID runs
+const scheduleVersionByID = `
 		SELECT id, hospital_id, status, effective_start_date, effective_end_date, scrape_batch_id, validation_results,
 		       created_at, created_by, updated_at, updated_by, deleted_at, deleted_by
 		FROM schedule_versions
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
+// GetByID retrieves a schedule version by ID
+func (r *ScheduleVersionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ScheduleVersion, error) {
+	return getScheduleVersion(ctx, r.db, scheduleVersionByID, id)
+}
+
+// lockScheduleVersion retrieves a schedule version and locks its row until
+// the transaction ends
+func lockScheduleVersion(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*entity.ScheduleVersion, error) {
+	return getScheduleVersion(ctx, tx, scheduleVersionByID+" FOR UPDATE", id)
+}
+
+// getScheduleVersion runs a query selecting one schedule version by ID
+func getScheduleVersion(ctx context.Context, q querier, query string, id uuid.UUID) (*entity.ScheduleVersion, error) {
+	version := &entity.ScheduleVersion{}
+
 	var validationJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := q.QueryRowContext(ctx, query, id).Scan(
 		&version.ID,
 		&version.HospitalID,
 		(*string)(&version.Status),
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -27,24 +28,38 @@ func (r *ScrapeBatchRepository) Create(ctx context.Context, batch *entity.Scrape
 		batch.ID = uuid.New()
 	}
 
+	statsJSON, err := marshalDivisionStats(batch.DivisionStats)
+	if err != nil {
+		return err
+	}
+	deltaJSON, err := marshalScrapeDelta(batch.Delta)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO scrape_batches (
 			id, hospital_id, state, window_start_date, window_end_date,
-			scraped_at, row_count, error_message, created_at, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			scraped_at, completed_at, row_count, ingest_checksum, error_message, created_at, created_by,
+			division_stats, delta
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, $13, $14)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		batch.ID,
 		batch.HospitalID,
 		string(batch.State),
 		batch.WindowStartDate,
 		batch.WindowEndDate,
 		batch.ScrapedAt,
+		batch.CompletedAt,
 		batch.RowCount,
+		batch.IngestChecksum,
 		batch.ErrorMessage,
 		batch.CreatedAt,
 		batch.CreatedBy,
+		statsJSON,
+		deltaJSON,
 	)
 
 	if err != nil {
@@ -56,29 +71,12 @@ func (r *ScrapeBatchRepository) Create(ctx context.Context, batch *entity.Scrape
 
 // GetByID retrieves a scrape batch by ID
 func (r *ScrapeBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ScrapeBatch, error) {
-	batch := &entity.ScrapeBatch{}
-
-	query := `
-		SELECT id, hospital_id, state, window_start_date, window_end_date,
-		       scraped_at, row_count, error_message, created_at, created_by, deleted_at
+	query := `SELECT ` + scrapeBatchColumns + `
 		FROM scrape_batches
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&batch.ID,
-		&batch.HospitalID,
-		(*string)(&batch.State),
-		&batch.WindowStartDate,
-		&batch.WindowEndDate,
-		&batch.ScrapedAt,
-		&batch.RowCount,
-		&batch.ErrorMessage,
-		&batch.CreatedAt,
-		&batch.CreatedBy,
-		&batch.DeletedAt,
-	)
-
+	batch, err := scanScrapeBatch(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, &repository.NotFoundError{
 			ResourceType: "ScrapeBatch",
@@ -94,9 +92,7 @@ func (r *ScrapeBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*ent
 
 // GetByHospital retrieves all scrape batches for a hospital
 func (r *ScrapeBatchRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.ScrapeBatch, error) {
-	query := `
-		SELECT id, hospital_id, state, window_start_date, window_end_date,
-		       scraped_at, row_count, error_message, created_at, created_by, deleted_at
+	query := `SELECT ` + scrapeBatchColumns + `
 		FROM scrape_batches
 		WHERE hospital_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -110,20 +106,7 @@ func (r *ScrapeBatchRepository) GetByHospital(ctx context.Context, hospitalID uu
 
 	var batches []*entity.ScrapeBatch
 	for rows.Next() {
-		batch := &entity.ScrapeBatch{}
-		err := rows.Scan(
-			&batch.ID,
-			&batch.HospitalID,
-			(*string)(&batch.State),
-			&batch.WindowStartDate,
-			&batch.WindowEndDate,
-			&batch.ScrapedAt,
-			&batch.RowCount,
-			&batch.ErrorMessage,
-			&batch.CreatedAt,
-			&batch.CreatedBy,
-			&batch.DeletedAt,
-		)
+		batch, err := scanScrapeBatch(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan scrape batch: %w", err)
 		}
@@ -135,9 +118,7 @@ func (r *ScrapeBatchRepository) GetByHospital(ctx context.Context, hospitalID uu
 
 // GetByStatus retrieves all scrape batches with a specific status
 func (r *ScrapeBatchRepository) GetByStatus(ctx context.Context, status entity.BatchState) ([]*entity.ScrapeBatch, error) {
-	query := `
-		SELECT id, hospital_id, state, window_start_date, window_end_date,
-		       scraped_at, row_count, error_message, created_at, created_by, deleted_at
+	query := `SELECT ` + scrapeBatchColumns + `
 		FROM scrape_batches
 		WHERE state = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -151,20 +132,7 @@ func (r *ScrapeBatchRepository) GetByStatus(ctx context.Context, status entity.B
 
 	var batches []*entity.ScrapeBatch
 	for rows.Next() {
-		batch := &entity.ScrapeBatch{}
-		err := rows.Scan(
-			&batch.ID,
-			&batch.HospitalID,
-			(*string)(&batch.State),
-			&batch.WindowStartDate,
-			&batch.WindowEndDate,
-			&batch.ScrapedAt,
-			&batch.RowCount,
-			&batch.ErrorMessage,
-			&batch.CreatedAt,
-			&batch.CreatedBy,
-			&batch.DeletedAt,
-		)
+		batch, err := scanScrapeBatch(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan scrape batch: %w", err)
 		}
@@ -176,10 +144,20 @@ func (r *ScrapeBatchRepository) GetByStatus(ctx context.Context, status entity.B
 
 // Update updates a scrape batch
 func (r *ScrapeBatchRepository) Update(ctx context.Context, batch *entity.ScrapeBatch) error {
+	statsJSON, err := marshalDivisionStats(batch.DivisionStats)
+	if err != nil {
+		return err
+	}
+	deltaJSON, err := marshalScrapeDelta(batch.Delta)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE scrape_batches
-		SET state = $1, row_count = $2, error_message = $3, scraped_at = $4
-		WHERE id = $5 AND deleted_at IS NULL
+		SET state = $1, row_count = $2, error_message = $3, scraped_at = $4, completed_at = $5,
+		    ingest_checksum = NULLIF($6, ''), division_stats = $7, delta = $8
+		WHERE id = $9 AND deleted_at IS NULL
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -187,6 +165,10 @@ func (r *ScrapeBatchRepository) Update(ctx context.Context, batch *entity.Scrape
 		batch.RowCount,
 		batch.ErrorMessage,
 		batch.ScrapedAt,
+		batch.CompletedAt,
+		batch.IngestChecksum,
+		statsJSON,
+		deltaJSON,
 		batch.ID,
 	)
 
@@ -247,3 +229,177 @@ func (r *ScrapeBatchRepository) Count(ctx context.Context) (int64, error) {
 
 	return count, nil
 }
+
+// Rollback soft-deletes the assignments and shift instances a batch created
+// and marks the batch rolled back, in one transaction. MANUAL and OVERRIDE
+// assignments are left alone, as is any shift still holding one of them.
+func (r *ScrapeBatchRepository) Rollback(ctx context.Context, id uuid.UUID, rolledBackBy uuid.UUID) (*entity.BatchRollback, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rollback := &entity.BatchRollback{ScrapeBatchID: id, RolledBackBy: rolledBackBy}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE scrape_batches
+		SET rolled_back_at = NOW(), rolled_back_by = $2
+		WHERE id = $1 AND deleted_at IS NULL AND rolled_back_at IS NULL
+		RETURNING rolled_back_at
+	`, id, rolledBackBy).Scan(&rollback.RolledBackAt)
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "ScrapeBatch",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark scrape batch rolled back: %w", err)
+	}
+
+	versionRows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT schedule_version_id FROM shift_instances
+		WHERE scrape_batch_id = $1 AND deleted_at IS NULL
+		UNION
+		SELECT DISTINCT si.schedule_version_id FROM assignments a
+		INNER JOIN shift_instances si ON a.shift_instance_id = si.id
+		WHERE a.scrape_batch_id = $1 AND a.deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rolled back schedule versions: %w", err)
+	}
+	defer versionRows.Close()
+	for versionRows.Next() {
+		var versionID uuid.UUID
+		if err := versionRows.Scan(&versionID); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule version id: %w", err)
+		}
+		rollback.ScheduleVersionIDs = append(rollback.ScheduleVersionIDs, versionID)
+	}
+	if err := versionRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schedule versions: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE assignments
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE scrape_batch_id = $1 AND deleted_at IS NULL AND source NOT IN ($3, $4)
+	`, id, rolledBackBy, string(entity.AssignmentSourceManual), string(entity.AssignmentSourceOverride))
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back assignments: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	rollback.AssignmentsDeleted = int(deleted)
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM assignments a
+		INNER JOIN shift_instances si ON a.shift_instance_id = si.id
+		WHERE si.scrape_batch_id = $1 AND si.deleted_at IS NULL AND a.deleted_at IS NULL
+	`, id).Scan(&rollback.AssignmentsKept)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count kept assignments: %w", err)
+	}
+
+	result, err = tx.ExecContext(ctx, `
+		UPDATE shift_instances si
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE si.scrape_batch_id = $1 AND si.deleted_at IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM assignments a
+		      WHERE a.shift_instance_id = si.id AND a.deleted_at IS NULL
+		  )
+	`, id, rolledBackBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back shift instances: %w", err)
+	}
+	deleted, err = result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	rollback.ShiftsDeleted = int(deleted)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit scrape batch rollback: %w", err)
+	}
+	return rollback, nil
+}
+
+// scrapeBatchColumns are the columns scanScrapeBatch reads, in order
+const scrapeBatchColumns = `id, hospital_id, state, window_start_date, window_end_date,
+		       scraped_at, completed_at, row_count, COALESCE(ingest_checksum, ''), error_message, created_at, created_by, deleted_at,
+		       division_stats, delta, rolled_back_at, rolled_back_by`
+
+func scanScrapeBatch(row rowScanner) (*entity.ScrapeBatch, error) {
+	batch := &entity.ScrapeBatch{}
+	var statsJSON, deltaJSON []byte
+	err := row.Scan(
+		&batch.ID,
+		&batch.HospitalID,
+		(*string)(&batch.State),
+		&batch.WindowStartDate,
+		&batch.WindowEndDate,
+		&batch.ScrapedAt,
+		&batch.CompletedAt,
+		&batch.RowCount,
+		&batch.IngestChecksum,
+		&batch.ErrorMessage,
+		&batch.CreatedAt,
+		&batch.CreatedBy,
+		&batch.DeletedAt,
+		&statsJSON,
+		&deltaJSON,
+		&batch.RolledBackAt,
+		&batch.RolledBackBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if batch.DivisionStats, err = unmarshalDivisionStats(statsJSON); err != nil {
+		return nil, err
+	}
+	if len(deltaJSON) > 0 {
+		batch.Delta = &entity.ScrapeDelta{}
+		if err := json.Unmarshal(deltaJSON, batch.Delta); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal scrape delta: %w", err)
+		}
+	}
+	return batch, nil
+}
+
+// marshalScrapeDelta encodes the delta, NULL when there was nothing to compare against
+func marshalScrapeDelta(delta *entity.ScrapeDelta) ([]byte, error) {
+	if delta == nil {
+		return nil, nil
+	}
+	deltaJSON, err := json.Marshal(delta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scrape delta: %w", err)
+	}
+	return deltaJSON, nil
+}
+
+// marshalDivisionStats encodes per-division stats, NULL for single-source batches
+func marshalDivisionStats(stats []entity.DivisionScrapeStats) ([]byte, error) {
+	if len(stats) == 0 {
+		return nil, nil
+	}
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal division stats: %w", err)
+	}
+	return statsJSON, nil
+}
+
+func unmarshalDivisionStats(statsJSON []byte) ([]entity.DivisionScrapeStats, error) {
+	if len(statsJSON) == 0 {
+		return nil, nil
+	}
+	var stats []entity.DivisionScrapeStats
+	if err := json.Unmarshal(statsJSON, &stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal division stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// ScrapedAssignmentRepository implements repository.ScrapedAssignmentRepository for PostgreSQL
+type ScrapedAssignmentRepository struct {
+	db *sql.DB
+}
+
+// NewScrapedAssignmentRepository creates a new ScrapedAssignmentRepository
+func NewScrapedAssignmentRepository(db *sql.DB) *ScrapedAssignmentRepository {
+	return &ScrapedAssignmentRepository{db: db}
+}
+
+// CreateBatch stores everything a scrape batch saw in one transaction
+func (r *ScrapedAssignmentRepository) CreateBatch(ctx context.Context, batchID uuid.UUID, assignments []entity.ScrapedAssignment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO scraped_assignments (scrape_batch_id, schedule_date, shift, start_time, end_time, location, person)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare scraped assignment insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, a := range assignments {
+		if _, err := stmt.ExecContext(ctx, batchID, a.Date, a.Shift, a.StartTime, a.EndTime, a.Location, a.Person); err != nil {
+			return fmt.Errorf("failed to create scraped assignment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit scraped assignments: %w", err)
+	}
+	return nil
+}
+
+// GetByBatch retrieves everything a scrape batch saw, by date
+func (r *ScrapedAssignmentRepository) GetByBatch(ctx context.Context, batchID uuid.UUID) ([]entity.ScrapedAssignment, error) {
+	query := `
+		SELECT scrape_batch_id, schedule_date, shift, start_time, end_time, location, person
+		FROM scraped_assignments
+		WHERE scrape_batch_id = $1
+		ORDER BY schedule_date, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scraped assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []entity.ScrapedAssignment
+	for rows.Next() {
+		var a entity.ScrapedAssignment
+		var date time.Time
+		if err := rows.Scan(&a.ScrapeBatchID, &date, &a.Shift, &a.StartTime, &a.EndTime, &a.Location, &a.Person); err != nil {
+			return nil, fmt.Errorf("failed to scan scraped assignment: %w", err)
+		}
+		a.Date = date.Format("2006-01-02")
+		assignments = append(assignments, a)
+	}
+
+	return assignments, rows.Err()
+}
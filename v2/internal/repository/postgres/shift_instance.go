@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -41,6 +42,7 @@ func scanShiftInstance(scanner interface{ Scan(...interface{}) error }, shift *e
 		&specialtyConstraint,
 		&shift.DesiredCoverage,
 		&shift.IsMandatory,
+		&shift.ScrapeBatchID,
 		&shift.CreatedAt,
 		&shift.CreatedBy,
 	)
@@ -73,8 +75,8 @@ func (r *ShiftInstanceRepository) Create(ctx context.Context, shift *entity.Shif
 		INSERT INTO shift_instances (
 			id, schedule_version_id, hospital_id, shift_type, schedule_date,
 			start_time, end_time, study_type, specialty_constraint, desired_coverage,
-			is_mandatory, created_at, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			is_mandatory, scrape_batch_id, created_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -89,6 +91,7 @@ func (r *ShiftInstanceRepository) Create(ctx context.Context, shift *entity.Shif
 		string(shift.SpecialtyConstraint),
 		shift.DesiredCoverage,
 		shift.IsMandatory,
+		shift.ScrapeBatchID,
 		shift.CreatedAt,
 		shift.CreatedBy,
 	)
@@ -100,6 +103,106 @@ func (r *ShiftInstanceRepository) Create(ctx context.Context, shift *entity.Shif
 	return nil
 }
 
+// shiftInsertChunk is how many shift instances one multi-row INSERT writes
+const shiftInsertChunk = 500
+
+// CreateBatch creates many shift instances in one transaction
+func (r *ShiftInstanceRepository) CreateBatch(ctx context.Context, shifts []*entity.ShiftInstance) error {
+	if len(shifts) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertShiftInstances(ctx, tx, shifts); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit shift instances: %w", err)
+	}
+	return nil
+}
+
+// insertShiftInstances writes shift instances with multi-row INSERTs of up
+// to shiftInsertChunk rows
+func insertShiftInstances(ctx context.Context, tx *sql.Tx, shifts []*entity.ShiftInstance) error {
+	const columns = 14
+	for start := 0; start < len(shifts); start += shiftInsertChunk {
+		end := start + shiftInsertChunk
+		if end > len(shifts) {
+			end = len(shifts)
+		}
+
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*columns)
+		for i, shift := range shifts[start:end] {
+			if shift.ID == uuid.Nil {
+				shift.ID = uuid.New()
+			}
+			placeholders := make([]string, columns)
+			for j := range placeholders {
+				placeholders[j] = fmt.Sprintf("$%d", i*columns+j+1)
+			}
+			values = append(values, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args,
+				shift.ID,
+				shift.ScheduleVersionID,
+				shift.HospitalID,
+				string(shift.ShiftType),
+				shift.ScheduleDate,
+				shift.StartTime,
+				shift.EndTime,
+				string(shift.StudyType),
+				string(shift.SpecialtyConstraint),
+				shift.DesiredCoverage,
+				shift.IsMandatory,
+				shift.ScrapeBatchID,
+				shift.CreatedAt,
+				shift.CreatedBy,
+			)
+		}
+
+		query := `
+			INSERT INTO shift_instances (
+				id, schedule_version_id, hospital_id, shift_type, schedule_date,
+				start_time, end_time, study_type, specialty_constraint, desired_coverage,
+				is_mandatory, scrape_batch_id, created_at, created_by
+			) VALUES ` + strings.Join(values, ", ")
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to create shift instances: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteShiftInstances soft-deletes shift instances; one that is no longer
+// live fails with a NotFoundError
+func deleteShiftInstances(ctx context.Context, tx *sql.Tx, ids []uuid.UUID, deleterID uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	deleted, err := returnedIDs(tx.QueryContext(ctx, `
+		UPDATE shift_instances
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = ANY($1) AND deleted_at IS NULL
+		RETURNING id
+	`, pq.Array(ids), deleterID))
+	if err != nil {
+		return fmt.Errorf("failed to delete shift instances: %w", err)
+	}
+	for _, id := range ids {
+		if !deleted[id] {
+			return &repository.NotFoundError{ResourceType: "ShiftInstance", ResourceID: id.String()}
+		}
+	}
+	return nil
+}
+
 // GetByID retrieves a shift instance by ID
 func (r *ShiftInstanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ShiftInstance, error) {
 	shift := &entity.ShiftInstance{}
@@ -107,9 +210,9 @@ func (r *ShiftInstanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*e
 	query := `
 		SELECT id, schedule_version_id, hospital_id, shift_type, schedule_date,
 		       start_time, end_time, study_type, specialty_constraint, desired_coverage,
-		       is_mandatory, created_at, created_by
+		       is_mandatory, scrape_batch_id, created_at, created_by
 		FROM shift_instances
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	err := scanShiftInstance(r.db.QueryRowContext(ctx, query, id), shift)
@@ -129,16 +232,21 @@ func (r *ShiftInstanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*e
 
 // GetByScheduleVersion retrieves all shifts for a schedule version
 func (r *ShiftInstanceRepository) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.ShiftInstance, error) {
+	return shiftsByVersion(ctx, r.db, versionID)
+}
+
+// shiftsByVersion retrieves all shifts for a schedule version
+func shiftsByVersion(ctx context.Context, q querier, versionID uuid.UUID) ([]*entity.ShiftInstance, error) {
 	query := `
 		SELECT id, schedule_version_id, hospital_id, shift_type, schedule_date,
 		       start_time, end_time, study_type, specialty_constraint, desired_coverage,
-		       is_mandatory, created_at, created_by
+		       is_mandatory, scrape_batch_id, created_at, created_by
 		FROM shift_instances
-		WHERE schedule_version_id = $1
+		WHERE schedule_version_id = $1 AND deleted_at IS NULL
 		ORDER BY schedule_date ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, versionID)
+	rows, err := q.QueryContext(ctx, query, versionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query shifts by schedule version: %w", err)
 	}
@@ -161,9 +269,9 @@ func (r *ShiftInstanceRepository) GetByDateRange(ctx context.Context, versionID
 	query := `
 		SELECT id, schedule_version_id, hospital_id, shift_type, schedule_date,
 		       start_time, end_time, study_type, specialty_constraint, desired_coverage,
-		       is_mandatory, created_at, created_by
+		       is_mandatory, scrape_batch_id, created_at, created_by
 		FROM shift_instances
-		WHERE schedule_version_id = $1 AND schedule_date >= $2 AND schedule_date <= $3
+		WHERE schedule_version_id = $1 AND schedule_date >= $2 AND schedule_date <= $3 AND deleted_at IS NULL
 		ORDER BY schedule_date ASC
 	`
 
@@ -194,9 +302,9 @@ func (r *ShiftInstanceRepository) GetAllByShiftIDs(ctx context.Context, shiftIDs
 	query := `
 		SELECT id, schedule_version_id, hospital_id, shift_type, schedule_date,
 		       start_time, end_time, study_type, specialty_constraint, desired_coverage,
-		       is_mandatory, created_at, created_by
+		       is_mandatory, scrape_batch_id, created_at, created_by
 		FROM shift_instances
-		WHERE id = ANY($1)
+		WHERE id = ANY($1) AND deleted_at IS NULL
 		ORDER BY schedule_date ASC
 	`
 
@@ -218,10 +326,75 @@ func (r *ShiftInstanceRepository) GetAllByShiftIDs(ctx context.Context, shiftIDs
 	return shifts, rows.Err()
 }
 
+// Update updates a shift instance's slot, times and coverage
+func (r *ShiftInstanceRepository) Update(ctx context.Context, shift *entity.ShiftInstance) error {
+	query := `
+		UPDATE shift_instances
+		SET shift_type = $2, schedule_date = $3, start_time = $4, end_time = $5, study_type = $6,
+		    specialty_constraint = $7, desired_coverage = $8, is_mandatory = $9, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		shift.ID,
+		string(shift.ShiftType),
+		shift.ScheduleDate,
+		shift.StartTime,
+		shift.EndTime,
+		string(shift.StudyType),
+		string(shift.SpecialtyConstraint),
+		shift.DesiredCoverage,
+		shift.IsMandatory,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update shift instance: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "ShiftInstance",
+			ResourceID:   shift.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+// Delete marks a shift instance as deleted
+func (r *ShiftInstanceRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	query := `
+		UPDATE shift_instances
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deleterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete shift instance: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "ShiftInstance",
+			ResourceID:   id.String(),
+		}
+	}
+
+	return nil
+}
+
 // Count returns the total number of shifts
 func (r *ShiftInstanceRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	query := `SELECT COUNT(*) FROM shift_instances`
+	query := `SELECT COUNT(*) FROM shift_instances WHERE deleted_at IS NULL`
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count shifts: %w", err)
@@ -232,7 +405,7 @@ func (r *ShiftInstanceRepository) Count(ctx context.Context) (int64, error) {
 // CountByScheduleVersion returns the number of shifts in a schedule version
 func (r *ShiftInstanceRepository) CountByScheduleVersion(ctx context.Context, versionID uuid.UUID) (int64, error) {
 	var count int64
-	query := `SELECT COUNT(*) FROM shift_instances WHERE schedule_version_id = $1`
+	query := `SELECT COUNT(*) FROM shift_instances WHERE schedule_version_id = $1 AND deleted_at IS NULL`
 	err := r.db.QueryRowContext(ctx, query, versionID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count shifts by version: %w", err)
@@ -0,0 +1,229 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// ShiftTemplateRepository implements repository.ShiftTemplateRepository for PostgreSQL
+type ShiftTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewShiftTemplateRepository creates a new ShiftTemplateRepository
+func NewShiftTemplateRepository(db *sql.DB) *ShiftTemplateRepository {
+	return &ShiftTemplateRepository{db: db}
+}
+
+// Create creates a new shift template
+func (r *ShiftTemplateRepository) Create(ctx context.Context, template *entity.ShiftTemplate) error {
+	if template.ID == uuid.Nil {
+		template.ID = uuid.New()
+	}
+
+	variants, err := json.Marshal(template.Variants)
+	if err != nil {
+		return fmt.Errorf("failed to marshal shift template variants: %w", err)
+	}
+
+	query := `
+		INSERT INTO shift_templates (
+			id, hospital_id, name, shift_type, weekdays, variants, skip_holidays,
+			effective_from, effective_until, created_at, created_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		template.ID,
+		template.HospitalID,
+		template.Name,
+		template.ShiftType,
+		pq.Array(weekdayNumbers(template.Weekdays)),
+		variants,
+		template.SkipHolidays,
+		template.EffectiveFrom,
+		template.EffectiveUntil,
+		template.CreatedAt,
+		template.CreatedBy,
+		template.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create shift template: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a shift template by ID
+func (r *ShiftTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ShiftTemplate, error) {
+	query := `
+		SELECT id, hospital_id, name, shift_type, weekdays, variants, skip_holidays, effective_from,
+		       effective_until, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM shift_templates
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	template, err := scanShiftTemplate(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "ShiftTemplate",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift template: %w", err)
+	}
+
+	return template, nil
+}
+
+// GetByHospital retrieves all shift templates of a hospital
+func (r *ShiftTemplateRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.ShiftTemplate, error) {
+	query := `
+		SELECT id, hospital_id, name, shift_type, weekdays, variants, skip_holidays, effective_from,
+		       effective_until, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM shift_templates
+		WHERE hospital_id = $1 AND deleted_at IS NULL
+		ORDER BY lower(name)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, hospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shift templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*entity.ShiftTemplate
+	for rows.Next() {
+		template, err := scanShiftTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shift template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+// Update updates a shift template
+func (r *ShiftTemplateRepository) Update(ctx context.Context, template *entity.ShiftTemplate) error {
+	variants, err := json.Marshal(template.Variants)
+	if err != nil {
+		return fmt.Errorf("failed to marshal shift template variants: %w", err)
+	}
+
+	query := `
+		UPDATE shift_templates
+		SET name = $2, shift_type = $3, weekdays = $4, variants = $5, skip_holidays = $6,
+		    effective_from = $7, effective_until = $8, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		template.ID,
+		template.Name,
+		template.ShiftType,
+		pq.Array(weekdayNumbers(template.Weekdays)),
+		variants,
+		template.SkipHolidays,
+		template.EffectiveFrom,
+		template.EffectiveUntil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update shift template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "ShiftTemplate",
+			ResourceID:   template.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+// Delete soft-deletes a shift template
+func (r *ShiftTemplateRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	query := `
+		UPDATE shift_templates
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deleterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete shift template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "ShiftTemplate",
+			ResourceID:   id.String(),
+		}
+	}
+
+	return nil
+}
+
+func scanShiftTemplate(row rowScanner) (*entity.ShiftTemplate, error) {
+	template := &entity.ShiftTemplate{}
+	var weekdays []int64
+	var variants []byte
+	err := row.Scan(
+		&template.ID,
+		&template.HospitalID,
+		&template.Name,
+		&template.ShiftType,
+		pq.Array(&weekdays),
+		&variants,
+		&template.SkipHolidays,
+		&template.EffectiveFrom,
+		&template.EffectiveUntil,
+		&template.CreatedAt,
+		&template.CreatedBy,
+		&template.UpdatedAt,
+		&template.DeletedAt,
+		&template.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wd := range weekdays {
+		template.Weekdays = append(template.Weekdays, time.Weekday(wd))
+	}
+	if err := json.Unmarshal(variants, &template.Variants); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shift template variants: %w", err)
+	}
+	return template, nil
+}
+
+// weekdayNumbers stores weekdays as 0 (Sunday) .. 6 (Saturday)
+func weekdayNumbers(weekdays []time.Weekday) []int64 {
+	numbers := make([]int64, 0, len(weekdays))
+	for _, wd := range weekdays {
+		numbers = append(numbers, int64(wd))
+	}
+	return numbers
+}
@@ -242,14 +242,14 @@ func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 }
 
 // Delete soft-deletes a user
-func (r *UserRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+func (r *UserRepository) Delete(ctx context.Context, userID uuid.UUID, deleterID uuid.UUID) error {
 	query := `
 		UPDATE users
-		SET deleted_at = NOW()
+		SET deleted_at = NOW(), deleted_by = $2
 		WHERE id = $1
 	`
 
-	_, err := r.db.ExecContext(ctx, query, userID)
+	_, err := r.db.ExecContext(ctx, query, userID, deleterID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// WebhookDeliveryRepository implements repository.WebhookDeliveryRepository for PostgreSQL
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookDeliveryRepository creates a new WebhookDeliveryRepository
+func NewWebhookDeliveryRepository(db *sql.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+// Create creates a new webhook delivery log entry
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d *entity.WebhookDelivery) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, subscription_id, hospital_id, event_type, payload, status,
+			attempts, redelivery_of, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.ID,
+		d.SubscriptionID,
+		d.HospitalID,
+		d.EventType,
+		d.Payload,
+		string(d.Status),
+		d.Attempts,
+		d.RedeliveryOf,
+		d.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a webhook delivery by ID
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookDelivery, error) {
+	query := `
+		SELECT id, subscription_id, hospital_id, event_type, payload, status, attempts,
+			response_status, response_body, error_message, redelivery_of,
+			created_at, last_attempt_at, delivered_at
+		FROM webhook_deliveries
+		WHERE id = $1
+	`
+
+	d, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "WebhookDelivery",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return d, nil
+}
+
+// GetBySubscription retrieves the most recent deliveries for a subscription
+func (r *WebhookDeliveryRepository) GetBySubscription(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*entity.WebhookDelivery, error) {
+	query := `
+		SELECT id, subscription_id, hospital_id, event_type, payload, status, attempts,
+			response_status, response_body, error_message, redelivery_of,
+			created_at, last_attempt_at, delivered_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Update records the outcome of a delivery attempt
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d *entity.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_status = $4, response_body = $5,
+			error_message = $6, last_attempt_at = $7, delivered_at = $8
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		d.ID,
+		string(d.Status),
+		d.Attempts,
+		d.ResponseStatus,
+		d.ResponseBody,
+		d.ErrorMessage,
+		d.LastAttemptAt,
+		d.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "WebhookDelivery",
+			ResourceID:   d.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*entity.WebhookDelivery, error) {
+	d := &entity.WebhookDelivery{}
+	var status string
+	err := row.Scan(
+		&d.ID,
+		&d.SubscriptionID,
+		&d.HospitalID,
+		&d.EventType,
+		&d.Payload,
+		&status,
+		&d.Attempts,
+		&d.ResponseStatus,
+		&d.ResponseBody,
+		&d.ErrorMessage,
+		&d.RedeliveryOf,
+		&d.CreatedAt,
+		&d.LastAttemptAt,
+		&d.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.Status = entity.WebhookDeliveryStatus(status)
+	return d, nil
+}
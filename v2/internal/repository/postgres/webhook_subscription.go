@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// WebhookSubscriptionRepository implements repository.WebhookSubscriptionRepository for PostgreSQL
+type WebhookSubscriptionRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookSubscriptionRepository creates a new WebhookSubscriptionRepository
+func NewWebhookSubscriptionRepository(db *sql.DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: db}
+}
+
+// Create creates a new webhook subscription
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, sub *entity.WebhookSubscription) error {
+	if sub.ID == uuid.Nil {
+		sub.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO webhook_subscriptions (
+			id, hospital_id, url, secret, event_types, active, created_at, created_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		sub.ID,
+		sub.HospitalID,
+		sub.URL,
+		sub.Secret,
+		pq.Array(sub.EventTypes),
+		sub.Active,
+		sub.CreatedAt,
+		sub.CreatedBy,
+		sub.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a webhook subscription by ID
+func (r *WebhookSubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+	query := `
+		SELECT id, hospital_id, url, secret, event_types, active, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM webhook_subscriptions
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	sub, err := scanWebhookSubscription(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, &repository.NotFoundError{
+			ResourceType: "WebhookSubscription",
+			ResourceID:   id.String(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// GetByHospital retrieves all webhook subscriptions for a hospital
+func (r *WebhookSubscriptionRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.WebhookSubscription, error) {
+	query := `
+		SELECT id, hospital_id, url, secret, event_types, active, created_at, created_by, updated_at, deleted_at, deleted_by
+		FROM webhook_subscriptions
+		WHERE hospital_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, hospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*entity.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// Update updates a webhook subscription
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, sub *entity.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, active = $4, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		sub.ID,
+		sub.URL,
+		pq.Array(sub.EventTypes),
+		sub.Active,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "WebhookSubscription",
+			ResourceID:   sub.ID.String(),
+		}
+	}
+
+	return nil
+}
+
+// Delete soft-deletes a webhook subscription
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET deleted_at = NOW(), deleted_by = $2, active = FALSE
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deleterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &repository.NotFoundError{
+			ResourceType: "WebhookSubscription",
+			ResourceID:   id.String(),
+		}
+	}
+
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhookSubscription(row rowScanner) (*entity.WebhookSubscription, error) {
+	sub := &entity.WebhookSubscription{}
+	err := row.Scan(
+		&sub.ID,
+		&sub.HospitalID,
+		&sub.URL,
+		&sub.Secret,
+		pq.Array(&sub.EventTypes),
+		&sub.Active,
+		&sub.CreatedAt,
+		&sub.CreatedBy,
+		&sub.UpdatedAt,
+		&sub.DeletedAt,
+		&sub.DeletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
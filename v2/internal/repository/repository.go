@@ -84,6 +84,8 @@ type ScheduleVersionRepository interface {
 // ShiftInstanceRepository defines data access operations for shift instances
 type ShiftInstanceRepository interface {
 	Create(ctx context.Context, shift *entity.ShiftInstance) error
+	// CreateBatch creates many shift instances in one transaction
+	CreateBatch(ctx context.Context, shifts []*entity.ShiftInstance) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.ShiftInstance, error)
 	GetByScheduleVersion(ctx context.Context, scheduleVersionID uuid.UUID) ([]*entity.ShiftInstance, error)
 	GetByDateRange(ctx context.Context, scheduleVersionID uuid.UUID, startDate, endDate time.Time) ([]*entity.ShiftInstance, error)
@@ -107,8 +109,25 @@ type AssignmentRepository interface {
 
 	// Batch operations (no N+1 queries)
 	GetAllByShiftIDs(ctx context.Context, shiftInstanceIDs []uuid.UUID) ([]*entity.Assignment, error)
+	// ApplyChanges writes creates, updates and deletes in one transaction
+	ApplyChanges(ctx context.Context, changes *entity.AssignmentChanges, editorID uuid.UUID) error
+	// EditVersion locks a schedule version, reads its shifts and live
+	// assignments in the same transaction and writes the changes plan returns,
+	// so they are validated against the state they are applied to. A nil or
+	// empty change set writes nothing; an error from plan rolls back.
+	EditVersion(ctx context.Context, versionID uuid.UUID, editorID uuid.UUID, plan VersionEditPlan) error
+	// EditSchedule is EditVersion for plans that also add or drop shifts
+	EditSchedule(ctx context.Context, versionID uuid.UUID, editorID uuid.UUID, plan ScheduleEditPlan) error
 }
 
+// VersionEditPlan works out assignment changes from a locked version's
+// shifts and live assignments
+type VersionEditPlan func(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.AssignmentChanges, error)
+
+// ScheduleEditPlan works out shift and assignment changes from a locked
+// version's shifts and live assignments
+type ScheduleEditPlan func(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.ScheduleChanges, error)
+
 // ScrapeBatchRepository defines data access operations for scrape batches
 type ScrapeBatchRepository interface {
 	Create(ctx context.Context, batch *entity.ScrapeBatch) error
@@ -118,6 +137,37 @@ type ScrapeBatchRepository interface {
 	Update(ctx context.Context, batch *entity.ScrapeBatch) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Count(ctx context.Context) (int64, error)
+	// Rollback soft-deletes the rows the batch created, except MANUAL and OVERRIDE assignments
+	Rollback(ctx context.Context, id uuid.UUID, rolledBackBy uuid.UUID) (*entity.BatchRollback, error)
+}
+
+// ScheduleMergeRepository defines data access operations for schedule merges
+type ScheduleMergeRepository interface {
+	// Create locks the STAGING version, plans the merge from its shifts and
+	// live assignments, and stores the merge and its conflicts together with
+	// the changes it applied, in one transaction
+	Create(ctx context.Context, stagingVersionID uuid.UUID, editorID uuid.UUID, plan MergePlan) error
+	// GetByID retrieves a merge with its conflicts
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ScheduleMerge, error)
+	GetByStagingVersion(ctx context.Context, stagingVersionID uuid.UUID) ([]*entity.ScheduleMerge, error)
+	// ResolveConflict locks the STAGING version, plans the resolution from its
+	// shifts and live assignments, and records it together with the changes it
+	// applied, in one transaction; it fails with a NotFoundError unless the
+	// conflict is open
+	ResolveConflict(ctx context.Context, stagingVersionID uuid.UUID, editorID uuid.UUID, plan ConflictResolutionPlan) error
+}
+
+// MergePlan works out a merge from a locked STAGING version's shifts and live assignments
+type MergePlan func(staging *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.ScheduleMerge, *entity.ScheduleChanges, error)
+
+// ConflictResolutionPlan works out a resolved conflict and the changes it
+// makes from a locked STAGING version's shifts and live assignments
+type ConflictResolutionPlan func(staging *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.MergeConflict, *entity.ScheduleChanges, error)
+
+// ScrapedAssignmentRepository defines data access operations for the assignments each scrape batch saw
+type ScrapedAssignmentRepository interface {
+	CreateBatch(ctx context.Context, batchID uuid.UUID, assignments []entity.ScrapedAssignment) error
+	GetByBatch(ctx context.Context, batchID uuid.UUID) ([]entity.ScrapedAssignment, error)
 }
 
 // CoverageCalculationRepository defines data access operations for coverage calculations
@@ -168,6 +218,89 @@ type JobQueueRepository interface {
 	CleanupOldJobs(ctx context.Context, daysOld int) (int64, error)
 }
 
+// WebhookSubscriptionRepository defines data access operations for webhook subscriptions
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *entity.WebhookSubscription) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error)
+	GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *entity.WebhookSubscription) error
+	Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error
+}
+
+// WebhookDeliveryRepository defines data access operations for webhook delivery logs
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *entity.WebhookDelivery) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookDelivery, error)
+	GetBySubscription(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*entity.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+// EventCursorRepository remembers the last event each event consumer handled
+type EventCursorRepository interface {
+	Get(ctx context.Context, consumer string) (int64, error) // 0 if the consumer has not handled any event
+	Save(ctx context.Context, consumer string, lastEventID int64) error
+}
+
+// NotificationPreferenceRepository defines data access operations for notification preferences
+type NotificationPreferenceRepository interface {
+	GetByPerson(ctx context.Context, personID uuid.UUID) (*entity.NotificationPreference, error)
+	Upsert(ctx context.Context, preference *entity.NotificationPreference) error
+}
+
+// PendingNotificationRepository defines data access operations for digest notifications
+type PendingNotificationRepository interface {
+	Create(ctx context.Context, notification *entity.PendingNotification) error
+	GetUnsent(ctx context.Context) ([]*entity.PendingNotification, error)
+	MarkSent(ctx context.Context, ids []uuid.UUID, sentAt time.Time) error
+}
+
+// NotificationDeliveryRepository records which people a promotion's notifications reached
+type NotificationDeliveryRepository interface {
+	GetByVersionChange(ctx context.Context, oldVersionID, newVersionID uuid.UUID) ([]*entity.NotificationDelivery, error)
+	Create(ctx context.Context, delivery *entity.NotificationDelivery) error // No-op if the person is already recorded
+}
+
+// CoverageAlertRepository defines data access operations for coverage gap alerts
+type CoverageAlertRepository interface {
+	Create(ctx context.Context, alert *entity.CoverageAlert) error
+	Update(ctx context.Context, alert *entity.CoverageAlert) error
+	GetByHospital(ctx context.Context, hospitalID uuid.UUID, state entity.AlertState) ([]*entity.CoverageAlert, error) // Empty state returns all
+}
+
+// AmionSnapshotRepository defines data access operations for archived Amion pages
+type AmionSnapshotRepository interface {
+	Save(ctx context.Context, snapshot *entity.AmionPageSnapshot) error // Replaces the batch's snapshot of the same URL
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AmionPageSnapshot, error)
+	ListByBatch(ctx context.Context, batchID uuid.UUID) ([]*entity.AmionPageSnapshot, error) // Without Content
+}
+
+// AmionDivisionRepository defines data access operations for per-hospital Amion divisions
+type AmionDivisionRepository interface {
+	Create(ctx context.Context, division *entity.AmionDivision) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AmionDivision, error)
+	GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.AmionDivision, error)
+	Update(ctx context.Context, division *entity.AmionDivision) error
+	Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error
+}
+
+// AmionShiftMappingRepository defines data access operations for per-hospital Amion shift label mappings
+type AmionShiftMappingRepository interface {
+	Create(ctx context.Context, mapping *entity.AmionShiftMapping) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AmionShiftMapping, error)
+	GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.AmionShiftMapping, error)
+	Update(ctx context.Context, mapping *entity.AmionShiftMapping) error
+	Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error
+}
+
+// ShiftTemplateRepository defines data access operations for per-hospital recurring shift templates
+type ShiftTemplateRepository interface {
+	Create(ctx context.Context, template *entity.ShiftTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ShiftTemplate, error)
+	GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.ShiftTemplate, error)
+	Update(ctx context.Context, template *entity.ShiftTemplate) error
+	Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error
+}
+
 // NotFoundError represents a record not found error
 type NotFoundError struct {
 	ResourceType string
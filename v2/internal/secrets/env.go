@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultEnvPrefix prefixes the environment variables read by EnvProvider
+const DefaultEnvPrefix = "SCHEDCU_SECRET_"
+
+// EnvProvider reads secrets from environment variables. Key "password" of
+// secret "amion/st-marys" is read from SCHEDCU_SECRET_AMION_ST_MARYS__PASSWORD:
+// the name is upper-cased with every other character replaced by "_", and
+// separated from the key by a double underscore.
+type EnvProvider struct {
+	prefix  string
+	environ func() []string
+}
+
+// NewEnvProvider creates an environment variable provider
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix, environ: os.Environ}
+}
+
+// EnvVarName returns the variable holding a key of a secret
+func (p *EnvProvider) EnvVarName(name, key string) string {
+	return p.prefix + envToken(name) + "__" + envToken(key)
+}
+
+// Get implements Provider
+func (p *EnvProvider) Get(ctx context.Context, name string) (Secret, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	varPrefix := p.prefix + envToken(name) + "__"
+	secret := Secret{}
+	for _, kv := range p.environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, varPrefix) {
+			continue
+		}
+		secret[strings.ToLower(strings.TrimPrefix(key, varPrefix))] = value
+	}
+
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("%w: %s (no %s* variables)", ErrNotFound, name, varPrefix)
+	}
+	return secret, nil
+}
+
+// envToken upper-cases s and replaces anything but letters and digits with "_"
+func envToken(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,151 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MasterKeySize is the AES-256 key length in bytes
+const MasterKeySize = 32
+
+// fileFormatVersion identifies the encrypted file layout
+const fileFormatVersion = 1
+
+// encryptedFile is the on-disk envelope. The plaintext is a JSON object mapping
+// secret names to their key/value pairs.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Nonce      string `json:"nonce"`      // Base64
+	Ciphertext string `json:"ciphertext"` // Base64 AES-256-GCM output
+}
+
+// FileProvider serves secrets from a local file encrypted with AES-256-GCM
+// under a master key. The file is decrypted once, when the provider is created.
+type FileProvider struct {
+	secrets map[string]Secret
+}
+
+// ParseMasterKey decodes a 32-byte master key given as base64 or hex
+func ParseMasterKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, errors.New("master key is empty")
+	}
+
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == MasterKeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == MasterKeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("master key must be %d bytes, base64 or hex encoded", MasterKeySize)
+}
+
+// NewFileProvider decrypts the secrets file at path
+func NewFileProvider(path string, masterKey []byte) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var envelope encryptedFile
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if envelope.Version != fileFormatVersion {
+		return nil, fmt.Errorf("unsupported secrets file version %d", envelope.Version)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(envelope.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce in secrets file: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext in secrets file: %w", err)
+	}
+
+	gcm, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d in secrets file", len(nonce))
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt secrets file: wrong master key or corrupted file")
+	}
+
+	var secrets map[string]Secret
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted secrets: %w", err)
+	}
+	return &FileProvider{secrets: secrets}, nil
+}
+
+// Get implements Provider
+func (p *FileProvider) Get(ctx context.Context, name string) (Secret, error) {
+	secret, ok := p.secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	copied := make(Secret, len(secret))
+	for k, v := range secret {
+		copied[k] = v
+	}
+	return copied, nil
+}
+
+// WriteEncryptedFile encrypts secrets under the master key and writes them to
+// path (mode 0600), replacing any existing file
+func WriteEncryptedFile(path string, masterKey []byte, secrets map[string]Secret) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	gcm, err := newGCM(masterKey)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    fileFormatVersion,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil)),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets file: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return nil
+}
+
+func newGCM(masterKey []byte) (cipher.AEAD, error) {
+	if len(masterKey) != MasterKeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", MasterKeySize, len(masterKey))
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
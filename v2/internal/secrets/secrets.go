@@ -0,0 +1,138 @@
+// Package secrets resolves named secrets (Amion logins, database credentials)
+// from a pluggable provider, so that job payloads and logs only ever carry the
+// secret's name.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned (wrapped) when a secret doesn't exist
+var ErrNotFound = errors.New("secret not found")
+
+// Secret is a named set of key/value pairs, e.g. {"username": ..., "password": ...}
+type Secret map[string]string
+
+// String redacts the values so a secret can't leak through logging
+func (s Secret) String() string {
+	return fmt.Sprintf("secret(%d keys)", len(s))
+}
+
+// GoString redacts the values for %#v as well
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// Provider looks up secrets by name. Names are slash-separated paths such as
+// "amion/3f6c...".
+type Provider interface {
+	Get(ctx context.Context, name string) (Secret, error)
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*(/[A-Za-z0-9_.-]+)*$`)
+
+// ValidateName checks that a secret name is a plain slash-separated path
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	return nil
+}
+
+// AmionCredentialsName is the conventional name of a hospital's Amion login
+func AmionCredentialsName(hospitalID uuid.UUID) string {
+	return "amion/" + hospitalID.String()
+}
+
+// Credentials is a username/password pair
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// String redacts the password
+func (c Credentials) String() string {
+	return fmt.Sprintf("%s:REDACTED", c.Username)
+}
+
+// GoString redacts the password for %#v as well
+func (c Credentials) GoString() string {
+	return c.String()
+}
+
+// LoadCredentials reads a secret holding "username" and "password" keys
+func LoadCredentials(ctx context.Context, provider Provider, name string) (*Credentials, error) {
+	secret, err := provider.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := &Credentials{Username: secret["username"], Password: secret["password"]}
+	if creds.Username == "" || creds.Password == "" {
+		return nil, fmt.Errorf("secret %q must have username and password", name)
+	}
+	return creds, nil
+}
+
+// DatabaseURL resolves the database connection string from a secret. The secret
+// either holds the full connection string under "url", or "username" and
+// "password" that are applied to baseURL.
+func DatabaseURL(ctx context.Context, provider Provider, name, baseURL string) (string, error) {
+	secret, err := provider.Get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if full := secret["url"]; full != "" {
+		return full, nil
+	}
+
+	if baseURL == "" {
+		return "", fmt.Errorf("secret %q has no url and no base database URL is set", name)
+	}
+	if secret["username"] == "" {
+		return "", fmt.Errorf("secret %q must have url or username", name)
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base database URL: %w", err)
+	}
+	if secret["password"] != "" {
+		u.User = url.UserPassword(secret["username"], secret["password"])
+	} else {
+		u.User = url.User(secret["username"])
+	}
+	return u.String(), nil
+}
+
+// NewProviderFromEnv builds the provider selected by SECRETS_PROVIDER:
+//   - "env" (default): SCHEDCU_SECRET_* environment variables, see EnvProvider
+//   - "file": the AES-GCM encrypted file at SECRETS_FILE, key in SECRETS_MASTER_KEY
+//   - "vault": Vault KV v2 at VAULT_ADDR with VAULT_TOKEN, mount VAULT_KV_MOUNT (default "secret")
+func NewProviderFromEnv() (Provider, error) {
+	switch kind := os.Getenv("SECRETS_PROVIDER"); kind {
+	case "", "env":
+		return NewEnvProvider(DefaultEnvPrefix), nil
+	case "file":
+		key, err := ParseMasterKey(os.Getenv("SECRETS_MASTER_KEY"))
+		if err != nil {
+			return nil, err
+		}
+		return NewFileProvider(os.Getenv("SECRETS_FILE"), key)
+	case "vault":
+		return NewVaultProvider(VaultConfig{
+			Address:   os.Getenv("VAULT_ADDR"),
+			Token:     os.Getenv("VAULT_TOKEN"),
+			Mount:     os.Getenv("VAULT_KV_MOUNT"),
+			Namespace: os.Getenv("VAULT_NAMESPACE"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q (expected env, file or vault)", kind)
+	}
+}
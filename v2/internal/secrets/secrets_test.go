@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEnvProvider(vars ...string) *EnvProvider {
+	p := NewEnvProvider(DefaultEnvPrefix)
+	p.environ = func() []string { return vars }
+	return p
+}
+
+func TestEnvProvider_Get(t *testing.T) {
+	hospitalID := uuid.MustParse("3f6c8a2e-1b2d-4c5e-8f90-a1b2c3d4e5f6")
+	name := AmionCredentialsName(hospitalID)
+	p := newTestEnvProvider(
+		"SCHEDCU_SECRET_AMION_3F6C8A2E_1B2D_4C5E_8F90_A1B2C3D4E5F6__USERNAME=scheduler",
+		"SCHEDCU_SECRET_AMION_3F6C8A2E_1B2D_4C5E_8F90_A1B2C3D4E5F6__PASSWORD=hunter2",
+		"SCHEDCU_SECRET_AMION_OTHER__PASSWORD=nope",
+		"PATH=/usr/bin",
+	)
+
+	assert.Equal(t, "SCHEDCU_SECRET_AMION_3F6C8A2E_1B2D_4C5E_8F90_A1B2C3D4E5F6__PASSWORD", p.EnvVarName(name, "password"))
+
+	secret, err := p.Get(context.Background(), name)
+	require.NoError(t, err)
+	assert.Equal(t, Secret{"username": "scheduler", "password": "hunter2"}, secret)
+
+	_, err = p.Get(context.Background(), "amion/missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = p.Get(context.Background(), "../etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestFileProvider_RoundTrip(t *testing.T) {
+	key := make([]byte, MasterKeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	require.NoError(t, WriteEncryptedFile(path, key, map[string]Secret{
+		"amion/st-marys": {"username": "scheduler", "password": "hunter2"},
+		"database":       {"url": "postgres://app:pw@db/schedcu"},
+	}))
+
+	p, err := NewFileProvider(path, key)
+	require.NoError(t, err)
+
+	creds, err := LoadCredentials(context.Background(), p, "amion/st-marys")
+	require.NoError(t, err)
+	assert.Equal(t, "scheduler", creds.Username)
+	assert.Equal(t, "hunter2", creds.Password)
+
+	_, err = p.Get(context.Background(), "amion/unknown")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// Callers can't modify the provider's copy
+	secret, err := p.Get(context.Background(), "database")
+	require.NoError(t, err)
+	secret["url"] = "changed"
+	again, err := p.Get(context.Background(), "database")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app:pw@db/schedcu", again["url"])
+
+	wrongKey := make([]byte, MasterKeySize)
+	_, err = NewFileProvider(path, wrongKey)
+	assert.ErrorContains(t, err, "wrong master key")
+}
+
+func TestParseMasterKey(t *testing.T) {
+	raw := make([]byte, MasterKeySize)
+	raw[0] = 0xab
+
+	key, err := ParseMasterKey(hex.EncodeToString(raw))
+	require.NoError(t, err)
+	assert.Equal(t, raw, key)
+
+	_, err = ParseMasterKey("too-short")
+	assert.Error(t, err)
+	_, err = ParseMasterKey("")
+	assert.Error(t, err)
+}
+
+func TestLoadCredentials_MissingPassword(t *testing.T) {
+	p := newTestEnvProvider("SCHEDCU_SECRET_AMION_X__USERNAME=scheduler")
+
+	_, err := LoadCredentials(context.Background(), p, "amion/x")
+	assert.ErrorContains(t, err, "must have username and password")
+}
+
+func TestDatabaseURL(t *testing.T) {
+	ctx := context.Background()
+
+	full := newTestEnvProvider("SCHEDCU_SECRET_DATABASE__URL=postgres://a:b@db/full")
+	got, err := DatabaseURL(ctx, full, "database", "postgres://ignored/db")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://a:b@db/full", got)
+
+	userPass := newTestEnvProvider(
+		"SCHEDCU_SECRET_DATABASE__USERNAME=app",
+		"SCHEDCU_SECRET_DATABASE__PASSWORD=p@ss",
+	)
+	got, err = DatabaseURL(ctx, userPass, "database", "postgres://db:5432/schedcu?sslmode=disable")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app:p%40ss@db:5432/schedcu?sslmode=disable", got)
+
+	_, err = DatabaseURL(ctx, userPass, "database", "")
+	assert.Error(t, err)
+}
+
+func TestRedaction(t *testing.T) {
+	secret := Secret{"password": "hunter2"}
+	creds := Credentials{Username: "scheduler", Password: "hunter2"}
+
+	for _, s := range []string{
+		fmt.Sprintf("%v %+v %#v %s", secret, secret, secret, secret),
+		fmt.Sprintf("%v %+v %#v %s", creds, creds, creds, creds),
+	} {
+		assert.NotContains(t, s, "hunter2")
+	}
+}
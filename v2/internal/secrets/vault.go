@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultConfig configures a VaultProvider
+type VaultConfig struct {
+	Address    string       // e.g. https://vault.internal:8200
+	Token      string       // Sent as X-Vault-Token
+	Mount      string       // KV v2 mount, default "secret"
+	Namespace  string       // Optional, sent as X-Vault-Namespace
+	HTTPClient *http.Client // Optional, default has a 10s timeout
+}
+
+// VaultProvider reads secrets from a HashiCorp Vault KV version 2 engine over
+// its HTTP API (GET /v1/<mount>/data/<name>)
+type VaultProvider struct {
+	address   *url.URL
+	token     string
+	mount     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVaultProvider creates a Vault KV v2 provider
+func NewVaultProvider(config VaultConfig) (*VaultProvider, error) {
+	if config.Address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if config.Token == "" {
+		return nil, errors.New("vault token is required")
+	}
+	address, err := url.Parse(strings.TrimRight(config.Address, "/"))
+	if err != nil || address.Scheme == "" || address.Host == "" {
+		return nil, fmt.Errorf("invalid vault address %q", config.Address)
+	}
+
+	mount := strings.Trim(config.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &VaultProvider{
+		address:   address,
+		token:     config.Token,
+		mount:     mount,
+		namespace: config.Namespace,
+		client:    client,
+	}, nil
+}
+
+// vaultKVResponse is the part of a KV v2 read response we use
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// Get implements Provider
+func (p *VaultProvider) Get(ctx context.Context, name string) (Secret, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	endpoint := p.address.JoinPath("v1", p.mount, "data", name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	case resp.StatusCode != http.StatusOK:
+		// Vault error bodies only hold messages, never secret data
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vault returned HTTP %d for %s: %s", resp.StatusCode, name, strings.TrimSpace(string(body)))
+	}
+
+	var parsed vaultKVResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response for %s: %w", name, err)
+	}
+	if parsed.Data.Data == nil {
+		// A deleted (soft-deleted) version has no data
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	secret := make(Secret, len(parsed.Data.Data))
+	for k, v := range parsed.Data.Data {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("vault secret %s key %q is not a string", name, k)
+		}
+		secret[k] = s
+	}
+	return secret, nil
+}
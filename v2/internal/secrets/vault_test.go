@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/amion/st-marys":
+			assert.Equal(t, "team-a", r.Header.Get("X-Vault-Namespace"))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"data":{"username":"scheduler","password":"hunter2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/data/amion/deleted":
+			w.Write([]byte(`{"data":{"data":null,"metadata":{"deletion_time":"2026-01-01T00:00:00Z"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewVaultProvider(VaultConfig{
+		Address:   server.URL + "/",
+		Token:     "test-token",
+		Mount:     "kv",
+		Namespace: "team-a",
+	})
+	require.NoError(t, err)
+
+	creds, err := LoadCredentials(context.Background(), p, "amion/st-marys")
+	require.NoError(t, err)
+	assert.Equal(t, "scheduler", creds.Username)
+	assert.Equal(t, "hunter2", creds.Password)
+
+	_, err = p.Get(context.Background(), "amion/missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = p.Get(context.Background(), "amion/deleted")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestVaultProvider_PermissionDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+	}))
+	defer server.Close()
+
+	p, err := NewVaultProvider(VaultConfig{Address: server.URL, Token: "bad"})
+	require.NoError(t, err)
+
+	_, err = p.Get(context.Background(), "amion/st-marys")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+	assert.Contains(t, err.Error(), "HTTP 403")
+}
+
+func TestNewVaultProvider_RequiresAddressAndToken(t *testing.T) {
+	_, err := NewVaultProvider(VaultConfig{Token: "t"})
+	assert.Error(t, err)
+	_, err = NewVaultProvider(VaultConfig{Address: "http://vault:8200"})
+	assert.Error(t, err)
+}
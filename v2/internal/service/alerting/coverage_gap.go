@@ -0,0 +1,350 @@
+// Package alerting watches upcoming PRODUCTION schedules for mandatory shifts that
+// are not fully staffed, and escalates alerts as those shifts approach.
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service/coverage"
+)
+
+// Alert actions carried in TypeCoverageGapAlert events
+const (
+	ActionRaised    = "raised"
+	ActionEscalated = "escalated"
+	ActionResolved  = "resolved"
+)
+
+// EscalationPolicy decides the alert tier from the time left until the shift starts
+type EscalationPolicy struct {
+	ChiefWithin time.Duration // Escalate to the chief when the shift starts within this window
+	AdminWithin time.Duration // Escalate to admins when the shift starts within this window
+}
+
+// DefaultEscalationPolicy alerts schedulers first, the chief within 3 days and admins within 1 day
+var DefaultEscalationPolicy = EscalationPolicy{
+	ChiefWithin: 72 * time.Hour,
+	AdminWithin: 24 * time.Hour,
+}
+
+// TierFor returns the tier for a gap whose shift starts in untilStart
+func (p EscalationPolicy) TierFor(untilStart time.Duration) entity.AlertTier {
+	switch {
+	case untilStart <= p.AdminWithin:
+		return entity.AlertTierAdmin
+	case untilStart <= p.ChiefWithin:
+		return entity.AlertTierChief
+	default:
+		return entity.AlertTierScheduler
+	}
+}
+
+// Config controls the coverage gap scan
+type Config struct {
+	Horizon time.Duration // How far ahead to look for gaps
+	Policy  EscalationPolicy
+}
+
+// DefaultConfig scans the next 14 days with the default escalation policy
+var DefaultConfig = Config{
+	Horizon: 14 * 24 * time.Hour,
+	Policy:  DefaultEscalationPolicy,
+}
+
+// ScanResult summarises one scan across all hospitals
+type ScanResult struct {
+	Hospitals int
+	Gaps      int
+	Raised    int
+	Escalated int
+	Resolved  int
+}
+
+// CoverageGapMonitor raises, escalates and resolves coverage gap alerts
+type CoverageGapMonitor struct {
+	hospitals   repository.HospitalRepository
+	versions    repository.ScheduleVersionRepository
+	shifts      repository.ShiftInstanceRepository
+	assignments repository.AssignmentRepository
+	alerts      repository.CoverageAlertRepository
+	events      event.Publisher // Optional
+	config      Config
+	now         func() time.Time
+}
+
+// NewCoverageGapMonitor creates a coverage gap monitor
+func NewCoverageGapMonitor(
+	hospitals repository.HospitalRepository,
+	versions repository.ScheduleVersionRepository,
+	shifts repository.ShiftInstanceRepository,
+	assignments repository.AssignmentRepository,
+	alerts repository.CoverageAlertRepository,
+	config Config,
+) *CoverageGapMonitor {
+	return &CoverageGapMonitor{
+		hospitals:   hospitals,
+		versions:    versions,
+		shifts:      shifts,
+		assignments: assignments,
+		alerts:      alerts,
+		config:      config,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetEventPublisher publishes TypeCoverageGapAlert events for every alert change
+func (m *CoverageGapMonitor) SetEventPublisher(events event.Publisher) {
+	m.events = events
+}
+
+// gap is an under-staffed mandatory shift found by a scan
+type gap struct {
+	shift  *entity.ShiftInstance
+	start  time.Time
+	detail coverage.CoverageDetail
+}
+
+// Scan checks every hospital. A failing hospital is logged and does not stop the others.
+func (m *CoverageGapMonitor) Scan(ctx context.Context) (*ScanResult, error) {
+	hospitals, err := m.hospitals.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hospitals: %w", err)
+	}
+
+	result := &ScanResult{}
+	var errs []error
+	for _, hospital := range hospitals {
+		if err := m.scanHospital(ctx, hospital.ID, result); err != nil {
+			log.Printf("Coverage gap scan failed for hospital %s: %v", hospital.ID, err)
+			errs = append(errs, fmt.Errorf("hospital %s: %w", hospital.ID, err))
+			continue
+		}
+		result.Hospitals++
+	}
+
+	return result, errors.Join(errs...)
+}
+
+func (m *CoverageGapMonitor) scanHospital(ctx context.Context, hospitalID uuid.UUID, result *ScanResult) error {
+	now := m.now()
+	gaps, covered, err := m.findGaps(ctx, hospitalID, now)
+	if err != nil {
+		return err
+	}
+	result.Gaps += len(gaps)
+
+	open, err := m.alerts.GetByHospital(ctx, hospitalID, entity.AlertStateOpen)
+	if err != nil {
+		return fmt.Errorf("failed to load open alerts: %w", err)
+	}
+	openByKey := make(map[string]*entity.CoverageAlert, len(open))
+	for _, alert := range open {
+		openByKey[alert.GapKey] = alert
+	}
+
+	keys := make([]string, 0, len(gaps))
+	for key := range gaps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		g := gaps[key]
+		tier := m.config.Policy.TierFor(g.start.Sub(now))
+
+		alert, exists := openByKey[key]
+		if !exists {
+			alert = &entity.CoverageAlert{
+				ID:         uuid.New(),
+				HospitalID: hospitalID,
+				GapKey:     key,
+				ShiftType:  g.shift.ShiftType,
+				StudyType:  g.shift.StudyType,
+				ShiftStart: g.start,
+				Tier:       tier,
+				State:      entity.AlertStateOpen,
+				CreatedAt:  now,
+			}
+		}
+		alert.ScheduleVersionID = g.shift.ScheduleVersionID
+		alert.ShiftInstanceID = g.shift.ID
+		alert.CoverageStatus = string(g.detail.Status)
+		alert.Required = g.detail.Required
+		alert.Assigned = g.detail.Assigned
+		alert.UpdatedAt = now
+
+		if !exists {
+			if err := m.alerts.Create(ctx, alert); err != nil {
+				return fmt.Errorf("failed to create alert: %w", err)
+			}
+			result.Raised++
+			m.publish(ctx, alert, ActionRaised)
+			continue
+		}
+
+		// Tiers only move up, so a gap that was already escalated stays escalated
+		escalated := tier.Rank() > alert.Tier.Rank()
+		if escalated {
+			alert.Tier = tier
+			alert.EscalatedAt = &now
+		}
+		if err := m.alerts.Update(ctx, alert); err != nil {
+			return fmt.Errorf("failed to update alert: %w", err)
+		}
+		if escalated {
+			result.Escalated++
+			m.publish(ctx, alert, ActionEscalated)
+		}
+	}
+
+	for key, alert := range openByKey {
+		if _, stillOpen := gaps[key]; stillOpen {
+			continue
+		}
+
+		switch {
+		case covered[key]:
+			alert.ResolutionReason = entity.AlertResolvedFilled
+		case !alert.ShiftStart.After(now):
+			alert.ResolutionReason = entity.AlertResolvedExpired
+		default:
+			alert.ResolutionReason = entity.AlertResolvedRemoved
+		}
+		alert.State = entity.AlertStateResolved
+		alert.ResolvedAt = &now
+		alert.UpdatedAt = now
+
+		if err := m.alerts.Update(ctx, alert); err != nil {
+			return fmt.Errorf("failed to resolve alert: %w", err)
+		}
+		result.Resolved++
+		m.publish(ctx, alert, ActionResolved)
+	}
+
+	return nil
+}
+
+// findGaps returns under-staffed mandatory shifts starting within the horizon, keyed by
+// gap key, plus the keys of mandatory shifts in the window that are fully covered
+func (m *CoverageGapMonitor) findGaps(ctx context.Context, hospitalID uuid.UUID, now time.Time) (map[string]gap, map[string]bool, error) {
+	versions, err := m.versions.GetByHospitalAndStatus(ctx, hospitalID, entity.VersionStatusProduction)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load production versions: %w", err)
+	}
+
+	windowEnd := now.Add(m.config.Horizon)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	gaps := make(map[string]gap)
+	covered := make(map[string]bool)
+
+	for _, version := range versions {
+		shifts, err := m.shifts.GetByDateRange(ctx, version.ID, today, windowEnd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load shifts for version %s: %w", version.ID, err)
+		}
+
+		var upcoming []*entity.ShiftInstance
+		var ids []uuid.UUID
+		for _, shift := range shifts {
+			start := shiftStart(shift)
+			if !shift.IsMandatory || !start.After(now) || start.After(windowEnd) {
+				continue
+			}
+			upcoming = append(upcoming, shift)
+			ids = append(ids, shift.ID)
+		}
+		if len(upcoming) == 0 {
+			continue
+		}
+
+		assignments, err := m.assignments.GetAllByShiftIDs(ctx, ids)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load assignments for version %s: %w", version.ID, err)
+		}
+		byShift := make(map[uuid.UUID][]*entity.Assignment)
+		for _, a := range assignments {
+			byShift[a.ShiftInstanceID] = append(byShift[a.ShiftInstanceID], a)
+		}
+
+		for _, shift := range upcoming {
+			detail := shiftCoverage(shift, byShift[shift.ID])
+			key := gapKey(shift)
+			if detail.Status == coverage.StatusFull {
+				covered[key] = true
+				continue
+			}
+			gaps[key] = gap{shift: shift, start: shiftStart(shift), detail: detail}
+		}
+	}
+
+	return gaps, covered, nil
+}
+
+// shiftCoverage resolves coverage for a single shift instance
+func shiftCoverage(shift *entity.ShiftInstance, assignments []*entity.Assignment) coverage.CoverageDetail {
+	// ResolveCoverage groups by OriginalShiftType, so tag each assignment with this shift's type
+	tagged := make([]entity.Assignment, 0, len(assignments))
+	for _, a := range assignments {
+		t := *a
+		t.OriginalShiftType = string(shift.ShiftType)
+		tagged = append(tagged, t)
+	}
+
+	metrics := coverage.ResolveCoverage(tagged, map[entity.ShiftType]int{shift.ShiftType: shift.DesiredCoverage})
+	return metrics.CoverageByShiftType[shift.ShiftType]
+}
+
+// gapKey identifies a shift slot across schedule versions
+func gapKey(shift *entity.ShiftInstance) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", shift.ScheduleDate.Format("2006-01-02"), shift.ShiftType, shift.StudyType, shift.StartTime, shift.EndTime)
+}
+
+// shiftStart combines the schedule date with the HH:MM start time (UTC)
+func shiftStart(shift *entity.ShiftInstance) time.Time {
+	date := time.Date(shift.ScheduleDate.Year(), shift.ScheduleDate.Month(), shift.ScheduleDate.Day(), 0, 0, 0, 0, time.UTC)
+	t, err := time.Parse("15:04", shift.StartTime)
+	if err != nil {
+		return date
+	}
+	return date.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+}
+
+func (m *CoverageGapMonitor) publish(ctx context.Context, alert *entity.CoverageAlert, action string) {
+	log.Printf("Coverage gap alert %s: hospital=%s, shift=%s %s, tier=%s, assigned=%d/%d",
+		action, alert.HospitalID, alert.ShiftStart.Format("2006-01-02 15:04"), alert.ShiftType, alert.Tier, alert.Assigned, alert.Required)
+
+	if m.events == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"action":              action,
+		"alert_id":            alert.ID.String(),
+		"tier":                string(alert.Tier),
+		"schedule_version_id": alert.ScheduleVersionID.String(),
+		"shift_instance_id":   alert.ShiftInstanceID.String(),
+		"shift_type":          string(alert.ShiftType),
+		"study_type":          string(alert.StudyType),
+		"shift_start":         alert.ShiftStart,
+		"coverage_status":     alert.CoverageStatus,
+		"required":            alert.Required,
+		"assigned":            alert.Assigned,
+	}
+	if alert.ResolutionReason != "" {
+		data["resolution_reason"] = alert.ResolutionReason
+	}
+
+	if err := m.events.Publish(ctx, event.New(event.TypeCoverageGapAlert, alert.HospitalID, data)); err != nil {
+		log.Printf("Failed to publish coverage gap alert: %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The mocks embed the repository interfaces and implement only what the monitor calls
+
+type mockHospitalRepo struct {
+	repository.HospitalRepository
+	hospitals []*entity.Hospital
+}
+
+func (m *mockHospitalRepo) GetAll(ctx context.Context) ([]*entity.Hospital, error) {
+	return m.hospitals, nil
+}
+
+type mockVersionRepo struct {
+	repository.ScheduleVersionRepository
+	versions []*entity.ScheduleVersion
+}
+
+func (m *mockVersionRepo) GetByHospitalAndStatus(ctx context.Context, hospitalID uuid.UUID, status entity.VersionStatus) ([]*entity.ScheduleVersion, error) {
+	var result []*entity.ScheduleVersion
+	for _, v := range m.versions {
+		if v.HospitalID == hospitalID && v.Status == status {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+type mockShiftRepo struct {
+	repository.ShiftInstanceRepository
+	shifts []*entity.ShiftInstance
+}
+
+func (m *mockShiftRepo) GetByDateRange(ctx context.Context, versionID uuid.UUID, startDate, endDate time.Time) ([]*entity.ShiftInstance, error) {
+	var result []*entity.ShiftInstance
+	for _, s := range m.shifts {
+		if s.ScheduleVersionID == versionID && !s.ScheduleDate.Before(startDate) && !s.ScheduleDate.After(endDate) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+type mockAssignmentRepo struct {
+	repository.AssignmentRepository
+	assignments []*entity.Assignment
+}
+
+func (m *mockAssignmentRepo) GetAllByShiftIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Assignment, error) {
+	wanted := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	var result []*entity.Assignment
+	for _, a := range m.assignments {
+		if wanted[a.ShiftInstanceID] {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+type mockAlertRepo struct {
+	alerts map[uuid.UUID]*entity.CoverageAlert
+}
+
+func (m *mockAlertRepo) Create(ctx context.Context, alert *entity.CoverageAlert) error {
+	m.alerts[alert.ID] = alert
+	return nil
+}
+
+func (m *mockAlertRepo) Update(ctx context.Context, alert *entity.CoverageAlert) error {
+	if _, ok := m.alerts[alert.ID]; !ok {
+		return &repository.NotFoundError{ResourceType: "CoverageAlert", ResourceID: alert.ID.String()}
+	}
+	m.alerts[alert.ID] = alert
+	return nil
+}
+
+func (m *mockAlertRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID, state entity.AlertState) ([]*entity.CoverageAlert, error) {
+	var result []*entity.CoverageAlert
+	for _, a := range m.alerts {
+		if a.HospitalID == hospitalID && (state == "" || a.State == state) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+type monitorFixture struct {
+	monitor     *CoverageGapMonitor
+	hospitalID  uuid.UUID
+	version     *entity.ScheduleVersion
+	versions    *mockVersionRepo
+	shifts      *mockShiftRepo
+	assignments *mockAssignmentRepo
+	alerts      *mockAlertRepo
+	bus         *event.MemoryBus
+	clock       time.Time
+}
+
+func newMonitorFixture(t *testing.T) *monitorFixture {
+	f := &monitorFixture{
+		hospitalID:  uuid.New(),
+		versions:    &mockVersionRepo{},
+		shifts:      &mockShiftRepo{},
+		assignments: &mockAssignmentRepo{},
+		alerts:      &mockAlertRepo{alerts: map[uuid.UUID]*entity.CoverageAlert{}},
+		bus:         event.NewMemoryBus(event.DefaultRetention),
+		clock:       time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+	t.Cleanup(func() { f.bus.Close() })
+
+	f.version = f.addVersion()
+	f.monitor = NewCoverageGapMonitor(
+		&mockHospitalRepo{hospitals: []*entity.Hospital{{ID: f.hospitalID}}},
+		f.versions,
+		f.shifts,
+		f.assignments,
+		f.alerts,
+		DefaultConfig,
+	)
+	f.monitor.SetEventPublisher(f.bus)
+	f.monitor.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *monitorFixture) addVersion() *entity.ScheduleVersion {
+	v := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: f.hospitalID, Status: entity.VersionStatusProduction}
+	f.versions.versions = append(f.versions.versions, v)
+	return v
+}
+
+func (f *monitorFixture) addShift(version *entity.ScheduleVersion, date time.Time, desired int, mandatory bool) *entity.ShiftInstance {
+	s := &entity.ShiftInstance{
+		ID:                uuid.New(),
+		ScheduleVersionID: version.ID,
+		HospitalID:        f.hospitalID,
+		ShiftType:         entity.ShiftTypeON1,
+		StudyType:         entity.StudyTypeBodyImaging,
+		ScheduleDate:      date,
+		StartTime:         "17:00",
+		EndTime:           "07:00",
+		DesiredCoverage:   desired,
+		IsMandatory:       mandatory,
+	}
+	f.shifts.shifts = append(f.shifts.shifts, s)
+	return s
+}
+
+func (f *monitorFixture) assign(shift *entity.ShiftInstance) {
+	f.assignments.assignments = append(f.assignments.assignments, &entity.Assignment{
+		ID:              uuid.New(),
+		PersonID:        uuid.New(),
+		ShiftInstanceID: shift.ID,
+		ScheduleDate:    shift.ScheduleDate,
+	})
+}
+
+func (f *monitorFixture) openAlerts(t *testing.T) []*entity.CoverageAlert {
+	alerts, err := f.alerts.GetByHospital(context.Background(), f.hospitalID, entity.AlertStateOpen)
+	require.NoError(t, err)
+	return alerts
+}
+
+func day(offset int) time.Time {
+	return time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, offset)
+}
+
+func TestEscalationPolicy_TierFor(t *testing.T) {
+	p := DefaultEscalationPolicy
+	assert.Equal(t, entity.AlertTierScheduler, p.TierFor(5*24*time.Hour))
+	assert.Equal(t, entity.AlertTierChief, p.TierFor(72*time.Hour))
+	assert.Equal(t, entity.AlertTierChief, p.TierFor(30*time.Hour))
+	assert.Equal(t, entity.AlertTierAdmin, p.TierFor(24*time.Hour))
+	assert.Equal(t, entity.AlertTierAdmin, p.TierFor(time.Hour))
+}
+
+func TestScan_RaisesAlertsForUnderstaffedMandatoryShifts(t *testing.T) {
+	f := newMonitorFixture(t)
+
+	uncovered := f.addShift(f.version, day(5), 1, true)
+	partial := f.addShift(f.version, day(6), 2, true)
+	f.assign(partial)
+	full := f.addShift(f.version, day(7), 1, true)
+	f.assign(full)
+	f.addShift(f.version, day(8), 1, false) // Not mandatory
+	f.addShift(f.version, day(30), 1, true) // Beyond the horizon
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := f.bus.Subscribe(ctx, f.hospitalID, 0)
+	require.NoError(t, err)
+
+	result, err := f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Hospitals)
+	assert.Equal(t, 2, result.Gaps)
+	assert.Equal(t, 2, result.Raised)
+
+	byShift := map[uuid.UUID]*entity.CoverageAlert{}
+	for _, a := range f.openAlerts(t) {
+		byShift[a.ShiftInstanceID] = a
+	}
+	require.Len(t, byShift, 2)
+	assert.Equal(t, "UNCOVERED", byShift[uncovered.ID].CoverageStatus)
+	assert.Equal(t, "PARTIAL", byShift[partial.ID].CoverageStatus)
+	assert.Equal(t, 1, byShift[partial.ID].Assigned)
+	assert.Equal(t, entity.AlertTierScheduler, byShift[uncovered.ID].Tier)
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, event.TypeCoverageGapAlert, evt.Type)
+		assert.Equal(t, ActionRaised, evt.Data["action"])
+	case <-time.After(time.Second):
+		t.Fatal("expected a coverage gap alert event")
+	}
+}
+
+func TestScan_DeduplicatesAcrossScansAndVersions(t *testing.T) {
+	f := newMonitorFixture(t)
+	f.addShift(f.version, day(5), 1, true)
+
+	_, err := f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+
+	result, err := f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Raised)
+	assert.Len(t, f.openAlerts(t), 1)
+
+	// A newly promoted version has its own shift IDs for the same slot
+	f.version.Status = entity.VersionStatusArchived
+	next := f.addVersion()
+	shift := f.addShift(next, day(5), 1, true)
+
+	result, err = f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Raised)
+	assert.Equal(t, 0, result.Resolved)
+
+	open := f.openAlerts(t)
+	require.Len(t, open, 1)
+	assert.Equal(t, shift.ID, open[0].ShiftInstanceID)
+	assert.Equal(t, next.ID, open[0].ScheduleVersionID)
+}
+
+func TestScan_EscalatesAsShiftApproaches(t *testing.T) {
+	f := newMonitorFixture(t)
+	f.addShift(f.version, day(5), 1, true) // Starts 2025-11-06 17:00
+
+	_, err := f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, entity.AlertTierScheduler, f.openAlerts(t)[0].Tier)
+
+	f.clock = time.Date(2025, 11, 4, 17, 0, 0, 0, time.UTC) // 48h out
+	result, err := f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Escalated)
+	alert := f.openAlerts(t)[0]
+	assert.Equal(t, entity.AlertTierChief, alert.Tier)
+	require.NotNil(t, alert.EscalatedAt)
+
+	f.clock = time.Date(2025, 11, 6, 5, 0, 0, 0, time.UTC) // 12h out
+	result, err = f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Escalated)
+	assert.Equal(t, entity.AlertTierAdmin, f.openAlerts(t)[0].Tier)
+
+	// Rescanning at the same tier is not another escalation
+	result, err = f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Escalated)
+}
+
+func TestScan_ResolvesFilledAndExpiredGaps(t *testing.T) {
+	f := newMonitorFixture(t)
+	filled := f.addShift(f.version, day(3), 1, true)
+	f.addShift(f.version, day(1), 1, true) // Starts 2025-11-02 17:00
+
+	_, err := f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	require.Len(t, f.openAlerts(t), 2)
+
+	f.assign(filled)
+	f.clock = time.Date(2025, 11, 2, 18, 0, 0, 0, time.UTC)
+
+	result, err := f.monitor.Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Resolved)
+	assert.Empty(t, f.openAlerts(t))
+
+	reasons := map[uuid.UUID]string{}
+	for _, a := range f.alerts.alerts {
+		assert.Equal(t, entity.AlertStateResolved, a.State)
+		require.NotNil(t, a.ResolvedAt)
+		reasons[a.ShiftInstanceID] = a.ResolutionReason
+	}
+	assert.Equal(t, entity.AlertResolvedFilled, reasons[filled.ID])
+	assert.Equal(t, entity.AlertResolvedExpired, reasons[f.shifts.shifts[1].ID])
+}
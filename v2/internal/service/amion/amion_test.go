@@ -0,0 +1,403 @@
+package amion
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	amionweb "github.com/schedcu/reimplement/pkg/amion"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The mocks embed the repository interfaces and implement only what the package calls
+
+type mockSnapshotRepo struct {
+	snapshots []*entity.AmionPageSnapshot
+}
+
+func (m *mockSnapshotRepo) Save(ctx context.Context, snapshot *entity.AmionPageSnapshot) error {
+	for i, s := range m.snapshots {
+		if s.ScrapeBatchID == snapshot.ScrapeBatchID && s.URL == snapshot.URL {
+			snapshot.ID = s.ID
+			m.snapshots[i] = snapshot
+			return nil
+		}
+	}
+	snapshot.ID = uuid.New()
+	m.snapshots = append(m.snapshots, snapshot)
+	return nil
+}
+
+func (m *mockSnapshotRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.AmionPageSnapshot, error) {
+	for _, s := range m.snapshots {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, &repository.NotFoundError{ResourceType: "AmionPageSnapshot", ResourceID: id.String()}
+}
+
+func (m *mockSnapshotRepo) ListByBatch(ctx context.Context, batchID uuid.UUID) ([]*entity.AmionPageSnapshot, error) {
+	var result []*entity.AmionPageSnapshot
+	for _, s := range m.snapshots {
+		if s.ScrapeBatchID == batchID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+type mockBatchRepo struct {
+	repository.ScrapeBatchRepository
+	batches map[uuid.UUID]*entity.ScrapeBatch
+}
+
+func (m *mockBatchRepo) Create(ctx context.Context, batch *entity.ScrapeBatch) error {
+	m.batches[batch.ID] = batch
+	return nil
+}
+
+func (m *mockBatchRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.ScrapeBatch, error) {
+	if b, ok := m.batches[id]; ok {
+		return b, nil
+	}
+	return nil, &repository.NotFoundError{ResourceType: "ScrapeBatch", ResourceID: id.String()}
+}
+
+func (m *mockBatchRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.ScrapeBatch, error) {
+	var batches []*entity.ScrapeBatch
+	for _, b := range m.batches {
+		if b.HospitalID == hospitalID {
+			batches = append(batches, b)
+		}
+	}
+	return batches, nil
+}
+
+func (m *mockBatchRepo) Update(ctx context.Context, batch *entity.ScrapeBatch) error {
+	m.batches[batch.ID] = batch
+	return nil
+}
+
+type mockVersionRepo struct {
+	repository.ScheduleVersionRepository
+	versions []*entity.ScheduleVersion
+	deleted  []uuid.UUID
+}
+
+func (m *mockVersionRepo) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func (m *mockVersionRepo) Create(ctx context.Context, version *entity.ScheduleVersion) error {
+	m.versions = append(m.versions, version)
+	return nil
+}
+
+func (m *mockVersionRepo) GetByHospitalAndStatus(ctx context.Context, hospitalID uuid.UUID, status entity.VersionStatus) ([]*entity.ScheduleVersion, error) {
+	var versions []*entity.ScheduleVersion
+	for _, v := range m.versions {
+		if v.HospitalID == hospitalID && v.Status == status {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+type mockShiftRepo struct {
+	repository.ShiftInstanceRepository
+	shifts []*entity.ShiftInstance
+	err    error // Returned by Create when set
+}
+
+func (m *mockShiftRepo) Create(ctx context.Context, shift *entity.ShiftInstance) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.shifts = append(m.shifts, shift)
+	return nil
+}
+
+func (m *mockShiftRepo) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.ShiftInstance, error) {
+	var shifts []*entity.ShiftInstance
+	for _, s := range m.shifts {
+		if s.ScheduleVersionID == versionID {
+			shifts = append(shifts, s)
+		}
+	}
+	return shifts, nil
+}
+
+type mockAssignmentRepo struct {
+	repository.AssignmentRepository
+	assignments []*entity.Assignment
+	shifts      *mockShiftRepo // Resolves GetByScheduleVersion
+}
+
+func (m *mockAssignmentRepo) Create(ctx context.Context, assignment *entity.Assignment) error {
+	m.assignments = append(m.assignments, assignment)
+	return nil
+}
+
+// EditSchedule applies the planned changes to the mocks; the version passed
+// to plan carries only its ID
+func (m *mockAssignmentRepo) EditSchedule(ctx context.Context, versionID uuid.UUID, editorID uuid.UUID, plan repository.ScheduleEditPlan) error {
+	shifts, err := m.shifts.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	assignments, err := m.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	changes, err := plan(&entity.ScheduleVersion{ID: versionID}, shifts, assignments)
+	if err != nil || changes == nil {
+		return err
+	}
+	for _, shift := range changes.CreateShifts {
+		if err := m.shifts.Create(ctx, shift); err != nil {
+			return err
+		}
+	}
+	m.assignments = append(m.assignments, changes.Assignments.Create...)
+	return nil
+}
+
+func (m *mockAssignmentRepo) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.Assignment, error) {
+	inVersion := make(map[uuid.UUID]bool)
+	if m.shifts != nil {
+		for _, s := range m.shifts.shifts {
+			inVersion[s.ID] = s.ScheduleVersionID == versionID
+		}
+	}
+	var assignments []*entity.Assignment
+	for _, a := range m.assignments {
+		if inVersion[a.ShiftInstanceID] {
+			assignments = append(assignments, a)
+		}
+	}
+	return assignments, nil
+}
+
+type mockPersonRepo struct {
+	repository.PersonRepository
+	people []*entity.Person
+}
+
+func (m *mockPersonRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.Person, error) {
+	return m.people, nil
+}
+
+// decemberSpill is the first week of December that a month view renders
+// after the month; the page window drops it
+const decemberSpill = `	<tr><td>2025-12-01</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+	<tr><td>2025-12-02</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+	<tr><td>2025-12-03</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+	<tr><td>2025-12-04</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+	<tr><td>2025-12-05</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+	<tr><td>2025-12-06</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+	<tr><td>2025-12-07</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+`
+
+var november = Page{Label: "2025-11", URL: "/schedule/2025-11", From: "2025-11-01", To: "2025-11-30"}
+
+const novemberPage = `<html><body><table><tbody>
+	<tr><th>Date</th><th>Shift</th><th>Start</th><th>End</th><th>Location</th><th>Need</th><th>Staff</th></tr>
+	<tr><td>2025-11-15</td><td>ON1</td><td>19:00</td><td>07:00</td><td>Main</td><td>2</td><td>Smith, Jane<br>Open</td></tr>
+	<tr><td>2025-11-16</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>2</td><td><a>Lee, Ann</a><a>Doe, John</a></td></tr>
+	<tr><td>Sun 17</td><td>DAY</td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+	<tr><td>2025-11-18</td><td></td><td>07:00</td><td>19:00</td><td>Main</td><td>1</td><td>Lee, Ann</td></tr>
+` + decemberSpill + `</tbody></table></body></html>`
+
+func TestExtractRows(t *testing.T) {
+	rows, rowErrors, err := ExtractRows([]byte(novemberPage), amionweb.DefaultSelectorProfiles())
+	require.NoError(t, err)
+
+	require.Len(t, rows, 10)
+	assert.Equal(t, Row{
+		Index: 2, Date: "2025-11-15", ShiftType: "ON1", StartTime: "19:00", EndTime: "07:00",
+		Location: "Main", StaffNames: []string{"Smith, Jane"}, OpenSlots: 1,
+	}, rows[0])
+	assert.Equal(t, []string{"Lee, Ann", "Doe, John"}, rows[1].StaffNames)
+	assert.Equal(t, "Sun 17", rows[2].Date, "dates are validated on import, not extraction")
+
+	require.Len(t, rowErrors, 1)
+	assert.Equal(t, RowError{Index: 5, Field: "shift_type", Reason: "empty or missing shift type cell"}, rowErrors[0])
+
+	// A page most of whose rows no longer extract is drift, not data
+	_, _, err = ExtractRows([]byte(strings.Replace(novemberPage, decemberSpill, "", 1)), amionweb.DefaultSelectorProfiles())
+	assert.Error(t, err)
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockSnapshotRepo{}
+	archive := NewArchive(repo)
+	batchID := uuid.New()
+
+	first, err := archive.RecordDivisionPage(ctx, batchID, uuid.New(), uuid.New(), november, "/schedule/2025-11", []byte("old"), time.Now())
+	require.NoError(t, err)
+	snapshot, err := archive.RecordDivisionPage(ctx, batchID, uuid.New(), uuid.New(), november, "/schedule/2025-11", []byte(novemberPage), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, snapshot.ID, "re-recording a URL replaces the page")
+	assert.Equal(t, len(novemberPage), snapshot.Size)
+	assert.Less(t, len(snapshot.Content), len(novemberPage), "content is compressed")
+
+	pages, err := archive.Pages(ctx, batchID)
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+
+	_, html, err := archive.Load(ctx, snapshot.ID)
+	require.NoError(t, err)
+	assert.Equal(t, novemberPage, string(html))
+
+	// A page whose content no longer matches its hash is rejected
+	snapshot.ContentHash = ContentHash([]byte("something else"))
+	_, _, err = archive.Load(ctx, snapshot.ID)
+	assert.ErrorContains(t, err, "corrupt")
+}
+
+func TestReimporter_Reimport(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	creatorID := uuid.New()
+
+	source := &entity.ScrapeBatch{
+		ID:              uuid.New(),
+		HospitalID:      hospitalID,
+		State:           entity.BatchStateComplete,
+		WindowStartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		WindowEndDate:   time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+	batches := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{source.ID: source}}
+	versions := &mockVersionRepo{}
+	shifts := &mockShiftRepo{}
+	assignments := &mockAssignmentRepo{shifts: shifts}
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Aliases: []string{"Smith, Jane"}, Active: true}
+	ann := &entity.Person{ID: uuid.New(), Name: "Lee,  Ann", Active: true}
+	people := &mockPersonRepo{people: []*entity.Person{jane, ann}}
+
+	archive := NewArchive(&mockSnapshotRepo{})
+	_, err := archive.RecordDivisionPage(ctx, source.ID, hospitalID, uuid.New(), november, "/schedule/2025-11", []byte(novemberPage), time.Now())
+	require.NoError(t, err)
+
+	reimporter := NewReimporter(archive, batches, versions, assignments, people)
+	result, err := reimporter.Reimport(ctx, source.ID, creatorID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Pages)
+	assert.Equal(t, 2, result.Shifts)
+	assert.Equal(t, 2, result.Assignments)
+
+	require.Len(t, versions.versions, 1)
+	version := versions.versions[0]
+	assert.Equal(t, result.VersionID, version.ID)
+	assert.Equal(t, entity.VersionStatusStaging, version.Status)
+	assert.Equal(t, result.BatchID, *version.ScrapeBatchID)
+	assert.Equal(t, source.WindowStartDate, version.EffectiveStartDate)
+
+	batch := batches.batches[result.BatchID]
+	assert.Equal(t, entity.BatchStateComplete, batch.State)
+	assert.Equal(t, 2, batch.RowCount)
+	assert.NotEmpty(t, batch.IngestChecksum)
+
+	require.Len(t, shifts.shifts, 2)
+	assert.Equal(t, 2, shifts.shifts[0].DesiredCoverage, "open slots count towards coverage")
+	assert.Equal(t, jane.ID, assignments.assignments[0].PersonID)
+	assert.Equal(t, ann.ID, assignments.assignments[1].PersonID)
+	assert.Equal(t, entity.AssignmentSourceAmion, assignments.assignments[0].Source)
+
+	vr := result.Validation
+	assert.Len(t, vr.MessagesByCode("AMION_PERSON_NOT_FOUND"), 1, "Doe, John is unknown")
+	assert.Len(t, vr.MessagesByCode("AMION_ROW_INVALID"), 2, "bad date and missing shift type")
+	assert.True(t, vr.CanImport())
+}
+
+func TestReimporter_AppliesPageWindow(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	source := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete}
+	batches := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{source.ID: source}}
+	shifts := &mockShiftRepo{}
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Aliases: []string{"Smith, Jane"}, Active: true}
+
+	// Rows the page renders past its window belong to the neighbouring page
+	week := Page{Label: "2025-11-10", URL: "/schedule/2025-11-10", From: "2025-11-10", To: "2025-11-15"}
+	archive := NewArchive(&mockSnapshotRepo{})
+	_, err := archive.RecordDivisionPage(ctx, source.ID, hospitalID, uuid.New(), week, week.URL, []byte(novemberPage), time.Now())
+	require.NoError(t, err)
+
+	reimporter := NewReimporter(archive, batches, &mockVersionRepo{}, &mockAssignmentRepo{shifts: shifts},
+		&mockPersonRepo{people: []*entity.Person{jane}})
+	result, err := reimporter.Reimport(ctx, source.ID, uuid.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Shifts, "2025-11-16 is outside the page window")
+	require.Len(t, shifts.shifts, 1)
+	assert.Equal(t, entity.ShiftType("ON1"), shifts.shifts[0].ShiftType)
+}
+
+func TestReimporter_ChecksumIgnoresArchiveOrder(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	december := Page{Label: "2025-12", URL: "/schedule/2025-12", From: "2025-12-01", To: "2025-12-31"}
+
+	checksum := func(pages ...Page) string {
+		source := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete}
+		batches := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{source.ID: source}}
+		archive := NewArchive(&mockSnapshotRepo{})
+		for _, page := range pages {
+			_, err := archive.RecordDivisionPage(ctx, source.ID, hospitalID, uuid.New(), page, page.URL, []byte(page.Label+novemberPage), time.Now())
+			require.NoError(t, err)
+		}
+		reimporter := NewReimporter(archive, batches, &mockVersionRepo{}, &mockAssignmentRepo{shifts: &mockShiftRepo{}}, &mockPersonRepo{})
+		result, err := reimporter.Reimport(ctx, source.ID, uuid.New())
+		require.NoError(t, err)
+		return batches.batches[result.BatchID].IngestChecksum
+	}
+
+	assert.Equal(t, checksum(november, december), checksum(december, november))
+}
+
+func TestReimporter_DeletesVersionOnImportFailure(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	source := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete}
+	batches := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{source.ID: source}}
+	versions := &mockVersionRepo{}
+
+	archive := NewArchive(&mockSnapshotRepo{})
+	_, err := archive.RecordDivisionPage(ctx, source.ID, hospitalID, uuid.New(), november, november.URL, []byte(novemberPage), time.Now())
+	require.NoError(t, err)
+
+	reimporter := NewReimporter(archive, batches, versions,
+		&mockAssignmentRepo{shifts: &mockShiftRepo{err: errors.New("connection reset")}}, &mockPersonRepo{})
+	_, err = reimporter.Reimport(ctx, source.ID, uuid.New())
+	assert.ErrorContains(t, err, "connection reset")
+
+	require.Len(t, versions.versions, 1)
+	assert.Equal(t, []uuid.UUID{versions.versions[0].ID}, versions.deleted, "the partial STAGING version is removed")
+	for id, batch := range batches.batches {
+		if id != source.ID {
+			assert.Equal(t, entity.BatchStateFailed, batch.State)
+		}
+	}
+}
+
+func TestReimporter_NoArchivedPages(t *testing.T) {
+	source := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: uuid.New()}
+	batches := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{source.ID: source}}
+	reimporter := NewReimporter(NewArchive(&mockSnapshotRepo{}), batches, &mockVersionRepo{},
+		&mockAssignmentRepo{shifts: &mockShiftRepo{}}, &mockPersonRepo{})
+
+	_, err := reimporter.Reimport(context.Background(), source.ID, uuid.New())
+	assert.ErrorContains(t, err, "no archived pages")
+	assert.Len(t, batches.batches, 1, "nothing is created")
+}
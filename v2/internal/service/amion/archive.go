@@ -0,0 +1,119 @@
+// Package amion holds the v2 side of Amion scraping: the raw page archive,
+// HTML extraction, and offline re-import of archived scrape batches.
+package amion
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// MaxPageSize bounds a decompressed page, guarding against corrupt archives
+const MaxPageSize = 32 << 20
+
+// Archive stores every fetched Amion page against its scrape batch, gzip
+// compressed and content-hashed, so batches can be re-parsed without Amion
+type Archive struct {
+	repo repository.AmionSnapshotRepository
+	now  func() time.Time
+}
+
+// NewArchive creates an archive backed by the snapshot repository
+func NewArchive(repo repository.AmionSnapshotRepository) *Archive {
+	return &Archive{repo: repo, now: time.Now}
+}
+
+// RecordDivisionPage archives a page fetched for one Amion division of the
+// hospital together with its date window, so a re-import can apply that
+// division's shift name mapping. Recording the same URL again for the batch
+// (e.g. after a retry) replaces the earlier copy.
+func (a *Archive) RecordDivisionPage(ctx context.Context, batchID, hospitalID, divisionID uuid.UUID, page Page, url string, html []byte, fetchedAt time.Time) (*entity.AmionPageSnapshot, error) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(html); err != nil {
+		return nil, fmt.Errorf("failed to compress page %s: %w", url, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress page %s: %w", url, err)
+	}
+
+	snapshot := &entity.AmionPageSnapshot{
+		ScrapeBatchID: batchID,
+		HospitalID:    hospitalID,
+		DivisionID:    &divisionID,
+		URL:           url,
+		Month:         page.Label,
+		WindowFrom:    page.From,
+		WindowTo:      page.To,
+		ContentHash:   ContentHash(html),
+		Encoding:      entity.SnapshotEncodingGzip,
+		Size:          len(html),
+		Content:       compressed.Bytes(),
+		FetchedAt:     fetchedAt,
+		CreatedAt:     a.now(),
+	}
+	if err := a.repo.Save(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Pages lists a batch's archived pages (without content), ordered by month
+func (a *Archive) Pages(ctx context.Context, batchID uuid.UUID) ([]*entity.AmionPageSnapshot, error) {
+	return a.repo.ListByBatch(ctx, batchID)
+}
+
+// Load returns an archived page and its decompressed HTML, verifying the
+// content hash
+func (a *Archive) Load(ctx context.Context, snapshotID uuid.UUID) (*entity.AmionPageSnapshot, []byte, error) {
+	snapshot, err := a.repo.GetByID(ctx, snapshotID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	html, err := Decompress(snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot, html, nil
+}
+
+// Decompress returns a snapshot's HTML, verifying it against ContentHash
+func Decompress(snapshot *entity.AmionPageSnapshot) ([]byte, error) {
+	if snapshot.Encoding != entity.SnapshotEncodingGzip {
+		return nil, fmt.Errorf("snapshot %s has unsupported encoding %q", snapshot.ID, snapshot.Encoding)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(snapshot.Content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot %s: %w", snapshot.ID, err)
+	}
+	defer zr.Close()
+
+	html, err := io.ReadAll(io.LimitReader(zr, MaxPageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot %s: %w", snapshot.ID, err)
+	}
+	if len(html) > MaxPageSize {
+		return nil, fmt.Errorf("snapshot %s is larger than %d bytes", snapshot.ID, MaxPageSize)
+	}
+	if hash := ContentHash(html); hash != snapshot.ContentHash {
+		return nil, fmt.Errorf("snapshot %s is corrupt: content hash %s, expected %s", snapshot.ID, hash, snapshot.ContentHash)
+	}
+	return html, nil
+}
+
+// ContentHash is the hex SHA-256 of a page's HTML
+func ContentHash(html []byte) string {
+	sum := sha256.Sum256(html)
+	return hex.EncodeToString(sum[:])
+}
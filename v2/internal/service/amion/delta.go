@@ -0,0 +1,557 @@
+package amion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// Roster lists every staff member on every row with a valid date, the form
+// in which scrapes are compared
+func Roster(rows []SourcedRow) []entity.ScrapedAssignment {
+	var roster []entity.ScrapedAssignment
+	for _, row := range rows {
+		if _, err := time.Parse("2006-01-02", row.Date); err != nil {
+			continue
+		}
+		for _, name := range row.StaffNames {
+			roster = append(roster, entity.ScrapedAssignment{
+				Date:      row.Date,
+				Shift:     strings.TrimSpace(row.ShiftType),
+				StartTime: row.StartTime,
+				EndTime:   row.EndTime,
+				Location:  strings.TrimSpace(row.Location),
+				Person:    strings.Join(strings.Fields(name), " "),
+			})
+		}
+	}
+	return roster
+}
+
+// DiffScrapes compares two rosters over [from, to]. A staff member who left
+// one shift and joined another on the same date is reported as moved rather
+// than as a removal and an addition.
+func DiffScrapes(previousBatchID uuid.UUID, previous, current []entity.ScrapedAssignment, from, to time.Time) *entity.ScrapeDelta {
+	delta := &entity.ScrapeDelta{
+		PreviousBatchID: previousBatchID,
+		WindowStart:     from.Format("2006-01-02"),
+		WindowEnd:       to.Format("2006-01-02"),
+	}
+	before := byDateAndPerson(previous, delta.WindowStart, delta.WindowEnd)
+	after := byDateAndPerson(current, delta.WindowStart, delta.WindowEnd)
+
+	for _, date := range sortedKeys(dateKeys(before), dateKeys(after)) {
+		dd := entity.DateDelta{Date: date}
+		for _, person := range sortedKeys(personKeys(before[date]), personKeys(after[date])) {
+			removed, added := subtractShifts(before[date][person], after[date][person])
+			for len(removed) > 0 && len(added) > 0 {
+				dd.Moved = append(dd.Moved, entity.AssignmentMove{Person: added[0].Person, From: removed[0], To: added[0]})
+				removed, added = removed[1:], added[1:]
+			}
+			dd.Removed = append(dd.Removed, removed...)
+			dd.Added = append(dd.Added, added...)
+		}
+
+		if len(dd.Added)+len(dd.Removed)+len(dd.Moved) > 0 {
+			delta.Dates = append(delta.Dates, dd)
+			delta.Added += len(dd.Added)
+			delta.Removed += len(dd.Removed)
+			delta.Moved += len(dd.Moved)
+		}
+	}
+	return delta
+}
+
+// byDateAndPerson groups a roster's assignments inside [from, to] by date and normalized name
+func byDateAndPerson(roster []entity.ScrapedAssignment, from, to string) map[string]map[string][]entity.ScrapedAssignment {
+	grouped := make(map[string]map[string][]entity.ScrapedAssignment)
+	for _, a := range roster {
+		if a.Date < from || a.Date > to {
+			continue
+		}
+		if grouped[a.Date] == nil {
+			grouped[a.Date] = make(map[string][]entity.ScrapedAssignment)
+		}
+		person := normalizeName(a.Person)
+		grouped[a.Date][person] = append(grouped[a.Date][person], a)
+	}
+	return grouped
+}
+
+// sortedKeys merges key lists into one sorted list without duplicates
+func sortedKeys(keyLists ...[]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, list := range keyLists {
+		for _, k := range list {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dateKeys(m map[string]map[string][]entity.ScrapedAssignment) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func personKeys(m map[string][]entity.ScrapedAssignment) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// subtractShifts returns the shifts only in before and those only in after
+func subtractShifts(before, after []entity.ScrapedAssignment) (removed, added []entity.ScrapedAssignment) {
+	remaining := make(map[string]int)
+	for _, a := range after {
+		remaining[shiftKey(a)]++
+	}
+	for _, b := range before {
+		if remaining[shiftKey(b)] > 0 {
+			remaining[shiftKey(b)]--
+			continue
+		}
+		removed = append(removed, b)
+	}
+	for _, a := range after {
+		if remaining[shiftKey(a)] > 0 {
+			remaining[shiftKey(a)]--
+			added = append(added, a)
+		}
+	}
+	return removed, added
+}
+
+// shiftKey identifies the shift of a scraped assignment
+func shiftKey(a entity.ScrapedAssignment) string {
+	clock := func(value string) string {
+		if normalized := entity.NormalizeClock(value); normalized != "" {
+			return normalized
+		}
+		return strings.TrimSpace(value)
+	}
+	return strings.Join([]string{strings.ToLower(a.Shift), clock(a.StartTime), clock(a.EndTime), strings.ToLower(a.Location)}, "|")
+}
+
+// ChangeStager compares each scrape with the hospital's previous one and
+// stages the changes that touch the PRODUCTION schedule
+type ChangeStager struct {
+	batchRepo   repository.ScrapeBatchRepository
+	rosterRepo  repository.ScrapedAssignmentRepository
+	versionRepo repository.ScheduleVersionRepository
+	shiftRepo   repository.ShiftInstanceRepository
+	importer    *Importer
+}
+
+// NewChangeStager creates a change stager. Staged assignments are written
+// through importer's repositories, and its shift mappings place added staff.
+func NewChangeStager(
+	batchRepo repository.ScrapeBatchRepository,
+	rosterRepo repository.ScrapedAssignmentRepository,
+	versionRepo repository.ScheduleVersionRepository,
+	shiftRepo repository.ShiftInstanceRepository,
+	importer *Importer,
+) *ChangeStager {
+	return &ChangeStager{
+		batchRepo:   batchRepo,
+		rosterRepo:  rosterRepo,
+		versionRepo: versionRepo,
+		shiftRepo:   shiftRepo,
+		importer:    importer,
+	}
+}
+
+// Record saves the roster of a completed batch and diffs it against the
+// hospital's latest earlier batch with a roster, over the dates both cover.
+// If anything changed inside a PRODUCTION version's window, the version's
+// assignments on the changed dates are compared with this scrape, and a
+// STAGING copy with the differences applied is created for promotion. The delta
+// is stored on the batch; it is nil when there is nothing to compare against.
+// Unchanged scrapes create no version.
+func (cs *ChangeStager) Record(
+	ctx context.Context,
+	batch *entity.ScrapeBatch,
+	rows []SourcedRow,
+	creatorID uuid.UUID,
+	vr *validation.Result,
+) (*entity.ScrapeDelta, error) {
+	roster := Roster(rows)
+	if err := cs.rosterRepo.CreateBatch(ctx, batch.ID, roster); err != nil {
+		return nil, fmt.Errorf("failed to record scraped assignments: %w", err)
+	}
+
+	previous, before, err := cs.previousRoster(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		vr.AddInfo("AMION_NO_PREVIOUS_SCRAPE", "No earlier scrape of this hospital to compare against")
+		return nil, nil
+	}
+
+	from, to := batch.WindowStartDate, batch.WindowEndDate
+	if previous.WindowStartDate.After(from) {
+		from = previous.WindowStartDate
+	}
+	if previous.WindowEndDate.Before(to) {
+		to = previous.WindowEndDate
+	}
+
+	delta := DiffScrapes(previous.ID, before, roster, from, to)
+	if !delta.Changed() {
+		vr.AddInfo("AMION_SCHEDULE_UNCHANGED", fmt.Sprintf("Amion schedule unchanged since scrape batch %s", previous.ID))
+	} else {
+		vr.AddInfo("AMION_SCHEDULE_CHANGED", fmt.Sprintf(
+			"Amion schedule changed since scrape batch %s: %d added, %d removed, %d moved",
+			previous.ID, delta.Added, delta.Removed, delta.Moved))
+
+		productions, err := cs.versionRepo.GetByHospitalAndStatus(ctx, batch.HospitalID, entity.VersionStatusProduction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load production versions: %w", err)
+		}
+		for _, production := range productions {
+			changes := changesWithin(delta, production.EffectiveStartDate, production.EffectiveEndDate)
+			if len(changes) == 0 {
+				continue
+			}
+			staged, changedDates, err := cs.stage(ctx, production, batch, rows, changes, creatorID, vr)
+			if err != nil {
+				return nil, err
+			}
+			if staged == nil {
+				vr.AddInfo("AMION_PRODUCTION_CURRENT", fmt.Sprintf(
+					"PRODUCTION version %s already matches Amion on the changed dates", production.ID))
+				continue
+			}
+			delta.StagedVersionIDs = append(delta.StagedVersionIDs, staged.ID)
+			vr.Add(validation.SeverityInfo, "AMION_CHANGES_STAGED", fmt.Sprintf(
+				"Staged Amion changes on %d dates of the PRODUCTION schedule as version %s", changedDates, staged.ID),
+				map[string]interface{}{"version_id": staged.ID.String(), "production_version_id": production.ID.String()})
+		}
+	}
+
+	batch.Delta = delta
+	if err := cs.batchRepo.Update(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to store scrape delta: %w", err)
+	}
+	return delta, nil
+}
+
+// previousRoster finds the hospital's latest complete batch scraped before
+// batch that recorded a roster. Batches without one (re-imports, scrapes from
+// before rosters were kept) and rolled back batches are skipped.
+func (cs *ChangeStager) previousRoster(ctx context.Context, batch *entity.ScrapeBatch) (*entity.ScrapeBatch, []entity.ScrapedAssignment, error) {
+	batches, err := cs.batchRepo.GetByHospital(ctx, batch.HospitalID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load scrape batches: %w", err)
+	}
+
+	var candidates []*entity.ScrapeBatch
+	for _, b := range batches {
+		if b.ID != batch.ID && b.State == entity.BatchStateComplete && !b.IsDeleted() && !b.IsRolledBack() && b.ScrapedAt.Before(batch.ScrapedAt) {
+			candidates = append(candidates, b)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ScrapedAt.After(candidates[j].ScrapedAt) })
+
+	for _, candidate := range candidates {
+		roster, err := cs.rosterRepo.GetByBatch(ctx, candidate.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load scraped assignments: %w", err)
+		}
+		if len(roster) > 0 {
+			return candidate, roster, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// changesWithin returns the delta's dates inside [from, to]
+func changesWithin(delta *entity.ScrapeDelta, from, to time.Time) []entity.DateDelta {
+	var changes []entity.DateDelta
+	for _, dd := range delta.Dates {
+		if dd.Date >= from.Format("2006-01-02") && dd.Date <= to.Format("2006-01-02") {
+			changes = append(changes, dd)
+		}
+	}
+	return changes
+}
+
+// stagedAddition is a staff member Amion lists on a row that the PRODUCTION
+// schedule lacks, and the production shift they join (nil for a shift
+// production doesn't have yet)
+type stagedAddition struct {
+	row      SourcedRow
+	personID uuid.UUID
+	shift    *entity.ShiftInstance
+	slot     entity.ShiftSlot
+	exact    bool // slot was placed by a shift mapping
+}
+
+// stage compares production's assignments on the changed dates with the
+// scraped rows and, if they differ, copies production into a new STAGING
+// version with the differences applied: Amion assignments Amion no longer
+// lists are left out of the copy, and newly listed staff are assigned to the
+// copied shift for their slot. Staff already on that shift, whatever the
+// source, are not added again. As in Importer.Import, rows for a shift holding
+// a pinned (MANUAL or OVERRIDE) assignment add nobody, and an
+// OVERRIDE_CONFLICT warning lists Amion's staff and the pinned staff.
+//
+// The copy is written in one transaction; if that fails the new version is
+// deleted again. It returns a nil version when production already matches
+// Amion, and the number of dates that differ.
+func (cs *ChangeStager) stage(
+	ctx context.Context,
+	production *entity.ScheduleVersion,
+	batch *entity.ScrapeBatch,
+	rows []SourcedRow,
+	changes []entity.DateDelta,
+	creatorID uuid.UUID,
+	vr *validation.Result,
+) (*entity.ScheduleVersion, int, error) {
+	dates := make(map[string]bool, len(changes))
+	for _, dd := range changes {
+		dates[dd.Date] = true
+	}
+
+	shifts, err := cs.shiftRepo.GetByScheduleVersion(ctx, production.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load production shifts: %w", err)
+	}
+	assignments, err := cs.importer.assignmentRepo.GetByScheduleVersion(ctx, production.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load production assignments: %w", err)
+	}
+	people, err := cs.importer.personRepo.GetByHospital(ctx, production.HospitalID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load staff: %w", err)
+	}
+	personIDs := personIndex(people)
+	var mappings []*entity.AmionShiftMapping
+	if cs.importer.mappingRepo != nil {
+		if mappings, err = cs.importer.mappingRepo.GetByHospital(ctx, production.HospitalID); err != nil {
+			return nil, 0, fmt.Errorf("failed to load shift mappings: %w", err)
+		}
+	}
+
+	// Production's live assignments on the changed dates, by date, person
+	// and label, and who is on each production shift
+	remaining := make(map[string][]*entity.Assignment)
+	onShift := make(map[uuid.UUID]map[uuid.UUID]bool, len(shifts))
+	for _, shift := range shifts {
+		onShift[shift.ID] = make(map[uuid.UUID]bool)
+	}
+	for _, a := range assignments {
+		if onShift[a.ShiftInstanceID] != nil {
+			onShift[a.ShiftInstanceID][a.PersonID] = true
+		}
+		if date := a.ScheduleDate.Format("2006-01-02"); dates[date] {
+			key := amionAssignmentKey(date, a.PersonID, a.OriginalShiftType)
+			remaining[key] = append(remaining[key], a)
+		}
+	}
+	pinned := pinnedAssignments(shifts, assignments)
+
+	// Staff unknown here were already reported when the scrape was imported
+	var added []stagedAddition
+	var conflictKeys []entity.ShiftSlot
+	conflicts := make(map[entity.ShiftSlot][]SourcedRow)
+	for _, row := range rows {
+		if !dates[row.Date] {
+			continue
+		}
+		slot, mapping := rowSlot(mappings, row)
+		target := findSlotShift(shifts, slot, mapping != nil)
+		locked := target != nil && len(pinned[target.Slot()]) > 0
+		if locked {
+			if _, ok := conflicts[target.Slot()]; !ok {
+				conflictKeys = append(conflictKeys, target.Slot())
+			}
+			conflicts[target.Slot()] = append(conflicts[target.Slot()], row)
+		}
+
+		for _, name := range row.StaffNames {
+			personID, ok := personIDs[normalizeName(name)]
+			if !ok {
+				continue
+			}
+			key := amionAssignmentKey(row.Date, personID, row.ShiftType)
+			if len(remaining[key]) > 0 {
+				remaining[key] = remaining[key][1:]
+				continue
+			}
+			if locked || (target != nil && onShift[target.ID][personID]) {
+				continue
+			}
+			if target != nil {
+				onShift[target.ID][personID] = true
+			}
+			added = append(added, stagedAddition{row: row, personID: personID, shift: target, slot: slot, exact: mapping != nil})
+		}
+	}
+	for _, key := range conflictKeys {
+		addOverrideConflict(vr, key, conflicts[key], pinned[key], people, personIDs)
+	}
+
+	dropped := make(map[uuid.UUID]bool)
+	changedDates := make(map[string]bool)
+	for _, left := range remaining {
+		for _, a := range left {
+			if a.Source != entity.AssignmentSourceAmion {
+				continue
+			}
+			dropped[a.ID] = true
+			changedDates[a.ScheduleDate.Format("2006-01-02")] = true
+		}
+	}
+	for _, a := range added {
+		changedDates[a.row.Date] = true
+	}
+	if len(changedDates) == 0 {
+		return nil, 0, nil
+	}
+
+	now := entity.Now()
+	staged := &entity.ScheduleVersion{
+		ID:                 uuid.New(),
+		HospitalID:         production.HospitalID,
+		Status:             entity.VersionStatusStaging,
+		EffectiveStartDate: production.EffectiveStartDate,
+		EffectiveEndDate:   production.EffectiveEndDate,
+		ScrapeBatchID:      &batch.ID,
+		CreatedAt:          now,
+		CreatedBy:          creatorID,
+		UpdatedAt:          now,
+		UpdatedBy:          creatorID,
+	}
+	if err := cs.versionRepo.Create(ctx, staged); err != nil {
+		return nil, 0, fmt.Errorf("failed to create staging version: %w", err)
+	}
+
+	// The version is new, so the plan ignores what it holds
+	staging := stagedCopy(staged, batch, shifts, assignments, dropped, added, creatorID)
+	err = cs.importer.assignmentRepo.EditSchedule(ctx, staged.ID, creatorID, func(*entity.ScheduleVersion, []*entity.ShiftInstance, []*entity.Assignment) (*entity.ScheduleChanges, error) {
+		return staging, nil
+	})
+	if err != nil {
+		if cleanupErr := cs.versionRepo.Delete(ctx, staged.ID, creatorID); cleanupErr != nil {
+			return nil, 0, fmt.Errorf("failed to stage changes: %w (and failed to delete the partial version %s: %v)", err, staged.ID, cleanupErr)
+		}
+		return nil, 0, fmt.Errorf("failed to stage changes: %w", err)
+	}
+	return staged, len(changedDates), nil
+}
+
+// stagedCopy builds the shifts and assignments of a STAGING copy of
+// production: every shift, every assignment not dropped, and the additions.
+// Copies keep the ScrapeBatchID of the row they copy, so rolling back the
+// batch that imported a row also removes it from later staged versions.
+func stagedCopy(
+	staged *entity.ScheduleVersion,
+	batch *entity.ScrapeBatch,
+	shifts []*entity.ShiftInstance,
+	assignments []*entity.Assignment,
+	dropped map[uuid.UUID]bool,
+	added []stagedAddition,
+	creatorID uuid.UUID,
+) *entity.ScheduleChanges {
+	changes := &entity.ScheduleChanges{}
+	copied := make(map[uuid.UUID]*entity.ShiftInstance, len(shifts))
+	for _, shift := range shifts {
+		shiftCopy := *shift
+		shiftCopy.ID = uuid.New()
+		shiftCopy.ScheduleVersionID = staged.ID
+		shiftCopy.CreatedAt = staged.CreatedAt
+		shiftCopy.CreatedBy = creatorID
+		copied[shift.ID] = &shiftCopy
+		changes.CreateShifts = append(changes.CreateShifts, &shiftCopy)
+	}
+
+	for _, a := range assignments {
+		shift, ok := copied[a.ShiftInstanceID]
+		if dropped[a.ID] || !ok {
+			continue
+		}
+		assignmentCopy := *a
+		assignmentCopy.ID = uuid.New()
+		assignmentCopy.ShiftInstanceID = shift.ID
+		assignmentCopy.CreatedAt = staged.CreatedAt
+		assignmentCopy.CreatedBy = creatorID
+		changes.Assignments.Create = append(changes.Assignments.Create, &assignmentCopy)
+	}
+
+	// Shifts Amion added that production doesn't have yet, and who joins them
+	var created []*entity.ShiftInstance
+	joined := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, a := range added {
+		var shift *entity.ShiftInstance
+		if a.shift != nil {
+			shift = copied[a.shift.ID]
+		} else if shift = findSlotShift(created, a.slot, a.exact); shift == nil {
+			date, _ := time.Parse("2006-01-02", a.row.Date)
+			shift = newShiftInstance(staged, date, a.row, batch.ID, creatorID)
+			shift.ShiftType = a.slot.ShiftType
+			shift.StudyType = a.slot.StudyType
+			shift.SpecialtyConstraint = a.slot.Specialty
+			created = append(created, shift)
+			changes.CreateShifts = append(changes.CreateShifts, shift)
+			joined[shift.ID] = make(map[uuid.UUID]bool)
+		}
+		if a.shift == nil {
+			if joined[shift.ID][a.personID] {
+				continue
+			}
+			joined[shift.ID][a.personID] = true
+		}
+
+		changes.Assignments.Create = append(changes.Assignments.Create, &entity.Assignment{
+			ID:                uuid.New(),
+			PersonID:          a.personID,
+			ShiftInstanceID:   shift.ID,
+			ScheduleDate:      shift.ScheduleDate,
+			OriginalShiftType: a.row.ShiftType,
+			Source:            entity.AssignmentSourceAmion,
+			ScrapeBatchID:     &batch.ID,
+			CreatedAt:         staged.CreatedAt,
+			CreatedBy:         creatorID,
+		})
+	}
+	return changes
+}
+
+// amionAssignmentKey identifies an Amion assignment by date, person and
+// Amion shift label
+func amionAssignmentKey(date string, personID uuid.UUID, label string) string {
+	return date + "|" + personID.String() + "|" + strings.ToLower(strings.TrimSpace(label))
+}
+
+// findSlotShift finds the shift filling slot. Slots placed by a shift mapping
+// must match exactly; otherwise any shift of the slot's type on its date will do.
+func findSlotShift(shifts []*entity.ShiftInstance, slot entity.ShiftSlot, exact bool) *entity.ShiftInstance {
+	for _, shift := range shifts {
+		if exact && shift.Slot() == slot {
+			return shift
+		}
+		if !exact && shift.ShiftType == slot.ShiftType && shift.ScheduleDate.Format("2006-01-02") == slot.Date {
+			return shift
+		}
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package amion
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRosterRepo struct {
+	rosters map[uuid.UUID][]entity.ScrapedAssignment
+	err     error // Returned by CreateBatch when set
+}
+
+func (m *mockRosterRepo) CreateBatch(ctx context.Context, batchID uuid.UUID, assignments []entity.ScrapedAssignment) error {
+	if m.err != nil {
+		return m.err
+	}
+	for i := range assignments {
+		assignments[i].ScrapeBatchID = batchID
+	}
+	m.rosters[batchID] = assignments
+	return nil
+}
+
+func (m *mockRosterRepo) GetByBatch(ctx context.Context, batchID uuid.UUID) ([]entity.ScrapedAssignment, error) {
+	return m.rosters[batchID], nil
+}
+
+func scraped(date, shift, person string) entity.ScrapedAssignment {
+	return entity.ScrapedAssignment{Date: date, Shift: shift, StartTime: "07:00", EndTime: "17:00", Person: person}
+}
+
+func TestDiffScrapes(t *testing.T) {
+	previous := []entity.ScrapedAssignment{
+		scraped("2025-11-03", "Body Day", "Jane Smith"),
+		scraped("2025-11-03", "Overnight", "Ann Lee"),
+		scraped("2025-11-04", "Body Day", "Bob Ray"),
+		scraped("2025-11-30", "Neuro Day", "Carl Diaz"),
+	}
+	current := []entity.ScrapedAssignment{
+		scraped("2025-11-03", "Body Late", "Jane Smith"),
+		scraped("2025-11-03", "overnight", "Ann  Lee"),
+		scraped("2025-11-04", "Overnight", "Dana Fox"),
+	}
+	previousID := uuid.New()
+
+	delta := DiffScrapes(previousID, previous, current,
+		time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 29, 0, 0, 0, 0, time.UTC))
+
+	assert.True(t, delta.Changed())
+	assert.Equal(t, previousID, delta.PreviousBatchID)
+	assert.Equal(t, []int{1, 1, 1}, []int{delta.Added, delta.Removed, delta.Moved}, "dates outside the window are not compared")
+	require.Len(t, delta.Dates, 2)
+	assert.Equal(t, entity.DateDelta{Date: "2025-11-03", Moved: []entity.AssignmentMove{
+		{Person: "Jane Smith", From: previous[0], To: current[0]},
+	}}, delta.Dates[0])
+	assert.Equal(t, []entity.ScrapedAssignment{current[2]}, delta.Dates[1].Added)
+	assert.Equal(t, []entity.ScrapedAssignment{previous[2]}, delta.Dates[1].Removed)
+
+	assert.False(t, DiffScrapes(previousID, previous, previous, time.Time{}, time.Now()).Changed())
+}
+
+func TestChangeStager_StagesProductionChanges(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	nov1, nov30 := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+	bob := &entity.Person{ID: uuid.New(), Name: "Bob Ray", Active: true}
+	carl := &entity.Person{ID: uuid.New(), Name: "Carl Diaz", Active: true}
+
+	// PRODUCTION as imported from the previous scrape, plus a manual assignment
+	production := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: hospitalID, Status: entity.VersionStatusProduction,
+		EffectiveStartDate: nov1, EffectiveEndDate: nov30}
+	nov3Day := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: production.ID, ShiftType: "Body Day",
+		ScheduleDate: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)}
+	nov4Day := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: production.ID, ShiftType: "Body Day",
+		ScheduleDate: time.Date(2025, 11, 4, 0, 0, 0, 0, time.UTC)}
+	shiftRepo := &mockShiftRepo{shifts: []*entity.ShiftInstance{nov3Day, nov4Day}}
+	assignmentRepo := &mockAssignmentRepo{shifts: shiftRepo, assignments: []*entity.Assignment{
+		{ID: uuid.New(), PersonID: jane.ID, ShiftInstanceID: nov3Day.ID, ScheduleDate: nov3Day.ScheduleDate,
+			OriginalShiftType: "Body Day", Source: entity.AssignmentSourceAmion},
+		{ID: uuid.New(), PersonID: bob.ID, ShiftInstanceID: nov4Day.ID, ScheduleDate: nov4Day.ScheduleDate,
+			OriginalShiftType: "Body Day", Source: entity.AssignmentSourceAmion},
+		{ID: uuid.New(), PersonID: carl.ID, ShiftInstanceID: nov4Day.ID, ScheduleDate: nov4Day.ScheduleDate,
+			Source: entity.AssignmentSourceManual},
+	}}
+	versionRepo := &mockVersionRepo{versions: []*entity.ScheduleVersion{production}}
+
+	previous := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now().Add(-24 * time.Hour)}
+	batchRepo := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{previous.ID: previous}}
+	rosterRepo := &mockRosterRepo{rosters: map[uuid.UUID][]entity.ScrapedAssignment{previous.ID: {
+		scraped("2025-11-03", "Body Day", "Jane Smith"),
+		scraped("2025-11-04", "Body Day", "Bob Ray"),
+	}}}
+
+	importer := NewImporter(assignmentRepo, &mockPersonRepo{people: []*entity.Person{jane, bob, carl}})
+	stager := NewChangeStager(batchRepo, rosterRepo, versionRepo, shiftRepo, importer)
+
+	// Jane moved to the late shift and Bob called in sick
+	batch := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now()}
+	batchRepo.batches[batch.ID] = batch
+	rows := []SourcedRow{{Row: Row{Date: "2025-11-03", ShiftType: "Body Late", StartTime: "07:00", EndTime: "17:00", StaffNames: []string{"Jane Smith"}}}}
+
+	vr := validation.NewResult()
+	delta, err := stager.Record(ctx, batch, rows, uuid.New(), vr)
+	require.NoError(t, err)
+
+	require.NotNil(t, delta)
+	assert.Equal(t, delta, batch.Delta, "stored with the batch")
+	assert.Equal(t, []int{0, 1, 1}, []int{delta.Added, delta.Removed, delta.Moved})
+	require.Len(t, delta.StagedVersionIDs, 1)
+	require.Len(t, versionRepo.versions, 2)
+	staged := versionRepo.versions[1]
+	assert.Equal(t, delta.StagedVersionIDs[0], staged.ID)
+	assert.Equal(t, entity.VersionStatusStaging, staged.Status)
+	assert.Equal(t, &batch.ID, staged.ScrapeBatchID)
+	assert.Len(t, vr.MessagesByCode("AMION_CHANGES_STAGED"), 1)
+
+	stagedAssignments, err := assignmentRepo.GetByScheduleVersion(ctx, staged.ID)
+	require.NoError(t, err)
+	byPerson := map[uuid.UUID]*entity.Assignment{}
+	for _, a := range stagedAssignments {
+		byPerson[a.PersonID] = a
+	}
+	assert.Len(t, stagedAssignments, 2)
+	assert.NotContains(t, byPerson, bob.ID, "Bob's shift was dropped")
+	assert.Equal(t, entity.AssignmentSourceManual, byPerson[carl.ID].Source, "manual assignments are carried over")
+	assert.Equal(t, "Body Late", byPerson[jane.ID].OriginalShiftType)
+	assert.Len(t, assignmentRepo.assignments, 5, "PRODUCTION is untouched")
+
+	// Nothing changed on the next scrape
+	next := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now().Add(time.Hour)}
+	batchRepo.batches[next.ID] = next
+	vr = validation.NewResult()
+	delta, err = stager.Record(ctx, next, rows, uuid.New(), vr)
+	require.NoError(t, err)
+
+	assert.False(t, delta.Changed())
+	assert.Equal(t, batch.ID, delta.PreviousBatchID)
+	assert.Len(t, versionRepo.versions, 2, "unchanged scrapes create no version")
+	assert.Len(t, vr.MessagesByCode("AMION_SCHEDULE_UNCHANGED"), 1)
+}
+
+func TestChangeStager_DiffsAgainstProduction(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	nov1, nov30 := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+	nov5, nov6 := time.Date(2025, 11, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 6, 0, 0, 0, 0, time.UTC)
+
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+	ann := &entity.Person{ID: uuid.New(), Name: "Ann Lee", Active: true}
+	bob := &entity.Person{ID: uuid.New(), Name: "Bob Ray", Active: true}
+	dana := &entity.Person{ID: uuid.New(), Name: "Dana Fox", Active: true}
+	neuro := &entity.AmionDivision{ID: uuid.New(), Name: "Neuro", ShiftNameMapping: map[string]string{"Neuro Day": "NEURO_DAY"}}
+
+	// Bob's Nov 6 shift was staged from the previous scrape but never promoted
+	production := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: hospitalID, Status: entity.VersionStatusProduction,
+		EffectiveStartDate: nov1, EffectiveEndDate: nov30}
+	nov5Day := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: production.ID, ShiftType: "NEURO_DAY", ScheduleDate: nov5}
+	nov6Day := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: production.ID, ShiftType: "NEURO_DAY", ScheduleDate: nov6}
+	shiftRepo := &mockShiftRepo{shifts: []*entity.ShiftInstance{nov5Day, nov6Day}}
+	assignmentRepo := &mockAssignmentRepo{shifts: shiftRepo, assignments: []*entity.Assignment{
+		{ID: uuid.New(), PersonID: jane.ID, ShiftInstanceID: nov5Day.ID, ScheduleDate: nov5,
+			OriginalShiftType: "Neuro Day", Source: entity.AssignmentSourceAmion},
+	}}
+	versionRepo := &mockVersionRepo{versions: []*entity.ScheduleVersion{production}}
+
+	previous := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now().Add(-24 * time.Hour)}
+	batchRepo := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{previous.ID: previous}}
+	rosterRepo := &mockRosterRepo{rosters: map[uuid.UUID][]entity.ScrapedAssignment{previous.ID: {
+		scraped("2025-11-05", "Neuro Day", "Jane Smith"),
+		scraped("2025-11-06", "Neuro Day", "Bob Ray"),
+	}}}
+
+	importer := NewImporter(assignmentRepo, &mockPersonRepo{people: []*entity.Person{jane, ann, bob, dana}})
+	stager := NewChangeStager(batchRepo, rosterRepo, versionRepo, shiftRepo, importer)
+
+	batch := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now()}
+	batchRepo.batches[batch.ID] = batch
+	rows := []SourcedRow{
+		{Row: Row{Date: "2025-11-05", ShiftType: "Neuro Day", StartTime: "07:00", EndTime: "17:00",
+			StaffNames: []string{"Jane Smith", "Ann Lee"}}, Division: neuro},
+		{Row: Row{Date: "2025-11-06", ShiftType: "Neuro Day", StartTime: "07:00", EndTime: "17:00",
+			StaffNames: []string{"Bob Ray", "Dana Fox"}}, Division: neuro},
+	}
+
+	vr := validation.NewResult()
+	delta, err := stager.Record(ctx, batch, rows, uuid.New(), vr)
+	require.NoError(t, err)
+	require.Len(t, delta.StagedVersionIDs, 1)
+	staged := delta.StagedVersionIDs[0]
+
+	stagedShifts, err := shiftRepo.GetByScheduleVersion(ctx, staged)
+	require.NoError(t, err)
+	assert.Len(t, stagedShifts, 2, "added staff join the copied shifts")
+	shiftByDate := map[string]uuid.UUID{}
+	for _, s := range stagedShifts {
+		assert.Equal(t, entity.ShiftType("NEURO_DAY"), s.ShiftType)
+		shiftByDate[s.ScheduleDate.Format("2006-01-02")] = s.ID
+	}
+
+	stagedAssignments, err := assignmentRepo.GetByScheduleVersion(ctx, staged)
+	require.NoError(t, err)
+	onShift := map[uuid.UUID]uuid.UUID{}
+	for _, a := range stagedAssignments {
+		onShift[a.PersonID] = a.ShiftInstanceID
+	}
+	assert.Len(t, stagedAssignments, 4)
+	assert.Equal(t, shiftByDate["2025-11-05"], onShift[jane.ID])
+	assert.Equal(t, shiftByDate["2025-11-05"], onShift[ann.ID])
+	assert.Equal(t, shiftByDate["2025-11-06"], onShift[bob.ID], "PRODUCTION never had Bob, whatever the previous scrape said")
+	assert.Equal(t, shiftByDate["2025-11-06"], onShift[dana.ID])
+}
+
+func TestChangeStager_RespectsPinnedAndExistingStaff(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+	nov1, nov30 := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+	nov3 := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+
+	xavier := &entity.Person{ID: uuid.New(), Name: "Xavier Pell", Active: true}
+	yuri := &entity.Person{ID: uuid.New(), Name: "Yuri Tam", Active: true}
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+	carl := &entity.Person{ID: uuid.New(), Name: "Carl Diaz", Active: true}
+	ann := &entity.Person{ID: uuid.New(), Name: "Ann Lee", Active: true}
+
+	// A scheduler swapped Amion's Xavier for Yuri on the day shift, and added
+	// Carl to the late shift by hand before unpinning him
+	production := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: hospitalID, Status: entity.VersionStatusProduction,
+		EffectiveStartDate: nov1, EffectiveEndDate: nov30}
+	day := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: production.ID, ShiftType: "Body Day", ScheduleDate: nov3}
+	late := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: production.ID, ShiftType: "Body Late", ScheduleDate: nov3}
+	shiftRepo := &mockShiftRepo{shifts: []*entity.ShiftInstance{day, late}}
+	unpinned := entity.Now()
+	assignmentRepo := &mockAssignmentRepo{shifts: shiftRepo, assignments: []*entity.Assignment{
+		{ID: uuid.New(), PersonID: yuri.ID, ShiftInstanceID: day.ID, ScheduleDate: nov3,
+			OriginalShiftType: "Body Day", Source: entity.AssignmentSourceOverride},
+		{ID: uuid.New(), PersonID: jane.ID, ShiftInstanceID: late.ID, ScheduleDate: nov3,
+			OriginalShiftType: "Body Late", Source: entity.AssignmentSourceAmion},
+		{ID: uuid.New(), PersonID: carl.ID, ShiftInstanceID: late.ID, ScheduleDate: nov3,
+			Source: entity.AssignmentSourceManual, UnpinnedAt: &unpinned},
+	}}
+	versionRepo := &mockVersionRepo{versions: []*entity.ScheduleVersion{production}}
+
+	previous := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now().Add(-24 * time.Hour)}
+	batchRepo := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{previous.ID: previous}}
+	rosterRepo := &mockRosterRepo{rosters: map[uuid.UUID][]entity.ScrapedAssignment{previous.ID: {
+		scraped("2025-11-03", "Body Day", "Xavier Pell"),
+		scraped("2025-11-03", "Body Late", "Jane Smith"),
+	}}}
+
+	importer := NewImporter(assignmentRepo, &mockPersonRepo{people: []*entity.Person{xavier, yuri, jane, carl, ann}})
+	stager := NewChangeStager(batchRepo, rosterRepo, versionRepo, shiftRepo, importer)
+
+	batch := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now()}
+	batchRepo.batches[batch.ID] = batch
+	rows := []SourcedRow{
+		{Row: Row{Date: "2025-11-03", ShiftType: "Body Day", StaffNames: []string{"Xavier Pell"}}},
+		{Row: Row{Date: "2025-11-03", ShiftType: "Body Late", StaffNames: []string{"Jane Smith", "Carl Diaz", "Ann Lee"}}},
+	}
+
+	vr := validation.NewResult()
+	delta, err := stager.Record(ctx, batch, rows, uuid.New(), vr)
+	require.NoError(t, err)
+	require.Len(t, delta.StagedVersionIDs, 1)
+
+	stagedAssignments, err := assignmentRepo.GetByScheduleVersion(ctx, delta.StagedVersionIDs[0])
+	require.NoError(t, err)
+	people := map[uuid.UUID]int{}
+	for _, a := range stagedAssignments {
+		people[a.PersonID]++
+	}
+	assert.Equal(t, map[uuid.UUID]int{yuri.ID: 1, jane.ID: 1, carl.ID: 1, ann.ID: 1}, people,
+		"the override stands and Carl is not added twice")
+
+	conflicts := vr.MessagesByCode("OVERRIDE_CONFLICT")
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, []string{"Xavier Pell"}, conflicts[0].Context["amion"])
+	assert.Equal(t, []string{"Yuri Tam"}, conflicts[0].Context["pinned"])
+}
+
+func TestChangeStager_DeletesVersionOnWriteFailure(t *testing.T) {
+	hospitalID := uuid.New()
+	nov1, nov30 := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+
+	production := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: hospitalID, Status: entity.VersionStatusProduction,
+		EffectiveStartDate: nov1, EffectiveEndDate: nov30}
+	shiftRepo := &mockShiftRepo{shifts: []*entity.ShiftInstance{{ID: uuid.New(), ScheduleVersionID: production.ID,
+		ShiftType: "Body Day", ScheduleDate: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)}}}
+	versionRepo := &mockVersionRepo{versions: []*entity.ScheduleVersion{production}}
+	previous := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now().Add(-24 * time.Hour)}
+	batchRepo := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{previous.ID: previous}}
+	rosterRepo := &mockRosterRepo{rosters: map[uuid.UUID][]entity.ScrapedAssignment{previous.ID: {
+		scraped("2025-11-04", "Body Day", "Jane Smith"),
+	}}}
+	stager := NewChangeStager(batchRepo, rosterRepo, versionRepo, shiftRepo,
+		NewImporter(&mockAssignmentRepo{shifts: shiftRepo}, &mockPersonRepo{people: []*entity.Person{jane}}))
+
+	batch := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: hospitalID, State: entity.BatchStateComplete,
+		WindowStartDate: nov1, WindowEndDate: nov30, ScrapedAt: time.Now()}
+	batchRepo.batches[batch.ID] = batch
+	rows := []SourcedRow{{Row: Row{Date: "2025-11-03", ShiftType: "Body Day", StaffNames: []string{"Jane Smith"}}}}
+
+	shiftRepo.err = errors.New("connection reset")
+	_, err := stager.Record(context.Background(), batch, rows, uuid.New(), validation.NewResult())
+	assert.ErrorContains(t, err, "connection reset")
+	require.Len(t, versionRepo.versions, 2)
+	assert.Equal(t, []uuid.UUID{versionRepo.versions[1].ID}, versionRepo.deleted, "the partial STAGING version is removed")
+}
+
+func TestChangeStager_FirstScrape(t *testing.T) {
+	batch := &entity.ScrapeBatch{ID: uuid.New(), HospitalID: uuid.New(), State: entity.BatchStateComplete, ScrapedAt: time.Now()}
+	batchRepo := &mockBatchRepo{batches: map[uuid.UUID]*entity.ScrapeBatch{batch.ID: batch}}
+	rosterRepo := &mockRosterRepo{rosters: map[uuid.UUID][]entity.ScrapedAssignment{}}
+	stager := NewChangeStager(batchRepo, rosterRepo, &mockVersionRepo{}, &mockShiftRepo{}, NewImporter(&mockAssignmentRepo{shifts: &mockShiftRepo{}}, &mockPersonRepo{}))
+
+	rows := []SourcedRow{{Row: Row{Date: "2025-11-03", ShiftType: "Body Day", StaffNames: []string{"Jane Smith"}}}}
+	vr := validation.NewResult()
+	delta, err := stager.Record(context.Background(), batch, rows, uuid.New(), vr)
+	require.NoError(t, err)
+
+	assert.Nil(t, delta)
+	assert.Nil(t, batch.Delta)
+	assert.Len(t, rosterRepo.rosters[batch.ID], 1, "the roster is kept for the next scrape")
+	assert.Len(t, vr.MessagesByCode("AMION_NO_PREVIOUS_SCRAPE"), 1)
+}
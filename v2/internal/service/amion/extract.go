@@ -0,0 +1,106 @@
+package amion
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/PuerkitoBio/goquery"
+	amionweb "github.com/schedcu/reimplement/pkg/amion"
+)
+
+// Row is one shift row extracted from a page
+type Row struct {
+	Index      int // 1-based position among the page's rows, for error reports
+	Date       string
+	ShiftType  string
+	StartTime  string
+	EndTime    string
+	Location   string
+	StaffNames []string
+	OpenSlots  int // "open"/"TBD" markers in the staff cell
+}
+
+// RowError is a row that could not be extracted
+type RowError struct {
+	Index  int
+	Field  string
+	Reason string
+}
+
+// Error implements error
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s: %s", e.Index, e.Field, e.Reason)
+}
+
+// ExtractRows extracts every shift row from a page with the profile that
+// matches its markup best. Rows missing a required cell (date, shift type,
+// start or end time) are reported instead; dates are checked on import.
+func ExtractRows(html []byte, profiles *amionweb.SelectorProfileSet) ([]Row, []RowError, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	profile, err := profiles.Select(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	extracted := amionweb.ExtractShiftsWithSelectors(doc, &profile.Selectors)
+	rows := make([]Row, 0, len(extracted.Shifts))
+	for _, shift := range extracted.Shifts {
+		rows = append(rows, rowFromShift(shift))
+	}
+	return rows, rowErrors(extracted.Errors), nil
+}
+
+func rowFromShift(shift amionweb.RawAmionShift) Row {
+	return Row{
+		Index:      shift.RowIndex,
+		Date:       shift.Date,
+		ShiftType:  shift.ShiftType,
+		StartTime:  shift.StartTime,
+		EndTime:    shift.EndTime,
+		Location:   shift.Location,
+		StaffNames: shift.StaffNames,
+		OpenSlots:  shift.OpenSlots,
+	}
+}
+
+func rowErrors(errs []amionweb.ExtractionError) []RowError {
+	var result []RowError
+	for _, e := range errs {
+		result = append(result, RowError{Index: e.RowIndex, Field: e.Field, Reason: e.Reason})
+	}
+	return result
+}
+
+// selectorProfiles returns the versions of the named profile in all. An
+// empty name is DefaultSelectorProfile.
+func selectorProfiles(all *amionweb.SelectorProfileSet, name string) (*amionweb.SelectorProfileSet, error) {
+	if name == "" {
+		name = DefaultSelectorProfile
+	}
+	var named []*amionweb.SelectorProfile
+	for _, p := range all.Profiles() {
+		if p.Name == name {
+			named = append(named, p)
+		}
+	}
+	if len(named) == 0 {
+		return nil, fmt.Errorf("unknown selector profile %q", name)
+	}
+	return amionweb.NewSelectorProfileSet(named...)
+}
+
+// selectorProfileNames lists the distinct profile names in a set
+func selectorProfileNames(all *amionweb.SelectorProfileSet) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range all.Profiles() {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
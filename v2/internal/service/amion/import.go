@@ -0,0 +1,440 @@
+package amion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// SourcedRow is an extracted row and the page it came from
+type SourcedRow struct {
+	Row
+	URL      string
+	Division *entity.AmionDivision // nil when the hospital has no divisions
+}
+
+// ImportCounts totals what an import created
+type ImportCounts struct {
+	Shifts      int
+	Assignments int
+}
+
+// Importer writes extracted rows into a schedule version
+type Importer struct {
+	assignmentRepo repository.AssignmentRepository
+	personRepo     repository.PersonRepository
+	mappingRepo    repository.AmionShiftMappingRepository // Optional: maps Amion labels onto shift slots
+}
+
+// NewImporter creates an importer
+func NewImporter(
+	assignmentRepo repository.AssignmentRepository,
+	personRepo repository.PersonRepository,
+) *Importer {
+	return &Importer{
+		assignmentRepo: assignmentRepo,
+		personRepo:     personRepo,
+	}
+}
+
+// SetShiftMappingRepository enables the hospital's shift mappings: mapped
+// rows are attached to the version's shift instance for their slot, and
+// unmapped labels are reported
+func (im *Importer) SetShiftMappingRepository(repo repository.AmionShiftMappingRepository) {
+	im.mappingRepo = repo
+}
+
+// plannedShift is a shift instance and the rows whose staff it receives
+type plannedShift struct {
+	shift    *entity.ShiftInstance
+	existing bool // Already in the version
+	rows     []SourcedRow
+}
+
+// Import attaches each row to a shift instance and creates an assignment for
+// each staff name that resolves to a person.
+//
+// Rows whose label has a shift mapping share the version's shift instance for
+// the mapped slot, creating it if the version has none. Other rows reuse an
+// unclaimed shift instance of the version for their slot, preferring one with
+// the row's times, or else get a shift instance of their own, typed by the
+// division's shift name mapping or the Amion label itself; with shift
+// mappings enabled, their labels are reported in one
+// AMION_UNMAPPED_SHIFT_LABELS warning. People already assigned to a shift are
+// not assigned again, so importing the same rows twice changes nothing.
+//
+// Rows for a slot (date, shift type, study type and specialty) holding a
+// pinned (MANUAL or OVERRIDE) assignment in the version are skipped; when
+// Amion's staff differ from the pinned staff, an OVERRIDE_CONFLICT warning
+// lists both.
+//
+// The version is locked and everything is written in one transaction.
+// Every shift instance and assignment created records batchID, so the batch
+// can be rolled back. Row-level problems are added to vr as warnings; a
+// repository failure stops the import and writes nothing.
+func (im *Importer) Import(
+	ctx context.Context,
+	version *entity.ScheduleVersion,
+	rows []SourcedRow,
+	batchID uuid.UUID,
+	creatorID uuid.UUID,
+	vr *validation.Result,
+) (ImportCounts, error) {
+	var counts ImportCounts
+
+	people, err := im.personRepo.GetByHospital(ctx, version.HospitalID)
+	if err != nil {
+		return counts, fmt.Errorf("failed to load staff: %w", err)
+	}
+	personIDs := personIndex(people)
+
+	var mappings []*entity.AmionShiftMapping
+	if im.mappingRepo != nil {
+		if mappings, err = im.mappingRepo.GetByHospital(ctx, version.HospitalID); err != nil {
+			return counts, fmt.Errorf("failed to load shift mappings: %w", err)
+		}
+	}
+
+	err = im.assignmentRepo.EditSchedule(ctx, version.ID, creatorID, func(_ *entity.ScheduleVersion, existing []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.ScheduleChanges, error) {
+		counts = ImportCounts{}
+		planned, conflictKeys, conflicts, pinned := im.planImport(version, mappings, existing, assignments, rows, batchID, creatorID, vr)
+
+		assigned := make(map[uuid.UUID]map[uuid.UUID]bool)
+		for _, a := range assignments {
+			if assigned[a.ShiftInstanceID] == nil {
+				assigned[a.ShiftInstanceID] = make(map[uuid.UUID]bool)
+			}
+			assigned[a.ShiftInstanceID][a.PersonID] = true
+		}
+
+		changes := &entity.ScheduleChanges{}
+		for _, p := range planned {
+			if !p.existing {
+				changes.CreateShifts = append(changes.CreateShifts, p.shift)
+			}
+			if assigned[p.shift.ID] == nil {
+				assigned[p.shift.ID] = make(map[uuid.UUID]bool)
+			}
+			for _, row := range p.rows {
+				changes.Assignments.Create = append(changes.Assignments.Create,
+					assignStaff(p.shift, row, personIDs, assigned[p.shift.ID], batchID, creatorID, vr)...)
+			}
+		}
+
+		for _, key := range conflictKeys {
+			addOverrideConflict(vr, key, conflicts[key], pinned[key], people, personIDs)
+		}
+		counts.Shifts = len(changes.CreateShifts)
+		counts.Assignments = len(changes.Assignments.Create)
+		return changes, nil
+	})
+	if err != nil {
+		return ImportCounts{}, fmt.Errorf("failed to import rows: %w", err)
+	}
+	return counts, nil
+}
+
+// planImport attaches the rows to the version's shift instances or new ones,
+// and collects the rows skipped for pinned slots
+func (im *Importer) planImport(
+	version *entity.ScheduleVersion,
+	mappings []*entity.AmionShiftMapping,
+	existing []*entity.ShiftInstance,
+	assignments []*entity.Assignment,
+	rows []SourcedRow,
+	batchID uuid.UUID,
+	creatorID uuid.UUID,
+	vr *validation.Result,
+) ([]*plannedShift, []entity.ShiftSlot, map[entity.ShiftSlot][]SourcedRow, map[entity.ShiftSlot][]*entity.Assignment) {
+	pinned := pinnedAssignments(existing, assignments)
+	slots := make(map[entity.ShiftSlot]*plannedShift)
+	unclaimed := make(map[entity.ShiftSlot][]*entity.ShiftInstance)
+	for _, shift := range existing {
+		if len(mappings) > 0 {
+			if _, ok := slots[shift.Slot()]; !ok {
+				slots[shift.Slot()] = &plannedShift{shift: shift, existing: true}
+			}
+		}
+		unclaimed[shift.Slot()] = append(unclaimed[shift.Slot()], shift)
+	}
+
+	var planned []*plannedShift
+	unmapped := make(map[string]int)
+	var conflictKeys []entity.ShiftSlot
+	conflicts := make(map[entity.ShiftSlot][]SourcedRow)
+	skipPinned := func(key entity.ShiftSlot, row SourcedRow) bool {
+		if _, ok := pinned[key]; !ok {
+			return false
+		}
+		if _, ok := conflicts[key]; !ok {
+			conflictKeys = append(conflictKeys, key)
+		}
+		conflicts[key] = append(conflicts[key], row)
+		return true
+	}
+	for _, row := range rows {
+		date, err := time.Parse("2006-01-02", row.Date)
+		if err != nil {
+			vr.AddWarningWithContext("AMION_ROW_INVALID",
+				fmt.Sprintf("row %d: date: invalid date %q", row.Index, row.Date), rowContext(row))
+			continue
+		}
+
+		slot, mapping := rowSlot(mappings, row)
+		if mapping == nil {
+			if im.mappingRepo != nil && string(slot.ShiftType) == row.ShiftType {
+				unmapped[strings.TrimSpace(row.ShiftType)]++
+			}
+			if skipPinned(slot, row) {
+				continue
+			}
+			if shift := claimShift(unclaimed, slot, row); shift != nil {
+				planned = append(planned, &plannedShift{shift: shift, existing: true, rows: []SourcedRow{row}})
+				continue
+			}
+			shift := newShiftInstance(version, date, row, batchID, creatorID)
+			shift.ShiftType = slot.ShiftType
+			planned = append(planned, &plannedShift{shift: shift, rows: []SourcedRow{row}})
+			continue
+		}
+
+		if skipPinned(slot, row) {
+			continue
+		}
+		p, ok := slots[slot]
+		if !ok {
+			shift := newShiftInstance(version, date, row, batchID, creatorID)
+			shift.ShiftType = slot.ShiftType
+			shift.StudyType = slot.StudyType
+			shift.SpecialtyConstraint = slot.Specialty
+			shift.DesiredCoverage = 0
+			p = &plannedShift{shift: shift}
+			slots[slot] = p
+		}
+		if len(p.rows) == 0 {
+			planned = append(planned, p)
+		}
+		if !p.existing {
+			p.shift.DesiredCoverage += len(row.StaffNames) + row.OpenSlots
+		}
+		p.rows = append(p.rows, row)
+	}
+
+	if len(unmapped) > 0 {
+		addUnmappedLabels(vr, unmapped)
+	}
+	return planned, conflictKeys, conflicts, pinned
+}
+
+// claimShift takes an existing shift instance for an unmapped row's slot out
+// of unclaimed, preferring one with the row's times; nil if there is none
+func claimShift(unclaimed map[entity.ShiftSlot][]*entity.ShiftInstance, slot entity.ShiftSlot, row SourcedRow) *entity.ShiftInstance {
+	candidates := unclaimed[slot]
+	if len(candidates) == 0 {
+		return nil
+	}
+	i := 0
+	for j, shift := range candidates {
+		if shift.StartTime == row.StartTime && shift.EndTime == row.EndTime {
+			i = j
+			break
+		}
+	}
+	shift := candidates[i]
+	unclaimed[slot] = append(candidates[:i:i], candidates[i+1:]...)
+	return shift
+}
+
+// pinnedAssignments indexes the version's pinned assignments by the slot of
+// their shift
+func pinnedAssignments(shifts []*entity.ShiftInstance, assignments []*entity.Assignment) map[entity.ShiftSlot][]*entity.Assignment {
+	pinned := make(map[entity.ShiftSlot][]*entity.Assignment)
+	byID := make(map[uuid.UUID]*entity.ShiftInstance, len(shifts))
+	for _, shift := range shifts {
+		byID[shift.ID] = shift
+	}
+	for _, a := range assignments {
+		shift, ok := byID[a.ShiftInstanceID]
+		if !ok || !a.IsPinned() {
+			continue
+		}
+		pinned[shift.Slot()] = append(pinned[shift.Slot()], a)
+	}
+	return pinned
+}
+
+// rowSlot returns the slot a row's shift fills and the hospital shift mapping
+// that placed it there. Without a matching mapping the slot is typed by the
+// row's division mapping, or else by the Amion label itself.
+func rowSlot(mappings []*entity.AmionShiftMapping, row SourcedRow) (entity.ShiftSlot, *entity.AmionShiftMapping) {
+	mapping := entity.MatchAmionShiftMapping(mappings, row.ShiftType, row.Location, row.StartTime, row.EndTime)
+	if mapping != nil {
+		return entity.ShiftSlot{Date: row.Date, ShiftType: mapping.ShiftType, StudyType: mapping.StudyType, Specialty: mapping.SpecialtyConstraint}, mapping
+	}
+	shiftType := row.ShiftType
+	if row.Division != nil {
+		shiftType = row.Division.MapShiftName(row.ShiftType)
+	}
+	return entity.ShiftSlot{Date: row.Date, ShiftType: entity.ShiftType(shiftType)}, nil
+}
+
+// newShiftInstance creates an unsaved shift instance for a row, typed by its Amion label
+func newShiftInstance(version *entity.ScheduleVersion, date time.Time, row SourcedRow, batchID uuid.UUID, creatorID uuid.UUID) *entity.ShiftInstance {
+	return &entity.ShiftInstance{
+		ID:                uuid.New(),
+		ScheduleVersionID: version.ID,
+		ShiftType:         entity.ShiftType(row.ShiftType),
+		ScheduleDate:      date,
+		StartTime:         row.StartTime,
+		EndTime:           row.EndTime,
+		HospitalID:        version.HospitalID,
+		DesiredCoverage:   len(row.StaffNames) + row.OpenSlots,
+		ScrapeBatchID:     &batchID,
+		CreatedAt:         entity.Now(),
+		CreatedBy:         creatorID,
+	}
+}
+
+// assignStaff returns an assignment to shift for each of the row's staff
+// names not already in assigned, adding them to it
+func assignStaff(
+	shift *entity.ShiftInstance,
+	row SourcedRow,
+	personIDs map[string]uuid.UUID,
+	assigned map[uuid.UUID]bool,
+	batchID uuid.UUID,
+	creatorID uuid.UUID,
+	vr *validation.Result,
+) []*entity.Assignment {
+	var created []*entity.Assignment
+	for _, name := range row.StaffNames {
+		personID, ok := personIDs[normalizeName(name)]
+		if !ok {
+			notFound := rowContext(row)
+			notFound["name"] = name
+			vr.AddWarningWithContext("AMION_PERSON_NOT_FOUND",
+				fmt.Sprintf("No staff member matches Amion name %q", name), notFound)
+			continue
+		}
+		if assigned[personID] {
+			continue
+		}
+		assigned[personID] = true
+
+		created = append(created, &entity.Assignment{
+			ID:                uuid.New(),
+			PersonID:          personID,
+			ShiftInstanceID:   shift.ID,
+			ScheduleDate:      shift.ScheduleDate,
+			OriginalShiftType: row.ShiftType,
+			Source:            entity.AssignmentSourceAmion,
+			ScrapeBatchID:     &batchID,
+			CreatedAt:         entity.Now(),
+			CreatedBy:         creatorID,
+		})
+	}
+	return created
+}
+
+// rowContext locates a row in warnings
+func rowContext(row SourcedRow) map[string]interface{} {
+	c := map[string]interface{}{"url": row.URL, "row": row.Index}
+	if row.Division != nil {
+		c["division"] = row.Division.Name
+	}
+	return c
+}
+
+// addOverrideConflict reports Amion rows skipped for a pinned slot,
+// unless Amion already agrees with the pinned staff
+func addOverrideConflict(
+	vr *validation.Result,
+	key entity.ShiftSlot,
+	rows []SourcedRow,
+	pins []*entity.Assignment,
+	people []*entity.Person,
+	personIDs map[string]uuid.UUID,
+) {
+	names := make(map[uuid.UUID]string, len(people))
+	for _, p := range people {
+		names[p.ID] = p.Name
+	}
+
+	var amion []string
+	amionIDs := make(map[uuid.UUID]bool)
+	agrees := true
+	for _, row := range rows {
+		for _, name := range row.StaffNames {
+			amion = append(amion, name)
+			id, ok := personIDs[normalizeName(name)]
+			agrees = agrees && ok
+			amionIDs[id] = true
+		}
+	}
+
+	pinnedNames := make([]string, 0, len(pins))
+	pinnedIDs := make([]string, 0, len(pins))
+	pinnedPeople := make(map[uuid.UUID]bool)
+	for _, a := range pins {
+		name, ok := names[a.PersonID]
+		if !ok {
+			name = a.PersonID.String()
+		}
+		pinnedNames = append(pinnedNames, name)
+		pinnedIDs = append(pinnedIDs, a.ID.String())
+		pinnedPeople[a.PersonID] = true
+		agrees = agrees && amionIDs[a.PersonID]
+	}
+	if agrees && len(amionIDs) == len(pinnedPeople) {
+		return
+	}
+
+	says := "nobody"
+	if len(amion) > 0 {
+		says = strings.Join(amion, ", ")
+	}
+	vr.AddWarningWithContext("OVERRIDE_CONFLICT", fmt.Sprintf(
+		"%s on %s: Amion has %s, but %s is pinned; the Amion rows were skipped",
+		key.ShiftType, key.Date, says, strings.Join(pinnedNames, ", ")),
+		map[string]interface{}{
+			"date":                  key.Date,
+			"shift_type":            string(key.ShiftType),
+			"study_type":            string(key.StudyType),
+			"specialty":             string(key.Specialty),
+			"amion":                 amion,
+			"pinned":                pinnedNames,
+			"pinned_assignment_ids": pinnedIDs,
+		})
+}
+
+// addUnmappedLabels reports every label without a shift mapping in one
+// warning, most frequent first, with the row count per label in its context
+func addUnmappedLabels(vr *validation.Result, unmapped map[string]int) {
+	labels := make([]string, 0, len(unmapped))
+	for label := range unmapped {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if unmapped[labels[i]] != unmapped[labels[j]] {
+			return unmapped[labels[i]] > unmapped[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+
+	listed := make([]string, len(labels))
+	counts := make(map[string]interface{}, len(labels))
+	for i, label := range labels {
+		listed[i] = fmt.Sprintf("%q (%d)", label, unmapped[label])
+		counts[label] = unmapped[label]
+	}
+	vr.AddWarningWithContext("AMION_UNMAPPED_SHIFT_LABELS",
+		fmt.Sprintf("%d Amion shift labels have no shift mapping: %s", len(labels), strings.Join(listed, ", ")),
+		map[string]interface{}{"labels": counts})
+}
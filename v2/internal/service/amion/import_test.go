@@ -0,0 +1,220 @@
+package amion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockShiftMappingRepo struct {
+	repository.AmionShiftMappingRepository
+	mappings []*entity.AmionShiftMapping
+}
+
+func (m *mockShiftMappingRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.AmionShiftMapping, error) {
+	return m.mappings, nil
+}
+
+func TestImporter_ShiftMappings(t *testing.T) {
+	hospitalID := uuid.New()
+	version := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: hospitalID}
+	nov3 := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+
+	// The version already holds the day slot, e.g. from the ODS import
+	odsDay := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: version.ID, ShiftType: entity.ShiftTypeDay,
+		StudyType: entity.StudyTypeGeneral, SpecialtyConstraint: entity.SpecialtyBoth, ScheduleDate: nov3, DesiredCoverage: 3}
+	shiftRepo := &mockShiftRepo{shifts: []*entity.ShiftInstance{odsDay}}
+	assignmentRepo := &mockAssignmentRepo{shifts: shiftRepo}
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+	ann := &entity.Person{ID: uuid.New(), Name: "Ann Lee", Active: true}
+	bob := &entity.Person{ID: uuid.New(), Name: "Bob Ray", Active: true}
+
+	importer := NewImporter(assignmentRepo, &mockPersonRepo{people: []*entity.Person{jane, ann, bob}})
+	importer.SetShiftMappingRepository(&mockShiftMappingRepo{mappings: []*entity.AmionShiftMapping{
+		{AmionLabel: "Day Reader", ShiftType: entity.ShiftTypeDay, StudyType: entity.StudyTypeGeneral, SpecialtyConstraint: entity.SpecialtyBoth},
+		{AmionLabel: "body late", ShiftType: entity.ShiftTypeMidL, StudyType: entity.StudyTypeBodyImaging, SpecialtyConstraint: entity.SpecialtyBodyOnly},
+		{AmionLabel: "Body Late", Location: "South", StartTime: "13:00", ShiftType: entity.ShiftTypeMidC,
+			StudyType: entity.StudyTypeBodyImaging, SpecialtyConstraint: entity.SpecialtyBodyOnly},
+	}})
+
+	row := func(date, label, start, location string, staff []string, open int) SourcedRow {
+		return SourcedRow{Row: Row{Date: date, ShiftType: label, StartTime: start, EndTime: "21:00", Location: location, StaffNames: staff, OpenSlots: open}}
+	}
+	rows := []SourcedRow{
+		row("2025-11-03", "Day Reader", "07:00", "Main", []string{"Jane Smith"}, 0),
+		row("2025-11-03", "Body Late", "13:00", "North", []string{"Ann Lee"}, 0),
+		row("2025-11-03", "Body Late", "13:00", "South", []string{"Bob Ray"}, 0),
+		row("2025-11-03", "Body Late", "13:00", "East", nil, 1),
+		row("2025-11-04", "Technologist", "07:00", "Main", nil, 1),
+		row("2025-11-05", "Technologist", "07:00", "Main", nil, 1),
+		row("2025-11-04", "IR", "08:00", "Main", nil, 1),
+	}
+
+	vr := validation.NewResult()
+	batchID := uuid.New()
+	counts, err := importer.Import(context.Background(), version, rows, batchID, uuid.New(), vr)
+	require.NoError(t, err)
+
+	assert.Equal(t, ImportCounts{Shifts: 5, Assignments: 3}, counts)
+	assert.Nil(t, odsDay.ScrapeBatchID, "existing shifts keep their lineage")
+	created := shiftRepo.shifts[1:]
+	require.Len(t, created, 5)
+	for _, shift := range created {
+		assert.Equal(t, &batchID, shift.ScrapeBatchID)
+	}
+	for _, a := range assignmentRepo.assignments {
+		assert.Equal(t, &batchID, a.ScrapeBatchID)
+	}
+
+	midL := created[0]
+	assert.Equal(t, entity.ShiftTypeMidL, midL.ShiftType)
+	assert.Equal(t, entity.StudyTypeBodyImaging, midL.StudyType)
+	assert.Equal(t, entity.SpecialtyBodyOnly, midL.SpecialtyConstraint)
+	assert.Equal(t, 2, midL.DesiredCoverage, "both Body Late rows outside South share the slot")
+	assert.Equal(t, entity.ShiftTypeMidC, created[1].ShiftType, "the more specific mapping wins")
+	assert.Equal(t, entity.ShiftType("Technologist"), created[2].ShiftType)
+
+	byPerson := map[uuid.UUID]*entity.Assignment{}
+	for _, a := range assignmentRepo.assignments {
+		byPerson[a.PersonID] = a
+	}
+	assert.Equal(t, odsDay.ID, byPerson[jane.ID].ShiftInstanceID, "attached to the existing slot")
+	assert.Equal(t, 3, odsDay.DesiredCoverage, "existing slots keep their coverage")
+	assert.Equal(t, midL.ID, byPerson[ann.ID].ShiftInstanceID)
+	assert.Equal(t, created[1].ID, byPerson[bob.ID].ShiftInstanceID)
+	assert.Equal(t, "Body Late", byPerson[ann.ID].OriginalShiftType)
+
+	unmapped := vr.MessagesByCode("AMION_UNMAPPED_SHIFT_LABELS")
+	require.Len(t, unmapped, 1, "one grouped warning")
+	assert.Equal(t, validation.SeverityWarning, unmapped[0].Severity)
+	assert.Contains(t, unmapped[0].Text, `"Technologist" (2), "IR" (1)`)
+	assert.Equal(t, map[string]interface{}{"Technologist": 2, "IR": 1}, unmapped[0].Context["labels"])
+}
+
+func TestImporter_WithoutShiftMappings(t *testing.T) {
+	shiftRepo := &mockShiftRepo{}
+	importer := NewImporter(&mockAssignmentRepo{shifts: shiftRepo}, &mockPersonRepo{})
+
+	rows := []SourcedRow{{Row: Row{Date: "2025-11-03", ShiftType: "Technologist", StartTime: "07:00", EndTime: "17:00", OpenSlots: 1}}}
+	vr := validation.NewResult()
+	counts, err := importer.Import(context.Background(), &entity.ScheduleVersion{ID: uuid.New()}, rows, uuid.New(), uuid.New(), vr)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, counts.Shifts)
+	assert.Equal(t, entity.ShiftType("Technologist"), shiftRepo.shifts[0].ShiftType)
+	assert.Empty(t, vr.MessagesByCode("AMION_UNMAPPED_SHIFT_LABELS"), "unmapped labels are only reported once mappings are enabled")
+}
+
+func TestImporter_SkipsPinnedShifts(t *testing.T) {
+	version := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: uuid.New()}
+	nov3 := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+	ann := &entity.Person{ID: uuid.New(), Name: "Ann Lee", Active: true}
+
+	// A scheduler put Jane on ON1 by hand; Amion still has Ann there
+	on1 := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: version.ID, ShiftType: "ON1", ScheduleDate: nov3}
+	day := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: version.ID, ShiftType: "DAY", ScheduleDate: nov3}
+	shiftRepo := &mockShiftRepo{shifts: []*entity.ShiftInstance{on1, day}}
+	override := &entity.Assignment{ID: uuid.New(), PersonID: jane.ID, ShiftInstanceID: on1.ID, Source: entity.AssignmentSourceOverride}
+	manual := &entity.Assignment{ID: uuid.New(), PersonID: ann.ID, ShiftInstanceID: day.ID, Source: entity.AssignmentSourceManual}
+	assignmentRepo := &mockAssignmentRepo{assignments: []*entity.Assignment{override, manual}, shifts: shiftRepo}
+
+	importer := NewImporter(assignmentRepo, &mockPersonRepo{people: []*entity.Person{jane, ann}})
+	rows := []SourcedRow{
+		{Row: Row{Date: "2025-11-03", ShiftType: "ON1", StaffNames: []string{"Ann Lee"}}},
+		{Row: Row{Date: "2025-11-03", ShiftType: "DAY", StaffNames: []string{"Ann Lee"}}},
+		{Row: Row{Date: "2025-11-04", ShiftType: "ON1", StaffNames: []string{"Ann Lee"}}},
+	}
+
+	vr := validation.NewResult()
+	counts, err := importer.Import(context.Background(), version, rows, uuid.New(), uuid.New(), vr)
+	require.NoError(t, err)
+
+	assert.Equal(t, ImportCounts{Shifts: 1, Assignments: 1}, counts, "only the unpinned date is imported")
+	conflicts := vr.MessagesByCode("OVERRIDE_CONFLICT")
+	require.Len(t, conflicts, 1, "Amion agrees with the pinned DAY shift")
+	assert.Equal(t, "2025-11-03", conflicts[0].Context["date"])
+	assert.Equal(t, []string{"Ann Lee"}, conflicts[0].Context["amion"])
+	assert.Equal(t, []string{"Jane Smith"}, conflicts[0].Context["pinned"])
+	assert.Equal(t, []string{override.ID.String()}, conflicts[0].Context["pinned_assignment_ids"])
+
+	// Once unpinned, the next import replaces the override's shift/date
+	require.NoError(t, override.Unpin(uuid.New()))
+	vr = validation.NewResult()
+	counts, err = importer.Import(context.Background(), version, rows[:1], uuid.New(), uuid.New(), vr)
+	require.NoError(t, err)
+	assert.Equal(t, 1, counts.Assignments)
+	assert.Empty(t, vr.MessagesByCode("OVERRIDE_CONFLICT"))
+}
+
+func TestImporter_PinsLockOnlyTheirSlot(t *testing.T) {
+	version := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: uuid.New()}
+	nov3 := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+	ann := &entity.Person{ID: uuid.New(), Name: "Ann Lee", Active: true}
+
+	// Jane is pinned to the neuro overnight; the body overnight that day is free
+	neuro := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: version.ID, ShiftType: "ON1", ScheduleDate: nov3,
+		StudyType: entity.StudyTypeNeuroImaging, SpecialtyConstraint: entity.SpecialtyNeuroOnly}
+	body := &entity.ShiftInstance{ID: uuid.New(), ScheduleVersionID: version.ID, ShiftType: "ON1", ScheduleDate: nov3,
+		StudyType: entity.StudyTypeBodyImaging, SpecialtyConstraint: entity.SpecialtyBodyOnly}
+	shiftRepo := &mockShiftRepo{shifts: []*entity.ShiftInstance{neuro, body}}
+	pin := &entity.Assignment{ID: uuid.New(), PersonID: jane.ID, ShiftInstanceID: neuro.ID, Source: entity.AssignmentSourceManual}
+	assignmentRepo := &mockAssignmentRepo{assignments: []*entity.Assignment{pin}, shifts: shiftRepo}
+
+	importer := NewImporter(assignmentRepo, &mockPersonRepo{people: []*entity.Person{jane, ann}})
+	importer.SetShiftMappingRepository(&mockShiftMappingRepo{mappings: []*entity.AmionShiftMapping{
+		{AmionLabel: "Neuro ON1", ShiftType: "ON1", StudyType: entity.StudyTypeNeuroImaging, SpecialtyConstraint: entity.SpecialtyNeuroOnly},
+		{AmionLabel: "Body ON1", ShiftType: "ON1", StudyType: entity.StudyTypeBodyImaging, SpecialtyConstraint: entity.SpecialtyBodyOnly},
+	}})
+	rows := []SourcedRow{
+		{Row: Row{Date: "2025-11-03", ShiftType: "Neuro ON1", StaffNames: []string{"Ann Lee"}}},
+		{Row: Row{Date: "2025-11-03", ShiftType: "Body ON1", StaffNames: []string{"Ann Lee"}}},
+	}
+
+	vr := validation.NewResult()
+	counts, err := importer.Import(context.Background(), version, rows, uuid.New(), uuid.New(), vr)
+	require.NoError(t, err)
+
+	assert.Equal(t, ImportCounts{Assignments: 1}, counts)
+	assert.Equal(t, body.ID, assignmentRepo.assignments[1].ShiftInstanceID, "the body slot is imported")
+	conflicts := vr.MessagesByCode("OVERRIDE_CONFLICT")
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, string(entity.StudyTypeNeuroImaging), conflicts[0].Context["study_type"])
+}
+
+func TestImporter_ReimportAddsNothing(t *testing.T) {
+	version := &entity.ScheduleVersion{ID: uuid.New(), HospitalID: uuid.New()}
+	jane := &entity.Person{ID: uuid.New(), Name: "Jane Smith", Active: true}
+	ann := &entity.Person{ID: uuid.New(), Name: "Ann Lee", Active: true}
+	shiftRepo := &mockShiftRepo{}
+	assignmentRepo := &mockAssignmentRepo{shifts: shiftRepo}
+
+	importer := NewImporter(assignmentRepo, &mockPersonRepo{people: []*entity.Person{jane, ann}})
+	importer.SetShiftMappingRepository(&mockShiftMappingRepo{mappings: []*entity.AmionShiftMapping{
+		{AmionLabel: "Day Reader", ShiftType: entity.ShiftTypeDay, StudyType: entity.StudyTypeGeneral, SpecialtyConstraint: entity.SpecialtyBoth},
+	}})
+	rows := []SourcedRow{
+		{Row: Row{Date: "2025-11-03", ShiftType: "Day Reader", StartTime: "07:00", EndTime: "17:00", StaffNames: []string{"Jane Smith", "Ann Lee"}}},
+		{Row: Row{Date: "2025-11-03", ShiftType: "Technologist", StartTime: "07:00", EndTime: "15:00", StaffNames: []string{"Ann Lee"}}},
+		{Row: Row{Date: "2025-11-03", ShiftType: "Technologist", StartTime: "15:00", EndTime: "23:00", StaffNames: []string{"Jane Smith"}}},
+	}
+
+	counts, err := importer.Import(context.Background(), version, rows, uuid.New(), uuid.New(), validation.NewResult())
+	require.NoError(t, err)
+	assert.Equal(t, ImportCounts{Shifts: 3, Assignments: 4}, counts)
+
+	// A retried job or a re-import into the same version
+	counts, err = importer.Import(context.Background(), version, rows, uuid.New(), uuid.New(), validation.NewResult())
+	require.NoError(t, err)
+	assert.Equal(t, ImportCounts{}, counts)
+	assert.Len(t, shiftRepo.shifts, 3, "unmapped rows reuse their slot's shifts")
+	assert.Len(t, assignmentRepo.assignments, 4)
+}
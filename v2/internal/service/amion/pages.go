@@ -0,0 +1,48 @@
+package amion
+
+import (
+	"time"
+
+	amionweb "github.com/schedcu/reimplement/pkg/amion"
+)
+
+// Page views, as stored in AmionDivision.PageView
+const (
+	ViewMonth = string(amionweb.ViewMonth)
+	ViewWeek  = string(amionweb.ViewWeek)
+	ViewDay   = string(amionweb.ViewDay)
+)
+
+// Page is one schedule page and the dates kept from it
+type Page = amionweb.Page
+
+// URLTemplate returns the page layout of a division's URL template and page
+// view. An empty template is the monthly "/schedule/YYYY-MM" layout and an
+// empty view is a month view.
+func URLTemplate(template, view string) amionweb.URLTemplate {
+	t := amionweb.DefaultURLTemplate
+	if template != "" {
+		t.Pattern = template
+	}
+	if view != "" {
+		t.View = amionweb.PageView(view)
+	}
+	return t
+}
+
+// ValidateURLTemplate checks a page path template against its view. The
+// placeholders are {yyyy} {yy} {mm} {m} {dd} {d} and {date} (YYYY-MM-DD);
+// month views need the year and month, week and day views the full date.
+func ValidateURLTemplate(template, view string) error {
+	return URLTemplate(template, view).Validate()
+}
+
+// Pages lists the pages covering [from, to] (inclusive dates), each trimmed to
+// the part of the window it covers. Week views start on Sunday.
+func Pages(template, view string, from, to time.Time) ([]Page, error) {
+	t := URLTemplate(template, view)
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t.Pages(from, to)
+}
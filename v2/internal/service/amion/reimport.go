@@ -0,0 +1,265 @@
+package amion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	amionweb "github.com/schedcu/reimplement/pkg/amion"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// Reimporter re-runs extraction over an archived scrape batch, producing a
+// new STAGING schedule version without contacting Amion
+type Reimporter struct {
+	archive      *Archive
+	batchRepo    repository.ScrapeBatchRepository
+	versionRepo  repository.ScheduleVersionRepository
+	importer     *Importer
+	divisionRepo repository.AmionDivisionRepository // Optional: applies division shift name mappings and selector profiles
+	profiles     *amionweb.SelectorProfileSet
+}
+
+// NewReimporter creates a reimporter using the default selector profile
+func NewReimporter(
+	archive *Archive,
+	batchRepo repository.ScrapeBatchRepository,
+	versionRepo repository.ScheduleVersionRepository,
+	assignmentRepo repository.AssignmentRepository,
+	personRepo repository.PersonRepository,
+) *Reimporter {
+	return &Reimporter{
+		archive:     archive,
+		batchRepo:   batchRepo,
+		versionRepo: versionRepo,
+		importer:    NewImporter(assignmentRepo, personRepo),
+		profiles:    amionweb.DefaultSelectorProfiles(),
+	}
+}
+
+// SetDivisionRepository lets re-imports of multi-division batches extract
+// each page with its division's selector profile and map its shift labels
+// through the division's mapping
+func (r *Reimporter) SetDivisionRepository(repo repository.AmionDivisionRepository) {
+	r.divisionRepo = repo
+}
+
+// SetShiftMappingRepository maps re-imported shift labels through the
+// hospital's shift mappings (see Importer.SetShiftMappingRepository)
+func (r *Reimporter) SetShiftMappingRepository(repo repository.AmionShiftMappingRepository) {
+	r.importer.SetShiftMappingRepository(repo)
+}
+
+// SetSelectorProfiles replaces the selector profiles used for extraction,
+// e.g. after adding a profile for changed markup
+func (r *Reimporter) SetSelectorProfiles(profiles *amionweb.SelectorProfileSet) {
+	r.profiles = profiles
+}
+
+// ReimportResult describes a completed re-import
+type ReimportResult struct {
+	SourceBatchID uuid.UUID          `json:"source_batch_id"`
+	BatchID       uuid.UUID          `json:"batch_id"`
+	VersionID     uuid.UUID          `json:"version_id"`
+	Pages         int                `json:"pages"`
+	Shifts        int                `json:"shifts"`
+	Assignments   int                `json:"assignments"`
+	Validation    *validation.Result `json:"validation"`
+}
+
+// Reimport extracts every archived page of sourceBatchID into a new STAGING
+// version, tracked by a new scrape batch over the same window. Row-level
+// problems (bad dates, unknown staff) are reported in the validation result;
+// an unreadable archive fails the whole re-import.
+func (r *Reimporter) Reimport(ctx context.Context, sourceBatchID, creatorID uuid.UUID) (*ReimportResult, error) {
+	source, err := r.batchRepo.GetByID(ctx, sourceBatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := r.archive.Pages(ctx, sourceBatchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived pages: %w", err)
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("scrape batch %s has no archived pages", sourceBatchID)
+	}
+
+	// Read everything up front so a corrupt page fails before anything is written
+	htmlByPage := make([][]byte, len(pages))
+	hashes := make([]string, len(pages))
+	for i, page := range pages {
+		_, html, err := r.archive.Load(ctx, page.ID)
+		if err != nil {
+			return nil, err
+		}
+		htmlByPage[i] = html
+		hashes[i] = page.ContentHash
+	}
+
+	divisions, err := r.divisions(ctx, source.HospitalID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := entity.Now()
+	batch := &entity.ScrapeBatch{
+		ID:              uuid.New(),
+		HospitalID:      source.HospitalID,
+		State:           entity.BatchStatePending,
+		WindowStartDate: source.WindowStartDate,
+		WindowEndDate:   source.WindowEndDate,
+		ScrapedAt:       now,
+		CreatedAt:       now,
+		CreatedBy:       creatorID,
+	}
+	if err := r.batchRepo.Create(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to create scrape batch: %w", err)
+	}
+
+	version := &entity.ScheduleVersion{
+		ID:                 uuid.New(),
+		HospitalID:         source.HospitalID,
+		Status:             entity.VersionStatusStaging,
+		EffectiveStartDate: source.WindowStartDate,
+		EffectiveEndDate:   source.WindowEndDate,
+		ScrapeBatchID:      &batch.ID,
+		CreatedAt:          now,
+		CreatedBy:          creatorID,
+		UpdatedAt:          now,
+		UpdatedBy:          creatorID,
+	}
+	if err := r.versionRepo.Create(ctx, version); err != nil {
+		return r.fail(ctx, batch, fmt.Errorf("failed to create schedule version: %w", err))
+	}
+
+	result := &ReimportResult{
+		SourceBatchID: sourceBatchID,
+		BatchID:       batch.ID,
+		VersionID:     version.ID,
+		Pages:         len(pages),
+		Validation:    validation.NewResult(),
+	}
+
+	var rows []SourcedRow
+	for i, page := range pages {
+		var division *entity.AmionDivision
+		profileName := ""
+		if page.DivisionID != nil {
+			division = divisions[*page.DivisionID]
+			if division != nil {
+				profileName = division.SelectorProfile
+			}
+		}
+		profiles, err := selectorProfiles(r.profiles, profileName)
+		if err != nil {
+			return r.discard(ctx, batch, version, creatorID, fmt.Errorf("failed to extract %s: %w", page.URL, err))
+		}
+
+		extracted, rowErrors, err := ExtractRows(htmlByPage[i], profiles)
+		if err != nil {
+			return r.discard(ctx, batch, version, creatorID, fmt.Errorf("failed to extract %s: %w", page.URL, err))
+		}
+		for _, rowErr := range rowErrors {
+			result.Validation.AddWarningWithContext("AMION_ROW_INVALID", rowErr.Error(), map[string]interface{}{
+				"url": page.URL, "row": rowErr.Index, "field": rowErr.Field,
+			})
+		}
+
+		for _, row := range extracted {
+			// Same rule as the scrape: overlapping pages only own their window
+			if _, err := time.Parse("2006-01-02", row.Date); err == nil && !page.Contains(row.Date) {
+				continue
+			}
+			rows = append(rows, SourcedRow{Row: row, URL: page.URL, Division: division})
+		}
+	}
+
+	counts, err := r.importer.Import(ctx, version, rows, batch.ID, creatorID, result.Validation)
+	if err != nil {
+		return r.discard(ctx, batch, version, creatorID, err)
+	}
+	result.Shifts, result.Assignments = counts.Shifts, counts.Assignments
+
+	sort.Strings(hashes)
+	batch.IngestChecksum = ingestChecksum(hashes)
+	batch.MarkComplete(creatorID, result.Shifts)
+	if err := r.batchRepo.Update(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to complete scrape batch: %w", err)
+	}
+
+	result.Validation.AddInfo("AMION_REIMPORTED", fmt.Sprintf(
+		"Re-imported %d shifts and %d assignments from %d archived pages of batch %s",
+		result.Shifts, result.Assignments, result.Pages, sourceBatchID))
+	return result, nil
+}
+
+// divisions indexes the hospital's divisions by ID. Pages of a division
+// deleted since the scrape are imported without a mapping.
+func (r *Reimporter) divisions(ctx context.Context, hospitalID uuid.UUID) (map[uuid.UUID]*entity.AmionDivision, error) {
+	index := make(map[uuid.UUID]*entity.AmionDivision)
+	if r.divisionRepo == nil {
+		return index, nil
+	}
+	divisions, err := r.divisionRepo.GetByHospital(ctx, hospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load amion divisions: %w", err)
+	}
+	for _, d := range divisions {
+		index[d.ID] = d
+	}
+	return index, nil
+}
+
+// discard deletes the partially imported version, then fails the batch
+func (r *Reimporter) discard(ctx context.Context, batch *entity.ScrapeBatch, version *entity.ScheduleVersion, creatorID uuid.UUID, err error) (*ReimportResult, error) {
+	if cleanupErr := r.versionRepo.Delete(ctx, version.ID, creatorID); cleanupErr != nil {
+		err = fmt.Errorf("%w (and failed to delete the partial version %s: %v)", err, version.ID, cleanupErr)
+	}
+	return r.fail(ctx, batch, err)
+}
+
+// fail marks the new batch FAILED and returns err
+func (r *Reimporter) fail(ctx context.Context, batch *entity.ScrapeBatch, err error) (*ReimportResult, error) {
+	batch.MarkFailed(err.Error())
+	if updateErr := r.batchRepo.Update(ctx, batch); updateErr != nil {
+		return nil, fmt.Errorf("%w (and failed to mark batch failed: %v)", err, updateErr)
+	}
+	return nil, err
+}
+
+// personIndex maps normalized names and aliases to person IDs. Inactive and
+// deleted staff are left out.
+func personIndex(people []*entity.Person) map[string]uuid.UUID {
+	index := make(map[string]uuid.UUID)
+	for _, p := range people {
+		if !p.Active || p.DeletedAt != nil {
+			continue
+		}
+		for _, name := range append([]string{p.Name}, p.Aliases...) {
+			if key := normalizeName(name); key != "" {
+				index[key] = p.ID
+			}
+		}
+	}
+	return index
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ingestChecksum combines the archived page hashes, so two batches imported
+// from identical pages share a checksum. Callers sort the hashes first so the
+// checksum does not depend on fetch or archive order.
+func ingestChecksum(pageHashes []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(pageHashes, "\n")))
+	return hex.EncodeToString(sum[:])
+}
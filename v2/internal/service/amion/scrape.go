@@ -0,0 +1,398 @@
+package amion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	amionweb "github.com/schedcu/reimplement/pkg/amion"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/secrets"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// DefaultSelectorProfile names the profile used when a division sets none
+const DefaultSelectorProfile = amionweb.DefaultProfileName
+
+// DefaultRequestInterval is the minimum time between requests to one Amion site
+const DefaultRequestInterval = time.Second
+
+// DivisionWorkers bounds how many of a division's pages are fetched at once
+const DivisionWorkers = 2
+
+// ErrNoDivisions is returned by Scraper.Scrape for a hospital without active
+// Amion divisions, which is scraped through its single legacy login instead
+var ErrNoDivisions = errors.New("hospital has no active amion divisions")
+
+// Scraper scrapes every active Amion division of a hospital concurrently and
+// merges the results into one scrape batch. Pages are fetched with the
+// hardened Amion client: rate limited and circuit broken per site, retried
+// with backoff, and checked for selector drift before they are imported.
+type Scraper struct {
+	divisionRepo repository.AmionDivisionRepository
+	batchRepo    repository.ScrapeBatchRepository
+	importer     *Importer
+	secrets      secrets.Provider // Optional: needed by divisions with a CredentialsRef
+	archive      *Archive         // Optional: archives every fetched page
+	changes      *ChangeStager    // Optional: diffs each scrape against the previous one
+	profiles     *amionweb.SelectorProfileSet
+	fingerprints amionweb.FingerprintStore
+	interval     time.Duration
+
+	mu    sync.Mutex
+	sites map[string]*site // By base URL
+}
+
+// site is the throttling state of one Amion site, shared by every division
+// and scrape that fetches from it
+type site struct {
+	limiter *amionweb.RateLimiter
+	breaker *amionweb.CircuitBreaker
+}
+
+// NewScraper creates a scraper with the default selector profile and
+// in-memory drift fingerprints
+func NewScraper(
+	divisionRepo repository.AmionDivisionRepository,
+	batchRepo repository.ScrapeBatchRepository,
+	importer *Importer,
+) *Scraper {
+	return &Scraper{
+		divisionRepo: divisionRepo,
+		batchRepo:    batchRepo,
+		importer:     importer,
+		profiles:     amionweb.DefaultSelectorProfiles(),
+		fingerprints: amionweb.NewMemoryFingerprintStore(),
+		interval:     DefaultRequestInterval,
+		sites:        make(map[string]*site),
+	}
+}
+
+// SetSecretProvider enables resolving division credentials
+func (s *Scraper) SetSecretProvider(provider secrets.Provider) {
+	s.secrets = provider
+}
+
+// SetArchive enables archiving fetched pages against the scrape batch
+func (s *Scraper) SetArchive(archive *Archive) {
+	s.archive = archive
+}
+
+// SetChangeStager enables comparing each completed scrape with the previous
+// one and staging changes to the PRODUCTION schedule
+func (s *Scraper) SetChangeStager(changes *ChangeStager) {
+	s.changes = changes
+}
+
+// SetSelectorProfiles replaces the selector profiles divisions refer to by
+// name (AmionDivision.SelectorProfile)
+func (s *Scraper) SetSelectorProfiles(profiles *amionweb.SelectorProfileSet) {
+	s.profiles = profiles
+}
+
+// SelectorProfileNames lists the profile names divisions may use
+func (s *Scraper) SelectorProfileNames() []string {
+	return selectorProfileNames(s.profiles)
+}
+
+// SetFingerprintStore replaces where each division's known-good page
+// structure is kept, e.g. with a file store so drift is caught across restarts
+func (s *Scraper) SetFingerprintStore(store amionweb.FingerprintStore) {
+	s.fingerprints = store
+}
+
+// SetRequestInterval changes the minimum time between requests to one Amion
+// site. It applies to sites not yet contacted.
+func (s *Scraper) SetRequestInterval(interval time.Duration) {
+	s.interval = interval
+}
+
+// site returns the throttling state for a base URL
+func (s *Scraper) site(baseURL string) *site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.sites[baseURL]
+	if !ok {
+		st = &site{
+			limiter: amionweb.NewRateLimiter(s.interval),
+			breaker: amionweb.NewCircuitBreaker(amionweb.DefaultFailureThreshold, amionweb.DefaultOpenTimeout),
+		}
+		s.sites[baseURL] = st
+	}
+	return st
+}
+
+// divisionFingerprints keys known-good fingerprints by division as well as
+// profile: divisions on different sites share profiles but not markup
+type divisionFingerprints struct {
+	store    amionweb.FingerprintStore
+	division uuid.UUID
+}
+
+// KnownGood implements amionweb.FingerprintStore
+func (f divisionFingerprints) KnownGood(profileID string) (amionweb.PageFingerprint, bool) {
+	return f.store.KnownGood(f.division.String() + "/" + profileID)
+}
+
+// RecordKnownGood implements amionweb.FingerprintStore
+func (f divisionFingerprints) RecordKnownGood(profileID string, fp amionweb.PageFingerprint) error {
+	return f.store.RecordKnownGood(f.division.String()+"/"+profileID, fp)
+}
+
+// fetchedPage is one page a division fetched, with the rows kept from it
+type fetchedPage struct {
+	page      Page
+	url       string
+	html      []byte
+	fetchedAt time.Time
+	rows      []SourcedRow
+}
+
+// divisionScrape is the outcome of scraping one division
+type divisionScrape struct {
+	division *entity.AmionDivision
+	pages    []fetchedPage
+	messages *validation.Result
+	errors   []string // Page or division level failures
+}
+
+// Scrape fetches every active division of the version's hospital over the
+// version's effective window and imports the rows into the version. Divisions
+// are scraped concurrently, pages within a division one at a time. A failing
+// division is recorded in the batch's DivisionStats and the rest are still
+// imported; the batch is marked FAILED only if no division returned a page.
+// With a change stager, the completed batch is then compared with the
+// previous scrape (see ChangeStager.Record); a failed comparison is a
+// warning. Returns ErrNoDivisions if the hospital has no active divisions.
+func (s *Scraper) Scrape(
+	ctx context.Context,
+	version *entity.ScheduleVersion,
+	creatorID uuid.UUID,
+) (*entity.ScrapeBatch, *validation.Result, error) {
+	all, err := s.divisionRepo.GetByHospital(ctx, version.HospitalID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load amion divisions: %w", err)
+	}
+	var divisions []*entity.AmionDivision
+	for _, d := range all {
+		if d.Active && d.DeletedAt == nil {
+			divisions = append(divisions, d)
+		}
+	}
+	if len(divisions) == 0 {
+		return nil, nil, ErrNoDivisions
+	}
+
+	now := entity.Now()
+	batch := &entity.ScrapeBatch{
+		ID:              uuid.New(),
+		HospitalID:      version.HospitalID,
+		State:           entity.BatchStatePending,
+		WindowStartDate: version.EffectiveStartDate,
+		WindowEndDate:   version.EffectiveEndDate,
+		ScrapedAt:       now,
+		CreatedAt:       now,
+		CreatedBy:       creatorID,
+	}
+	if err := s.batchRepo.Create(ctx, batch); err != nil {
+		return nil, nil, fmt.Errorf("failed to create scrape batch: %w", err)
+	}
+
+	scrapes := make([]*divisionScrape, len(divisions))
+	var wg sync.WaitGroup
+	for i, division := range divisions {
+		wg.Add(1)
+		go func(i int, division *entity.AmionDivision) {
+			defer wg.Done()
+			scrapes[i] = s.scrapeDivision(ctx, division, version.EffectiveStartDate, version.EffectiveEndDate)
+		}(i, division)
+	}
+	wg.Wait()
+
+	result := validation.NewResult()
+	var rows []SourcedRow
+	var hashes []string
+	pagesFetched := 0
+	for _, ds := range scrapes {
+		stats := entity.DivisionScrapeStats{
+			DivisionID: ds.division.ID,
+			Division:   ds.division.Name,
+			Pages:      len(ds.pages),
+			Errors:     ds.errors,
+		}
+		for _, fp := range ds.pages {
+			if s.archive != nil {
+				if _, err := s.archive.RecordDivisionPage(ctx, batch.ID, batch.HospitalID, ds.division.ID,
+					fp.page, fp.url, fp.html, fp.fetchedAt); err != nil {
+					return s.abort(ctx, batch, result, fmt.Errorf("failed to archive %s: %w", fp.url, err))
+				}
+			}
+			hashes = append(hashes, ContentHash(fp.html))
+			stats.Rows += len(fp.rows)
+			rows = append(rows, fp.rows...)
+		}
+		pagesFetched += len(ds.pages)
+		batch.DivisionStats = append(batch.DivisionStats, stats)
+
+		result.AddMessages(ds.messages.Messages...)
+		for _, e := range ds.errors {
+			result.AddWarningWithContext("AMION_DIVISION_ERROR", e, map[string]interface{}{"division": ds.division.Name})
+		}
+	}
+
+	if pagesFetched == 0 {
+		result.AddError("AMION_SCRAPE_FAILED", "No Amion division returned any pages")
+		if err := s.markFailed(ctx, batch, "no amion division returned any pages"); err != nil {
+			return nil, result, err
+		}
+		return batch, result, nil
+	}
+
+	counts, err := s.importer.Import(ctx, version, rows, batch.ID, creatorID, result)
+	if err != nil {
+		return s.abort(ctx, batch, result, err)
+	}
+
+	sort.Strings(hashes)
+	batch.IngestChecksum = ingestChecksum(hashes)
+	batch.MarkComplete(creatorID, counts.Shifts)
+	if err := s.batchRepo.Update(ctx, batch); err != nil {
+		return nil, result, fmt.Errorf("failed to complete scrape batch: %w", err)
+	}
+
+	result.AddInfo("AMION_DIVISIONS_SCRAPED", fmt.Sprintf(
+		"Scraped %d pages from %d divisions: %d shifts, %d assignments",
+		pagesFetched, len(divisions), counts.Shifts, counts.Assignments))
+
+	// The import is committed, so a failed comparison is only reported: an
+	// error would have the job retried and the scrape imported again
+	if s.changes != nil {
+		if _, err := s.changes.Record(ctx, batch, rows, creatorID, result); err != nil {
+			result.AddWarning("AMION_CHANGES_NOT_RECORDED", fmt.Sprintf("Failed to compare with the previous scrape: %v", err))
+		}
+	}
+	return batch, result, nil
+}
+
+// scrapeDivision fetches and extracts one division's pages. Failures are
+// collected rather than returned so the other divisions carry on; selector
+// drift on any page fails the whole division.
+func (s *Scraper) scrapeDivision(ctx context.Context, division *entity.AmionDivision, from, to time.Time) *divisionScrape {
+	ds := &divisionScrape{division: division, messages: validation.NewResult()}
+
+	profiles, err := selectorProfiles(s.profiles, division.SelectorProfile)
+	if err != nil {
+		ds.errors = append(ds.errors, err.Error())
+		return ds
+	}
+
+	// Only the secret's name is ever reported
+	var creds *secrets.Credentials
+	if division.CredentialsRef != "" {
+		if s.secrets == nil {
+			ds.errors = append(ds.errors, fmt.Sprintf("no secret provider configured for credentials %q", division.CredentialsRef))
+			return ds
+		}
+		creds, err = secrets.LoadCredentials(ctx, s.secrets, division.CredentialsRef)
+		if err != nil {
+			ds.errors = append(ds.errors, fmt.Sprintf("failed to load credentials %q", division.CredentialsRef))
+			return ds
+		}
+	}
+
+	baseURL := strings.TrimRight(division.BaseURL, "/")
+	client, err := amionweb.NewAmionHTTPClient(baseURL)
+	if err != nil {
+		ds.errors = append(ds.errors, err.Error())
+		return ds
+	}
+	defer client.Close()
+	st := s.site(baseURL)
+	client.SetCircuitBreaker(st.breaker)
+	if creds != nil {
+		client.SetLogin(amionweb.LoginConfig{Username: creds.Username, Password: creds.Password})
+	}
+
+	pool := amionweb.NewGoroutinePool(DivisionWorkers)
+	defer pool.Close()
+
+	// No response cache: every scrape imports into a new version, so
+	// unchanged pages are needed as much as changed ones
+	scraper := amionweb.NewAmionScraper(client, pool, st.limiter, amionweb.DefaultSelectors())
+	scraper.SetSelectorProfiles(profiles)
+	scraper.SetFingerprintStore(divisionFingerprints{store: s.fingerprints, division: division.ID})
+	if err := scraper.SetURLTemplate(URLTemplate(division.URLTemplate, division.PageView)); err != nil {
+		ds.errors = append(ds.errors, err.Error())
+		return ds
+	}
+
+	scraped, err := scraper.ScrapeWindowWithContext(ctx, from, to)
+	if err != nil {
+		var drift *amionweb.SelectorDriftError
+		if errors.As(err, &drift) {
+			ds.messages.AddWarningWithContext("AMION_SELECTOR_DRIFT", drift.Reason, map[string]interface{}{
+				"url": drift.URL, "profile": drift.Profile, "division": division.Name,
+			})
+		}
+		ds.errors = append(ds.errors, err.Error())
+		return ds
+	}
+
+	for _, e := range scraped.Errors {
+		ds.errors = append(ds.errors, e.Error.Error())
+	}
+	for _, w := range scraped.Warnings {
+		details := map[string]interface{}{"division": division.Name}
+		if len(w.Rows) == 2 {
+			details["url"] = baseURL + w.Rows[1].URL
+			details["row"] = w.Rows[1].RowIndex
+			details["duplicate_of"] = fmt.Sprintf("%s row %d", baseURL+w.Rows[0].URL, w.Rows[0].RowIndex)
+		}
+		ds.messages.AddWarningWithContext("AMION_DUPLICATE_ROW", w.Message, details)
+	}
+
+	rowsByPage := make(map[string][]SourcedRow)
+	for _, shift := range scraped.Shifts {
+		rowsByPage[shift.URL] = append(rowsByPage[shift.URL], SourcedRow{Row: rowFromShift(shift), URL: baseURL + shift.URL, Division: division})
+	}
+	for _, page := range scraped.Pages {
+		fp := fetchedPage{
+			page:      page.Page,
+			url:       baseURL + page.URL,
+			html:      page.Body,
+			fetchedAt: page.FetchedAt,
+			rows:      rowsByPage[page.URL],
+		}
+		sort.Slice(fp.rows, func(i, j int) bool { return fp.rows[i].Index < fp.rows[j].Index })
+		for _, rowErr := range rowErrors(page.RowErrors) {
+			ds.messages.AddWarningWithContext("AMION_ROW_INVALID", rowErr.Error(), map[string]interface{}{
+				"url": fp.url, "row": rowErr.Index, "field": rowErr.Field, "division": division.Name,
+			})
+		}
+		ds.pages = append(ds.pages, fp)
+	}
+	return ds
+}
+
+// abort marks the batch FAILED and returns cause
+func (s *Scraper) abort(ctx context.Context, batch *entity.ScrapeBatch, result *validation.Result, cause error) (*entity.ScrapeBatch, *validation.Result, error) {
+	if err := s.markFailed(ctx, batch, cause.Error()); err != nil {
+		return nil, result, fmt.Errorf("%w (%v)", cause, err)
+	}
+	return nil, result, cause
+}
+
+// markFailed records why the batch failed
+func (s *Scraper) markFailed(ctx context.Context, batch *entity.ScrapeBatch, reason string) error {
+	batch.MarkFailed(reason)
+	if err := s.batchRepo.Update(ctx, batch); err != nil {
+		return fmt.Errorf("failed to mark scrape batch failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package amion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDivisionRepo struct {
+	repository.AmionDivisionRepository
+	divisions []*entity.AmionDivision
+}
+
+func (m *mockDivisionRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.AmionDivision, error) {
+	var result []*entity.AmionDivision
+	for _, d := range m.divisions {
+		if d.HospitalID == hospitalID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+type mapSecrets map[string]secrets.Secret
+
+func (m mapSecrets) Get(ctx context.Context, name string) (secrets.Secret, error) {
+	if s, ok := m[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("%q: %w", name, secrets.ErrNotFound)
+}
+
+const loginPage = `<html><body><form method="post" action="/login">
+	<input name="username"><input type="password" name="password"></form></body></html>`
+
+// fakeAmionSite serves a schedule behind a cookie login. Each session is
+// good for sessionPages page views, after which the login form comes back.
+type fakeAmionSite struct {
+	username, password string
+	sessionPages       int
+	pages              func(path string) (string, bool)
+
+	mu       sync.Mutex
+	sessions map[string]int
+	logins   int
+}
+
+func (s *fakeAmionSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/login" && r.Method == http.MethodPost {
+		if r.FormValue("username") != s.username || r.FormValue("password") != s.password {
+			fmt.Fprint(w, loginPage)
+			return
+		}
+		s.logins++
+		token := uuid.NewString()
+		s.sessions[token] = 0
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: token, Path: "/"})
+		fmt.Fprint(w, "<html><body>Welcome</body></html>")
+		return
+	}
+
+	if s.username != "" {
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			fmt.Fprint(w, loginPage)
+			return
+		}
+		views, ok := s.sessions[cookie.Value]
+		if !ok || (s.sessionPages > 0 && views >= s.sessionPages) {
+			fmt.Fprint(w, loginPage)
+			return
+		}
+		s.sessions[cookie.Value] = views + 1
+	}
+
+	html, ok := s.pages(r.URL.RequestURI())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, html)
+}
+
+func newFakeAmionSite(username, password string, pages func(path string) (string, bool)) *fakeAmionSite {
+	return &fakeAmionSite{username: username, password: password, pages: pages, sessions: make(map[string]int)}
+}
+
+func TestPages(t *testing.T) {
+	nov1 := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	nov30 := time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	months, err := Pages("", "", time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC), nov30)
+	require.NoError(t, err)
+	assert.Equal(t, []Page{
+		{Label: "2025-10", URL: "/schedule/2025-10", From: "2025-10-20", To: "2025-10-31"},
+		{Label: "2025-11", URL: "/schedule/2025-11", From: "2025-11-01", To: "2025-11-30"},
+	}, months)
+
+	weeks, err := Pages("/cgi-bin/ocs?Syr={yyyy}&Mo={m}&Dy={d}&Days=7", ViewWeek, nov1, nov30)
+	require.NoError(t, err)
+	require.Len(t, weeks, 6)
+	assert.Equal(t, Page{Label: "2025-10-26", URL: "/cgi-bin/ocs?Syr=2025&Mo=10&Dy=26&Days=7", From: "2025-11-01", To: "2025-11-01"}, weeks[0])
+	assert.Equal(t, Page{Label: "2025-11-30", URL: "/cgi-bin/ocs?Syr=2025&Mo=11&Dy=30&Days=7", From: "2025-11-30", To: "2025-11-30"}, weeks[5])
+	assert.True(t, weeks[1].Contains("2025-11-08"))
+	assert.False(t, weeks[1].Contains("2025-11-09"))
+
+	days, err := Pages("/day/{date}", ViewDay, nov1, nov1.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Len(t, days, 3)
+
+	assert.Error(t, ValidateURLTemplate("/schedule/{yyyy}-{mm}", ViewDay), "a day view needs the day")
+	assert.Error(t, ValidateURLTemplate("/schedule/{year}", ViewMonth), "unknown placeholder")
+	assert.Error(t, ValidateURLTemplate("", "fortnight"))
+
+	_, err = Pages("/day/{date}", ViewDay, nov1, nov1.AddDate(2, 0, 0))
+	assert.Error(t, err, "too many pages")
+}
+
+func TestScraper_MergesDivisionsIntoOneBatch(t *testing.T) {
+	ctx := context.Background()
+	hospitalID := uuid.New()
+
+	body := newFakeAmionSite("body", "pw", func(path string) (string, bool) {
+		return novemberPage, path == "/schedule/2025-11"
+	})
+	bodyServer := httptest.NewServer(body)
+	defer bodyServer.Close()
+
+	// Neuro publishes week pages; each week's only shift is on its Monday
+	neuroServer := httptest.NewServer(newFakeAmionSite("", "", func(path string) (string, bool) {
+		sunday, err := time.Parse("2006-01-02", strings.TrimPrefix(path, "/week/"))
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf(`<table><tbody><tr><td>%s</td><td>NR Day</td><td>08:00</td><td>17:00</td><td>Neuro</td><td>1</td><td>Lee, Ann</td></tr></tbody></table>`,
+			sunday.AddDate(0, 0, 1).Format("2006-01-02")), true
+	}))
+	defer neuroServer.Close()
+
+	divisions := &mockDivisionRepo{divisions: []*entity.AmionDivision{
+		{ID: uuid.New(), HospitalID: hospitalID, Name: "Body", BaseURL: bodyServer.URL, CredentialsRef: "amion/body",
+			ShiftNameMapping: map[string]string{"on1": "Overnight"}, Active: true},
+		{ID: uuid.New(), HospitalID: hospitalID, Name: "Neuro", BaseURL: neuroServer.URL, URLTemplate: "/week/{date}", PageView: ViewWeek,
+			ShiftNameMapping: map[string]string{"NR Day": "NeuroDay"}, Active: true},
+		{ID: uuid.New(), HospitalID: hospitalID, Name: "IR", BaseURL: bodyServer.URL, CredentialsRef: "amion/missing", Active: true},
+		{ID: uuid.New(), HospitalID: hospitalID, Name: "Retired", BaseURL: bodyServer.URL, Active: false},
+	}}
+	batchRepo := &mockBatchRepo{batches: make(map[uuid.UUID]*entity.ScrapeBatch)}
+	snapshots := &mockSnapshotRepo{}
+	shiftRepo := &mockShiftRepo{}
+	assignmentRepo := &mockAssignmentRepo{shifts: shiftRepo}
+	personRepo := &mockPersonRepo{people: []*entity.Person{
+		{ID: uuid.New(), Name: "Jane Smith", Aliases: []string{"Smith, Jane"}, Active: true},
+		{ID: uuid.New(), Name: "Ann Lee", Aliases: []string{"Lee, Ann"}, Active: true},
+	}}
+
+	scraper := NewScraper(divisions, batchRepo, NewImporter(assignmentRepo, personRepo))
+	scraper.SetSecretProvider(mapSecrets{"amion/body": {"username": "body", "password": "pw"}})
+	scraper.SetArchive(NewArchive(snapshots))
+	scraper.SetRequestInterval(time.Millisecond)
+
+	version := &entity.ScheduleVersion{
+		ID:                 uuid.New(),
+		HospitalID:         hospitalID,
+		EffectiveStartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		EffectiveEndDate:   time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+	batch, result, err := scraper.Scrape(ctx, version, uuid.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, entity.BatchStateComplete, batch.State)
+	assert.Equal(t, batchRepo.batches[batch.ID], batch, "one batch for all divisions")
+	assert.NotEmpty(t, batch.IngestChecksum)
+
+	require.Len(t, batch.DivisionStats, 3)
+	assert.Equal(t, entity.DivisionScrapeStats{DivisionID: divisions.divisions[0].ID, Division: "Body", Pages: 1, Rows: 2}, batch.DivisionStats[0])
+	assert.Equal(t, entity.DivisionScrapeStats{DivisionID: divisions.divisions[1].ID, Division: "Neuro", Pages: 6, Rows: 4}, batch.DivisionStats[1],
+		"week pages are trimmed to the window")
+	assert.Equal(t, "IR", batch.DivisionStats[2].Division)
+	assert.Len(t, batch.DivisionStats[2].Errors, 1)
+	assert.NotContains(t, batch.DivisionStats[2].Errors[0], "pw", "credentials never leak into errors")
+
+	// Body: 2 November rows have valid dates; Neuro: 4 Mondays in November
+	assert.Equal(t, 6, batch.RowCount)
+	shiftTypes := map[entity.ShiftType]int{}
+	for _, s := range shiftRepo.shifts {
+		shiftTypes[s.ShiftType]++
+	}
+	assert.Equal(t, map[entity.ShiftType]int{"Overnight": 1, "DAY": 1, "NeuroDay": 4}, shiftTypes)
+	assert.Equal(t, "ON1", assignmentRepo.assignments[0].OriginalShiftType, "the Amion label is kept on the assignment")
+
+	assert.Len(t, snapshots.snapshots, 7)
+	for _, s := range snapshots.snapshots {
+		require.NotNil(t, s.DivisionID)
+		assert.Equal(t, batch.ID, s.ScrapeBatchID)
+	}
+
+	assert.Len(t, result.MessagesByCode("AMION_DIVISION_ERROR"), 1)
+	assert.Len(t, result.MessagesByCode("AMION_ROW_INVALID"), 2)
+	assert.Equal(t, "Body", result.MessagesByCode("AMION_ROW_INVALID")[0].Context["division"])
+}
+
+func TestScraper_NoDivisions(t *testing.T) {
+	scraper := NewScraper(&mockDivisionRepo{}, &mockBatchRepo{batches: make(map[uuid.UUID]*entity.ScrapeBatch)},
+		NewImporter(&mockAssignmentRepo{shifts: &mockShiftRepo{}}, &mockPersonRepo{}))
+
+	_, _, err := scraper.Scrape(context.Background(), &entity.ScheduleVersion{HospitalID: uuid.New()}, uuid.New())
+	assert.ErrorIs(t, err, ErrNoDivisions)
+}
+
+func TestScraper_AllDivisionsFailing(t *testing.T) {
+	hospitalID := uuid.New()
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	batchRepo := &mockBatchRepo{batches: make(map[uuid.UUID]*entity.ScrapeBatch)}
+	scraper := NewScraper(
+		&mockDivisionRepo{divisions: []*entity.AmionDivision{{ID: uuid.New(), HospitalID: hospitalID, Name: "Body", BaseURL: server.URL, Active: true}}},
+		batchRepo,
+		NewImporter(&mockAssignmentRepo{shifts: &mockShiftRepo{}}, &mockPersonRepo{}),
+	)
+
+	version := &entity.ScheduleVersion{
+		HospitalID:         hospitalID,
+		EffectiveStartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		EffectiveEndDate:   time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+	batch, result, err := scraper.Scrape(context.Background(), version, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entity.BatchStateFailed, batch.State)
+	assert.Contains(t, batch.DivisionStats[0].Errors[0], "HTTP 404")
+	assert.False(t, result.CanImport())
+}
+
+func TestScraper_DriftFailsTheDivision(t *testing.T) {
+	hospitalID := uuid.New()
+	page := novemberPage
+	server := httptest.NewServer(newFakeAmionSite("", "", func(path string) (string, bool) {
+		return page, path == "/schedule/2025-11"
+	}))
+	defer server.Close()
+
+	division := &entity.AmionDivision{ID: uuid.New(), HospitalID: hospitalID, Name: "Body", BaseURL: server.URL, Active: true}
+	shiftRepo := &mockShiftRepo{}
+	scraper := NewScraper(&mockDivisionRepo{divisions: []*entity.AmionDivision{division}},
+		&mockBatchRepo{batches: make(map[uuid.UUID]*entity.ScrapeBatch)},
+		NewImporter(&mockAssignmentRepo{shifts: shiftRepo}, &mockPersonRepo{}))
+	scraper.SetRequestInterval(time.Millisecond)
+
+	version := &entity.ScheduleVersion{
+		HospitalID:         hospitalID,
+		EffectiveStartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		EffectiveEndDate:   time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+	batch, _, err := scraper.Scrape(context.Background(), version, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entity.BatchStateComplete, batch.State)
+	imported := len(shiftRepo.shifts)
+
+	// Amion renames a column: the page still parses but no longer means the same
+	page = strings.Replace(novemberPage, "<th>Location</th>", "<th>Site</th>", 1)
+	batch, result, err := scraper.Scrape(context.Background(), version, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entity.BatchStateFailed, batch.State)
+	assert.Len(t, result.MessagesByCode("AMION_SELECTOR_DRIFT"), 1)
+	assert.Len(t, shiftRepo.shifts, imported, "nothing is imported from a drifted page")
+}
+
+func TestScraper_ReportsDuplicateRows(t *testing.T) {
+	hospitalID := uuid.New()
+	duplicate := `<tr><td>2025-11-15</td><td>ON1</td><td>19:00</td><td>07:00</td><td>Main</td><td>2</td><td>Smith, Jane<br>Open</td></tr>`
+	server := httptest.NewServer(newFakeAmionSite("", "", func(path string) (string, bool) {
+		return strings.Replace(novemberPage, "</tbody>", duplicate+"</tbody>", 1), path == "/schedule/2025-11"
+	}))
+	defer server.Close()
+
+	shiftRepo := &mockShiftRepo{}
+	scraper := NewScraper(
+		&mockDivisionRepo{divisions: []*entity.AmionDivision{{ID: uuid.New(), HospitalID: hospitalID, Name: "Body", BaseURL: server.URL, Active: true}}},
+		&mockBatchRepo{batches: make(map[uuid.UUID]*entity.ScrapeBatch)},
+		NewImporter(&mockAssignmentRepo{shifts: shiftRepo}, &mockPersonRepo{}),
+	)
+	scraper.SetRequestInterval(time.Millisecond)
+
+	version := &entity.ScheduleVersion{
+		HospitalID:         hospitalID,
+		EffectiveStartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		EffectiveEndDate:   time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+	batch, result, err := scraper.Scrape(context.Background(), version, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entity.BatchStateComplete, batch.State)
+	assert.Len(t, shiftRepo.shifts, 2, "the repeated row is imported once")
+
+	warnings := result.MessagesByCode("AMION_DUPLICATE_ROW")
+	require.Len(t, warnings, 1)
+	assert.Equal(t, server.URL+"/schedule/2025-11", warnings[0].Context["url"])
+	assert.Equal(t, server.URL+"/schedule/2025-11 row 2", warnings[0].Context["duplicate_of"])
+}
+
+func TestScraper_ComparisonFailureKeepsTheImport(t *testing.T) {
+	hospitalID := uuid.New()
+	server := httptest.NewServer(newFakeAmionSite("", "", func(path string) (string, bool) {
+		return novemberPage, path == "/schedule/2025-11"
+	}))
+	defer server.Close()
+
+	batchRepo := &mockBatchRepo{batches: make(map[uuid.UUID]*entity.ScrapeBatch)}
+	shiftRepo := &mockShiftRepo{}
+	importer := NewImporter(&mockAssignmentRepo{shifts: shiftRepo}, &mockPersonRepo{})
+	scraper := NewScraper(
+		&mockDivisionRepo{divisions: []*entity.AmionDivision{{ID: uuid.New(), HospitalID: hospitalID, Name: "Body", BaseURL: server.URL, Active: true}}},
+		batchRepo, importer)
+	scraper.SetRequestInterval(time.Millisecond)
+	scraper.SetChangeStager(NewChangeStager(batchRepo, &mockRosterRepo{err: errors.New("connection reset")},
+		&mockVersionRepo{}, shiftRepo, importer))
+
+	version := &entity.ScheduleVersion{
+		HospitalID:         hospitalID,
+		EffectiveStartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		EffectiveEndDate:   time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+	batch, result, err := scraper.Scrape(context.Background(), version, uuid.New())
+	require.NoError(t, err, "a retry would import the scrape again")
+	assert.Equal(t, entity.BatchStateComplete, batch.State)
+	assert.NotEmpty(t, shiftRepo.shifts)
+	assert.Len(t, result.MessagesByCode("AMION_CHANGES_NOT_RECORDED"), 1)
+}
@@ -39,6 +39,12 @@ type AmionScraperConfig struct {
 	ConcurrentWorkers int    // Number of concurrent goroutines for scraping
 }
 
+// String omits the password so the config can be logged safely
+func (c AmionScraperConfig) String() string {
+	return fmt.Sprintf("AmionScraperConfig{Username: %s, Password: REDACTED, MonthsToScrape: %d, StartDate: %s, ConcurrentWorkers: %d}",
+		c.Username, c.MonthsToScrape, c.StartDate.Format("2006-01-02"), c.ConcurrentWorkers)
+}
+
 // ScrapeAndImport scrapes Amion and imports the data as a batch
 // Returns a ScrapeBatch and validation result (with all issues collected)
 func (s *amionImportService) ScrapeAndImport(
@@ -0,0 +1,294 @@
+// Package clone copies a schedule version forward into a new period. Each
+// date of the new period takes its shifts from a date of the source version
+// staffed the same way: the same weekday, or a holiday for a holiday. An
+// optional rotation moves each person's assignments to the next person of
+// the same specialty, so the draft does not hand everyone the same days again.
+package clone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// ErrSourceDeleted is returned when cloning a deleted version
+var ErrSourceDeleted = errors.New("deleted schedule versions cannot be cloned")
+
+// Options describe the new period
+type Options struct {
+	StartDate          time.Time
+	EndDate            time.Time
+	Holidays           entity.HolidayCalendar // Extra holidays on top of entity.NewHolidayCalendar's
+	IncludeAssignments bool
+	RotationOffset     int // People each assignment moves along its specialty's rotation; 0 copies as is
+	CreatorID          uuid.UUID
+}
+
+// DayProjection says which source date a new date was copied from
+type DayProjection struct {
+	Date       time.Time
+	DayType    entity.DayType
+	Holiday    string
+	SourceDate *time.Time // nil when no source date matched
+}
+
+// Result is the new version and how it was built
+type Result struct {
+	Version     *entity.ScheduleVersion
+	Shifts      int
+	Assignments int
+	Days        []DayProjection
+	Validation  *validation.Result // Days and assignments that could not be copied
+}
+
+// Cloner copies schedule versions into new periods
+type Cloner struct {
+	versions    repository.ScheduleVersionRepository
+	shifts      repository.ShiftInstanceRepository
+	assignments repository.AssignmentRepository
+	people      repository.PersonRepository
+	coverage    service.CoverageQueue // Optional: calculates coverage of new versions
+}
+
+// NewCloner creates a cloner
+func NewCloner(
+	versions repository.ScheduleVersionRepository,
+	shifts repository.ShiftInstanceRepository,
+	assignments repository.AssignmentRepository,
+	people repository.PersonRepository,
+) *Cloner {
+	return &Cloner{
+		versions:    versions,
+		shifts:      shifts,
+		assignments: assignments,
+		people:      people,
+	}
+}
+
+// SetCoverageQueue enables coverage calculation of cloned versions
+func (c *Cloner) SetCoverageQueue(queue service.CoverageQueue) {
+	c.coverage = queue
+}
+
+// Clone creates a STAGING version over the options' period from the source
+// version's shifts and, if asked, its assignments
+func (c *Cloner) Clone(ctx context.Context, sourceID uuid.UUID, opts Options) (*Result, error) {
+	start, end := entity.DateOf(opts.StartDate), entity.DateOf(opts.EndDate)
+	if err := entity.ValidatePeriod(start, end); err != nil {
+		return nil, err
+	}
+	source, err := c.versions.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source.DeletedAt != nil {
+		return nil, ErrSourceDeleted
+	}
+
+	shifts, err := c.shifts.GetByScheduleVersion(ctx, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shift instances: %w", err)
+	}
+	byDate := make(map[string][]*entity.ShiftInstance)
+	for _, s := range shifts {
+		key := s.ScheduleDate.Format("2006-01-02")
+		byDate[key] = append(byDate[key], s)
+	}
+
+	calendar := entity.NewHolidayCalendar(entity.EarlierOf(start, source.EffectiveStartDate), entity.LaterOf(end, source.EffectiveEndDate))
+	for date, name := range opts.Holidays {
+		calendar[date] = name
+	}
+	pattern := newPattern(entity.DateOf(source.EffectiveStartDate), entity.DateOf(source.EffectiveEndDate), calendar)
+
+	now := entity.Now()
+	version := &entity.ScheduleVersion{
+		ID:                 uuid.New(),
+		HospitalID:         source.HospitalID,
+		Status:             entity.VersionStatusStaging,
+		EffectiveStartDate: start,
+		EffectiveEndDate:   end,
+		CreatedAt:          now,
+		CreatedBy:          opts.CreatorID,
+		UpdatedAt:          now,
+		UpdatedBy:          opts.CreatorID,
+	}
+	result := &Result{Version: version, Validation: validation.NewResult()}
+
+	var newShifts []*entity.ShiftInstance
+	copies := make(map[uuid.UUID][]*entity.ShiftInstance) // source shift ID -> its copies
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		day := DayProjection{Date: d, DayType: calendar.DayType(d)}
+		day.Holiday, _ = calendar.Holiday(d)
+		if from, ok := pattern.next(d); ok {
+			day.SourceDate = &from
+			for _, s := range byDate[from.Format("2006-01-02")] {
+				shift := *s
+				shift.ID = uuid.New()
+				shift.ScheduleVersionID = version.ID
+				shift.ScheduleDate = d
+				shift.ScrapeBatchID = nil
+				shift.CreatedAt = now
+				shift.CreatedBy = opts.CreatorID
+				newShifts = append(newShifts, &shift)
+				copies[s.ID] = append(copies[s.ID], &shift)
+			}
+		} else {
+			result.Validation.AddWarningWithContext("DAY_NOT_PROJECTED",
+				fmt.Sprintf("The source version has no %s to copy to %s", describe(d, day.DayType), d.Format("2006-01-02")),
+				map[string]interface{}{"date": d.Format("2006-01-02")})
+		}
+		result.Days = append(result.Days, day)
+	}
+
+	var newAssignments []*entity.Assignment
+	if opts.IncludeAssignments && len(newShifts) > 0 {
+		newAssignments, err = c.projectAssignments(ctx, source, copies, opts, result.Validation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.versions.Create(ctx, version); err != nil {
+		return nil, fmt.Errorf("failed to create schedule version: %w", err)
+	}
+	if err := c.write(ctx, newShifts, newAssignments, opts.CreatorID); err != nil {
+		if cleanupErr := c.versions.Delete(ctx, version.ID, opts.CreatorID); cleanupErr != nil {
+			return nil, fmt.Errorf("%w (and failed to delete the partial version %s: %v)", err, version.ID, cleanupErr)
+		}
+		return nil, err
+	}
+
+	result.Shifts = len(newShifts)
+	result.Assignments = len(newAssignments)
+
+	if len(newShifts) > 0 {
+		service.QueueCoverageRecalculation(ctx, c.coverage, version.ID, opts.CreatorID, start, end)
+	}
+	return result, nil
+}
+
+// write saves the copied shifts, then the copied assignments
+func (c *Cloner) write(ctx context.Context, shifts []*entity.ShiftInstance, assignments []*entity.Assignment, creatorID uuid.UUID) error {
+	if err := c.shifts.CreateBatch(ctx, shifts); err != nil {
+		return fmt.Errorf("failed to create shift instances: %w", err)
+	}
+	if len(assignments) > 0 {
+		if err := c.assignments.ApplyChanges(ctx, &entity.AssignmentChanges{Create: assignments}, creatorID); err != nil {
+			return fmt.Errorf("failed to create assignments: %w", err)
+		}
+	}
+	return nil
+}
+
+// projectAssignments copies each source assignment onto every copy of its
+// shift, rotated to another person when asked. Copies are MANUAL: they are a
+// scheduler's draft, not something Amion published.
+func (c *Cloner) projectAssignments(
+	ctx context.Context,
+	source *entity.ScheduleVersion,
+	copies map[uuid.UUID][]*entity.ShiftInstance,
+	opts Options,
+	vr *validation.Result,
+) ([]*entity.Assignment, error) {
+	assignments, err := c.assignments.GetByScheduleVersion(ctx, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assignments: %w", err)
+	}
+	people, err := c.people.GetByHospital(ctx, source.HospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load staff: %w", err)
+	}
+	staff := make(map[uuid.UUID]*entity.Person, len(people))
+	for _, p := range people {
+		if p.Active && p.DeletedAt == nil {
+			staff[p.ID] = p
+		}
+	}
+
+	live := assignments[:0:0]
+	for _, a := range assignments {
+		if !a.IsDeleted() {
+			live = append(live, a)
+		}
+	}
+	rotate := newRotation(live, staff, opts.RotationOffset)
+
+	skipped := make(map[uuid.UUID]bool)
+	var projected []*entity.Assignment
+	for _, a := range live {
+		personID, ok := rotate[a.PersonID]
+		if !ok {
+			if !skipped[a.PersonID] {
+				skipped[a.PersonID] = true
+				vr.AddWarningWithContext("PERSON_INACTIVE",
+					"Assignments of a person who is no longer active staff were not copied",
+					map[string]interface{}{"person_id": a.PersonID.String()})
+			}
+			continue
+		}
+		for _, shift := range copies[a.ShiftInstanceID] {
+			projected = append(projected, &entity.Assignment{
+				ID:                uuid.New(),
+				PersonID:          personID,
+				ShiftInstanceID:   shift.ID,
+				ScheduleDate:      shift.ScheduleDate,
+				OriginalShiftType: a.OriginalShiftType,
+				Source:            entity.AssignmentSourceManual,
+				CreatedAt:         entity.Now(),
+				CreatedBy:         opts.CreatorID,
+			})
+		}
+	}
+	return projected, nil
+}
+
+// newRotation maps each active person with source assignments to the person
+// offset places after them in their specialty's rotation. A rotation lists
+// people by their first assignment in the source version, so the order
+// follows the schedule rather than the alphabet.
+func newRotation(assignments []*entity.Assignment, staff map[uuid.UUID]*entity.Person, offset int) map[uuid.UUID]uuid.UUID {
+	ordered := make([]*entity.Assignment, len(assignments))
+	copy(ordered, assignments)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if !ordered[i].ScheduleDate.Equal(ordered[j].ScheduleDate) {
+			return ordered[i].ScheduleDate.Before(ordered[j].ScheduleDate)
+		}
+		return ordered[i].OriginalShiftType < ordered[j].OriginalShiftType
+	})
+
+	rings := make(map[entity.SpecialtyType][]uuid.UUID)
+	seen := make(map[uuid.UUID]bool)
+	for _, a := range ordered {
+		person, ok := staff[a.PersonID]
+		if !ok || seen[a.PersonID] {
+			continue
+		}
+		seen[a.PersonID] = true
+		rings[person.Specialty] = append(rings[person.Specialty], a.PersonID)
+	}
+
+	rotation := make(map[uuid.UUID]uuid.UUID, len(seen))
+	for _, ring := range rings {
+		n := len(ring)
+		for i, personID := range ring {
+			rotation[personID] = ring[((i+offset)%n+n)%n]
+		}
+	}
+	return rotation
+}
+
+func describe(d time.Time, dayType entity.DayType) string {
+	if dayType == entity.DayTypeHoliday {
+		return "holiday or Sunday"
+	}
+	return d.Weekday().String()
+}
@@ -0,0 +1,142 @@
+package clone
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/tests/helpers"
+	"github.com/schedcu/v2/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixture struct {
+	cloner      *Cloner
+	source      *entity.ScheduleVersion
+	shifts      *mocks.MockShiftInstanceRepository
+	assignments *mocks.MockAssignmentRepository
+}
+
+// newFixture is November 2025 (Thanksgiving on the 27th) with one shift a
+// day: alice on weekdays, bob on weekends and carol on Thanksgiving. dan,
+// no longer active, also works the 3rd.
+func newFixture(alice, bob, carol, dan *entity.Person) *fixture {
+	ctx := context.Background()
+	schedule := mocks.NewMockSchedule()
+	f := &fixture{shifts: schedule.Shifts, assignments: schedule.Assignments}
+	f.source = helpers.NewScheduleVersionBuilder().WithStatus(entity.VersionStatusProduction).
+		WithEffectiveStartDate(helpers.Date("2025-11-01")).WithEffectiveEndDate(helpers.Date("2025-11-30")).Build()
+	schedule.Versions.Create(ctx, f.source)
+
+	calendar := entity.NewHolidayCalendar(f.source.EffectiveStartDate, f.source.EffectiveEndDate)
+	for d := f.source.EffectiveStartDate; !d.After(f.source.EffectiveEndDate); d = d.AddDate(0, 0, 1) {
+		shiftType, person := entity.ShiftType("Day"), alice
+		switch calendar.DayType(d) {
+		case entity.DayTypeWeekend:
+			shiftType, person = "Weekend", bob
+		case entity.DayTypeHoliday:
+			shiftType, person = "Holiday", carol
+		}
+		shift := helpers.NewShiftInstanceBuilder().WithScheduleVersionID(f.source.ID).WithHospitalID(f.source.HospitalID).
+			WithShiftType(shiftType).WithScheduleDate(d).Build()
+		f.shifts.Create(ctx, shift)
+		assign := func(person *entity.Person) {
+			f.assignments.Create(ctx, helpers.NewAssignmentBuilder().WithPersonID(person.ID).WithShiftInstanceID(shift.ID).
+				WithScheduleDate(d).WithOriginalShiftType(string(shiftType)).Build())
+		}
+		assign(person)
+		if d.Equal(helpers.Date("2025-11-03")) {
+			assign(dan)
+		}
+	}
+
+	for _, person := range []*entity.Person{alice, bob, carol, dan} {
+		schedule.People.Create(ctx, person)
+	}
+
+	f.cloner = NewCloner(schedule.Versions, schedule.Shifts, schedule.Assignments, schedule.People)
+	return f
+}
+
+func TestClone(t *testing.T) {
+	alice := helpers.NewPersonBuilder().WithSpecialty(entity.SpecialtyBoth).Build()
+	bob := helpers.NewPersonBuilder().WithSpecialty(entity.SpecialtyBoth).Build()
+	carol := helpers.NewPersonBuilder().WithSpecialty(entity.SpecialtyNeuroOnly).Build()
+	dan := helpers.NewPersonBuilder().WithSpecialty(entity.SpecialtyBoth).WithActive(false).Build()
+	f := newFixture(alice, bob, carol, dan)
+
+	creatorID := uuid.New()
+	result, err := f.cloner.Clone(context.Background(), f.source.ID, Options{
+		StartDate: helpers.Date("2025-12-01"), EndDate: helpers.Date("2025-12-31"), IncludeAssignments: true, RotationOffset: 1,
+		CreatorID: creatorID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.VersionStatusStaging, result.Version.Status)
+	assert.False(t, result.Version.UpdatedAt.IsZero())
+	assert.Equal(t, creatorID, result.Version.UpdatedBy)
+	assert.Equal(t, 31, result.Shifts)
+	require.Len(t, result.Days, 31)
+
+	sourceOf := func(d string) string {
+		for _, day := range result.Days {
+			if day.Date.Equal(helpers.Date(d)) {
+				require.NotNil(t, day.SourceDate, d)
+				return day.SourceDate.Format("2006-01-02")
+			}
+		}
+		t.Fatalf("no projection for %s", d)
+		return ""
+	}
+	assert.Equal(t, "2025-11-03", sourceOf("2025-12-01"), "first Monday")
+	assert.Equal(t, "2025-11-03", sourceOf("2025-12-29"), "a fifth Monday wraps to the first")
+	assert.Equal(t, "2025-11-20", sourceOf("2025-12-18"), "Thanksgiving is not an ordinary Thursday")
+	assert.Equal(t, "2025-11-27", sourceOf("2025-12-25"), "Christmas is staffed like Thanksgiving")
+	assert.Equal(t, "2025-11-01", sourceOf("2025-12-06"))
+
+	// The BOTH rotation is bob, alice (by first assignment); offset 1 swaps them
+	byDate := make(map[string][]uuid.UUID)
+	shiftDates := make(map[uuid.UUID]string)
+	for _, s := range f.shifts.All() {
+		if s.ScheduleVersionID == result.Version.ID {
+			shiftDates[s.ID] = s.ScheduleDate.Format("2006-01-02")
+			assert.Nil(t, s.ScrapeBatchID)
+		}
+	}
+	for _, a := range f.assignments.All() {
+		if d, ok := shiftDates[a.ShiftInstanceID]; ok {
+			byDate[d] = append(byDate[d], a.PersonID)
+			assert.Equal(t, entity.AssignmentSourceManual, a.Source)
+		}
+	}
+	assert.Equal(t, []uuid.UUID{bob.ID}, byDate["2025-12-01"], "dan is inactive and not copied")
+	assert.Equal(t, []uuid.UUID{alice.ID}, byDate["2025-12-06"])
+	assert.Equal(t, []uuid.UUID{carol.ID}, byDate["2025-12-25"], "carol is alone in her rotation")
+	assert.Equal(t, 31, result.Assignments)
+	assert.Len(t, result.Validation.MessagesByCode("PERSON_INACTIVE"), 1)
+}
+
+func TestClone_ShortSource(t *testing.T) {
+	alice := helpers.NewPersonBuilder().WithSpecialty(entity.SpecialtyBoth).Build()
+	f := newFixture(alice, alice, alice, alice)
+	f.source.EffectiveStartDate, f.source.EffectiveEndDate = helpers.Date("2025-11-03"), helpers.Date("2025-11-05") // Mon-Wed
+
+	result, err := f.cloner.Clone(context.Background(), f.source.ID, Options{
+		StartDate: helpers.Date("2025-12-04"), EndDate: helpers.Date("2025-12-07"), // Thu-Sun
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Shifts, "Thursday and Friday fall back to other weekdays")
+	assert.Len(t, result.Validation.MessagesByCode("DAY_NOT_PROJECTED"), 2)
+	assert.Zero(t, result.Assignments)
+}
+
+func TestClone_InvalidRange(t *testing.T) {
+	alice := helpers.NewPersonBuilder().WithSpecialty(entity.SpecialtyBoth).Build()
+	f := newFixture(alice, alice, alice, alice)
+
+	_, err := f.cloner.Clone(context.Background(), f.source.ID, Options{StartDate: helpers.Date("2025-12-31"), EndDate: helpers.Date("2025-12-01")})
+	assert.ErrorIs(t, err, entity.ErrInvalidPeriod)
+	_, err = f.cloner.Clone(context.Background(), f.source.ID, Options{StartDate: helpers.Date("2025-01-01"), EndDate: helpers.Date("2026-01-31")})
+	assert.ErrorIs(t, err, entity.ErrInvalidPeriod)
+}
@@ -0,0 +1,78 @@
+package clone
+
+import (
+	"time"
+
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// pattern picks the source date each new date copies. The nth Monday of the
+// new period copies the nth Monday of the source, wrapping around when the
+// new period has more Mondays than the source, so a 31-day month can be
+// drafted from a 28-day one. Holidays copy the source's holiday of the same
+// name, else its holidays in turn, else its Sundays. Source holidays are
+// never copied onto ordinary days.
+type pattern struct {
+	calendar      entity.HolidayCalendar
+	holidays      []time.Time
+	holidayByName map[string]time.Time
+	byWeekday     map[time.Weekday][]time.Time
+	byDayType     map[entity.DayType][]time.Time
+	used          map[string]int
+}
+
+func newPattern(from, to time.Time, calendar entity.HolidayCalendar) *pattern {
+	p := &pattern{
+		calendar:      calendar,
+		holidayByName: make(map[string]time.Time),
+		byWeekday:     make(map[time.Weekday][]time.Time),
+		byDayType:     make(map[entity.DayType][]time.Time),
+		used:          make(map[string]int),
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dayType := calendar.DayType(d)
+		if dayType == entity.DayTypeHoliday {
+			name, _ := calendar.Holiday(d)
+			p.holidays = append(p.holidays, d)
+			p.holidayByName[name] = d
+			continue
+		}
+		p.byWeekday[d.Weekday()] = append(p.byWeekday[d.Weekday()], d)
+		p.byDayType[dayType] = append(p.byDayType[dayType], d)
+	}
+	return p
+}
+
+// next returns the source date for d; dates must be asked for in order
+func (p *pattern) next(d time.Time) (time.Time, bool) {
+	dayType := p.calendar.DayType(d)
+	if dayType == entity.DayTypeHoliday {
+		name, _ := p.calendar.Holiday(d)
+		if from, ok := p.holidayByName[name]; ok {
+			return from, true
+		}
+		if from, ok := p.pick("holiday", p.holidays); ok {
+			return from, true
+		}
+		if from, ok := p.pick("holiday-sunday", p.byWeekday[time.Sunday]); ok {
+			return from, true
+		}
+		return p.pick("holiday-weekend", p.byDayType[entity.DayTypeWeekend])
+	}
+
+	if from, ok := p.pick(d.Weekday().String(), p.byWeekday[d.Weekday()]); ok {
+		return from, true
+	}
+	// Source periods shorter than a week lack some weekdays
+	return p.pick(string(dayType), p.byDayType[dayType])
+}
+
+// pick returns the dates in turn, starting over after the last
+func (p *pattern) pick(key string, dates []time.Time) (time.Time, bool) {
+	if len(dates) == 0 {
+		return time.Time{}, false
+	}
+	i := p.used[key]
+	p.used[key]++
+	return dates[i%len(dates)], true
+}
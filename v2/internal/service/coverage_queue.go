@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// CoverageQueue schedules coverage recalculation (implemented by job.JobScheduler)
+type CoverageQueue interface {
+	EnqueueCoverageCalculation(ctx context.Context, versionID entity.ScheduleVersionID, startDate, endDate entity.Date, creatorID entity.UserID) (*asynq.TaskInfo, error)
+}
+
+// QueueCoverageRecalculation queues coverage recalculation of a version over
+// the span of dates. The change that prompted it has already been saved, so a
+// queue failure is logged rather than returned. Nothing is queued without a
+// queue or dates.
+func QueueCoverageRecalculation(ctx context.Context, queue CoverageQueue, versionID, creatorID uuid.UUID, dates ...time.Time) {
+	if queue == nil || len(dates) == 0 {
+		return
+	}
+	start, end := dates[0], dates[0]
+	for _, d := range dates[1:] {
+		start = entity.EarlierOf(start, d)
+		end = entity.LaterOf(end, d)
+	}
+	if _, err := queue.EnqueueCoverageCalculation(ctx, versionID, start, end, creatorID); err != nil {
+		log.Printf("failed to queue coverage recalculation for version %s: %v", versionID, err)
+	}
+}
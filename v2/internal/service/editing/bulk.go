@@ -0,0 +1,264 @@
+package editing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// MaxBulkOperations caps how many operations one bulk request may carry
+const MaxBulkOperations = 500
+
+// BulkOperationType is what a bulk operation does
+type BulkOperationType string
+
+const (
+	// BulkAdd puts PersonID on ShiftID
+	BulkAdd BulkOperationType = "add"
+	// BulkRemove takes AssignmentID off its shift
+	BulkRemove BulkOperationType = "remove"
+	// BulkMove moves AssignmentID to ShiftID and/or hands it to PersonID;
+	// either may be left out to keep the assignment's own
+	BulkMove BulkOperationType = "move"
+)
+
+// BulkOperation is one change in a bulk edit
+type BulkOperation struct {
+	Type         BulkOperationType
+	AssignmentID uuid.UUID
+	ShiftID      uuid.UUID
+	PersonID     uuid.UUID
+}
+
+// BulkOperationStatus is what happened to one operation
+type BulkOperationStatus string
+
+const (
+	// BulkApplied operations were written
+	BulkApplied BulkOperationStatus = "applied"
+	// BulkRejected operations failed validation
+	BulkRejected BulkOperationStatus = "rejected"
+	// BulkNotApplied operations were valid but the set was rejected
+	BulkNotApplied BulkOperationStatus = "not_applied"
+)
+
+// BulkOperationResult reports one operation's outcome
+type BulkOperationResult struct {
+	Index      int
+	Type       BulkOperationType
+	Status     BulkOperationStatus
+	Assignment *entity.Assignment
+	Messages   []validation.Message
+}
+
+// ShiftCoverageDelta is how a shift's staffing changed
+type ShiftCoverageDelta struct {
+	ShiftID         uuid.UUID
+	ShiftType       entity.ShiftType
+	ScheduleDate    time.Time
+	DesiredCoverage int
+	Before          int
+	After           int
+}
+
+// BulkResult reports a bulk edit. Validation holds errors that rejected the
+// request as a whole, and staffing warnings for the shifts it changed.
+type BulkResult struct {
+	Applied       bool
+	Results       []BulkOperationResult
+	CoverageDelta []ShiftCoverageDelta
+	Validation    *validation.Result
+}
+
+// ApplyBulk validates operations on a version as a set and writes them in one
+// transaction. Operations are checked in order, each against the version as
+// the earlier ones left it, so a week can be cleared and refilled in one
+// request. Unless partial is set, one rejected operation rejects the set and
+// nothing is written; with partial, rejected operations are skipped. The
+// version is locked while the set is validated and written, so concurrent
+// edits cannot invalidate it in between.
+func (e *Editor) ApplyBulk(ctx context.Context, versionID uuid.UUID, ops []BulkOperation, partial bool, opts Options) (*BulkResult, error) {
+	var snap *snapshot
+	var before map[uuid.UUID]int
+	var dates []time.Time
+	result := &BulkResult{Validation: validation.NewResult()}
+
+	err := e.assignments.EditVersion(ctx, versionID, opts.EditorID,
+		func(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.AssignmentChanges, error) {
+			var err error
+			snap, err = e.snapshot(ctx, version, shifts, assignments, opts)
+			if err != nil {
+				return nil, err
+			}
+			before = snap.staffing()
+			var changes *entity.AssignmentChanges
+			changes, dates = snap.planBulk(result, ops, partial, opts.EditorID)
+			return changes, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Applied {
+		return result, nil
+	}
+
+	after := snap.staffing()
+	for id, shift := range snap.shifts {
+		if before[id] == after[id] {
+			continue
+		}
+		result.CoverageDelta = append(result.CoverageDelta, ShiftCoverageDelta{
+			ShiftID:         id,
+			ShiftType:       shift.ShiftType,
+			ScheduleDate:    shift.ScheduleDate,
+			DesiredCoverage: shift.DesiredCoverage,
+			Before:          before[id],
+			After:           after[id],
+		})
+		snap.checkStaffing(result.Validation, shift, 0)
+	}
+	sort.Slice(result.CoverageDelta, func(i, j int) bool {
+		a, b := result.CoverageDelta[i], result.CoverageDelta[j]
+		if !a.ScheduleDate.Equal(b.ScheduleDate) {
+			return a.ScheduleDate.Before(b.ScheduleDate)
+		}
+		return a.ShiftType < b.ShiftType
+	})
+
+	service.QueueCoverageRecalculation(ctx, e.coverage, snap.version.ID, opts.EditorID, dates...)
+	return result, nil
+}
+
+// planBulk checks operations against the snapshot, applying each to it, and
+// records their outcomes in result. It returns the changes to write and the
+// dates they touch, or nil changes when the set is rejected.
+func (s *snapshot) planBulk(result *BulkResult, ops []BulkOperation, partial bool, editorID uuid.UUID) (*entity.AssignmentChanges, []time.Time) {
+	if len(ops) == 0 {
+		result.Validation.AddError("NO_OPERATIONS", "At least one operation is required")
+		return nil, nil
+	}
+	if len(ops) > MaxBulkOperations {
+		result.Validation.AddError("TOO_MANY_OPERATIONS",
+			fmt.Sprintf("A bulk edit can carry at most %d operations, got %d", MaxBulkOperations, len(ops)))
+		return nil, nil
+	}
+
+	var created []*entity.Assignment
+	touched := make(map[uuid.UUID]*entity.Assignment) // existing assignments removed or moved
+	dates := []time.Time{}
+	rejected := false
+
+	for i, op := range ops {
+		vr := validation.NewResult()
+		res := BulkOperationResult{Index: i, Type: op.Type}
+
+		switch op.Type {
+		case BulkAdd:
+			shift := s.shifts[op.ShiftID]
+			s.checkAssignment(vr, op.PersonID, shift)
+			if !vr.HasErrors() {
+				a := s.newAssignment(op.PersonID, shift, editorID)
+				s.assignments[a.ID] = a
+				created = append(created, a)
+				res.Assignment = a
+				dates = append(dates, shift.ScheduleDate)
+			}
+
+		case BulkRemove:
+			a, ok := s.assignments[op.AssignmentID]
+			if !ok {
+				vr.AddErrorWithContext("ASSIGNMENT_NOT_FOUND", "Assignment is not live in this schedule version",
+					map[string]interface{}{"assignment_id": op.AssignmentID.String()})
+				break
+			}
+			delete(s.assignments, a.ID)
+			touched[a.ID] = a
+			dates = append(dates, a.ScheduleDate)
+
+		case BulkMove:
+			a, ok := s.assignments[op.AssignmentID]
+			if !ok {
+				vr.AddErrorWithContext("ASSIGNMENT_NOT_FOUND", "Assignment is not live in this schedule version",
+					map[string]interface{}{"assignment_id": op.AssignmentID.String()})
+				break
+			}
+			personID := op.PersonID
+			if personID == uuid.Nil {
+				personID = a.PersonID
+			}
+			shiftID := op.ShiftID
+			if shiftID == uuid.Nil {
+				shiftID = a.ShiftInstanceID
+			}
+			if shiftID == a.ShiftInstanceID && personID == a.PersonID {
+				vr.AddErrorWithContext("NO_CHANGE", "The move leaves the assignment where it is",
+					map[string]interface{}{"assignment_id": a.ID.String()})
+				break
+			}
+			shift := s.shifts[shiftID]
+			s.checkAssignment(vr, personID, shift, a.ID)
+			if vr.HasErrors() {
+				break
+			}
+			dates = append(dates, a.ScheduleDate, shift.ScheduleDate)
+			moved := *a
+			moved.PersonID = personID
+			moved.ShiftInstanceID = shift.ID
+			moved.ScheduleDate = shift.ScheduleDate
+			moved.OriginalShiftType = string(shift.ShiftType)
+			s.markEdited(&moved)
+			s.assignments[moved.ID] = &moved
+			touched[moved.ID] = &moved
+			res.Assignment = &moved
+
+		default:
+			vr.AddError("INVALID_OPERATION", fmt.Sprintf("Unknown operation type %q; use add, remove or move", op.Type))
+		}
+
+		if vr.HasErrors() {
+			res.Status = BulkRejected
+			res.Messages = vr.Messages
+			rejected = true
+		} else {
+			res.Status = BulkApplied
+		}
+		result.Results = append(result.Results, res)
+	}
+
+	if rejected && !partial {
+		for i := range result.Results {
+			if result.Results[i].Status == BulkApplied {
+				result.Results[i].Status = BulkNotApplied
+				result.Results[i].Assignment = nil
+			}
+		}
+		result.Validation.AddError("OPERATIONS_REJECTED", "One or more operations failed validation; nothing was applied")
+		return nil, nil
+	}
+
+	changes := &entity.AssignmentChanges{Create: created}
+	for id, a := range touched {
+		if _, live := s.assignments[id]; live {
+			changes.Update = append(changes.Update, a)
+		} else {
+			changes.Delete = append(changes.Delete, id)
+		}
+	}
+	result.Applied = true
+	return changes, dates
+}
+
+// staffing counts the live assignments on each shift
+func (s *snapshot) staffing() map[uuid.UUID]int {
+	counts := make(map[uuid.UUID]int, len(s.shifts))
+	for _, a := range s.assignments {
+		counts[a.ShiftInstanceID]++
+	}
+	return counts
+}
@@ -0,0 +1,131 @@
+package editing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyBulk_ValidatesInOrder(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	onNeuro := f.assign(f.both, f.neuroDay, entity.AssignmentSourceAmion)
+
+	// Move Bea to the body shift, then put Nina on the neuro shift Bea left
+	// and Bo on the overnight
+	result, err := f.editor.ApplyBulk(context.Background(), f.version.ID, []BulkOperation{
+		{Type: BulkMove, AssignmentID: onNeuro.ID, ShiftID: f.bodyDay.ID},
+		{Type: BulkAdd, ShiftID: f.neuroDay.ID, PersonID: f.neuro.ID},
+		{Type: BulkAdd, ShiftID: f.overnight.ID, PersonID: f.body.ID},
+	}, false, Options{})
+	require.NoError(t, err)
+	require.True(t, result.Applied, result.Validation.Summary())
+	for _, r := range result.Results {
+		assert.Equal(t, BulkApplied, r.Status)
+	}
+
+	require.Len(t, f.assignments.Applied(), 1, "written in one transaction")
+	changes := f.assignments.Applied()[0]
+	assert.Len(t, changes.Create, 2)
+	require.Len(t, changes.Update, 1)
+	assert.Equal(t, f.bodyDay.ID, changes.Update[0].ShiftInstanceID)
+	assert.Equal(t, entity.AssignmentSourceManual, changes.Update[0].Source)
+	assert.Empty(t, changes.Delete)
+
+	// Neuro day stays at one person; body day and the overnight go 0 -> 1
+	require.Len(t, result.CoverageDelta, 2)
+	for _, d := range result.CoverageDelta {
+		assert.Equal(t, 0, d.Before)
+		assert.Equal(t, 1, d.After)
+	}
+	assert.Len(t, f.queue.Ranges(), 1)
+}
+
+func TestApplyBulk_ValidatesLockedState(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	// Someone else puts Nina on the neuro shift just before the bulk edit
+	// takes the lock; the set must be checked against that
+	f.assignments.SetBeforeEdit(func() { f.assign(f.neuro, f.neuroDay, entity.AssignmentSourceManual) })
+
+	result, err := f.editor.ApplyBulk(context.Background(), f.version.ID, []BulkOperation{
+		{Type: BulkAdd, ShiftID: f.neuroDay.ID, PersonID: f.neuro.ID},
+	}, false, Options{})
+	require.NoError(t, err)
+	assert.False(t, result.Applied)
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, "ALREADY_ASSIGNED", result.Results[0].Messages[0].Code)
+	assert.Empty(t, f.assignments.Applied())
+}
+
+func TestApplyBulk_RejectsWholeSet(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	onNeuro := f.assign(f.neuro, f.neuroDay, entity.AssignmentSourceAmion)
+
+	ops := []BulkOperation{
+		{Type: BulkRemove, AssignmentID: onNeuro.ID},
+		{Type: BulkAdd, ShiftID: f.bodyDay.ID, PersonID: f.neuro.ID},
+		{Type: BulkAdd, ShiftID: f.overnight.ID, PersonID: uuid.New()},
+		{Type: "teleport"},
+	}
+	result, err := f.editor.ApplyBulk(context.Background(), f.version.ID, ops, false, Options{})
+	require.NoError(t, err)
+	assert.False(t, result.Applied)
+	assert.Len(t, result.Validation.MessagesByCode("OPERATIONS_REJECTED"), 1)
+	assert.Equal(t, BulkNotApplied, result.Results[0].Status)
+	assert.Equal(t, BulkRejected, result.Results[1].Status)
+	assert.Equal(t, "SPECIALTY_MISMATCH", result.Results[1].Messages[0].Code)
+	assert.Equal(t, "PERSON_NOT_FOUND", result.Results[2].Messages[0].Code)
+	assert.Equal(t, "INVALID_OPERATION", result.Results[3].Messages[0].Code)
+	assert.Empty(t, f.assignments.Applied(), "nothing is written")
+	assert.False(t, onNeuro.IsDeleted())
+	assert.Empty(t, f.queue.Ranges())
+
+	result, err = f.editor.ApplyBulk(context.Background(), f.version.ID, ops, true, Options{})
+	require.NoError(t, err)
+	assert.True(t, result.Applied, "partial mode skips the rejected operations")
+	assert.Equal(t, BulkApplied, result.Results[0].Status)
+	assert.Equal(t, BulkRejected, result.Results[1].Status)
+	require.Len(t, f.assignments.Applied(), 1)
+	assert.Equal(t, []uuid.UUID{onNeuro.ID}, f.assignments.Applied()[0].Delete)
+	assert.True(t, onNeuro.IsDeleted())
+	require.Len(t, result.CoverageDelta, 1)
+	assert.Equal(t, 1, result.CoverageDelta[0].Before)
+	assert.Equal(t, 0, result.CoverageDelta[0].After)
+	assert.Len(t, result.Validation.MessagesByCode("SHIFT_UNDERSTAFFED"), 1)
+}
+
+func TestApplyBulk_SeesEarlierOperations(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	onBody := f.assign(f.both, f.bodyDay, entity.AssignmentSourceAmion)
+
+	// Bea cannot be added to the overlapping neuro shift until she is
+	// removed from the body shift, and a removed assignment cannot be moved
+	result, err := f.editor.ApplyBulk(context.Background(), f.version.ID, []BulkOperation{
+		{Type: BulkAdd, ShiftID: f.neuroDay.ID, PersonID: f.both.ID},
+		{Type: BulkRemove, AssignmentID: onBody.ID},
+		{Type: BulkAdd, ShiftID: f.neuroDay.ID, PersonID: f.both.ID},
+		{Type: BulkMove, AssignmentID: onBody.ID, ShiftID: f.overnight.ID},
+	}, true, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "SHIFT_OVERLAP", result.Results[0].Messages[0].Code)
+	assert.Equal(t, BulkApplied, result.Results[1].Status)
+	assert.Equal(t, BulkApplied, result.Results[2].Status)
+	assert.Equal(t, "ASSIGNMENT_NOT_FOUND", result.Results[3].Messages[0].Code)
+}
+
+func TestApplyBulk_Limits(t *testing.T) {
+	f := newFixture(entity.VersionStatusProduction)
+	_, err := f.editor.ApplyBulk(context.Background(), f.version.ID, []BulkOperation{{Type: BulkAdd}}, false, Options{})
+	assert.ErrorIs(t, err, ErrProductionLocked)
+
+	result, err := f.editor.ApplyBulk(context.Background(), f.version.ID, nil, false, Options{AllowProduction: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Validation.MessagesByCode("NO_OPERATIONS"), 1)
+
+	result, err = f.editor.ApplyBulk(context.Background(), f.version.ID, make([]BulkOperation, MaxBulkOperations+1), false, Options{AllowProduction: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Validation.MessagesByCode("TOO_MANY_OPERATIONS"), 1)
+}
@@ -0,0 +1,258 @@
+// Package editing changes individual assignments and shift instances by hand.
+// Edits go to STAGING versions; PRODUCTION versions can only be edited when
+// the caller confirms it. Each edit is checked against specialty and overlap
+// rules before anything is written, and queues a coverage recalculation.
+package editing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+var (
+	// ErrVersionArchived is returned for edits to an ARCHIVED version
+	ErrVersionArchived = errors.New("archived schedule versions cannot be edited")
+	// ErrProductionLocked is returned for unconfirmed edits to a PRODUCTION version
+	ErrProductionLocked = errors.New("editing a PRODUCTION schedule version must be confirmed")
+)
+
+// Options say who is editing and whether a PRODUCTION version may be changed
+type Options struct {
+	EditorID        uuid.UUID
+	AllowProduction bool
+}
+
+// Editor applies manual edits to schedule versions
+type Editor struct {
+	versions    repository.ScheduleVersionRepository
+	shifts      repository.ShiftInstanceRepository
+	assignments repository.AssignmentRepository
+	people      repository.PersonRepository
+	coverage    service.CoverageQueue // Optional: recalculates coverage after each edit
+}
+
+// NewEditor creates an editor
+func NewEditor(
+	versions repository.ScheduleVersionRepository,
+	shifts repository.ShiftInstanceRepository,
+	assignments repository.AssignmentRepository,
+	people repository.PersonRepository,
+) *Editor {
+	return &Editor{
+		versions:    versions,
+		shifts:      shifts,
+		assignments: assignments,
+		people:      people,
+	}
+}
+
+// SetCoverageQueue enables coverage recalculation after edits
+func (e *Editor) SetCoverageQueue(queue service.CoverageQueue) {
+	e.coverage = queue
+}
+
+// AddAssignment puts a person on a shift
+func (e *Editor) AddAssignment(ctx context.Context, shiftID, personID uuid.UUID, opts Options) (*entity.Assignment, *validation.Result, error) {
+	shift, err := e.shifts.GetByID(ctx, shiftID)
+	if err != nil {
+		return nil, nil, err
+	}
+	snap, err := e.load(ctx, shift.ScheduleVersionID, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vr := validation.NewResult()
+	snap.checkAssignment(vr, personID, snap.shifts[shift.ID])
+	if vr.HasErrors() {
+		return nil, vr, nil
+	}
+	snap.checkStaffing(vr, shift, 1)
+
+	assignment := snap.newAssignment(personID, shift, opts.EditorID)
+	if err := e.assignments.Create(ctx, assignment); err != nil {
+		return nil, nil, fmt.Errorf("failed to create assignment: %w", err)
+	}
+
+	service.QueueCoverageRecalculation(ctx, e.coverage, snap.version.ID, opts.EditorID, shift.ScheduleDate)
+	return assignment, vr, nil
+}
+
+// RemoveAssignment takes a person off a shift
+func (e *Editor) RemoveAssignment(ctx context.Context, assignmentID uuid.UUID, opts Options) (*validation.Result, error) {
+	assignment, shift, err := e.assignmentAndShift(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := e.load(ctx, shift.ScheduleVersionID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	vr := validation.NewResult()
+	snap.checkStaffing(vr, shift, -1)
+
+	if err := e.assignments.Delete(ctx, assignment.ID, opts.EditorID); err != nil {
+		return nil, fmt.Errorf("failed to delete assignment: %w", err)
+	}
+
+	service.QueueCoverageRecalculation(ctx, e.coverage, snap.version.ID, opts.EditorID, shift.ScheduleDate)
+	return vr, nil
+}
+
+// SwapAssignments exchanges the people on two assignments of the same version
+func (e *Editor) SwapAssignments(ctx context.Context, firstID, secondID uuid.UUID, opts Options) ([]*entity.Assignment, *validation.Result, error) {
+	first, firstShift, err := e.assignmentAndShift(ctx, firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, secondShift, err := e.assignmentAndShift(ctx, secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vr := validation.NewResult()
+	if firstShift.ScheduleVersionID != secondShift.ScheduleVersionID {
+		vr.AddError("DIFFERENT_VERSIONS", "Only assignments in the same schedule version can be swapped")
+		return nil, vr, nil
+	}
+	if first.PersonID == second.PersonID {
+		vr.AddError("SAME_PERSON", "Both assignments belong to the same person")
+		return nil, vr, nil
+	}
+
+	snap, err := e.load(ctx, firstShift.ScheduleVersionID, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	snap.checkAssignment(vr, first.PersonID, secondShift, first.ID, second.ID)
+	snap.checkAssignment(vr, second.PersonID, firstShift, first.ID, second.ID)
+	if vr.HasErrors() {
+		return nil, vr, nil
+	}
+
+	first.PersonID, second.PersonID = second.PersonID, first.PersonID
+	for _, a := range []*entity.Assignment{first, second} {
+		snap.markEdited(a)
+		if err := e.assignments.Update(ctx, a); err != nil {
+			return nil, nil, fmt.Errorf("failed to update assignment: %w", err)
+		}
+	}
+
+	service.QueueCoverageRecalculation(ctx, e.coverage, snap.version.ID, opts.EditorID, firstShift.ScheduleDate, secondShift.ScheduleDate)
+	return []*entity.Assignment{first, second}, vr, nil
+}
+
+// AddShift adds an ad-hoc shift instance to a version. The shift's type,
+// date, times, study type, specialty constraint, coverage and mandatory flag
+// are taken from spec.
+func (e *Editor) AddShift(ctx context.Context, versionID uuid.UUID, spec *entity.ShiftInstance, opts Options) (*entity.ShiftInstance, *validation.Result, error) {
+	snap, err := e.load(ctx, versionID, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vr := validation.NewResult()
+	shift := *spec
+	shift.ShiftType = entity.ShiftType(strings.TrimSpace(string(shift.ShiftType)))
+	if shift.ShiftType == "" {
+		vr.AddError("INVALID_SHIFT_TYPE", "shift_type is required")
+	}
+	day := shift.ScheduleDate
+	if day.Before(snap.version.EffectiveStartDate) || day.After(snap.version.EffectiveEndDate) {
+		vr.AddErrorWithContext("SHIFT_OUTSIDE_VERSION", "Shift date is outside the schedule version's effective dates",
+			map[string]interface{}{"date": day.Format("2006-01-02")})
+	}
+	for _, clock := range []*string{&shift.StartTime, &shift.EndTime} {
+		if normalized := entity.NormalizeClock(*clock); normalized != "" {
+			*clock = normalized
+		} else if strings.TrimSpace(*clock) != "" {
+			vr.AddError("INVALID_TIME", fmt.Sprintf("%q is not an HH:MM time", *clock))
+		}
+	}
+	if shift.StudyType != "" && !entity.ValidateStudyType(string(shift.StudyType)) {
+		vr.AddError("INVALID_STUDY_TYPE", fmt.Sprintf("Unknown study type %q", shift.StudyType))
+	}
+	if shift.SpecialtyConstraint != "" && !entity.ValidateSpecialty(string(shift.SpecialtyConstraint)) {
+		vr.AddError("INVALID_SPECIALTY", fmt.Sprintf("Unknown specialty constraint %q", shift.SpecialtyConstraint))
+	}
+	if shift.DesiredCoverage < 0 {
+		vr.AddError("INVALID_COVERAGE", "desired_coverage cannot be negative")
+	}
+	if vr.HasErrors() {
+		return nil, vr, nil
+	}
+	// Shifts of one type on a day are distinct slots when their study type or specialty differ
+	for _, other := range snap.shifts {
+		if other.Slot() == shift.Slot() {
+			vr.AddWarningWithContext("DUPLICATE_SHIFT", fmt.Sprintf("The version already has a %s shift on %s",
+				shift.ShiftType, day.Format("2006-01-02")), map[string]interface{}{
+				"shift_id":             other.ID.String(),
+				"study_type":           string(shift.StudyType),
+				"specialty_constraint": string(shift.SpecialtyConstraint),
+			})
+			break
+		}
+	}
+
+	shift.ID = uuid.New()
+	shift.ScheduleVersionID = snap.version.ID
+	shift.HospitalID = snap.version.HospitalID
+	shift.ScrapeBatchID = nil
+	shift.CreatedAt = entity.Now()
+	shift.CreatedBy = opts.EditorID
+	if err := e.shifts.Create(ctx, &shift); err != nil {
+		return nil, nil, fmt.Errorf("failed to create shift instance: %w", err)
+	}
+
+	service.QueueCoverageRecalculation(ctx, e.coverage, snap.version.ID, opts.EditorID, shift.ScheduleDate)
+	return &shift, vr, nil
+}
+
+// SetDesiredCoverage changes how many people a shift needs
+func (e *Editor) SetDesiredCoverage(ctx context.Context, shiftID uuid.UUID, coverage int, opts Options) (*entity.ShiftInstance, *validation.Result, error) {
+	shift, err := e.shifts.GetByID(ctx, shiftID)
+	if err != nil {
+		return nil, nil, err
+	}
+	snap, err := e.load(ctx, shift.ScheduleVersionID, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vr := validation.NewResult()
+	if coverage < 0 {
+		vr.AddError("INVALID_COVERAGE", "desired_coverage cannot be negative")
+		return nil, vr, nil
+	}
+	shift.DesiredCoverage = coverage
+	snap.checkStaffing(vr, shift, 0)
+
+	if err := e.shifts.Update(ctx, shift); err != nil {
+		return nil, nil, fmt.Errorf("failed to update shift instance: %w", err)
+	}
+
+	service.QueueCoverageRecalculation(ctx, e.coverage, snap.version.ID, opts.EditorID, shift.ScheduleDate)
+	return shift, vr, nil
+}
+
+// assignmentAndShift loads an assignment and the shift it is on
+func (e *Editor) assignmentAndShift(ctx context.Context, assignmentID uuid.UUID) (*entity.Assignment, *entity.ShiftInstance, error) {
+	assignment, err := e.assignments.GetByID(ctx, assignmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	shift, err := e.shifts.GetByID(ctx, assignment.ShiftInstanceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return assignment, shift, nil
+}
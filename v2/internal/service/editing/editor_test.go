@@ -0,0 +1,186 @@
+package editing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/tests/helpers"
+	"github.com/schedcu/v2/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixture is a STAGING version with a neuro day shift, a body day shift and
+// an overnight, and three radiologists
+type fixture struct {
+	editor      *Editor
+	version     *entity.ScheduleVersion
+	shifts      *mocks.MockShiftInstanceRepository
+	assignments *mocks.MockAssignmentRepository
+	queue       *mocks.MockCoverageQueue
+	neuroDay    *entity.ShiftInstance
+	bodyDay     *entity.ShiftInstance
+	overnight   *entity.ShiftInstance
+	neuro       *entity.Person
+	body        *entity.Person
+	both        *entity.Person
+}
+
+func newFixture(status entity.VersionStatus) *fixture {
+	ctx := context.Background()
+	schedule := mocks.NewMockSchedule()
+	f := &fixture{shifts: schedule.Shifts, assignments: schedule.Assignments, queue: mocks.NewMockCoverageQueue()}
+	f.version = helpers.NewScheduleVersionBuilder().WithStatus(status).
+		WithEffectiveStartDate(helpers.Date("2025-11-01")).WithEffectiveEndDate(helpers.Date("2025-11-30")).Build()
+	shift := func(shiftType entity.ShiftType, start, end string, specialty entity.SpecialtyType) *entity.ShiftInstance {
+		return helpers.NewShiftInstanceBuilder().
+			WithScheduleVersionID(f.version.ID).WithHospitalID(f.version.HospitalID).
+			WithShiftType(shiftType).WithScheduleDate(helpers.Date("2025-11-03")).
+			WithStartTime(start).WithEndTime(end).WithSpecialtyConstraint(specialty).Build()
+	}
+	f.neuroDay = shift(entity.ShiftTypeDay, "07:00", "17:00", entity.SpecialtyNeuroOnly)
+	f.bodyDay = shift(entity.ShiftTypeMidC, "08:00", "16:00", entity.SpecialtyBodyOnly)
+	f.overnight = shift(entity.ShiftTypeON1, "19:00", "07:00", entity.SpecialtyBoth)
+	schedule.Versions.Create(ctx, f.version)
+	schedule.Shifts.CreateBatch(ctx, []*entity.ShiftInstance{f.neuroDay, f.bodyDay, f.overnight})
+
+	f.neuro = helpers.NewPersonBuilder().WithName("Nina Neuro").WithSpecialty(entity.SpecialtyNeuroOnly).Build()
+	f.body = helpers.NewPersonBuilder().WithName("Bo Body").WithSpecialty(entity.SpecialtyBodyOnly).Build()
+	f.both = helpers.NewPersonBuilder().WithName("Bea Both").WithSpecialty(entity.SpecialtyBoth).Build()
+
+	for _, person := range []*entity.Person{f.neuro, f.body, f.both} {
+		schedule.People.Create(ctx, person)
+	}
+
+	f.editor = NewEditor(schedule.Versions, schedule.Shifts, schedule.Assignments, schedule.People)
+	f.editor.SetCoverageQueue(f.queue)
+	return f
+}
+
+func (f *fixture) assign(person *entity.Person, shift *entity.ShiftInstance, source entity.AssignmentSource) *entity.Assignment {
+	a := helpers.NewAssignmentBuilder().WithPersonID(person.ID).WithShiftInstanceID(shift.ID).
+		WithScheduleDate(shift.ScheduleDate).WithOriginalShiftType(string(shift.ShiftType)).WithSource(source).Build()
+	f.assignments.Create(context.Background(), a)
+	return a
+}
+
+func TestAddAssignment(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	ctx := context.Background()
+
+	assignment, vr, err := f.editor.AddAssignment(ctx, f.neuroDay.ID, f.neuro.ID, Options{EditorID: uuid.New()})
+	require.NoError(t, err)
+	assert.False(t, vr.HasErrors())
+	assert.Equal(t, entity.AssignmentSourceManual, assignment.Source)
+	assert.True(t, assignment.IsPinned())
+	assert.Len(t, f.queue.Ranges(), 1, "coverage is recalculated")
+
+	_, vr, err = f.editor.AddAssignment(ctx, f.bodyDay.ID, f.neuro.ID, Options{})
+	require.NoError(t, err)
+	assert.Len(t, vr.MessagesByCode("SPECIALTY_MISMATCH"), 1)
+
+	_, vr, err = f.editor.AddAssignment(ctx, f.bodyDay.ID, f.both.ID, Options{})
+	require.NoError(t, err)
+	assert.False(t, vr.HasErrors())
+	_, vr, err = f.editor.AddAssignment(ctx, f.neuroDay.ID, f.both.ID, Options{})
+	require.NoError(t, err)
+	assert.Len(t, vr.MessagesByCode("SHIFT_OVERLAP"), 1, "already on the body shift at the same time")
+	assert.Len(t, f.assignments.All(), 2, "rejected edits are not written")
+}
+
+func TestEditsGuardProduction(t *testing.T) {
+	f := newFixture(entity.VersionStatusProduction)
+	ctx := context.Background()
+
+	_, _, err := f.editor.AddAssignment(ctx, f.neuroDay.ID, f.neuro.ID, Options{})
+	assert.ErrorIs(t, err, ErrProductionLocked)
+
+	assignment, _, err := f.editor.AddAssignment(ctx, f.neuroDay.ID, f.neuro.ID, Options{AllowProduction: true})
+	require.NoError(t, err)
+	assert.Equal(t, entity.AssignmentSourceOverride, assignment.Source)
+
+	f.version.Status = entity.VersionStatusArchived
+	_, err = f.editor.RemoveAssignment(ctx, assignment.ID, Options{AllowProduction: true})
+	assert.ErrorIs(t, err, ErrVersionArchived)
+}
+
+func TestSwapAssignments(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	ctx := context.Background()
+	onDay := f.assign(f.both, f.neuroDay, entity.AssignmentSourceAmion)
+	overnight := f.assign(f.neuro, f.overnight, entity.AssignmentSourceAmion)
+
+	swapped, vr, err := f.editor.SwapAssignments(ctx, onDay.ID, overnight.ID, Options{})
+	require.NoError(t, err)
+	require.False(t, vr.HasErrors(), vr.Summary())
+	require.Len(t, swapped, 2)
+	assert.Equal(t, f.neuro.ID, onDay.PersonID)
+	assert.Equal(t, f.both.ID, overnight.PersonID)
+	assert.Equal(t, entity.AssignmentSourceManual, onDay.Source, "swapped assignments are pinned")
+
+	body := f.assign(f.body, f.bodyDay, entity.AssignmentSourceAmion)
+	_, vr, err = f.editor.SwapAssignments(ctx, onDay.ID, body.ID, Options{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, vr.MessagesByCode("SPECIALTY_MISMATCH"))
+	assert.Equal(t, f.neuro.ID, onDay.PersonID, "a rejected swap changes nothing")
+}
+
+func TestRemoveAssignment(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	a := f.assign(f.neuro, f.neuroDay, entity.AssignmentSourceAmion)
+
+	vr, err := f.editor.RemoveAssignment(context.Background(), a.ID, Options{})
+	require.NoError(t, err)
+	assert.True(t, a.IsDeleted())
+	assert.Len(t, vr.MessagesByCode("SHIFT_UNDERSTAFFED"), 1)
+}
+
+func TestAddShiftAndSetDesiredCoverage(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	ctx := context.Background()
+
+	shift, vr, err := f.editor.AddShift(ctx, f.version.ID, &entity.ShiftInstance{
+		ShiftType: "Extra Reader", ScheduleDate: f.neuroDay.ScheduleDate.AddDate(0, 0, 1), StartTime: "9:00", EndTime: "13:00", DesiredCoverage: 1,
+	}, Options{})
+	require.NoError(t, err)
+	require.False(t, vr.HasErrors(), vr.Summary())
+	assert.Equal(t, "09:00", shift.StartTime)
+	assert.Equal(t, f.version.HospitalID, shift.HospitalID)
+	assert.Nil(t, shift.ScrapeBatchID)
+
+	_, vr, err = f.editor.AddShift(ctx, f.version.ID, &entity.ShiftInstance{ShiftType: "Extra", ScheduleDate: f.neuroDay.ScheduleDate.AddDate(0, 2, 0)}, Options{})
+	require.NoError(t, err)
+	assert.Len(t, vr.MessagesByCode("SHIFT_OUTSIDE_VERSION"), 1)
+
+	f.assign(f.neuro, f.neuroDay, entity.AssignmentSourceAmion)
+	updated, vr, err := f.editor.SetDesiredCoverage(ctx, f.neuroDay.ID, 0, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated.DesiredCoverage)
+	assert.Len(t, vr.MessagesByCode("SHIFT_OVERSTAFFED"), 1)
+
+	_, vr, err = f.editor.SetDesiredCoverage(ctx, f.neuroDay.ID, -1, Options{})
+	require.NoError(t, err)
+	assert.True(t, vr.HasErrors())
+}
+
+func TestAddShiftWarnsOnlyForTheSameSlot(t *testing.T) {
+	f := newFixture(entity.VersionStatusStaging)
+	ctx := context.Background()
+
+	// Another DAY shift on Nov 3, but for body rather than neuro
+	_, vr, err := f.editor.AddShift(ctx, f.version.ID, &entity.ShiftInstance{
+		ShiftType: entity.ShiftTypeDay, ScheduleDate: f.neuroDay.ScheduleDate, StudyType: entity.StudyTypeGeneral,
+		SpecialtyConstraint: entity.SpecialtyBodyOnly, DesiredCoverage: 1,
+	}, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, vr.MessagesByCode("DUPLICATE_SHIFT"))
+
+	_, vr, err = f.editor.AddShift(ctx, f.version.ID, &entity.ShiftInstance{
+		ShiftType: entity.ShiftTypeDay, ScheduleDate: f.neuroDay.ScheduleDate, StudyType: entity.StudyTypeGeneral,
+		SpecialtyConstraint: entity.SpecialtyNeuroOnly, DesiredCoverage: 1,
+	}, Options{})
+	require.NoError(t, err)
+	assert.Len(t, vr.MessagesByCode("DUPLICATE_SHIFT"), 1)
+}
@@ -0,0 +1,168 @@
+package editing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+// snapshot is a version's shifts, live assignments and the hospital's staff,
+// loaded once so edits can be checked against them
+type snapshot struct {
+	version     *entity.ScheduleVersion
+	source      entity.AssignmentSource // MANUAL on STAGING, OVERRIDE on PRODUCTION
+	shifts      map[uuid.UUID]*entity.ShiftInstance
+	assignments map[uuid.UUID]*entity.Assignment
+	people      map[uuid.UUID]*entity.Person
+}
+
+// load checks the version may be edited and loads its snapshot
+func (e *Editor) load(ctx context.Context, versionID uuid.UUID, opts Options) (*snapshot, error) {
+	version, err := e.versions.GetByID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := e.shifts.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shift instances: %w", err)
+	}
+	assignments, err := e.assignments.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assignments: %w", err)
+	}
+	return e.snapshot(ctx, version, shifts, assignments, opts)
+}
+
+// snapshot checks the version may be edited and builds its snapshot from
+// shifts and assignments already read, loading the hospital's staff
+func (e *Editor) snapshot(ctx context.Context, version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment, opts Options) (*snapshot, error) {
+	snap := &snapshot{
+		version:     version,
+		source:      entity.AssignmentSourceManual,
+		shifts:      make(map[uuid.UUID]*entity.ShiftInstance),
+		assignments: make(map[uuid.UUID]*entity.Assignment),
+		people:      make(map[uuid.UUID]*entity.Person),
+	}
+	switch version.Status {
+	case entity.VersionStatusArchived:
+		return nil, ErrVersionArchived
+	case entity.VersionStatusProduction:
+		if !opts.AllowProduction {
+			return nil, ErrProductionLocked
+		}
+		snap.source = entity.AssignmentSourceOverride
+	}
+
+	for _, s := range shifts {
+		snap.shifts[s.ID] = s
+	}
+	for _, a := range assignments {
+		if !a.IsDeleted() {
+			snap.assignments[a.ID] = a
+		}
+	}
+	people, err := e.people.GetByHospital(ctx, version.HospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load staff: %w", err)
+	}
+	for _, p := range people {
+		snap.people[p.ID] = p
+	}
+	return snap, nil
+}
+
+// checkAssignment adds an error to vr unless the person may be put on the
+// shift: an active staff member of the hospital whose specialty the shift
+// accepts, not already on it and not on another shift at the same time.
+// Assignments in ignore are being changed by the same edit.
+func (s *snapshot) checkAssignment(vr *validation.Result, personID uuid.UUID, shift *entity.ShiftInstance, ignore ...uuid.UUID) {
+	details := map[string]interface{}{"person_id": personID.String()}
+	if shift == nil {
+		vr.AddErrorWithContext("SHIFT_NOT_IN_VERSION", "Shift is not part of this schedule version", details)
+		return
+	}
+	details["shift_id"] = shift.ID.String()
+
+	person, ok := s.people[personID]
+	if !ok || !person.Active || person.DeletedAt != nil {
+		vr.AddErrorWithContext("PERSON_NOT_FOUND", "No active staff member of this hospital has that ID", details)
+		return
+	}
+	if !person.CanCover(shift.SpecialtyConstraint) {
+		details["specialty"] = string(person.Specialty)
+		details["specialty_constraint"] = string(shift.SpecialtyConstraint)
+		vr.AddErrorWithContext("SPECIALTY_MISMATCH", fmt.Sprintf("%s (%s) cannot cover a %s shift",
+			person.Name, person.Specialty, shift.SpecialtyConstraint), details)
+		return
+	}
+
+	ignored := make(map[uuid.UUID]bool, len(ignore))
+	for _, id := range ignore {
+		ignored[id] = true
+	}
+	for _, a := range s.assignments {
+		if ignored[a.ID] || a.PersonID != personID {
+			continue
+		}
+		if a.ShiftInstanceID == shift.ID {
+			vr.AddErrorWithContext("ALREADY_ASSIGNED", fmt.Sprintf("%s is already on this shift", person.Name), details)
+			return
+		}
+		other, ok := s.shifts[a.ShiftInstanceID]
+		if ok && other.Overlaps(shift) {
+			details["overlapping_shift_id"] = other.ID.String()
+			vr.AddErrorWithContext("SHIFT_OVERLAP", fmt.Sprintf("%s is already on %s on %s at the same time",
+				person.Name, other.ShiftType, other.ScheduleDate.Format("2006-01-02")), details)
+			return
+		}
+	}
+}
+
+// checkStaffing warns when the shift would end up over- or understaffed
+// after change people are added (or removed, when negative)
+func (s *snapshot) checkStaffing(vr *validation.Result, shift *entity.ShiftInstance, change int) {
+	assigned := change
+	for _, a := range s.assignments {
+		if a.ShiftInstanceID == shift.ID {
+			assigned++
+		}
+	}
+	details := map[string]interface{}{
+		"shift_id":         shift.ID.String(),
+		"assigned":         assigned,
+		"desired_coverage": shift.DesiredCoverage,
+	}
+	switch {
+	case assigned > shift.DesiredCoverage:
+		vr.AddWarningWithContext("SHIFT_OVERSTAFFED", fmt.Sprintf("%s on %s has %d people for %d positions",
+			shift.ShiftType, shift.ScheduleDate.Format("2006-01-02"), assigned, shift.DesiredCoverage), details)
+	case assigned < shift.DesiredCoverage:
+		vr.AddWarningWithContext("SHIFT_UNDERSTAFFED", fmt.Sprintf("%s on %s has %d people for %d positions",
+			shift.ShiftType, shift.ScheduleDate.Format("2006-01-02"), assigned, shift.DesiredCoverage), details)
+	}
+}
+
+// newAssignment creates an unsaved hand-made assignment
+func (s *snapshot) newAssignment(personID uuid.UUID, shift *entity.ShiftInstance, editorID uuid.UUID) *entity.Assignment {
+	return &entity.Assignment{
+		ID:                uuid.New(),
+		PersonID:          personID,
+		ShiftInstanceID:   shift.ID,
+		ScheduleDate:      shift.ScheduleDate,
+		OriginalShiftType: string(shift.ShiftType),
+		Source:            s.source,
+		CreatedAt:         entity.Now(),
+		CreatedBy:         editorID,
+	}
+}
+
+// markEdited records that an assignment was changed by hand, which pins it
+// against imports again
+func (s *snapshot) markEdited(a *entity.Assignment) {
+	a.Source = s.source
+	a.UnpinnedAt = nil
+	a.UnpinnedBy = nil
+}
@@ -0,0 +1,277 @@
+// Package freshness watches Amion scrape batches so that a nightly scrape that
+// silently fails (or "succeeds" with no data) is noticed before stale
+// assignments are served for long.
+package freshness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// AnomalyKind classifies a scrape freshness problem
+type AnomalyKind string
+
+const (
+	// AnomalyNeverScraped means the hospital has no successful scrape at all
+	AnomalyNeverScraped AnomalyKind = "NEVER_SCRAPED"
+	// AnomalyStale means the last successful scrape is older than Config.MaxAge
+	AnomalyStale AnomalyKind = "STALE"
+	// AnomalyZeroRows means the latest COMPLETE batch imported no rows
+	AnomalyZeroRows AnomalyKind = "ZERO_ROWS_COMPLETE"
+	// AnomalyRowCountDrop means the latest successful scrape has far fewer rows than usual
+	AnomalyRowCountDrop AnomalyKind = "ROW_COUNT_DROP"
+	// AnomalyLastAttemptFailed means the most recent batch is FAILED
+	AnomalyLastAttemptFailed AnomalyKind = "LAST_ATTEMPT_FAILED"
+)
+
+// AnomalyKinds lists every kind, e.g. for resetting metrics
+var AnomalyKinds = []AnomalyKind{
+	AnomalyNeverScraped,
+	AnomalyStale,
+	AnomalyZeroRows,
+	AnomalyRowCountDrop,
+	AnomalyLastAttemptFailed,
+}
+
+// Anomaly is one detected problem
+type Anomaly struct {
+	Kind    AnomalyKind `json:"kind"`
+	Message string      `json:"message"`
+}
+
+// Config holds freshness thresholds
+type Config struct {
+	MaxAge         time.Duration // Last success older than this is STALE
+	TrailingWindow int           // Number of earlier successful batches averaged for the drop check
+	MinHistory     int           // Earlier successful batches needed before the drop check applies
+	DropRatio      float64       // Flag when rows < DropRatio * trailing average
+}
+
+// DefaultConfig suits a nightly scrape: a day and a half before STALE, and a drop
+// below half of the average of the previous week
+var DefaultConfig = Config{
+	MaxAge:         36 * time.Hour,
+	TrailingWindow: 7,
+	MinHistory:     3,
+	DropRatio:      0.5,
+}
+
+// Status is the scrape freshness of one hospital
+type Status struct {
+	HospitalID          uuid.UUID         `json:"hospital_id"`
+	LastBatchState      entity.BatchState `json:"last_batch_state,omitempty"`
+	LastAttemptAt       *time.Time        `json:"last_attempt_at,omitempty"`
+	LastSuccessID       *uuid.UUID        `json:"last_success_batch_id,omitempty"`
+	LastSuccessAt       *time.Time        `json:"last_success_at,omitempty"`
+	LastSuccessRows     int               `json:"last_success_rows"`
+	AgeSeconds          float64           `json:"age_seconds,omitempty"`
+	TrailingAverageRows float64           `json:"trailing_average_rows,omitempty"`
+	Anomalies           []Anomaly         `json:"anomalies"`
+}
+
+// Healthy reports whether no anomalies were found
+func (s *Status) Healthy() bool {
+	return len(s.Anomalies) == 0
+}
+
+// HasAnomaly reports whether the status contains an anomaly of the given kind
+func (s *Status) HasAnomaly(kind AnomalyKind) bool {
+	for _, a := range s.Anomalies {
+		if a.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate computes the freshness status from a hospital's batches. It is pure so it
+// can be tested without a database.
+func Evaluate(hospitalID uuid.UUID, batches []*entity.ScrapeBatch, now time.Time, config Config) *Status {
+	status := &Status{HospitalID: hospitalID, Anomalies: []Anomaly{}}
+
+	sorted := make([]*entity.ScrapeBatch, 0, len(batches))
+	for _, b := range batches {
+		if b.DeletedAt == nil {
+			sorted = append(sorted, b)
+		}
+	}
+	// Newest first
+	sort.Slice(sorted, func(i, j int) bool {
+		return batchTime(sorted[i]).After(batchTime(sorted[j]))
+	})
+
+	if len(sorted) > 0 {
+		latest := sorted[0]
+		at := batchTime(latest)
+		status.LastBatchState = latest.State
+		status.LastAttemptAt = &at
+		if latest.State == entity.BatchStateFailed {
+			msg := "most recent scrape failed"
+			if latest.ErrorMessage != nil {
+				msg += ": " + *latest.ErrorMessage
+			}
+			status.Anomalies = append(status.Anomalies, Anomaly{Kind: AnomalyLastAttemptFailed, Message: msg})
+		}
+	}
+
+	// A COMPLETE batch with no rows doesn't count as a success
+	var successes []*entity.ScrapeBatch
+	zeroRowsFlagged := false
+	for _, b := range sorted {
+		if b.State != entity.BatchStateComplete {
+			continue
+		}
+		if b.RowCount == 0 {
+			if len(successes) == 0 && !zeroRowsFlagged {
+				status.Anomalies = append(status.Anomalies, Anomaly{
+					Kind:    AnomalyZeroRows,
+					Message: fmt.Sprintf("batch %s is COMPLETE but imported no rows", b.ID),
+				})
+				zeroRowsFlagged = true
+			}
+			continue
+		}
+		successes = append(successes, b)
+	}
+
+	if len(successes) == 0 {
+		status.Anomalies = append(status.Anomalies, Anomaly{Kind: AnomalyNeverScraped, Message: "no successful scrape on record"})
+		return status
+	}
+
+	last := successes[0]
+	lastAt := batchTime(last)
+	age := now.Sub(lastAt)
+	status.LastSuccessID = &last.ID
+	status.LastSuccessAt = &lastAt
+	status.LastSuccessRows = last.RowCount
+	status.AgeSeconds = age.Seconds()
+
+	if age > config.MaxAge {
+		status.Anomalies = append(status.Anomalies, Anomaly{
+			Kind:    AnomalyStale,
+			Message: fmt.Sprintf("last successful scrape was %s ago (limit %s)", age.Round(time.Minute), config.MaxAge),
+		})
+	}
+
+	trailing := successes[1:]
+	if len(trailing) > config.TrailingWindow {
+		trailing = trailing[:config.TrailingWindow]
+	}
+	if len(trailing) > 0 {
+		total := 0
+		for _, b := range trailing {
+			total += b.RowCount
+		}
+		status.TrailingAverageRows = float64(total) / float64(len(trailing))
+	}
+	if len(trailing) >= config.MinHistory && float64(last.RowCount) < config.DropRatio*status.TrailingAverageRows {
+		status.Anomalies = append(status.Anomalies, Anomaly{
+			Kind:    AnomalyRowCountDrop,
+			Message: fmt.Sprintf("last scrape imported %d rows vs trailing average %.0f", last.RowCount, status.TrailingAverageRows),
+		})
+	}
+
+	return status
+}
+
+// batchTime is when a batch finished, falling back to when it was scraped
+func batchTime(b *entity.ScrapeBatch) time.Time {
+	if b.CompletedAt != nil {
+		return *b.CompletedAt
+	}
+	return b.ScrapedAt
+}
+
+// Checker evaluates scrape freshness for hospitals and records it as metrics
+type Checker struct {
+	hospitals repository.HospitalRepository
+	batches   repository.ScrapeBatchRepository
+	config    Config
+	metrics   *Metrics // Optional
+	now       func() time.Time
+}
+
+// NewChecker creates a freshness checker
+func NewChecker(hospitals repository.HospitalRepository, batches repository.ScrapeBatchRepository, config Config) *Checker {
+	return &Checker{
+		hospitals: hospitals,
+		batches:   batches,
+		config:    config,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetMetrics records every check in the given Prometheus metrics
+func (c *Checker) SetMetrics(metrics *Metrics) {
+	c.metrics = metrics
+}
+
+// CheckHospital evaluates one hospital
+func (c *Checker) CheckHospital(ctx context.Context, hospitalID uuid.UUID) (*Status, error) {
+	batches, err := c.batches.GetByHospital(ctx, hospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scrape batches: %w", err)
+	}
+
+	status := Evaluate(hospitalID, batches, c.now(), c.config)
+	if c.metrics != nil {
+		c.metrics.Observe(status)
+	}
+	return status, nil
+}
+
+// Check evaluates every hospital. A hospital that can't be checked is skipped and
+// reported in the returned error.
+func (c *Checker) Check(ctx context.Context) ([]*Status, error) {
+	hospitals, err := c.hospitals.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hospitals: %w", err)
+	}
+
+	statuses := make([]*Status, 0, len(hospitals))
+	var errs []error
+	for _, hospital := range hospitals {
+		status, err := c.CheckHospital(ctx, hospital.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hospital %s: %w", hospital.ID, err))
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, errors.Join(errs...)
+}
+
+// Run checks every interval until ctx is cancelled, keeping the metrics current
+// between calls to the health endpoint
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		statuses, err := c.Check(ctx)
+		if err != nil {
+			log.Printf("Scrape freshness check failed: %v", err)
+		}
+		for _, status := range statuses {
+			for _, a := range status.Anomalies {
+				log.Printf("Scrape freshness anomaly: hospital=%s kind=%s: %s", status.HospitalID, a.Kind, a.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
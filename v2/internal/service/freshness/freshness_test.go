@@ -0,0 +1,149 @@
+package freshness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC)
+
+func batch(state entity.BatchState, daysAgo int, rows int) *entity.ScrapeBatch {
+	completed := now.Add(-time.Duration(daysAgo)*24*time.Hour - 7*time.Hour) // Nightly at 02:00
+	return &entity.ScrapeBatch{
+		ID:          uuid.New(),
+		State:       state,
+		ScrapedAt:   completed.Add(-10 * time.Minute),
+		CompletedAt: &completed,
+		RowCount:    rows,
+	}
+}
+
+func kinds(s *Status) []AnomalyKind {
+	var result []AnomalyKind
+	for _, a := range s.Anomalies {
+		result = append(result, a.Kind)
+	}
+	return result
+}
+
+func TestEvaluate_Healthy(t *testing.T) {
+	batches := []*entity.ScrapeBatch{
+		batch(entity.BatchStateComplete, 3, 400),
+		batch(entity.BatchStateComplete, 0, 410),
+		batch(entity.BatchStateComplete, 2, 390),
+		batch(entity.BatchStateComplete, 1, 405),
+	}
+
+	status := Evaluate(uuid.New(), batches, now, DefaultConfig)
+	assert.True(t, status.Healthy(), kinds(status))
+	assert.Equal(t, 410, status.LastSuccessRows)
+	assert.Equal(t, entity.BatchStateComplete, status.LastBatchState)
+	assert.InDelta(t, 398.3, status.TrailingAverageRows, 0.1)
+	assert.InDelta(t, (7 * time.Hour).Seconds(), status.AgeSeconds, 1)
+}
+
+func TestEvaluate_NeverScraped(t *testing.T) {
+	status := Evaluate(uuid.New(), nil, now, DefaultConfig)
+	assert.Equal(t, []AnomalyKind{AnomalyNeverScraped}, kinds(status))
+	assert.Nil(t, status.LastSuccessAt)
+}
+
+func TestEvaluate_StaleAfterFailedScrapes(t *testing.T) {
+	failed := batch(entity.BatchStateFailed, 0, 0)
+	msg := "login rejected"
+	failed.ErrorMessage = &msg
+
+	batches := []*entity.ScrapeBatch{
+		batch(entity.BatchStateComplete, 2, 400),
+		batch(entity.BatchStateFailed, 1, 0),
+		failed,
+	}
+
+	status := Evaluate(uuid.New(), batches, now, DefaultConfig)
+	assert.ElementsMatch(t, []AnomalyKind{AnomalyLastAttemptFailed, AnomalyStale}, kinds(status))
+	assert.Equal(t, entity.BatchStateFailed, status.LastBatchState)
+	assert.Contains(t, status.Anomalies[0].Message, "login rejected")
+}
+
+func TestEvaluate_ZeroRowsComplete(t *testing.T) {
+	batches := []*entity.ScrapeBatch{
+		batch(entity.BatchStateComplete, 1, 400),
+		batch(entity.BatchStateComplete, 0, 0),
+	}
+
+	status := Evaluate(uuid.New(), batches, now, DefaultConfig)
+	assert.Equal(t, []AnomalyKind{AnomalyZeroRows}, kinds(status))
+	// The empty batch doesn't count as the last success
+	assert.Equal(t, 400, status.LastSuccessRows)
+}
+
+func TestEvaluate_RowCountDrop(t *testing.T) {
+	batches := []*entity.ScrapeBatch{
+		batch(entity.BatchStateComplete, 0, 120),
+		batch(entity.BatchStateComplete, 1, 400),
+		batch(entity.BatchStateComplete, 2, 410),
+		batch(entity.BatchStateComplete, 3, 390),
+	}
+
+	status := Evaluate(uuid.New(), batches, now, DefaultConfig)
+	assert.Equal(t, []AnomalyKind{AnomalyRowCountDrop}, kinds(status))
+
+	// Not enough history to judge
+	status = Evaluate(uuid.New(), batches[:3], now, DefaultConfig)
+	assert.True(t, status.Healthy(), kinds(status))
+}
+
+type mockHospitalRepo struct {
+	repository.HospitalRepository
+	hospitals []*entity.Hospital
+}
+
+func (m *mockHospitalRepo) GetAll(ctx context.Context) ([]*entity.Hospital, error) {
+	return m.hospitals, nil
+}
+
+type mockBatchRepo struct {
+	repository.ScrapeBatchRepository
+	byHospital map[uuid.UUID][]*entity.ScrapeBatch
+}
+
+func (m *mockBatchRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.ScrapeBatch, error) {
+	return m.byHospital[hospitalID], nil
+}
+
+func TestChecker_RecordsMetrics(t *testing.T) {
+	fresh := uuid.New()
+	stale := uuid.New()
+
+	checker := NewChecker(
+		&mockHospitalRepo{hospitals: []*entity.Hospital{{ID: fresh}, {ID: stale}}},
+		&mockBatchRepo{byHospital: map[uuid.UUID][]*entity.ScrapeBatch{
+			fresh: {batch(entity.BatchStateComplete, 0, 400)},
+			stale: {batch(entity.BatchStateComplete, 5, 380)},
+		}},
+		DefaultConfig,
+	)
+	checker.now = func() time.Time { return now }
+
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	checker.SetMetrics(metrics)
+
+	statuses, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, 400.0, testutil.ToFloat64(metrics.lastSuccessRows.WithLabelValues(fresh.String())))
+	assert.Equal(t, float64(statuses[0].LastSuccessAt.Unix()), testutil.ToFloat64(metrics.lastSuccess.WithLabelValues(fresh.String())))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.anomaly.WithLabelValues(fresh.String(), string(AnomalyStale))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.anomaly.WithLabelValues(stale.String(), string(AnomalyStale))))
+}
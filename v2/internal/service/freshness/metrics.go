@@ -0,0 +1,71 @@
+package freshness
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics exports scrape freshness as Prometheus gauges, labelled by hospital.
+// Alert on e.g. time() - schedcu_amion_scrape_last_success_timestamp_seconds > 129600.
+type Metrics struct {
+	lastSuccess     *prometheus.GaugeVec
+	lastSuccessRows *prometheus.GaugeVec
+	trailingRows    *prometheus.GaugeVec
+	anomaly         *prometheus.GaugeVec
+}
+
+// NewMetrics creates the freshness gauges and registers them. It panics if
+// registration fails, like prometheus.MustRegister.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		lastSuccess: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "schedcu_amion_scrape_last_success_timestamp_seconds",
+				Help: "Unix time of the last successful Amion scrape batch",
+			},
+			[]string{"hospital_id"},
+		),
+		lastSuccessRows: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "schedcu_amion_scrape_last_success_rows",
+				Help: "Rows imported by the last successful Amion scrape batch",
+			},
+			[]string{"hospital_id"},
+		),
+		trailingRows: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "schedcu_amion_scrape_trailing_average_rows",
+				Help: "Average rows of the successful batches before the last one",
+			},
+			[]string{"hospital_id"},
+		),
+		anomaly: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "schedcu_amion_scrape_anomaly",
+				Help: "1 if the scrape freshness anomaly of this kind is present, else 0",
+			},
+			[]string{"hospital_id", "kind"},
+		),
+	}
+
+	registerer.MustRegister(m.lastSuccess, m.lastSuccessRows, m.trailingRows, m.anomaly)
+	return m
+}
+
+// Observe records a freshness status
+func (m *Metrics) Observe(status *Status) {
+	hospital := status.HospitalID.String()
+
+	if status.LastSuccessAt != nil {
+		m.lastSuccess.WithLabelValues(hospital).Set(float64(status.LastSuccessAt.Unix()))
+		m.lastSuccessRows.WithLabelValues(hospital).Set(float64(status.LastSuccessRows))
+	}
+	m.trailingRows.WithLabelValues(hospital).Set(status.TrailingAverageRows)
+
+	for _, kind := range AnomalyKinds {
+		value := 0.0
+		if status.HasAnomaly(kind) {
+			value = 1
+		}
+		m.anomaly.WithLabelValues(hospital, string(kind)).Set(value)
+	}
+}
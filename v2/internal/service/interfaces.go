@@ -5,7 +5,9 @@ import (
 	"io"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
 	"github.com/schedcu/v2/internal/validation"
 )
 
@@ -44,6 +46,25 @@ type CoverageCalculator interface {
 	CalculateCoverage(ctx context.Context, scheduleVersionID entity.ScheduleVersionID, startDate, endDate time.Time) (*entity.CoverageCalculation, *validation.Result)
 }
 
+// WebhookService manages webhook subscriptions and delivers signed lifecycle events
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, hospitalID entity.HospitalID, url string, eventTypes []string, secret string, creatorID entity.UserID) (*entity.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, hospitalID entity.HospitalID) ([]*entity.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID, deleterID entity.UserID) error
+	// Dispatch records a delivery for every matching subscription and queues it
+	Dispatch(ctx context.Context, evt *event.Event) ([]*entity.WebhookDelivery, error)
+	// Deliver performs one delivery attempt; a non-nil error means the attempt should be retried
+	Deliver(ctx context.Context, deliveryID uuid.UUID, finalAttempt bool) error
+	Redeliver(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (*entity.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*entity.WebhookDelivery, error)
+}
+
+// WebhookQueue schedules webhook delivery attempts (implemented by job.JobScheduler)
+type WebhookQueue interface {
+	EnqueueWebhookDelivery(ctx context.Context, deliveryID uuid.UUID) error
+}
+
 // ScheduleOrchestrator coordinates the full scheduling workflow
 type ScheduleOrchestrator interface {
 	// ExecuteFullWorkflow executes the complete 3-phase workflow
@@ -0,0 +1,259 @@
+// Package merge folds a fresh Amion import into a STAGING version that has
+// been edited by hand. Both started from a common base version; each shift
+// slot (a shift type, study type and specialty on a date) is compared across
+// the three. Shifts only the import changed are copied into the STAGING
+// version, shifts only the edits changed are kept, and shifts both changed
+// differently become conflicts to be resolved one at a time.
+package merge
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service"
+)
+
+var (
+	// ErrNotStaging is returned when the version being merged into is not STAGING
+	ErrNotStaging = errors.New("imports can only be merged into a STAGING version")
+	// ErrSameVersion is returned when two of the three versions are the same
+	ErrSameVersion = errors.New("the base, staging and imported versions must all be different")
+	// ErrHospitalMismatch is returned when the versions belong to different hospitals
+	ErrHospitalMismatch = errors.New("the base, staging and imported versions must belong to the same hospital")
+	// ErrConflictResolved is returned when resolving a conflict a second time
+	ErrConflictResolved = errors.New("merge conflict has already been resolved")
+	// ErrInvalidResolution is returned for a resolution other than STAGING or IMPORTED
+	ErrInvalidResolution = errors.New("resolution must be STAGING or IMPORTED")
+)
+
+// ShiftChange is a shift the merge copied from the import into the STAGING version
+type ShiftChange struct {
+	ScheduleDate        time.Time
+	ShiftType           entity.ShiftType
+	StudyType           entity.StudyType
+	SpecialtyConstraint entity.SpecialtyType
+	ShiftAdded          bool // The STAGING version did not have the shift
+	ShiftRemoved        bool // The import dropped the shift
+	Added               []uuid.UUID
+	Removed             []uuid.UUID
+}
+
+// Result is a merge and the changes it applied
+type Result struct {
+	Merge   *entity.ScheduleMerge
+	Applied []ShiftChange
+}
+
+// Service merges imports into edited STAGING versions
+type Service struct {
+	versions    repository.ScheduleVersionRepository
+	shifts      repository.ShiftInstanceRepository
+	assignments repository.AssignmentRepository
+	merges      repository.ScheduleMergeRepository
+	coverage    service.CoverageQueue // Optional: recalculates coverage after a merge
+}
+
+// NewService creates a merge service
+func NewService(
+	versions repository.ScheduleVersionRepository,
+	shifts repository.ShiftInstanceRepository,
+	assignments repository.AssignmentRepository,
+	merges repository.ScheduleMergeRepository,
+) *Service {
+	return &Service{
+		versions:    versions,
+		shifts:      shifts,
+		assignments: assignments,
+		merges:      merges,
+	}
+}
+
+// SetCoverageQueue enables coverage recalculation after merges
+func (s *Service) SetCoverageQueue(queue service.CoverageQueue) {
+	s.coverage = queue
+}
+
+// Merge merges the imported version into the STAGING version. Only dates
+// both of them cover are merged. An import change that would drop a pinned
+// (MANUAL or OVERRIDE) assignment is a conflict even if the STAGING version
+// left that shift alone. The STAGING side is read with the version locked, so
+// edits made while the merge runs are never planned around.
+func (s *Service) Merge(ctx context.Context, stagingID, baseID, importedID, creatorID uuid.UUID) (*Result, error) {
+	if stagingID == baseID || stagingID == importedID || baseID == importedID {
+		return nil, ErrSameVersion
+	}
+	base, err := s.load(ctx, baseID)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := s.load(ctx, importedID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *Result
+	var p *plan
+	err = s.merges.Create(ctx, stagingID, creatorID, func(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.ScheduleMerge, *entity.ScheduleChanges, error) {
+		staging := newSide(version, shifts, assignments)
+		if version.Status != entity.VersionStatusStaging {
+			return nil, nil, ErrNotStaging
+		}
+		hospitalID := version.HospitalID
+		if base.version.HospitalID != hospitalID || imported.version.HospitalID != hospitalID {
+			return nil, nil, ErrHospitalMismatch
+		}
+
+		now := entity.Now()
+		merge := &entity.ScheduleMerge{
+			ID:                uuid.New(),
+			HospitalID:        hospitalID,
+			BaseVersionID:     baseID,
+			StagingVersionID:  stagingID,
+			ImportedVersionID: importedID,
+			Conflicts:         []entity.MergeConflict{},
+			CreatedAt:         now,
+			CreatedBy:         creatorID,
+		}
+		result = &Result{Merge: merge}
+		p = &plan{creatorID: creatorID, now: now}
+
+		from := entity.LaterOf(version.EffectiveStartDate, imported.version.EffectiveStartDate).Format("2006-01-02")
+		to := entity.EarlierOf(version.EffectiveEndDate, imported.version.EffectiveEndDate).Format("2006-01-02")
+		for _, key := range slotKeys(base, staging, imported) {
+			if key.Date < from || key.Date > to {
+				continue
+			}
+			was, ours, theirs := base.state(key), staging.state(key), imported.state(key)
+			switch {
+			case theirs.equal(was), ours.equal(theirs):
+				continue
+			case ours.equal(was) && !staging.dropsPinned(key, theirs):
+				result.Applied = append(result.Applied, p.take(key, staging, imported))
+			default:
+				merge.Conflicts = append(merge.Conflicts, entity.MergeConflict{
+					ID:                  uuid.New(),
+					MergeID:             merge.ID,
+					ScheduleDate:        slotDay(key),
+					ShiftType:           key.ShiftType,
+					StudyType:           key.StudyType,
+					SpecialtyConstraint: key.Specialty,
+					BasePersonIDs:       was.people,
+					StagingPersonIDs:    ours.people,
+					ImportedPersonIDs:   theirs.people,
+				})
+			}
+		}
+		merge.AutoApplied = len(result.Applied)
+		return merge, &p.changes, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	service.QueueCoverageRecalculation(ctx, s.coverage, stagingID, creatorID, p.dates...)
+	return result, nil
+}
+
+// GetMerge retrieves a merge with its conflicts
+func (s *Service) GetMerge(ctx context.Context, id uuid.UUID) (*entity.ScheduleMerge, error) {
+	return s.merges.GetByID(ctx, id)
+}
+
+// ListMerges retrieves the merges into a STAGING version, newest first
+func (s *Service) ListMerges(ctx context.Context, stagingVersionID uuid.UUID) ([]*entity.ScheduleMerge, error) {
+	return s.merges.GetByStagingVersion(ctx, stagingVersionID)
+}
+
+// ResolveConflict settles one conflict of a merge. STAGING keeps the edited
+// staffing as it is; IMPORTED replaces it with the imported version's current
+// staffing of that shift, pinned assignments included.
+func (s *Service) ResolveConflict(ctx context.Context, mergeID, conflictID uuid.UUID, resolution entity.MergeResolution, resolverID uuid.UUID) (*entity.MergeConflict, *ShiftChange, error) {
+	if resolution != entity.MergeResolutionStaging && resolution != entity.MergeResolutionImported {
+		return nil, nil, ErrInvalidResolution
+	}
+	merge, err := s.merges.GetByID(ctx, mergeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var conflict *entity.MergeConflict
+	for i := range merge.Conflicts {
+		if merge.Conflicts[i].ID == conflictID {
+			conflict = &merge.Conflicts[i]
+		}
+	}
+	if conflict == nil {
+		return nil, nil, &repository.NotFoundError{ResourceType: "MergeConflict", ResourceID: conflictID.String()}
+	}
+	if conflict.IsResolved() {
+		return nil, nil, ErrConflictResolved
+	}
+
+	var imported *side
+	if resolution == entity.MergeResolutionImported {
+		if imported, err = s.load(ctx, merge.ImportedVersionID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := entity.Now()
+	p := &plan{creatorID: resolverID, now: now}
+	var change *ShiftChange
+	err = s.merges.ResolveConflict(ctx, merge.StagingVersionID, resolverID, func(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.MergeConflict, *entity.ScheduleChanges, error) {
+		if version.Status != entity.VersionStatusStaging {
+			return nil, nil, ErrNotStaging
+		}
+		if imported != nil {
+			staging := newSide(version, shifts, assignments)
+			key := conflict.Slot()
+			if !staging.state(key).equal(imported.state(key)) {
+				applied := p.take(key, staging, imported)
+				change = &applied
+			}
+		}
+
+		conflict.Resolution = resolution
+		conflict.ResolvedAt = &now
+		conflict.ResolvedBy = &resolverID
+		return conflict, &p.changes, nil
+	})
+	if err != nil {
+		conflict.Resolution, conflict.ResolvedAt, conflict.ResolvedBy = "", nil, nil
+		return nil, nil, err
+	}
+
+	service.QueueCoverageRecalculation(ctx, s.coverage, merge.StagingVersionID, resolverID, p.dates...)
+	return conflict, change, nil
+}
+
+// slotKeys lists every shift slot in any of the versions, in date order
+func slotKeys(versions ...*side) []entity.ShiftSlot {
+	seen := make(map[entity.ShiftSlot]bool)
+	var keys []entity.ShiftSlot
+	for _, v := range versions {
+		for key := range v.shifts {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.ShiftType != b.ShiftType {
+			return a.ShiftType < b.ShiftType
+		}
+		if a.StudyType != b.StudyType {
+			return a.StudyType < b.StudyType
+		}
+		return a.Specialty < b.Specialty
+	})
+	return keys
+}
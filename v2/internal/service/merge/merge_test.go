@@ -0,0 +1,275 @@
+package merge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/tests/helpers"
+	"github.com/schedcu/v2/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockMergeRepo plans against the STAGING version in the mocks, records
+// merges and applies their schedule changes to the shift and assignment
+// mocks, as the postgres repository does in one locked transaction
+type mockMergeRepo struct {
+	repository.ScheduleMergeRepository
+	merges   map[uuid.UUID]*entity.ScheduleMerge
+	schedule *mocks.MockSchedule
+}
+
+func (m *mockMergeRepo) apply(ctx context.Context, changes *entity.ScheduleChanges, editorID uuid.UUID) error {
+	if err := m.schedule.Shifts.CreateBatch(ctx, changes.CreateShifts); err != nil {
+		return err
+	}
+	if err := m.schedule.Assignments.ApplyChanges(ctx, &changes.Assignments, editorID); err != nil {
+		return err
+	}
+	for _, id := range changes.DeleteShifts {
+		if err := m.schedule.Shifts.Delete(ctx, id, editorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staging reads the STAGING version's shifts and assignments for a plan
+func (m *mockMergeRepo) staging(ctx context.Context, versionID uuid.UUID) (*entity.ScheduleVersion, []*entity.ShiftInstance, []*entity.Assignment, error) {
+	version, err := m.schedule.Versions.GetByID(ctx, versionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	shifts, err := m.schedule.Shifts.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	assignments, err := m.schedule.Assignments.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return version, shifts, assignments, nil
+}
+
+func (m *mockMergeRepo) Create(ctx context.Context, stagingVersionID uuid.UUID, editorID uuid.UUID, plan repository.MergePlan) error {
+	version, shifts, assignments, err := m.staging(ctx, stagingVersionID)
+	if err != nil {
+		return err
+	}
+	merge, changes, err := plan(version, shifts, assignments)
+	if err != nil {
+		return err
+	}
+	if err := m.apply(ctx, changes, editorID); err != nil {
+		return err
+	}
+	m.merges[merge.ID] = merge
+	return nil
+}
+
+func (m *mockMergeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.ScheduleMerge, error) {
+	if merge, ok := m.merges[id]; ok {
+		return merge, nil
+	}
+	return nil, &repository.NotFoundError{ResourceType: "ScheduleMerge", ResourceID: id.String()}
+}
+
+func (m *mockMergeRepo) ResolveConflict(ctx context.Context, stagingVersionID uuid.UUID, editorID uuid.UUID, plan repository.ConflictResolutionPlan) error {
+	version, shifts, assignments, err := m.staging(ctx, stagingVersionID)
+	if err != nil {
+		return err
+	}
+	_, changes, err := plan(version, shifts, assignments)
+	if err != nil {
+		return err
+	}
+	return m.apply(ctx, changes, editorID)
+}
+
+type fixture struct {
+	service    *Service
+	hospitalID uuid.UUID
+	schedule   *mocks.MockSchedule
+	base       *entity.ScheduleVersion
+	staging    *entity.ScheduleVersion
+	imported   *entity.ScheduleVersion
+}
+
+func newFixture() *fixture {
+	f := &fixture{hospitalID: uuid.New(), schedule: mocks.NewMockSchedule()}
+	f.base = f.version(entity.VersionStatusProduction)
+	f.staging = f.version(entity.VersionStatusStaging)
+	f.imported = f.version(entity.VersionStatusStaging)
+	merges := &mockMergeRepo{merges: map[uuid.UUID]*entity.ScheduleMerge{}, schedule: f.schedule}
+	f.service = NewService(f.schedule.Versions, f.schedule.Shifts, f.schedule.Assignments, merges)
+	return f
+}
+
+func (f *fixture) version(status entity.VersionStatus) *entity.ScheduleVersion {
+	v := helpers.NewScheduleVersionBuilder().WithHospitalID(f.hospitalID).WithStatus(status).
+		WithEffectiveStartDate(helpers.Date("2025-11-01")).WithEffectiveEndDate(helpers.Date("2025-11-30")).Build()
+	f.schedule.Versions.Create(context.Background(), v)
+	return v
+}
+
+// staff puts a general shift of the given type on Nov 3 in the version, with
+// people on it from the given source
+func (f *fixture) staff(version *entity.ScheduleVersion, shiftType entity.ShiftType, source entity.AssignmentSource, people ...uuid.UUID) *entity.ShiftInstance {
+	return f.staffSlot(version, slot(shiftType, entity.StudyTypeGeneral, entity.SpecialtyBoth), source, people...)
+}
+
+// staffSlot puts a shift in the given slot in the version, with people on it
+// from the given source
+func (f *fixture) staffSlot(version *entity.ScheduleVersion, key entity.ShiftSlot, source entity.AssignmentSource, people ...uuid.UUID) *entity.ShiftInstance {
+	shift := helpers.NewShiftInstanceBuilder().WithScheduleVersionID(version.ID).WithHospitalID(f.hospitalID).
+		WithShiftType(key.ShiftType).WithStudyType(key.StudyType).WithSpecialtyConstraint(key.Specialty).
+		WithScheduleDate(helpers.Date(key.Date)).Build()
+	f.schedule.Shifts.Create(context.Background(), shift)
+	for _, personID := range people {
+		f.schedule.Assignments.Create(context.Background(), helpers.NewAssignmentBuilder().
+			WithPersonID(personID).WithShiftInstanceID(shift.ID).WithScheduleDate(shift.ScheduleDate).
+			WithOriginalShiftType(string(key.ShiftType)).WithSource(source).Build())
+	}
+	return shift
+}
+
+// slot names a shift slot on Nov 3
+func slot(shiftType entity.ShiftType, studyType entity.StudyType, specialty entity.SpecialtyType) entity.ShiftSlot {
+	return entity.ShiftSlot{Date: "2025-11-03", ShiftType: shiftType, StudyType: studyType, Specialty: specialty}
+}
+
+// people lists who is on the version's general shift of that type on Nov 3
+func (f *fixture) people(t *testing.T, version *entity.ScheduleVersion, shiftType entity.ShiftType) []uuid.UUID {
+	return f.slotPeople(t, version, slot(shiftType, entity.StudyTypeGeneral, entity.SpecialtyBoth))
+}
+
+// slotPeople lists who is on the version's shift in the slot
+func (f *fixture) slotPeople(t *testing.T, version *entity.ScheduleVersion, key entity.ShiftSlot) []uuid.UUID {
+	t.Helper()
+	sd, err := f.service.load(context.Background(), version.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sd.state(key).people
+}
+
+func TestMerge(t *testing.T) {
+	f := newFixture()
+	alice, bob, carol, dan, erin := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// Only the import changed the day shift: taken
+	f.staff(f.base, entity.ShiftTypeDay, entity.AssignmentSourceAmion, alice)
+	f.staff(f.staging, entity.ShiftTypeDay, entity.AssignmentSourceAmion, alice)
+	f.staff(f.imported, entity.ShiftTypeDay, entity.AssignmentSourceAmion, bob)
+	// Only the edits changed ON1: kept
+	f.staff(f.base, entity.ShiftTypeON1, entity.AssignmentSourceAmion, alice)
+	f.staff(f.staging, entity.ShiftTypeON1, entity.AssignmentSourceManual, carol)
+	f.staff(f.imported, entity.ShiftTypeON1, entity.AssignmentSourceAmion, alice)
+	// Both changed MidC differently: conflict
+	f.staff(f.base, entity.ShiftTypeMidC, entity.AssignmentSourceAmion, bob)
+	f.staff(f.staging, entity.ShiftTypeMidC, entity.AssignmentSourceManual, carol)
+	f.staff(f.imported, entity.ShiftTypeMidC, entity.AssignmentSourceAmion, dan)
+	// The import added MidL: copied
+	f.staff(f.imported, entity.ShiftTypeMidL, entity.AssignmentSourceAmion, erin)
+	// The import dropped a pinned override on ON2: conflict
+	f.staff(f.base, entity.ShiftTypeON2, entity.AssignmentSourceOverride, dan)
+	f.staff(f.staging, entity.ShiftTypeON2, entity.AssignmentSourceOverride, dan)
+	f.staff(f.imported, entity.ShiftTypeON2, entity.AssignmentSourceAmion)
+
+	result, err := f.service.Merge(context.Background(), f.staging.ID, f.base.ID, f.imported.ID, uuid.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Merge.AutoApplied)
+	assert.Equal(t, []uuid.UUID{bob}, f.people(t, f.staging, entity.ShiftTypeDay))
+	assert.Equal(t, []uuid.UUID{carol}, f.people(t, f.staging, entity.ShiftTypeON1))
+	assert.Equal(t, []uuid.UUID{carol}, f.people(t, f.staging, entity.ShiftTypeMidC))
+	assert.Equal(t, []uuid.UUID{erin}, f.people(t, f.staging, entity.ShiftTypeMidL))
+	assert.Equal(t, []uuid.UUID{dan}, f.people(t, f.staging, entity.ShiftTypeON2))
+
+	require.Len(t, result.Merge.Conflicts, 2)
+	midC := result.Merge.Conflicts[0]
+	assert.Equal(t, entity.ShiftTypeMidC, midC.ShiftType)
+	assert.Equal(t, []uuid.UUID{bob}, midC.BasePersonIDs)
+	assert.Equal(t, []uuid.UUID{carol}, midC.StagingPersonIDs)
+	assert.Equal(t, []uuid.UUID{dan}, midC.ImportedPersonIDs)
+	assert.Equal(t, entity.ShiftTypeON2, result.Merge.Conflicts[1].ShiftType)
+	assert.Equal(t, 2, result.Merge.OpenConflicts())
+
+	conflict, change, err := f.service.ResolveConflict(context.Background(), result.Merge.ID, midC.ID, entity.MergeResolutionImported, uuid.New())
+	require.NoError(t, err)
+	assert.True(t, conflict.IsResolved())
+	require.NotNil(t, change)
+	assert.Equal(t, []uuid.UUID{dan}, change.Added)
+	assert.Equal(t, []uuid.UUID{carol}, change.Removed)
+	assert.Equal(t, []uuid.UUID{dan}, f.people(t, f.staging, entity.ShiftTypeMidC))
+
+	_, _, err = f.service.ResolveConflict(context.Background(), result.Merge.ID, midC.ID, entity.MergeResolutionStaging, uuid.New())
+	assert.ErrorIs(t, err, ErrConflictResolved)
+
+	on2 := result.Merge.Conflicts[1]
+	_, change, err = f.service.ResolveConflict(context.Background(), result.Merge.ID, on2.ID, entity.MergeResolutionStaging, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, change)
+	assert.Equal(t, []uuid.UUID{dan}, f.people(t, f.staging, entity.ShiftTypeON2))
+	assert.Zero(t, result.Merge.OpenConflicts())
+}
+
+func TestMerge_ImportDropsShift(t *testing.T) {
+	f := newFixture()
+	alice := uuid.New()
+	f.staff(f.base, entity.ShiftTypeDay, entity.AssignmentSourceAmion, alice)
+	dropped := f.staff(f.staging, entity.ShiftTypeDay, entity.AssignmentSourceAmion, alice)
+
+	result, err := f.service.Merge(context.Background(), f.staging.ID, f.base.ID, f.imported.ID, uuid.New())
+	require.NoError(t, err)
+	require.Len(t, result.Applied, 1)
+	assert.True(t, result.Applied[0].ShiftRemoved)
+	assert.True(t, f.schedule.Shifts.IsDeleted(dropped.ID))
+	assert.Empty(t, f.people(t, f.staging, entity.ShiftTypeDay))
+}
+
+func TestMerge_KeepsStudyTypesApart(t *testing.T) {
+	f := newFixture()
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	body := slot(entity.ShiftTypeON1, entity.StudyTypeBodyImaging, entity.SpecialtyBodyOnly)
+	neuro := slot(entity.ShiftTypeON1, entity.StudyTypeNeuroImaging, entity.SpecialtyNeuroOnly)
+
+	// The import changed only the BODY ON1; the NEURO ON1 the same night is
+	// left alone rather than folded into it
+	f.staffSlot(f.base, body, entity.AssignmentSourceAmion, alice)
+	f.staffSlot(f.base, neuro, entity.AssignmentSourceAmion, bob)
+	f.staffSlot(f.staging, body, entity.AssignmentSourceAmion, alice)
+	f.staffSlot(f.staging, neuro, entity.AssignmentSourceAmion, bob)
+	f.staffSlot(f.imported, body, entity.AssignmentSourceAmion, carol)
+	f.staffSlot(f.imported, neuro, entity.AssignmentSourceAmion, bob)
+
+	result, err := f.service.Merge(context.Background(), f.staging.ID, f.base.ID, f.imported.ID, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, result.Merge.Conflicts)
+	require.Len(t, result.Applied, 1)
+	assert.Equal(t, entity.StudyTypeBodyImaging, result.Applied[0].StudyType)
+	assert.Equal(t, entity.SpecialtyBodyOnly, result.Applied[0].SpecialtyConstraint)
+	assert.Equal(t, []uuid.UUID{carol}, f.slotPeople(t, f.staging, body))
+	assert.Equal(t, []uuid.UUID{bob}, f.slotPeople(t, f.staging, neuro))
+}
+
+func TestMerge_Guards(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	_, err := f.service.Merge(ctx, f.staging.ID, f.base.ID, f.staging.ID, uuid.New())
+	assert.ErrorIs(t, err, ErrSameVersion)
+
+	_, err = f.service.Merge(ctx, f.base.ID, f.staging.ID, f.imported.ID, uuid.New())
+	assert.ErrorIs(t, err, ErrNotStaging)
+
+	f.imported.HospitalID = uuid.New()
+	_, err = f.service.Merge(ctx, f.staging.ID, f.base.ID, f.imported.ID, uuid.New())
+	assert.ErrorIs(t, err, ErrHospitalMismatch)
+
+	_, _, err = f.service.ResolveConflict(ctx, uuid.New(), uuid.New(), "BOTH", uuid.New())
+	assert.ErrorIs(t, err, ErrInvalidResolution)
+}
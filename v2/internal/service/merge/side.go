@@ -0,0 +1,182 @@
+package merge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// slotDay is the date of a shift slot
+func slotDay(key entity.ShiftSlot) time.Time {
+	day, _ := time.Parse("2006-01-02", key.Date)
+	return day
+}
+
+// side is one version's shifts and live assignments by slot
+type side struct {
+	version     *entity.ScheduleVersion
+	shifts      map[entity.ShiftSlot][]*entity.ShiftInstance
+	assignments map[entity.ShiftSlot][]*entity.Assignment
+}
+
+// load reads a version's shifts and live assignments
+func (s *Service) load(ctx context.Context, versionID uuid.UUID) (*side, error) {
+	version, err := s.versions.GetByID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.shifts.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shift instances: %w", err)
+	}
+	assignments, err := s.assignments.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assignments: %w", err)
+	}
+	return newSide(version, shifts, assignments), nil
+}
+
+// newSide groups a version's shifts and live assignments by slot
+func newSide(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) *side {
+	sd := &side{
+		version:     version,
+		shifts:      make(map[entity.ShiftSlot][]*entity.ShiftInstance),
+		assignments: make(map[entity.ShiftSlot][]*entity.Assignment),
+	}
+	keys := make(map[uuid.UUID]entity.ShiftSlot, len(shifts))
+	for _, shift := range shifts {
+		key := shift.Slot()
+		keys[shift.ID] = key
+		sd.shifts[key] = append(sd.shifts[key], shift)
+	}
+	for _, a := range assignments {
+		if key, ok := keys[a.ShiftInstanceID]; ok && !a.IsDeleted() {
+			sd.assignments[key] = append(sd.assignments[key], a)
+		}
+	}
+	return sd
+}
+
+// slotState is whether a version has a shift and who is on it
+type slotState struct {
+	exists bool
+	people []uuid.UUID // Sorted, without repeats
+}
+
+func (a slotState) equal(b slotState) bool {
+	if a.exists != b.exists || len(a.people) != len(b.people) {
+		return false
+	}
+	for i := range a.people {
+		if a.people[i] != b.people[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (a slotState) has(personID uuid.UUID) bool {
+	i := sort.Search(len(a.people), func(i int) bool { return bytes.Compare(a.people[i][:], personID[:]) >= 0 })
+	return i < len(a.people) && a.people[i] == personID
+}
+
+// state is the slot's staffing in this version
+func (sd *side) state(key entity.ShiftSlot) slotState {
+	st := slotState{exists: len(sd.shifts[key]) > 0, people: []uuid.UUID{}}
+	seen := make(map[uuid.UUID]bool)
+	for _, a := range sd.assignments[key] {
+		if !seen[a.PersonID] {
+			seen[a.PersonID] = true
+			st.people = append(st.people, a.PersonID)
+		}
+	}
+	sort.Slice(st.people, func(i, j int) bool { return bytes.Compare(st.people[i][:], st.people[j][:]) < 0 })
+	return st
+}
+
+// dropsPinned reports whether staffing the slot as target would take a
+// pinned assignment off it
+func (sd *side) dropsPinned(key entity.ShiftSlot, target slotState) bool {
+	for _, a := range sd.assignments[key] {
+		if a.IsPinned() && !target.has(a.PersonID) {
+			return true
+		}
+	}
+	return false
+}
+
+// plan collects the writes that make STAGING slots match the import
+type plan struct {
+	creatorID uuid.UUID
+	now       time.Time
+	changes   entity.ScheduleChanges
+	dates     []time.Time
+}
+
+// take plans making the slot in staging look like it does in imported.
+// Shifts and assignments copied from the import keep their source and
+// scrape batch, so rolling back that batch removes them again.
+func (p *plan) take(key entity.ShiftSlot, staging, imported *side) ShiftChange {
+	change := ShiftChange{
+		ScheduleDate:        slotDay(key),
+		ShiftType:           key.ShiftType,
+		StudyType:           key.StudyType,
+		SpecialtyConstraint: key.Specialty,
+		Added:               []uuid.UUID{},
+		Removed:             []uuid.UUID{},
+	}
+	target := imported.state(key)
+	p.dates = append(p.dates, change.ScheduleDate)
+
+	for _, a := range staging.assignments[key] {
+		if !target.exists || !target.has(a.PersonID) {
+			p.changes.Assignments.Delete = append(p.changes.Assignments.Delete, a.ID)
+			change.Removed = append(change.Removed, a.PersonID)
+		}
+	}
+	if !target.exists {
+		for _, shift := range staging.shifts[key] {
+			p.changes.DeleteShifts = append(p.changes.DeleteShifts, shift.ID)
+		}
+		change.ShiftRemoved = true
+		return change
+	}
+
+	var shiftID uuid.UUID
+	if existing := staging.shifts[key]; len(existing) > 0 {
+		shiftID = existing[0].ID
+	} else {
+		shift := *imported.shifts[key][0]
+		shift.ID = uuid.New()
+		shift.ScheduleVersionID = staging.version.ID
+		shift.CreatedAt = p.now
+		shift.CreatedBy = p.creatorID
+		p.changes.CreateShifts = append(p.changes.CreateShifts, &shift)
+		shiftID = shift.ID
+		change.ShiftAdded = true
+	}
+
+	current := staging.state(key)
+	added := make(map[uuid.UUID]bool)
+	for _, a := range imported.assignments[key] {
+		if current.has(a.PersonID) || added[a.PersonID] {
+			continue
+		}
+		added[a.PersonID] = true
+		copied := *a
+		copied.ID = uuid.New()
+		copied.ShiftInstanceID = shiftID
+		copied.CreatedAt = p.now
+		copied.CreatedBy = p.creatorID
+		copied.DeletedAt = nil
+		copied.DeletedBy = nil
+		p.changes.Assignments.Create = append(p.changes.Assignments.Create, &copied)
+		change.Added = append(change.Added, a.PersonID)
+	}
+	return change
+}
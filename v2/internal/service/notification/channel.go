@@ -0,0 +1,193 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// Message is a rendered notification
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Recipient is who a message goes to
+type Recipient struct {
+	Person     *entity.Person
+	Preference *entity.NotificationPreference
+}
+
+// Channel delivers messages over one medium
+type Channel interface {
+	Kind() entity.NotificationChannel
+	Send(ctx context.Context, to Recipient, msg Message) error
+}
+
+// ErrNoAddress is returned when a recipient has no address for a channel
+var ErrNoAddress = errors.New("recipient has no address for this channel")
+
+// smtpTimeout bounds one email when the caller's context has no deadline
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig holds outgoing mail settings
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Optional; PLAIN auth is used when set
+	Password string
+	From     string
+}
+
+// SMTPChannel sends notifications as plain-text email
+type SMTPChannel struct {
+	config SMTPConfig
+}
+
+// NewSMTPChannel creates an email channel
+func NewSMTPChannel(config SMTPConfig) *SMTPChannel {
+	return &SMTPChannel{config: config}
+}
+
+// Kind returns EMAIL
+func (c *SMTPChannel) Kind() entity.NotificationChannel {
+	return entity.NotificationChannelEmail
+}
+
+// Send emails the message to the person's address. The SMTP conversation
+// stops when ctx is cancelled or after smtpTimeout, whichever comes first.
+func (c *SMTPChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if to.Person == nil || to.Person.Email == "" {
+		return ErrNoAddress
+	}
+
+	addr := fmt.Sprintf("%s:%d", c.config.Host, c.config.Port)
+
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + c.config.From + "\r\n")
+	b.WriteString("To: " + to.Person.Email + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().UTC().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	if err := c.sendMail(ctx, addr, auth, to.Person.Email, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// sendMail does what smtp.SendMail does over a connection bound to ctx
+func (c *SMTPChannel) sendMail(ctx context.Context, addr string, auth smtp.Auth, rcpt string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// Unblock any read or write in progress as soon as ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.config.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(rcpt); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// SMSGateway sends text messages through a provider (Twilio, hospital paging gateway, ...)
+type SMSGateway interface {
+	SendSMS(ctx context.Context, phoneNumber, text string) error
+}
+
+// smsMaxLength keeps messages within a few SMS segments (in characters)
+const smsMaxLength = 480
+
+// SMSChannel sends notifications through an SMSGateway
+type SMSChannel struct {
+	gateway SMSGateway
+}
+
+// NewSMSChannel creates an SMS channel on top of a gateway
+func NewSMSChannel(gateway SMSGateway) *SMSChannel {
+	return &SMSChannel{gateway: gateway}
+}
+
+// Kind returns SMS
+func (c *SMSChannel) Kind() entity.NotificationChannel {
+	return entity.NotificationChannelSMS
+}
+
+// Send texts the subject and body to the phone number in the person's preferences
+func (c *SMSChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if to.Preference == nil || to.Preference.PhoneNumber == "" {
+		return ErrNoAddress
+	}
+
+	// Cut on characters, not bytes, so a multi-byte character is never split
+	text := msg.Subject + "\n" + msg.Body
+	if utf8.RuneCountInString(text) > smsMaxLength {
+		text = string([]rune(text)[:smsMaxLength-3]) + "..."
+	}
+
+	if err := c.gateway.SendSMS(ctx, to.Preference.PhoneNumber, text); err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+
+	return nil
+}
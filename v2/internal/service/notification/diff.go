@@ -0,0 +1,185 @@
+// Package notification tells people when their assignments change between
+// PRODUCTION schedule versions, via pluggable channels (email, SMS) with
+// per-person preferences and daily digests.
+package notification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+)
+
+// Slot is one assigned shift as a person sees it
+type Slot struct {
+	Date      time.Time
+	ShiftType entity.ShiftType
+	StudyType entity.StudyType
+	StartTime string
+	EndTime   string
+}
+
+// key identifies a slot independently of version-specific shift IDs
+func (s Slot) key() string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", s.Date.Format("2006-01-02"), s.ShiftType, s.StudyType, s.StartTime, s.EndTime)
+}
+
+// String renders the slot for messages, e.g. "Mon 2025-11-03 ON1 (BODY) 17:00-07:00"
+func (s Slot) String() string {
+	out := fmt.Sprintf("%s %s", s.Date.Format("Mon 2006-01-02"), s.ShiftType)
+	if s.StudyType != "" {
+		out += fmt.Sprintf(" (%s)", s.StudyType)
+	}
+	if s.StartTime != "" || s.EndTime != "" {
+		out += fmt.Sprintf(" %s-%s", s.StartTime, s.EndTime)
+	}
+	return out
+}
+
+// ChangeSet lists the slots added to and removed from one person's schedule
+type ChangeSet struct {
+	PersonID uuid.UUID
+	Added    []Slot
+	Removed  []Slot
+}
+
+// Empty reports whether nothing changed for the person
+func (c *ChangeSet) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0
+}
+
+// Render formats the change set as plain text
+func (c *ChangeSet) Render() string {
+	var b strings.Builder
+	if len(c.Added) > 0 {
+		b.WriteString("New shifts:\n")
+		for _, s := range c.Added {
+			b.WriteString("  + " + s.String() + "\n")
+		}
+	}
+	if len(c.Removed) > 0 {
+		b.WriteString("Removed shifts:\n")
+		for _, s := range c.Removed {
+			b.WriteString("  - " + s.String() + "\n")
+		}
+	}
+	return b.String()
+}
+
+// VersionSnapshot is the shift and assignment data of one schedule version
+type VersionSnapshot struct {
+	Shifts      []*entity.ShiftInstance
+	Assignments []*entity.Assignment
+	From, To    time.Time // Effective window; zero when unknown
+}
+
+// window returns the version's effective window, if known
+func (v VersionSnapshot) window() (from, to time.Time, ok bool) {
+	return v.From, v.To, !v.From.IsZero() && !v.To.IsZero()
+}
+
+// personSlots maps person → slot key → slot for a version
+func (v VersionSnapshot) personSlots() map[uuid.UUID]map[string]Slot {
+	shifts := make(map[uuid.UUID]*entity.ShiftInstance, len(v.Shifts))
+	for _, shift := range v.Shifts {
+		shifts[shift.ID] = shift
+	}
+
+	result := make(map[uuid.UUID]map[string]Slot)
+	for _, a := range v.Assignments {
+		if a.DeletedAt != nil {
+			continue
+		}
+		shift, ok := shifts[a.ShiftInstanceID]
+		if !ok {
+			continue
+		}
+		slot := Slot{
+			Date:      shift.ScheduleDate,
+			ShiftType: shift.ShiftType,
+			StudyType: shift.StudyType,
+			StartTime: shift.StartTime,
+			EndTime:   shift.EndTime,
+		}
+		if result[a.PersonID] == nil {
+			result[a.PersonID] = make(map[string]Slot)
+		}
+		result[a.PersonID][slot.key()] = slot
+	}
+	return result
+}
+
+// DiffVersions computes per-person change sets between the outgoing and incoming
+// PRODUCTION versions. Shifts are matched by date, type, study type and times since
+// each version has its own ShiftInstance IDs. When both windows are known only the
+// dates they share are compared, so a version covering a different period does not
+// read as every shift being added or removed. Only people with changes are returned.
+func DiffVersions(outgoing, incoming VersionSnapshot) []*ChangeSet {
+	oldSlots := outgoing.personSlots()
+	newSlots := incoming.personSlots()
+
+	oldFrom, oldTo, oldKnown := outgoing.window()
+	newFrom, newTo, newKnown := incoming.window()
+	if oldKnown && newKnown {
+		from, to, ok := entity.Overlap(oldFrom, oldTo, newFrom, newTo)
+		if !ok {
+			return nil
+		}
+		keepWithin(oldSlots, from, to)
+		keepWithin(newSlots, from, to)
+	}
+
+	people := make(map[uuid.UUID]struct{})
+	for id := range oldSlots {
+		people[id] = struct{}{}
+	}
+	for id := range newSlots {
+		people[id] = struct{}{}
+	}
+
+	var changes []*ChangeSet
+	for personID := range people {
+		cs := &ChangeSet{PersonID: personID}
+		for k, slot := range newSlots[personID] {
+			if _, ok := oldSlots[personID][k]; !ok {
+				cs.Added = append(cs.Added, slot)
+			}
+		}
+		for k, slot := range oldSlots[personID] {
+			if _, ok := newSlots[personID][k]; !ok {
+				cs.Removed = append(cs.Removed, slot)
+			}
+		}
+		if cs.Empty() {
+			continue
+		}
+		sortSlots(cs.Added)
+		sortSlots(cs.Removed)
+		changes = append(changes, cs)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].PersonID.String() < changes[j].PersonID.String()
+	})
+	return changes
+}
+
+// keepWithin drops the slots dated outside [from, to]
+func keepWithin(slots map[uuid.UUID]map[string]Slot, from, to time.Time) {
+	for _, byKey := range slots {
+		for k, slot := range byKey {
+			if date := entity.DateOf(slot.Date); date.Before(from) || date.After(to) {
+				delete(byKey, k)
+			}
+		}
+	}
+}
+
+func sortSlots(slots []Slot) {
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].key() < slots[j].key()
+	})
+}
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshotBuilder builds a VersionSnapshot; every shift gets a fresh ID, as in a real version
+type snapshotBuilder struct {
+	snap VersionSnapshot
+}
+
+func (b *snapshotBuilder) assign(personID uuid.UUID, date time.Time, shiftType entity.ShiftType) *snapshotBuilder {
+	shift := &entity.ShiftInstance{
+		ID:           uuid.New(),
+		ShiftType:    shiftType,
+		ScheduleDate: date,
+		StudyType:    entity.StudyTypeBodyImaging,
+		StartTime:    "17:00",
+		EndTime:      "07:00",
+	}
+	b.snap.Shifts = append(b.snap.Shifts, shift)
+	b.snap.Assignments = append(b.snap.Assignments, &entity.Assignment{
+		ID:              uuid.New(),
+		PersonID:        personID,
+		ShiftInstanceID: shift.ID,
+		ScheduleDate:    date,
+	})
+	return b
+}
+
+func TestDiffVersions(t *testing.T) {
+	alice := uuid.New()
+	bob := uuid.New()
+	carol := uuid.New()
+	mon := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	tue := mon.AddDate(0, 0, 1)
+
+	outgoing := (&snapshotBuilder{}).
+		assign(alice, mon, entity.ShiftTypeON1).
+		assign(bob, tue, entity.ShiftTypeON1).
+		assign(carol, mon, entity.ShiftTypeON2).snap
+	incoming := (&snapshotBuilder{}).
+		assign(alice, mon, entity.ShiftTypeON1). // unchanged
+		assign(carol, tue, entity.ShiftTypeON1). // bob's shift moved to carol
+		assign(carol, mon, entity.ShiftTypeON2).snap
+
+	changes := DiffVersions(outgoing, incoming)
+	require.Len(t, changes, 2)
+
+	byPerson := map[uuid.UUID]*ChangeSet{}
+	for _, cs := range changes {
+		byPerson[cs.PersonID] = cs
+	}
+
+	assert.NotContains(t, byPerson, alice)
+
+	require.Contains(t, byPerson, bob)
+	assert.Empty(t, byPerson[bob].Added)
+	require.Len(t, byPerson[bob].Removed, 1)
+	assert.Equal(t, tue, byPerson[bob].Removed[0].Date)
+
+	require.Contains(t, byPerson, carol)
+	assert.Empty(t, byPerson[carol].Removed)
+	require.Len(t, byPerson[carol].Added, 1)
+	assert.Equal(t, entity.ShiftTypeON1, byPerson[carol].Added[0].ShiftType)
+}
+
+func TestDiffVersions_IgnoresDeletedAssignments(t *testing.T) {
+	person := uuid.New()
+	day := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+
+	outgoing := (&snapshotBuilder{}).assign(person, day, entity.ShiftTypeON1).snap
+	incoming := (&snapshotBuilder{}).assign(person, day, entity.ShiftTypeON1).snap
+	now := time.Now()
+	incoming.Assignments[0].DeletedAt = &now
+
+	changes := DiffVersions(outgoing, incoming)
+	require.Len(t, changes, 1)
+	assert.Len(t, changes[0].Removed, 1)
+}
+
+func TestDiffVersions_ComparesSharedDatesOnly(t *testing.T) {
+	person := uuid.New()
+	oct31 := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
+	nov3 := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	dec1 := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	outgoing := (&snapshotBuilder{}).assign(person, oct31, entity.ShiftTypeON1).assign(person, nov3, entity.ShiftTypeON1).snap
+	outgoing.From, outgoing.To = time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)
+	incoming := (&snapshotBuilder{}).assign(person, nov3, entity.ShiftTypeON2).assign(person, dec1, entity.ShiftTypeON1).snap
+	incoming.From, incoming.To = time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	changes := DiffVersions(outgoing, incoming)
+	require.Len(t, changes, 1)
+	require.Len(t, changes[0].Added, 1)
+	require.Len(t, changes[0].Removed, 1)
+	assert.Equal(t, nov3, changes[0].Added[0].Date, "December is outside the outgoing window")
+	assert.Equal(t, nov3, changes[0].Removed[0].Date, "October is outside the incoming window")
+
+	incoming.From, incoming.To = dec1, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	assert.Empty(t, DiffVersions(outgoing, incoming), "disjoint versions share no dates")
+}
+
+func TestChangeSet_Render(t *testing.T) {
+	day := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	cs := &ChangeSet{
+		Added:   []Slot{{Date: day, ShiftType: entity.ShiftTypeON1, StudyType: entity.StudyTypeBodyImaging, StartTime: "17:00", EndTime: "07:00"}},
+		Removed: []Slot{{Date: day.AddDate(0, 0, 1), ShiftType: entity.ShiftTypeDay}},
+	}
+
+	out := cs.Render()
+	assert.Contains(t, out, "+ Mon 2025-11-03 ON1 (BODY) 17:00-07:00")
+	assert.Contains(t, out, "- Tue 2025-11-04 DAY")
+}
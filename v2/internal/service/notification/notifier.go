@@ -0,0 +1,317 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// digestMaxAge forces a digest out if the configured hour was missed (e.g. worker down)
+const digestMaxAge = 24 * time.Hour
+
+// Result summarises one notification run
+type Result struct {
+	PeopleChanged int
+	Sent          int
+	Queued        int // Held for a daily digest
+	Skipped       int // Notifications disabled
+	Failed        int
+	Repeated      int // Already notified by an earlier attempt
+}
+
+// Notifier computes per-person change sets on promotion and delivers them
+type Notifier struct {
+	shifts      repository.ShiftInstanceRepository
+	assignments repository.AssignmentRepository
+	persons     repository.PersonRepository
+	preferences repository.NotificationPreferenceRepository
+	pending     repository.PendingNotificationRepository
+	deliveries  repository.NotificationDeliveryRepository
+	versions    repository.ScheduleVersionRepository // Optional: limits diffs to the dates both versions cover
+	channels    map[entity.NotificationChannel]Channel
+	now         func() time.Time
+}
+
+// NewNotifier creates a notifier; register channels with RegisterChannel
+func NewNotifier(
+	shifts repository.ShiftInstanceRepository,
+	assignments repository.AssignmentRepository,
+	persons repository.PersonRepository,
+	preferences repository.NotificationPreferenceRepository,
+	pending repository.PendingNotificationRepository,
+	deliveries repository.NotificationDeliveryRepository,
+) *Notifier {
+	return &Notifier{
+		shifts:      shifts,
+		assignments: assignments,
+		persons:     persons,
+		preferences: preferences,
+		pending:     pending,
+		deliveries:  deliveries,
+		channels:    make(map[entity.NotificationChannel]Channel),
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetVersionRepository lets the notifier compare only the dates the outgoing
+// and incoming versions share
+func (n *Notifier) SetVersionRepository(repo repository.ScheduleVersionRepository) {
+	n.versions = repo
+}
+
+// RegisterChannel makes a delivery channel available
+func (n *Notifier) RegisterChannel(ch Channel) {
+	n.channels[ch.Kind()] = ch
+}
+
+// NotifyVersionChange tells everyone whose assignments differ between the outgoing
+// and incoming PRODUCTION versions. Nothing is sent for a hospital's first promotion
+// (outgoingID == uuid.Nil), since every assignment would count as new.
+// Each person handled is recorded, so when some deliveries fail the returned
+// error lets the job retry without contacting the others again.
+func (n *Notifier) NotifyVersionChange(ctx context.Context, hospitalID, outgoingID, incomingID uuid.UUID) (*Result, error) {
+	result := &Result{}
+	if outgoingID == uuid.Nil {
+		return result, nil
+	}
+
+	outgoing, err := n.loadSnapshot(ctx, outgoingID)
+	if err != nil {
+		return nil, err
+	}
+	incoming, err := n.loadSnapshot(ctx, incomingID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := DiffVersions(outgoing, incoming)
+	result.PeopleChanged = len(changes)
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uuid.UUID, len(changes))
+	for i, cs := range changes {
+		ids[i] = cs.PersonID
+	}
+	people, err := n.persons.GetAllByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load people: %w", err)
+	}
+	byID := make(map[uuid.UUID]*entity.Person, len(people))
+	for _, p := range people {
+		byID[p.ID] = p
+	}
+
+	delivered, err := n.deliveries.GetByVersionChange(ctx, outgoingID, incomingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification deliveries: %w", err)
+	}
+	handled := make(map[uuid.UUID]bool, len(delivered))
+	for _, d := range delivered {
+		handled[d.PersonID] = true
+	}
+
+	for _, cs := range changes {
+		if handled[cs.PersonID] {
+			result.Repeated++
+			continue
+		}
+		person, ok := byID[cs.PersonID]
+		if !ok || !person.Active {
+			result.Skipped++
+			continue
+		}
+
+		pref, err := n.preferenceFor(ctx, person.ID)
+		if err != nil {
+			log.Printf("Failed to notify person %s: %v", person.ID, err)
+			result.Failed++
+			continue
+		}
+		if !pref.Enabled {
+			result.Skipped++
+			continue
+		}
+
+		body := cs.Render()
+
+		if pref.DigestMode == entity.DigestModeDaily {
+			err := n.pending.Create(ctx, &entity.PendingNotification{
+				ID:           uuid.New(),
+				PersonID:     person.ID,
+				HospitalID:   hospitalID,
+				OldVersionID: outgoingID,
+				NewVersionID: incomingID,
+				Body:         body,
+				CreatedAt:    n.now(),
+			})
+			if err != nil {
+				log.Printf("Failed to queue digest notification for person %s: %v", person.ID, err)
+				result.Failed++
+				continue
+			}
+			n.recordDelivery(ctx, outgoingID, incomingID, person.ID, entity.NotificationDeliveryQueued)
+			result.Queued++
+			continue
+		}
+
+		msg := Message{
+			Subject: "Your schedule has changed",
+			Body:    greeting(person) + "A new schedule has been published. Your assignments changed:\n\n" + body,
+		}
+		if n.send(ctx, Recipient{Person: person, Preference: pref}, msg) {
+			n.recordDelivery(ctx, outgoingID, incomingID, person.ID, entity.NotificationDeliverySent)
+			result.Sent++
+		} else {
+			result.Failed++
+		}
+	}
+
+	if result.Failed > 0 {
+		return result, fmt.Errorf("failed to notify %d of %d people", result.Failed, result.PeopleChanged)
+	}
+	return result, nil
+}
+
+// recordDelivery marks a person as handled for a promotion. A failure is only
+// logged: the person has been contacted, and a retry would at worst repeat it.
+func (n *Notifier) recordDelivery(ctx context.Context, outgoingID, incomingID, personID uuid.UUID, status entity.NotificationDeliveryStatus) {
+	err := n.deliveries.Create(ctx, &entity.NotificationDelivery{
+		OldVersionID: outgoingID,
+		NewVersionID: incomingID,
+		PersonID:     personID,
+		Status:       status,
+		CreatedAt:    n.now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record notification delivery for person %s: %v", personID, err)
+	}
+}
+
+// FlushDigests sends one combined message per person whose digest hour is now,
+// or whose oldest queued change is more than a day old
+func (n *Notifier) FlushDigests(ctx context.Context) (*Result, error) {
+	result := &Result{}
+	now := n.now()
+
+	unsent, err := n.pending.GetUnsent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending notifications: %w", err)
+	}
+
+	byPerson := make(map[uuid.UUID][]*entity.PendingNotification)
+	var order []uuid.UUID
+	for _, p := range unsent {
+		if _, ok := byPerson[p.PersonID]; !ok {
+			order = append(order, p.PersonID)
+		}
+		byPerson[p.PersonID] = append(byPerson[p.PersonID], p)
+	}
+
+	for _, personID := range order {
+		items := byPerson[personID]
+
+		pref, err := n.preferenceFor(ctx, personID)
+		if err != nil {
+			return nil, err
+		}
+		due := now.Hour() == pref.DigestHour || now.Sub(items[0].CreatedAt) >= digestMaxAge
+		if !due {
+			continue
+		}
+
+		person, err := n.persons.GetByID(ctx, personID)
+		if err != nil || person == nil {
+			result.Skipped++
+			continue
+		}
+
+		ids := make([]uuid.UUID, len(items))
+		var body strings.Builder
+		body.WriteString(greeting(person))
+		body.WriteString("Your schedule changed since your last digest:\n")
+		for i, item := range items {
+			ids[i] = item.ID
+			body.WriteString(fmt.Sprintf("\nPublished %s:\n", item.CreatedAt.Format("Mon 2006-01-02 15:04 MST")))
+			body.WriteString(item.Body)
+		}
+
+		msg := Message{
+			Subject: fmt.Sprintf("Daily schedule digest: %d update(s)", len(items)),
+			Body:    body.String(),
+		}
+		if !pref.Enabled || n.send(ctx, Recipient{Person: person, Preference: pref}, msg) {
+			if err := n.pending.MarkSent(ctx, ids, now); err != nil {
+				return nil, fmt.Errorf("failed to mark digest sent: %w", err)
+			}
+			result.Sent++
+		} else {
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// send delivers through every channel the person chose; true if at least one succeeded
+func (n *Notifier) send(ctx context.Context, to Recipient, msg Message) bool {
+	delivered := false
+	for _, kind := range to.Preference.Channels {
+		ch, ok := n.channels[kind]
+		if !ok {
+			continue
+		}
+		if err := ch.Send(ctx, to, msg); err != nil {
+			log.Printf("Failed to notify person %s via %s: %v", to.Person.ID, kind, err)
+			continue
+		}
+		delivered = true
+	}
+	return delivered
+}
+
+// preferenceFor returns stored preferences or the defaults
+func (n *Notifier) preferenceFor(ctx context.Context, personID uuid.UUID) (*entity.NotificationPreference, error) {
+	pref, err := n.preferences.GetByPerson(ctx, personID)
+	if repository.IsNotFound(err) || (err == nil && pref == nil) {
+		return entity.DefaultNotificationPreference(personID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification preference: %w", err)
+	}
+	return pref, nil
+}
+
+func (n *Notifier) loadSnapshot(ctx context.Context, versionID uuid.UUID) (VersionSnapshot, error) {
+	shifts, err := n.shifts.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return VersionSnapshot{}, fmt.Errorf("failed to load shifts for version %s: %w", versionID, err)
+	}
+	assignments, err := n.assignments.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return VersionSnapshot{}, fmt.Errorf("failed to load assignments for version %s: %w", versionID, err)
+	}
+	snapshot := VersionSnapshot{Shifts: shifts, Assignments: assignments}
+	if n.versions != nil {
+		version, err := n.versions.GetByID(ctx, versionID)
+		if err != nil {
+			return VersionSnapshot{}, fmt.Errorf("failed to load version %s: %w", versionID, err)
+		}
+		snapshot.From, snapshot.To = version.EffectiveStartDate, version.EffectiveEndDate
+	}
+	return snapshot, nil
+}
+
+func greeting(person *entity.Person) string {
+	if person.Name == "" {
+		return "Hello,\n\n"
+	}
+	return "Hello " + person.Name + ",\n\n"
+}
@@ -0,0 +1,460 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The mocks embed the repository interfaces and implement only what the notifier calls
+
+type mockShiftRepo struct {
+	repository.ShiftInstanceRepository
+	byVersion map[uuid.UUID][]*entity.ShiftInstance
+}
+
+func (m *mockShiftRepo) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.ShiftInstance, error) {
+	return m.byVersion[versionID], nil
+}
+
+type mockAssignmentRepo struct {
+	repository.AssignmentRepository
+	byVersion map[uuid.UUID][]*entity.Assignment
+}
+
+func (m *mockAssignmentRepo) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.Assignment, error) {
+	return m.byVersion[versionID], nil
+}
+
+type mockPersonRepo struct {
+	repository.PersonRepository
+	people map[uuid.UUID]*entity.Person
+}
+
+func (m *mockPersonRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Person, error) {
+	if p, ok := m.people[id]; ok {
+		return p, nil
+	}
+	return nil, &repository.NotFoundError{ResourceType: "Person", ResourceID: id.String()}
+}
+
+func (m *mockPersonRepo) GetAllByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Person, error) {
+	var result []*entity.Person
+	for _, id := range ids {
+		if p, ok := m.people[id]; ok {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+type mockPreferenceRepo struct {
+	prefs map[uuid.UUID]*entity.NotificationPreference
+}
+
+func (m *mockPreferenceRepo) GetByPerson(ctx context.Context, personID uuid.UUID) (*entity.NotificationPreference, error) {
+	if p, ok := m.prefs[personID]; ok {
+		return p, nil
+	}
+	return nil, &repository.NotFoundError{ResourceType: "NotificationPreference", ResourceID: personID.String()}
+}
+
+func (m *mockPreferenceRepo) Upsert(ctx context.Context, pref *entity.NotificationPreference) error {
+	m.prefs[pref.PersonID] = pref
+	return nil
+}
+
+type mockPendingRepo struct {
+	items []*entity.PendingNotification
+}
+
+func (m *mockPendingRepo) Create(ctx context.Context, n *entity.PendingNotification) error {
+	m.items = append(m.items, n)
+	return nil
+}
+
+func (m *mockPendingRepo) GetUnsent(ctx context.Context) ([]*entity.PendingNotification, error) {
+	var result []*entity.PendingNotification
+	for _, n := range m.items {
+		if n.SentAt == nil {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPendingRepo) MarkSent(ctx context.Context, ids []uuid.UUID, sentAt time.Time) error {
+	for _, n := range m.items {
+		for _, id := range ids {
+			if n.ID == id {
+				t := sentAt
+				n.SentAt = &t
+			}
+		}
+	}
+	return nil
+}
+
+type mockDeliveryRepo struct {
+	items []*entity.NotificationDelivery
+}
+
+func (m *mockDeliveryRepo) GetByVersionChange(ctx context.Context, oldVersionID, newVersionID uuid.UUID) ([]*entity.NotificationDelivery, error) {
+	var result []*entity.NotificationDelivery
+	for _, d := range m.items {
+		if d.OldVersionID == oldVersionID && d.NewVersionID == newVersionID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDeliveryRepo) Create(ctx context.Context, d *entity.NotificationDelivery) error {
+	m.items = append(m.items, d)
+	return nil
+}
+
+// smtpSink is a minimal local SMTP server that records received mail
+type smtpSink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	mail     []sinkMail
+}
+
+type sinkMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &smtpSink{listener: l}
+	t.Cleanup(func() { l.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpSink) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return SMTPConfig{Host: host, Port: p, From: "schedcu@example.org"}
+}
+
+func (s *smtpSink) messages() []sinkMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sinkMail(nil), s.mail...)
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpSink) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 sink ready")
+	var current sinkMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 sink")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			current = sinkMail{From: strings.Trim(cmd[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			current.To = append(current.To, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.mail = append(s.mail, current)
+			s.mu.Unlock()
+			reply("250 queued")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// fakeSMSGateway records texts instead of sending them
+type fakeSMSGateway struct {
+	sent map[string]string
+	err  error // Returned instead of sending when set
+}
+
+func (g *fakeSMSGateway) SendSMS(ctx context.Context, phoneNumber, text string) error {
+	if g.err != nil {
+		return g.err
+	}
+	g.sent[phoneNumber] = text
+	return nil
+}
+
+type notifierFixture struct {
+	notifier   *Notifier
+	prefs      *mockPreferenceRepo
+	pending    *mockPendingRepo
+	sink       *smtpSink
+	sms        *fakeSMSGateway
+	hospitalID uuid.UUID
+	oldVersion uuid.UUID
+	newVersion uuid.UUID
+	moved      *entity.Person // Loses a shift
+	gained     *entity.Person // Picks it up
+	unchanged  *entity.Person
+}
+
+func newNotifierFixture(t *testing.T) *notifierFixture {
+	f := &notifierFixture{
+		prefs:      &mockPreferenceRepo{prefs: map[uuid.UUID]*entity.NotificationPreference{}},
+		pending:    &mockPendingRepo{},
+		sink:       newSMTPSink(t),
+		sms:        &fakeSMSGateway{sent: map[string]string{}},
+		hospitalID: uuid.New(),
+		oldVersion: uuid.New(),
+		newVersion: uuid.New(),
+		moved:      &entity.Person{ID: uuid.New(), Email: "moved@example.org", Name: "Dr. Moved", Active: true},
+		gained:     &entity.Person{ID: uuid.New(), Email: "gained@example.org", Name: "Dr. Gained", Active: true},
+		unchanged:  &entity.Person{ID: uuid.New(), Email: "same@example.org", Name: "Dr. Same", Active: true},
+	}
+
+	mon := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	outgoing := (&snapshotBuilder{}).
+		assign(f.moved.ID, mon, entity.ShiftTypeON1).
+		assign(f.unchanged.ID, mon, entity.ShiftTypeON2).snap
+	incoming := (&snapshotBuilder{}).
+		assign(f.gained.ID, mon, entity.ShiftTypeON1).
+		assign(f.unchanged.ID, mon, entity.ShiftTypeON2).snap
+
+	f.notifier = NewNotifier(
+		&mockShiftRepo{byVersion: map[uuid.UUID][]*entity.ShiftInstance{f.oldVersion: outgoing.Shifts, f.newVersion: incoming.Shifts}},
+		&mockAssignmentRepo{byVersion: map[uuid.UUID][]*entity.Assignment{f.oldVersion: outgoing.Assignments, f.newVersion: incoming.Assignments}},
+		&mockPersonRepo{people: map[uuid.UUID]*entity.Person{f.moved.ID: f.moved, f.gained.ID: f.gained, f.unchanged.ID: f.unchanged}},
+		f.prefs,
+		f.pending,
+		&mockDeliveryRepo{},
+	)
+	f.notifier.RegisterChannel(NewSMTPChannel(f.sink.config()))
+	f.notifier.RegisterChannel(NewSMSChannel(f.sms))
+	return f
+}
+
+func TestNotifyVersionChange_EmailsChangedPeople(t *testing.T) {
+	f := newNotifierFixture(t)
+
+	result, err := f.notifier.NotifyVersionChange(context.Background(), f.hospitalID, f.oldVersion, f.newVersion)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.PeopleChanged)
+	assert.Equal(t, 2, result.Sent)
+
+	mail := f.sink.messages()
+	require.Len(t, mail, 2)
+
+	byRecipient := map[string]sinkMail{}
+	for _, m := range mail {
+		require.Len(t, m.To, 1)
+		assert.Equal(t, "schedcu@example.org", m.From)
+		byRecipient[m.To[0]] = m
+	}
+	assert.NotContains(t, byRecipient, f.unchanged.Email)
+	assert.Contains(t, byRecipient[f.moved.Email].Data, "Subject: Your schedule has changed")
+	assert.Contains(t, byRecipient[f.moved.Email].Data, "- Mon 2025-11-03 ON1")
+	assert.Contains(t, byRecipient[f.gained.Email].Data, "+ Mon 2025-11-03 ON1")
+}
+
+func TestNotifyVersionChange_FirstPromotionSendsNothing(t *testing.T) {
+	f := newNotifierFixture(t)
+
+	result, err := f.notifier.NotifyVersionChange(context.Background(), f.hospitalID, uuid.Nil, f.newVersion)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.PeopleChanged)
+	assert.Empty(t, f.sink.messages())
+}
+
+func TestNotifyVersionChange_RespectsPreferences(t *testing.T) {
+	f := newNotifierFixture(t)
+	f.prefs.prefs[f.moved.ID] = &entity.NotificationPreference{PersonID: f.moved.ID, Enabled: false}
+	f.prefs.prefs[f.gained.ID] = &entity.NotificationPreference{
+		PersonID:    f.gained.ID,
+		Enabled:     true,
+		Channels:    []entity.NotificationChannel{entity.NotificationChannelSMS},
+		PhoneNumber: "+15550100",
+		DigestMode:  entity.DigestModeImmediate,
+	}
+
+	result, err := f.notifier.NotifyVersionChange(context.Background(), f.hospitalID, f.oldVersion, f.newVersion)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Sent)
+
+	assert.Empty(t, f.sink.messages())
+	assert.Contains(t, f.sms.sent["+15550100"], "+ Mon 2025-11-03 ON1")
+}
+
+func TestNotifyVersionChange_RetryOnlyContactsFailedPeople(t *testing.T) {
+	f := newNotifierFixture(t)
+	f.prefs.prefs[f.gained.ID] = &entity.NotificationPreference{
+		PersonID:    f.gained.ID,
+		Enabled:     true,
+		Channels:    []entity.NotificationChannel{entity.NotificationChannelSMS},
+		PhoneNumber: "+15550100",
+		DigestMode:  entity.DigestModeImmediate,
+	}
+	f.sms.err = errors.New("gateway unavailable")
+
+	result, err := f.notifier.NotifyVersionChange(context.Background(), f.hospitalID, f.oldVersion, f.newVersion)
+	require.Error(t, err, "a failed delivery makes the job retry")
+	assert.Equal(t, 1, result.Sent, "the rest are still notified")
+	assert.Equal(t, 1, result.Failed)
+	assert.Len(t, f.sink.messages(), 1)
+
+	f.sms.err = nil
+	result, err = f.notifier.NotifyVersionChange(context.Background(), f.hospitalID, f.oldVersion, f.newVersion)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Repeated)
+	assert.Equal(t, 1, result.Sent)
+	assert.Len(t, f.sink.messages(), 1, "the email is not sent twice")
+	assert.Contains(t, f.sms.sent["+15550100"], "+ Mon 2025-11-03 ON1")
+}
+
+func TestFlushDigests_BatchesUntilDigestHour(t *testing.T) {
+	f := newNotifierFixture(t)
+	for _, p := range []*entity.Person{f.moved, f.gained} {
+		f.prefs.prefs[p.ID] = &entity.NotificationPreference{
+			PersonID:   p.ID,
+			Enabled:    true,
+			Channels:   []entity.NotificationChannel{entity.NotificationChannelEmail},
+			DigestMode: entity.DigestModeDaily,
+			DigestHour: 7,
+		}
+	}
+
+	clock := time.Date(2025, 11, 1, 14, 0, 0, 0, time.UTC)
+	f.notifier.now = func() time.Time { return clock }
+
+	// Two promotions in one afternoon (there and back) are queued, not sent
+	for _, promotion := range [][2]uuid.UUID{{f.oldVersion, f.newVersion}, {f.newVersion, f.oldVersion}} {
+		result, err := f.notifier.NotifyVersionChange(context.Background(), f.hospitalID, promotion[0], promotion[1])
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Queued)
+	}
+	assert.Empty(t, f.sink.messages())
+
+	// Not the digest hour yet
+	clock = clock.Add(time.Hour)
+	result, err := f.notifier.FlushDigests(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Sent)
+	assert.Empty(t, f.sink.messages())
+
+	// 07:00 the next morning: one combined message per person
+	clock = time.Date(2025, 11, 2, 7, 0, 0, 0, time.UTC)
+	result, err = f.notifier.FlushDigests(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Sent)
+
+	mail := f.sink.messages()
+	require.Len(t, mail, 2)
+	for _, m := range mail {
+		assert.Contains(t, m.Data, "Subject: Daily schedule digest: 2 update(s)")
+	}
+
+	unsent, err := f.pending.GetUnsent(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, unsent)
+}
+
+func TestSMSChannel_RequiresPhoneNumber(t *testing.T) {
+	ch := NewSMSChannel(&fakeSMSGateway{sent: map[string]string{}})
+	err := ch.Send(context.Background(), Recipient{
+		Person:     &entity.Person{ID: uuid.New()},
+		Preference: &entity.NotificationPreference{},
+	}, Message{Subject: "s", Body: "b"})
+	assert.ErrorIs(t, err, ErrNoAddress)
+}
+
+func TestSMSChannel_TruncatesOnCharacters(t *testing.T) {
+	gateway := &fakeSMSGateway{sent: map[string]string{}}
+	ch := NewSMSChannel(gateway)
+	err := ch.Send(context.Background(), Recipient{
+		Person:     &entity.Person{ID: uuid.New()},
+		Preference: &entity.NotificationPreference{PhoneNumber: "+15550100"},
+	}, Message{Subject: "Änderung", Body: strings.Repeat("é", 2*smsMaxLength)})
+	require.NoError(t, err)
+
+	text := gateway.sent["+15550100"]
+	assert.True(t, utf8.ValidString(text), "no character is cut in half")
+	assert.Equal(t, smsMaxLength, utf8.RuneCountInString(text))
+	assert.True(t, strings.HasSuffix(text, "é..."))
+}
+
+func TestSMTPChannel_StopsWhenContextEnds(t *testing.T) {
+	// Accepts connections but never greets, like a hung mail server
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	p, _ := strconv.Atoi(port)
+	ch := NewSMTPChannel(SMTPConfig{Host: host, Port: p, From: "schedcu@example.org"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = ch.Send(ctx, Recipient{Person: &entity.Person{ID: uuid.New(), Email: "a@example.org"}}, Message{Subject: "s", Body: "b"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,91 @@
+// Package rollback undoes a bad scrape batch: the shift instances and
+// assignments it imported are soft-deleted, hand-made MANUAL and OVERRIDE
+// assignments are kept, and the rollback is recorded in the audit log.
+package rollback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// AuditAction is the audit log action recorded for a batch rollback
+const AuditAction = "ROLLBACK_SCRAPE_BATCH"
+
+var (
+	// ErrAlreadyRolledBack is returned for a batch that was rolled back before
+	ErrAlreadyRolledBack = errors.New("scrape batch is already rolled back")
+	// ErrBatchPending is returned for a batch whose import is still running
+	ErrBatchPending = errors.New("scrape batch is still pending")
+)
+
+// AuditLogWriter is the part of repository.AuditLogRepository the service needs
+type AuditLogWriter interface {
+	Create(ctx context.Context, log *entity.AuditLog) error
+}
+
+// Service rolls back scrape batches
+type Service struct {
+	batches repository.ScrapeBatchRepository
+	audit   AuditLogWriter
+}
+
+// NewService creates a batch rollback service
+func NewService(batches repository.ScrapeBatchRepository, audit AuditLogWriter) *Service {
+	return &Service{batches: batches, audit: audit}
+}
+
+// Rollback soft-deletes everything batchID created and marks the batch
+// rolled back. The rollback is committed before the audit entry is written;
+// if writing the entry fails, the rollback is returned along with the error.
+func (s *Service) Rollback(ctx context.Context, batchID uuid.UUID, userID uuid.UUID, ipAddress string) (*entity.BatchRollback, error) {
+	batch, err := s.batches.GetByID(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.IsRolledBack() {
+		return nil, ErrAlreadyRolledBack
+	}
+	if batch.State == entity.BatchStatePending {
+		return nil, ErrBatchPending
+	}
+
+	result, err := s.batches.Rollback(ctx, batchID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back scrape batch: %w", err)
+	}
+
+	oldValues, err := json.Marshal(map[string]interface{}{
+		"state":             batch.State,
+		"row_count":         batch.RowCount,
+		"window_start_date": batch.WindowStartDate.Format("2006-01-02"),
+		"window_end_date":   batch.WindowEndDate.Format("2006-01-02"),
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to marshal audit values: %w", err)
+	}
+	newValues, err := json.Marshal(result)
+	if err != nil {
+		return result, fmt.Errorf("failed to marshal audit values: %w", err)
+	}
+
+	entry := &entity.AuditLog{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Action:    AuditAction,
+		Resource:  "ScrapeBatch#" + batchID.String(),
+		OldValues: string(oldValues),
+		NewValues: string(newValues),
+		Timestamp: result.RolledBackAt,
+		IPAddress: ipAddress,
+	}
+	if err := s.audit.Create(ctx, entry); err != nil {
+		return result, fmt.Errorf("scrape batch rolled back but audit log failed: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package rollback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBatchRepo struct {
+	repository.ScrapeBatchRepository
+	batch      *entity.ScrapeBatch
+	rolledBack []uuid.UUID
+}
+
+func (m *mockBatchRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.ScrapeBatch, error) {
+	if m.batch == nil || m.batch.ID != id {
+		return nil, &repository.NotFoundError{ResourceType: "ScrapeBatch", ResourceID: id.String()}
+	}
+	return m.batch, nil
+}
+
+func (m *mockBatchRepo) Rollback(ctx context.Context, id uuid.UUID, rolledBackBy uuid.UUID) (*entity.BatchRollback, error) {
+	m.rolledBack = append(m.rolledBack, id)
+	return &entity.BatchRollback{
+		ScrapeBatchID:      id,
+		AssignmentsDeleted: 12,
+		ShiftsDeleted:      4,
+		AssignmentsKept:    1,
+		RolledBackAt:       time.Date(2025, 11, 10, 9, 0, 0, 0, time.UTC),
+		RolledBackBy:       rolledBackBy,
+	}, nil
+}
+
+type mockAuditLog struct {
+	entries []*entity.AuditLog
+	err     error
+}
+
+func (m *mockAuditLog) Create(ctx context.Context, log *entity.AuditLog) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.entries = append(m.entries, log)
+	return nil
+}
+
+func completeBatch() *entity.ScrapeBatch {
+	return &entity.ScrapeBatch{
+		ID:              uuid.New(),
+		State:           entity.BatchStateComplete,
+		RowCount:        16,
+		WindowStartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+		WindowEndDate:   time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRollback_RecordsAuditEntry(t *testing.T) {
+	batch := completeBatch()
+	batches := &mockBatchRepo{batch: batch}
+	audit := &mockAuditLog{}
+	userID := uuid.New()
+
+	result, err := NewService(batches, audit).Rollback(context.Background(), batch.ID, userID, "10.0.0.7")
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{batch.ID}, batches.rolledBack)
+	assert.Equal(t, 12, result.AssignmentsDeleted)
+	assert.Equal(t, userID, result.RolledBackBy)
+
+	require.Len(t, audit.entries, 1)
+	entry := audit.entries[0]
+	assert.Equal(t, AuditAction, entry.Action)
+	assert.Equal(t, "ScrapeBatch#"+batch.ID.String(), entry.Resource)
+	assert.Equal(t, userID, entry.UserID)
+	assert.Equal(t, "10.0.0.7", entry.IPAddress)
+	assert.Equal(t, result.RolledBackAt, entry.Timestamp)
+
+	var recorded entity.BatchRollback
+	require.NoError(t, json.Unmarshal([]byte(entry.NewValues), &recorded))
+	assert.Equal(t, 4, recorded.ShiftsDeleted)
+	assert.Contains(t, entry.OldValues, `"row_count":16`)
+}
+
+func TestRollback_RefusesRolledBackAndPendingBatches(t *testing.T) {
+	rolledBack := completeBatch()
+	at := time.Now()
+	rolledBack.RolledBackAt = &at
+	pending := completeBatch()
+	pending.State = entity.BatchStatePending
+
+	for _, tc := range []struct {
+		batch *entity.ScrapeBatch
+		err   error
+	}{
+		{rolledBack, ErrAlreadyRolledBack},
+		{pending, ErrBatchPending},
+	} {
+		batches := &mockBatchRepo{batch: tc.batch}
+		audit := &mockAuditLog{}
+
+		_, err := NewService(batches, audit).Rollback(context.Background(), tc.batch.ID, uuid.New(), "")
+		assert.ErrorIs(t, err, tc.err)
+		assert.Empty(t, batches.rolledBack)
+		assert.Empty(t, audit.entries)
+	}
+}
+
+func TestRollback_UnknownBatch(t *testing.T) {
+	_, err := NewService(&mockBatchRepo{}, &mockAuditLog{}).Rollback(context.Background(), uuid.New(), uuid.New(), "")
+	assert.True(t, repository.IsNotFound(err))
+}
+
+func TestRollback_AuditFailureStillReturnsRollback(t *testing.T) {
+	batch := completeBatch()
+	audit := &mockAuditLog{err: errors.New("connection reset")}
+
+	result, err := NewService(&mockBatchRepo{batch: batch}, audit).Rollback(context.Background(), batch.ID, uuid.New(), "")
+	assert.Error(t, err)
+	require.NotNil(t, result, "the rollback itself was committed")
+	assert.Equal(t, batch.ID, result.ScrapeBatchID)
+}
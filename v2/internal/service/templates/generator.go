@@ -0,0 +1,224 @@
+// Package templates generates shift instances from a hospital's recurring
+// shift templates, so a schedule version can be drafted for a period no ODS
+// file covers. Each date of the period gets one shift per template that runs
+// that day, staffed by the template's variant for the date's day type.
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/internal/service"
+	"github.com/schedcu/v2/internal/validation"
+)
+
+var (
+	// ErrOutsideVersion is returned for a period outside the version's effective dates
+	ErrOutsideVersion = errors.New("period must fall within the schedule version's effective dates")
+	// ErrNotStaging is returned when generating into a version that is not STAGING
+	ErrNotStaging = errors.New("shifts can only be generated into a STAGING version")
+)
+
+// Options describe the period to generate. Generate defaults an unset start
+// or end to the version's.
+type Options struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Holidays  entity.HolidayCalendar // Extra holidays on top of entity.NewHolidayCalendar's
+	CreatorID uuid.UUID
+}
+
+// GeneratedShift is a shift instance and the template it came from
+type GeneratedShift struct {
+	Shift      *entity.ShiftInstance
+	TemplateID uuid.UUID
+	DayType    entity.DayType // Day type of the variant used
+	Holiday    string
+}
+
+// Result is the generated shifts, in date order
+type Result struct {
+	Shifts     []GeneratedShift
+	Skipped    int                // Shifts not generated because their slot was already taken
+	Validation *validation.Result // Skipped shifts and empty template sets
+}
+
+// Generator materializes shift templates into shift instances
+type Generator struct {
+	templates repository.ShiftTemplateRepository
+	versions  repository.ScheduleVersionRepository
+	shifts    repository.ShiftInstanceRepository
+	coverage  service.CoverageQueue // Optional: calculates coverage after generating
+}
+
+// NewGenerator creates a generator
+func NewGenerator(
+	templates repository.ShiftTemplateRepository,
+	versions repository.ScheduleVersionRepository,
+	shifts repository.ShiftInstanceRepository,
+) *Generator {
+	return &Generator{
+		templates: templates,
+		versions:  versions,
+		shifts:    shifts,
+	}
+}
+
+// SetCoverageQueue enables coverage calculation after generating shifts
+func (g *Generator) SetCoverageQueue(queue service.CoverageQueue) {
+	g.coverage = queue
+}
+
+// Preview returns the shifts a hospital's templates generate over the
+// options' period without saving anything
+func (g *Generator) Preview(ctx context.Context, hospitalID uuid.UUID, opts Options) (*Result, error) {
+	start, end := entity.DateOf(opts.StartDate), entity.DateOf(opts.EndDate)
+	if err := entity.ValidatePeriod(start, end); err != nil {
+		return nil, err
+	}
+	templates, err := g.templates.GetByHospital(ctx, hospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shift templates: %w", err)
+	}
+	return materialize(templates, hospitalID, uuid.Nil, start, end, calendar(start, end, opts.Holidays), opts.CreatorID, map[entity.ShiftSlot]bool{}), nil
+}
+
+// Generate creates the shifts of the version's hospital's templates in a
+// STAGING version. Slots the version already has a shift for (same date,
+// shift type, study type and specialty) are skipped, so generating twice
+// does not duplicate shifts.
+func (g *Generator) Generate(ctx context.Context, versionID uuid.UUID, opts Options) (*Result, error) {
+	version, err := g.versions.GetByID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != entity.VersionStatusStaging || version.DeletedAt != nil {
+		return nil, ErrNotStaging
+	}
+
+	start, end := entity.DateOf(version.EffectiveStartDate), entity.DateOf(version.EffectiveEndDate)
+	if !opts.StartDate.IsZero() {
+		start = entity.DateOf(opts.StartDate)
+	}
+	if !opts.EndDate.IsZero() {
+		end = entity.DateOf(opts.EndDate)
+	}
+	if err := entity.ValidatePeriod(start, end); err != nil {
+		return nil, err
+	}
+	if start.Before(entity.DateOf(version.EffectiveStartDate)) || end.After(entity.DateOf(version.EffectiveEndDate)) {
+		return nil, ErrOutsideVersion
+	}
+
+	existing, err := g.shifts.GetByScheduleVersion(ctx, version.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shift instances: %w", err)
+	}
+	taken := make(map[entity.ShiftSlot]bool, len(existing))
+	for _, s := range existing {
+		taken[s.Slot()] = true
+	}
+
+	templates, err := g.templates.GetByHospital(ctx, version.HospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shift templates: %w", err)
+	}
+	result := materialize(templates, version.HospitalID, version.ID, start, end, calendar(start, end, opts.Holidays), opts.CreatorID, taken)
+	if len(result.Shifts) == 0 {
+		return result, nil
+	}
+
+	shifts := make([]*entity.ShiftInstance, 0, len(result.Shifts))
+	for _, generated := range result.Shifts {
+		shifts = append(shifts, generated.Shift)
+	}
+	if err := g.shifts.CreateBatch(ctx, shifts); err != nil {
+		return nil, fmt.Errorf("failed to create shift instances: %w", err)
+	}
+
+	service.QueueCoverageRecalculation(ctx, g.coverage, version.ID, opts.CreatorID, start, end)
+	return result, nil
+}
+
+// materialize builds the templates' shifts for each date of start..end.
+// taken holds the slots already filled, by the version or an earlier
+// template; shifts whose slot is taken are skipped with one warning per template.
+func materialize(
+	templates []*entity.ShiftTemplate,
+	hospitalID, versionID uuid.UUID,
+	start, end time.Time,
+	holidays entity.HolidayCalendar,
+	creatorID uuid.UUID,
+	taken map[entity.ShiftSlot]bool,
+) *Result {
+	result := &Result{Validation: validation.NewResult()}
+	if len(templates) == 0 {
+		result.Validation.AddWarning("NO_TEMPLATES", "The hospital has no shift templates")
+		return result
+	}
+
+	now := entity.Now()
+	skipped := make(map[*entity.ShiftTemplate][]string)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		holiday, _ := holidays.Holiday(d)
+		for _, t := range templates {
+			variant := t.VariantFor(d, holidays)
+			if variant == nil {
+				continue
+			}
+			shift := &entity.ShiftInstance{
+				ID:                  uuid.New(),
+				ScheduleVersionID:   versionID,
+				ShiftType:           t.ShiftType,
+				ScheduleDate:        d,
+				StartTime:           variant.StartTime,
+				EndTime:             variant.EndTime,
+				HospitalID:          hospitalID,
+				StudyType:           variant.StudyType,
+				SpecialtyConstraint: variant.SpecialtyConstraint,
+				DesiredCoverage:     variant.DesiredCoverage,
+				IsMandatory:         variant.IsMandatory,
+				CreatedAt:           now,
+				CreatedBy:           creatorID,
+			}
+			if taken[shift.Slot()] {
+				skipped[t] = append(skipped[t], d.Format("2006-01-02"))
+				continue
+			}
+			taken[shift.Slot()] = true
+
+			result.Shifts = append(result.Shifts, GeneratedShift{
+				Shift:      shift,
+				TemplateID: t.ID,
+				DayType:    variant.DayType,
+				Holiday:    holiday,
+			})
+		}
+	}
+
+	for _, t := range templates {
+		dates := skipped[t]
+		if len(dates) == 0 {
+			continue
+		}
+		result.Skipped += len(dates)
+		result.Validation.AddWarningWithContext("SHIFT_EXISTS",
+			fmt.Sprintf("%d %s shift(s) of template %q were not generated because the slot already has a shift", len(dates), t.ShiftType, t.Name),
+			map[string]interface{}{"template_id": t.ID.String(), "dates": dates})
+	}
+	return result
+}
+
+// calendar is the standard holidays over start..end plus the extra ones
+func calendar(start, end time.Time, extra entity.HolidayCalendar) entity.HolidayCalendar {
+	c := entity.NewHolidayCalendar(start, end)
+	for date, name := range extra {
+		c[date] = name
+	}
+	return c
+}
@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/schedcu/v2/tests/helpers"
+	"github.com/schedcu/v2/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTemplateRepo struct {
+	repository.ShiftTemplateRepository
+	templates []*entity.ShiftTemplate
+}
+
+func (m *mockTemplateRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.ShiftTemplate, error) {
+	var templates []*entity.ShiftTemplate
+	for _, t := range m.templates {
+		if t.HospitalID == hospitalID {
+			templates = append(templates, t)
+		}
+	}
+	return templates, nil
+}
+
+type fixture struct {
+	generator *Generator
+	version   *entity.ScheduleVersion
+	templates *mockTemplateRepo
+	shifts    *mocks.MockShiftInstanceRepository
+}
+
+// newFixture is a STAGING version over the week of Thanksgiving 2025
+// (Mon 24th - Sun 30th) and two templates: a weekday DAY shift with a
+// holiday variant, and an ON1 shift every night.
+func newFixture() *fixture {
+	hospitalID := uuid.New()
+	schedule := mocks.NewMockSchedule()
+	f := &fixture{shifts: schedule.Shifts}
+	f.version = helpers.NewScheduleVersionBuilder().WithHospitalID(hospitalID).WithStatus(entity.VersionStatusStaging).
+		WithEffectiveStartDate(helpers.Date("2025-11-24")).WithEffectiveEndDate(helpers.Date("2025-11-30")).Build()
+	f.templates = &mockTemplateRepo{templates: []*entity.ShiftTemplate{
+		{
+			ID: uuid.New(), HospitalID: hospitalID, Name: "Day", ShiftType: entity.ShiftTypeDay,
+			Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Variants: []entity.ShiftTemplateVariant{
+				{DayType: entity.DayTypeWeekday, StartTime: "07:00", EndTime: "15:00", StudyType: entity.StudyTypeGeneral,
+					SpecialtyConstraint: entity.SpecialtyBoth, DesiredCoverage: 3, IsMandatory: true},
+				{DayType: entity.DayTypeHoliday, StartTime: "08:00", EndTime: "12:00", StudyType: entity.StudyTypeGeneral,
+					SpecialtyConstraint: entity.SpecialtyBoth, DesiredCoverage: 1},
+			},
+			EffectiveFrom: helpers.Date("2025-01-01"),
+		},
+		{
+			ID: uuid.New(), HospitalID: hospitalID, Name: "Overnight", ShiftType: entity.ShiftTypeON1,
+			Weekdays: []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+			Variants: []entity.ShiftTemplateVariant{
+				{DayType: entity.DayTypeWeekday, StartTime: "19:00", EndTime: "07:00", StudyType: entity.StudyTypeGeneral,
+					SpecialtyConstraint: entity.SpecialtyBoth, DesiredCoverage: 1, IsMandatory: true},
+				{DayType: entity.DayTypeWeekend, StartTime: "17:00", EndTime: "07:00", StudyType: entity.StudyTypeGeneral,
+					SpecialtyConstraint: entity.SpecialtyBoth, DesiredCoverage: 1, IsMandatory: true},
+			},
+			EffectiveFrom: helpers.Date("2025-01-01"),
+		},
+	}}
+	schedule.Versions.Create(context.Background(), f.version)
+	f.generator = NewGenerator(f.templates, schedule.Versions, schedule.Shifts)
+	return f
+}
+
+func TestPreview(t *testing.T) {
+	f := newFixture()
+
+	result, err := f.generator.Preview(context.Background(), f.version.HospitalID, Options{
+		StartDate: helpers.Date("2025-11-24"), EndDate: helpers.Date("2025-11-30"),
+	})
+	require.NoError(t, err)
+	assert.Len(t, result.Shifts, 12, "5 DAY shifts and 7 ON1 shifts")
+	assert.Empty(t, f.shifts.All(), "previews are not saved")
+
+	byDay := make(map[string]GeneratedShift)
+	for _, g := range result.Shifts {
+		byDay[g.Shift.ScheduleDate.Format("2006-01-02")+"/"+string(g.Shift.ShiftType)] = g
+	}
+	thanksgiving := byDay["2025-11-27/DAY"]
+	assert.Equal(t, entity.DayTypeHoliday, thanksgiving.DayType)
+	assert.Equal(t, "Thanksgiving", thanksgiving.Holiday)
+	assert.Equal(t, 1, thanksgiving.Shift.DesiredCoverage)
+	assert.Equal(t, 3, byDay["2025-11-28/DAY"].Shift.DesiredCoverage)
+	assert.Equal(t, "17:00", byDay["2025-11-29/ON1"].Shift.StartTime)
+	assert.Equal(t, entity.DayTypeWeekday, byDay["2025-11-27/ON1"].DayType, "ON1 has no holiday variant")
+	assert.Equal(t, uuid.Nil, thanksgiving.Shift.ScheduleVersionID)
+}
+
+func TestGenerate_SkipsTakenSlots(t *testing.T) {
+	f := newFixture()
+	f.shifts.Create(context.Background(), helpers.NewShiftInstanceBuilder().
+		WithScheduleVersionID(f.version.ID).WithHospitalID(f.version.HospitalID).
+		WithShiftType(entity.ShiftTypeDay).WithScheduleDate(helpers.Date("2025-11-24")).
+		WithStudyType(entity.StudyTypeGeneral).WithSpecialtyConstraint(entity.SpecialtyBoth).Build())
+
+	result, err := f.generator.Generate(context.Background(), f.version.ID, Options{})
+	require.NoError(t, err)
+	assert.Len(t, result.Shifts, 11)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Len(t, result.Validation.MessagesByCode("SHIFT_EXISTS"), 1)
+	assert.Len(t, f.shifts.All(), 12)
+	for _, g := range result.Shifts {
+		assert.Equal(t, f.version.ID, g.Shift.ScheduleVersionID)
+		assert.Nil(t, g.Shift.ScrapeBatchID)
+	}
+
+	again, err := f.generator.Generate(context.Background(), f.version.ID, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, again.Shifts, "generating twice does not duplicate shifts")
+	assert.Equal(t, 12, again.Skipped)
+}
+
+func TestGenerate_StudyTypesShareShiftType(t *testing.T) {
+	f := newFixture()
+	neuro := *f.templates.templates[1]
+	neuro.ID, neuro.Name = uuid.New(), "Overnight neuro"
+	neuro.Variants = []entity.ShiftTemplateVariant{{DayType: entity.DayTypeWeekday, StartTime: "19:00", EndTime: "07:00",
+		StudyType: entity.StudyTypeNeuroImaging, SpecialtyConstraint: entity.SpecialtyNeuroOnly, DesiredCoverage: 1}}
+	f.templates.templates = append(f.templates.templates, &neuro)
+
+	result, err := f.generator.Generate(context.Background(), f.version.ID, Options{})
+	require.NoError(t, err)
+	assert.Len(t, result.Shifts, 17, "a NEURO ON1 on each of the 5 weekdays next to the GENERAL one")
+	assert.Zero(t, result.Skipped)
+}
+
+func TestGenerate_Guards(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	_, err := f.generator.Generate(ctx, f.version.ID, Options{StartDate: helpers.Date("2025-11-20")})
+	assert.ErrorIs(t, err, ErrOutsideVersion)
+	_, err = f.generator.Generate(ctx, f.version.ID, Options{StartDate: helpers.Date("2025-11-30"), EndDate: helpers.Date("2025-11-24")})
+	assert.ErrorIs(t, err, entity.ErrInvalidPeriod)
+	_, err = f.generator.Preview(ctx, f.version.HospitalID, Options{StartDate: helpers.Date("2025-01-01"), EndDate: helpers.Date("2026-01-31")})
+	assert.ErrorIs(t, err, entity.ErrInvalidPeriod)
+
+	f.version.Status = entity.VersionStatusProduction
+	_, err = f.generator.Generate(ctx, f.version.ID, Options{})
+	assert.ErrorIs(t, err, ErrNotStaging)
+}
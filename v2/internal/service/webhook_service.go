@@ -0,0 +1,354 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/repository"
+)
+
+// Webhook request headers
+const (
+	WebhookHeaderEvent     = "X-SchedCU-Event"
+	WebhookHeaderDelivery  = "X-SchedCU-Delivery"
+	WebhookHeaderTimestamp = "X-SchedCU-Timestamp"
+	WebhookHeaderSignature = "X-SchedCU-Signature"
+)
+
+// WebhookEventTypes lists the events that can be delivered to webhooks
+var WebhookEventTypes = []string{
+	string(event.TypeVersionPromoted),
+	string(event.TypeCoverageDropped),
+	string(event.TypeImportFailed),
+	string(event.TypeCoverageGapAlert),
+}
+
+// maxStoredResponseBody limits how much of a receiver's response is kept in the delivery log
+const maxStoredResponseBody = 1024
+
+// WebhookPayload is the JSON body sent to webhook receivers
+type WebhookPayload struct {
+	DeliveryID string                 `json:"delivery_id"`
+	Event      string                 `json:"event"`
+	HospitalID string                 `json:"hospital_id"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// SignWebhookPayload computes the signature header value for a payload.
+// Receivers verify it by computing HMAC-SHA256(secret, "<timestamp>.<body>") and
+// comparing against the hex digest after the "sha256=" prefix.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookService is the concrete implementation of WebhookService
+type webhookService struct {
+	subscriptions repository.WebhookSubscriptionRepository
+	deliveries    repository.WebhookDeliveryRepository
+	queue         WebhookQueue
+	client        *http.Client
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	subscriptions repository.WebhookSubscriptionRepository,
+	deliveries repository.WebhookDeliveryRepository,
+	queue WebhookQueue,
+) WebhookService {
+	return &webhookService{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		queue:         queue,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CreateSubscription registers a new webhook for a hospital.
+// A random secret is generated when none is supplied.
+func (s *webhookService) CreateSubscription(
+	ctx context.Context,
+	hospitalID entity.HospitalID,
+	rawURL string,
+	eventTypes []string,
+	secret string,
+	creatorID entity.UserID,
+) (*entity.WebhookSubscription, error) {
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, &repository.ValidationError{Field: "url", Message: "must be an absolute http(s) URL"}
+	}
+
+	for _, t := range eventTypes {
+		if !isWebhookEventType(t) {
+			return nil, &repository.ValidationError{Field: "event_types", Message: fmt.Sprintf("unknown event type %q", t)}
+		}
+	}
+
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	now := time.Now().UTC()
+	sub := &entity.WebhookSubscription{
+		ID:         uuid.New(),
+		HospitalID: hospitalID,
+		URL:        rawURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+		CreatedAt:  now,
+		CreatedBy:  creatorID,
+		UpdatedAt:  now,
+	}
+
+	if err := s.subscriptions.Create(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// GetSubscription retrieves a webhook subscription by ID
+func (s *webhookService) GetSubscription(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+	return s.subscriptions.GetByID(ctx, id)
+}
+
+// ListSubscriptions lists a hospital's webhook subscriptions
+func (s *webhookService) ListSubscriptions(ctx context.Context, hospitalID entity.HospitalID) ([]*entity.WebhookSubscription, error) {
+	return s.subscriptions.GetByHospital(ctx, hospitalID)
+}
+
+// DeleteSubscription removes a webhook subscription; queued deliveries are dropped
+func (s *webhookService) DeleteSubscription(ctx context.Context, id uuid.UUID, deleterID entity.UserID) error {
+	return s.subscriptions.Delete(ctx, id, deleterID)
+}
+
+// Dispatch records a delivery for every subscription interested in the event and queues it
+func (s *webhookService) Dispatch(ctx context.Context, evt *event.Event) ([]*entity.WebhookDelivery, error) {
+	if !isWebhookEventType(string(evt.Type)) {
+		return nil, nil
+	}
+
+	subs, err := s.subscriptions.GetByHospital(ctx, evt.HospitalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+
+	var deliveries []*entity.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Accepts(string(evt.Type)) {
+			continue
+		}
+
+		deliveryID := uuid.New()
+		payload, err := json.Marshal(WebhookPayload{
+			DeliveryID: deliveryID.String(),
+			Event:      string(evt.Type),
+			HospitalID: evt.HospitalID.String(),
+			OccurredAt: evt.OccurredAt,
+			Data:       evt.Data,
+		})
+		if err != nil {
+			return deliveries, fmt.Errorf("failed to marshal webhook payload: %w", err)
+		}
+
+		delivery := &entity.WebhookDelivery{
+			ID:             deliveryID,
+			SubscriptionID: sub.ID,
+			HospitalID:     evt.HospitalID,
+			EventType:      string(evt.Type),
+			Payload:        payload,
+			Status:         entity.WebhookDeliveryPending,
+			CreatedAt:      time.Now().UTC(),
+		}
+
+		if err := s.createAndQueue(ctx, delivery); err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// Deliver performs one signed delivery attempt and records the outcome
+func (s *webhookService) Deliver(ctx context.Context, deliveryID uuid.UUID, finalAttempt bool) error {
+	delivery, err := s.deliveries.GetByID(ctx, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook delivery: %w", err)
+	}
+
+	if delivery.Status != entity.WebhookDeliveryPending {
+		return nil // Already delivered or given up
+	}
+
+	sub, err := s.subscriptions.GetByID(ctx, delivery.SubscriptionID)
+	if err != nil && !repository.IsNotFound(err) {
+		return fmt.Errorf("failed to load webhook subscription: %w", err)
+	}
+	if err != nil || !sub.Active {
+		// Subscription removed or disabled since the event: stop retrying
+		msg := "subscription is no longer active"
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.ErrorMessage = &msg
+		return s.deliveries.Update(ctx, delivery)
+	}
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = nil
+	delivery.ErrorMessage = nil
+
+	attemptErr := s.post(ctx, sub, delivery)
+	if attemptErr == nil {
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	} else {
+		msg := attemptErr.Error()
+		delivery.ErrorMessage = &msg
+		if finalAttempt {
+			delivery.Status = entity.WebhookDeliveryFailed
+		}
+	}
+
+	if err := s.deliveries.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return attemptErr
+}
+
+// Redeliver queues a fresh copy of an earlier delivery. The payload is the
+// original one with the new delivery's ID, so it matches the delivery header.
+func (s *webhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (*entity.WebhookDelivery, error) {
+	original, err := s.deliveries.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if original.SubscriptionID != subscriptionID {
+		return nil, &repository.NotFoundError{ResourceType: "WebhookDelivery", ResourceID: deliveryID.String()}
+	}
+
+	if _, err := s.subscriptions.GetByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	var body WebhookPayload
+	if err := json.Unmarshal(original.Payload, &body); err != nil {
+		return nil, fmt.Errorf("failed to read original webhook payload: %w", err)
+	}
+	id := uuid.New()
+	body.DeliveryID = id.String()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	delivery := &entity.WebhookDelivery{
+		ID:             id,
+		SubscriptionID: original.SubscriptionID,
+		HospitalID:     original.HospitalID,
+		EventType:      original.EventType,
+		Payload:        payload,
+		Status:         entity.WebhookDeliveryPending,
+		RedeliveryOf:   &original.ID,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if err := s.createAndQueue(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries returns the most recent deliveries for a subscription
+func (s *webhookService) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*entity.WebhookDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.deliveries.GetBySubscription(ctx, subscriptionID, limit)
+}
+
+// createAndQueue stores a pending delivery and hands it to the job queue
+func (s *webhookService) createAndQueue(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	if err := s.deliveries.Create(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	if err := s.queue.EnqueueWebhookDelivery(ctx, delivery.ID); err != nil {
+		return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// post sends the signed payload; any non-2xx response is an error
+func (s *webhookService) post(ctx context.Context, sub *entity.WebhookSubscription, delivery *entity.WebhookDelivery) error {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SchedCU-Webhooks/1.0")
+	req.Header.Set(WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(WebhookHeaderDelivery, delivery.ID.String())
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxStoredResponseBody))
+	status := resp.StatusCode
+	bodyStr := string(body)
+	delivery.ResponseStatus = &status
+	delivery.ResponseBody = &bodyStr
+
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("webhook receiver returned status %d", status)
+	}
+
+	return nil
+}
+
+func isWebhookEventType(eventType string) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
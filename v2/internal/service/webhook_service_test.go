@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/event"
+	"github.com/schedcu/v2/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockWebhookSubscriptionRepo is an in-memory WebhookSubscriptionRepository
+type mockWebhookSubscriptionRepo struct {
+	subs map[uuid.UUID]*entity.WebhookSubscription
+}
+
+func (m *mockWebhookSubscriptionRepo) Create(ctx context.Context, sub *entity.WebhookSubscription) error {
+	m.subs[sub.ID] = sub
+	return nil
+}
+
+func (m *mockWebhookSubscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+	if sub, ok := m.subs[id]; ok && sub.DeletedAt == nil {
+		return sub, nil
+	}
+	return nil, &repository.NotFoundError{ResourceType: "WebhookSubscription", ResourceID: id.String()}
+}
+
+func (m *mockWebhookSubscriptionRepo) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.WebhookSubscription, error) {
+	var result []*entity.WebhookSubscription
+	for _, sub := range m.subs {
+		if sub.HospitalID == hospitalID && sub.DeletedAt == nil {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookSubscriptionRepo) Update(ctx context.Context, sub *entity.WebhookSubscription) error {
+	m.subs[sub.ID] = sub
+	return nil
+}
+
+func (m *mockWebhookSubscriptionRepo) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	sub, ok := m.subs[id]
+	if !ok {
+		return &repository.NotFoundError{ResourceType: "WebhookSubscription", ResourceID: id.String()}
+	}
+	sub.SoftDelete(deleterID)
+	return nil
+}
+
+// mockWebhookDeliveryRepo is an in-memory WebhookDeliveryRepository
+type mockWebhookDeliveryRepo struct {
+	deliveries map[uuid.UUID]*entity.WebhookDelivery
+}
+
+func (m *mockWebhookDeliveryRepo) Create(ctx context.Context, d *entity.WebhookDelivery) error {
+	m.deliveries[d.ID] = d
+	return nil
+}
+
+func (m *mockWebhookDeliveryRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookDelivery, error) {
+	if d, ok := m.deliveries[id]; ok {
+		return d, nil
+	}
+	return nil, &repository.NotFoundError{ResourceType: "WebhookDelivery", ResourceID: id.String()}
+}
+
+func (m *mockWebhookDeliveryRepo) GetBySubscription(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*entity.WebhookDelivery, error) {
+	var result []*entity.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookDeliveryRepo) Update(ctx context.Context, d *entity.WebhookDelivery) error {
+	m.deliveries[d.ID] = d
+	return nil
+}
+
+// mockWebhookQueue records enqueued delivery IDs
+type mockWebhookQueue struct {
+	enqueued []uuid.UUID
+}
+
+func (m *mockWebhookQueue) EnqueueWebhookDelivery(ctx context.Context, deliveryID uuid.UUID) error {
+	m.enqueued = append(m.enqueued, deliveryID)
+	return nil
+}
+
+// webhookReceiver is an httptest server that records requests and replies with a configurable status
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	r := &webhookReceiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		status := r.status
+		r.mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte("ack"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func newTestWebhookService() (*webhookService, *mockWebhookDeliveryRepo, *mockWebhookQueue) {
+	deliveries := &mockWebhookDeliveryRepo{deliveries: make(map[uuid.UUID]*entity.WebhookDelivery)}
+	queue := &mockWebhookQueue{}
+	svc := NewWebhookService(
+		&mockWebhookSubscriptionRepo{subs: make(map[uuid.UUID]*entity.WebhookSubscription)},
+		deliveries,
+		queue,
+	).(*webhookService)
+	return svc, deliveries, queue
+}
+
+func TestWebhookService_CreateSubscriptionValidation(t *testing.T) {
+	svc, _, _ := newTestWebhookService()
+	ctx := context.Background()
+
+	_, err := svc.CreateSubscription(ctx, uuid.New(), "ftp://example.com", nil, "", uuid.New())
+	assert.Error(t, err)
+
+	_, err = svc.CreateSubscription(ctx, uuid.New(), "https://example.com/hook", []string{"unknown.event"}, "", uuid.New())
+	assert.Error(t, err)
+
+	sub, err := svc.CreateSubscription(ctx, uuid.New(), "https://example.com/hook", []string{string(event.TypeVersionPromoted)}, "", uuid.New())
+	require.NoError(t, err)
+	assert.Len(t, sub.Secret, 64, "secret should be generated when not supplied")
+	assert.True(t, sub.Active)
+}
+
+func TestWebhookService_DispatchAndDeliverSigned(t *testing.T) {
+	svc, deliveries, queue := newTestWebhookService()
+	receiver := newWebhookReceiver(t)
+	ctx := context.Background()
+
+	hospitalID := uuid.New()
+	promoted, err := svc.CreateSubscription(ctx, hospitalID, receiver.URL, []string{string(event.TypeVersionPromoted)}, "s3cret", uuid.New())
+	require.NoError(t, err)
+	_, err = svc.CreateSubscription(ctx, hospitalID, receiver.URL, []string{string(event.TypeImportFailed)}, "other", uuid.New())
+	require.NoError(t, err)
+	_, err = svc.CreateSubscription(ctx, uuid.New(), receiver.URL, nil, "elsewhere", uuid.New())
+	require.NoError(t, err)
+
+	evt := event.New(event.TypeVersionPromoted, hospitalID, map[string]interface{}{"version_id": "v1"})
+	dispatched, err := svc.Dispatch(ctx, evt)
+	require.NoError(t, err)
+
+	// Only the matching subscription for this hospital gets a delivery
+	require.Len(t, dispatched, 1)
+	assert.Equal(t, promoted.ID, dispatched[0].SubscriptionID)
+	assert.Equal(t, []uuid.UUID{dispatched[0].ID}, queue.enqueued)
+
+	require.NoError(t, svc.Deliver(ctx, dispatched[0].ID, false))
+
+	require.Len(t, receiver.requests, 1)
+	req := receiver.requests[0]
+	body := receiver.bodies[0]
+
+	assert.Equal(t, string(event.TypeVersionPromoted), req.Header.Get(WebhookHeaderEvent))
+	assert.Equal(t, dispatched[0].ID.String(), req.Header.Get(WebhookHeaderDelivery))
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(WebhookHeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, SignWebhookPayload("s3cret", timestamp, body), req.Header.Get(WebhookHeaderSignature))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, hospitalID.String(), payload.HospitalID)
+	assert.Equal(t, "v1", payload.Data["version_id"])
+
+	logged := deliveries.deliveries[dispatched[0].ID]
+	assert.Equal(t, entity.WebhookDeliverySucceeded, logged.Status)
+	assert.Equal(t, 1, logged.Attempts)
+	require.NotNil(t, logged.ResponseStatus)
+	assert.Equal(t, http.StatusOK, *logged.ResponseStatus)
+	assert.NotNil(t, logged.DeliveredAt)
+}
+
+func TestWebhookService_DispatchIgnoresInternalEvents(t *testing.T) {
+	svc, _, queue := newTestWebhookService()
+	ctx := context.Background()
+
+	hospitalID := uuid.New()
+	_, err := svc.CreateSubscription(ctx, hospitalID, "https://example.com/hook", nil, "", uuid.New())
+	require.NoError(t, err)
+
+	dispatched, err := svc.Dispatch(ctx, event.New(event.TypeJobStateChanged, hospitalID, nil))
+	require.NoError(t, err)
+	assert.Empty(t, dispatched)
+	assert.Empty(t, queue.enqueued)
+}
+
+func TestWebhookService_DeliverRetryThenFail(t *testing.T) {
+	svc, deliveries, _ := newTestWebhookService()
+	receiver := newWebhookReceiver(t)
+	receiver.status = http.StatusServiceUnavailable
+	ctx := context.Background()
+
+	hospitalID := uuid.New()
+	_, err := svc.CreateSubscription(ctx, hospitalID, receiver.URL, nil, "s3cret", uuid.New())
+	require.NoError(t, err)
+
+	dispatched, err := svc.Dispatch(ctx, event.New(event.TypeImportFailed, hospitalID, nil))
+	require.NoError(t, err)
+	require.Len(t, dispatched, 1)
+	id := dispatched[0].ID
+
+	// Non-final failure: error returned so the job queue retries, still pending
+	assert.Error(t, svc.Deliver(ctx, id, false))
+	assert.Equal(t, entity.WebhookDeliveryPending, deliveries.deliveries[id].Status)
+	assert.NotNil(t, deliveries.deliveries[id].ErrorMessage)
+
+	// Final failure: marked FAILED
+	assert.Error(t, svc.Deliver(ctx, id, true))
+	assert.Equal(t, entity.WebhookDeliveryFailed, deliveries.deliveries[id].Status)
+	assert.Equal(t, 2, deliveries.deliveries[id].Attempts)
+
+	// Further attempts are no-ops
+	assert.NoError(t, svc.Deliver(ctx, id, false))
+	assert.Len(t, receiver.requests, 2)
+}
+
+func TestWebhookService_DeliverToDeletedSubscription(t *testing.T) {
+	svc, deliveries, _ := newTestWebhookService()
+	receiver := newWebhookReceiver(t)
+	ctx := context.Background()
+
+	hospitalID := uuid.New()
+	sub, err := svc.CreateSubscription(ctx, hospitalID, receiver.URL, nil, "", uuid.New())
+	require.NoError(t, err)
+
+	dispatched, err := svc.Dispatch(ctx, event.New(event.TypeCoverageDropped, hospitalID, nil))
+	require.NoError(t, err)
+	require.Len(t, dispatched, 1)
+
+	require.NoError(t, svc.DeleteSubscription(ctx, sub.ID, uuid.New()))
+
+	assert.NoError(t, svc.Deliver(ctx, dispatched[0].ID, false))
+	assert.Equal(t, entity.WebhookDeliveryFailed, deliveries.deliveries[dispatched[0].ID].Status)
+	assert.Empty(t, receiver.requests)
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	svc, _, queue := newTestWebhookService()
+	receiver := newWebhookReceiver(t)
+	ctx := context.Background()
+
+	hospitalID := uuid.New()
+	sub, err := svc.CreateSubscription(ctx, hospitalID, receiver.URL, nil, "s3cret", uuid.New())
+	require.NoError(t, err)
+
+	dispatched, err := svc.Dispatch(ctx, event.New(event.TypeVersionPromoted, hospitalID, nil))
+	require.NoError(t, err)
+	original := dispatched[0]
+
+	redelivery, err := svc.Redeliver(ctx, sub.ID, original.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, original.ID, redelivery.ID)
+	require.NotNil(t, redelivery.RedeliveryOf)
+	assert.Equal(t, original.ID, *redelivery.RedeliveryOf)
+	assert.Equal(t, []uuid.UUID{original.ID, redelivery.ID}, queue.enqueued)
+
+	// Same event, but the body names the new delivery like its header does
+	var was, now WebhookPayload
+	require.NoError(t, json.Unmarshal(original.Payload, &was))
+	require.NoError(t, json.Unmarshal(redelivery.Payload, &now))
+	assert.Equal(t, redelivery.ID.String(), now.DeliveryID)
+	was.DeliveryID = now.DeliveryID
+	assert.Equal(t, was, now)
+
+	// Delivery must belong to the given subscription
+	_, err = svc.Redeliver(ctx, uuid.New(), original.ID)
+	assert.True(t, repository.IsNotFound(err))
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_events_inserted;
+DROP INDEX IF EXISTS idx_events_occurred;
+DROP INDEX IF EXISTS idx_events_hospital;
+DROP TABLE IF EXISTS events;
@@ -0,0 +1,18 @@
+CREATE TABLE events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    hospital_id UUID NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    data JSONB,
+    inserted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp()
+);
+
+-- Indexes for replay queries
+CREATE INDEX idx_events_hospital ON events(hospital_id, id);
+CREATE INDEX idx_events_occurred ON events(occurred_at DESC);
+CREATE INDEX idx_events_inserted ON events(inserted_at);
+
+COMMENT ON TABLE events IS 'Event log backing the SSE stream. IDs are used as Last-Event-ID for resume; rows are announced to all API replicas via NOTIFY schedcu_events.';
+COMMENT ON COLUMN events.event_type IS 'job.state_changed, version.promoted, coverage.recalculated';
+COMMENT ON COLUMN events.data IS 'JSON payload delivered to subscribers';
+COMMENT ON COLUMN events.inserted_at IS 'Database time of the insert; lets readers re-check recent rows, since a BIGSERIAL ID can commit after higher ones';
@@ -0,0 +1,50 @@
+CREATE TABLE webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by UUID NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by UUID
+);
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id),
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'SUCCEEDED', 'FAILED')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    response_body TEXT,
+    error_message TEXT,
+    redelivery_of UUID REFERENCES webhook_deliveries(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+-- How far each event consumer (e.g. the webhook dispatcher) has read the events table
+CREATE TABLE event_cursors (
+    consumer VARCHAR(100) PRIMARY KEY,
+    last_event_id BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Indexes for common queries
+CREATE INDEX idx_webhook_subscriptions_hospital ON webhook_subscriptions(hospital_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_status ON webhook_deliveries(status);
+
+COMMENT ON TABLE webhook_subscriptions IS 'Outgoing webhook registrations per hospital. Payloads are signed with HMAC-SHA256 using the subscription secret.';
+COMMENT ON COLUMN webhook_subscriptions.event_types IS 'Event type filter (version.promoted, coverage.dropped, import.failed); empty means all events';
+COMMENT ON TABLE webhook_deliveries IS 'Delivery log: one row per event per subscription, updated on every attempt';
+COMMENT ON COLUMN webhook_deliveries.payload IS 'Exact JSON body sent to the receiver';
+COMMENT ON COLUMN webhook_deliveries.redelivery_of IS 'Original delivery when created through the redelivery endpoint';
+COMMENT ON TABLE event_cursors IS 'Last event handled per consumer, so a restarted consumer resumes where it stopped';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pending_notifications_unsent;
+DROP TABLE IF EXISTS pending_notifications;
+DROP TABLE IF EXISTS notification_preferences;
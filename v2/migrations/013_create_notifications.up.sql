@@ -0,0 +1,29 @@
+CREATE TABLE notification_preferences (
+    person_id UUID PRIMARY KEY REFERENCES persons(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    channels TEXT[] NOT NULL DEFAULT '{EMAIL}',
+    phone_number VARCHAR(32),
+    digest_mode VARCHAR(20) NOT NULL DEFAULT 'IMMEDIATE'
+        CHECK (digest_mode IN ('IMMEDIATE', 'DAILY')),
+    digest_hour INTEGER NOT NULL DEFAULT 7 CHECK (digest_hour BETWEEN 0 AND 23),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE pending_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    person_id UUID NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    old_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    new_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Indexes for common queries
+CREATE INDEX idx_pending_notifications_unsent ON pending_notifications(person_id, created_at) WHERE sent_at IS NULL;
+
+COMMENT ON TABLE notification_preferences IS 'Per-person settings for schedule change notifications. People without a row get immediate email.';
+COMMENT ON COLUMN notification_preferences.channels IS 'EMAIL and/or SMS';
+COMMENT ON COLUMN notification_preferences.digest_hour IS 'UTC hour at which DAILY digests are sent';
+COMMENT ON TABLE pending_notifications IS 'Rendered per-person change sets waiting for the daily digest';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_coverage_alerts_hospital;
+DROP INDEX IF EXISTS idx_coverage_alerts_open_gap;
+DROP TABLE IF EXISTS coverage_alerts;
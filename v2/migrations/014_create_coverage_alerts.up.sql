@@ -0,0 +1,28 @@
+CREATE TABLE coverage_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    schedule_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    shift_instance_id UUID NOT NULL,
+    gap_key VARCHAR(200) NOT NULL,
+    shift_type VARCHAR(50) NOT NULL,
+    study_type VARCHAR(50),
+    shift_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    coverage_status VARCHAR(20) NOT NULL CHECK (coverage_status IN ('PARTIAL', 'UNCOVERED')),
+    required INTEGER NOT NULL,
+    assigned INTEGER NOT NULL,
+    tier VARCHAR(20) NOT NULL CHECK (tier IN ('SCHEDULER', 'CHIEF', 'ADMIN')),
+    state VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (state IN ('OPEN', 'RESOLVED')),
+    resolution_reason VARCHAR(20),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    escalated_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One open alert per gap
+CREATE UNIQUE INDEX idx_coverage_alerts_open_gap ON coverage_alerts(hospital_id, gap_key) WHERE state = 'OPEN';
+CREATE INDEX idx_coverage_alerts_hospital ON coverage_alerts(hospital_id, created_at DESC);
+
+COMMENT ON TABLE coverage_alerts IS 'Alerts for UNCOVERED/PARTIAL mandatory shifts in upcoming PRODUCTION schedules';
+COMMENT ON COLUMN coverage_alerts.gap_key IS 'Date, shift type, study type and times; stable across schedule versions';
+COMMENT ON COLUMN coverage_alerts.tier IS 'Escalation tier based on time to shift start: SCHEDULER, CHIEF, ADMIN';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_amion_page_snapshots_hash;
+DROP INDEX IF EXISTS idx_amion_page_snapshots_batch_url;
+DROP TABLE IF EXISTS amion_page_snapshots;
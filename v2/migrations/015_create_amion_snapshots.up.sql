@@ -0,0 +1,24 @@
+CREATE TABLE amion_page_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scrape_batch_id UUID NOT NULL REFERENCES scrape_batches(id),
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    url TEXT NOT NULL,
+    month VARCHAR(7) NOT NULL,
+    window_from VARCHAR(10) NOT NULL DEFAULT '',
+    window_to VARCHAR(10) NOT NULL DEFAULT '',
+    content_hash CHAR(64) NOT NULL,
+    encoding VARCHAR(20) NOT NULL DEFAULT 'gzip',
+    size INTEGER NOT NULL,
+    content BYTEA NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- One snapshot per page per batch; a retried fetch replaces the earlier copy
+CREATE UNIQUE INDEX idx_amion_page_snapshots_batch_url ON amion_page_snapshots(scrape_batch_id, url);
+CREATE INDEX idx_amion_page_snapshots_hash ON amion_page_snapshots(content_hash);
+
+COMMENT ON TABLE amion_page_snapshots IS 'Raw Amion pages archived per scrape batch for offline re-import and debugging';
+COMMENT ON COLUMN amion_page_snapshots.content_hash IS 'SHA-256 (hex) of the uncompressed HTML';
+COMMENT ON COLUMN amion_page_snapshots.content IS 'HTML compressed with the given encoding';
+COMMENT ON COLUMN amion_page_snapshots.window_from IS 'First date the page is authoritative for; rows outside the window are dropped on import';
@@ -0,0 +1,4 @@
+ALTER TABLE amion_page_snapshots DROP COLUMN IF EXISTS division_id;
+ALTER TABLE scrape_batches DROP COLUMN IF EXISTS division_stats;
+DROP INDEX IF EXISTS idx_amion_divisions_hospital_name;
+DROP TABLE IF EXISTS amion_divisions;
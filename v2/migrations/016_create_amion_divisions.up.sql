@@ -0,0 +1,32 @@
+CREATE TABLE amion_divisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    name VARCHAR(100) NOT NULL,
+    base_url TEXT NOT NULL,
+    url_template TEXT NOT NULL DEFAULT '',
+    page_view VARCHAR(10) NOT NULL DEFAULT ''
+        CHECK (page_view IN ('', 'month', 'week', 'day')),
+    credentials_ref VARCHAR(255) NOT NULL DEFAULT '',
+    selector_profile VARCHAR(100) NOT NULL DEFAULT '',
+    shift_name_mapping JSONB NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by UUID NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by UUID
+);
+
+-- Division names are unique per hospital among live rows
+CREATE UNIQUE INDEX idx_amion_divisions_hospital_name ON amion_divisions(hospital_id, lower(name)) WHERE deleted_at IS NULL;
+
+ALTER TABLE scrape_batches ADD COLUMN division_stats JSONB;
+
+-- Archived pages remember their division; week and day pages are labelled by date
+ALTER TABLE amion_page_snapshots ADD COLUMN division_id UUID REFERENCES amion_divisions(id);
+ALTER TABLE amion_page_snapshots ALTER COLUMN month TYPE VARCHAR(10);
+
+COMMENT ON TABLE amion_divisions IS 'Separately published Amion schedules of a hospital, scraped together into one batch';
+COMMENT ON COLUMN amion_divisions.credentials_ref IS 'Secret name resolved by the worker; credentials are never stored here';
+COMMENT ON COLUMN amion_divisions.shift_name_mapping IS 'Amion shift label -> shift type';
+COMMENT ON COLUMN scrape_batches.division_stats IS 'Per-division pages, rows and errors for multi-division scrapes';
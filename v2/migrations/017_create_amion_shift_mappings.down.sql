@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_amion_shift_mappings_match;
+DROP TABLE IF EXISTS amion_shift_mappings;
@@ -0,0 +1,29 @@
+CREATE TABLE amion_shift_mappings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    amion_label VARCHAR(100) NOT NULL,
+    location VARCHAR(100) NOT NULL DEFAULT '',
+    start_time VARCHAR(5) NOT NULL DEFAULT '',
+    end_time VARCHAR(5) NOT NULL DEFAULT '',
+    shift_type VARCHAR(50) NOT NULL
+        CHECK (shift_type IN ('ON1', 'ON2', 'MidC', 'MidL', 'DAY')),
+    study_type VARCHAR(50) NOT NULL
+        CHECK (study_type IN ('GENERAL', 'BODY', 'NEURO')),
+    specialty_constraint VARCHAR(50) NOT NULL
+        CHECK (specialty_constraint IN ('BODY_ONLY', 'NEURO_ONLY', 'BOTH')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by UUID NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by UUID
+);
+
+-- One live mapping per label and condition set, so a match is never ambiguous
+CREATE UNIQUE INDEX idx_amion_shift_mappings_match
+    ON amion_shift_mappings(hospital_id, lower(amion_label), lower(location), start_time, end_time)
+    WHERE deleted_at IS NULL;
+
+COMMENT ON TABLE amion_shift_mappings IS 'Amion shift labels -> shift type, study type and specialty of our shift slots';
+COMMENT ON COLUMN amion_shift_mappings.location IS 'Optional: only rows at this Amion location match; empty matches any';
+COMMENT ON COLUMN amion_shift_mappings.start_time IS 'Optional HH:MM: only rows starting at this time match; empty matches any';
+COMMENT ON COLUMN amion_shift_mappings.end_time IS 'Optional HH:MM: only rows ending at this time match; empty matches any';
@@ -0,0 +1,3 @@
+ALTER TABLE scrape_batches DROP COLUMN IF EXISTS delta;
+DROP INDEX IF EXISTS idx_scraped_assignments_batch;
+DROP TABLE IF EXISTS scraped_assignments;
@@ -0,0 +1,17 @@
+CREATE TABLE scraped_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    scrape_batch_id UUID NOT NULL REFERENCES scrape_batches(id) ON DELETE CASCADE,
+    schedule_date DATE NOT NULL,
+    shift VARCHAR(100) NOT NULL,
+    start_time VARCHAR(10) NOT NULL DEFAULT '',
+    end_time VARCHAR(10) NOT NULL DEFAULT '',
+    location VARCHAR(100) NOT NULL DEFAULT '',
+    person VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_scraped_assignments_batch ON scraped_assignments(scrape_batch_id, schedule_date);
+
+ALTER TABLE scrape_batches ADD COLUMN delta JSONB;
+
+COMMENT ON TABLE scraped_assignments IS 'Every staff member on every Amion shift a scrape batch saw, compared against by the next scrape';
+COMMENT ON COLUMN scrape_batches.delta IS 'Added, removed and moved assignments since the previous scrape, and any STAGING versions created for them';
@@ -0,0 +1,6 @@
+ALTER TABLE scrape_batches DROP COLUMN IF EXISTS rolled_back_by;
+ALTER TABLE scrape_batches DROP COLUMN IF EXISTS rolled_back_at;
+DROP INDEX IF EXISTS idx_assignments_scrape_batch;
+DROP INDEX IF EXISTS idx_shift_instances_scrape_batch;
+ALTER TABLE assignments DROP COLUMN IF EXISTS scrape_batch_id;
+ALTER TABLE shift_instances DROP COLUMN IF EXISTS scrape_batch_id;
@@ -0,0 +1,13 @@
+-- Which scrape batch (Amion scrape, re-import or ODS import) created each row
+ALTER TABLE shift_instances ADD COLUMN scrape_batch_id UUID REFERENCES scrape_batches(id);
+ALTER TABLE assignments ADD COLUMN scrape_batch_id UUID REFERENCES scrape_batches(id);
+
+CREATE INDEX idx_shift_instances_scrape_batch ON shift_instances(scrape_batch_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_assignments_scrape_batch ON assignments(scrape_batch_id) WHERE deleted_at IS NULL;
+
+ALTER TABLE scrape_batches ADD COLUMN rolled_back_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE scrape_batches ADD COLUMN rolled_back_by UUID;
+
+COMMENT ON COLUMN shift_instances.scrape_batch_id IS 'Batch that created the shift; NULL for shifts created by hand';
+COMMENT ON COLUMN assignments.scrape_batch_id IS 'Batch that created the assignment; NULL for assignments created by hand';
+COMMENT ON COLUMN scrape_batches.rolled_back_at IS 'When the rows the batch created were soft-deleted by a rollback';
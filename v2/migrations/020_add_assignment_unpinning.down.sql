@@ -0,0 +1,2 @@
+ALTER TABLE assignments DROP COLUMN IF EXISTS unpinned_by;
+ALTER TABLE assignments DROP COLUMN IF EXISTS unpinned_at;
@@ -0,0 +1,6 @@
+-- MANUAL and OVERRIDE assignments are pinned: imports leave their shift/date
+-- alone until a scheduler unpins them
+ALTER TABLE assignments ADD COLUMN unpinned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE assignments ADD COLUMN unpinned_by UUID;
+
+COMMENT ON COLUMN assignments.unpinned_at IS 'When a MANUAL/OVERRIDE assignment was unpinned, letting imports replace it; NULL while pinned';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_merge_conflicts_merge;
+DROP TABLE IF EXISTS merge_conflicts;
+DROP INDEX IF EXISTS idx_schedule_merges_staging;
+DROP TABLE IF EXISTS schedule_merges;
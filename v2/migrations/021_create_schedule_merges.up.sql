@@ -0,0 +1,33 @@
+CREATE TABLE schedule_merges (
+    id UUID PRIMARY KEY,
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    base_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    staging_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    imported_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    auto_applied INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID NOT NULL
+);
+
+CREATE INDEX idx_schedule_merges_staging ON schedule_merges(staging_version_id, created_at DESC);
+
+CREATE TABLE merge_conflicts (
+    id UUID PRIMARY KEY,
+    merge_id UUID NOT NULL REFERENCES schedule_merges(id) ON DELETE CASCADE,
+    schedule_date DATE NOT NULL,
+    shift_type VARCHAR(100) NOT NULL,
+    study_type VARCHAR(50) NOT NULL DEFAULT '',
+    specialty_constraint VARCHAR(50) NOT NULL DEFAULT '',
+    base_person_ids UUID[] NOT NULL DEFAULT '{}',
+    staging_person_ids UUID[] NOT NULL DEFAULT '{}',
+    imported_person_ids UUID[] NOT NULL DEFAULT '{}',
+    resolution VARCHAR(20),
+    resolved_at TIMESTAMP,
+    resolved_by UUID
+);
+
+CREATE INDEX idx_merge_conflicts_merge ON merge_conflicts(merge_id, schedule_date, shift_type);
+
+COMMENT ON TABLE schedule_merges IS 'Three-way merges of a freshly imported version into an edited STAGING version, against their common base';
+COMMENT ON TABLE merge_conflicts IS 'Shifts a merge could not apply because the STAGING edits and the import both changed them';
+COMMENT ON COLUMN merge_conflicts.resolution IS 'STAGING keeps the edited staffing, IMPORTED takes the import''s; NULL while open';
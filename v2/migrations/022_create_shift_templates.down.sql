@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_shift_templates_name;
+DROP TABLE IF EXISTS shift_templates;
@@ -0,0 +1,28 @@
+CREATE TABLE shift_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hospital_id UUID NOT NULL REFERENCES hospitals(id),
+    name VARCHAR(100) NOT NULL,
+    shift_type VARCHAR(50) NOT NULL
+        CHECK (shift_type IN ('ON1', 'ON2', 'MidC', 'MidL', 'DAY')),
+    weekdays SMALLINT[] NOT NULL DEFAULT '{}',
+    variants JSONB NOT NULL DEFAULT '[]',
+    skip_holidays BOOLEAN NOT NULL DEFAULT false,
+    effective_from DATE NOT NULL,
+    effective_until DATE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by UUID NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by UUID,
+    CHECK (effective_until IS NULL OR effective_until >= effective_from)
+);
+
+CREATE UNIQUE INDEX idx_shift_templates_name
+    ON shift_templates(hospital_id, lower(name))
+    WHERE deleted_at IS NULL;
+
+COMMENT ON TABLE shift_templates IS 'Recurring shift requirements used to generate shift instances without an ODS import';
+COMMENT ON COLUMN shift_templates.weekdays IS 'Days of the week the shift runs: 0 = Sunday .. 6 = Saturday';
+COMMENT ON COLUMN shift_templates.variants IS 'Times, study type, specialty and coverage per day type (WEEKDAY, WEEKEND, HOLIDAY)';
+COMMENT ON COLUMN shift_templates.skip_holidays IS 'No shift on holidays unless a HOLIDAY variant is defined';
+COMMENT ON COLUMN shift_templates.effective_until IS 'Optional: last date the template applies; NULL runs indefinitely';
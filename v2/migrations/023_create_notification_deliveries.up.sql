@@ -0,0 +1,11 @@
+CREATE TABLE notification_deliveries (
+    old_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    new_version_id UUID NOT NULL REFERENCES schedule_versions(id),
+    person_id UUID NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('SENT', 'QUEUED')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (old_version_id, new_version_id, person_id)
+);
+
+COMMENT ON TABLE notification_deliveries IS 'People already notified about a promotion, so a retried notification job skips them';
+COMMENT ON COLUMN notification_deliveries.status IS 'SENT immediately or QUEUED for the daily digest';
@@ -10,6 +10,16 @@ import (
 
 // Factory functions create valid entities with sensible defaults
 
+// Date parses a YYYY-MM-DD date as midnight UTC, for fixtures pinned to a
+// calendar day
+func Date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
 // CreateValidPerson creates a valid Person with all required fields
 func CreateValidPerson() *entity.Person {
 	return NewPersonBuilder().Build()
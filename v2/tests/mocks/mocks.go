@@ -3,14 +3,18 @@ package mocks
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
 	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
 	"github.com/schedcu/v2/internal/validation"
 )
 
 // MockPersonRepository is a mock implementation of PersonRepository for testing
 type MockPersonRepository struct {
+	repository.PersonRepository
 	mu      sync.RWMutex
 	people  map[uuid.UUID]*entity.Person
 	getErr  error
@@ -77,6 +81,12 @@ func (m *MockPersonRepository) GetAll(ctx context.Context) ([]*entity.Person, er
 	return people, nil
 }
 
+// GetByHospital retrieves people (mock implementation). People are not tied
+// to hospitals, so every stored person is returned.
+func (m *MockPersonRepository) GetByHospital(ctx context.Context, hospitalID uuid.UUID) ([]*entity.Person, error) {
+	return m.GetAll(ctx)
+}
+
 // SetGetError sets the error to return from Get operations
 func (m *MockPersonRepository) SetGetError(err error) {
 	m.mu.Lock()
@@ -91,8 +101,8 @@ func (m *MockPersonRepository) SetSaveError(err error) {
 	m.saveErr = err
 }
 
-// Count returns the number of stored people
-func (m *MockPersonRepository) Count() int {
+// Len returns the number of stored people
+func (m *MockPersonRepository) Len() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return len(m.people)
@@ -107,6 +117,7 @@ func (m *MockPersonRepository) Clear() {
 
 // MockScheduleVersionRepository is a mock implementation of ScheduleVersionRepository
 type MockScheduleVersionRepository struct {
+	repository.ScheduleVersionRepository
 	mu        sync.RWMutex
 	versions  map[uuid.UUID]*entity.ScheduleVersion
 	getErr    error
@@ -142,7 +153,7 @@ func (m *MockScheduleVersionRepository) GetByID(ctx context.Context, id uuid.UUI
 	if version, ok := m.versions[id]; ok {
 		return version, nil
 	}
-	return nil, nil
+	return nil, &repository.NotFoundError{ResourceType: "ScheduleVersion", ResourceID: id.String()}
 }
 
 // GetByStatus retrieves schedule versions by status
@@ -161,6 +172,21 @@ func (m *MockScheduleVersionRepository) GetByStatus(ctx context.Context, status
 	return versions, nil
 }
 
+// GetByHospitalAndStatus retrieves a hospital's schedule versions with a status
+func (m *MockScheduleVersionRepository) GetByHospitalAndStatus(ctx context.Context, hospitalID uuid.UUID, status entity.VersionStatus) ([]*entity.ScheduleVersion, error) {
+	versions, err := m.GetByStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	var matching []*entity.ScheduleVersion
+	for _, version := range versions {
+		if version.HospitalID == hospitalID {
+			matching = append(matching, version)
+		}
+	}
+	return matching, nil
+}
+
 // Update updates a schedule version
 func (m *MockScheduleVersionRepository) Update(ctx context.Context, version *entity.ScheduleVersion) error {
 	m.mu.Lock()
@@ -172,6 +198,17 @@ func (m *MockScheduleVersionRepository) Update(ctx context.Context, version *ent
 	return nil
 }
 
+// Delete removes a schedule version
+func (m *MockScheduleVersionRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.versions[id]; !ok {
+		return &repository.NotFoundError{ResourceType: "ScheduleVersion", ResourceID: id.String()}
+	}
+	delete(m.versions, id)
+	return nil
+}
+
 // SetGetError sets the error to return from Get operations
 func (m *MockScheduleVersionRepository) SetGetError(err error) {
 	m.mu.Lock()
@@ -193,8 +230,8 @@ func (m *MockScheduleVersionRepository) SetUpdateError(err error) {
 	m.updateErr = err
 }
 
-// Count returns the number of stored versions
-func (m *MockScheduleVersionRepository) Count() int {
+// Len returns the number of stored versions
+func (m *MockScheduleVersionRepository) Len() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return len(m.versions)
@@ -207,19 +244,129 @@ func (m *MockScheduleVersionRepository) Clear() {
 	m.versions = make(map[uuid.UUID]*entity.ScheduleVersion)
 }
 
-// MockAssignmentRepository is a mock implementation of AssignmentRepository
+// MockShiftInstanceRepository is a mock implementation of ShiftInstanceRepository.
+// Shifts are kept in the order they were created; deleted ones are kept but
+// no longer returned.
+type MockShiftInstanceRepository struct {
+	repository.ShiftInstanceRepository
+	mu      sync.RWMutex
+	shifts  []*entity.ShiftInstance
+	deleted map[uuid.UUID]bool
+	saveErr error
+}
+
+// NewMockShiftInstanceRepository creates a new mock shift instance repository
+func NewMockShiftInstanceRepository() *MockShiftInstanceRepository {
+	return &MockShiftInstanceRepository{
+		deleted: make(map[uuid.UUID]bool),
+	}
+}
+
+// Create stores a shift instance
+func (m *MockShiftInstanceRepository) Create(ctx context.Context, shift *entity.ShiftInstance) error {
+	return m.CreateBatch(ctx, []*entity.ShiftInstance{shift})
+}
+
+// CreateBatch stores shift instances
+func (m *MockShiftInstanceRepository) CreateBatch(ctx context.Context, shifts []*entity.ShiftInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.shifts = append(m.shifts, shifts...)
+	return nil
+}
+
+// GetByID retrieves a live shift instance by ID
+func (m *MockShiftInstanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.ShiftInstance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, shift := range m.shifts {
+		if shift.ID == id && !m.deleted[id] {
+			return shift, nil
+		}
+	}
+	return nil, &repository.NotFoundError{ResourceType: "ShiftInstance", ResourceID: id.String()}
+}
+
+// GetByScheduleVersion retrieves the live shift instances of a schedule version
+func (m *MockShiftInstanceRepository) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.ShiftInstance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var shifts []*entity.ShiftInstance
+	for _, shift := range m.shifts {
+		if shift.ScheduleVersionID == versionID && !m.deleted[shift.ID] {
+			shifts = append(shifts, shift)
+		}
+	}
+	return shifts, nil
+}
+
+// Update replaces a live shift instance
+func (m *MockShiftInstanceRepository) Update(ctx context.Context, shift *entity.ShiftInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.shifts {
+		if existing.ID == shift.ID && !m.deleted[shift.ID] {
+			m.shifts[i] = shift
+			return nil
+		}
+	}
+	return &repository.NotFoundError{ResourceType: "ShiftInstance", ResourceID: shift.ID.String()}
+}
+
+// Delete marks a live shift instance deleted
+func (m *MockShiftInstanceRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	if _, err := m.GetByID(ctx, id); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted[id] = true
+	return nil
+}
+
+// IsDeleted reports whether a shift instance was deleted
+func (m *MockShiftInstanceRepository) IsDeleted(id uuid.UUID) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.deleted[id]
+}
+
+// All returns every stored shift instance, deleted ones included
+func (m *MockShiftInstanceRepository) All() []*entity.ShiftInstance {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*entity.ShiftInstance(nil), m.shifts...)
+}
+
+// SetSaveError sets the error to return from Create operations
+func (m *MockShiftInstanceRepository) SetSaveError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saveErr = err
+}
+
+// MockAssignmentRepository is a mock implementation of AssignmentRepository.
+// Assignments are kept in the order they were created. Queries by schedule
+// version and EditVersion need the version and shift mocks the assignments
+// belong to; NewMockSchedule wires them up.
 type MockAssignmentRepository struct {
-	mu        sync.RWMutex
-	assignments map[uuid.UUID]*entity.Assignment
-	getErr    error
-	saveErr   error
+	repository.AssignmentRepository
+	mu          sync.RWMutex
+	assignments []*entity.Assignment
+	versions    *MockScheduleVersionRepository
+	shifts      *MockShiftInstanceRepository
+	applied     []*entity.AssignmentChanges
+	beforeEdit  func()
+	getErr      error
+	saveErr     error
 }
 
 // NewMockAssignmentRepository creates a new mock assignment repository
 func NewMockAssignmentRepository() *MockAssignmentRepository {
-	return &MockAssignmentRepository{
-		assignments: make(map[uuid.UUID]*entity.Assignment),
-	}
+	return &MockAssignmentRepository{}
 }
 
 // Create stores an assignment
@@ -229,21 +376,23 @@ func (m *MockAssignmentRepository) Create(ctx context.Context, assignment *entit
 	if m.saveErr != nil {
 		return m.saveErr
 	}
-	m.assignments[assignment.ID] = assignment
+	m.assignments = append(m.assignments, assignment)
 	return nil
 }
 
-// GetByID retrieves an assignment by ID
+// GetByID retrieves a live assignment by ID
 func (m *MockAssignmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Assignment, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.getErr != nil {
 		return nil, m.getErr
 	}
-	if assignment, ok := m.assignments[id]; ok {
-		return assignment, nil
+	for _, assignment := range m.assignments {
+		if assignment.ID == id && !assignment.IsDeleted() {
+			return assignment, nil
+		}
 	}
-	return nil, nil
+	return nil, &repository.NotFoundError{ResourceType: "Assignment", ResourceID: id.String()}
 }
 
 // GetByPersonID retrieves all assignments for a person
@@ -278,6 +427,153 @@ func (m *MockAssignmentRepository) GetByShiftInstanceID(ctx context.Context, shi
 	return assignments, nil
 }
 
+// GetByScheduleVersion retrieves the live assignments on a schedule version's shifts
+func (m *MockAssignmentRepository) GetByScheduleVersion(ctx context.Context, versionID uuid.UUID) ([]*entity.Assignment, error) {
+	inVersion := make(map[uuid.UUID]bool)
+	if m.shifts != nil {
+		for _, shift := range m.shifts.All() {
+			inVersion[shift.ID] = shift.ScheduleVersionID == versionID
+		}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	var assignments []*entity.Assignment
+	for _, assignment := range m.assignments {
+		if inVersion[assignment.ShiftInstanceID] && !assignment.IsDeleted() {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments, nil
+}
+
+// Update replaces a live assignment
+func (m *MockAssignmentRepository) Update(ctx context.Context, assignment *entity.Assignment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.assignments {
+		if existing.ID == assignment.ID && !existing.IsDeleted() {
+			m.assignments[i] = assignment
+			return nil
+		}
+	}
+	return &repository.NotFoundError{ResourceType: "Assignment", ResourceID: assignment.ID.String()}
+}
+
+// Delete soft-deletes a live assignment
+func (m *MockAssignmentRepository) Delete(ctx context.Context, id uuid.UUID, deleterID uuid.UUID) error {
+	assignment, err := m.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assignment.SoftDelete(deleterID)
+	return nil
+}
+
+// ApplyChanges records the change set and applies its deletes, updates and creates
+func (m *MockAssignmentRepository) ApplyChanges(ctx context.Context, changes *entity.AssignmentChanges, editorID uuid.UUID) error {
+	m.mu.Lock()
+	m.applied = append(m.applied, changes)
+	m.mu.Unlock()
+	for _, id := range changes.Delete {
+		if err := m.Delete(ctx, id, editorID); err != nil {
+			return err
+		}
+	}
+	for _, assignment := range changes.Update {
+		if err := m.Update(ctx, assignment); err != nil {
+			return err
+		}
+	}
+	for _, assignment := range changes.Create {
+		if err := m.Create(ctx, assignment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EditVersion runs plan against the version's shifts and live assignments
+// and applies the changes it returns
+func (m *MockAssignmentRepository) EditVersion(ctx context.Context, versionID uuid.UUID, editorID uuid.UUID, plan repository.VersionEditPlan) error {
+	return m.EditSchedule(ctx, versionID, editorID, func(version *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.ScheduleChanges, error) {
+		changes, err := plan(version, shifts, assignments)
+		if err != nil || changes == nil {
+			return nil, err
+		}
+		return &entity.ScheduleChanges{Assignments: *changes}, nil
+	})
+}
+
+// EditSchedule runs plan against the version's shifts and live assignments
+// and applies the shift and assignment changes it returns
+func (m *MockAssignmentRepository) EditSchedule(ctx context.Context, versionID uuid.UUID, editorID uuid.UUID, plan repository.ScheduleEditPlan) error {
+	m.mu.RLock()
+	beforeEdit := m.beforeEdit
+	m.mu.RUnlock()
+	if beforeEdit != nil {
+		beforeEdit()
+	}
+	version, err := m.versions.GetByID(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	shifts, err := m.shifts.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	assignments, err := m.GetByScheduleVersion(ctx, versionID)
+	if err != nil {
+		return err
+	}
+	changes, err := plan(version, shifts, assignments)
+	if err != nil || changes == nil || changes.IsEmpty() {
+		return err
+	}
+	for _, shift := range changes.CreateShifts {
+		if err := m.shifts.Create(ctx, shift); err != nil {
+			return err
+		}
+	}
+	if !changes.Assignments.IsEmpty() {
+		if err := m.ApplyChanges(ctx, &changes.Assignments, editorID); err != nil {
+			return err
+		}
+	}
+	for _, id := range changes.DeleteShifts {
+		if err := m.shifts.Delete(ctx, id, editorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Applied returns the change sets passed to ApplyChanges, in order
+func (m *MockAssignmentRepository) Applied() []*entity.AssignmentChanges {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*entity.AssignmentChanges(nil), m.applied...)
+}
+
+// SetBeforeEdit sets a function EditVersion runs before reading the version,
+// standing in for an edit that lands just before the lock is taken
+func (m *MockAssignmentRepository) SetBeforeEdit(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.beforeEdit = fn
+}
+
+// All returns every stored assignment, deleted ones included
+func (m *MockAssignmentRepository) All() []*entity.Assignment {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*entity.Assignment(nil), m.assignments...)
+}
+
 // SetGetError sets the error to return from Get operations
 func (m *MockAssignmentRepository) SetGetError(err error) {
 	m.mu.Lock()
@@ -292,8 +588,8 @@ func (m *MockAssignmentRepository) SetSaveError(err error) {
 	m.saveErr = err
 }
 
-// Count returns the number of stored assignments
-func (m *MockAssignmentRepository) Count() int {
+// Len returns the number of stored assignments
+func (m *MockAssignmentRepository) Len() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return len(m.assignments)
@@ -303,7 +599,67 @@ func (m *MockAssignmentRepository) Count() int {
 func (m *MockAssignmentRepository) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.assignments = make(map[uuid.UUID]*entity.Assignment)
+	m.assignments = nil
+	m.applied = nil
+}
+
+// MockSchedule is version, shift, assignment and person mocks wired together
+// as in the database: assignments reach their version through their shift
+type MockSchedule struct {
+	Versions    *MockScheduleVersionRepository
+	Shifts      *MockShiftInstanceRepository
+	Assignments *MockAssignmentRepository
+	People      *MockPersonRepository
+}
+
+// NewMockSchedule creates empty, wired schedule mocks
+func NewMockSchedule() *MockSchedule {
+	s := &MockSchedule{
+		Versions:    NewMockScheduleVersionRepository(),
+		Shifts:      NewMockShiftInstanceRepository(),
+		Assignments: NewMockAssignmentRepository(),
+		People:      NewMockPersonRepository(),
+	}
+	s.Assignments.versions = s.Versions
+	s.Assignments.shifts = s.Shifts
+	return s
+}
+
+// MockCoverageQueue is a mock CoverageQueue that records the ranges queued
+type MockCoverageQueue struct {
+	mu     sync.Mutex
+	ranges [][2]time.Time
+	err    error
+}
+
+// NewMockCoverageQueue creates a new mock coverage queue
+func NewMockCoverageQueue() *MockCoverageQueue {
+	return &MockCoverageQueue{}
+}
+
+// EnqueueCoverageCalculation records the range queued
+func (m *MockCoverageQueue) EnqueueCoverageCalculation(ctx context.Context, versionID entity.ScheduleVersionID, startDate, endDate entity.Date, creatorID entity.UserID) (*asynq.TaskInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.ranges = append(m.ranges, [2]time.Time{startDate, endDate})
+	return &asynq.TaskInfo{}, nil
+}
+
+// Ranges returns the start and end dates queued, in order
+func (m *MockCoverageQueue) Ranges() [][2]time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][2]time.Time(nil), m.ranges...)
+}
+
+// SetError sets the error to return from EnqueueCoverageCalculation
+func (m *MockCoverageQueue) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
 }
 
 // MockValidationService is a mock implementation of a validation service
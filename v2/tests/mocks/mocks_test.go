@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/schedcu/v2/internal/entity"
+	"github.com/schedcu/v2/internal/repository"
 	"github.com/schedcu/v2/internal/validation"
 	"github.com/schedcu/v2/tests/helpers"
 )
@@ -22,7 +24,7 @@ func TestMockPersonRepository_Create(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	if repo.Count() != 1 {
+	if repo.Len() != 1 {
 		t.Error("expected 1 person in repository")
 	}
 }
@@ -109,7 +111,7 @@ func TestMockScheduleVersionRepository_Create(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	if repo.Count() != 1 {
+	if repo.Len() != 1 {
 		t.Error("expected 1 version in repository")
 	}
 }
@@ -168,7 +170,7 @@ func TestMockAssignmentRepository_Create(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
-	if repo.Count() != 1 {
+	if repo.Len() != 1 {
 		t.Error("expected 1 assignment in repository")
 	}
 }
@@ -300,6 +302,107 @@ func TestMockValidationService_SetNextResult(t *testing.T) {
 	}
 }
 
+// TestMockSchedule_GetByScheduleVersion verifies assignments are found
+// through their shift's version
+func TestMockSchedule_GetByScheduleVersion(t *testing.T) {
+	ctx := context.Background()
+	schedule := NewMockSchedule()
+	version := helpers.CreateValidScheduleVersion()
+	schedule.Versions.Create(ctx, version)
+
+	shift := helpers.CreateValidShiftInstance()
+	shift.ScheduleVersionID = version.ID
+	other := helpers.CreateValidShiftInstance()
+	schedule.Shifts.CreateBatch(ctx, []*entity.ShiftInstance{shift, other})
+
+	live := helpers.NewAssignmentBuilder().WithShiftInstanceID(shift.ID).Build()
+	deleted := helpers.NewAssignmentBuilder().WithShiftInstanceID(shift.ID).Build()
+	elsewhere := helpers.NewAssignmentBuilder().WithShiftInstanceID(other.ID).Build()
+	for _, a := range []*entity.Assignment{live, deleted, elsewhere} {
+		schedule.Assignments.Create(ctx, a)
+	}
+	schedule.Assignments.Delete(ctx, deleted.ID, uuid.New())
+
+	assignments, err := schedule.Assignments.GetByScheduleVersion(ctx, version.ID)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(assignments) != 1 || assignments[0].ID != live.ID {
+		t.Errorf("expected only the live assignment in the version, got %d", len(assignments))
+	}
+}
+
+// TestMockSchedule_EditVersion verifies planned changes are applied and recorded
+func TestMockSchedule_EditVersion(t *testing.T) {
+	ctx := context.Background()
+	schedule := NewMockSchedule()
+	version := helpers.CreateValidScheduleVersion()
+	schedule.Versions.Create(ctx, version)
+	shift := helpers.CreateValidShiftInstance()
+	shift.ScheduleVersionID = version.ID
+	schedule.Shifts.Create(ctx, shift)
+
+	added := helpers.NewAssignmentBuilder().WithShiftInstanceID(shift.ID).Build()
+	err := schedule.Assignments.EditVersion(ctx, version.ID, uuid.New(),
+		func(v *entity.ScheduleVersion, shifts []*entity.ShiftInstance, assignments []*entity.Assignment) (*entity.AssignmentChanges, error) {
+			if v.ID != version.ID || len(shifts) != 1 || len(assignments) != 0 {
+				t.Error("expected the version's shifts and assignments")
+			}
+			return &entity.AssignmentChanges{Create: []*entity.Assignment{added}}, nil
+		})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(schedule.Assignments.Applied()) != 1 || schedule.Assignments.Len() != 1 {
+		t.Error("expected the change set to be applied")
+	}
+
+	_, err = schedule.Versions.GetByID(ctx, uuid.New())
+	var notFound *repository.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Error("expected a NotFoundError for an unknown version")
+	}
+}
+
+// TestMockShiftInstanceRepository_Delete verifies deleted shifts are hidden but kept
+func TestMockShiftInstanceRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockShiftInstanceRepository()
+	shift := helpers.CreateValidShiftInstance()
+	repo.Create(ctx, shift)
+
+	if err := repo.Delete(ctx, shift.ID, uuid.New()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !repo.IsDeleted(shift.ID) || len(repo.All()) != 1 {
+		t.Error("expected the shift to be marked deleted and kept")
+	}
+	if _, err := repo.GetByID(ctx, shift.ID); err == nil {
+		t.Error("expected deleted shift to be hidden")
+	}
+	if err := repo.Delete(ctx, shift.ID, uuid.New()); err == nil {
+		t.Error("expected deleting twice to fail")
+	}
+}
+
+// TestMockCoverageQueue verifies queued ranges are recorded
+func TestMockCoverageQueue(t *testing.T) {
+	queue := NewMockCoverageQueue()
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 6)
+
+	queue.EnqueueCoverageCalculation(context.Background(), uuid.New(), start, end, uuid.New())
+	if ranges := queue.Ranges(); len(ranges) != 1 || !ranges[0][0].Equal(start) || !ranges[0][1].Equal(end) {
+		t.Error("expected the queued range to be recorded")
+	}
+
+	testErr := errors.New("redis down")
+	queue.SetError(testErr)
+	if _, err := queue.EnqueueCoverageCalculation(context.Background(), uuid.New(), start, end, uuid.New()); !errors.Is(err, testErr) {
+		t.Error("expected mock to return set error")
+	}
+}
+
 // TestMocks_ConcurrentAccess verifies mocks are thread-safe
 func TestMocks_ConcurrentAccess(t *testing.T) {
 	ctx := context.Background()
@@ -321,8 +424,8 @@ func TestMocks_ConcurrentAccess(t *testing.T) {
 		}
 	}
 
-	if repo.Count() != 10 {
-		t.Errorf("expected 10 people, got %d", repo.Count())
+	if repo.Len() != 10 {
+		t.Errorf("expected 10 people, got %d", repo.Len())
 	}
 }
 
@@ -336,12 +439,12 @@ func TestMocks_Clear(t *testing.T) {
 		repo.Create(ctx, person)
 	}
 
-	if repo.Count() != 5 {
+	if repo.Len() != 5 {
 		t.Error("expected 5 people")
 	}
 
 	repo.Clear()
-	if repo.Count() != 0 {
+	if repo.Len() != 0 {
 		t.Error("expected 0 people after clear")
 	}
 }